@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/manifest"
+	"github.com/sandrolain/crawldown/src/pagebuffer"
+)
+
+// applyRedirects points redirected URLs at the file of the page they led to and records the chains for the manifest
+func applyRedirects(redirects map[string][]crawler.Redirect, pages pagebuffer.Buffer[pageRecord], urlToFile map[string]string) {
+	for target, chain := range redirects {
+		key := strings.TrimSuffix(target, "/")
+		filename, ok := urlToFile[key]
+		if !ok {
+			continue
+		}
+
+		page, isPage, err := pages.Get(key)
+		if err != nil {
+			printStderr("  Error reading buffered page: %v\n", err)
+		}
+		for _, hop := range chain {
+			hopURL := strings.TrimSuffix(hop.URL, "/")
+			if _, exists := urlToFile[hopURL]; !exists {
+				urlToFile[hopURL] = filename
+			}
+			if isPage {
+				page.redirects = append(page.redirects, manifest.Redirect{URL: hop.URL, Status: hop.Status})
+			}
+		}
+
+		if isPage {
+			if err := pages.Put(key, page); err != nil {
+				printStderr("  Error buffering page: %v\n", err)
+			}
+		}
+	}
+}
+
+// applyURLRewrites points URLs changed by rewrite rules at the file of the URL visited instead
+func applyURLRewrites(rewritten map[string]string, urlToFile map[string]string) {
+	for original, target := range rewritten {
+		originalURL := strings.TrimSuffix(original, "/")
+		if _, exists := urlToFile[originalURL]; exists {
+			continue
+		}
+		if filename, ok := urlToFile[strings.TrimSuffix(target, "/")]; ok {
+			urlToFile[originalURL] = filename
+		}
+	}
+}
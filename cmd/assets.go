@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/flavor"
+	"github.com/sandrolain/crawldown/src/imaging"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// imageDownloader downloads the images of pages into content-addressed asset files.
+// Each image URL is fetched once per run, and images with the same content share one file,
+// so a logo shown on every page is stored once.
+type imageDownloader struct {
+	fetch     func(assetURL string) ([]byte, string, error)
+	writer    output.Writer
+	assetsDir string
+	process   imaging.Options
+
+	mutex sync.Mutex
+	paths map[string]string // Asset paths by image URL, "" for images that could not be downloaded
+}
+
+func newImageDownloader(fetch func(string) ([]byte, string, error), writer output.Writer, assetsDir string, process imaging.Options) *imageDownloader {
+	return &imageDownloader{fetch: fetch, writer: writer, assetsDir: assetsDir, process: process, paths: make(map[string]string)}
+}
+
+// imageProcessing returns the processing of downloaded images selected by the command options
+func imageProcessing(options *getOptions) imaging.Options {
+	return imaging.Options{
+		Format:        options.imageFormat,
+		MaxDimension:  options.maxImageDimension,
+		StripMetadata: options.stripImageMetadata,
+	}
+}
+
+// localize replaces the image sources of a page with the paths of the downloaded images
+func (d *imageDownloader) localize(content, pageURL string) string {
+	return converter.LocalizeImages(content, pageURL, d.download)
+}
+
+// download returns the asset path of an image, downloading and saving it on first use
+func (d *imageDownloader) download(imageURL string) (string, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if assetPath, seen := d.paths[imageURL]; seen {
+		return assetPath, assetPath != ""
+	}
+	d.paths[imageURL] = ""
+
+	data, contentType, err := d.fetch(imageURL)
+	if err != nil {
+		printStderr("  Error downloading image %s: %v\n", imageURL, err)
+		return "", false
+	}
+
+	// Processed images are stored under the hash of their new content
+	processed, processedType, err := imaging.Process(data, contentType, d.process)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		printStderr("  Keeping image %s as downloaded: %v\n", imageURL, err)
+	case err != nil:
+		printStderr("  Error processing image %s: %v\n", imageURL, err)
+		return "", false
+	default:
+		data, contentType = processed, processedType
+	}
+
+	asset := converter.Asset{
+		Path: converter.AssetPath(d.assetsDir, data, converter.AssetExtension(contentType, imageURL)),
+		Data: data,
+	}
+	if err := saveAsset(d.writer, asset); err != nil {
+		printStderr("  Error saving image: %v\n", err)
+		return "", false
+	}
+
+	d.paths[imageURL] = asset.Path
+	return asset.Path, true
+}
+
+// fileDownloader downloads linked files, such as archives and spreadsheets, and media into the files directory
+// under their own names, so the output stays self-contained
+type fileDownloader struct {
+	fetch        func(fileURL string, maxSize int64) ([]byte, string, error)
+	writer       output.Writer
+	dir          string
+	maxMediaSize int64
+
+	mutex sync.Mutex
+	paths map[string]string // File paths by URL, "" for files that could not be downloaded
+	owner map[string]string // URLs by folded file path, to keep files with the same name apart, also in another case
+}
+
+func newFileDownloader(fetch func(string, int64) ([]byte, string, error), writer output.Writer, dir string, maxMediaSize int64) *fileDownloader {
+	return &fileDownloader{
+		fetch:        fetch,
+		writer:       writer,
+		dir:          dir,
+		maxMediaSize: maxMediaSize,
+		paths:        make(map[string]string),
+		owner:        make(map[string]string),
+	}
+}
+
+// filesDir returns the directory of downloaded files next to the assets directory of a flavor
+func filesDir(f flavor.Flavor) string {
+	return path.Join(path.Dir(f.AssetsDir()), converter.DefaultFilesDir)
+}
+
+// download returns the path of a linked file, downloading and saving it on first use
+func (d *fileDownloader) download(fileURL string) (string, bool) {
+	return d.get(fileURL, 0)
+}
+
+// downloadMedia returns the path of a video or audio file, downloading it on first use unless it is larger than maxMediaSize
+func (d *fileDownloader) downloadMedia(mediaURL string) (string, bool) {
+	return d.get(mediaURL, d.maxMediaSize)
+}
+
+func (d *fileDownloader) get(fileURL string, maxSize int64) (string, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if filePath, seen := d.paths[fileURL]; seen {
+		return filePath, filePath != ""
+	}
+	d.paths[fileURL] = ""
+
+	data, _, err := d.fetch(fileURL, maxSize)
+	if err != nil {
+		printStderr("  Error downloading file %s: %v\n", fileURL, err)
+		return "", false
+	}
+
+	filePath := d.filePath(fileURL)
+	if err := d.writer.WriteFile(filePath, data); err != nil {
+		printStderr("  Error saving file: %v\n", err)
+		return "", false
+	}
+
+	d.paths[fileURL] = filePath
+	d.owner[converter.FoldFilename(filePath)] = fileURL
+	return filePath, true
+}
+
+// filePath returns the path of a downloaded file named after the last segment of its URL.
+// A file whose name is taken by another URL gets a suffix derived from its URL.
+func (d *fileDownloader) filePath(fileURL string) string {
+	name := "file"
+	if parsedURL, err := url.Parse(fileURL); err == nil {
+		if base := converter.SanitizeSegment(path.Base(parsedURL.Path)); base != "" && base != "." && base != "/" {
+			name = base
+		}
+	}
+
+	filePath := path.Join(d.dir, name)
+	if owner, taken := d.owner[converter.FoldFilename(filePath)]; !taken || owner == fileURL {
+		return filePath
+	}
+
+	sum := sha256.Sum256([]byte(fileURL))
+	extension := path.Ext(name)
+	return path.Join(d.dir, strings.TrimSuffix(name, extension)+"-"+hex.EncodeToString(sum[:4])+extension)
+}
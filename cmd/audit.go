@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandrolain/crawldown/src/audit"
+)
+
+// auditLinkLimit is the number of example links listed per decision
+const auditLinkLimit = 5
+
+type auditOptions struct {
+	url                 string
+	excludedPaths       []string
+	requestTimeout      int
+	requestDelay        int
+	ignoreRobotsTxt     bool
+	followExternalLinks bool
+	userAgent           string
+}
+
+func newAuditCommand() *cobra.Command {
+	options := auditOptions{}
+
+	auditCmd := &cobra.Command{
+		Use:           "audit [flags] [url]",
+		Short:         "Check what a crawl is allowed to do before running it",
+		Long:          "Fetch robots.txt and the start page, report crawl-delay, sitemaps, and robots directives, and show which links the crawl would follow under the given options.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := options.url
+			if len(args) > 0 {
+				if target != "" {
+					return fmt.Errorf("provide the URL either as argument or with --url")
+				}
+				target = args[0]
+			}
+			if target == "" {
+				return fmt.Errorf("requires a URL argument or --url")
+			}
+
+			return runAudit(options, target)
+		},
+	}
+
+	flags := auditCmd.Flags()
+	flags.StringVarP(&options.url, "url", "u", "", "Start URL to audit")
+	flags.StringSliceVarP(&options.excludedPaths, "exclude", "e", nil, "URL path prefixes to exclude from crawling")
+	flags.IntVarP(&options.requestTimeout, "timeout", "t", 60, "Request timeout in seconds")
+	flags.IntVar(&options.requestDelay, "delay", 1, "Delay between requests in seconds")
+	flags.BoolVar(&options.ignoreRobotsTxt, "ignore-robots-txt", false, "Ignore robots.txt while crawling")
+	flags.BoolVar(&options.followExternalLinks, "follow-external-links", false, "Allow following external links")
+	flags.StringVar(&options.userAgent, "user-agent", "CrawlDown/1.0", "HTTP user agent used for requests")
+
+	return auditCmd
+}
+
+func runAudit(options auditOptions, target string) error {
+	report, err := audit.Run(target, audit.Options{
+		UserAgent:           options.userAgent,
+		IgnoreRobotsTxt:     options.ignoreRobotsTxt,
+		FollowExternalLinks: options.followExternalLinks,
+		RequestDelay:        time.Duration(options.requestDelay) * time.Second,
+		ExcludedPaths:       options.excludedPaths,
+		Client:              &http.Client{Timeout: time.Duration(options.requestTimeout) * time.Second},
+	})
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	printStdout("%s", formatAuditReport(report, options.userAgent))
+
+	if !report.Allowed() {
+		return fmt.Errorf("the start page cannot be crawled: %s", report.StartDecision)
+	}
+
+	return nil
+}
+
+// formatAuditReport renders an audit report as plain text
+func formatAuditReport(report *audit.Report, userAgent string) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("Audit of %s (user agent %q)\n\n", report.StartURL, userAgent))
+
+	builder.WriteString(fmt.Sprintf("robots.txt: %s (HTTP %d)\n", report.RobotsURL, report.RobotsStatus))
+	if report.RobotsAgent != "" {
+		builder.WriteString(fmt.Sprintf("  Applicable group: User-agent: %s\n", report.RobotsAgent))
+	}
+	for _, rule := range report.RobotsRules {
+		builder.WriteString("  " + rule + "\n")
+	}
+	if report.CrawlDelay > 0 {
+		builder.WriteString(fmt.Sprintf("  Crawl-delay: %s\n", report.CrawlDelay))
+	}
+	for _, sitemap := range report.Sitemaps {
+		builder.WriteString("  Sitemap: " + sitemap + "\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("\nStart page: HTTP %d, %s\n", report.StartStatus, report.StartDecision))
+	if len(report.MetaRobots) > 0 {
+		builder.WriteString("  Meta robots: " + strings.Join(report.MetaRobots, "; ") + "\n")
+	}
+	if len(report.XRobotsTag) > 0 {
+		builder.WriteString("  X-Robots-Tag: " + strings.Join(report.XRobotsTag, "; ") + "\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("\nLinks on the start page: %d\n", len(report.Links)))
+	for _, decision := range []string{audit.DecisionAllowed, audit.DecisionRobotsBlocked, audit.DecisionExcluded, audit.DecisionExternal} {
+		var urls []string
+		for _, link := range report.Links {
+			if link.Decision == decision {
+				urls = append(urls, link.URL)
+			}
+		}
+		if len(urls) == 0 {
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("  %s: %d\n", decision, len(urls)))
+		for i, linkURL := range urls {
+			if i == auditLinkLimit {
+				builder.WriteString(fmt.Sprintf("    ... and %d more\n", len(urls)-auditLinkLimit))
+				break
+			}
+			builder.WriteString("    " + linkURL + "\n")
+		}
+	}
+
+	if len(report.Warnings) > 0 {
+		builder.WriteString("\nWarnings:\n")
+		for _, warning := range report.Warnings {
+			builder.WriteString("  - " + warning + "\n")
+		}
+	}
+
+	return builder.String()
+}
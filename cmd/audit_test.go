@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/audit"
+)
+
+func TestFormatAuditReport(t *testing.T) {
+	t.Parallel()
+
+	report := &audit.Report{
+		StartURL:      "https://example.com/",
+		RobotsURL:     "https://example.com/robots.txt",
+		RobotsStatus:  200,
+		RobotsAgent:   "*",
+		RobotsRules:   []string{"Disallow: /private"},
+		CrawlDelay:    5 * time.Second,
+		Sitemaps:      []string{"https://example.com/sitemap.xml"},
+		StartStatus:   200,
+		StartDecision: audit.DecisionAllowed,
+		MetaRobots:    []string{"nofollow"},
+		Links: []audit.LinkCheck{
+			{URL: "https://example.com/a", Decision: audit.DecisionAllowed},
+			{URL: "https://example.com/private/b", Decision: audit.DecisionRobotsBlocked},
+		},
+		Warnings: []string{"robots.txt asks for a crawl delay of 5s"},
+	}
+
+	got := formatAuditReport(report, "CrawlDown/1.0")
+
+	for _, want := range []string{
+		"Audit of https://example.com/ (user agent \"CrawlDown/1.0\")",
+		"  Disallow: /private\n",
+		"  Crawl-delay: 5s\n",
+		"  Sitemap: https://example.com/sitemap.xml\n",
+		"Start page: HTTP 200, allowed\n",
+		"  Meta robots: nofollow\n",
+		"  blocked by robots.txt: 1\n    https://example.com/private/b\n",
+		"Warnings:\n  - robots.txt asks for a crawl delay of 5s\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report is missing %q:\n%s", want, got)
+		}
+	}
+}
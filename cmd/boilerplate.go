@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sandrolain/crawldown/src/boilerplate"
+	"github.com/sandrolain/crawldown/src/flavor"
+	"github.com/sandrolain/crawldown/src/output"
+	"github.com/sandrolain/crawldown/src/pagebuffer"
+	"github.com/sandrolain/crawldown/src/render"
+	"github.com/sandrolain/crawldown/src/summary"
+)
+
+// boilerplateFilename is the name of the report of the blocks removed with --boilerplate-report
+const boilerplateFilename = "boilerplate.json"
+
+// boilerplateReport is the content of boilerplate.json
+type boilerplateReport struct {
+	Pages  int                 `json:"pages"` // Number of pages the blocks were looked for on
+	Blocks []boilerplate.Block `json:"blocks"`
+}
+
+// stripBoilerplate removes the blocks of Markdown found on at least share of the pages from every page
+// and renders the pages again, summarizing them again with the given number of sentences
+func stripBoilerplate(pages pagebuffer.Buffer[pageRecord], share float64, sentences int, renderer *render.Renderer, pageFlavor flavor.Flavor) boilerplateReport {
+	detector := boilerplate.NewDetector()
+	for _, key := range pages.Keys() {
+		page, ok, err := pages.Get(key)
+		if err != nil {
+			printStderr("  Error reading buffered page: %v\n", err)
+			continue
+		}
+		if ok {
+			detector.Add(page.renderData.Markdown)
+		}
+	}
+
+	report := boilerplateReport{Pages: detector.Pages(), Blocks: detector.Repeated(share)}
+	if len(report.Blocks) == 0 {
+		return report
+	}
+
+	remove := make(map[string]bool, len(report.Blocks))
+	for _, block := range report.Blocks {
+		remove[block.Text] = true
+	}
+
+	for _, key := range pages.Keys() {
+		page, ok, err := pages.Get(key)
+		if err != nil {
+			printStderr("  Error reading buffered page: %v\n", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		markdown, removed := boilerplate.Strip(page.renderData.Markdown, remove)
+		if removed == 0 {
+			continue
+		}
+		page.renderData.Markdown = markdown
+		if sentences > 0 {
+			page.renderData.Summary = summary.Summarize(markdown, page.renderData.Metadata.Description, sentences)
+		}
+
+		content, err := buildPageContent(renderer, pageFlavor, page.renderData)
+		if err != nil {
+			printStderr("  Error rendering template: %v\n", err)
+			continue
+		}
+		page.markdown = content
+
+		if err := pages.Put(key, page); err != nil {
+			printStderr("  Error buffering page: %v\n", err)
+		}
+	}
+
+	printStdout("Stripped %d boilerplate blocks repeated on %.0f%% or more of %d pages\n", len(report.Blocks), share*100, report.Pages)
+	return report
+}
+
+// saveBoilerplateReport writes the blocks removed as boilerplate to boilerplate.json
+func saveBoilerplateReport(writer output.Writer, report boilerplateReport) error {
+	if report.Blocks == nil {
+		report.Blocks = []boilerplate.Block{}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode boilerplate report: %w", err)
+	}
+
+	if err := writer.WriteFile(boilerplateFilename, data); err != nil {
+		return fmt.Errorf("write boilerplate report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/flavor"
+	"github.com/sandrolain/crawldown/src/pagebuffer"
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+// resolveFilenameCollisions gives pages whose URLs map to the same file name distinct names.
+// Names differing only in case collide too, as they are the same file on case-insensitive file systems.
+// The page with the lowest URL keeps the name and the others get a hash of their URL appended,
+// so the result does not depend on the crawl order.
+func resolveFilenameCollisions(pages pagebuffer.Buffer[pageRecord], urlToFile map[string]string, renderer *render.Renderer, pageFlavor flavor.Flavor) {
+	byFile := make(map[string][]string)
+	for _, key := range pages.Keys() {
+		page, ok, err := pages.Get(key)
+		if err != nil {
+			printStderr("  Error reading buffered page: %v\n", err)
+			continue
+		}
+		if ok {
+			folded := converter.FoldFilename(page.filename)
+			byFile[folded] = append(byFile[folded], key)
+		}
+	}
+
+	for _, keys := range byFile {
+		if len(keys) < 2 {
+			continue
+		}
+		sort.Strings(keys)
+
+		for i, key := range keys {
+			page, _, err := pages.Get(key)
+			if err != nil {
+				printStderr("  Error reading buffered page: %v\n", err)
+				continue
+			}
+			filename := page.filename
+			page.originalFile = filename
+
+			if i == 0 {
+				printStdout("Filename collision: %d pages map to %s\n", len(keys), filename)
+			} else {
+				page.filename = converter.DisambiguateFilename(filename, page.pageURL)
+				page.renderData.File = page.filename
+
+				if content, err := buildPageContent(renderer, pageFlavor, page.renderData); err == nil {
+					page.markdown = content
+				} else {
+					printStderr("  Error rendering template: %v\n", err)
+				}
+
+				urlToFile[key] = page.filename
+				for _, alias := range page.aliases {
+					urlToFile[alias] = page.filename
+				}
+			}
+
+			if err := pages.Put(key, page); err != nil {
+				printStderr("  Error buffering page: %v\n", err)
+			}
+		}
+	}
+}
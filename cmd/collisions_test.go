@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/manifest"
+)
+
+func TestCrawlOnceFilenameCollisions(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p><a href="/a/b">Nested</a> <a href="/a-b">Dashed</a></p></main></body></html>`))
+	})
+	mux.HandleFunc("/a/b", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Nested</title></head><body><main><p>Nested page</p></main></body></html>`))
+	})
+	mux.HandleFunc("/a-b", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Dashed</title></head><body><main><p>Dashed page</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	pageManifest, err := manifest.Load(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading manifest: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, entry := range pageManifest.Pages {
+		if entry.URL == srv.URL {
+			continue
+		}
+		if entry.OriginalFile != "a-b.md" {
+			t.Errorf("expected %s to record the original file a-b.md, got %q", entry.URL, entry.OriginalFile)
+		}
+		files[entry.URL] = entry.File
+	}
+
+	if len(files) != 2 || files[srv.URL+"/a-b"] != "a-b.md" || files[srv.URL+"/a/b"] == "a-b.md" {
+		t.Fatalf("expected /a-b to keep a-b.md and /a/b to get another file, got %v", files)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	home, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading home page: %v", err)
+	}
+
+	for _, link := range []string{"[Nested](" + files[srv.URL+"/a/b"] + ")", "[Dashed](a-b.md)"} {
+		if !strings.Contains(string(home), link) {
+			t.Errorf("expected home page to contain %s, got: %s", link, home)
+		}
+	}
+}
+
+func TestCrawlOnceCaseInsensitiveCollisions(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p><a href="/Guide">Upper</a> <a href="/guide">Lower</a></p></main></body></html>`))
+	})
+	mux.HandleFunc("/Guide", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Upper</title></head><body><main><p>Upper page</p></main></body></html>`))
+	})
+	mux.HandleFunc("/guide", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Lower</title></head><body><main><p>Lower page</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	pageManifest, err := manifest.Load(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading manifest: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, entry := range pageManifest.Pages {
+		files[entry.URL] = entry.File
+	}
+
+	upper, lower := files[srv.URL+"/Guide"], files[srv.URL+"/guide"]
+	if upper != "Guide.md" || lower == "" || strings.EqualFold(upper, lower) {
+		t.Fatalf("expected /Guide to keep Guide.md and /guide to get a name differing in more than case, got %v", files)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/export"
+	"github.com/sandrolain/crawldown/src/manifest"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// previousEntry returns the manifest entry of a page whose file is still in the output.
+// The URL is matched with and without a trailing slash, as pages are recorded by their normalized URL.
+func previousEntry(writer output.Writer, previous *manifest.Manifest, pageURL string) (manifest.Entry, bool) {
+	trimmed := strings.TrimSuffix(pageURL, "/")
+	for _, candidate := range []string{pageURL, trimmed, trimmed + "/"} {
+		entry, ok := previous.Lookup(candidate)
+		if !ok {
+			continue
+		}
+		exists, err := writer.Exists(entry.File)
+		return entry, err == nil && exists
+	}
+	return manifest.Entry{}, false
+}
+
+// conditionalRequests sends If-Modified-Since with the recorded fetch time of pages that are already in the output
+// and collects the pages the server reports as not modified
+type conditionalRequests struct {
+	writer   output.Writer
+	previous *manifest.Manifest
+
+	mutex     sync.Mutex
+	unchanged []manifest.Entry
+}
+
+// register adds the request and skip hooks to the crawler
+func (r *conditionalRequests) register(c *crawler.Crawler) {
+	c.OnRequest(func(req *crawler.Request) {
+		if entry, ok := previousEntry(r.writer, r.previous, req.URL.String()); ok && !entry.FetchedAt.IsZero() {
+			req.Headers.Set("If-Modified-Since", entry.FetchedAt.UTC().Format(http.TimeFormat))
+		}
+	})
+
+	c.OnSkip(func(pageURL string, reason crawler.SkipReason) {
+		if reason != crawler.SkipUnchanged {
+			return
+		}
+		if entry, ok := previousEntry(r.writer, r.previous, pageURL); ok {
+			r.mutex.Lock()
+			r.unchanged = append(r.unchanged, entry)
+			r.mutex.Unlock()
+		}
+	})
+}
+
+// entries returns the entries of the pages that were not modified
+func (r *conditionalRequests) entries() []manifest.Entry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]manifest.Entry{}, r.unchanged...)
+}
+
+// unchangedDocument reads back the Markdown file of a not modified page for the exporters
+func unchangedDocument(options *getOptions, writer output.Writer, entry manifest.Entry) (export.Document, bool) {
+	if options.format != formatMarkdown && options.format != formatMdBook {
+		return export.Document{}, false
+	}
+
+	data, err := writer.ReadFile(entry.File)
+	if err != nil {
+		return export.Document{}, false
+	}
+
+	document := export.Document{
+		URL:       entry.URL,
+		File:      entry.File,
+		Markdown:  string(data),
+		Summary:   entry.Summary,
+		FetchedAt: entry.FetchedAt,
+
+		NavPosition: entry.NavPosition,
+	}
+	if entry.Metadata != nil {
+		document.Title = entry.Metadata.Title
+	}
+	return document, true
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/render"
+	"github.com/sandrolain/crawldown/src/tagging"
+)
+
+// fileConfig holds structured settings loaded from the --config JSON file
+type fileConfig struct {
+	ContentRules []crawler.ContentRule     `json:"content_rules"`
+	Templates    []render.Rule             `json:"templates"`
+	FrontMatter  []render.FrontMatterField `json:"front_matter"`
+	Tags         []tagging.Rule            `json:"tags"`
+	Replacements []converter.Replacement   `json:"replacements"`
+	Domains      []crawler.DomainOptions   `json:"domains"`
+	URLRewrites  []crawler.URLRewrite      `json:"url_rewrites"`
+	Skip         crawler.SkipRules         `json:"skip"`
+	Hosts        map[string]string         `json:"hosts"` // IP address to connect to for each host, like --resolve
+
+	// baseDir is the directory of the config file, used to resolve relative paths
+	baseDir string
+}
+
+// loadConfig reads and validates a JSON configuration file
+func loadConfig(path string) (*fileConfig, error) {
+	//nolint:gosec // The configuration path is provided by the user.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	cfg := &fileConfig{baseDir: filepath.Dir(path)}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	for i, rule := range cfg.ContentRules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("config content_rules[%d]: %w", i, err)
+		}
+	}
+
+	for i, rule := range cfg.Templates {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("config templates[%d]: %w", i, err)
+		}
+	}
+
+	for i, field := range cfg.FrontMatter {
+		if err := field.Validate(); err != nil {
+			return nil, fmt.Errorf("config front_matter[%d]: %w", i, err)
+		}
+	}
+
+	for i, rule := range cfg.Tags {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("config tags[%d]: %w", i, err)
+		}
+	}
+
+	for i, replacement := range cfg.Replacements {
+		if err := replacement.Validate(); err != nil {
+			return nil, fmt.Errorf("config replacements[%d]: %w", i, err)
+		}
+	}
+
+	for i, domain := range cfg.Domains {
+		if err := domain.Validate(); err != nil {
+			return nil, fmt.Errorf("config domains[%d]: %w", i, err)
+		}
+	}
+
+	for i, rewrite := range cfg.URLRewrites {
+		if err := rewrite.Validate(); err != nil {
+			return nil, fmt.Errorf("config url_rewrites[%d]: %w", i, err)
+		}
+	}
+
+	if err := cfg.Skip.Validate(); err != nil {
+		return nil, fmt.Errorf("config skip: %w", err)
+	}
+
+	for host, ip := range cfg.Hosts {
+		if _, _, err := crawler.ParseHostAddress(host + ":" + ip); err != nil {
+			return nil, fmt.Errorf("config hosts: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "valid content rules",
+			content: `{"content_rules": [{"selector": ".login", "action": "skip"}]}`,
+		},
+		{
+			name:    "valid templates",
+			content: `{"content_rules": [{"selector": ".login", "action": "skip"}], "templates": [{"pattern": "/blog/*", "template": "{{.Markdown}}"}]}`,
+		},
+		{
+			name:    "invalid template rule",
+			content: `{"content_rules": [{"selector": ".login", "action": "skip"}], "templates": [{"pattern": "/blog/*"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "valid front matter mapping",
+			content: `{"content_rules": [{"selector": ".login", "action": "skip"}], "front_matter": [{"from": "og:description", "to": "summary"}, {"from": "published_time", "to": "date", "type": "date"}]}`,
+		},
+		{
+			name:    "invalid front matter type",
+			content: `{"front_matter": [{"from": "published_time", "to": "date", "type": "timestamp"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "valid tag rules",
+			content: `{"content_rules": [{"selector": ".login", "action": "skip"}], "tags": [{"pattern": "/blog/*", "tags": ["blog"]}, {"selector": "pre code", "tags": ["code"]}]}`,
+		},
+		{
+			name:    "tag rule without tags",
+			content: `{"tags": [{"pattern": "/blog/*"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "valid replacements",
+			content: `{"content_rules": [{"selector": ".login", "action": "skip"}], "replacements": [{"find": "Was this page helpful\\?"}, {"find": "<aside>", "stage": "html"}]}`,
+		},
+		{
+			name:    "invalid replacement stage",
+			content: `{"replacements": [{"find": "x", "stage": "text"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "valid domains and url rewrites",
+			content: `{"content_rules": [{"selector": ".login", "action": "skip"}], "domains": [{"domain": "github.com", "max_depth": 1}], "url_rewrites": [{"find": "/index\\.html$", "replace": "/"}]}`,
+		},
+		{
+			name:    "invalid domain",
+			content: `{"domains": [{"domain": "https://github.com/"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid url rewrite",
+			content: `{"url_rewrites": [{"find": "(unclosed", "replace": "/"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "valid skip rules",
+			content: `{"content_rules": [{"selector": ".login", "action": "skip"}], "skip": {"titles": ["page not found"], "min_words": 20}}`,
+		},
+		{
+			name:    "invalid skip pattern",
+			content: `{"skip": {"content": ["[a-"]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "valid hosts",
+			content: `{"content_rules": [{"selector": ".login", "action": "skip"}], "hosts": {"docs.example.com": "10.0.0.5", "api.example.com": "2001:db8::1"}}`,
+		},
+		{
+			name:    "invalid host address",
+			content: `{"hosts": {"docs.example.com": "staging"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			content: `{"content_rules": [`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid rule",
+			content: `{"content_rules": [{"action": "skip"}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(test.content), 0o600); err != nil {
+				t.Fatalf("writing config: %v", err)
+			}
+
+			cfg, err := loadConfig(path)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected an error but got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(cfg.ContentRules) != 1 {
+				t.Fatalf("expected 1 content rule, got %d", len(cfg.ContentRules))
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	t.Parallel()
+
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected an error for a missing config file")
+	}
+}
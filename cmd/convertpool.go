@@ -0,0 +1,49 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+)
+
+// conversionPool converts crawled pages on a fixed number of worker goroutines.
+// Submitting blocks while every worker is busy and the queue is full, so fetching slows down to the conversion rate.
+type conversionPool struct {
+	pages chan crawler.Page
+	wg    sync.WaitGroup
+}
+
+// newConversionPool starts workers calling convert for each submitted page
+func newConversionPool(workers int, convert func(page crawler.Page)) *conversionPool {
+	pool := &conversionPool{pages: make(chan crawler.Page, workers)}
+	pool.wg.Add(workers)
+	for range workers {
+		go func() {
+			defer pool.wg.Done()
+			for page := range pool.pages {
+				convert(page)
+			}
+		}()
+	}
+	return pool
+}
+
+// submit queues a page for conversion
+func (p *conversionPool) submit(page crawler.Page) {
+	p.pages <- page
+}
+
+// wait stops accepting pages and returns once the queued pages are converted
+func (p *conversionPool) wait() {
+	close(p.pages)
+	p.wg.Wait()
+}
+
+// conversionWorkers returns the number of conversion workers, one per CPU unless --convert-workers is set
+func conversionWorkers(options *getOptions) int {
+	if options.convertWorkers > 0 {
+		return options.convertWorkers
+	}
+	return runtime.GOMAXPROCS(0)
+}
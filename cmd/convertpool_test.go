@@ -0,0 +1,43 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+)
+
+func TestConversionPool(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	converted := make(map[string]bool)
+	running, peak := 0, 0
+
+	pool := newConversionPool(3, func(page crawler.Page) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		converted[page.URL] = true
+		mu.Unlock()
+	})
+
+	for _, pageURL := range []string{"a", "b", "c", "d", "e", "f"} {
+		pool.submit(crawler.Page{URL: pageURL})
+	}
+	pool.wait()
+
+	if len(converted) != 6 {
+		t.Errorf("converted %d pages, want 6", len(converted))
+	}
+	if peak < 2 || peak > 3 {
+		t.Errorf("%d pages converted at once, want up to 3 and more than 1", peak)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandrolain/crawldown/src/diff"
+	"github.com/sandrolain/crawldown/src/manifest"
+)
+
+type diffOptions struct {
+	summaryOnly bool
+	context     int
+	reportPath  string
+}
+
+func newDiffCommand() *cobra.Command {
+	options := diffOptions{context: 3}
+
+	diffCmd := &cobra.Command{
+		Use:           "diff [flags] <old-dir> <new-dir>",
+		Short:         "Compare two crawl output directories",
+		Long:          "Compare the Markdown files of two crawl output directories and report added, removed, and changed pages with unified diffs.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(options, args[0], args[1])
+		},
+	}
+
+	flags := diffCmd.Flags()
+	flags.BoolVar(&options.summaryOnly, "summary-only", false, "Only list added, removed, and changed pages without unified diffs")
+	flags.IntVar(&options.context, "context", 3, "Number of context lines in unified diffs")
+	flags.StringVar(&options.reportPath, "report", "", "Write the report to this file instead of stdout")
+
+	return diffCmd
+}
+
+func runDiff(options diffOptions, oldDir, newDir string) error {
+	report, err := diff.CompareDirs(oldDir, newDir)
+	if err != nil {
+		return fmt.Errorf("compare directories: %w", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if options.reportPath != "" {
+		//nolint:gosec // The report path is provided by the user.
+		file, err := os.Create(options.reportPath)
+		if err != nil {
+			return fmt.Errorf("create report: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		out = file
+	}
+
+	text, err := formatDiffReport(options, oldDir, newDir, report)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(out, text); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	return nil
+}
+
+// formatDiffReport renders the summary and, unless disabled, the unified diffs of changed pages
+func formatDiffReport(options diffOptions, oldDir, newDir string, report diff.Report) (string, error) {
+	urls := manifestURLs(oldDir)
+	for file, pageURL := range manifestURLs(newDir) {
+		urls[file] = pageURL
+	}
+
+	var builder strings.Builder
+
+	sections := []struct {
+		title  string
+		marker string
+		files  []string
+	}{
+		{title: "Added", marker: "+", files: report.Added},
+		{title: "Removed", marker: "-", files: report.Removed},
+		{title: "Changed", marker: "~", files: report.Changed},
+	}
+
+	for _, section := range sections {
+		if len(section.files) == 0 {
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("%s (%d):\n", section.title, len(section.files)))
+		for _, file := range section.files {
+			if pageURL, ok := urls[file]; ok {
+				builder.WriteString(fmt.Sprintf("  %s %s (%s)\n", section.marker, file, pageURL))
+			} else {
+				builder.WriteString(fmt.Sprintf("  %s %s\n", section.marker, file))
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("%d added, %d removed, %d changed\n",
+		len(report.Added), len(report.Removed), len(report.Changed)))
+
+	if options.summaryOnly {
+		return builder.String(), nil
+	}
+
+	for _, file := range report.Changed {
+		oldPath := filepath.Join(oldDir, filepath.FromSlash(file))
+		newPath := filepath.Join(newDir, filepath.FromSlash(file))
+
+		//nolint:gosec // Paths come from walking user-provided directories.
+		oldContent, err := os.ReadFile(oldPath)
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", oldPath, err)
+		}
+
+		//nolint:gosec // Paths come from walking user-provided directories.
+		newContent, err := os.ReadFile(newPath)
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", newPath, err)
+		}
+
+		builder.WriteString("\n")
+		builder.WriteString(diff.Unified(oldPath, newPath, string(oldContent), string(newContent), options.context))
+	}
+
+	return builder.String(), nil
+}
+
+// manifestURLs maps output files to page URLs using the manifest of a directory, if present
+func manifestURLs(dir string) map[string]string {
+	urls := make(map[string]string)
+
+	m, err := manifest.Load(filepath.Join(dir, manifest.Filename))
+	if err != nil {
+		return urls
+	}
+
+	for _, entry := range m.Pages {
+		urls[entry.File] = entry.URL
+	}
+
+	return urls
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDiffWritesReport(t *testing.T) {
+	t.Parallel()
+
+	oldDir := t.TempDir()
+	newDir := t.TempDir()
+
+	files := map[string]string{
+		filepath.Join(oldDir, "index.md"):   "# Home\n\nOld text\n",
+		filepath.Join(newDir, "index.md"):   "# Home\n\nNew text\n",
+		filepath.Join(oldDir, "removed.md"): "gone\n",
+		filepath.Join(newDir, "added.md"):   "fresh\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("writing fixture: %v", err)
+		}
+	}
+
+	reportPath := filepath.Join(t.TempDir(), "report.txt")
+	options := diffOptions{context: 3, reportPath: reportPath}
+
+	if err := runDiff(options, oldDir, newDir); err != nil {
+		t.Fatalf("runDiff returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("reading report: %v", err)
+	}
+
+	report := string(content)
+	for _, want := range []string{
+		"Added (1):\n  + added.md",
+		"Removed (1):\n  - removed.md",
+		"Changed (1):\n  ~ index.md",
+		"1 added, 1 removed, 1 changed",
+		"-Old text\n+New text",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// errorsFilename is the name of the report of failed requests in the output
+const errorsFilename = "errors.json"
+
+// errorReport is the content of errors.json
+type errorReport struct {
+	Errors []crawler.CrawlError `json:"errors"`
+}
+
+// saveErrors writes the failed requests of the run to errors.json, replacing the report of a previous run
+func saveErrors(writer output.Writer, crawlErrors []crawler.CrawlError) error {
+	if len(crawlErrors) == 0 {
+		exists, err := writer.Exists(errorsFilename)
+		if err != nil || !exists {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(errorReport{Errors: append([]crawler.CrawlError{}, crawlErrors...)}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode errors: %w", err)
+	}
+
+	if err := writer.WriteFile(errorsFilename, data); err != nil {
+		return fmt.Errorf("write errors: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Stages of the page pipeline where a page can fail
+const (
+	stageConvert     = "convert"
+	stagePostprocess = "postprocess"
+	stageTemplate    = "template"
+	stageRender      = "render"
+	stageSave        = "save"
+)
+
+// pageFailure is a page that could not be converted or saved
+type pageFailure struct {
+	url   string
+	stage string
+	err   error
+}
+
+// pageFailures collects the page failures of a run from concurrent page callbacks
+type pageFailures struct {
+	failures []pageFailure
+	mutex    sync.Mutex
+}
+
+// add records a failure
+func (f *pageFailures) add(pageURL, stage string, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failures = append(f.failures, pageFailure{url: pageURL, stage: stage, err: err})
+}
+
+// list returns the failures ordered by URL
+func (f *pageFailures) list() []pageFailure {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	failures := append([]pageFailure(nil), f.failures...)
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].url < failures[j].url
+	})
+	return failures
+}
+
+// formatFailures renders the failures for the final summary
+func formatFailures(failures []pageFailure) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Failed to process %d pages:\n", len(failures))
+	for _, failure := range failures {
+		fmt.Fprintf(&builder, "  %s (%s): %v\n", failure.url, failure.stage, failure.err)
+	}
+	return builder.String()
+}
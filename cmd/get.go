@@ -1,34 +1,225 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/sandrolain/crawldown/src/archive"
 	"github.com/sandrolain/crawldown/src/converter"
 	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/elasticsearch"
+	"github.com/sandrolain/crawldown/src/export"
+	"github.com/sandrolain/crawldown/src/flavor"
+	"github.com/sandrolain/crawldown/src/frontier"
+	"github.com/sandrolain/crawldown/src/htmlsite"
+	"github.com/sandrolain/crawldown/src/lang"
+	"github.com/sandrolain/crawldown/src/manifest"
+	"github.com/sandrolain/crawldown/src/metrics"
+	"github.com/sandrolain/crawldown/src/output"
+	"github.com/sandrolain/crawldown/src/pagebuffer"
+	"github.com/sandrolain/crawldown/src/postprocess"
+	"github.com/sandrolain/crawldown/src/progress"
+	"github.com/sandrolain/crawldown/src/render"
+	"github.com/sandrolain/crawldown/src/storage"
+	"github.com/sandrolain/crawldown/src/summary"
+	"github.com/sandrolain/crawldown/src/tagging"
+	"github.com/sandrolain/crawldown/src/vector"
+	"github.com/sandrolain/crawldown/src/webhook"
 )
 
+// Output formats
+const (
+	formatMarkdown = "markdown"
+	formatHTMLSite = "html-site"
+	formatMdBook   = "mdbook"
+)
+
+// defaultMaxMediaSize limits the media downloaded with --media download
+const defaultMaxMediaSize byteSize = 50 * 1024 * 1024
+
 type getOptions struct {
 	outputDir           string
 	singleURL           string
 	maxDepth            int
 	excludedPaths       []string
+	scope               string
+	traversal           string
+	deterministic       bool
+	navSelector         string
 	requestTimeout      int
 	requestDelay        int
+	jitter              jitterDuration
+	concurrency         int
+	politeness          string
 	ignoreRobotsTxt     bool
+	ignoreMetaRobots    bool
 	followExternalLinks bool
+	includeSubdomains   bool
+	distinctSchemes     bool
+	maxBodySize         byteSize
+	truncateOversized   bool
+	maxMarkdownSize     byteSize
+	limitPaths          []string
+	priorities          []string
+	maxPages            int
+	maxDuration         time.Duration
+	redisURL            string
+	redisKey            string
+	spillDir            string
+	stream              bool
+	force               bool
+	noClobber           bool
+	prune               bool
+	stripBoilerplate    float64
+	boilerplateReport   bool
+	summarize           int
+	llmsTxt             bool
+	indexFile           string
+	summaryMD           bool
+	postprocessCmd      string
+	postprocessURL      string
+	postprocessModel    string
+	postprocessPrompt   string
+	postprocessWorkers  int
+	postprocessRate     float64
+	postprocessTimeout  time.Duration
+	vectorStore         string
+	embeddingURL        string
+	embeddingModel      string
+	chunkSize           int
+	chunkOverlap        int
+	elasticsearchURL    string
+	elasticsearchIndex  string
+	elasticsearchBulk   bool
+	templateFile        string
+	fileMode            fileMode
+	dirMode             fileMode
+	umask               bool
+	convertWorkers      int
+	embeds              string
+	embedRules          []string
+	skipTitles          []string
+	skipContent         []string
+	minWords            int
+	keepStatuses        []int
+	followStatuses      []int
+	retries             int
+	maxErrorRate        float64
+	failOnPageError     bool
+	maxFilenameLength   int
+	utf8Filenames       bool
+	filenameFrom        string
+	allowDomains        []string
+	denyDomains         []string
+	externalDepth       int
 	userAgent           string
+	watch               bool
+	watchInterval       time.Duration
+	changelogPath       string
+	extractDataURIs     bool
+	downloadImages      bool
+	downloadExtensions  []string
+	media               string
+	maxMediaSize        byteSize
+	imageFormat         string
+	maxImageDimension   int
+	stripImageMetadata  bool
+	dataURIThreshold    int
+	format              string
+	flavor              string
+	metadataFrontMatter bool
+	searchIndex         bool
+	validateMarkdown    bool
+	docusaurus          bool
+	notion              bool
+	confluence          bool
+	languages           []string
+	splitByLang         bool
+	canonicalOnly       bool
+	followPagination    bool
+	mergePagination     bool
+	stripSelectors      []string
+	requireSelectors    []string
+	hubs                []string
+	noDefaultStrip      bool
+	removeSelectors     []string
+	noDefaultRemove     bool
+	definitionLists     string
+	noFlattenTabs       bool
+	tables              string
+	headingAnchors      string
+	dialect             string
+	tableSyntax         string
+	lineBreaks          string
+	noRawHTML           bool
+	normalize           []string
+	progress            bool
+	progressInterval    time.Duration
+	quiet               bool
+	summaryPath         string
+	webhookURL          string
+	webhookEvents       []string
+	execPerPage         string
+	execWorkers         int
+	metricsAddr         string
+	acceptEncodings     []string
+	noKeepAlive         bool
+	maxIdleConnsPerHost int
+	noHTTP2             bool
+	tlsMinVersion       string
+	caCert              string
+	insecureSkipVerify  bool
+	denyPrivateNetworks bool
+	allowNetworks       []string
+	denyNetworks        []string
+	resolve             []string
+	verbose             bool
+	store               string
+	storeOnly           bool
+	gitCommit           bool
+	archivePath         string
+	archiveCleanup      bool
+	configPath          string
+	config              *fileConfig
+	metrics             *metrics.Metrics // Metrics of long-running modes, nil when they are not exposed
 }
 
 func defaultGetOptions() *getOptions {
 	return &getOptions{
-		maxDepth:       2,
-		requestTimeout: 60,
-		requestDelay:   1,
-		userAgent:      "CrawlDown/1.0",
+		maxDepth:          2,
+		requestTimeout:    60,
+		requestDelay:      1,
+		userAgent:         "CrawlDown/1.0",
+		watchInterval:     6 * time.Hour,
+		dataURIThreshold:  1024,
+		maxFilenameLength: converter.DefaultMaxFilenameLength,
+		filenameFrom:      converter.FilenameFromPath,
+		definitionLists:   converter.DefinitionListBold,
+		tables:            converter.TableHTML,
+		headingAnchors:    converter.HeadingAnchorHTML,
+		media:             converter.MediaLink,
+		maxMediaSize:      defaultMaxMediaSize,
+		embeds:            string(crawler.EmbedLink),
+		normalize:         converter.DefaultNormalize,
+		acceptEncodings:   crawler.DefaultEncodings,
+		format:            formatMarkdown,
+		flavor:            flavor.Standard,
+		progressInterval:  5 * time.Second,
+
+		postprocessTimeout: 2 * time.Minute,
+		chunkOverlap:       vector.DefaultChunkOverlap,
 	}
 }
 
@@ -44,26 +235,109 @@ func runGet(options *getOptions, args []string) error {
 		isSingle = true
 	}
 
-	printStdout("Starting crawl of: %s\n", startURL)
-	printStdout("Output directory: %s\n", options.outputDir)
-	printStdout("Max depth: %d\n", options.maxDepth)
-	printStdout("Request timeout: %ds\n", options.requestTimeout)
-	printStdout("Request delay: %ds\n", options.requestDelay)
-	printStdout("Ignore robots.txt: %t\n", options.ignoreRobotsTxt)
-	printStdout("Follow external links: %t\n", options.followExternalLinks)
+	options.logf("Starting crawl of: %s\n", startURL)
+	options.logf("Output directory: %s\n", options.outputDir)
+	options.logf("Max depth: %d\n", options.maxDepth)
+	options.logf("Request timeout: %ds\n", options.requestTimeout)
+	options.logf("Request delay: %ds\n", options.requestDelay)
+	options.logf("Ignore robots.txt: %t\n", options.ignoreRobotsTxt)
+	options.logf("Follow external links: %t\n", options.followExternalLinks)
+	if options.includeSubdomains {
+		options.logf("Including subdomains\n")
+	}
+	options.logf("Output format: %s\n", options.format)
+	if options.flavor != "" && options.flavor != flavor.Standard {
+		options.logf("Markdown flavor: %s\n", options.flavor)
+	}
 	if len(options.excludedPaths) > 0 {
-		printStdout("Excluded paths: %v\n", options.excludedPaths)
+		options.logf("Excluded paths: %v\n", options.excludedPaths)
+	}
+	if options.maxBodySize > 0 {
+		options.logf("Max body size: %s\n", options.maxBodySize.String())
+	}
+	if len(options.limitPaths) > 0 {
+		options.logf("Path limits: %v\n", options.limitPaths)
+	}
+	if len(options.priorities) > 0 {
+		options.logf("URL priorities: %v\n", options.priorities)
+	}
+	if options.maxPages > 0 {
+		options.logf("Max pages: %d\n", options.maxPages)
+	}
+	if options.maxDuration > 0 {
+		options.logf("Max duration: %s\n", options.maxDuration)
+	}
+	if len(options.allowDomains) > 0 {
+		options.logf("Allowed external domains: %v\n", options.allowDomains)
+	}
+	if len(options.denyDomains) > 0 {
+		options.logf("Denied domains: %v\n", options.denyDomains)
+	}
+	if options.externalDepth > 0 {
+		options.logf("External depth: %d\n", options.externalDepth)
+	}
+	if len(options.languages) > 0 {
+		options.logf("Languages: %v\n", options.languages)
+	}
+	if options.splitByLang {
+		options.logf("Splitting output by language\n")
 	}
 	if isSingle {
-		printStdout("Single-page mode: fetching %s only\n", startURL)
+		options.logf("Single-page mode: fetching %s only\n", startURL)
+	}
+	if options.watch {
+		options.logf("Watch mode: re-crawling every %s\n", options.watchInterval)
+	}
+	if options.configPath != "" {
+		options.logf("Config file: %s\n", options.configPath)
+	}
+	options.logf("\n")
+
+	if options.configPath != "" {
+		cfg, err := loadConfig(options.configPath)
+		if err != nil {
+			return err
+		}
+		options.config = cfg
 	}
-	printlnStdout()
 
-	if err := os.MkdirAll(options.outputDir, 0o750); err != nil {
-		return fmt.Errorf("create output directory: %w", err)
+	if options.outputDir != "" && !output.IsRemote(options.outputDir) {
+		if err := output.MkdirAll(options.outputDir, options.permissions()); err != nil {
+			return fmt.Errorf("create output directory: %w", err)
+		}
 	}
 
-	converterOpts := converter.Options{
+	if options.watch {
+		if options.metricsAddr != "" {
+			options.metrics = metrics.New()
+			stop, err := startMetricsServer(options.metricsAddr, options.metrics)
+			if err != nil {
+				return err
+			}
+			defer stop()
+		}
+		return runWatch(options, startURL, isSingle)
+	}
+
+	result, err := crawlOnce(options, startURL, isSingle)
+	if err != nil {
+		return err
+	}
+
+	if err := finishRun(options, startURL, result); err != nil {
+		return err
+	}
+
+	if options.failOnPageError && len(result.failures) > 0 {
+		return fmt.Errorf("%d pages failed to process", len(result.failures))
+	}
+
+	return nil
+}
+
+// defaultConverterOptions returns the converter configuration used by the CLI
+func defaultConverterOptions() converter.Options {
+	return converter.Options{
 		Domain:           "",
 		BulletListMarker: "-",
 		CodeBlockStyle:   "fenced",
@@ -71,126 +345,1407 @@ func runGet(options *getOptions, args []string) error {
 		StrongDelimiter:  "**",
 		LinkStyle:        "inlined",
 	}
+}
+
+// converterOptions returns the converter configuration for a crawl, including the elements removed before conversion
+func converterOptions(options *getOptions) converter.Options {
+	opts := defaultConverterOptions()
+	if !options.noDefaultRemove {
+		opts.RemoveSelectors = append(opts.RemoveSelectors, converter.DefaultRemoveSelectors...)
+	}
+	opts.RemoveSelectors = append(opts.RemoveSelectors, options.removeSelectors...)
+	opts.DefinitionLists = options.definitionLists
+	opts.FlattenTabs = !options.noFlattenTabs
+	opts.Tables = options.tables
+	opts.HeadingAnchors = options.headingAnchors
+	opts.Dialect = options.dialect
+	opts.TableSyntax = options.tableSyntax
+	opts.LineBreaks = options.lineBreaks
+	// The html-site renderer omits raw HTML and the MDX of the Docusaurus export escapes it,
+	// so complex tables and heading anchors must be written as Markdown
+	opts.NoRawHTML = options.noRawHTML || options.format == formatHTMLSite || options.docusaurus
+	opts.Media = options.media
+	// The names are checked when the command arguments are validated
+	opts.Normalize, _ = converter.ParseNormalize(options.normalize)
+	return opts
+}
+
+// pageRecord holds a converted page waiting for link rewriting and saving
+type pageRecord struct {
+	title     string
+	markdown  string
+	filename  string
+	pageURL   string
+	links     []string
+	aliases   []string
+	redirects []manifest.Redirect
+	fetchedAt time.Time
+	status    int
+
+	// originalFile is the file name shared with other pages before it was disambiguated
+	originalFile string
+
+	// renderData is the page before the flavor layout or template, used to re-render merged pages
+	renderData render.Data
+	next       string
+	prev       string
+
+	// hubLinks are the links of the main content of a hub page, listed by its index
+	hubLinks []string
+}
+
+// bufferedPage is the encoding of a pageRecord in a page buffer spilled to disk
+type bufferedPage struct {
+	Title        string              `json:"title"`
+	Markdown     string              `json:"markdown"`
+	Filename     string              `json:"filename"`
+	PageURL      string              `json:"page_url"`
+	Links        []string            `json:"links,omitempty"`
+	Aliases      []string            `json:"aliases,omitempty"`
+	Redirects    []manifest.Redirect `json:"redirects,omitempty"`
+	FetchedAt    time.Time           `json:"fetched_at"`
+	Status       int                 `json:"status"`
+	OriginalFile string              `json:"original_file,omitempty"`
+	RenderData   render.Data         `json:"render_data"`
+	Next         string              `json:"next,omitempty"`
+	Prev         string              `json:"prev,omitempty"`
+	HubLinks     []string            `json:"hub_links,omitempty"`
+}
+
+// MarshalJSON encodes the record for a page buffer spilled to disk
+func (p pageRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bufferedPage{
+		Title:        p.title,
+		Markdown:     p.markdown,
+		Filename:     p.filename,
+		PageURL:      p.pageURL,
+		Links:        p.links,
+		Aliases:      p.aliases,
+		Redirects:    p.redirects,
+		FetchedAt:    p.fetchedAt,
+		Status:       p.status,
+		OriginalFile: p.originalFile,
+		RenderData:   p.renderData,
+		Next:         p.next,
+		Prev:         p.prev,
+		HubLinks:     p.hubLinks,
+	})
+}
+
+// UnmarshalJSON decodes a record read back from a page buffer spilled to disk
+func (p *pageRecord) UnmarshalJSON(data []byte) error {
+	var stored bufferedPage
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+
+	*p = pageRecord{
+		title:        stored.Title,
+		markdown:     stored.Markdown,
+		filename:     stored.Filename,
+		pageURL:      stored.PageURL,
+		links:        stored.Links,
+		aliases:      stored.Aliases,
+		redirects:    stored.Redirects,
+		fetchedAt:    stored.FetchedAt,
+		status:       stored.Status,
+		originalFile: stored.OriginalFile,
+		renderData:   stored.RenderData,
+		next:         stored.Next,
+		prev:         stored.Prev,
+		hubLinks:     stored.HubLinks,
+	}
+	return nil
+}
+
+// savedPage is the outcome of saving a page
+type savedPage struct {
+	entry     manifest.Entry
+	document  export.Document
+	location  string // Output location of the file
+	written   int    // Bytes written, 0 when the file was unchanged
+	stored    bool   // True when the page was only stored, with --store-only
+	unchanged bool   // True when the file already held the page
+	kept      bool   // True when an existing file was kept, with --no-clobber
+}
+
+// wrote reports whether the page was written to its file
+func (s savedPage) wrote() bool {
+	return !s.stored && !s.unchanged && !s.kept
+}
+
+// fileSet is a set of file names safe for concurrent use
+type fileSet struct {
+	mutex sync.Mutex
+	files map[string]bool
+}
+
+// add records a file name
+func (f *fileSet) add(name string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.files[name] = true
+}
+
+// has reports whether a file name was recorded
+func (f *fileSet) has(name string) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.files[name]
+}
+
+// crawlResult summarizes a single crawl run
+type crawlResult struct {
+	pagesCrawled int
+	pagesSaved   int
+	changes      manifest.Changes
+	failures     []pageFailure // Pages that could not be converted, post-processed, rendered, or saved
+
+	requestErrors int // Failed requests, as listed in errors.json
+}
+
+// finishRun performs the post-crawl steps shared by single and watch runs
+func finishRun(options *getOptions, startURL string, result crawlResult) error {
+	if options.gitCommit {
+		if err := commitOutput(options.outputDir, startURL, result); err != nil {
+			return fmt.Errorf("git commit: %w", err)
+		}
+	}
+
+	if options.archivePath != "" {
+		if err := archive.Create(options.archivePath, options.outputDir); err != nil {
+			return fmt.Errorf("archive output: %w", err)
+		}
+		printStdout("Archived output to %s\n", options.archivePath)
+
+		if options.archiveCleanup {
+			if err := os.RemoveAll(options.outputDir); err != nil {
+				return fmt.Errorf("remove output directory: %w", err)
+			}
+			printStdout("Removed output directory %s\n", options.outputDir)
+		}
+	}
+
+	return nil
+}
 
-	conv, err := converter.NewConverter(converterOpts)
+// crawlOnce performs a single crawl run and returns its statistics and the changes compared to the previous run,
+// notifying the --webhook of its start and its end
+func crawlOnce(options *getOptions, startURL string, isSingle bool) (crawlResult, error) {
+	notifier := newNotifier(options)
+	defer notifier.Close()
+	notifier.Send(webhook.Event{Type: webhook.CrawlStarted, URL: startURL})
+
+	stats := newRunStats()
+	result, err := runCrawl(options, startURL, isSingle, stats, notifier)
 	if err != nil {
-		return fmt.Errorf("create converter: %w", err)
+		notifier.Send(webhook.Event{Type: webhook.CrawlFailed, URL: startURL, Error: err.Error()})
+		return result, err
+	}
+
+	notifier.Send(webhook.Event{
+		Type:    webhook.CrawlFinished,
+		URL:     startURL,
+		Text:    fmt.Sprintf("Crawl of %s finished: %d pages saved, %d failed, %d request errors", startURL, result.pagesSaved, len(result.failures), result.requestErrors),
+		Summary: stats.summary(startURL, options.outputDir, result),
+	})
+	return result, nil
+}
+
+// runCrawl performs the crawl run of crawlOnce
+func runCrawl(options *getOptions, startURL string, isSingle bool, stats *runStats, notifier *webhook.Notifier) (crawlResult, error) {
+	var writer output.Writer
+	previousManifest := manifest.New()
+	currentManifest := manifest.New()
+
+	if !options.storeOnly {
+		var err error
+		writer, err = output.Open(options.outputDir, options.permissions())
+		if err != nil {
+			return crawlResult{}, fmt.Errorf("open output: %w", err)
+		}
+
+		previousManifest, err = loadManifest(writer)
+		if err != nil {
+			return crawlResult{}, err
+		}
+	}
+
+	var pageStore storage.Store
+	if options.store != "" {
+		var err error
+		pageStore, err = storage.Open(options.store)
+		if err != nil {
+			return crawlResult{}, fmt.Errorf("open store: %w", err)
+		}
+		defer func() { _ = pageStore.Close() }()
+	}
+
+	var vectorStore vector.Store
+	if options.vectorStore != "" {
+		var err error
+		vectorStore, err = vector.Open(options.vectorStore)
+		if err != nil {
+			return crawlResult{}, fmt.Errorf("open vector store: %w", err)
+		}
+	}
+
+	conv, err := converter.NewConverter(converterOptions(options))
+	if err != nil {
+		return crawlResult{}, fmt.Errorf("create converter: %w", err)
+	}
+
+	pageFlavor, err := pageFlavorOf(options)
+	if err != nil {
+		return crawlResult{}, err
+	}
+
+	// The path strategy keeps the layout of the flavor, other strategies name files directly
+	var filenameStrategy converter.FilenameStrategy
+	if options.filenameFrom != "" && options.filenameFrom != converter.FilenameFromPath {
+		filenameStrategy, err = converter.GetFilenameStrategy(options.filenameFrom)
+		if err != nil {
+			return crawlResult{}, err
+		}
 	}
 
 	urlToFile := make(map[string]string)
 	var urlToFileMutex sync.Mutex
 
-	pageData := make(map[string]struct {
-		markdown string
-		filename string
-		pageURL  string
-	})
-	var pageDataMutex sync.Mutex
+	// Converted pages wait for link rewriting in memory, or in a database file under --spill-dir for large crawls
+	pageData, err := pagebuffer.Open[pageRecord](options.spillDir)
+	if err != nil {
+		return crawlResult{}, fmt.Errorf("open page buffer: %w", err)
+	}
+	defer func() { _ = pageData.Close() }()
 
 	pageCount := 0
 	var pageCountMutex sync.Mutex
 
+	limits, err := pathLimits(options)
+	if err != nil {
+		return crawlResult{}, fmt.Errorf("parse path limits: %w", err)
+	}
+
+	priorities, err := priorityRules(options)
+	if err != nil {
+		return crawlResult{}, fmt.Errorf("parse priorities: %w", err)
+	}
+
+	embeds, err := embedRules(options)
+	if err != nil {
+		return crawlResult{}, fmt.Errorf("parse embed rules: %w", err)
+	}
+
+	hubs, err := hubPatterns(options)
+	if err != nil {
+		return crawlResult{}, fmt.Errorf("parse hub patterns: %w", err)
+	}
+
 	crawlerOpts := crawler.Options{
 		MaxDepth:            options.maxDepth,
 		UserAgent:           options.userAgent,
 		IgnoreRobotsTxt:     options.ignoreRobotsTxt,
+		IgnoreMetaRobots:    options.ignoreMetaRobots,
 		FollowExternalLinks: options.followExternalLinks,
+		IncludeSubdomains:   options.includeSubdomains,
+		DistinctSchemes:     options.distinctSchemes,
+		MaxBodySize:         int(options.maxBodySize),
+		TruncateOversized:   options.truncateOversized,
+		PathLimits:          limits,
+		Priorities:          priorities,
+		MaxPages:            options.maxPages,
+		MaxDuration:         options.maxDuration,
+		EmbedPolicy:         crawler.EmbedPolicy(options.embeds),
+		EmbedRules:          embeds,
+		KeepStatuses:        options.keepStatuses,
+		FollowStatuses:      options.followStatuses,
+		Retries:             options.retries,
+		MaxErrorRate:        options.maxErrorRate,
+		Verbosity:           verbosity(options),
+		AcceptEncodings:     options.acceptEncodings,
+		DisableKeepAlives:   options.noKeepAlive,
+		MaxIdleConnsPerHost: options.maxIdleConnsPerHost,
+		DisableHTTP2:        options.noHTTP2,
+		TLSMinVersion:       options.tlsMinVersion,
+		CACertFile:          options.caCert,
+		InsecureSkipVerify:  options.insecureSkipVerify,
+		DenyPrivateNetworks: options.denyPrivateNetworks,
+		AllowedNetworks:     options.allowNetworks,
+		DeniedNetworks:      options.denyNetworks,
 		SinglePage:          isSingle,
 		RequestTimeout:      options.requestTimeout,
 		RequestDelay:        options.requestDelay,
+		Jitter:              options.jitter.crawlerJitter(),
+		Parallelism:         options.concurrency,
 		ExcludedPaths:       options.excludedPaths,
+		Scope:               options.scope,
+		Traversal:           options.traversal,
+		Deterministic:       options.deterministic,
+		NavSelector:         options.navSelector,
+		CanonicalOnly:       options.canonicalOnly,
+		FollowPagination:    options.followPagination,
+		StripSelectors:      stripSelectors(options),
+		RequireSelectors:    options.requireSelectors,
+		ExternalDomains:     options.allowDomains,
+		DeniedDomains:       options.denyDomains,
+		ExternalDepth:       options.externalDepth,
+		SpillDir:            options.spillDir,
+		HostAddresses:       hostAddresses(options),
+	}
+	if options.config != nil {
+		crawlerOpts.Domains = options.config.Domains
+		crawlerOpts.URLRewrites = options.config.URLRewrites
+	}
+
+	if options.redisURL != "" {
+		shared, err := frontier.NewRedis(options.redisURL, frontier.RedisOptions{Key: redisKey(options, startURL)})
+		if err != nil {
+			return crawlResult{}, fmt.Errorf("open shared frontier: %w", err)
+		}
+		defer func() { _ = shared.Close() }()
+		crawlerOpts.Frontier = shared
+		options.logf("Shared frontier: %s\n", redisKey(options, startURL))
 	}
 
 	c, err := crawler.NewCrawler(startURL, crawlerOpts)
 	if err != nil {
-		return fmt.Errorf("create crawler: %w", err)
+		return crawlResult{}, fmt.Errorf("create crawler: %w", err)
 	}
+	defer func() { _ = c.Close() }()
 
-	c.OnPage(func(page crawler.Page) {
-		pageCountMutex.Lock()
-		pageCount++
-		currentCount := pageCount
-		pageCountMutex.Unlock()
+	if options.config != nil && len(options.config.ContentRules) > 0 {
+		hook, err := crawler.NewContentRuleHook(options.config.ContentRules)
+		if err != nil {
+			return crawlResult{}, fmt.Errorf("create content rules: %w", err)
+		}
+		c.AddContentHook(hook)
+	}
+
+	// Evaluated after the content rules, so that the word count ignores removed elements
+	if rules := skipRules(options); !rules.IsZero() {
+		hook, err := crawler.NewSkipRuleHook(rules, options.logf)
+		if err != nil {
+			return crawlResult{}, fmt.Errorf("create skip rules: %w", err)
+		}
+		c.AddContentHook(hook)
+	}
+
+	renderer, err := newRenderer(options)
+	if err != nil {
+		return crawlResult{}, err
+	}
+	crawlInfo := render.Crawl{StartURL: startURL, StartedAt: time.Now().UTC(), Version: version}
+
+	var replacer *converter.Replacer
+	if options.config != nil && len(options.config.Replacements) > 0 {
+		replacer, err = converter.NewReplacer(options.config.Replacements)
+		if err != nil {
+			return crawlResult{}, fmt.Errorf("create replacements: %w", err)
+		}
+	}
+
+	postprocessor := newPostprocessor(options)
+
+	var tagger *tagging.Tagger
+	if options.config != nil && len(options.config.Tags) > 0 {
+		tagger, err = tagging.NewTagger(options.config.Tags)
+		if err != nil {
+			return crawlResult{}, fmt.Errorf("create tag rules: %w", err)
+		}
+	}
+
+	tracker := progress.NewTracker()
+	stopProgress := startProgress(options, tracker, writer)
+	defer stopProgress()
+
+	// A page that is already in the output is only rewritten when the server reports it as modified
+	var conditional *conditionalRequests
+	if isSingle && writer != nil {
+		conditional = &conditionalRequests{writer: writer, previous: previousManifest}
+		conditional.register(c)
+	}
+
+	var images *imageDownloader
+	if options.downloadImages && writer != nil {
+		images = newImageDownloader(c.FetchAsset, writer, pageFlavor.AssetsDir(), imageProcessing(options))
+	}
+
+	var files *fileDownloader
+	if (len(options.downloadExtensions) > 0 || options.media == converter.MediaDownload) && writer != nil {
+		files = newFileDownloader(c.FetchAssetLimited, writer, filesDir(pageFlavor), int64(options.maxMediaSize))
+	}
+
+	c.OnError(tracker.Failed)
+	c.OnError(func(string, error) { options.metrics.RequestFailed() })
+	c.OnError(func(requestURL string, err error) {
+		notifier.Send(webhook.Event{Type: webhook.RequestFailed, URL: requestURL, Error: err.Error()})
+	})
+	// Pages not requested because of the budget may still exist, so --prune leaves their files alone
+	var budgetHit atomic.Bool
+	c.OnSkip(func(_ string, reason crawler.SkipReason) {
+		// Not modified pages are counted as unchanged
+		if reason != crawler.SkipUnchanged {
+			stats.skip(string(reason))
+		}
+		if reason == crawler.SkipBudget {
+			budgetHit.Store(true)
+		}
+	})
+	c.OnResponse(func(resp *crawler.Response) {
+		stats.download(len(resp.Body))
+		options.metrics.Downloaded(len(resp.Body))
+	})
+
+	var failures pageFailures
+	failPage := func(pageURL, stage string, err error) {
+		failures.add(pageURL, stage, err)
+		tracker.Failed(pageURL, err)
+		options.metrics.PageFailed()
+		notifier.Send(webhook.Event{Type: webhook.PageFailed, URL: pageURL, Stage: stage, Error: err.Error()})
+	}
+
+	// Files written by this run, which --no-clobber does not protect, as --stream saves pages again
+	writtenFiles := &fileSet{files: make(map[string]bool)}
+
+	// savePage rewrites the links of a page and writes it, or only stores it with --store-only.
+	// The file is not written again when it matches previous, the manifest entry it was last saved with.
+	savePage := func(data pageRecord, previous manifest.Entry, known bool) (savedPage, bool) {
+		urlToFileMutex.Lock()
+		markdown := pageFlavor.RewriteLinks(data.markdown, data.pageURL, urlToFile)
+		urlToFileMutex.Unlock()
+
+		rendered, err := renderOutput(options.format, data.title, markdown)
+		if err != nil {
+			printStderr("  Error rendering page: %v\n", err)
+			failPage(data.pageURL, stageRender, err)
+			return savedPage{}, false
+		}
+
+		saved := savedPage{
+			location: writer.Location(data.filename),
+			entry: manifest.Entry{
+				URL:       data.pageURL,
+				File:      data.filename,
+				Hash:      manifest.HashContent([]byte(rendered)),
+				FetchedAt: data.fetchedAt,
+				Redirects: data.redirects,
+				Tags:      data.renderData.Tags,
+				Summary:   data.renderData.Summary,
 
-		printStdout("[%d] Crawling: %s\n", currentCount, page.URL)
+				OriginalFile: data.originalFile,
+			},
+		}
+		if data.status != http.StatusOK {
+			saved.entry.Status = data.status
+		}
+		if !data.renderData.Metadata.IsZero() {
+			pageMetadata := data.renderData.Metadata
+			saved.entry.Metadata = &pageMetadata
+		}
+
+		if pageStore != nil {
+			if err := storePage(pageStore, data, markdown, saved.entry); err != nil {
+				printStderr("  Error storing page: %v\n", err)
+				if options.storeOnly {
+					return savedPage{}, false
+				}
+			}
+		}
+
+		if options.storeOnly {
+			saved.stored = true
+			return saved, true
+		}
+
+		saved.document = export.Document{
+			URL:       data.pageURL,
+			Title:     data.title,
+			File:      data.filename,
+			Markdown:  markdown,
+			Summary:   data.renderData.Summary,
+			Links:     data.links,
+			FetchedAt: data.fetchedAt,
+		}
+
+		if known && !options.force && isUnchanged(writer, previous, saved.entry) {
+			saved.unchanged = true
+			return saved, true
+		}
+
+		// With --no-clobber, files that existed before the run are kept and recorded with their own hash
+		if options.noClobber && !writtenFiles.has(data.filename) {
+			if existing, err := writer.ReadFile(data.filename); err == nil {
+				saved.entry.Hash = manifest.HashContent(existing)
+				saved.kept = true
+				return saved, true
+			}
+		}
+
+		if err := writer.WriteFile(data.filename, []byte(rendered)); err != nil {
+			printStderr("  Error saving file: %v\n", err)
+			failPage(data.pageURL, stageSave, err)
+			return savedPage{}, false
+		}
+		writtenFiles.add(data.filename)
+		saved.written = len(rendered)
+		return saved, true
+	}
+
+	hooks := newPageHooks(options)
+	defer hooks.wait()
+
+	// reportSaved logs a saved page, counts it in the progress, metrics, and summary, and runs --exec-per-page
+	// for written files unless deferHook is set
+	reportSaved := func(pageURL string, saved savedPage, deferHook bool) {
+		switch {
+		case saved.stored:
+			options.logf("  Stored: %s\n", pageURL)
+		case saved.unchanged:
+			options.logf("  Unchanged: %s\n", saved.location)
+			stats.save(saved.location, 0)
+		case saved.kept:
+			options.logf("  Kept existing file: %s\n", saved.location)
+			stats.save(saved.location, 0)
+		default:
+			options.logf("  Saved: %s\n", saved.location)
+			stats.save(saved.location, saved.written)
+			if !deferHook {
+				hooks.run(saved.location, pageURL)
+			}
+		}
+		tracker.Saved(pageURL)
+		options.metrics.PageSaved()
+		notifier.Send(webhook.Event{Type: webhook.PageSaved, URL: pageURL, File: saved.location, Unchanged: saved.unchanged || saved.kept})
+	}
 
-		markdown, err := conv.Convert(page.Content)
+	// With --stream, pages are saved as soon as they are converted instead of after the crawl
+	var stream *pageStream
+	if options.stream {
+		pending, err := pagebuffer.Open[pageRecord](options.spillDir)
+		if err != nil {
+			return crawlResult{}, fmt.Errorf("open page buffer: %w", err)
+		}
+		defer func() { _ = pending.Close() }()
+		stream = newPageStream(savePage, pending, len(buildExporters(options, startURL, vectorStore)) > 0)
+	}
+
+	// Pages are converted on worker goroutines, so that fetching is not held up by CPU-bound conversions
+	pool := newConversionPool(conversionWorkers(options), func(page crawler.Page) {
+		content := page.Content
+		if replacer != nil {
+			content = replacer.HTML(page.URL, content)
+		}
+		if options.extractDataURIs {
+			var assets []converter.Asset
+			content, assets = converter.ExtractDataURIs(content, options.dataURIThreshold, pageFlavor.AssetsDir())
+			for _, asset := range assets {
+				if writer == nil {
+					continue
+				}
+				if err := saveAsset(writer, asset); err != nil {
+					printStderr("  Error saving asset: %v\n", err)
+				}
+			}
+		}
+
+		if images != nil {
+			content = images.localize(content, page.URL)
+		}
+		if files != nil {
+			content = converter.LocalizeFiles(content, page.URL, options.downloadExtensions, files.download)
+			if options.media == converter.MediaDownload {
+				content = converter.LocalizeMedia(content, page.URL, files.downloadMedia)
+			}
+		}
+
+		if options.tables == converter.TableCSV {
+			var tables []converter.Asset
+			content, tables = converter.ExtractComplexTables(content, pageFlavor.AssetsDir())
+			for _, table := range tables {
+				if writer == nil {
+					continue
+				}
+				if err := saveAsset(writer, table); err != nil {
+					printStderr("  Error saving table: %v\n", err)
+				}
+			}
+		}
+
+		markdown, err := conv.Convert(content)
 		if err != nil {
 			printStderr("  Error converting page: %v\n", err)
+			failPage(page.URL, stageConvert, err)
+			return
+		}
+		if replacer != nil {
+			markdown = replacer.Markdown(page.URL, markdown)
+		}
+		if postprocessor != nil {
+			markdown, err = postprocessor.Process(postprocess.Page{URL: page.URL, Title: page.Title, Markdown: markdown})
+			if err != nil {
+				printStderr("  Error post-processing page: %v\n", err)
+				failPage(page.URL, stagePostprocess, err)
+				return
+			}
+		}
+
+		if options.maxMarkdownSize > 0 && len(markdown) > int(options.maxMarkdownSize) {
+			options.logf("  Skipped (Markdown of %d bytes exceeds --max-markdown-size): %s\n", len(markdown), page.URL)
+			stats.skip(skipMarkdownSize)
 			return
 		}
 
-		filename := converter.GenerateFilename(page.URL)
+		pageLang := lang.Resolve(page.Lang, markdown)
+		if len(options.languages) > 0 && pageLang != "" && !lang.Matches(pageLang, options.languages) {
+			options.logf("  Skipped (language %s): %s\n", pageLang, page.URL)
+			stats.skip(skipLanguage)
+			return
+		}
+
+		filename := pageFlavor.Filename(page.URL)
+		if filenameStrategy != nil {
+			filename = filenameStrategy(page)
+		}
+		if options.splitByLang {
+			dir := pageLang
+			if dir == "" {
+				dir = lang.Undetermined
+			}
+			filename = flavor.LanguageFilename(pageFlavor, filename, dir)
+		}
+		if options.format == formatHTMLSite {
+			filename = htmlsite.Filename(filename)
+		}
+		if !options.utf8Filenames {
+			filename = converter.TransliterateFilename(filename)
+		}
+		filename = converter.LimitFilename(converter.PortableFilename(filename), options.maxFilenameLength)
 		normalizedURL := strings.TrimSuffix(page.URL, "/")
 
+		// Links to aliases and redirected URLs point at the file of the final page
 		urlToFileMutex.Lock()
+		originalFile := ""
+		if stream != nil {
+			filename, originalFile = stream.claim(filename, normalizedURL, page.URL)
+		}
 		urlToFile[normalizedURL] = filename
+		aliases := make([]string, 0, len(page.Aliases))
+		for _, alias := range page.Aliases {
+			aliases = append(aliases, strings.TrimSuffix(alias, "/"))
+			urlToFile[strings.TrimSuffix(alias, "/")] = filename
+		}
 		urlToFileMutex.Unlock()
 
-		header := fmt.Sprintf("# %s\n\nURL: %s\n\n---\n\n", page.Title, page.URL)
-		markdown = header + markdown
+		fetchedAt := time.Now().UTC()
+		pageRenderData := render.NewData(page.URL, page.Title, filename, markdown, fetchedAt)
+		pageRenderData.Lang = pageLang
+		pageRenderData.Canonical = page.Canonical
+		pageRenderData.Alternates = page.Alternates
+		pageRenderData.Metadata = page.Metadata
+		pageRenderData.Status = page.Status
+		if tagger != nil {
+			pageRenderData.Tags = tagger.Tags(page.URL, page.Content)
+		}
+		if options.summarize > 0 {
+			pageRenderData.Summary = summary.Summarize(markdown, page.Metadata.Description, options.summarize)
+		}
+		pageRenderData.Crawl = crawlInfo
+		markdown, err = buildPageContent(renderer, pageFlavor, pageRenderData)
+		if err != nil {
+			printStderr("  Error rendering template: %v\n", err)
+			failPage(page.URL, stageTemplate, err)
+			return
+		}
+
+		record := pageRecord{
+			title:     page.Title,
+			markdown:  markdown,
+			filename:  filename,
+			pageURL:   page.URL,
+			links:     page.Links,
+			aliases:   aliases,
+			fetchedAt: fetchedAt,
+			status:    page.Status,
+
+			originalFile: originalFile,
+
+			renderData: pageRenderData,
+			next:       strings.TrimSuffix(page.Next, "/"),
+			prev:       strings.TrimSuffix(page.Prev, "/"),
+		}
+		if isHub(hubs, page.URL) {
+			record.hubLinks = contentLinks(page.Content, page.URL)
+		}
+
+		if stream != nil {
+			// Links are checked before they are rewritten, so a page crawled in between is patched rather than missed
+			urlToFileMutex.Lock()
+			unresolved := unresolvedLinks(record.links, urlToFile, func(link string) bool { return c.Follows(page, link) })
+			urlToFileMutex.Unlock()
+
+			stats.convert()
+			previous, known := previousManifest.Lookup(record.pageURL)
+			if saved, ok := stream.add(normalizedURL, record, previous, known, unresolved); ok {
+				// The command of a page whose links may still be patched runs once its file is final
+				reportSaved(page.URL, saved, stream.deferred(normalizedURL))
+			}
+			return
+		}
 
-		pageDataMutex.Lock()
-		pageData[normalizedURL] = struct {
-			markdown string
-			filename string
-			pageURL  string
-		}{
-			markdown: markdown,
-			filename: filename,
-			pageURL:  page.URL,
+		if err := pageData.Put(normalizedURL, record); err != nil {
+			printStderr("  Error buffering page: %v\n", err)
+			failPage(page.URL, stageSave, err)
+			return
 		}
-		pageDataMutex.Unlock()
+		stats.convert()
+		options.metrics.QueueAdd(1)
 	})
 
-	if err := c.Start(); err != nil {
-		return fmt.Errorf("crawl: %w", err)
+	c.OnPage(func(page crawler.Page) {
+		pageCountMutex.Lock()
+		pageCount++
+		currentCount := pageCount
+		pageCountMutex.Unlock()
+
+		tracker.Crawled(page.URL)
+		options.metrics.PageFetched()
+
+		options.logf("[%d] Crawling: %s\n", currentCount, page.URL)
+		pool.submit(page)
+	})
+
+	// Pages crawled before the error rate was exceeded are still saved, and the error is returned afterwards
+	crawlErr := c.Start()
+	pool.wait()
+	stats.crawlDone()
+	var rateErr *crawler.ErrorRateError
+	if crawlErr != nil && !errors.As(crawlErr, &rateErr) {
+		options.metrics.QueueAdd(-pageData.Len())
+		return crawlResult{}, fmt.Errorf("crawl: %w", crawlErr)
 	}
 
 	pageCountMutex.Lock()
 	finalPageCount := pageCount
 	pageCountMutex.Unlock()
 
-	printStdout("\nCrawled %d pages. Converting links and saving files...\n\n", finalPageCount)
+	options.logf("\nCrawled %d pages. Converting links and saving files...\n\n", finalPageCount)
 
 	successCount := 0
 	processedCount := 0
+	var documents []export.Document
+	navigation := navigationIndex(c.Navigation())
 
-	pageDataMutex.Lock()
-	pageDataCopy := make(map[string]struct {
-		markdown string
-		filename string
-		pageURL  string
-	})
-	for key, value := range pageData {
-		pageDataCopy[key] = value
+	// Boilerplate is counted on the pages as crawled, before paginated series are merged into one page
+	var boilerplateBlocks boilerplateReport
+	if options.stripBoilerplate > 0 {
+		boilerplateBlocks = stripBoilerplate(pageData, options.stripBoilerplate, options.summarize, renderer, pageFlavor)
 	}
-	pageDataMutex.Unlock()
 
-	for _, data := range pageDataCopy {
-		processedCount++
-		printStdout("[%d/%d] Processing: %s\n", processedCount, len(pageDataCopy), data.pageURL)
+	// Collisions are resolved before merging, as merged pages intentionally share a file
+	urlToFileMutex.Lock()
+	resolveFilenameCollisions(pageData, urlToFile, renderer, pageFlavor)
+	urlToFileMutex.Unlock()
 
+	if options.mergePagination {
 		urlToFileMutex.Lock()
-		urlToFileCopy := make(map[string]string)
-		for key, value := range urlToFile {
-			urlToFileCopy[key] = value
-		}
+		mergePaginatedPages(pageData, urlToFile, renderer, pageFlavor)
 		urlToFileMutex.Unlock()
+	}
 
-		markdown := converter.ConvertLinksToLocal(data.markdown, data.pageURL, urlToFileCopy)
-		outputPath := filepath.Join(options.outputDir, data.filename)
+	// Merged pages are resolved through urlToFile, so redirects and rewrites are applied after merging
+	urlToFileMutex.Lock()
+	applyRedirects(c.Redirects(), pageData, urlToFile)
+	applyURLRewrites(c.RewrittenURLs(), urlToFile)
+	urlToFileMutex.Unlock()
 
-		if err := os.WriteFile(outputPath, []byte(markdown), 0o600); err != nil {
-			printStderr("  Error saving file: %v\n", err)
+	// Hub indexes list the pages as merged, under the titles they are saved with
+	if len(hubs) > 0 {
+		urlToFileMutex.Lock()
+		buildHubIndexes(pageData, urlToFile, hubs, renderer, pageFlavor)
+		urlToFileMutex.Unlock()
+	}
+
+	tracker.StartSaving(pageData.Len())
+
+	for _, key := range pageData.Keys() {
+		data, ok, err := pageData.Get(key)
+		if err != nil {
+			printStderr("  Error reading buffered page: %v\n", err)
+			failPage(key, stageSave, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		processedCount++
+		options.metrics.QueueAdd(-1)
+		options.logf("[%d/%d] Processing: %s\n", processedCount, pageData.Len(), data.pageURL)
+
+		previous, known := previousManifest.Lookup(data.pageURL)
+		saved, ok := savePage(data, previous, known)
+		if !ok {
 			continue
 		}
 
-		printStdout("  Saved: %s\n", outputPath)
+		saved.entry = navigation.apply(saved.entry)
+		saved.document.NavPosition = saved.entry.NavPosition
+		saved.document.NavParent = saved.entry.NavParent
+		if !options.storeOnly {
+			currentManifest.Add(saved.entry)
+			documents = append(documents, saved.document)
+		}
+		reportSaved(data.pageURL, saved, false)
 		successCount++
 	}
 
+	if stream != nil {
+		// Saved pages linking to pages crawled after them are saved again, now that the redirects are known too.
+		// The conversion workers have stopped, so urlToFile is only read from here on.
+		patched := stream.patch(func(key string) bool {
+			_, exists := urlToFile[key]
+			return exists
+		}, options.logf)
+		stream.addRedirects(c.Redirects())
+		options.logf("Patched the links of %d saved pages\n", patched)
+		for _, saved := range stream.hookPages() {
+			hooks.run(saved.location, saved.entry.URL)
+		}
+
+		for _, saved := range stream.pages() {
+			saved.entry = navigation.apply(saved.entry)
+			saved.document.NavPosition = saved.entry.NavPosition
+			saved.document.NavParent = saved.entry.NavParent
+			if !options.storeOnly {
+				currentManifest.Add(saved.entry)
+				if saved.document.URL != "" {
+					documents = append(documents, saved.document)
+				}
+			}
+			successCount++
+		}
+	}
+
+	if conditional != nil {
+		for _, entry := range conditional.entries() {
+			entry = navigation.apply(entry)
+			currentManifest.Add(entry)
+			if document, ok := unchangedDocument(options, writer, entry); ok {
+				documents = append(documents, document)
+			}
+			options.logf("  Not modified: %s\n", writer.Location(entry.File))
+			stats.save(writer.Location(entry.File), 0)
+			tracker.Saved(entry.URL)
+			options.metrics.PageSaved()
+			notifier.Send(webhook.Event{Type: webhook.PageSaved, URL: entry.URL, File: writer.Location(entry.File), Unchanged: true})
+			successCount++
+		}
+	}
+
+	if failed := hooks.wait(); failed > 0 {
+		printStderr("%d --exec-per-page commands failed\n", failed)
+	}
+
 	printStdout("\nSuccessfully processed %d pages\n", successCount)
 
+	result := crawlResult{
+		pagesCrawled: finalPageCount,
+		pagesSaved:   successCount,
+		failures:     failures.list(),
+
+		requestErrors: len(c.Errors()),
+	}
+	if len(result.failures) > 0 {
+		printStderr("%s", formatFailures(result.failures))
+	}
+
+	// The summary is written last, so that it covers the exports, also when the error rate was exceeded
+	writeSummary := func() error {
+		if options.summaryPath == "" {
+			return nil
+		}
+		return saveSummary(options.summaryPath, stats.summary(startURL, options.outputDir, result))
+	}
+
+	if options.storeOnly {
+		return result, writeSummary()
+	}
+
+	if indexer, ok := pageFlavor.(flavor.SectionIndexer); ok {
+		if err := saveSectionIndexes(writer, indexer, currentManifest); err != nil {
+			return crawlResult{}, err
+		}
+	}
+
+	if err := saveManifest(writer, currentManifest); err != nil {
+		return crawlResult{}, err
+	}
+
+	if err := saveErrors(writer, c.Errors()); err != nil {
+		return crawlResult{}, err
+	}
+
+	if options.boilerplateReport {
+		if err := saveBoilerplateReport(writer, boilerplateBlocks); err != nil {
+			return crawlResult{}, err
+		}
+	}
+
+	if options.prune {
+		if reason := pruneBlocker(c.Errors(), result.failures, budgetHit.Load()); reason != "" {
+			printStderr("Not pruning stale files: %s\n", reason)
+		} else {
+			printStdout("Pruned %d stale files\n", pruneStaleFiles(writer, previousManifest, currentManifest, options.logf))
+		}
+	}
+
+	for _, exporter := range buildExporters(options, startURL, vectorStore) {
+		if err := exporter.Export(documents, writer); err != nil {
+			return crawlResult{}, fmt.Errorf("export %s: %w", exporter.Name(), err)
+		}
+		printStdout("Exported %s\n", exporter.Name())
+		stats.export(exporter.Name())
+	}
+
+	result.changes = manifest.Diff(previousManifest, currentManifest)
+
+	if err := writeSummary(); err != nil {
+		return crawlResult{}, err
+	}
+
+	if rateErr != nil {
+		return result, fmt.Errorf("crawl: %w", rateErr)
+	}
+
+	return result, nil
+}
+
+// startProgress periodically writes the progress file when enabled and returns a function writing the final state
+func startProgress(options *getOptions, tracker *progress.Tracker, writer output.Writer) func() {
+	if !options.progress || writer == nil {
+		return func() {}
+	}
+
+	write := func(data []byte) {
+		if err := writer.WriteFile(progress.Filename, data); err != nil {
+			printStderr("Error writing progress: %v\n", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tracker.Run(ctx, options.progressInterval, write)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+
+		tracker.Finish()
+		data, err := tracker.Encode()
+		if err != nil {
+			printStderr("Error writing progress: %v\n", err)
+			return
+		}
+		write(data)
+	}
+}
+
+// storePage persists a page and its outgoing links in the configured store
+func storePage(pageStore storage.Store, data pageRecord, markdown string, entry manifest.Entry) error {
+	err := pageStore.SavePage(storage.Page{
+		URL:       data.pageURL,
+		Title:     data.title,
+		File:      data.filename,
+		Markdown:  markdown,
+		Hash:      entry.Hash,
+		Metadata:  storedMetadata(data.renderData),
+		FetchedAt: data.fetchedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return pageStore.SaveLinks(data.pageURL, data.links)
+}
+
+// storedMetadata flattens the metadata, tags, summary, and status of a page into the metadata of the store.
+// Open Graph and Twitter card fields keep their og: and twitter: prefixes, and JSON-LD blocks are stored as a JSON array.
+func storedMetadata(data render.Data) map[string]string {
+	stored := make(map[string]string)
+	set := func(key, value string) {
+		if value != "" {
+			stored[key] = value
+		}
+	}
+
+	set("lang", data.Lang)
+	set("canonical", data.Canonical)
+	set("summary", data.Summary)
+	set("tags", strings.Join(data.Tags, ","))
+	if data.Status != 0 {
+		set("status", strconv.Itoa(data.Status))
+	}
+
+	meta := data.Metadata
+	set("description", meta.Description)
+	set("image", meta.Image)
+	set("type", meta.Type)
+	set("site_name", meta.SiteName)
+	set("author", meta.Author)
+	set("published_time", meta.PublishedTime)
+	set("modified_time", meta.ModifiedTime)
+	for key, value := range meta.OpenGraph {
+		set("og:"+key, value)
+	}
+	for key, value := range meta.Twitter {
+		set("twitter:"+key, value)
+	}
+	if len(meta.JSONLD) > 0 {
+		if encoded, err := json.Marshal(meta.JSONLD); err == nil {
+			set("json_ld", string(encoded))
+		}
+	}
+	return stored
+}
+
+// newRenderer compiles the page templates of the configuration file followed by --template, which renders
+// the pages no configured pattern matches, and the front matter mapping of the configuration file.
+// It returns nil when there are neither templates nor a mapping.
+func newRenderer(options *getOptions) (*render.Renderer, error) {
+	var rules []render.Rule
+	var fields []render.FrontMatterField
+	baseDir := ""
+	if options.config != nil {
+		rules = append(rules, options.config.Templates...)
+		fields = options.config.FrontMatter
+		baseDir = options.config.baseDir
+	}
+	if options.templateFile != "" {
+		// The file is relative to the working directory, unlike the template files of the configuration
+		path, err := filepath.Abs(options.templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("resolve template: %w", err)
+		}
+		rules = append(rules, render.Rule{Pattern: "*", TemplateFile: path})
+	}
+	if len(rules) == 0 && len(fields) == 0 {
+		return nil, nil
+	}
+
+	renderer, err := render.NewRenderer(rules, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("create templates: %w", err)
+	}
+	if err := renderer.MapFrontMatter(fields); err != nil {
+		return nil, fmt.Errorf("create front matter mapping: %w", err)
+	}
+	return renderer, nil
+}
+
+// buildPageContent applies the matching page template, falling back to the flavor page layout,
+// and sets the mapped front matter fields
+func buildPageContent(renderer *render.Renderer, pageFlavor flavor.Flavor, data render.Data) (string, error) {
+	if renderer == nil {
+		return pageFlavor.Page(data), nil
+	}
+
+	content, matched, err := renderer.Render(data)
+	if err != nil {
+		return "", err
+	}
+	if !matched {
+		content = pageFlavor.Page(data)
+	}
+	return renderer.ApplyFrontMatter(content, data), nil
+}
+
+// pageFlavorOf returns the flavor of the pages, which the mdbook format replaces with the layout of mdBook books
+func pageFlavorOf(options *getOptions) (flavor.Flavor, error) {
+	if options.format == formatMdBook {
+		return flavor.MdBook(), nil
+	}
+	if options.metadataFrontMatter {
+		return flavor.StandardWithMetadata(), nil
+	}
+	return flavor.Get(options.flavor)
+}
+
+// renderOutput produces the file content for a page in the selected output format
+func renderOutput(format, title, markdown string) (string, error) {
+	if format == formatHTMLSite {
+		return htmlsite.Render(title, markdown)
+	}
+	return markdown, nil
+}
+
+// hostAddresses returns the IP addresses of the hosts mapped in the configuration file, overridden by --resolve
+func hostAddresses(options *getOptions) map[string]string {
+	addresses := make(map[string]string)
+	if options.config != nil {
+		for host, ip := range options.config.Hosts {
+			addresses[host] = ip
+		}
+	}
+	for _, value := range options.resolve {
+		if host, ip, err := crawler.ParseHostAddress(value); err == nil {
+			addresses[host] = ip
+		}
+	}
+	return addresses
+}
+
+// pathLimits parses the --limit-path values
+func pathLimits(options *getOptions) ([]crawler.PathLimit, error) {
+	limits := make([]crawler.PathLimit, 0, len(options.limitPaths))
+	for _, value := range options.limitPaths {
+		limit, err := crawler.ParsePathLimit(value)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// redisKey returns the prefix of the Redis keys of a shared crawl, derived from the start host unless --redis-key is set
+func redisKey(options *getOptions, startURL string) string {
+	if options.redisKey != "" {
+		return options.redisKey
+	}
+	host := startURL
+	if parsedURL, err := url.Parse(startURL); err == nil && parsedURL.Host != "" {
+		host = parsedURL.Host
+	}
+	return "crawldown:" + host
+}
+
+// priorityRules parses the --priority values
+func priorityRules(options *getOptions) ([]crawler.PriorityRule, error) {
+	rules := make([]crawler.PriorityRule, 0, len(options.priorities))
+	for _, value := range options.priorities {
+		rule, err := crawler.ParsePriorityRule(value)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// embedRules parses the --embed-rule values
+func embedRules(options *getOptions) ([]crawler.EmbedRule, error) {
+	rules := make([]crawler.EmbedRule, 0, len(options.embedRules))
+	for _, value := range options.embedRules {
+		rule, err := crawler.ParseEmbedRule(value)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// verbosity maps --quiet and --verbose to the crawler log level
+func verbosity(options *getOptions) crawler.Verbosity {
+	switch {
+	case options.quiet:
+		return crawler.VerbosityQuiet
+	case options.verbose:
+		return crawler.VerbosityVerbose
+	default:
+		return crawler.VerbosityNormal
+	}
+}
+
+// skipRules combines the skip rules of the config file with the --skip-title, --skip-content, and --min-words flags
+func skipRules(options *getOptions) crawler.SkipRules {
+	var rules crawler.SkipRules
+	if options.config != nil {
+		rules = options.config.Skip
+	}
+
+	rules.Titles = append(append([]string(nil), rules.Titles...), options.skipTitles...)
+	rules.Content = append(append([]string(nil), rules.Content...), options.skipContent...)
+	if options.minWords > 0 {
+		rules.MinWords = options.minWords
+	}
+	return rules
+}
+
+// stripSelectors returns the selectors of elements removed before content extraction
+func stripSelectors(options *getOptions) []string {
+	var selectors []string
+	if !options.noDefaultStrip {
+		selectors = append(selectors, crawler.DefaultStripSelectors...)
+	}
+	return append(selectors, options.stripSelectors...)
+}
+
+// navigationItems maps page URLs, without trailing slash, to their item in the site navigation
+type navigationItems map[string]crawler.NavItem
+
+func navigationIndex(items []crawler.NavItem) navigationItems {
+	index := make(navigationItems, len(items))
+	for _, item := range items {
+		index[strings.TrimSuffix(item.URL, "/")] = item
+	}
+	return index
+}
+
+// apply records the navigation position and parent of the page of a manifest entry
+func (n navigationItems) apply(entry manifest.Entry) manifest.Entry {
+	item, ok := n[strings.TrimSuffix(entry.URL, "/")]
+	if !ok {
+		return entry
+	}
+	entry.NavPosition = item.Position
+	entry.NavParent = item.Parent
+	return entry
+}
+
+// buildExporters returns the exporters enabled by the options, storing chunks in vectorStore when not nil
+func buildExporters(options *getOptions, startURL string, vectorStore vector.Store) []export.Exporter {
+	var exporters []export.Exporter
+
+	if options.searchIndex {
+		exporters = append(exporters, export.SearchIndexExporter{})
+	}
+
+	if options.format == formatMdBook {
+		exporters = append(exporters, export.MdBookExporter{Dir: flavor.MdBookSourceDir, Site: startURL})
+	}
+
+	if options.docusaurus {
+		exporters = append(exporters, export.DocusaurusExporter{})
+	}
+
+	if options.notion {
+		exporters = append(exporters, export.NotionExporter{})
+	}
+
+	if options.confluence {
+		exporters = append(exporters, export.ConfluenceExporter{})
+	}
+
+	if options.validateMarkdown {
+		exporters = append(exporters, export.ValidationExporter{})
+	}
+
+	if options.llmsTxt {
+		exporters = append(exporters, export.LLMsTxtExporter{Site: startURL})
+	}
+
+	if options.indexFile != "" {
+		exporters = append(exporters, export.IndexExporter{Filename: options.indexFile, Site: startURL})
+	}
+
+	if options.summaryMD {
+		exporters = append(exporters, export.BookSummaryExporter{Site: startURL})
+	}
+
+	if options.elasticsearchBulk {
+		exporters = append(exporters, elasticsearch.FileExporter{Index: options.elasticsearchIndex})
+	}
+
+	if options.elasticsearchURL != "" {
+		exporters = append(exporters, elasticsearch.NewExporter(options.elasticsearchURL, options.elasticsearchIndex))
+	}
+
+	if vectorStore != nil {
+		exporters = append(exporters, vector.Exporter{
+			Store:    vectorStore,
+			Embedder: vector.NewEmbedder(options.embeddingURL, options.embeddingModel),
+			Size:     options.chunkSize,
+			Overlap:  options.chunkOverlap,
+		})
+	}
+
+	return exporters
+}
+
+// loadManifest reads the manifest of the previous run, if any
+func loadManifest(writer output.Writer) (*manifest.Manifest, error) {
+	data, err := writer.ReadFile(manifest.Filename)
+	if errors.Is(err, output.ErrNotExist) {
+		return manifest.New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load manifest: %w", err)
+	}
+
+	m, err := manifest.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("load manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// saveManifest writes the manifest of the current run
+func saveManifest(writer output.Writer, m *manifest.Manifest) error {
+	data, err := m.Encode()
+	if err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+
+	if err := writer.WriteFile(manifest.Filename, data); err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+
+	return nil
+}
+
+// saveSectionIndexes writes the index files a flavor needs for directories without a crawled page
+func saveSectionIndexes(writer output.Writer, indexer flavor.SectionIndexer, m *manifest.Manifest) error {
+	files := make([]string, 0, len(m.Pages))
+	for _, entry := range m.Pages {
+		files = append(files, entry.File)
+	}
+
+	indexes := indexer.SectionIndexes(files)
+	names := make([]string, 0, len(indexes))
+	for name := range indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := writer.WriteFile(name, []byte(indexes[name])); err != nil {
+			return fmt.Errorf("save section index: %w", err)
+		}
+	}
+
 	return nil
 }
+
+// saveAsset writes an extracted asset to the output unless it already exists
+func saveAsset(writer output.Writer, asset converter.Asset) error {
+	exists, err := writer.Exists(asset.Path)
+	if err != nil {
+		return fmt.Errorf("check asset: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	if err := writer.WriteFile(asset.Path, asset.Data); err != nil {
+		return fmt.Errorf("write asset: %w", err)
+	}
+
+	return nil
+}
+
+// isUnchanged reports whether a page produced the same content as in the previous run
+func isUnchanged(writer output.Writer, previous, current manifest.Entry) bool {
+	if previous.File != current.File || previous.Hash != current.Hash {
+		return false
+	}
+
+	exists, err := writer.Exists(current.File)
+	return err == nil && exists
+}
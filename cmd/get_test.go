@@ -0,0 +1,2139 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/export"
+	"github.com/sandrolain/crawldown/src/flavor"
+	"github.com/sandrolain/crawldown/src/manifest"
+	"github.com/sandrolain/crawldown/src/metrics"
+	"github.com/sandrolain/crawldown/src/output"
+	"github.com/sandrolain/crawldown/src/progress"
+	"github.com/sandrolain/crawldown/src/render"
+	"github.com/sandrolain/crawldown/src/tagging"
+	"github.com/sandrolain/crawldown/src/webhook"
+)
+
+func newTestSite(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Read the <a href="/guide">guide</a>.</p></main></body></html>`))
+	})
+	mux.HandleFunc("/guide", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Guide</title></head><body><main><p>Guide content</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestCrawlOnceHTMLSite(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.format = formatHTMLSite
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("reading rendered page: %v", err)
+	}
+
+	page := string(content)
+	if !strings.Contains(page, "<title>Home</title>") {
+		t.Errorf("rendered page is missing the title: %s", page)
+	}
+
+	if !strings.Contains(page, `<a href="guide.html">guide</a>`) {
+		t.Errorf("rendered page does not link to the local HTML file: %s", page)
+	}
+
+	if _, err := os.Stat(filepath.Join(options.outputDir, "guide.html")); err != nil {
+		t.Errorf("linked page was not rendered: %v", err)
+	}
+}
+
+func TestCrawlOnceHTMLSiteRawHTML(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Plans</title></head><body><main><h2 id="section-pricing">Pricing</h2>` +
+			`<table><tr><th>Plan</th><th>Feature</th></tr>` +
+			`<tr><td rowspan="2">Pro</td><td>Backups</td></tr><tr><td>Support</td></tr></table></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.format = formatHTMLSite
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("reading rendered page: %v", err)
+	}
+
+	page := string(content)
+	if strings.Contains(page, "raw HTML omitted") {
+		t.Errorf("rendered page lost raw HTML: %s", page)
+	}
+	for _, want := range []string{`<h2 id="section-pricing">Pricing</h2>`, "<table>", "Pro", "Backups", "Support"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("rendered page is missing %q: %s", want, page)
+		}
+	}
+}
+
+func TestCrawlOnceDocusaurusHeadingAnchors(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Plans</title></head><body><main><h2 id="section-pricing">Pricing</h2><p>Monthly plans.</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.docusaurus = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "docusaurus", "docs", "index.md"))
+	if err != nil {
+		t.Fatalf("reading docusaurus page: %v", err)
+	}
+
+	page := string(content)
+	if strings.Contains(page, "&lt;") || strings.Contains(page, `\{`) {
+		t.Errorf("docusaurus page shows escaped anchors: %s", page)
+	}
+	if !strings.Contains(page, "## Pricing {#section-pricing}") {
+		t.Errorf("docusaurus page is missing the heading id: %s", page)
+	}
+}
+
+func TestCrawlOnceDocusaurusComplexTables(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Plans</title></head><body><main><h1>Plans</h1>` +
+			`<table><tr><th>Plan</th><th>Feature</th></tr>` +
+			`<tr><td rowspan="2">Pro</td><td>Backups</td></tr><tr><td>Support</td></tr></table></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.docusaurus = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "docusaurus", "docs", "index.md"))
+	if err != nil {
+		t.Fatalf("reading docusaurus page: %v", err)
+	}
+
+	page := string(content)
+	if strings.Contains(page, "&lt;table") {
+		t.Errorf("docusaurus page shows the table as escaped HTML: %s", page)
+	}
+	for _, want := range []string{"| Plan | Feature |", "Pro", "Backups", "Support"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("docusaurus page is missing %q: %s", want, page)
+		}
+	}
+}
+
+func TestCrawlOnceMdBook(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.format = formatMdBook
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	config, err := os.ReadFile(filepath.Join(options.outputDir, "book.toml"))
+	if err != nil {
+		t.Fatalf("reading book.toml: %v", err)
+	}
+	if !strings.Contains(string(config), "title = \"Home\"") || !strings.Contains(string(config), "src = \"src\"") {
+		t.Errorf("book.toml = %s", config)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	summary, err := os.ReadFile(filepath.Join(options.outputDir, "src", "SUMMARY.md"))
+	if err != nil {
+		t.Fatalf("reading SUMMARY.md: %v", err)
+	}
+	if want := "# Summary\n\n[Home](index.md)\n\n- [Guide](guide.md)\n"; string(summary) != want {
+		t.Errorf("SUMMARY.md = %q, want %q", summary, want)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	page, err := os.ReadFile(filepath.Join(options.outputDir, "src", "index.md"))
+	if err != nil {
+		t.Fatalf("reading the start page: %v", err)
+	}
+	if !strings.Contains(string(page), "[guide](guide.md)") {
+		t.Errorf("start page does not link to the local file: %s", page)
+	}
+}
+
+func TestCrawlOnceResolve(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+	serverURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parsing server URL: %v", err)
+	}
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.resolve = []string{"staging.example.invalid:" + serverURL.Hostname()}
+
+	startURL := "http://staging.example.invalid:" + serverURL.Port()
+	if _, err := crawlOnce(options, startURL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	m, err := manifest.Load(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading manifest: %v", err)
+	}
+	if _, ok := m.Lookup(startURL + "/guide"); !ok || len(m.Pages) != 2 {
+		t.Errorf("manifest pages = %+v, want the pages under the staging host", m.Pages)
+	}
+}
+
+func TestCrawlOnceSearchIndex(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.searchIndex = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "search-index.json"))
+	if err != nil {
+		t.Fatalf("reading search index: %v", err)
+	}
+
+	if !strings.Contains(string(content), `"guide"`) {
+		t.Errorf("search index does not contain the guide term: %s", content)
+	}
+}
+
+func TestCrawlOnceDocusaurus(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.docusaurus = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	home, err := os.ReadFile(filepath.Join(options.outputDir, "docusaurus", "docs", "index.md"))
+	if err != nil {
+		t.Fatalf("reading docusaurus index: %v", err)
+	}
+
+	if !strings.Contains(string(home), "sidebar_position: 1") || !strings.Contains(string(home), "[guide](./guide.md)") {
+		t.Errorf("unexpected docusaurus index: %s", home)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	sidebar, err := os.ReadFile(filepath.Join(options.outputDir, "docusaurus", "sidebar.json"))
+	if err != nil {
+		t.Fatalf("reading sidebar: %v", err)
+	}
+
+	if !strings.Contains(string(sidebar), `"id": "guide"`) {
+		t.Errorf("sidebar does not list the guide: %s", sidebar)
+	}
+}
+
+func newMultilingualTestSite(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html lang="en"><head><title>Home</title></head><body><main><p>Read the <a href="/guide">guide</a> or the <a href="/de/anleitung">Anleitung</a>.</p></main></body></html>`))
+	})
+	mux.HandleFunc("/guide", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Guide</title></head><body><main><p>This is the guide and it is the best way to learn the tool.</p></main></body></html>`))
+	})
+	mux.HandleFunc("/de/anleitung", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html lang="de-DE"><head><title>Anleitung</title></head><body><main><p>Zur <a href="/">Startseite</a>.</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestCrawlOnceLanguageFilter(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultilingualTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.languages = []string{"en"}
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	for _, file := range []string{"index.md", "guide.md"} {
+		if _, err := os.Stat(filepath.Join(options.outputDir, file)); err != nil {
+			t.Errorf("expected %s to be saved: %v", file, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(options.outputDir, "de-anleitung.md")); !os.IsNotExist(err) {
+		t.Errorf("expected the German page to be skipped, got %v", err)
+	}
+}
+
+func TestCrawlOnceSplitByLang(t *testing.T) {
+	t.Parallel()
+
+	srv := newMultilingualTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.splitByLang = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	for _, file := range []string{"en/index.md", "en/guide.md", "de/de-anleitung.md"} {
+		if _, err := os.Stat(filepath.Join(options.outputDir, filepath.FromSlash(file))); err != nil {
+			t.Errorf("expected %s to be saved: %v", file, err)
+		}
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "de", "de-anleitung.md"))
+	if err != nil {
+		t.Fatalf("reading German page: %v", err)
+	}
+
+	if !strings.Contains(string(content), "[Startseite](../en/index.md)") {
+		t.Errorf("link to the English page is not relative to the language directory: %s", content)
+	}
+}
+
+func TestCrawlOnceCanonicalOnly(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><a href="/article?ref=home">Article</a></main></body></html>`))
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Article</title><link rel="canonical" href="/article"><link rel="alternate" hreflang="de" href="/de/article"></head><body><main><p>Article</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.canonicalOnly = true
+	options.flavor = flavor.Obsidian
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(options.outputDir, "article-ref-home.md")); !os.IsNotExist(err) {
+		t.Errorf("expected the tracking variant to be skipped, got %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "article.md"))
+	if err != nil {
+		t.Fatalf("reading canonical page: %v", err)
+	}
+
+	if !strings.Contains(string(content), `"de": "`+srv.URL+`/de/article"`) {
+		t.Errorf("front matter does not record the alternates: %s", content)
+	}
+}
+
+func TestCrawlOnceMetadata(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Post</title>
+<meta property="og:description" content="A post">
+<meta property="article:published_time" content="2024-03-01T08:00:00Z">
+</head><body><main><p>Body</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.flavor = flavor.Hugo
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "content", "_index.md"))
+	if err != nil {
+		t.Fatalf("reading page: %v", err)
+	}
+
+	if !strings.Contains(string(content), "description: \"A post\"\n") || !strings.Contains(string(content), "publishDate: \"2024-03-01T08:00:00Z\"\n") {
+		t.Errorf("front matter does not contain the metadata: %s", content)
+	}
+
+	pageManifest, err := manifest.Load(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading manifest: %v", err)
+	}
+
+	if len(pageManifest.Pages) != 1 || pageManifest.Pages[0].Metadata == nil || pageManifest.Pages[0].Metadata.Description != "A post" {
+		t.Errorf("manifest does not record the metadata: %+v", pageManifest.Pages)
+	}
+}
+
+func TestCrawlOnceRedirects(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p><a href="/old">Old guide</a></p></main></body></html>`))
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>New</title></head><body><main><p>New guide</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	home, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading home page: %v", err)
+	}
+
+	if !strings.Contains(string(home), "[Old guide](new.md)") {
+		t.Errorf("expected the redirected link to point at new.md, got: %s", home)
+	}
+
+	pageManifest, err := manifest.Load(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading manifest: %v", err)
+	}
+
+	var redirects []manifest.Redirect
+	for _, entry := range pageManifest.Pages {
+		if entry.File == "new.md" {
+			redirects = entry.Redirects
+		}
+	}
+
+	if len(redirects) != 1 || redirects[0].URL != srv.URL+"/old" || redirects[0].Status != http.StatusMovedPermanently {
+		t.Errorf("expected the manifest to record the redirect from /old, got %+v", redirects)
+	}
+}
+
+func TestCrawlOnceKeepStatus(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<html><head><title>Not found</title></head><body><main><p>This page is gone.</p></main></body></html>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p><a href="/gone">Gone</a></p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.keepStatuses = []int{http.StatusNotFound}
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	pageManifest, err := manifest.Load(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading manifest: %v", err)
+	}
+
+	statuses := map[string]int{}
+	for _, entry := range pageManifest.Pages {
+		statuses[entry.File] = entry.Status
+	}
+
+	if len(statuses) != 2 || statuses["index.md"] != 0 || statuses["gone.md"] != http.StatusNotFound {
+		t.Errorf("expected the manifest to record the 404 status of gone.md only, got %v", statuses)
+	}
+}
+
+func TestCrawlOnceErrorReport(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p><a href="/broken">Broken</a></p></main></body></html>`))
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.retries = 1
+	options.maxErrorRate = 0.25
+
+	result, err := crawlOnce(options, srv.URL, false)
+	var rateErr *crawler.ErrorRateError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("expected an error rate error, got %v", err)
+	}
+	if result.pagesSaved != 1 {
+		t.Errorf("expected the home page to be saved, got %d pages", result.pagesSaved)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	data, err := os.ReadFile(filepath.Join(options.outputDir, errorsFilename))
+	if err != nil {
+		t.Fatalf("reading errors report: %v", err)
+	}
+
+	var report errorReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("errors report is not valid JSON: %v", err)
+	}
+
+	if len(report.Errors) != 1 || report.Errors[0].URL != srv.URL+"/broken" || report.Errors[0].Status != http.StatusBadGateway || report.Errors[0].Retries != 1 {
+		t.Errorf("expected one 502 error for /broken retried once, got %+v", report.Errors)
+	}
+}
+
+func TestCrawlOnceReportsPageFailures(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p><a href="/empty">Empty</a></p></main></body></html>`))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Empty</title></head><body></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+
+	result, err := crawlOnce(options, srv.URL, false)
+	if err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	if len(result.failures) != 1 || result.failures[0].url != srv.URL+"/empty" || result.failures[0].stage != stageConvert {
+		t.Fatalf("expected a conversion failure for /empty, got %+v", result.failures)
+	}
+
+	summary := formatFailures(result.failures)
+	if !strings.Contains(summary, srv.URL+"/empty (convert): ") {
+		t.Errorf("expected the summary to list the failed page and its cause, got: %s", summary)
+	}
+}
+
+func TestCrawlOnceWritesSummary(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p><a href="/empty">Empty</a> <a href="/about">About</a></p></main></body></html>`))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Empty</title></head><body></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.summaryPath = filepath.Join(t.TempDir(), "summary.json")
+	options.quiet = true
+	options.searchIndex = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	data, err := os.ReadFile(options.summaryPath)
+	if err != nil {
+		t.Fatalf("expected summary file: %v", err)
+	}
+
+	var summary runSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("expected valid summary JSON: %v", err)
+	}
+
+	if summary.URL != srv.URL {
+		t.Errorf("expected url %s, got %s", srv.URL, summary.URL)
+	}
+	if summary.Pages.Crawled != 3 || summary.Pages.Saved != 2 || summary.Pages.Failed != 1 {
+		t.Errorf("expected 3 crawled, 2 saved, and 1 failed page, got %+v", summary.Pages)
+	}
+	if len(summary.Output.Files) != 2 {
+		t.Errorf("expected 2 output files, got %v", summary.Output.Files)
+	}
+	if summary.Bytes.Downloaded == 0 || summary.Bytes.Written == 0 {
+		t.Errorf("expected downloaded and written bytes, got %+v", summary.Bytes)
+	}
+	if len(summary.Output.Exports) != 1 {
+		t.Errorf("expected the search index export, got %v", summary.Output.Exports)
+	}
+	if summary.Durations.Total < summary.Durations.Crawl {
+		t.Errorf("expected the total duration to include the crawl, got %+v", summary.Durations)
+	}
+}
+
+func TestCrawlOnceSingleNotModified(t *testing.T) {
+	t.Parallel()
+
+	var conditional []string
+	var mutex sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		if since := r.Header.Get("If-Modified-Since"); since != "" {
+			mutex.Lock()
+			conditional = append(conditional, since)
+			mutex.Unlock()
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Page</title></head><body><main><p>Page content</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+
+	first, err := crawlOnce(options, srv.URL+"/page", true)
+	if err != nil {
+		t.Fatalf("first crawlOnce returned error: %v", err)
+	}
+	if first.pagesSaved != 1 || len(conditional) != 0 {
+		t.Fatalf("expected an unconditional first fetch saving 1 page, got %d saved and %v", first.pagesSaved, conditional)
+	}
+
+	second, err := crawlOnce(options, srv.URL+"/page", true)
+	if err != nil {
+		t.Fatalf("second crawlOnce returned error: %v", err)
+	}
+	if len(conditional) != 1 {
+		t.Fatalf("expected a conditional request, got %v", conditional)
+	}
+	if second.pagesSaved != 1 || !second.changes.Empty() {
+		t.Errorf("expected the page to be kept without changes, got %d saved and %+v", second.pagesSaved, second.changes)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	data, err := os.ReadFile(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("expected manifest: %v", err)
+	}
+	if !strings.Contains(string(data), srv.URL+"/page") {
+		t.Errorf("expected the manifest to keep the page, got: %s", data)
+	}
+
+	errorsFile := filepath.Join(options.outputDir, errorsFilename)
+	if _, err := os.Stat(errorsFile); !os.IsNotExist(err) {
+		t.Errorf("expected no errors.json for a 304 response, got %v", err)
+	}
+}
+
+func TestCrawlOnceTablesCSV(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Plans</title></head><body><main>` +
+			`<table><caption>Plans</caption><tr><th>Plan</th><th>Price</th></tr><tr><td colspan="2">Custom</td></tr></table>` +
+			`</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.tables = "csv"
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(options.outputDir, "assets", "table-*.csv"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one CSV table under assets/, got %v (%v)", files, err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	page, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("expected index.md: %v", err)
+	}
+	if want := "[Plans (CSV)](assets/" + filepath.Base(files[0]) + ")"; !strings.Contains(string(page), want) {
+		t.Errorf("expected the page to link the CSV table with %q, got:\n%s", want, page)
+	}
+}
+
+func TestCrawlOnceDownloadImagesDeduplicates(t *testing.T) {
+	t.Parallel()
+
+	var logoRequests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main>` +
+			`<p><img src="/static/logo.png" alt="Logo"></p><p><a href="/guide">Guide</a></p>` +
+			`</main></body></html>`))
+	})
+	mux.HandleFunc("/guide", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Guide</title></head><body><main>` +
+			`<p><img src="/static/logo.png" alt="Logo"></p><p><img src="/v2/logo.png" alt="Same logo"></p>` +
+			`</main></body></html>`))
+	})
+	for _, logoPath := range []string{"/static/logo.png", "/v2/logo.png"} {
+		mux.HandleFunc(logoPath, func(w http.ResponseWriter, r *http.Request) {
+			logoRequests.Add(1)
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("logo-bytes"))
+		})
+	}
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.downloadImages = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(options.outputDir, "assets", "*.png"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one shared image under assets/, got %v (%v)", files, err)
+	}
+	if got := logoRequests.Load(); got != 2 {
+		t.Errorf("expected each image URL to be downloaded once, got %d requests", got)
+	}
+
+	want := "](assets/" + filepath.Base(files[0]) + ")"
+	for _, name := range []string{"index.md", "guide.md"} {
+		//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+		page, err := os.ReadFile(filepath.Join(options.outputDir, name))
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		if !strings.Contains(string(page), want) {
+			t.Errorf("expected %s to reference the shared image with %q, got:\n%s", name, want, page)
+		}
+	}
+}
+
+func TestCrawlOnceDownloadImagesConverts(t *testing.T) {
+	t.Parallel()
+
+	webp, err := os.ReadFile(filepath.Join("..", "src", "imaging", "testdata", "gopher.webp"))
+	if err != nil {
+		t.Fatalf("failed to read test image: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main>` +
+			`<p><img src="/gopher.webp" alt="Gopher"></p></main></body></html>`))
+	})
+	mux.HandleFunc("/gopher.webp", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/webp")
+		_, _ = w.Write(webp)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.downloadImages = true
+	options.imageFormat = "png"
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(options.outputDir, "assets", "*"))
+	if err != nil || len(files) != 1 || filepath.Ext(files[0]) != ".png" {
+		t.Fatalf("expected the image converted to one PNG under assets/, got %v (%v)", files, err)
+	}
+}
+
+func TestCrawlOnceConvertImagesKeepsAVIF(t *testing.T) {
+	t.Parallel()
+
+	avif := []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00avifmif1miaf")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main>` +
+			`<p><img src="/photo.avif" alt="Photo"></p></main></body></html>`))
+	})
+	mux.HandleFunc("/photo.avif", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/avif")
+		_, _ = w.Write(avif)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.downloadImages = true
+	options.imageFormat = "png"
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(options.outputDir, "assets", "*"))
+	if err != nil || len(files) != 1 || filepath.Ext(files[0]) != ".avif" {
+		t.Fatalf("expected the AVIF image kept as one file under assets/, got %v (%v)", files, err)
+	}
+}
+
+func TestCrawlOnceDownloadExtensions(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Downloads</title></head><body><main>` +
+			`<p><a href="/2024/data.csv">2024 data</a> <a href="/2025/data.csv">2025 data</a> <a href="/report.pdf">Report</a></p>` +
+			`</main></body></html>`))
+	})
+	for _, year := range []string{"2024", "2025"} {
+		mux.HandleFunc("/"+year+"/data.csv", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("year\n" + year + "\n"))
+		})
+	}
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.downloadExtensions = []string{"csv"}
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(options.outputDir, "files", "data*.csv"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("expected two CSV files under files/, got %v (%v)", files, err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	page, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("expected index.md: %v", err)
+	}
+	for _, file := range files {
+		if want := "](files/" + filepath.Base(file) + ")"; !strings.Contains(string(page), want) {
+			t.Errorf("expected the page to link %q, got:\n%s", want, page)
+		}
+	}
+	if strings.Contains(string(page), "files/report") {
+		t.Errorf("expected the file without a matching extension not to be downloaded, got:\n%s", page)
+	}
+}
+
+func TestCrawlOnceMediaDownload(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Media</title></head><body><main>` +
+			`<video src="/media/clip.mp4" controls></video><audio><source src="/media/talk.mp3"></audio>` +
+			`</main></body></html>`))
+	})
+	mux.HandleFunc("/media/clip.mp4", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("small clip"))
+	})
+	mux.HandleFunc("/media/talk.mp3", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 256)))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.media = "download"
+	options.maxMediaSize = 64
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	page, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("expected index.md: %v", err)
+	}
+	for _, want := range []string{"[Video: clip.mp4](files/clip.mp4)", "[Audio: talk.mp3](/media/talk.mp3)"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("expected the page to contain %q, got:\n%s", want, page)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(options.outputDir, "files", "talk.mp3")); !os.IsNotExist(err) {
+		t.Errorf("expected media larger than --max-media-size not to be saved, got %v", err)
+	}
+}
+
+func TestCrawlOnceNavigationOrder(t *testing.T) {
+	t.Parallel()
+
+	nav := `<nav class="sidebar"><ul><li><a href="/zeta">Zeta</a><ul><li><a href="/alpha">Alpha</a></li></ul></li></ul></nav>`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body>` + nav + `<main><p>Start with <a href="/alpha">alpha</a>.</p></main></body></html>`))
+	})
+	for _, name := range []string{"alpha", "zeta"} {
+		mux.HandleFunc("/"+name, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html><head><title>` + name + `</title></head><body>` + nav + `<main><p>The ` + name + ` page.</p></main></body></html>`))
+		})
+	}
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.navSelector = "nav.sidebar"
+	options.docusaurus = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	data, err := os.ReadFile(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	m, err := manifest.Decode(data)
+	if err != nil {
+		t.Fatalf("decoding manifest: %v", err)
+	}
+
+	alpha, ok := m.Lookup(srv.URL + "/alpha")
+	if !ok || alpha.NavPosition != 2 || alpha.NavParent != srv.URL+"/zeta" {
+		t.Errorf("alpha entry = %+v, want navigation position 2 under zeta", alpha)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	sidebar, err := os.ReadFile(filepath.Join(options.outputDir, "docusaurus", "sidebar.json"))
+	if err != nil {
+		t.Fatalf("reading sidebar: %v", err)
+	}
+	if zeta, alpha := strings.Index(string(sidebar), `"zeta"`), strings.Index(string(sidebar), `"alpha"`); zeta < 0 || alpha < zeta {
+		t.Errorf("sidebar does not follow the navigation order: %s", sidebar)
+	}
+}
+
+func TestCrawlOnceSpillDir(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Read the <a href="/guide">guide</a>.</p></main></body></html>`))
+	})
+	mux.HandleFunc("/guide", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Guide</title></head><body><main><p>The guide.</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.spillDir = t.TempDir()
+	options.requestDelay = 0
+
+	result, err := crawlOnce(options, srv.URL, false)
+	if err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+	if result.pagesSaved != 2 {
+		t.Errorf("pagesSaved = %d, want 2", result.pagesSaved)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	home, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading home page: %v", err)
+	}
+	if !strings.Contains(string(home), "[guide](guide.md)") {
+		t.Errorf("home page links were not rewritten from the spilled pages:\n%s", home)
+	}
+
+	entries, err := os.ReadDir(options.spillDir)
+	if err != nil {
+		t.Fatalf("reading spill dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("spill dir holds %d files after the crawl, want the buffers removed", len(entries))
+	}
+}
+
+func TestCrawlOnceStream(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	var homeSavedEarly atomic.Bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Read the <a href="/guide">guide</a>.</p></main></body></html>`))
+	})
+	mux.HandleFunc("/guide", func(w http.ResponseWriter, r *http.Request) {
+		// The home page is converted and saved while this page is still being fetched
+		time.Sleep(200 * time.Millisecond)
+		_, err := os.Stat(filepath.Join(outputDir, "index.md"))
+		homeSavedEarly.Store(err == nil)
+		_, _ = w.Write([]byte(`<html><head><title>Guide</title></head><body><main><p>Back <a href="/">home</a>.</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = outputDir
+	options.requestDelay = 0
+	options.stream = true
+
+	result, err := crawlOnce(options, srv.URL, false)
+	if err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+	if result.pagesSaved != 2 {
+		t.Errorf("pagesSaved = %d, want 2", result.pagesSaved)
+	}
+	if !homeSavedEarly.Load() {
+		t.Error("home page was not saved before the crawl ended")
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	home, err := os.ReadFile(filepath.Join(outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading home page: %v", err)
+	}
+	if !strings.Contains(string(home), "[guide](guide.md)") {
+		t.Errorf("link to the page crawled after the home page was not patched:\n%s", home)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	data, err := os.ReadFile(filepath.Join(outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("reading manifest: %v", err)
+	}
+	m, err := manifest.Decode(data)
+	if err != nil {
+		t.Fatalf("decoding manifest: %v", err)
+	}
+	if entry, ok := m.Lookup(srv.URL); !ok || entry.Hash != manifest.HashContent(home) {
+		t.Errorf("home entry = %+v, want the hash of the patched file", entry)
+	}
+}
+
+func TestCrawlOnceClobberModes(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Original text.</p></main></body></html>`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		configure func(options *getOptions)
+		crawled   bool // Whether a first run wrote the file and its manifest entry
+		want      string
+	}{
+		{name: "unchanged files are not rewritten", configure: func(*getOptions) {}, crawled: true, want: "edited"},
+		{name: "force rewrites unchanged files", configure: func(options *getOptions) { options.force = true }, crawled: true, want: "Original text."},
+		{name: "no clobber keeps existing files", configure: func(options *getOptions) { options.noClobber = true }, want: "edited"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := defaultGetOptions()
+			options.outputDir = t.TempDir()
+			options.requestDelay = 0
+			file := filepath.Join(options.outputDir, "index.md")
+
+			if test.crawled {
+				if _, err := crawlOnce(options, srv.URL, false); err != nil {
+					t.Fatalf("first crawlOnce returned error: %v", err)
+				}
+			}
+			if err := os.WriteFile(file, []byte("edited"), 0o600); err != nil {
+				t.Fatalf("editing the page: %v", err)
+			}
+
+			test.configure(options)
+			if _, err := crawlOnce(options, srv.URL, false); err != nil {
+				t.Fatalf("crawlOnce returned error: %v", err)
+			}
+
+			//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("reading page: %v", err)
+			}
+			if !strings.Contains(string(data), test.want) {
+				t.Errorf("page = %q, want it to contain %q", data, test.want)
+			}
+		})
+	}
+}
+
+func TestCrawlOncePrune(t *testing.T) {
+	t.Parallel()
+
+	// The old page answers with oldStatus on the second run; the home page keeps linking to it unless it is 404
+	var oldStatus atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		links := `<a href="/kept">Kept</a>`
+		if oldStatus.Load() != http.StatusNotFound {
+			links += ` <a href="/old">Old</a>`
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>` + links + `</p></main></body></html>`))
+	})
+	mux.HandleFunc("/kept", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Kept</title></head><body><main><p>Kept page</p></main></body></html>`))
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		if status := int(oldStatus.Load()); status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Old</title></head><body><main><p>Old page</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		oldStatus int
+		wantOld   bool
+	}{
+		{name: "removes files of deleted pages", oldStatus: http.StatusNotFound, wantOld: false},
+		{name: "keeps files when requests failed", oldStatus: http.StatusInternalServerError, wantOld: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := defaultGetOptions()
+			options.outputDir = t.TempDir()
+			options.requestDelay = 0
+			options.retries = 0
+			options.prune = true
+
+			oldStatus.Store(http.StatusOK)
+			if _, err := crawlOnce(options, srv.URL, false); err != nil {
+				t.Fatalf("first crawlOnce returned error: %v", err)
+			}
+			oldFile := filepath.Join(options.outputDir, "old.md")
+			if _, err := os.Stat(oldFile); err != nil {
+				t.Fatalf("first run did not save the old page: %v", err)
+			}
+
+			oldStatus.Store(int32(test.oldStatus))
+			if _, err := crawlOnce(options, srv.URL, false); err != nil {
+				t.Fatalf("crawlOnce returned error: %v", err)
+			}
+
+			if _, err := os.Stat(oldFile); (err == nil) != test.wantOld {
+				t.Errorf("old page file exists = %v, want %v", err == nil, test.wantOld)
+			}
+			if _, err := os.Stat(filepath.Join(options.outputDir, "kept.md")); err != nil {
+				t.Errorf("kept page file was removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestCrawlOnceStoreMetadata(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html lang="en"><head><title>Home</title>` +
+			`<meta property="og:description" content="The home page.">` +
+			`<meta property="og:type" content="website"></head>` +
+			`<body><main><p>Welcome home. This page has enough text to be summarized.</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dbPath := filepath.Join(t.TempDir(), "crawl.db")
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.store = "sqlite:" + dbPath
+	options.summarize = 1
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var encoded string
+	if err := db.QueryRow(`SELECT metadata FROM pages WHERE url = ?`, srv.URL).Scan(&encoded); err != nil {
+		t.Fatalf("querying metadata: %v", err)
+	}
+	var stored map[string]string
+	if err := json.Unmarshal([]byte(encoded), &stored); err != nil {
+		t.Fatalf("decoding metadata %q: %v", encoded, err)
+	}
+
+	for key, want := range map[string]string{
+		"lang":           "en",
+		"status":         "200",
+		"description":    "The home page.",
+		"og:description": "The home page.",
+		"type":           "website",
+	} {
+		if stored[key] != want {
+			t.Errorf("metadata[%q] = %q, want %q (metadata %s)", key, stored[key], want, encoded)
+		}
+	}
+	if stored["summary"] == "" {
+		t.Errorf("metadata has no summary: %s", encoded)
+	}
+}
+
+func TestPruneStaleFilesOutsideOutput(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "out")
+	outside := filepath.Join(root, "secret.md")
+	for _, file := range []string{outside, filepath.Join(dir, "stale.md")} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+			t.Fatalf("creating directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte("# Page"), 0o600); err != nil {
+			t.Fatalf("writing file: %v", err)
+		}
+	}
+
+	previous := manifest.New()
+	previous.Add(manifest.Entry{URL: "https://example.com/stale", File: "stale.md"})
+	previous.Add(manifest.Entry{URL: "https://example.com/secret", File: "../secret.md"})
+
+	writer := output.NewDirWriter(dir, output.Permissions{})
+	if pruned := pruneStaleFiles(writer, previous, manifest.New(), t.Logf); pruned != 1 {
+		t.Errorf("pruned = %d, want only the file inside the output", pruned)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stale.md")); !os.IsNotExist(err) {
+		t.Errorf("stale file was not removed: %v", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("file outside the output was removed: %v", err)
+	}
+}
+
+func TestCrawlOnceStripBoilerplate(t *testing.T) {
+	t.Parallel()
+
+	const footer = "Was this page helpful? Send us your feedback."
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.Trim(r.URL.Path, "/")
+		if name == "" {
+			name = "home"
+		}
+		_, _ = w.Write([]byte(`<html><head><title>` + name + `</title></head><body><main>` +
+			`<p>The ` + name + ` page has its own text.</p><p>` + footer + `</p>` +
+			`<p><a href="/one">One</a> <a href="/two">Two</a> <a href="/three">Three</a></p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.stripBoilerplate = 0.75
+	options.boilerplateReport = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	page, err := os.ReadFile(filepath.Join(options.outputDir, "one.md"))
+	if err != nil {
+		t.Fatalf("reading page: %v", err)
+	}
+	if strings.Contains(string(page), "Was this page helpful") || !strings.Contains(string(page), "The one page has its own text.") {
+		t.Errorf("page = %q, want the repeated footer removed and its own text kept", page)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	data, err := os.ReadFile(filepath.Join(options.outputDir, boilerplateFilename))
+	if err != nil {
+		t.Fatalf("reading boilerplate report: %v", err)
+	}
+	var report boilerplateReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("decoding boilerplate report: %v", err)
+	}
+	if report.Pages != 4 || len(report.Blocks) == 0 || report.Blocks[0].Pages != 4 {
+		t.Errorf("report = %+v, want blocks found on all 4 pages", report)
+	}
+}
+
+func TestCrawlOnceSummarize(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Docs</title></head><body><main><h1>Docs</h1>` +
+			`<p>The documentation of the example tool. It covers every command. Read it first.</p>` +
+			`<p><a href="/faq">FAQ</a></p></main></body></html>`))
+	})
+	mux.HandleFunc("/faq", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>FAQ</title><meta name="description" content="Answers to common questions."></head>` +
+			`<body><main><h1>FAQ</h1><ul><li>Is it free?</li><li>Yes.</li></ul></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.summarize = 2
+	options.llmsTxt = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	page, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading page: %v", err)
+	}
+	if !strings.HasPrefix(string(page), "---\nsummary: \"The documentation of the example tool. It covers every command.\"\n---\n") {
+		t.Errorf("page = %q, want the summary in the front matter", page)
+	}
+
+	pageManifest, err := manifest.Load(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading manifest: %v", err)
+	}
+	for _, entry := range pageManifest.Pages {
+		if entry.Summary == "" {
+			t.Errorf("manifest entry %s has no summary", entry.URL)
+		}
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	index, err := os.ReadFile(filepath.Join(options.outputDir, export.LLMsTxtFilename))
+	if err != nil {
+		t.Fatalf("reading llms.txt: %v", err)
+	}
+	want := "# Docs\n\n> The documentation of the example tool. It covers every command.\n\n## Pages\n\n" +
+		"- [Docs](" + srv.URL + "): The documentation of the example tool. It covers every command.\n" +
+		"- [FAQ](" + srv.URL + "/faq): Answers to common questions.\n"
+	if string(index) != want {
+		t.Errorf("llms.txt = %q, want %q", index, want)
+	}
+}
+
+func TestCrawlOncePostprocess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Welcome home.</p>` +
+			`<p><a href="/fail">Fail</a></p></main></body></html>`))
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Fail</title></head><body><main><p>Not processed.</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.postprocessCmd = `case "$CRAWLDOWN_URL" in */fail) exit 1;; esac; sed 's/home/HOME/'`
+
+	result, err := crawlOnce(options, srv.URL, false)
+	if err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	page, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading page: %v", err)
+	}
+	if !strings.Contains(string(page), "Welcome HOME.") {
+		t.Errorf("page = %q, want the output of the command", page)
+	}
+
+	if _, err := os.Stat(filepath.Join(options.outputDir, "fail.md")); !os.IsNotExist(err) {
+		t.Errorf("page failing post-processing was written: %v", err)
+	}
+	if len(result.failures) != 1 || result.failures[0].stage != stagePostprocess {
+		t.Errorf("failures = %+v, want the post-processing failure", result.failures)
+	}
+}
+
+func TestCrawlOnceWebhook(t *testing.T) {
+	t.Parallel()
+
+	site := http.NewServeMux()
+	site.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Welcome.</p>` +
+			`<p><a href="/missing">Missing</a></p></main></body></html>`))
+	})
+	site.HandleFunc("/missing", http.NotFound)
+	srv := httptest.NewServer(site)
+	defer srv.Close()
+
+	var mutex sync.Mutex
+	var events []webhook.Event
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event webhook.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mutex.Lock()
+		events = append(events, event)
+		mutex.Unlock()
+	}))
+	defer hook.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.webhookURL = hook.URL
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	var types []string
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	want := []string{webhook.CrawlStarted, webhook.RequestFailed, webhook.PageSaved, webhook.CrawlFinished}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+
+	finished := events[len(events)-1]
+	summary, ok := finished.Summary.(map[string]any)
+	if !ok || finished.URL != srv.URL || summary["errors"] != float64(1) {
+		t.Errorf("finished event = %+v, want the run summary with the failed request", finished)
+	}
+	if events[2].URL != srv.URL || events[2].File == "" {
+		t.Errorf("page event = %+v, want the page URL and its file", events[2])
+	}
+}
+
+func TestCrawlOnceRequestErrorCallbacks(t *testing.T) {
+	t.Parallel()
+
+	site := http.NewServeMux()
+	site.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Welcome.</p>` +
+			`<p><a href="/missing">Missing</a></p></main></body></html>`))
+	})
+	site.HandleFunc("/missing", http.NotFound)
+	srv := httptest.NewServer(site)
+	defer srv.Close()
+
+	var mutex sync.Mutex
+	var failed []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event webhook.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if event.Type == webhook.RequestFailed {
+			mutex.Lock()
+			failed = append(failed, event.URL)
+			mutex.Unlock()
+		}
+	}))
+	defer hook.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.progress = true
+	options.metrics = metrics.New()
+	options.webhookURL = hook.URL
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, progress.Filename))
+	if err != nil {
+		t.Fatalf("reading progress file: %v", err)
+	}
+	var snapshot progress.Snapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		t.Fatalf("decoding progress file: %v", err)
+	}
+	if snapshot.Errors != 1 || len(snapshot.LastErrors) != 1 || snapshot.LastErrors[0].URL != srv.URL+"/missing" {
+		t.Errorf("progress errors = %d %+v, want the failed request", snapshot.Errors, snapshot.LastErrors)
+	}
+
+	var out strings.Builder
+	if _, err := options.metrics.WriteTo(&out); err != nil {
+		t.Fatalf("writing metrics: %v", err)
+	}
+	if want := "crawldown_request_errors_total 1\n"; !strings.Contains(out.String(), want) {
+		t.Errorf("expected metrics to contain %q, got:\n%s", want, out.String())
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if want := []string{srv.URL + "/missing"}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("request failed events = %v, want %v", failed, want)
+	}
+}
+
+func TestCrawlOnceExecPerPage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Welcome.</p>` +
+			`<p><a href="/about">About</a></p></main></body></html>`))
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>About</title></head><body><main><p>About us.</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	logDir := t.TempDir()
+	options.execPerPage = `head -n 1 {file} > "` + logDir + `/$(basename {file}).txt" && echo {url} >> "` + logDir + `/$(basename {file}).txt"`
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	for file, want := range map[string]string{
+		"index.md.txt": "# Home\n" + srv.URL + "\n",
+		"about.md.txt": "# About\n" + srv.URL + "/about\n",
+	} {
+		//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+		got, err := os.ReadFile(filepath.Join(logDir, file))
+		if err != nil {
+			t.Fatalf("reading command output: %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestCrawlOnceStreamExecPerPage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Read the <a href="/guide">guide</a>.</p></main></body></html>`))
+	})
+	mux.HandleFunc("/guide", func(w http.ResponseWriter, r *http.Request) {
+		// The home page is saved with an absolute link to this page before it is fetched
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`<html><head><title>Guide</title></head><body><main><p>Guide.</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.stream = true
+	logDir := t.TempDir()
+	options.execPerPage = `cat {file} >> "` + logDir + `/$(basename {file}).txt"`
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	got, err := os.ReadFile(filepath.Join(logDir, "index.md.txt"))
+	if err != nil {
+		t.Fatalf("reading command output: %v", err)
+	}
+	if count := strings.Count(string(got), "# Home"); count != 1 {
+		t.Errorf("command ran %d times for the home page, want once:\n%s", count, got)
+	}
+	if !strings.Contains(string(got), "[guide](guide.md)") {
+		t.Errorf("command did not receive the patched home page:\n%s", got)
+	}
+}
+
+func TestCrawlOnceVectorStore(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p>Welcome.</p>` +
+			`<h2>Install</h2><p>Run the installer.</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var mutex sync.Mutex
+	var embedded []string
+	var points []any
+	api := http.NewServeMux()
+	api.HandleFunc("POST /v1/embeddings", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		data := make([]map[string]any, len(request.Input))
+		for i := range request.Input {
+			data[i] = map[string]any{"index": i, "embedding": []float32{1, float32(i)}}
+		}
+		mutex.Lock()
+		embedded = append(embedded, request.Input...)
+		mutex.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	})
+	api.HandleFunc("GET /collections/docs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result": {}}`))
+	})
+	api.HandleFunc("POST /collections/docs/points/delete", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result": {}}`))
+	})
+	api.HandleFunc("PUT /collections/docs/points", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Points []any `json:"points"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		mutex.Lock()
+		points = append(points, request.Points...)
+		mutex.Unlock()
+		_, _ = w.Write([]byte(`{"result": {}}`))
+	})
+	apiSrv := httptest.NewServer(api)
+	defer apiSrv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.vectorStore = "qdrant:" + apiSrv.URL + "/docs"
+	options.embeddingURL = apiSrv.URL + "/v1"
+	options.embeddingModel = "embed-small"
+
+	if _, err := crawlOnce(options, srv.URL, true); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	want := []string{"Home\n\nWelcome.", "Home > Install\n\nRun the installer."}
+	if !reflect.DeepEqual(embedded, want) {
+		t.Errorf("embedded %q, want %q", embedded, want)
+	}
+	if len(points) != 2 {
+		t.Fatalf("stored %d points, want 2", len(points))
+	}
+	payload, _ := points[1].(map[string]any)["payload"].(map[string]any)
+	if payload["url"] != srv.URL || payload["heading"] != "Install" || payload["file"] != "index.md" {
+		t.Errorf("point payload = %v, want the page URL, heading, and file", payload)
+	}
+}
+
+func TestCrawlOnceLongFilenames(t *testing.T) {
+	t.Parallel()
+
+	longPath := "/" + strings.Repeat("very-long-segment-", 20)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == longPath {
+			_, _ = w.Write([]byte(`<html><head><title>Long</title></head><body><main><p>Long page</p></main></body></html>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><p><a href="` + longPath + `">Long page</a></p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.maxFilenameLength = 64
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	home, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading home page: %v", err)
+	}
+
+	start := strings.Index(string(home), "[Long page](")
+	if start < 0 {
+		t.Fatalf("expected a link to the long page, got: %s", home)
+	}
+	target := strings.SplitN(string(home)[start+len("[Long page]("):], ")", 2)[0]
+
+	if len(target) > 64 || !strings.HasSuffix(target, ".md") {
+		t.Errorf("expected a local link of at most 64 bytes, got %q", target)
+	}
+
+	if _, err := os.Stat(filepath.Join(options.outputDir, target)); err != nil {
+		t.Errorf("expected the linked file to exist: %v", err)
+	}
+}
+
+func TestCrawlOnceMetadataFrontMatter(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Post</title>
+<meta property="og:description" content="A post">
+<meta name="twitter:card" content="summary">
+<script type="application/ld+json">{"@type": "Article"}</script>
+</head><body><main><p>Body</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.metadataFrontMatter = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading page: %v", err)
+	}
+
+	for _, want := range []string{"description: \"A post\"\n", "twitter:\n  \"card\": \"summary\"\n", "json_ld:\n  - {\"@type\":\"Article\"}\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("front matter is missing %q: %s", want, content)
+		}
+	}
+}
+
+func TestCrawlOncePageTemplates(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.config = &fileConfig{
+		Templates: []render.Rule{
+			{Pattern: "/guide", Template: "---\ntitle: {{quote .Title}}\n---\n\n{{.Markdown}}"},
+		},
+	}
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	guide, err := os.ReadFile(filepath.Join(options.outputDir, "guide.md"))
+	if err != nil {
+		t.Fatalf("reading guide page: %v", err)
+	}
+
+	if !strings.HasPrefix(string(guide), "---\ntitle: \"Guide\"\n---\n\n") {
+		t.Errorf("guide page was not rendered with its template: %s", guide)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	home, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading home page: %v", err)
+	}
+
+	if !strings.HasPrefix(string(home), "# Home\n\nURL: ") {
+		t.Errorf("home page did not keep the default header: %s", home)
+	}
+}
+
+func TestCrawlOnceTemplateFile(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	dir := t.TempDir()
+	templateFile := filepath.Join(dir, "page.tmpl")
+	source := "<!-- {{.URL}} from {{.Crawl.StartURL}}, status {{.Status}} -->\n# {{.Title}}\n\n{{.Markdown}}\n\n---\nSaved by crawldown {{.Crawl.Version}}\n"
+	if err := os.WriteFile(templateFile, []byte(source), 0o600); err != nil {
+		t.Fatalf("writing template: %v", err)
+	}
+
+	options := defaultGetOptions()
+	options.outputDir = filepath.Join(dir, "out")
+	options.requestDelay = 0
+	options.templateFile = templateFile
+	options.config = &fileConfig{
+		Templates: []render.Rule{
+			{Pattern: "/guide", Template: "Guide: {{.Markdown}}"},
+		},
+	}
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	home, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading home page: %v", err)
+	}
+	wantHeader := "<!-- " + srv.URL + " from " + srv.URL + ", status 200 -->\n# Home\n\n"
+	if !strings.HasPrefix(string(home), wantHeader) || !strings.HasSuffix(string(home), "Saved by crawldown "+version+"\n") {
+		t.Errorf("home page was not rendered with the template file: %s", home)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	guide, err := os.ReadFile(filepath.Join(options.outputDir, "guide.md"))
+	if err != nil {
+		t.Fatalf("reading guide page: %v", err)
+	}
+	if !strings.HasPrefix(string(guide), "Guide: ") {
+		t.Errorf("guide page was not rendered with its configured template: %s", guide)
+	}
+}
+
+func TestCrawlOnceTagRules(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.config = &fileConfig{
+		Tags: []tagging.Rule{
+			{Pattern: "/guide", Tags: []string{"guide"}},
+			{Selector: "a[href]", Tags: []string{"linking"}},
+		},
+	}
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	pageManifest, err := manifest.Load(filepath.Join(options.outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading manifest: %v", err)
+	}
+
+	tags := make(map[string][]string)
+	for _, entry := range pageManifest.Pages {
+		tags[entry.File] = entry.Tags
+	}
+	if !reflect.DeepEqual(tags["guide.md"], []string{"guide"}) || !reflect.DeepEqual(tags["index.md"], []string{"linking"}) {
+		t.Errorf("manifest tags = %v, want guide.md tagged guide and index.md tagged linking", tags)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	guide, err := os.ReadFile(filepath.Join(options.outputDir, "guide.md"))
+	if err != nil {
+		t.Fatalf("reading guide page: %v", err)
+	}
+	if !strings.HasPrefix(string(guide), "---\ntags:\n  - \"guide\"\n---\n\n# Guide\n") {
+		t.Errorf("guide page does not start with its tags: %s", guide)
+	}
+}
+
+func TestCrawlOnceProgress(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.progress = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, progress.Filename))
+	if err != nil {
+		t.Fatalf("reading progress file: %v", err)
+	}
+
+	var snapshot progress.Snapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		t.Fatalf("decoding progress file: %v", err)
+	}
+
+	if snapshot.Phase != progress.PhaseDone || snapshot.PagesCrawled != 2 || snapshot.PagesSaved != 2 {
+		t.Errorf("unexpected final progress: %+v", snapshot)
+	}
+}
+
+func TestFinishRunArchive(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	options := defaultGetOptions()
+	options.outputDir = filepath.Join(root, "out")
+	options.archivePath = filepath.Join(root, "out.zip")
+	options.archiveCleanup = true
+
+	if err := os.MkdirAll(options.outputDir, 0o750); err != nil {
+		t.Fatalf("creating output directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(options.outputDir, "index.md"), []byte("# Home"), 0o600); err != nil {
+		t.Fatalf("writing page: %v", err)
+	}
+
+	if err := finishRun(options, "https://example.com", crawlResult{}); err != nil {
+		t.Fatalf("finishRun returned error: %v", err)
+	}
+
+	if _, err := os.Stat(options.archivePath); err != nil {
+		t.Errorf("archive was not written: %v", err)
+	}
+	if _, err := os.Stat(options.outputDir); !os.IsNotExist(err) {
+		t.Errorf("expected output directory to be removed, got %v", err)
+	}
+}
+
+func TestCrawlOnceObsidianFlavor(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.flavor = flavor.Obsidian
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "index.md"))
+	if err != nil {
+		t.Fatalf("reading home page: %v", err)
+	}
+
+	page := string(content)
+	if !strings.HasPrefix(page, "---\ntitle: \"Home\"\naliases:\n") {
+		t.Errorf("home page is missing the front matter: %s", page)
+	}
+	if !strings.Contains(page, "[[guide]]") {
+		t.Errorf("home page does not use a wikilink for the guide: %s", page)
+	}
+}
+
+func TestCrawlOnceHugoFlavor(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.flavor = flavor.Hugo
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "content", "_index.md"))
+	if err != nil {
+		t.Fatalf("reading home section: %v", err)
+	}
+
+	page := string(content)
+	if !strings.HasPrefix(page, "---\ntitle: \"Home\"\ndate: ") {
+		t.Errorf("home section is missing the front matter: %s", page)
+	}
+	if !strings.Contains(page, "[guide](/guide/)") {
+		t.Errorf("home section does not link to the guide permalink: %s", page)
+	}
+
+	if _, err := os.Stat(filepath.Join(options.outputDir, "content", "guide.md")); err != nil {
+		t.Errorf("guide page was not written to the content tree: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// commitOutput records the content of the output directory in a git repository,
+// initializing the repository on first use
+func commitOutput(dir, startURL string, result crawlResult) error {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if _, err := runGit(dir, "init", "--quiet"); err != nil {
+			return err
+		}
+	}
+
+	if _, err := runGit(dir, "add", "--all"); err != nil {
+		return err
+	}
+
+	status, err := runGit(dir, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(status) == "" {
+		printStdout("Git: no changes to commit\n")
+		return nil
+	}
+
+	args := []string{"commit", "--quiet", "-m", buildCommitMessage(startURL, result)}
+	if email, _ := runGit(dir, "config", "user.email"); strings.TrimSpace(email) == "" {
+		args = append([]string{"-c", "user.name=CrawlDown", "-c", "user.email=crawldown@localhost"}, args...)
+	}
+
+	if _, err := runGit(dir, args...); err != nil {
+		return err
+	}
+
+	printStdout("Git: committed crawl results in %s\n", dir)
+
+	return nil
+}
+
+// buildCommitMessage describes a crawl run in a commit message
+func buildCommitMessage(startURL string, result crawlResult) string {
+	changes := result.changes
+
+	return fmt.Sprintf("Crawl %s (+%d -%d ~%d)\n\n"+
+		"Pages crawled: %d\n"+
+		"Pages saved: %d\n"+
+		"Added: %d\n"+
+		"Removed: %d\n"+
+		"Modified: %d\n",
+		startURL, len(changes.Added), len(changes.Removed), len(changes.Modified),
+		result.pagesCrawled,
+		result.pagesSaved,
+		len(changes.Added),
+		len(changes.Removed),
+		len(changes.Modified),
+	)
+}
+
+// runGit runs a git command in dir and returns its standard output
+func runGit(dir string, args ...string) (string, error) {
+	//nolint:gosec // Arguments are built by crawldown, not taken verbatim from user input.
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/manifest"
+)
+
+func TestBuildCommitMessage(t *testing.T) {
+	t.Parallel()
+
+	result := crawlResult{
+		pagesCrawled: 3,
+		pagesSaved:   2,
+		changes: manifest.Changes{
+			Added:    []manifest.Entry{{URL: "https://example.com/a"}},
+			Modified: []manifest.Entry{{URL: "https://example.com/b"}},
+		},
+	}
+
+	message := buildCommitMessage("https://example.com", result)
+
+	if !strings.HasPrefix(message, "Crawl https://example.com (+1 -0 ~1)\n\n") {
+		t.Fatalf("unexpected commit subject: %q", message)
+	}
+
+	if !strings.Contains(message, "Pages crawled: 3\nPages saved: 2\n") {
+		t.Fatalf("commit message is missing crawl stats: %q", message)
+	}
+}
+
+func TestCommitOutput(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.md"), []byte("# Home\n"), 0o600); err != nil {
+		t.Fatalf("writing page: %v", err)
+	}
+
+	if err := commitOutput(dir, "https://example.com", crawlResult{}); err != nil {
+		t.Fatalf("commitOutput returned error: %v", err)
+	}
+
+	// A second run without changes must not fail
+	if err := commitOutput(dir, "https://example.com", crawlResult{}); err != nil {
+		t.Fatalf("commitOutput without changes returned error: %v", err)
+	}
+
+	log, err := runGit(dir, "log", "--oneline")
+	if err != nil {
+		t.Fatalf("reading git log: %v", err)
+	}
+
+	if count := len(strings.Split(strings.TrimSpace(log), "\n")); count != 1 {
+		t.Fatalf("expected 1 commit, got %d: %s", count, log)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/sandrolain/crawldown/src/flavor"
+	"github.com/sandrolain/crawldown/src/pagebuffer"
+	"github.com/sandrolain/crawldown/src/render"
+	"github.com/sandrolain/crawldown/src/urlmatch"
+)
+
+// hubLinkEscaper escapes page titles used as link text in hub indexes
+var hubLinkEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+// hubPatterns compiles the --hub URL patterns
+func hubPatterns(options *getOptions) ([]*urlmatch.Pattern, error) {
+	patterns := make([]*urlmatch.Pattern, 0, len(options.hubs))
+	for _, hub := range options.hubs {
+		pattern, err := urlmatch.Compile(hub)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// isHub reports whether a page URL matches one of the hub patterns
+func isHub(patterns []*urlmatch.Pattern, pageURL string) bool {
+	for _, pattern := range patterns {
+		if pattern.Match(pageURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// contentLinks returns the absolute HTTP(S) URLs linked from the main content of a page, in order, without fragments,
+// trailing slashes, or duplicates
+func contentLinks(content, pageURL string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return nil
+	}
+
+	var links []string
+	seen := make(map[string]bool)
+	doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		target, err := base.Parse(strings.TrimSpace(a.AttrOr("href", "")))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			return
+		}
+		target.Fragment = ""
+		link := strings.TrimSuffix(target.String(), "/")
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	})
+	return links
+}
+
+// buildHubIndexes replaces the content of each hub page with a list of the saved pages linked from its main content,
+// titled after the pages, so that listing and category pages become indexes of the mirror.
+// Links are resolved through urlToFile, so that links to aliases, redirects, and merged pages list the page they lead to.
+func buildHubIndexes(pages pagebuffer.Buffer[pageRecord], urlToFile map[string]string, patterns []*urlmatch.Pattern, renderer *render.Renderer, pageFlavor flavor.Flavor) {
+	type child struct{ url, title string }
+	children := make(map[string]child, pages.Len()) // Keyed by file
+	var hubs []string
+	for _, key := range pages.Keys() {
+		page, ok, err := pages.Get(key)
+		if err != nil {
+			printStderr("  Error reading buffered page: %v\n", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		title := strings.TrimSpace(page.title)
+		if title == "" {
+			title = page.pageURL
+		}
+		children[page.filename] = child{url: page.pageURL, title: title}
+		if isHub(patterns, page.pageURL) {
+			hubs = append(hubs, key)
+		}
+	}
+
+	for _, key := range hubs {
+		hub, _, err := pages.Get(key)
+		if err != nil {
+			printStderr("  Error reading buffered page: %v\n", err)
+			continue
+		}
+
+		var index strings.Builder
+		listed := make(map[string]bool)
+		for _, link := range hub.hubLinks {
+			entry, ok := children[urlToFile[link]]
+			if !ok || entry.url == hub.pageURL || listed[entry.url] {
+				continue
+			}
+			listed[entry.url] = true
+			fmt.Fprintf(&index, "- [%s](%s)\n", hubLinkEscaper.Replace(entry.title), entry.url)
+		}
+
+		data := hub.renderData
+		data.Markdown = strings.TrimSuffix(index.String(), "\n")
+		markdown, err := buildPageContent(renderer, pageFlavor, data)
+		if err != nil {
+			printStderr("  Error building the index of %s: %v\n", hub.pageURL, err)
+			continue
+		}
+
+		hub.markdown = markdown
+		hub.renderData = data
+		if err := pages.Put(key, hub); err != nil {
+			printStderr("  Error building the index of %s: %v\n", hub.pageURL, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCrawlOnceHubIndexes(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	page := func(title, body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html><head><title>` + title + `</title></head><body>` +
+				`<nav><a href="/about">About</a></nav><main>` + body + `</main></body></html>`))
+		}
+	}
+	mux.HandleFunc("/", page("Home", `<a href="/category/go">Go</a>`))
+	mux.HandleFunc("/about", page("About", `<p>About us</p>`))
+	mux.HandleFunc("/category/go", page("Go posts", `<h2>Latest</h2><p>Teaser of the first post</p>`+
+		`<a href="/posts/first#intro">Read more</a> <a href="/posts/second/">Second [draft]</a> <a href="/posts/first">First again</a>`+
+		`<a href="/category/go">Self</a> <a href="https://other.example/">Elsewhere</a>`))
+	mux.HandleFunc("/posts/first", page("First post", `<p>First body</p>`))
+	mux.HandleFunc("/posts/second/", page("Second [draft]", `<p>Second body</p>`))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.maxDepth = 3
+	options.hubs = []string{"/category/*"}
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "category-go.md"))
+	if err != nil {
+		t.Fatalf("reading hub page: %v", err)
+	}
+
+	want := "- [First post](posts-first.md)\n- [Second \\[draft\\]](posts-second.md)"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("expected the hub index %q, got %s", want, content)
+	}
+	for _, notWant := range []string{"Teaser", "About", "Elsewhere", "Self"} {
+		if strings.Contains(string(content), notWant) {
+			t.Errorf("expected %q not to be in the hub index, got %s", notWant, content)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(options.outputDir, "posts-first.md")); err != nil {
+		t.Errorf("expected the pages linked from the hub to be saved: %v", err)
+	}
+}
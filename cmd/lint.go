@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/roundtrip"
+)
+
+type lintOptions struct {
+	roundtrip       bool
+	maxDepth        int
+	minScore        float64
+	requestTimeout  int
+	ignoreRobotsTxt bool
+	userAgent       string
+
+	// conversion holds the converter flags, bound and validated as for get
+	conversion *getOptions
+}
+
+// lintPageResult is the round trip result of a single page
+type lintPageResult struct {
+	url    string
+	result roundtrip.Result
+	err    error
+}
+
+func newLintCommand() *cobra.Command {
+	options := lintOptions{conversion: defaultGetOptions()}
+
+	lintCmd := &cobra.Command{
+		Use:           "lint [flags] <url>",
+		Short:         "Measure conversion quality of pages",
+		Long:          "Fetch pages, convert them to Markdown, and report quality checks such as round-trip fidelity.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLint(options, args[0])
+		},
+	}
+
+	flags := lintCmd.Flags()
+	flags.BoolVar(&options.roundtrip, "roundtrip", false, "Render the Markdown back to HTML and report the share of source text preserved per page")
+	flags.IntVarP(&options.maxDepth, "depth", "d", 0, "Crawl depth; 0 only checks the given page")
+	flags.Float64Var(&options.minScore, "min-score", 0, "Fail when any page scores below this round-trip fidelity (0-1)")
+	flags.IntVarP(&options.requestTimeout, "timeout", "t", 60, "Request timeout in seconds")
+	flags.BoolVar(&options.ignoreRobotsTxt, "ignore-robots-txt", false, "Ignore robots.txt while crawling")
+	flags.StringVar(&options.userAgent, "user-agent", "CrawlDown/1.0", "HTTP user agent used for requests")
+	bindConverterFlags(flags, options.conversion)
+
+	return lintCmd
+}
+
+func runLint(options lintOptions, startURL string) error {
+	if !options.roundtrip {
+		return fmt.Errorf("no checks selected: use --roundtrip")
+	}
+
+	if err := validateConverterOptions(options.conversion); err != nil {
+		return err
+	}
+
+	conv, err := converter.NewConverter(converterOptions(options.conversion))
+	if err != nil {
+		return fmt.Errorf("create converter: %w", err)
+	}
+
+	c, err := crawler.NewCrawler(startURL, crawler.Options{
+		MaxDepth:        options.maxDepth,
+		SinglePage:      options.maxDepth == 0,
+		UserAgent:       options.userAgent,
+		IgnoreRobotsTxt: options.ignoreRobotsTxt,
+		RequestTimeout:  options.requestTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("create crawler: %w", err)
+	}
+
+	var results []lintPageResult
+	var resultsMutex sync.Mutex
+
+	c.OnPage(func(page crawler.Page) {
+		result := lintPageResult{url: page.URL}
+
+		markdown, err := conv.Convert(page.Content)
+		if err != nil {
+			result.err = err
+		} else {
+			result.result, result.err = roundtrip.Compare(page.Content, markdown)
+		}
+
+		resultsMutex.Lock()
+		results = append(results, result)
+		resultsMutex.Unlock()
+	})
+
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("crawl: %w", err)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].url < results[j].url
+	})
+
+	printlnStdout()
+	printStdout("%s", formatLintReport(results))
+
+	if failing := countBelow(results, options.minScore); failing > 0 {
+		return fmt.Errorf("%d pages scored below %.2f round-trip fidelity", failing, options.minScore)
+	}
+
+	return nil
+}
+
+// formatLintReport renders the per-page round trip results and their average
+func formatLintReport(results []lintPageResult) string {
+	var builder strings.Builder
+
+	total := 0.0
+	scored := 0
+
+	for _, page := range results {
+		if page.err != nil {
+			builder.WriteString(fmt.Sprintf("[error] %s: %v\n", page.url, page.err))
+			continue
+		}
+
+		r := page.result
+		builder.WriteString(fmt.Sprintf("[%5.1f%%] %s (%d source words, %d missing)\n",
+			r.Score*100, page.url, r.SourceWords, r.MissingWords))
+		if len(r.Missing) > 0 {
+			builder.WriteString("         missing: " + strings.Join(r.Missing, ", ") + "\n")
+		}
+
+		total += r.Score
+		scored++
+	}
+
+	if scored > 0 {
+		builder.WriteString(fmt.Sprintf("\nAverage round-trip fidelity: %.1f%% over %d pages\n", total/float64(scored)*100, scored))
+	} else {
+		builder.WriteString("\nNo pages checked\n")
+	}
+
+	return builder.String()
+}
+
+// countBelow counts pages that failed or scored below the minimum
+func countBelow(results []lintPageResult, minScore float64) int {
+	count := 0
+	for _, page := range results {
+		if page.err != nil || page.result.Score < minScore {
+			count++
+		}
+	}
+	return count
+}
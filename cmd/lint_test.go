@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/roundtrip"
+)
+
+func TestFormatLintReport(t *testing.T) {
+	t.Parallel()
+
+	results := []lintPageResult{
+		{url: "https://example.com/a", result: roundtrip.Result{Score: 1, SourceWords: 10}},
+		{url: "https://example.com/b", result: roundtrip.Result{Score: 0.5, SourceWords: 4, MissingWords: 2, Missing: []string{"lost", "words"}}},
+		{url: "https://example.com/c", err: errors.New("empty HTML content")},
+	}
+
+	report := formatLintReport(results)
+
+	for _, want := range []string{
+		"[100.0%] https://example.com/a (10 source words, 0 missing)",
+		"[ 50.0%] https://example.com/b (4 source words, 2 missing)",
+		"missing: lost, words",
+		"[error] https://example.com/c: empty HTML content",
+		"Average round-trip fidelity: 75.0% over 2 pages",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q:\n%s", want, report)
+		}
+	}
+
+	if got := countBelow(results, 0.9); got != 2 {
+		t.Errorf("countBelow() = %d, want 2", got)
+	}
+}
+
+func TestRunLintRequiresCheck(t *testing.T) {
+	t.Parallel()
+
+	if err := runLint(lintOptions{}, "https://example.com"); err == nil {
+		t.Fatal("expected an error when no check is selected")
+	}
+}
+
+func TestRunLintRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	if err := runLint(lintOptions{roundtrip: true, minScore: 0.9, conversion: defaultGetOptions()}, srv.URL); err != nil {
+		t.Fatalf("runLint returned error: %v", err)
+	}
+}
+
+func TestLintCommandConverterFlags(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestSite(t)
+
+	cmd := newLintCommand()
+	cmd.SetArgs([]string{"--roundtrip", "--tables", "gfm", "--no-raw-html", "--remove-selector", "nav", srv.URL})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("lint with converter flags returned error: %v", err)
+	}
+
+	cmd = newLintCommand()
+	cmd.SetArgs([]string{"--roundtrip", "--tables", "bogus", srv.URL})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid --tables") {
+		t.Fatalf("lint with an invalid --tables returned %v, want an invalid --tables error", err)
+	}
+}
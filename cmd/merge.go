@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/manifest"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+type mergeOptions struct {
+	outputDir string
+}
+
+func newMergeCommand() *cobra.Command {
+	options := mergeOptions{}
+
+	mergeCmd := &cobra.Command{
+		Use:   "merge [flags] <worker-dir>...",
+		Short: "Merge the outputs of workers sharing a crawl",
+		Long: "Merge the output directories of crawldown workers that shared a crawl with --redis into one directory, " +
+			"combining their manifests and linking pages saved by different workers to each other.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMerge(options, args)
+		},
+	}
+
+	mergeCmd.Flags().StringVarP(&options.outputDir, "output", "o", "", "Directory where the merged output is written (required)")
+	_ = mergeCmd.MarkFlagRequired("output")
+
+	return mergeCmd
+}
+
+func runMerge(options mergeOptions, dirs []string) error {
+	merged := manifest.New()
+	for _, dir := range dirs {
+		workerManifest, err := manifest.Load(filepath.Join(dir, manifest.Filename))
+		if err != nil {
+			return fmt.Errorf("load manifest of %s: %w", dir, err)
+		}
+
+		// A page crawled by several workers keeps the copy of the first one
+		skipped := make(map[string]bool)
+		for _, entry := range workerManifest.Pages {
+			if _, exists := merged.Lookup(entry.URL); exists {
+				skipped[entry.File] = true
+				continue
+			}
+			merged.Add(entry)
+		}
+
+		if err := copyWorkerFiles(dir, options.outputDir, skipped); err != nil {
+			return err
+		}
+	}
+
+	urlToFile := make(map[string]string, len(merged.Pages))
+	for _, entry := range merged.Pages {
+		urlToFile[strings.TrimSuffix(entry.URL, "/")] = entry.File
+	}
+
+	// Links to pages saved by other workers were kept as absolute URLs
+	for i, entry := range merged.Pages {
+		if path.Ext(entry.File) != ".md" {
+			continue
+		}
+
+		file := filepath.Join(options.outputDir, filepath.FromSlash(entry.File))
+		//nolint:gosec // The path is built from the output directory and a manifest file name.
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file, err)
+		}
+
+		linked := converter.ConvertLinksToLocalFrom(string(content), entry.URL, entry.File, urlToFile)
+		if linked == string(content) {
+			continue
+		}
+		if err := output.WriteFileAtomic(file, []byte(linked)); err != nil {
+			return fmt.Errorf("write %s: %w", file, err)
+		}
+		merged.Pages[i].Hash = manifest.HashContent([]byte(linked))
+	}
+
+	if err := merged.Save(filepath.Join(options.outputDir, manifest.Filename)); err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+
+	printStdout("Merged %d pages from %d workers into %s\n", len(merged.Pages), len(dirs), options.outputDir)
+	return nil
+}
+
+// copyWorkerFiles copies the files of a worker output directory, except its manifest and the skipped pages.
+// Files already copied from another worker, such as shared assets, are kept.
+func copyWorkerFiles(dir, outputDir string, skipped map[string]bool) error {
+	return filepath.WalkDir(dir, func(source string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, source)
+		if err != nil {
+			return err
+		}
+		if rel == manifest.Filename || skipped[filepath.ToSlash(rel)] {
+			return nil
+		}
+
+		target := filepath.Join(outputDir, rel)
+		if _, err := os.Stat(target); err == nil {
+			return nil
+		}
+
+		//nolint:gosec // The path comes from walking a user-provided directory.
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", source, err)
+		}
+		if err := output.WriteFileAtomic(target, data); err != nil {
+			return fmt.Errorf("write %s: %w", target, err)
+		}
+		return nil
+	})
+}
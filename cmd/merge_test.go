@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/sandrolain/crawldown/src/manifest"
+)
+
+func TestRunMergeLinksWorkerPages(t *testing.T) {
+	t.Parallel()
+
+	first := t.TempDir()
+	second := t.TempDir()
+	outputDir := t.TempDir()
+
+	worker := func(dir string, entry manifest.Entry, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, entry.File), []byte(content), 0o600); err != nil {
+			t.Fatalf("writing fixture: %v", err)
+		}
+		m := manifest.New()
+		m.Add(entry)
+		if err := m.Save(filepath.Join(dir, manifest.Filename)); err != nil {
+			t.Fatalf("writing manifest: %v", err)
+		}
+	}
+	worker(first, manifest.Entry{URL: "https://example.com/", File: "index.md"}, "See the [guide](https://example.com/guide/#setup).\n")
+	worker(second, manifest.Entry{URL: "https://example.com/guide/", File: "guide.md"}, "Back [home](https://example.com/).\n")
+
+	if err := runMerge(mergeOptions{outputDir: outputDir}, []string{first, second}); err != nil {
+		t.Fatalf("runMerge returned error: %v", err)
+	}
+
+	for file, want := range map[string]string{
+		"index.md": "See the [guide](guide.md#setup).\n",
+		"guide.md": "Back [home](index.md).\n",
+	} {
+		//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+		content, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", file, content, want)
+		}
+	}
+
+	merged, err := manifest.Load(filepath.Join(outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading merged manifest: %v", err)
+	}
+	if len(merged.Pages) != 2 {
+		t.Errorf("merged manifest has %d pages, want 2", len(merged.Pages))
+	}
+}
+
+func TestCrawlOnceSharedFrontier(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// Slow pages give both workers links to fetch
+		time.Sleep(100 * time.Millisecond)
+		links := ""
+		if r.URL.Path == "/" {
+			links = `<a href="/a">A</a> <a href="/b">B</a> <a href="/c">C</a> <a href="/d">D</a>`
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Page</title></head><body><main><p>Page ` + r.URL.Path + ` ` + links + `</p></main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	redisServer := miniredis.RunT(t)
+
+	dirs := []string{t.TempDir(), t.TempDir()}
+	var wg sync.WaitGroup
+	for _, dir := range dirs {
+		options := defaultGetOptions()
+		options.outputDir = dir
+		options.requestDelay = 0
+		options.redisURL = "redis://" + redisServer.Addr()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := crawlOnce(options, srv.URL, false); err != nil {
+				t.Errorf("crawlOnce returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	outputDir := t.TempDir()
+	if err := runMerge(mergeOptions{outputDir: outputDir}, dirs); err != nil {
+		t.Fatalf("runMerge returned error: %v", err)
+	}
+
+	merged, err := manifest.Load(filepath.Join(outputDir, manifest.Filename))
+	if err != nil {
+		t.Fatalf("loading merged manifest: %v", err)
+	}
+	if len(merged.Pages) != 5 {
+		t.Fatalf("merged manifest has %d pages, want 5", len(merged.Pages))
+	}
+
+	home, ok := merged.Lookup(srv.URL)
+	if !ok {
+		home, ok = merged.Lookup(srv.URL + "/")
+	}
+	if !ok {
+		t.Fatalf("merged manifest does not list the start page")
+	}
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(outputDir, home.File))
+	if err != nil {
+		t.Fatalf("reading start page: %v", err)
+	}
+	if strings.Contains(string(content), srv.URL+"/") {
+		t.Errorf("start page still links to crawled pages by URL: %s", content)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/metrics"
+)
+
+// startMetricsServer serves the metrics on addr under /metrics and returns a function stopping the server
+func startMetricsServer(addr string, m *metrics.Metrics) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on metrics address: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m.Handler())
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			printStderr("Metrics server stopped: %v\n", err)
+		}
+	}()
+
+	printStdout("Serving metrics on http://%s/metrics\n", listener.Addr())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// fileMode is a flag value holding file permissions, parsed from octal values such as 0644 or 2775
+type fileMode fs.FileMode
+
+func (m *fileMode) String() string {
+	if *m == 0 {
+		return ""
+	}
+
+	bits := uint32(fs.FileMode(*m).Perm())
+	if fs.FileMode(*m)&fs.ModeSetuid != 0 {
+		bits |= 0o4000
+	}
+	if fs.FileMode(*m)&fs.ModeSetgid != 0 {
+		bits |= 0o2000
+	}
+	if fs.FileMode(*m)&fs.ModeSticky != 0 {
+		bits |= 0o1000
+	}
+	return fmt.Sprintf("%04o", bits)
+}
+
+func (m *fileMode) Set(value string) error {
+	mode, err := output.ParseMode(value)
+	if err != nil {
+		return err
+	}
+
+	*m = fileMode(mode)
+	return nil
+}
+
+func (m *fileMode) Type() string {
+	return "mode"
+}
+
+// permissions returns the modes of the files and directories written to a local output directory
+func (o *getOptions) permissions() output.Permissions {
+	return output.Permissions{File: fs.FileMode(o.fileMode), Dir: fs.FileMode(o.dirMode), Umask: o.umask}
+}
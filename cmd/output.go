@@ -22,3 +22,11 @@ func printStderr(format string, args ...any) {
 		return
 	}
 }
+
+// logf prints progress output unless --quiet is set
+func (o *getOptions) logf(format string, args ...any) {
+	if o.quiet {
+		return
+	}
+	printStdout(format, args...)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sandrolain/crawldown/src/shell"
+)
+
+// defaultExecWorkers is the number of --exec-per-page commands run at once unless --exec-workers is set
+const defaultExecWorkers = 4
+
+// pageCommand is a run of the --exec-per-page command for a written page
+type pageCommand struct {
+	file string
+	url  string
+}
+
+// pageHooks runs the --exec-per-page command for written pages on a fixed number of worker goroutines.
+// Running blocks while every worker is busy and the queue is full, so saving slows down to the command rate.
+// All methods do nothing on a nil receiver.
+type pageHooks struct {
+	command string
+	logf    func(format string, args ...any)
+
+	pages  chan pageCommand
+	wg     sync.WaitGroup
+	once   sync.Once
+	failed atomic.Int64
+}
+
+// newPageHooks starts the workers of --exec-per-page, or returns nil when it is not set
+func newPageHooks(options *getOptions) *pageHooks {
+	if options.execPerPage == "" {
+		return nil
+	}
+
+	workers := options.execWorkers
+	if workers == 0 {
+		workers = defaultExecWorkers
+	}
+
+	hooks := &pageHooks{command: options.execPerPage, logf: options.logf, pages: make(chan pageCommand, workers)}
+	hooks.wg.Add(workers)
+	for range workers {
+		go func() {
+			defer hooks.wg.Done()
+			for page := range hooks.pages {
+				hooks.exec(page)
+			}
+		}()
+	}
+	return hooks
+}
+
+// run queues the command for a written page
+func (h *pageHooks) run(file, pageURL string) {
+	if h == nil {
+		return
+	}
+	h.pages <- pageCommand{file: file, url: pageURL}
+}
+
+// wait stops accepting pages and returns the number of failed commands once the queued ones have run
+func (h *pageHooks) wait() int {
+	if h == nil {
+		return 0
+	}
+	h.once.Do(func() { close(h.pages) })
+	h.wg.Wait()
+	return int(h.failed.Load())
+}
+
+// exec runs the command for a page, with {file} and {url} replaced and CRAWLDOWN_FILE and CRAWLDOWN_URL set
+func (h *pageHooks) exec(page pageCommand) {
+	line := shell.Expand(h.command, map[string]string{"file": page.file, "url": page.url})
+	cmd := shell.Command(context.Background(), line)
+	cmd.Env = append(os.Environ(), "CRAWLDOWN_FILE="+page.file, "CRAWLDOWN_URL="+page.url)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	message := strings.TrimSpace(out.String())
+	if err != nil {
+		h.failed.Add(1)
+		printStderr("  Error running --exec-per-page for %s: %v %s\n", page.file, err, message)
+		return
+	}
+	if message != "" {
+		h.logf("  %s\n", message)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/flavor"
+	"github.com/sandrolain/crawldown/src/pagebuffer"
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+// paginationSeparator is placed between the parts of a merged paginated series
+const paginationSeparator = "\n\n"
+
+// mergePaginatedPages merges each paginated series into the record of its first page.
+// Later parts are removed from pages and their URLs are mapped to the file of the first page.
+func mergePaginatedPages(pages pagebuffer.Buffer[pageRecord], urlToFile map[string]string, renderer *render.Renderer, pageFlavor flavor.Flavor) {
+	for _, chain := range paginationChains(pages) {
+		parts := make([]pageRecord, 0, len(chain))
+		for _, key := range chain {
+			part, _, err := pages.Get(key)
+			if err != nil {
+				printStderr("  Error reading buffered page: %v\n", err)
+				break
+			}
+			parts = append(parts, part)
+		}
+		if len(parts) != len(chain) {
+			continue
+		}
+
+		head := parts[0]
+		data := head.renderData
+
+		bodies := make([]string, 0, len(chain))
+		links := append([]string{}, head.links...)
+		for i, part := range parts {
+			bodies = append(bodies, part.renderData.Markdown)
+			if i > 0 {
+				links = append(links, part.links...)
+			}
+		}
+		data.Markdown = strings.Join(bodies, paginationSeparator)
+
+		markdown, err := buildPageContent(renderer, pageFlavor, data)
+		if err != nil {
+			printStderr("  Error merging pages of %s: %v\n", head.pageURL, err)
+			continue
+		}
+
+		head.markdown = markdown
+		head.renderData = data
+		head.links = links
+		if err := pages.Put(chain[0], head); err != nil {
+			printStderr("  Error merging pages of %s: %v\n", head.pageURL, err)
+			continue
+		}
+
+		for _, key := range chain[1:] {
+			if err := pages.Delete(key); err != nil {
+				printStderr("  Error merging pages of %s: %v\n", head.pageURL, err)
+			}
+			urlToFile[key] = head.filename
+		}
+
+		printStdout("Merged %d pages of %s into %s\n", len(chain), head.pageURL, head.filename)
+	}
+}
+
+// paginationChains returns the keys of the crawled paginated series with more than one page, first page first
+func paginationChains(pages pagebuffer.Buffer[pageRecord]) [][]string {
+	// Only the series links are read, so that the pages do not all have to be loaded at once
+	series := make(map[string]pageRecord, pages.Len())
+	for _, key := range pages.Keys() {
+		page, ok, err := pages.Get(key)
+		if err != nil {
+			printStderr("  Error reading buffered page: %v\n", err)
+			continue
+		}
+		if ok {
+			series[key] = pageRecord{next: page.next, prev: page.prev}
+		}
+	}
+
+	next := make(map[string]string)
+	for key, page := range series {
+		if _, exists := series[page.next]; exists && page.next != key {
+			next[key] = page.next
+		}
+	}
+	for key, page := range series {
+		if _, exists := series[page.prev]; exists && page.prev != key {
+			if _, linked := next[page.prev]; !linked {
+				next[page.prev] = key
+			}
+		}
+	}
+
+	hasPrevious := make(map[string]bool, len(next))
+	for _, target := range next {
+		hasPrevious[target] = true
+	}
+
+	var heads []string
+	for key := range next {
+		if !hasPrevious[key] {
+			heads = append(heads, key)
+		}
+	}
+	sort.Strings(heads)
+
+	seen := make(map[string]bool)
+	var chains [][]string
+	for _, head := range heads {
+		var chain []string
+		for key := head; key != "" && !seen[key]; key = next[key] {
+			seen[key] = true
+			chain = append(chain, key)
+		}
+		if len(chain) > 1 {
+			chains = append(chains, chain)
+		}
+	}
+
+	return chains
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/pagebuffer"
+)
+
+func TestPaginationChains(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		pages map[string]pageRecord
+		want  [][]string
+	}{
+		{
+			name: "next links",
+			pages: map[string]pageRecord{
+				"a":  {next: "a2"},
+				"a2": {next: "a3"},
+				"a3": {},
+				"b":  {},
+			},
+			want: [][]string{{"a", "a2", "a3"}},
+		},
+		{
+			name: "prev links only",
+			pages: map[string]pageRecord{
+				"a":  {},
+				"a2": {prev: "a"},
+			},
+			want: [][]string{{"a", "a2"}},
+		},
+		{
+			name: "next to a page that was not crawled",
+			pages: map[string]pageRecord{
+				"a": {next: "a2"},
+			},
+			want: nil,
+		},
+		{
+			name: "cycle without a first page",
+			pages: map[string]pageRecord{
+				"a": {next: "b"},
+				"b": {next: "a"},
+			},
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			pages := pagebuffer.NewMemory[pageRecord]()
+			for key, page := range test.pages {
+				_ = pages.Put(key, page)
+			}
+
+			if got := paginationChains(pages); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestCrawlOnceMergePagination(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body><main><a href="/story">Story</a></main></body></html>`))
+	})
+	mux.HandleFunc("/story", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`<html><head><title>Story - Page 2</title><link rel="prev" href="/story"></head><body><main><p>Second part</p></main></body></html>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><head><title>Story</title><link rel="next" href="/story?page=2"></head><body><main><p>First part</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+	options.maxDepth = 2
+	options.followPagination = true
+	options.mergePagination = true
+
+	if _, err := crawlOnce(options, srv.URL, false); err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	content, err := os.ReadFile(filepath.Join(options.outputDir, "story.md"))
+	if err != nil {
+		t.Fatalf("reading merged page: %v", err)
+	}
+
+	if !strings.Contains(string(content), "First part\n\nSecond part") {
+		t.Errorf("expected both parts in the merged page, got %s", content)
+	}
+
+	if _, err := os.Stat(filepath.Join(options.outputDir, "story-page-2.md")); !os.IsNotExist(err) {
+		t.Errorf("expected the second part not to be saved separately, got %v", err)
+	}
+}
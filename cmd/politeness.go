@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Politeness profiles bundling the pace of a crawl
+const (
+	politenessAggressive = "aggressive"
+	politenessNormal     = "normal"
+	politenessGentle     = "gentle"
+)
+
+// politenessProfiles are the flag values of each politeness profile, applied to the flags not set on the command line
+var politenessProfiles = map[string]map[string]string{
+	politenessAggressive: {"concurrency": "8", "delay": "0", "jitter": "0s", "retries": "1"},
+	politenessNormal:     {"concurrency": "2", "delay": "1", "jitter": "500ms", "retries": "2"},
+	politenessGentle:     {"concurrency": "1", "delay": "5", "jitter": "2s", "retries": "3"},
+}
+
+// applyPoliteness sets the flags of the politeness profile that were not changed on the command line
+func applyPoliteness(options *getOptions, flags *pflag.FlagSet) error {
+	if options.politeness == "" {
+		return nil
+	}
+
+	values, ok := politenessProfiles[options.politeness]
+	if !ok {
+		return fmt.Errorf("invalid --politeness: unknown profile %q (expected %s, %s, or %s)",
+			options.politeness, politenessAggressive, politenessNormal, politenessGentle)
+	}
+
+	for name, value := range values {
+		flag := flags.Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid --politeness: --%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// jitterDuration is a flag value holding the random time added to the delay of each request,
+// which is derived from the delay until it is set
+type jitterDuration struct {
+	value time.Duration
+	set   bool
+}
+
+func (j *jitterDuration) String() string {
+	if !j.set {
+		return ""
+	}
+	return j.value.String()
+}
+
+func (j *jitterDuration) Set(value string) error {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	j.value, j.set = duration, true
+	return nil
+}
+
+func (j *jitterDuration) Type() string {
+	return "duration"
+}
+
+// crawlerJitter returns the jitter of the crawler options, where 0 derives it from the delay and a negative value adds none
+func (j *jitterDuration) crawlerJitter() time.Duration {
+	switch {
+	case !j.set:
+		return 0
+	case j.value == 0:
+		return -1
+	}
+	return j.value
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyPoliteness(t *testing.T) {
+	t.Parallel()
+
+	options := defaultGetOptions()
+	cmd := newProfileTestCommand(options)
+	if err := cmd.ParseFlags([]string{"--politeness", "gentle", "--delay", "2"}); err != nil {
+		t.Fatalf("parsing flags: %v", err)
+	}
+
+	if err := applyPoliteness(options, cmd.Flags()); err != nil {
+		t.Fatalf("applyPoliteness() error = %v", err)
+	}
+
+	if options.requestDelay != 2 {
+		t.Errorf("requestDelay = %d, want the 2 given on the command line", options.requestDelay)
+	}
+	if options.concurrency != 1 || options.retries != 3 {
+		t.Errorf("concurrency, retries = %d, %d, want 1, 3", options.concurrency, options.retries)
+	}
+	if got := options.jitter.crawlerJitter(); got != 2*time.Second {
+		t.Errorf("jitter = %v, want 2s", got)
+	}
+}
+
+func TestApplyPolitenessUnknownProfile(t *testing.T) {
+	t.Parallel()
+
+	options := defaultGetOptions()
+	cmd := newProfileTestCommand(options)
+	if err := cmd.ParseFlags([]string{"--politeness", "reckless"}); err != nil {
+		t.Fatalf("parsing flags: %v", err)
+	}
+
+	if err := applyPoliteness(options, cmd.Flags()); err == nil {
+		t.Fatal("expected an error for an unknown profile")
+	}
+}
+
+func TestJitterDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "derives the jitter when unset", want: 0},
+		{name: "disables the jitter when 0", value: "0s", want: -1},
+		{name: "keeps a set jitter", value: "750ms", want: 750 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var jitter jitterDuration
+			if test.value != "" {
+				if err := jitter.Set(test.value); err != nil {
+					t.Fatalf("Set(%q) error = %v", test.value, err)
+				}
+			}
+			if got := jitter.crawlerJitter(); got != test.want {
+				t.Errorf("crawlerJitter() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/sandrolain/crawldown/src/postprocess"
+)
+
+// defaultPostprocessWorkers is the number of pages post-processed at once unless --postprocess-workers is set
+const defaultPostprocessWorkers = 4
+
+// newPostprocessor returns the processor the Markdown of each page is passed through,
+// or nil when neither --postprocess-cmd nor --postprocess-url is set
+func newPostprocessor(options *getOptions) postprocess.Processor {
+	var processor postprocess.Processor
+	switch {
+	case options.postprocessCmd != "":
+		processor = postprocess.CommandProcessor{Command: options.postprocessCmd, Timeout: options.postprocessTimeout}
+	case options.postprocessURL != "":
+		processor = postprocess.NewAPIProcessor(options.postprocessURL, options.postprocessModel, options.postprocessPrompt, options.postprocessTimeout)
+	default:
+		return nil
+	}
+
+	workers := options.postprocessWorkers
+	if workers == 0 {
+		workers = defaultPostprocessWorkers
+	}
+	return postprocess.Limit(processor, workers, options.postprocessRate)
+}
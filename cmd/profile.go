@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/sandrolain/crawldown/src/output"
+	"github.com/sandrolain/crawldown/src/profile"
+)
+
+// profilePrefix marks a profile reference in run arguments
+const profilePrefix = "@"
+
+// profilePathFlags are flags holding local paths, stored as absolute paths so profiles work from any directory
+var profilePathFlags = map[string]bool{
+	"output":    true,
+	"config":    true,
+	"changelog": true,
+	"archive":   true,
+	"summary":   true,
+	"spill-dir": true,
+	"ca-cert":   true,
+	"template":  true,
+}
+
+func newRunCommand() *cobra.Command {
+	options := defaultGetOptions()
+
+	runCmd := &cobra.Command{
+		Use:           "run @<profile> [flags] [url]",
+		Short:         "Run a crawl from a saved profile",
+		Long:          "Load a profile saved with 'crawldown profile save' and run it. Flags and a URL given on the command line override the profile.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openProfileStore()
+			if err != nil {
+				return err
+			}
+
+			urlArgs, err := loadRunProfile(store, cmd.Flags(), args)
+			if err != nil {
+				return err
+			}
+
+			if err := applyPoliteness(options, cmd.Flags()); err != nil {
+				return err
+			}
+			if err := validateGetInvocation(options, urlArgs); err != nil {
+				return err
+			}
+
+			return runGet(options, urlArgs)
+		},
+	}
+
+	bindGetFlags(runCmd, options)
+
+	return runCmd
+}
+
+func newProfileCommand() *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage saved crawl profiles",
+		Long:  "Save, list, and delete named crawl profiles stored in the user configuration directory.",
+	}
+
+	profileCmd.AddCommand(newProfileSaveCommand(), newProfileListCommand(), newProfileDeleteCommand())
+
+	return profileCmd
+}
+
+func newProfileSaveCommand() *cobra.Command {
+	saveCmd := &cobra.Command{
+		Use:           "save <name> [flags] [url]",
+		Short:         "Save crawl flags and URL as a named profile",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openProfileStore()
+			if err != nil {
+				return err
+			}
+
+			p, err := captureProfile(args[0], cmd.Flags(), args[1:])
+			if err != nil {
+				return err
+			}
+
+			if err := store.Save(p); err != nil {
+				return fmt.Errorf("save profile: %w", err)
+			}
+
+			printStdout("Saved profile %s (%d flags)\n", p.Name, len(p.Flags))
+			return nil
+		},
+	}
+
+	bindGetFlags(saveCmd, defaultGetOptions())
+
+	return saveCmd
+}
+
+func newProfileListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:           "list",
+		Short:         "List saved profiles",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openProfileStore()
+			if err != nil {
+				return err
+			}
+
+			profiles, err := store.List()
+			if err != nil {
+				return fmt.Errorf("list profiles: %w", err)
+			}
+
+			if len(profiles) == 0 {
+				printStdout("No profiles saved in %s\n", store.Dir)
+				return nil
+			}
+
+			printStdout("%s", formatProfileList(profiles))
+			return nil
+		},
+	}
+}
+
+func newProfileDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:           "delete <name>",
+		Short:         "Delete a saved profile",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openProfileStore()
+			if err != nil {
+				return err
+			}
+
+			if err := store.Delete(args[0]); err != nil {
+				return fmt.Errorf("delete profile: %w", err)
+			}
+
+			printStdout("Deleted profile %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func openProfileStore() (*profile.Store, error) {
+	dir, err := profile.DefaultDir()
+	if err != nil {
+		return nil, fmt.Errorf("open profiles: %w", err)
+	}
+
+	return profile.NewStore(dir), nil
+}
+
+// captureProfile records the flags set on the command line into a profile
+func captureProfile(name string, flags *pflag.FlagSet, args []string) (profile.Profile, error) {
+	if err := profile.ValidateName(name); err != nil {
+		return profile.Profile{}, err
+	}
+
+	p := profile.Profile{
+		Name:    name,
+		Flags:   make(map[string][]string),
+		SavedAt: time.Now().UTC(),
+	}
+
+	if len(args) > 0 {
+		p.URL = args[0]
+	}
+
+	var captureErr error
+	flags.Visit(func(flag *pflag.Flag) {
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			p.Flags[flag.Name] = sliceValue.GetSlice()
+			return
+		}
+
+		value := flag.Value.String()
+		if profilePathFlags[flag.Name] && value != "" && !output.IsRemote(value) {
+			absolute, err := filepath.Abs(value)
+			if err != nil {
+				captureErr = fmt.Errorf("resolve --%s: %w", flag.Name, err)
+				return
+			}
+			value = absolute
+		}
+		p.Flags[flag.Name] = []string{value}
+	})
+
+	return p, captureErr
+}
+
+// loadRunProfile applies the referenced profile to flags not set on the command line and returns the URL arguments
+func loadRunProfile(store *profile.Store, flags *pflag.FlagSet, args []string) ([]string, error) {
+	name, found := strings.CutPrefix(args[0], profilePrefix)
+	if !found {
+		return nil, fmt.Errorf("expected a profile reference like @name, got %q", args[0])
+	}
+
+	p, err := store.Load(name)
+	if err != nil {
+		return nil, fmt.Errorf("load profile: %w", err)
+	}
+
+	if err := applyProfile(p, flags); err != nil {
+		return nil, err
+	}
+
+	if len(args) > 1 {
+		return args[1:], nil
+	}
+	if p.URL != "" {
+		return []string{p.URL}, nil
+	}
+
+	return nil, nil
+}
+
+// applyProfile sets the profile flags that were not changed on the command line
+func applyProfile(p profile.Profile, flags *pflag.FlagSet) error {
+	for name, values := range p.Flags {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("profile %s: unknown flag --%s", p.Name, name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			if err := sliceValue.Replace(values); err != nil {
+				return fmt.Errorf("profile %s: invalid --%s: %w", p.Name, name, err)
+			}
+			continue
+		}
+
+		if len(values) != 1 {
+			return fmt.Errorf("profile %s: --%s expects a single value", p.Name, name)
+		}
+		if err := flag.Value.Set(values[0]); err != nil {
+			return fmt.Errorf("profile %s: invalid --%s: %w", p.Name, name, err)
+		}
+	}
+
+	return nil
+}
+
+// formatProfileList renders saved profiles one per line
+func formatProfileList(profiles []profile.Profile) string {
+	var builder strings.Builder
+
+	for _, p := range profiles {
+		target := p.URL
+		if target == "" {
+			target = "(no URL)"
+		}
+		builder.WriteString(fmt.Sprintf("%s\t%s\t%d flags\n", p.Name, target, len(p.Flags)))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandrolain/crawldown/src/profile"
+)
+
+func newProfileTestCommand(options *getOptions) *cobra.Command {
+	cmd := &cobra.Command{Use: "test"}
+	bindGetFlags(cmd, options)
+	return cmd
+}
+
+func TestProfileSaveAndRun(t *testing.T) {
+	t.Parallel()
+
+	store := profile.NewStore(t.TempDir())
+
+	saveCmd := newProfileTestCommand(defaultGetOptions())
+	if err := saveCmd.ParseFlags([]string{"-o", "out", "-d", "4", "-e", "/admin", "-e", "/private", "--format", "html-site", "--archive", "site.zip"}); err != nil {
+		t.Fatalf("parsing save flags: %v", err)
+	}
+
+	p, err := captureProfile("docs", saveCmd.Flags(), []string{"https://example.com/docs"})
+	if err != nil {
+		t.Fatalf("capturing profile: %v", err)
+	}
+	if !filepath.IsAbs(p.Flags["output"][0]) {
+		t.Errorf("expected output path to be stored as absolute, got %q", p.Flags["output"][0])
+	}
+	if !filepath.IsAbs(p.Flags["archive"][0]) {
+		t.Errorf("expected archive path to be stored as absolute, got %q", p.Flags["archive"][0])
+	}
+	if err := store.Save(p); err != nil {
+		t.Fatalf("saving profile: %v", err)
+	}
+
+	options := defaultGetOptions()
+	runCmd := newProfileTestCommand(options)
+	if err := runCmd.ParseFlags([]string{"-d", "1"}); err != nil {
+		t.Fatalf("parsing run flags: %v", err)
+	}
+
+	args, err := loadRunProfile(store, runCmd.Flags(), []string{"@docs"})
+	if err != nil {
+		t.Fatalf("loading profile: %v", err)
+	}
+
+	if !reflect.DeepEqual(args, []string{"https://example.com/docs"}) {
+		t.Errorf("expected profile URL argument, got %v", args)
+	}
+	if options.maxDepth != 1 {
+		t.Errorf("expected command line depth 1 to override the profile, got %d", options.maxDepth)
+	}
+	if options.format != formatHTMLSite {
+		t.Errorf("expected format from profile, got %q", options.format)
+	}
+	if !reflect.DeepEqual(options.excludedPaths, []string{"/admin", "/private"}) {
+		t.Errorf("expected excluded paths from profile, got %v", options.excludedPaths)
+	}
+	if !strings.HasSuffix(options.outputDir, "out") {
+		t.Errorf("expected output directory from profile, got %q", options.outputDir)
+	}
+}
+
+func TestLoadRunProfileErrors(t *testing.T) {
+	t.Parallel()
+
+	store := profile.NewStore(t.TempDir())
+	if err := store.Save(profile.Profile{Name: "broken", Flags: map[string][]string{"no-such-flag": {"x"}}}); err != nil {
+		t.Fatalf("saving profile: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing prefix", args: []string{"docs"}},
+		{name: "unknown profile", args: []string{"@missing"}},
+		{name: "unknown flag", args: []string{"@broken"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := newProfileTestCommand(defaultGetOptions())
+			if _, err := loadRunProfile(store, cmd.Flags(), test.args); err == nil {
+				t.Fatal("expected an error but got nil")
+			}
+		})
+	}
+}
+
+func TestFormatProfileList(t *testing.T) {
+	t.Parallel()
+
+	got := formatProfileList([]profile.Profile{
+		{Name: "blog", Flags: map[string][]string{"depth": {"1"}}},
+		{Name: "docs", URL: "https://example.com/docs"},
+	})
+
+	want := "blog\t(no URL)\t1 flags\ndocs\thttps://example.com/docs\t0 flags\n"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/manifest"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// pruneBlocker returns why stale files must not be deleted after a crawl, or "" when the crawl covered the whole site.
+// Pages missing from an incomplete crawl may still exist, so only requests answered with 404 or 410,
+// which are how deleted pages usually answer, are not counted as failures.
+func pruneBlocker(errors []crawler.CrawlError, failures []pageFailure, budgetHit bool) string {
+	if budgetHit {
+		return "the page or time budget was reached"
+	}
+	if len(failures) > 0 {
+		return fmt.Sprintf("%d pages failed to process", len(failures))
+	}
+
+	failed := 0
+	for _, crawlErr := range errors {
+		if crawlErr.Status != http.StatusNotFound && crawlErr.Status != http.StatusGone {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Sprintf("%d requests failed", failed)
+	}
+	return ""
+}
+
+// pruneStaleFiles removes the page files of the previous run that no page of the current run was saved in,
+// returning the number of files removed
+func pruneStaleFiles(writer output.Writer, previous, current *manifest.Manifest, logf func(format string, args ...any)) int {
+	files := make(map[string]bool, len(current.Pages))
+	for _, entry := range current.Pages {
+		files[converter.FoldFilename(entry.File)] = true
+	}
+
+	var stale []string
+	for _, entry := range previous.Pages {
+		// A file renamed to another case is the same file on case-insensitive file systems, so it is kept
+		if entry.File == "" {
+			continue
+		}
+		// Entries of a manifest edited or merged from elsewhere must not delete files outside the output
+		if !filepath.IsLocal(filepath.FromSlash(entry.File)) {
+			printStderr("Not pruning %s: not a path inside the output\n", entry.File)
+			continue
+		}
+		if !files[converter.FoldFilename(entry.File)] {
+			files[converter.FoldFilename(entry.File)] = true
+			stale = append(stale, entry.File)
+		}
+	}
+	sort.Strings(stale)
+
+	pruned := 0
+	for _, file := range stale {
+		if err := writer.Remove(file); err != nil {
+			printStderr("Error removing %s: %v\n", writer.Location(file), err)
+			continue
+		}
+		logf("  Pruned: %s\n", writer.Location(file))
+		pruned++
+	}
+	return pruned
+}
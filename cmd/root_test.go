@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestValidateGetInvocation(t *testing.T) {
 	t.Parallel()
@@ -39,6 +42,568 @@ func TestValidateGetInvocation(t *testing.T) {
 			args:    []string{"https://example.com", "https://example.org"},
 			wantErr: true,
 		},
+		{
+			name:    "accepts store-only without output",
+			options: &getOptions{store: "sqlite:crawl.db", storeOnly: true},
+			args:    []string{"https://example.com"},
+		},
+		{
+			name:    "rejects store-only without store",
+			options: &getOptions{outputDir: "./out", storeOnly: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects metrics address without watch",
+			options: &getOptions{outputDir: "./out", metricsAddr: "127.0.0.1:9090"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown heading anchor style",
+			options: &getOptions{outputDir: "./out", headingAnchors: "comment"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown image format",
+			options: &getOptions{outputDir: "./out", downloadImages: true, imageFormat: "avif"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects image processing without image downloads",
+			options: &getOptions{outputDir: "./out", stripImageMetadata: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects invalid download extension",
+			options: &getOptions{outputDir: "./out", downloadExtensions: []string{"tar.gz"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown media policy",
+			options: &getOptions{outputDir: "./out", media: "embed"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown traversal",
+			options: &getOptions{outputDir: "./out", traversal: "random"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects invalid nav selector",
+			options: &getOptions{outputDir: "./out", navSelector: "nav["},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects priority without weight",
+			options: &getOptions{outputDir: "./out", priorities: []string{"/docs/*"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects negative max pages",
+			options: &getOptions{outputDir: "./out", maxPages: -1},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects negative convert workers",
+			options: &getOptions{outputDir: "./out", convertWorkers: -1},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects force with no clobber",
+			options: &getOptions{outputDir: "./out", force: true, noClobber: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects stream with merged pagination",
+			options: &getOptions{outputDir: "./out", stream: true, mergePagination: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects prune with single",
+			options: &getOptions{outputDir: "./out", prune: true, singleURL: "https://example.com/page"},
+			args:    []string{},
+			wantErr: true,
+		},
+		{
+			name:    "rejects prune with redis",
+			options: &getOptions{outputDir: "./out", prune: true, redisURL: "redis://localhost:6379/0"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects file mode with s3 output",
+			options: &getOptions{outputDir: "s3://bucket/prefix", fileMode: 0o644},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects boilerplate share above one",
+			options: &getOptions{outputDir: "./out", stripBoilerplate: 1.5},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects boilerplate report without stripping",
+			options: &getOptions{outputDir: "./out", boilerplateReport: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects summaries longer than three sentences",
+			options: &getOptions{outputDir: "./out", summarize: 4},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects post-processing command with API",
+			options: &getOptions{outputDir: "./out", postprocessCmd: "cat", postprocessURL: "http://localhost:11434/v1", postprocessModel: "llama3", postprocessPrompt: "Clean up"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects post-processing API without prompt",
+			options: &getOptions{outputDir: "./out", postprocessURL: "http://localhost:11434/v1", postprocessModel: "llama3"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects webhook without http scheme",
+			options: &getOptions{outputDir: "./out", webhookURL: "hooks.example.com/crawl"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown webhook events",
+			options: &getOptions{outputDir: "./out", webhookURL: "https://hooks.example.com/crawl", webhookEvents: []string{"crawl.done"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects exec workers without command",
+			options: &getOptions{outputDir: "./out", execWorkers: 2},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects elasticsearch index without elasticsearch",
+			options: &getOptions{outputDir: "./out", elasticsearchIndex: "docs"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects uppercase elasticsearch index",
+			options: &getOptions{outputDir: "./out", elasticsearchBulk: true, elasticsearchIndex: "Docs"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects vector store without embedding model",
+			options: &getOptions{outputDir: "./out", vectorStore: "qdrant:http://localhost:6333/docs", embeddingURL: "http://localhost:11434/v1"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects chunk size without vector store",
+			options: &getOptions{outputDir: "./out", chunkSize: 1000},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects chunk overlap of half the chunk size",
+			options: &getOptions{outputDir: "./out", vectorStore: "qdrant:http://localhost:6333/docs", embeddingURL: "http://localhost:11434/v1", embeddingModel: "nomic-embed-text", chunkSize: 400, chunkOverlap: 200},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "accepts pandoc flavor with grid tables and backslash line breaks",
+			options: &getOptions{outputDir: "./out", flavor: "pandoc", tableSyntax: "grid", lineBreaks: "backslash", noRawHTML: true},
+			args:    []string{"https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "accepts mkdocs dialect",
+			options: &getOptions{outputDir: "./out", dialect: "mkdocs"},
+			args:    []string{"https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "rejects unknown dialect",
+			options: &getOptions{outputDir: "./out", dialect: "markdown"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown table syntax",
+			options: &getOptions{outputDir: "./out", tableSyntax: "rst"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown line break style",
+			options: &getOptions{outputDir: "./out", lineBreaks: "br"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects invalid require selector",
+			options: &getOptions{outputDir: "./out", requireSelectors: []string{"article["}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects hub with stream",
+			options: &getOptions{outputDir: "./out", hubs: []string{"/category/*"}, stream: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects empty hub pattern",
+			options: &getOptions{outputDir: "./out", hubs: []string{""}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "accepts index file",
+			options: &getOptions{outputDir: "./out", indexFile: "index.md"},
+			args:    []string{"https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "rejects index file in a subdirectory",
+			options: &getOptions{outputDir: "./out", indexFile: "docs/README.md"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects index file with html site format",
+			options: &getOptions{outputDir: "./out", indexFile: "README.md", format: "html-site"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects summary md with html site format",
+			options: &getOptions{outputDir: "./out", summaryMD: true, format: "html-site"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects index file named like the summary md",
+			options: &getOptions{outputDir: "./out", summaryMD: true, indexFile: "summary.md"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "accepts mdbook format",
+			options: &getOptions{outputDir: "./out", format: formatMdBook},
+			args:    []string{"https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "rejects flavor with mdbook format",
+			options: &getOptions{outputDir: "./out", flavor: "hugo", format: formatMdBook},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects summary md with mdbook format",
+			options: &getOptions{outputDir: "./out", summaryMD: true, format: formatMdBook},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects filename from title with mdbook format",
+			options: &getOptions{outputDir: "./out", filenameFrom: "title", format: formatMdBook},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "accepts transport options",
+			options: &getOptions{outputDir: "./out", noKeepAlive: true, maxIdleConnsPerHost: 8, noHTTP2: true, tlsMinVersion: "1.3", caCert: "ca.pem"},
+			args:    []string{"https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "rejects unknown tls min version",
+			options: &getOptions{outputDir: "./out", tlsMinVersion: "1.4"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects negative max idle conns per host",
+			options: &getOptions{outputDir: "./out", maxIdleConnsPerHost: -1},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects insecure skip verify with ca cert",
+			options: &getOptions{outputDir: "./out", insecureSkipVerify: true, caCert: "ca.pem"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "accepts resolve",
+			options: &getOptions{outputDir: "./out", resolve: []string{"docs.example.com:10.0.0.5", "api.example.com:[2001:db8::1]"}},
+			args:    []string{"https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "rejects resolve without ip",
+			options: &getOptions{outputDir: "./out", resolve: []string{"docs.example.com"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects negative concurrency",
+			options: &getOptions{outputDir: "./out", concurrency: -1},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects negative jitter",
+			options: &getOptions{outputDir: "./out", jitter: jitterDuration{value: -time.Second, set: true}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects redis key without redis",
+			options: &getOptions{outputDir: "./out", redisKey: "docs"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects depth-first with redis",
+			options: &getOptions{outputDir: "./out", redisURL: "redis://localhost:6379", traversal: "depth-first"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unsupported accept encoding",
+			options: &getOptions{outputDir: "./out", acceptEncodings: []string{"gzip", "lzma"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects quiet with verbose",
+			options: &getOptions{outputDir: "./out", quiet: true, verbose: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "accepts archive outside the output directory",
+			options: &getOptions{outputDir: "./out", archivePath: "./out.tar.gz", archiveCleanup: true},
+			args:    []string{"https://example.com"},
+		},
+		{
+			name:    "rejects archive inside the output directory",
+			options: &getOptions{outputDir: "./out", archivePath: "./out/site.zip"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unsupported archive extension",
+			options: &getOptions{outputDir: "./out", archivePath: "./out.rar"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects archive for remote output",
+			options: &getOptions{outputDir: "s3://bucket/prefix", archivePath: "./out.zip"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects archive cleanup without archive",
+			options: &getOptions{outputDir: "./out", archiveCleanup: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects archive cleanup in watch mode",
+			options: &getOptions{outputDir: "./out", archivePath: "./out.zip", archiveCleanup: true, watch: true, watchInterval: time.Hour},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown flavor",
+			options: &getOptions{outputDir: "./out", flavor: "vimwiki"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects obsidian flavor for html site",
+			options: &getOptions{outputDir: "./out", flavor: "obsidian", format: formatHTMLSite},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects confluence with html site format",
+			options: &getOptions{outputDir: "./out", format: formatHTMLSite, confluence: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects docusaurus with obsidian flavor",
+			options: &getOptions{outputDir: "./out", flavor: "obsidian", docusaurus: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects metadata front matter with hugo flavor",
+			options: &getOptions{outputDir: "./out", flavor: "hugo", metadataFrontMatter: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects external depth without following external links",
+			options: &getOptions{outputDir: "./out", externalDepth: 1},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "accepts allowed domains with external links",
+			options: &getOptions{outputDir: "./out", followExternalLinks: true, allowDomains: []string{"github.com"}, externalDepth: 1},
+			args:    []string{"https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "rejects path limit without max",
+			options: &getOptions{outputDir: "./out", limitPaths: []string{"/blog/*"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects invalid skip title pattern",
+			options: &getOptions{outputDir: "./out", skipTitles: []string{"(404"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects negative min words",
+			options: &getOptions{outputDir: "./out", minWords: -1},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects error rate above one",
+			options: &getOptions{outputDir: "./out", maxErrorRate: 1.5},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects negative retries",
+			options: &getOptions{outputDir: "./out", retries: -1},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects success status to keep",
+			options: &getOptions{outputDir: "./out", keepStatuses: []int{200}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "accepts error statuses",
+			options: &getOptions{outputDir: "./out", keepStatuses: []int{404, 410}, followStatuses: []int{403}},
+			args:    []string{"https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "rejects unknown normalization",
+			options: &getOptions{outputDir: "./out", normalize: []string{"emoji"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown embed policy",
+			options: &getOptions{outputDir: "./out", embeds: "keep"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects embed rule without policy",
+			options: &getOptions{outputDir: "./out", embedRules: []string{"https://www.youtube.com/*"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown filename strategy",
+			options: &getOptions{outputDir: "./out", filenameFrom: "uuid"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects title filenames with hugo flavor",
+			options: &getOptions{outputDir: "./out", filenameFrom: "title", flavor: "hugo"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects invalid strip selector",
+			options: &getOptions{outputDir: "./out", stripSelectors: []string{"div[unclosed"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects invalid remove selector",
+			options: &getOptions{outputDir: "./out", removeSelectors: []string{"nav >"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects invalid language",
+			options: &getOptions{outputDir: "./out", languages: []string{"english"}},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "accepts language with region",
+			options: &getOptions{outputDir: "./out", languages: []string{"en", "pt-BR"}},
+			args:    []string{"https://example.com"},
+			wantErr: false,
+		},
+		{
+			name:    "rejects progress with store-only",
+			options: &getOptions{store: "sqlite:crawl.db", storeOnly: true, progress: true, progressInterval: time.Second},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects non-positive progress interval",
+			options: &getOptions{outputDir: "./out", progress: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects git commit for remote output",
+			options: &getOptions{outputDir: "s3://bucket/prefix", gitCommit: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects unknown output format",
+			options: &getOptions{outputDir: "./out", format: "pdf"},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "rejects non-positive watch interval",
+			options: &getOptions{outputDir: "./out", watch: true},
+			args:    []string{"https://example.com"},
+			wantErr: true,
+		},
 	}
 
 	for _, test := range tests {
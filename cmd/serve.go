@@ -0,0 +1,525 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sandrolain/crawldown/src/archive"
+	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/manifest"
+	"github.com/sandrolain/crawldown/src/metrics"
+)
+
+// Job states
+const (
+	jobQueued  = "queued"
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+// Result formats
+const (
+	resultZip      = "zip"
+	resultJSON     = "json"
+	resultMarkdown = "markdown"
+)
+
+type serveOptions struct {
+	addr                 string
+	dataDir              string
+	maxJobs              int
+	metricsAddr          string
+	allowPrivateNetworks bool
+	allowNetworks        []string
+	denyNetworks         []string
+	allowHosts           []string
+}
+
+// jobRequest is the body of a crawl job submission
+type jobRequest struct {
+	URL                 string   `json:"url"`
+	Single              bool     `json:"single"`
+	MaxDepth            *int     `json:"max_depth,omitempty"`
+	Exclude             []string `json:"exclude,omitempty"`
+	Timeout             int      `json:"timeout,omitempty"`
+	Delay               *int     `json:"delay,omitempty"`
+	IgnoreRobotsTxt     bool     `json:"ignore_robots_txt"`
+	FollowExternalLinks bool     `json:"follow_external_links"`
+	UserAgent           string   `json:"user_agent,omitempty"`
+	Format              string   `json:"format,omitempty"`
+}
+
+// jobStatus is the JSON representation of a crawl job
+type jobStatus struct {
+	ID           string     `json:"id"`
+	URL          string     `json:"url"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	PagesCrawled int        `json:"pages_crawled"`
+	PagesSaved   int        `json:"pages_saved"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// resultPage is a page in the JSON result of a job
+type resultPage struct {
+	URL       string    `json:"url"`
+	File      string    `json:"file"`
+	Content   string    `json:"content"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// crawlJob is a crawl submitted to the server
+type crawlJob struct {
+	mutex   sync.Mutex
+	status  jobStatus
+	url     string
+	dir     string
+	options *getOptions
+	single  bool
+}
+
+// jobPolicy restricts the sites jobs crawl, so that the API cannot be used to reach internal services
+type jobPolicy struct {
+	denyPrivateNetworks bool
+	allowNetworks       []string
+	denyNetworks        []string
+	allowHosts          []string // Hosts, with their subdomains, jobs may crawl; empty allows all
+}
+
+// jobServer runs crawl jobs submitted over HTTP
+type jobServer struct {
+	dataDir string
+	slots   chan struct{}
+	mutex   sync.Mutex
+	jobs    map[string]*crawlJob
+	metrics *metrics.Metrics // Metrics of the jobs, nil when they are not exposed
+	policy  jobPolicy
+}
+
+func newServeCommand() *cobra.Command {
+	options := serveOptions{}
+
+	serveCmd := &cobra.Command{
+		Use:           "serve [flags]",
+		Short:         "Expose crawls as an HTTP API",
+		Long:          "Run an HTTP server that accepts crawl jobs, reports their status, and serves the results as ZIP, JSON, or Markdown.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(options)
+		},
+	}
+
+	flags := serveCmd.Flags()
+	flags.StringVar(&options.addr, "addr", "127.0.0.1:8080", "Address the HTTP server listens on")
+	flags.StringVar(&options.dataDir, "data-dir", "", "Directory where job results are stored (default: a temporary directory)")
+	flags.IntVar(&options.maxJobs, "max-jobs", 2, "Maximum number of crawl jobs running at the same time")
+	flags.StringVar(&options.metricsAddr, "metrics-addr", "", "Serve Prometheus metrics of the jobs on this address under /metrics, e.g. 127.0.0.1:9090")
+	flags.BoolVar(&options.allowPrivateNetworks, "allow-private-networks", false, "Let jobs crawl loopback, private, link-local, and cloud metadata addresses, which are denied by default")
+	flags.StringArrayVar(&options.allowNetworks, "allow-network", nil, "IP address or CIDR prefix jobs may crawl even when private or denied, e.g. 10.1.0.0/16 for an internal wiki (can be specified multiple times)")
+	flags.StringArrayVar(&options.denyNetworks, "deny-network", nil, "IP address or CIDR prefix jobs may not crawl (can be specified multiple times)")
+	flags.StringArrayVar(&options.allowHosts, "allow-host", nil, "Host, with its subdomains, jobs may crawl; when set, jobs for other hosts are rejected (can be specified multiple times)")
+
+	return serveCmd
+}
+
+func runServe(options serveOptions) error {
+	if options.maxJobs < 1 {
+		return fmt.Errorf("--max-jobs must be at least 1")
+	}
+	for _, network := range options.allowNetworks {
+		if _, err := crawler.ParseNetwork(network); err != nil {
+			return fmt.Errorf("invalid --allow-network: %w", err)
+		}
+	}
+	for _, network := range options.denyNetworks {
+		if _, err := crawler.ParseNetwork(network); err != nil {
+			return fmt.Errorf("invalid --deny-network: %w", err)
+		}
+	}
+
+	dataDir := options.dataDir
+	if dataDir == "" {
+		var err error
+		dataDir, err = os.MkdirTemp("", "crawldown-serve-")
+		if err != nil {
+			return fmt.Errorf("create data directory: %w", err)
+		}
+	} else if err := os.MkdirAll(dataDir, 0o750); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+
+	jobs := newJobServer(dataDir, options.maxJobs)
+	jobs.policy = jobPolicy{
+		denyPrivateNetworks: !options.allowPrivateNetworks,
+		allowNetworks:       options.allowNetworks,
+		denyNetworks:        options.denyNetworks,
+		allowHosts:          options.allowHosts,
+	}
+	if options.metricsAddr != "" {
+		jobs.metrics = metrics.New()
+		stop, err := startMetricsServer(options.metricsAddr, jobs.metrics)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	server := &http.Server{
+		Addr:              options.addr,
+		Handler:           jobs.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	printStdout("Listening on http://%s\n", options.addr)
+	printStdout("Job data directory: %s\n", dataDir)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+
+	return nil
+}
+
+func newJobServer(dataDir string, maxJobs int) *jobServer {
+	return &jobServer{
+		dataDir: dataDir,
+		slots:   make(chan struct{}, maxJobs),
+		jobs:    make(map[string]*crawlJob),
+	}
+}
+
+func (s *jobServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", s.handleCreateJob)
+	mux.HandleFunc("GET /jobs", s.handleListJobs)
+	mux.HandleFunc("GET /jobs/{id}", s.handleJobStatus)
+	mux.HandleFunc("GET /jobs/{id}/result", s.handleJobResult)
+	return mux
+}
+
+func (s *jobServer) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	var request jobRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	options, err := request.getOptions()
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.policy.apply(r.Context(), options, request.URL); err != nil {
+		writeJSONError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	job := &crawlJob{
+		status: jobStatus{
+			ID:        id,
+			URL:       request.URL,
+			Status:    jobQueued,
+			CreatedAt: time.Now().UTC(),
+		},
+		url:     request.URL,
+		dir:     filepath.Join(s.dataDir, id),
+		options: options,
+		single:  request.Single,
+	}
+	job.options.outputDir = job.dir
+
+	s.mutex.Lock()
+	s.jobs[id] = job
+	s.mutex.Unlock()
+
+	go s.run(job)
+
+	w.Header().Set("Location", "/jobs/"+id)
+	writeJSON(w, http.StatusAccepted, job.snapshot())
+}
+
+func (s *jobServer) handleListJobs(w http.ResponseWriter, _ *http.Request) {
+	s.mutex.Lock()
+	statuses := make([]jobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, job.snapshot())
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].CreatedAt.Before(statuses[j].CreatedAt)
+	})
+
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *jobServer) handleJobStatus(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.lookup(r.PathValue("id"))
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job.snapshot())
+}
+
+func (s *jobServer) handleJobResult(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.lookup(r.PathValue("id"))
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	status := job.snapshot()
+	if status.Status != jobDone {
+		writeJSONError(w, http.StatusConflict, fmt.Sprintf("job is %s", status.Status))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = resultZip
+	}
+
+	switch format {
+	case resultZip:
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", status.ID+".zip"))
+		if err := archive.WriteZip(w, job.dir); err != nil {
+			printStderr("Job %s: %v\n", status.ID, err)
+		}
+	case resultJSON:
+		pages, err := readResultPages(job.dir)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, pages)
+	case resultMarkdown:
+		pages, err := readResultPages(job.dir)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		contents := make([]string, 0, len(pages))
+		for _, page := range pages {
+			contents = append(contents, strings.TrimSpace(page.Content))
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(strings.Join(contents, "\n\n---\n\n") + "\n"))
+	default:
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("unsupported result format %q: use zip, json, or markdown", format))
+	}
+}
+
+// run executes a job once a slot is available
+func (s *jobServer) run(job *crawlJob) {
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	startedAt := time.Now().UTC()
+	job.update(func(status *jobStatus) {
+		status.Status = jobRunning
+		status.StartedAt = &startedAt
+	})
+
+	s.metrics.RunStarted()
+	err := os.MkdirAll(job.dir, 0o750)
+	var result crawlResult
+	if err == nil {
+		job.options.metrics = s.metrics
+		result, err = crawlOnce(job.options, job.url, job.single)
+	}
+
+	finishedAt := time.Now().UTC()
+	s.metrics.RunFinished(finishedAt.Sub(startedAt), err == nil)
+	job.update(func(status *jobStatus) {
+		status.FinishedAt = &finishedAt
+		status.PagesCrawled = result.pagesCrawled
+		status.PagesSaved = result.pagesSaved
+		if err != nil {
+			status.Status = jobFailed
+			status.Error = err.Error()
+			return
+		}
+		status.Status = jobDone
+	})
+}
+
+func (s *jobServer) lookup(id string) (*crawlJob, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+func (j *crawlJob) snapshot() jobStatus {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.status
+}
+
+func (j *crawlJob) update(fn func(status *jobStatus)) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	fn(&j.status)
+}
+
+// getOptions validates the request and converts it into crawl options
+func (r jobRequest) getOptions() (*getOptions, error) {
+	if r.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if !strings.HasPrefix(r.URL, "http://") && !strings.HasPrefix(r.URL, "https://") {
+		return nil, fmt.Errorf("url must be an http or https URL")
+	}
+
+	options := defaultGetOptions()
+	if r.MaxDepth != nil {
+		options.maxDepth = *r.MaxDepth
+	}
+	if r.Delay != nil {
+		options.requestDelay = *r.Delay
+	}
+	if r.Timeout > 0 {
+		options.requestTimeout = r.Timeout
+	}
+	if r.UserAgent != "" {
+		options.userAgent = r.UserAgent
+	}
+	if r.Format != "" {
+		options.format = r.Format
+	}
+	options.excludedPaths = r.Exclude
+	options.ignoreRobotsTxt = r.IgnoreRobotsTxt
+	options.followExternalLinks = r.FollowExternalLinks
+
+	if options.format != formatMarkdown && options.format != formatHTMLSite {
+		return nil, fmt.Errorf("unsupported format %q", options.format)
+	}
+
+	return options, nil
+}
+
+// apply checks that a job may crawl its start URL and restricts the crawl to the allowed hosts and addresses.
+// The addresses of the start host are checked up front for a clear error, and every connection of the crawl
+// is checked again by the crawler, as links, redirects, and DNS answers lead elsewhere.
+func (p jobPolicy) apply(ctx context.Context, options *getOptions, startURL string) error {
+	parsedURL, err := url.Parse(startURL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	host := parsedURL.Hostname()
+
+	if len(p.allowHosts) > 0 {
+		allowed := false
+		for _, allowedHost := range p.allowHosts {
+			allowed = allowed || crawler.MatchesDomain(host, allowedHost)
+		}
+		if !allowed {
+			return fmt.Errorf("host %s is not allowed", host)
+		}
+		options.allowDomains = p.allowHosts
+	}
+
+	options.denyPrivateNetworks = p.denyPrivateNetworks
+	options.allowNetworks = p.allowNetworks
+	options.denyNetworks = p.denyNetworks
+	filter, err := crawler.NewAddressFilter(crawler.Options{
+		DenyPrivateNetworks: p.denyPrivateNetworks,
+		AllowedNetworks:     p.allowNetworks,
+		DeniedNetworks:      p.denyNetworks,
+	})
+	if err != nil || filter == nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		// Hosts that do not resolve fail when crawled
+		return nil
+	}
+	for _, addr := range addrs {
+		if !filter.Allows(addr) {
+			return fmt.Errorf("host %s resolves to the denied address %s", host, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// readResultPages loads the pages of a finished job in URL order
+func readResultPages(dir string) ([]resultPage, error) {
+	m, err := manifest.Load(filepath.Join(dir, manifest.Filename))
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]resultPage, 0, len(m.Pages))
+	for _, entry := range m.Pages {
+		//nolint:gosec // Files are listed in the manifest written by the job itself.
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path.Clean(entry.File))))
+		if err != nil {
+			return nil, fmt.Errorf("read result page: %w", err)
+		}
+		pages = append(pages, resultPage{
+			URL:       entry.URL,
+			File:      entry.File,
+			Content:   string(content),
+			FetchedAt: entry.FetchedAt,
+		})
+	}
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].URL < pages[j].URL
+	})
+
+	return pages, nil
+}
+
+func newJobID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate job id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/metrics"
+)
+
+func TestJobServerLifecycle(t *testing.T) {
+	t.Parallel()
+
+	site := newTestSite(t)
+	api := httptest.NewServer(newJobServer(t.TempDir(), 1).routes())
+	t.Cleanup(api.Close)
+
+	body := `{"url": "` + site.URL + `", "delay": 0}`
+	resp, err := http.Post(api.URL+"/jobs", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("creating job: %v", err)
+	}
+	var created jobStatus
+	decodeResponse(t, resp, http.StatusAccepted, &created)
+
+	if created.ID == "" || created.URL != site.URL {
+		t.Fatalf("unexpected job status: %+v", created)
+	}
+
+	status := waitForJob(t, api.URL, created.ID)
+	if status.Status != jobDone {
+		t.Fatalf("expected job to be done, got %+v", status)
+	}
+	if status.PagesSaved != 2 {
+		t.Errorf("expected 2 saved pages, got %d", status.PagesSaved)
+	}
+
+	resp, err = http.Get(api.URL + "/jobs/" + created.ID + "/result?format=json")
+	if err != nil {
+		t.Fatalf("fetching json result: %v", err)
+	}
+	var pages []resultPage
+	decodeResponse(t, resp, http.StatusOK, &pages)
+	if len(pages) != 2 || !strings.Contains(pages[1].Content, "Guide content") {
+		t.Errorf("unexpected json result: %+v", pages)
+	}
+
+	resp, err = http.Get(api.URL + "/jobs/" + created.ID + "/result")
+	if err != nil {
+		t.Fatalf("fetching zip result: %v", err)
+	}
+	data, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("reading zip result: %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("opening zip result: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, file := range reader.File {
+		names[file.Name] = true
+	}
+	for _, want := range []string{"index.md", "guide.md", "manifest.json"} {
+		if !names[want] {
+			t.Errorf("zip result is missing %s", want)
+		}
+	}
+
+	resp, err = http.Get(api.URL + "/jobs/" + created.ID + "/result?format=markdown")
+	if err != nil {
+		t.Fatalf("fetching markdown result: %v", err)
+	}
+	markdown, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("reading markdown result: %v", err)
+	}
+	if !strings.Contains(string(markdown), "# Home") || !strings.Contains(string(markdown), "# Guide") {
+		t.Errorf("markdown result is missing pages: %s", markdown)
+	}
+}
+
+func TestJobServerErrors(t *testing.T) {
+	t.Parallel()
+
+	api := httptest.NewServer(newJobServer(t.TempDir(), 1).routes())
+	t.Cleanup(api.Close)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "invalid body", method: http.MethodPost, path: "/jobs", body: "{", wantStatus: http.StatusBadRequest},
+		{name: "missing url", method: http.MethodPost, path: "/jobs", body: "{}", wantStatus: http.StatusBadRequest},
+		{name: "unsupported scheme", method: http.MethodPost, path: "/jobs", body: `{"url": "ftp://example.com"}`, wantStatus: http.StatusBadRequest},
+		{name: "unsupported format", method: http.MethodPost, path: "/jobs", body: `{"url": "https://example.com", "format": "pdf"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown job", method: http.MethodGet, path: "/jobs/missing", wantStatus: http.StatusNotFound},
+		{name: "unknown job result", method: http.MethodGet, path: "/jobs/missing/result", wantStatus: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(test.method, api.URL+test.path, strings.NewReader(test.body))
+			if err != nil {
+				t.Fatalf("creating request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("sending request: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("expected status %d, got %d", test.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestJobServerPolicy(t *testing.T) {
+	t.Parallel()
+
+	site := newTestSite(t)
+	jobs := newJobServer(t.TempDir(), 1)
+	jobs.policy = jobPolicy{denyPrivateNetworks: true, allowHosts: []string{"127.0.0.1", "localhost", "example.com"}}
+	api := httptest.NewServer(jobs.routes())
+	t.Cleanup(api.Close)
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+	}{
+		{name: "loopback address", url: site.URL, wantStatus: http.StatusForbidden},
+		{name: "loopback host", url: "http://localhost/", wantStatus: http.StatusForbidden},
+		{name: "host not allowed", url: "https://169.254.169.254/latest/meta-data/", wantStatus: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		resp, err := http.Post(api.URL+"/jobs", "application/json", strings.NewReader(`{"url": "`+test.url+`", "delay": 0}`))
+		if err != nil {
+			t.Fatalf("creating job: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != test.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.wantStatus, resp.StatusCode)
+		}
+	}
+
+	// Allowed networks let jobs crawl internal sites
+	internal := newJobServer(t.TempDir(), 1)
+	internal.policy = jobPolicy{denyPrivateNetworks: true, allowNetworks: []string{"127.0.0.1"}}
+	internalAPI := httptest.NewServer(internal.routes())
+	t.Cleanup(internalAPI.Close)
+
+	resp, err := http.Post(internalAPI.URL+"/jobs", "application/json", strings.NewReader(`{"url": "`+site.URL+`", "delay": 0}`))
+	if err != nil {
+		t.Fatalf("creating job: %v", err)
+	}
+	var created jobStatus
+	decodeResponse(t, resp, http.StatusAccepted, &created)
+	if status := waitForJob(t, internalAPI.URL, created.ID); status.Status != jobDone || status.PagesSaved != 2 {
+		t.Errorf("expected the job to save 2 pages, got %+v", status)
+	}
+}
+
+func waitForJob(t *testing.T, baseURL, id string) jobStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(baseURL + "/jobs/" + id)
+		if err != nil {
+			t.Fatalf("polling job: %v", err)
+		}
+		var status jobStatus
+		decodeResponse(t, resp, http.StatusOK, &status)
+
+		if status.Status == jobDone || status.Status == jobFailed {
+			return status
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("job %s did not finish in time", id)
+	return jobStatus{}
+}
+
+func decodeResponse(t *testing.T, resp *http.Response, wantStatus int, value any) {
+	t.Helper()
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("expected status %d, got %d", wantStatus, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(value); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+}
+
+func TestJobServerMetrics(t *testing.T) {
+	t.Parallel()
+
+	site := newTestSite(t)
+	jobs := newJobServer(t.TempDir(), 1)
+	jobs.metrics = metrics.New()
+	api := httptest.NewServer(jobs.routes())
+	t.Cleanup(api.Close)
+
+	resp, err := http.Post(api.URL+"/jobs", "application/json", strings.NewReader(`{"url": "`+site.URL+`", "delay": 0}`))
+	if err != nil {
+		t.Fatalf("creating job: %v", err)
+	}
+	var created jobStatus
+	decodeResponse(t, resp, http.StatusAccepted, &created)
+
+	if status := waitForJob(t, api.URL, created.ID); status.Status != jobDone {
+		t.Fatalf("expected job to be done, got %+v", status)
+	}
+
+	var out strings.Builder
+	if _, err := jobs.metrics.WriteTo(&out); err != nil {
+		t.Fatalf("writing metrics: %v", err)
+	}
+	for _, want := range []string{
+		"crawldown_pages_fetched_total 2\n",
+		"crawldown_pages_saved_total 2\n",
+		"crawldown_queue_depth 0\n",
+		"crawldown_running_crawls 0\n",
+		`crawldown_runs_total{result="success"} 1` + "\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
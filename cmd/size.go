@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dustin/go-humanize"
+)
+
+// byteSize is a flag value holding a size in bytes, parsed from values such as 5MB or 512KiB
+type byteSize int
+
+func (s *byteSize) String() string {
+	if *s == 0 {
+		return "0"
+	}
+	return humanize.IBytes(uint64(*s))
+}
+
+func (s *byteSize) Set(value string) error {
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		return fmt.Errorf("invalid size %q: %w", value, err)
+	}
+	if size > math.MaxInt32 {
+		return fmt.Errorf("size %q is too large", value)
+	}
+
+	*s = byteSize(size)
+	return nil
+}
+
+func (s *byteSize) Type() string {
+	return "size"
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestByteSizeSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    byteSize
+		wantErr bool
+	}{
+		{value: "5MB", want: 5_000_000},
+		{value: "512KiB", want: 512 * 1024},
+		{value: "1024", want: 1024},
+		{value: "lots", wantErr: true},
+		{value: "10TB", wantErr: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			var size byteSize
+			err := size.Set(test.value)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for %q", test.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if size != test.want {
+				t.Errorf("expected %d, got %d", test.want, size)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/crawler"
+	"github.com/sandrolain/crawldown/src/export"
+	"github.com/sandrolain/crawldown/src/manifest"
+	"github.com/sandrolain/crawldown/src/pagebuffer"
+)
+
+// savePageFunc saves a page, skipping the write when it matches previous, see savePage in crawlOnce
+type savePageFunc func(data pageRecord, previous manifest.Entry, known bool) (savedPage, bool)
+
+// pageStream saves pages as soon as they are converted, with --stream.
+// Links to pages that have no file yet when a page is saved stay absolute. Once the crawl is over,
+// only the pages with such links to pages crawled afterwards are saved again, so that output appears
+// as the crawl goes and the converted pages do not all wait in memory.
+type pageStream struct {
+	save          savePageFunc
+	keepDocuments bool // When false, the export documents of saved pages are dropped, as no exporter needs them
+
+	mutex   sync.Mutex
+	owners  map[string]string             // Normalized URL of the page saved in each file, by folded file name
+	saved   map[string]savedPage          // Saved pages by normalized URL
+	waiting map[string][]string           // Normalized URLs of the pages linking to each URL that had no file yet
+	pending pagebuffer.Buffer[pageRecord] // Pages with links waiting for a file, saved again by patch
+	hooks   map[string]bool               // Whether the file of each page in pending was written, so that its --exec-per-page command runs after patch
+}
+
+// newPageStream creates a stream keeping the pages with unresolved links in pending
+func newPageStream(save savePageFunc, pending pagebuffer.Buffer[pageRecord], keepDocuments bool) *pageStream {
+	return &pageStream{
+		save:          save,
+		keepDocuments: keepDocuments,
+		owners:        make(map[string]string),
+		saved:         make(map[string]savedPage),
+		waiting:       make(map[string][]string),
+		pending:       pending,
+		hooks:         make(map[string]bool),
+	}
+}
+
+// claim returns the file a page is saved in: filename, unless another page was saved in it first, in which case
+// the name is disambiguated with a hash of the URL and filename is returned as the original name.
+// Names differing only in case are taken as the same file, as they are on case-insensitive file systems.
+// Unlike the collisions resolved after a crawl, the first page crawled keeps the name.
+func (s *pageStream) claim(filename, key, pageURL string) (string, string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if owner, taken := s.owners[converter.FoldFilename(filename)]; taken && owner != key {
+		disambiguated := converter.DisambiguateFilename(filename, pageURL)
+		s.owners[converter.FoldFilename(disambiguated)] = key
+		return disambiguated, filename
+	}
+	s.owners[converter.FoldFilename(filename)] = key
+	return filename, ""
+}
+
+// add saves a converted page, which is not written when it matches previous, its entry of the last run.
+// unresolved are the normalized URLs of its links that had no file when it was converted.
+func (s *pageStream) add(key string, data pageRecord, previous manifest.Entry, known bool, unresolved []string) (savedPage, bool) {
+	saved, ok := s.save(data, previous, known)
+	if !ok {
+		return saved, false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.saved[key] = s.keep(saved)
+	if len(unresolved) == 0 {
+		return saved, true
+	}
+	if err := s.pending.Put(key, data); err != nil {
+		printStderr("  Error buffering page: %v\n", err)
+		return saved, true
+	}
+	for _, link := range unresolved {
+		s.waiting[link] = append(s.waiting[link], key)
+	}
+	s.hooks[key] = saved.wrote()
+	return saved, true
+}
+
+// deferred reports whether the page is kept for patch, so that its --exec-per-page command waits for hookPages
+func (s *pageStream) deferred(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.hooks[key]
+	return ok
+}
+
+// patch saves again the pages linking to URLs that have a file by now, returning the number of files rewritten
+func (s *pageStream) patch(hasFile func(key string) bool, logf func(format string, args ...any)) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stale := make(map[string]bool)
+	for link, keys := range s.waiting {
+		if !hasFile(link) {
+			continue
+		}
+		for _, key := range keys {
+			stale[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(stale))
+	for key := range stale {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	patched := 0
+	for _, key := range keys {
+		data, ok, err := s.pending.Get(key)
+		if err != nil {
+			printStderr("  Error reading buffered page: %v\n", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		// The file was last written with the entry of the first save, so it is only written again when links changed
+		previous := s.saved[key]
+		saved, ok := s.save(data, previous.entry, !previous.stored)
+		if !ok {
+			continue
+		}
+		if saved.wrote() {
+			logf("  Patched links: %s\n", saved.location)
+			patched++
+			s.hooks[key] = true
+		}
+		s.saved[key] = s.keep(saved)
+	}
+	return patched
+}
+
+// hookPages returns the deferred pages whose file was written when they were added or patched, ordered by URL.
+// Their --exec-per-page command runs once, after patch, with the links of their final version.
+func (s *pageStream) hookPages() []savedPage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	keys := make([]string, 0, len(s.hooks))
+	for key, written := range s.hooks {
+		if written {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	pages := make([]savedPage, 0, len(keys))
+	for _, key := range keys {
+		pages = append(pages, s.saved[key])
+	}
+	return pages
+}
+
+// keep drops the export document of a saved page when it is not needed
+func (s *pageStream) keep(saved savedPage) savedPage {
+	if !s.keepDocuments {
+		saved.document = export.Document{}
+	}
+	return saved
+}
+
+// addRedirects records the redirect chains leading to saved pages in their manifest entries
+func (s *pageStream) addRedirects(redirects map[string][]crawler.Redirect) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for target, chain := range redirects {
+		key := strings.TrimSuffix(target, "/")
+		saved, ok := s.saved[key]
+		if !ok {
+			continue
+		}
+		saved.entry.Redirects = nil
+		for _, hop := range chain {
+			saved.entry.Redirects = append(saved.entry.Redirects, manifest.Redirect{URL: hop.URL, Status: hop.Status})
+		}
+		s.saved[key] = saved
+	}
+}
+
+// pages returns the saved pages ordered by URL, like the pages saved after a crawl
+func (s *pageStream) pages() []savedPage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	keys := make([]string, 0, len(s.saved))
+	for key := range s.saved {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pages := make([]savedPage, 0, len(keys))
+	for _, key := range keys {
+		pages = append(pages, s.saved[key])
+	}
+	return pages
+}
+
+// unresolvedLinks returns the normalized URLs of links that have no file in urlToFile but may still get one,
+// as follows reports that the crawler may visit them. External, excluded, and out of scope links never get a file,
+// so they do not keep the page waiting for patch.
+func unresolvedLinks(links []string, urlToFile map[string]string, follows func(link string) bool) []string {
+	var unresolved []string
+	for _, link := range links {
+		key := strings.TrimSuffix(link, "/")
+		if _, exists := urlToFile[key]; !exists && follows(link) {
+			unresolved = append(unresolved, key)
+		}
+	}
+	return unresolved
+}
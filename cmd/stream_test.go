@@ -0,0 +1,26 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnresolvedLinks(t *testing.T) {
+	t.Parallel()
+
+	urlToFile := map[string]string{"https://example.com/saved": "saved.md"}
+	links := []string{
+		"https://example.com/saved/",
+		"https://example.com/later",
+		"https://external.example.org/page",
+	}
+	follows := func(link string) bool {
+		return strings.HasPrefix(link, "https://example.com/")
+	}
+
+	want := []string{"https://example.com/later"}
+	if got := unresolvedLinks(links, urlToFile, follows); !reflect.DeepEqual(got, want) {
+		t.Errorf("unresolvedLinks() = %v, want %v", got, want)
+	}
+}
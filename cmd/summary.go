@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// Reasons for pages skipped by the CLI after they were crawled
+const (
+	skipMarkdownSize = "markdown-size"
+	skipLanguage     = "language"
+)
+
+// runSummary is the content of the --summary file
+type runSummary struct {
+	URL        string           `json:"url"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Durations  summaryDurations `json:"durations"`
+	Pages      summaryPages     `json:"pages"`
+	Skipped    map[string]int   `json:"skipped"` // Skipped URLs and pages by reason
+	Errors     int              `json:"errors"`  // Failed requests, as listed in errors.json
+	Bytes      summaryBytes     `json:"bytes"`
+	Output     summaryOutput    `json:"output"`
+}
+
+// summaryDurations are the durations of the run phases in seconds
+type summaryDurations struct {
+	Crawl float64 `json:"crawl_seconds"`
+	Save  float64 `json:"save_seconds"`
+	Total float64 `json:"total_seconds"`
+}
+
+// summaryPages counts the pages of the run
+type summaryPages struct {
+	Crawled   int `json:"crawled"`   // Pages fetched and kept by the crawler
+	Converted int `json:"converted"` // Pages converted to Markdown and queued for saving
+	Saved     int `json:"saved"`     // Pages written or stored
+	Unchanged int `json:"unchanged"` // Saved pages whose file was already up to date
+	Skipped   int `json:"skipped"`   // URLs and pages skipped for any reason
+	Failed    int `json:"failed"`    // Pages that failed to convert, render, or save
+}
+
+// summaryBytes counts the bytes transferred by the run
+type summaryBytes struct {
+	Downloaded int64 `json:"downloaded"` // Response bodies read
+	Written    int64 `json:"written"`    // Page files written, excluding unchanged pages
+}
+
+// summaryOutput lists the output of the run
+type summaryOutput struct {
+	Dir     string   `json:"dir,omitempty"`
+	Files   []string `json:"files"`
+	Exports []string `json:"exports,omitempty"`
+}
+
+// runStats collects the summary counters updated concurrently during the crawl
+type runStats struct {
+	mu         sync.Mutex
+	startedAt  time.Time
+	crawledAt  time.Time
+	skipped    map[string]int
+	downloaded int64
+	converted  int
+	unchanged  int
+	written    int64
+	files      []string
+	exports    []string
+}
+
+func newRunStats() *runStats {
+	return &runStats{startedAt: time.Now().UTC(), skipped: make(map[string]int)}
+}
+
+func (s *runStats) skip(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skipped[reason]++
+}
+
+func (s *runStats) download(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.downloaded += int64(n)
+}
+
+func (s *runStats) convert() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.converted++
+}
+
+// crawlDone marks the end of the crawl phase
+func (s *runStats) crawlDone() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.crawledAt = time.Now().UTC()
+}
+
+// save records a saved page file and the bytes written for it, 0 when it was unchanged
+func (s *runStats) save(location string, written int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files = append(s.files, location)
+	if written == 0 {
+		s.unchanged++
+	}
+	s.written += int64(written)
+}
+
+func (s *runStats) export(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exports = append(s.exports, name)
+}
+
+// summary builds the run summary from the collected counters and the result of the run
+func (s *runStats) summary(startURL, outputDir string, result crawlResult) runSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finishedAt := time.Now().UTC()
+	crawledAt := s.crawledAt
+	if crawledAt.IsZero() {
+		crawledAt = finishedAt
+	}
+
+	skipped := make(map[string]int, len(s.skipped))
+	total := 0
+	for reason, count := range s.skipped {
+		skipped[reason] = count
+		total += count
+	}
+
+	files := append([]string{}, s.files...)
+	sort.Strings(files)
+
+	return runSummary{
+		URL:        startURL,
+		StartedAt:  s.startedAt,
+		FinishedAt: finishedAt,
+		Durations: summaryDurations{
+			Crawl: crawledAt.Sub(s.startedAt).Seconds(),
+			Save:  finishedAt.Sub(crawledAt).Seconds(),
+			Total: finishedAt.Sub(s.startedAt).Seconds(),
+		},
+		Pages: summaryPages{
+			Crawled:   result.pagesCrawled,
+			Converted: s.converted,
+			Saved:     result.pagesSaved,
+			Unchanged: s.unchanged,
+			Skipped:   total,
+			Failed:    len(result.failures),
+		},
+		Skipped: skipped,
+		Errors:  result.requestErrors,
+		Bytes: summaryBytes{
+			Downloaded: s.downloaded,
+			Written:    s.written,
+		},
+		Output: summaryOutput{
+			Dir:     outputDir,
+			Files:   files,
+			Exports: append([]string{}, s.exports...),
+		},
+	}
+}
+
+// saveSummary writes the run summary as JSON to path
+func saveSummary(path string, summary runSummary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode summary: %w", err)
+	}
+
+	if err := output.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("write summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/manifest"
+)
+
+// runWatch re-crawls the site periodically until the process is interrupted
+func runWatch(options *getOptions, startURL string, isSingle bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	run := 0
+	for {
+		run++
+		startedAt := time.Now().UTC()
+		printStdout("Watch run %d started at %s\n", run, startedAt.Format(time.RFC3339))
+
+		options.metrics.RunStarted()
+		result, err := crawlOnce(options, startURL, isSingle)
+		options.metrics.RunFinished(time.Since(startedAt), err == nil)
+		if err != nil {
+			printStderr("Watch run %d failed: %v\n", run, err)
+		} else {
+			changes := result.changes
+			printStdout("Watch run %d: %d added, %d removed, %d modified\n",
+				run, len(changes.Added), len(changes.Removed), len(changes.Modified))
+
+			if options.changelogPath != "" && !changes.Empty() {
+				if err := appendChangelog(options.changelogPath, startedAt, changes); err != nil {
+					printStderr("Error writing changelog: %v\n", err)
+				}
+			}
+
+			if err := finishRun(options, startURL, result); err != nil {
+				printStderr("Watch run %d: %v\n", run, err)
+			}
+		}
+
+		printStdout("Next run at %s\n\n", time.Now().Add(options.watchInterval).Format(time.RFC3339))
+
+		select {
+		case <-ctx.Done():
+			printStdout("Watch mode stopped\n")
+			return nil
+		case <-time.After(options.watchInterval):
+		}
+	}
+}
+
+// appendChangelog appends a Markdown section describing the changes of a run
+func appendChangelog(path string, runAt time.Time, changes manifest.Changes) error {
+	//nolint:gosec // The changelog path is provided by the user.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open changelog: %w", err)
+	}
+
+	if _, err := file.WriteString(formatChangelog(runAt, changes)); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write changelog: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close changelog: %w", err)
+	}
+
+	return nil
+}
+
+// formatChangelog renders the changes of a run as a Markdown section
+func formatChangelog(runAt time.Time, changes manifest.Changes) string {
+	var builder strings.Builder
+
+	builder.WriteString("## " + runAt.Format(time.RFC3339) + "\n\n")
+
+	sections := []struct {
+		title   string
+		entries []manifest.Entry
+	}{
+		{title: "Added", entries: changes.Added},
+		{title: "Removed", entries: changes.Removed},
+		{title: "Modified", entries: changes.Modified},
+	}
+
+	for _, section := range sections {
+		if len(section.entries) == 0 {
+			continue
+		}
+
+		builder.WriteString("### " + section.title + "\n\n")
+		for _, entry := range section.entries {
+			builder.WriteString(fmt.Sprintf("- %s (%s)\n", entry.URL, entry.File))
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
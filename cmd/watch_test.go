@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/manifest"
+)
+
+func TestFormatChangelog(t *testing.T) {
+	t.Parallel()
+
+	runAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	changes := manifest.Changes{
+		Added:    []manifest.Entry{{URL: "https://example.com/new", File: "new.md"}},
+		Modified: []manifest.Entry{{URL: "https://example.com/", File: "index.md"}},
+	}
+
+	got := formatChangelog(runAt, changes)
+
+	for _, want := range []string{
+		"## 2025-01-02T03:04:05Z",
+		"### Added\n\n- https://example.com/new (new.md)",
+		"### Modified\n\n- https://example.com/ (index.md)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatChangelog() missing %q\nGot: %s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "### Removed") {
+		t.Errorf("formatChangelog() should omit empty sections\nGot: %s", got)
+	}
+}
+
+func TestCrawlOnceDetectsChanges(t *testing.T) {
+	t.Parallel()
+
+	var body atomic.Value
+	body.Store("First version")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Page</title></head><body><main><p>` + body.Load().(string) + `</p></main></body></html>`))
+	}))
+	defer srv.Close()
+
+	options := defaultGetOptions()
+	options.outputDir = t.TempDir()
+	options.requestDelay = 0
+
+	result, err := crawlOnce(options, srv.URL, true)
+	if err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+	if len(result.changes.Added) != 1 {
+		t.Fatalf("expected 1 added page on first run, got %+v", result.changes)
+	}
+
+	result, err = crawlOnce(options, srv.URL, true)
+	if err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+	if !result.changes.Empty() {
+		t.Fatalf("expected no changes on unchanged site, got %+v", result.changes)
+	}
+
+	body.Store("Second version")
+	result, err = crawlOnce(options, srv.URL, true)
+	if err != nil {
+		t.Fatalf("crawlOnce returned error: %v", err)
+	}
+	if len(result.changes.Modified) != 1 {
+		t.Fatalf("expected 1 modified page, got %+v", result.changes)
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"github.com/sandrolain/crawldown/src/webhook"
+)
+
+// newNotifier starts the notifier of --webhook, or returns nil when it is not set
+func newNotifier(options *getOptions) *webhook.Notifier {
+	if options.webhookURL == "" {
+		return nil
+	}
+	return webhook.New(options.webhookURL, options.webhookEvents, printStderr)
+}
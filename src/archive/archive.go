@@ -0,0 +1,171 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Archive formats
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+// FormatForPath returns the archive format implied by the file extension
+func FormatForPath(path string) (string, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGz, nil
+	default:
+		return "", fmt.Errorf("unsupported archive extension for %q: use .zip, .tar.gz, or .tgz", path)
+	}
+}
+
+// Create packages dir into an archive file whose format is chosen by its extension
+func Create(path, dir string) error {
+	format, err := FormatForPath(path)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // The archive path is provided by the user.
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	if format == FormatZip {
+		err = WriteZip(file, dir)
+	} else {
+		err = WriteTarGz(file, dir)
+	}
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+
+	return nil
+}
+
+// WriteZip writes the regular files below dir into a ZIP archive, using slash-separated relative names
+func WriteZip(w io.Writer, dir string) error {
+	zipWriter := zip.NewWriter(w)
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		header.Method = zip.Deflate
+
+		fileWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		return copyFile(fileWriter, path)
+	})
+	if err != nil {
+		_ = zipWriter.Close()
+		return fmt.Errorf("failed to write zip archive: %w", err)
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write zip archive: %w", err)
+	}
+
+	return nil
+}
+
+// WriteTarGz writes the regular files below dir into a gzip-compressed tar archive, using slash-separated relative names
+func WriteTarGz(w io.Writer, dir string) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		return copyFile(tarWriter, path)
+	})
+	if err == nil {
+		err = tarWriter.Close()
+	}
+	if err == nil {
+		err = gzipWriter.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write tar.gz archive: %w", err)
+	}
+
+	return nil
+}
+
+// copyFile copies the content of the file at path into w
+func copyFile(w io.Writer, path string) error {
+	//nolint:gosec // Paths come from walking the archived directory.
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -0,0 +1,146 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func writeTestTree(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.md":         "# Home",
+		"assets/image.png": "png",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	return dir
+}
+
+func TestWriteZip(t *testing.T) {
+	dir := writeTestTree(t)
+
+	var buf bytes.Buffer
+	if err := WriteZip(&buf, dir); err != nil {
+		t.Fatalf("WriteZip() error = %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to open zip: %v", err)
+	}
+
+	contents := make(map[string]string)
+	var names []string
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", file.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file.Name, err)
+		}
+		names = append(names, file.Name)
+		contents[file.Name] = string(data)
+	}
+	sort.Strings(names)
+
+	if len(names) != 2 || names[0] != "assets/image.png" || names[1] != "index.md" {
+		t.Errorf("archive entries = %v, want [assets/image.png index.md]", names)
+	}
+	if contents["index.md"] != "# Home" {
+		t.Errorf("index.md content = %q, want %q", contents["index.md"], "# Home")
+	}
+}
+
+func TestWriteZipMissingDir(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteZip(&buf, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("WriteZip() expected error for missing directory")
+	}
+}
+
+func TestWriteTarGz(t *testing.T) {
+	dir := writeTestTree(t)
+
+	var buf bytes.Buffer
+	if err := WriteTarGz(&buf, dir); err != nil {
+		t.Fatalf("WriteTarGz() error = %v", err)
+	}
+
+	gzipReader, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("failed to open gzip stream: %v", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	contents := make(map[string]string)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read tar entry: %v", err)
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", header.Name, err)
+		}
+		contents[header.Name] = string(data)
+	}
+
+	if len(contents) != 2 || contents["index.md"] != "# Home" || contents["assets/image.png"] != "png" {
+		t.Errorf("archive contents = %v, want index.md and assets/image.png", contents)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := writeTestTree(t)
+
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{name: "zip", file: "out.zip"},
+		{name: "tar.gz", file: "out.tar.gz"},
+		{name: "tgz", file: "out.TGZ"},
+		{name: "unsupported", file: "out.rar", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			err := Create(path, dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			info, err := os.Stat(path)
+			if err != nil || info.Size() == 0 {
+				t.Errorf("Create() did not write %s: %v", tt.file, err)
+			}
+		})
+	}
+}
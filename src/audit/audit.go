@@ -0,0 +1,312 @@
+package audit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/temoto/robotstxt"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+)
+
+// maxBodySize limits how much of robots.txt and the start page is read
+const maxBodySize = 5 << 20
+
+// Link decisions
+const (
+	DecisionAllowed       = "allowed"
+	DecisionRobotsBlocked = "blocked by robots.txt"
+	DecisionExcluded      = "excluded by --exclude"
+	DecisionExternal      = "external link, not followed"
+)
+
+// Options mirrors the crawl options that affect what a crawl may fetch
+type Options struct {
+	UserAgent           string
+	IgnoreRobotsTxt     bool
+	FollowExternalLinks bool
+	RequestDelay        time.Duration
+	ExcludedPaths       []string
+	Client              *http.Client
+}
+
+// LinkCheck is the decision taken for a link found on the start page
+type LinkCheck struct {
+	URL      string
+	Decision string
+}
+
+// Report describes what a crawl is allowed to do on a site
+type Report struct {
+	StartURL      string
+	RobotsURL     string
+	RobotsStatus  int
+	RobotsAgent   string
+	RobotsRules   []string
+	CrawlDelay    time.Duration
+	Sitemaps      []string
+	StartStatus   int
+	StartDecision string
+	MetaRobots    []string
+	XRobotsTag    []string
+	Links         []LinkCheck
+	Warnings      []string
+}
+
+// Run fetches robots.txt and the start page and evaluates them against the options
+func Run(startURL string, opts Options) (*Report, error) {
+	parsedURL, err := url.Parse(startURL)
+	if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return nil, fmt.Errorf("invalid URL: %s", startURL)
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	report := &Report{
+		StartURL:  startURL,
+		RobotsURL: parsedURL.Scheme + "://" + parsedURL.Host + "/robots.txt",
+	}
+
+	robots, err := fetchRobots(client, opts.UserAgent, report)
+	if err != nil {
+		return nil, err
+	}
+
+	report.StartDecision = decide(parsedURL, parsedURL, robots, opts)
+
+	if err := fetchStartPage(client, parsedURL, robots, opts, report); err != nil {
+		return nil, err
+	}
+
+	addWarnings(report, opts)
+
+	return report, nil
+}
+
+// Allowed reports whether the start page may be crawled
+func (r *Report) Allowed() bool {
+	return r.StartDecision == DecisionAllowed
+}
+
+func fetchRobots(client *http.Client, userAgent string, report *Report) (*robotstxt.RobotsData, error) {
+	body, status, _, err := fetch(client, report.RobotsURL, userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch robots.txt: %w", err)
+	}
+	report.RobotsStatus = status
+
+	robots, err := robotstxt.FromStatusAndBytes(status, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse robots.txt: %w", err)
+	}
+
+	report.Sitemaps = robots.Sitemaps
+	group := robots.FindGroup(userAgent)
+	report.CrawlDelay = group.CrawlDelay
+	if status >= 200 && status < 300 {
+		report.RobotsAgent, report.RobotsRules = groupRules(string(body), userAgent)
+	}
+
+	return robots, nil
+}
+
+func fetchStartPage(client *http.Client, startURL *url.URL, robots *robotstxt.RobotsData, opts Options, report *Report) error {
+	body, status, header, err := fetch(client, startURL.String(), opts.UserAgent)
+	if err != nil {
+		return fmt.Errorf("failed to fetch start page: %w", err)
+	}
+	report.StartStatus = status
+	report.XRobotsTag = header.Values("X-Robots-Tag")
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	if err != nil {
+		return fmt.Errorf("failed to parse start page: %w", err)
+	}
+
+	doc.Find("meta[name]").Each(func(_ int, meta *goquery.Selection) {
+		name := strings.ToLower(meta.AttrOr("name", ""))
+		if name == "robots" || name == strings.ToLower(opts.UserAgent) {
+			if content := strings.TrimSpace(meta.AttrOr("content", "")); content != "" {
+				report.MetaRobots = append(report.MetaRobots, content)
+			}
+		}
+	})
+
+	seen := make(map[string]bool)
+	doc.Find("a[href]").Each(func(_ int, link *goquery.Selection) {
+		linkURL, err := startURL.Parse(link.AttrOr("href", ""))
+		if err != nil || (linkURL.Scheme != "http" && linkURL.Scheme != "https") {
+			return
+		}
+		linkURL.Fragment = ""
+		if seen[linkURL.String()] {
+			return
+		}
+		seen[linkURL.String()] = true
+
+		report.Links = append(report.Links, LinkCheck{
+			URL:      linkURL.String(),
+			Decision: decide(startURL, linkURL, robots, opts),
+		})
+	})
+
+	sort.Slice(report.Links, func(i, j int) bool {
+		return report.Links[i].URL < report.Links[j].URL
+	})
+
+	return nil
+}
+
+// decide applies the crawl rules to a URL
+func decide(startURL, target *url.URL, robots *robotstxt.RobotsData, opts Options) string {
+	if target.Host != startURL.Host && !opts.FollowExternalLinks {
+		return DecisionExternal
+	}
+
+	if crawler.MatchesExcludedPath(target.String(), opts.ExcludedPaths) {
+		return DecisionExcluded
+	}
+
+	if !opts.IgnoreRobotsTxt && target.Host == startURL.Host {
+		path := target.EscapedPath()
+		if path == "" {
+			path = "/"
+		}
+		if target.RawQuery != "" {
+			path += "?" + target.RawQuery
+		}
+		if !robots.TestAgent(path, opts.UserAgent) {
+			return DecisionRobotsBlocked
+		}
+	}
+
+	return DecisionAllowed
+}
+
+func addWarnings(report *Report, opts Options) {
+	if !opts.IgnoreRobotsTxt && report.CrawlDelay > opts.RequestDelay {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"robots.txt asks for a crawl delay of %s but the request delay is %s; use --delay %d",
+			report.CrawlDelay, opts.RequestDelay, int(report.CrawlDelay.Seconds()+0.999)))
+	}
+
+	if opts.IgnoreRobotsTxt {
+		report.Warnings = append(report.Warnings, "robots.txt is ignored; make sure you have permission to crawl this site")
+	}
+
+	for _, directive := range append(append([]string{}, report.MetaRobots...), report.XRobotsTag...) {
+		if restrictive(directive) {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("the start page declares robots directives %q", directive))
+		}
+	}
+
+	if report.StartStatus >= 400 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("the start page returned HTTP %d", report.StartStatus))
+	}
+}
+
+// restrictive reports whether a robots directive list contains noindex, nofollow, or none
+func restrictive(directive string) bool {
+	for _, token := range strings.FieldsFunc(strings.ToLower(directive), func(r rune) bool { return r == ',' || r == ' ' }) {
+		switch token {
+		case "noindex", "nofollow", "none":
+			return true
+		}
+	}
+	return false
+}
+
+// groupRules returns the robots.txt group that applies to the user agent and its Allow and Disallow lines.
+// The group is selected like robots parsers do: the longest agent name that prefixes the user agent, falling back to "*".
+func groupRules(body, userAgent string) (string, []string) {
+	groups := make(map[string][]string)
+	var current []string
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !lastWasAgent {
+				current = nil
+			}
+			agent := strings.ToLower(value)
+			current = append(current, agent)
+			if _, exists := groups[agent]; !exists {
+				groups[agent] = []string{}
+			}
+			lastWasAgent = true
+		case "allow", "disallow":
+			lastWasAgent = false
+			if value == "" {
+				continue
+			}
+			for _, agent := range current {
+				groups[agent] = append(groups[agent], strings.ToUpper(key[:1])+key[1:]+": "+value)
+			}
+		default:
+			lastWasAgent = false
+		}
+	}
+
+	userAgent = strings.ToLower(userAgent)
+	selected := ""
+	if _, exists := groups["*"]; exists {
+		selected = "*"
+	}
+	for agent := range groups {
+		if agent != "*" && strings.HasPrefix(userAgent, agent) && (selected == "*" || selected == "" || len(agent) > len(selected)) {
+			selected = agent
+		}
+	}
+
+	if selected == "" {
+		return "", nil
+	}
+	return selected, groups[selected]
+}
+
+func fetch(client *http.Client, target, userAgent string) ([]byte, int, http.Header, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	return body, resp.StatusCode, resp.Header, nil
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSite(t *testing.T, robots string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		if robots == "" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(robots))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Robots-Tag", "noarchive")
+		_, _ = w.Write([]byte(`<html><head><meta name="robots" content="index, nofollow"></head><body>
+<a href="/docs/intro">Intro</a>
+<a href="/private/data">Private</a>
+<a href="/admin/panel">Admin</a>
+<a href="https://other.example/page">Other</a>
+<a href="mailto:me@example.com">Mail</a>
+</body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRun(t *testing.T) {
+	robots := "User-agent: *\nDisallow: /private\nCrawl-delay: 5\n\nUser-agent: OtherBot\nDisallow: /\n\nSitemap: https://example.com/sitemap.xml\n"
+	srv := newTestSite(t, robots)
+
+	report, err := Run(srv.URL+"/", Options{
+		UserAgent:     "CrawlDown/1.0",
+		RequestDelay:  time.Second,
+		ExcludedPaths: []string{srv.URL + "/admin"},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if !report.Allowed() {
+		t.Errorf("expected start page to be allowed, got %q", report.StartDecision)
+	}
+	if report.CrawlDelay != 5*time.Second {
+		t.Errorf("CrawlDelay = %v, want 5s", report.CrawlDelay)
+	}
+	if !reflect.DeepEqual(report.Sitemaps, []string{"https://example.com/sitemap.xml"}) {
+		t.Errorf("Sitemaps = %v", report.Sitemaps)
+	}
+	if !reflect.DeepEqual(report.RobotsRules, []string{"Disallow: /private"}) {
+		t.Errorf("RobotsRules = %v, want [Disallow: /private]", report.RobotsRules)
+	}
+	if !reflect.DeepEqual(report.MetaRobots, []string{"index, nofollow"}) {
+		t.Errorf("MetaRobots = %v", report.MetaRobots)
+	}
+	if !reflect.DeepEqual(report.XRobotsTag, []string{"noarchive"}) {
+		t.Errorf("XRobotsTag = %v", report.XRobotsTag)
+	}
+
+	decisions := make(map[string]string)
+	for _, link := range report.Links {
+		decisions[strings.TrimPrefix(link.URL, srv.URL)] = link.Decision
+	}
+	want := map[string]string{
+		"/docs/intro":                DecisionAllowed,
+		"/private/data":              DecisionRobotsBlocked,
+		"/admin/panel":               DecisionExcluded,
+		"https://other.example/page": DecisionExternal,
+	}
+	if !reflect.DeepEqual(decisions, want) {
+		t.Errorf("link decisions = %v, want %v", decisions, want)
+	}
+
+	if len(report.Warnings) != 2 {
+		t.Errorf("expected crawl delay and meta robots warnings, got %v", report.Warnings)
+	}
+}
+
+func TestRunWithoutRobots(t *testing.T) {
+	srv := newTestSite(t, "")
+
+	report, err := Run(srv.URL, Options{UserAgent: "CrawlDown/1.0", IgnoreRobotsTxt: true})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.RobotsStatus != http.StatusNotFound || len(report.RobotsRules) != 0 {
+		t.Errorf("unexpected robots result: status %d, rules %v", report.RobotsStatus, report.RobotsRules)
+	}
+	for _, link := range report.Links {
+		if link.Decision == DecisionRobotsBlocked {
+			t.Errorf("link %s blocked although robots.txt is ignored", link.URL)
+		}
+	}
+}
+
+func TestRunInvalidURL(t *testing.T) {
+	if _, err := Run("ftp://example.com", Options{}); err == nil {
+		t.Errorf("Run() expected error for unsupported scheme")
+	}
+}
+
+func TestGroupRules(t *testing.T) {
+	body := "User-agent: a\nUser-agent: *\nDisallow: /x # comment\nAllow: /x/y\n\nUser-agent: bot\nDisallow: /z\n"
+
+	tests := []struct {
+		userAgent string
+		wantAgent string
+		wantRules []string
+	}{
+		{userAgent: "CrawlDown/1.0", wantAgent: "*", wantRules: []string{"Disallow: /x", "Allow: /x/y"}},
+		{userAgent: "Bot/2.0", wantAgent: "bot", wantRules: []string{"Disallow: /z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userAgent, func(t *testing.T) {
+			agent, rules := groupRules(body, tt.userAgent)
+			if agent != tt.wantAgent || !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Errorf("groupRules() = %q %v, want %q %v", agent, rules, tt.wantAgent, tt.wantRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Package boilerplate detects the text blocks repeated verbatim across the pages of a crawl,
+// such as headers, footers, and cookie notices left in the extracted content, and strips them
+package boilerplate
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MinPages is the number of pages a block must appear on to be boilerplate, whatever the share,
+	// so that two pages sharing a paragraph are not enough
+	MinPages = 3
+	// MinBlockLength is the number of characters below which blocks are never boilerplate,
+	// so that short lines such as rules, labels, or closing code fences are kept
+	MinBlockLength = 20
+)
+
+// Block is a text block repeated across pages
+type Block struct {
+	Text  string `json:"text"`
+	Pages int    `json:"pages"` // Number of pages the block appears on
+}
+
+// Detector counts the pages each block of Markdown appears on
+type Detector struct {
+	counts map[string]int
+	pages  int
+}
+
+// NewDetector creates an empty detector
+func NewDetector() *Detector {
+	return &Detector{counts: make(map[string]int)}
+}
+
+// Add counts the blocks of the Markdown of a page, each once however often it appears on the page
+func (d *Detector) Add(markdown string) {
+	d.pages++
+
+	seen := make(map[string]bool)
+	for _, block := range Blocks(markdown) {
+		if candidate(block) && !seen[block] {
+			seen[block] = true
+			d.counts[block]++
+		}
+	}
+}
+
+// Pages returns the number of pages added
+func (d *Detector) Pages() int {
+	return d.pages
+}
+
+// Repeated returns the blocks found on at least minShare of the pages and on MinPages pages or more,
+// the most frequent first
+func (d *Detector) Repeated(minShare float64) []Block {
+	var blocks []Block
+	for text, pages := range d.counts {
+		if pages >= MinPages && float64(pages) >= minShare*float64(d.pages) {
+			blocks = append(blocks, Block{Text: text, Pages: pages})
+		}
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Pages != blocks[j].Pages {
+			return blocks[i].Pages > blocks[j].Pages
+		}
+		return blocks[i].Text < blocks[j].Text
+	})
+	return blocks
+}
+
+// Strip removes the given blocks from Markdown, returning the result and the number of blocks removed
+func Strip(markdown string, boilerplate map[string]bool) (string, int) {
+	blocks := Blocks(markdown)
+
+	kept := blocks[:0]
+	for _, block := range blocks {
+		if !boilerplate[block] {
+			kept = append(kept, block)
+		}
+	}
+
+	removed := len(blocks) - len(kept)
+	if removed == 0 {
+		return markdown, 0
+	}
+	return strings.Join(kept, "\n\n"), removed
+}
+
+// Blocks splits Markdown into the blocks separated by blank lines, without surrounding whitespace.
+// Fenced code blocks are kept whole, including their blank lines.
+func Blocks(markdown string) []string {
+	var blocks []string
+	var current []string
+	fence := ""
+
+	flush := func() {
+		if block := strings.TrimSpace(strings.Join(current, "\n")); block != "" {
+			blocks = append(blocks, block)
+		}
+		current = current[:0]
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(trimmed, "```"):
+			fence = "```"
+		case strings.HasPrefix(trimmed, "~~~"):
+			fence = "~~~"
+		case trimmed == "":
+			flush()
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return blocks
+}
+
+// candidate reports whether a block may be boilerplate: headings and short blocks are structure, not boilerplate
+func candidate(block string) bool {
+	return !strings.HasPrefix(block, "#") && utf8.RuneCountInString(block) >= MinBlockLength
+}
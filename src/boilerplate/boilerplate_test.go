@@ -0,0 +1,65 @@
+package boilerplate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlocks(t *testing.T) {
+	markdown := "# Title\n\nFirst paragraph\nwrapped.\n\n\n```go\nfunc main() {\n\n}\n```\n\n- item\n"
+
+	want := []string{"# Title", "First paragraph\nwrapped.", "```go\nfunc main() {\n\n}\n```", "- item"}
+	if got := Blocks(markdown); !reflect.DeepEqual(got, want) {
+		t.Errorf("Blocks() = %q, want %q", got, want)
+	}
+}
+
+func TestDetectorRepeated(t *testing.T) {
+	footer := "Copyright 2024 Example Inc. All rights reserved."
+	cookies := "We use cookies to improve your experience on this site."
+
+	detector := NewDetector()
+	detector.Add("# One\n\nFirst page text that is long enough.\n\n" + footer + "\n\n" + footer)
+	detector.Add("# Two\n\nSecond page text that is long enough.\n\n" + cookies + "\n\n" + footer)
+	detector.Add("# Three\n\nThird page text that is long enough.\n\n" + cookies + "\n\n" + footer)
+	detector.Add("# Four\n\nFourth page text that is long enough.\n\n" + footer)
+	detector.Add("# Five\n\nFifth page\n\n---\n\n" + cookies)
+
+	tests := []struct {
+		name  string
+		share float64
+		want  []Block
+	}{
+		{name: "most pages", share: 0.8, want: []Block{{Text: footer, Pages: 4}}},
+		{name: "half of the pages", share: 0.5, want: []Block{{Text: footer, Pages: 4}, {Text: cookies, Pages: 3}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detector.Repeated(tt.share); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repeated(%v) = %+v, want %+v", tt.share, got, tt.want)
+			}
+		})
+	}
+
+	small := NewDetector()
+	small.Add(footer)
+	small.Add(footer)
+	if got := small.Repeated(0.5); len(got) != 0 {
+		t.Errorf("Repeated() = %+v, want no block shared by fewer than %d pages", got, MinPages)
+	}
+}
+
+func TestStrip(t *testing.T) {
+	boilerplate := map[string]bool{"Was this page helpful? Let us know.": true}
+
+	got, removed := Strip("# Page\n\nBody text.\n\nWas this page helpful? Let us know.\n", boilerplate)
+	if got != "# Page\n\nBody text." || removed != 1 {
+		t.Errorf("Strip() = %q, %d, want the block removed", got, removed)
+	}
+
+	unchanged := "# Page\n\n\nBody text.\n"
+	if got, removed := Strip(unchanged, boilerplate); got != unchanged || removed != 0 {
+		t.Errorf("Strip() = %q, %d, want the Markdown unchanged", got, removed)
+	}
+}
@@ -3,12 +3,15 @@ package converter
 import (
 	"fmt"
 	"net/url"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	md "github.com/JohannesKaufmann/html-to-markdown"
-	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
 )
 
 // Options defines converter configuration
@@ -20,8 +23,47 @@ type Options struct {
 	EmDelimiter      string
 	StrongDelimiter  string
 	LinkStyle        string
+	RemoveSelectors  []string // CSS selectors of elements removed from the content before conversion
+	DefinitionLists  string   // Style of definition lists: DefinitionListBold (default) or DefinitionListSyntax
+	FlattenTabs      bool     // When true, tab widgets and details/summary accordions become sections with a heading per tab
+	Tables           string   // Strategy for tables with merged cells or nested tables: TableGFM (default), TableHTML, TableCSV, or TableList
+	HeadingAnchors   string   // Style of anchors keeping heading ids: HeadingAnchorNone (default), HeadingAnchorHTML, or HeadingAnchorAttribute
+	Media            string   // Policy for video and audio elements: MediaLink, MediaDownload, or MediaDrop; empty keeps only their fallback text
+	Dialect          string   // Markdown dialect: DialectGFM (default), DialectCommonMark, or DialectMkDocs
+	TableSyntax      string   // Syntax of tables: TableSyntaxPipe (default) or TableSyntaxGrid
+	LineBreaks       string   // Style of line breaks outside tables: LineBreakParagraph (default), LineBreakSpaces, or LineBreakBackslash
+	NoRawHTML        bool     // When true, the HTML table strategy and heading anchors are replaced by Markdown, and pipe table cells are not split with <br>
+	Normalize        NormalizeOptions
 }
 
+// DefaultRemoveSelectors match page chrome often left inside the main content:
+// navigation, asides, footers, breadcrumbs, "edit this page" links, and previous/next page navigation
+var DefaultRemoveSelectors = []string{
+	"nav",
+	"aside",
+	"footer",
+	".breadcrumb",
+	".breadcrumbs",
+	`[aria-label="breadcrumb" i]`,
+	`[aria-label="breadcrumbs" i]`,
+	".edit-this-page",
+	".theme-edit-this-page",
+	".edit-page-link",
+	".pagination-nav",
+	".prev-next",
+	".page-navigation",
+}
+
+// Patterns compiled once, as they run on every page
+var (
+	// extraNewlines matches runs of more than one blank line
+	extraNewlines = regexp.MustCompile(`\n{3,}`)
+	// invalidFilenameChars matches characters replaced in file names, including = and & from query parameters
+	invalidFilenameChars = regexp.MustCompile(`[<>:"/\\|?*=&]`)
+	// dashRuns matches consecutive dashes in file names
+	dashRuns = regexp.MustCompile(`-+`)
+)
+
 // Converter handles HTML to Markdown conversion
 type Converter struct {
 	converter *md.Converter
@@ -32,11 +74,67 @@ type Converter struct {
 func NewConverter(opts Options) (*Converter, error) {
 	converter := md.NewConverter(opts.Domain, true, nil)
 
-	// Add plugins for better conversion
-	converter.Use(plugin.GitHubFlavored())
-	converter.Use(plugin.Table())
-	converter.Use(plugin.TaskListItems())
-	converter.Use(plugin.Strikethrough("~~"))
+	if err := ValidateDialect(opts.Dialect); err != nil {
+		return nil, err
+	}
+	useDialect(converter, opts)
+
+	if err := ValidateDefinitionListStyle(opts.DefinitionLists); err != nil {
+		return nil, err
+	}
+	converter.AddRules(definitionListRules(opts.DefinitionLists)...)
+
+	if opts.NoRawHTML {
+		opts = withoutRawHTML(opts)
+	}
+
+	if err := ValidateTableStrategy(opts.Tables); err != nil {
+		return nil, err
+	}
+	converter.AddRules(complexTableRules(opts.Tables)...)
+
+	if err := ValidateTableSyntax(opts.TableSyntax); err != nil {
+		return nil, err
+	}
+	if err := ValidateLineBreakStyle(opts.LineBreaks); err != nil {
+		return nil, err
+	}
+	if opts.TableSyntax == TableSyntaxGrid {
+		converter.AddRules(gridTableRules(opts.Tables, newCellConverter(opts))...)
+	} else if opts.NoRawHTML {
+		converter.AddRules(pipeCellRules()...)
+	}
+	converter.AddRules(lineBreakRules(opts.LineBreaks)...)
+	if opts.LineBreaks == LineBreakSpaces {
+		converter.After(restoreSpaceBreaks)
+	}
+
+	if err := ValidateHeadingAnchorStyle(opts.HeadingAnchors); err != nil {
+		return nil, err
+	}
+	converter.AddRules(headingAnchorRules(opts.HeadingAnchors)...)
+
+	if err := ValidateMediaPolicy(opts.Media); err != nil {
+		return nil, err
+	}
+	converter.AddRules(mediaRules(opts.Media)...)
+
+	converter.Before(preserveDiagrams)
+	converter.Before(resolveImageSources)
+
+	if opts.FlattenTabs {
+		converter.Before(flattenTabs)
+	}
+
+	if len(opts.RemoveSelectors) > 0 {
+		selector := strings.Join(opts.RemoveSelectors, ", ")
+		if _, err := cascadia.ParseGroup(selector); err != nil {
+			return nil, fmt.Errorf("invalid remove selector: %w", err)
+		}
+		converter.Before(func(selection *goquery.Selection) {
+			selection.Find(selector).Remove()
+		})
+	}
 
 	return &Converter{
 		converter: converter,
@@ -63,9 +161,10 @@ func (c *Converter) Convert(html string) (string, error) {
 
 // cleanMarkdown performs post-processing cleanup on the markdown
 func (c *Converter) cleanMarkdown(markdown string) string {
+	markdown = c.options.Normalize.normalize(markdown)
+
 	// Remove excessive newlines (more than 2 consecutive)
-	re := regexp.MustCompile(`\n{3,}`)
-	markdown = re.ReplaceAllString(markdown, "\n\n")
+	markdown = extraNewlines.ReplaceAllString(markdown, "\n\n")
 
 	// Trim leading and trailing whitespace
 	markdown = strings.TrimSpace(markdown)
@@ -75,70 +174,196 @@ func (c *Converter) cleanMarkdown(markdown string) string {
 
 // ConvertLinksToLocal converts absolute URLs to local markdown file references
 func ConvertLinksToLocal(markdown string, baseURL string, urlToFileMap map[string]string) string {
+	return ConvertLinksToLocalFrom(markdown, baseURL, "", urlToFileMap)
+}
+
+// ConvertLinksToLocalFrom converts absolute URLs to local file references relative to the directory of baseFile
+func ConvertLinksToLocalFrom(markdown string, baseURL string, baseFile string, urlToFileMap map[string]string) string {
+	if !strings.Contains(markdown, "](") {
+		return markdown
+	}
+
 	parsedBase, err := url.Parse(baseURL)
 	if err != nil {
 		return markdown
 	}
 
 	// Replace markdown links [text](url) with local file references
-	re := regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
-	markdown = re.ReplaceAllStringFunc(markdown, func(match string) string {
-		parts := re.FindStringSubmatch(match)
-		if len(parts) != 3 {
+	return replaceMarkdownLinks(markdown, func(match string, _ bool, linkText, target string) string {
+		localFile, fragment, ok := resolveLocalLink(target, parsedBase, urlToFileMap)
+		if !ok {
+			// Keep external links as-is
 			return match
 		}
+		localFile = RelativePath(baseFile, localFile)
 
-		linkText := parts[1]
-		linkURL := parts[2]
+		// Convert to local markdown file reference
+		if fragment != "" {
+			return "[" + linkText + "](" + localFile + "#" + fragment + ")"
+		}
+		return "[" + linkText + "](" + localFile + ")"
+	})
+}
 
-		// Skip anchor links, external protocols, and fragments
-		if strings.HasPrefix(linkURL, "#") ||
-			strings.HasPrefix(linkURL, "mailto:") ||
-			strings.HasPrefix(linkURL, "javascript:") {
-			return match
+// replaceMarkdownLinks replaces each Markdown link [text](target) with the result of replace, which receives
+// the link, whether it is an image (preceded by !, which is kept), its text, and its target. It finds the same
+// links as the pattern `(?s)\[((?:\\.|[^\]\\])+)\]\(([^)]+)\)`, where escaped brackets are part of the text,
+// in one pass over the text, as regular expressions with submatches are slow on large pages.
+func replaceMarkdownLinks(markdown string, replace func(match string, image bool, text, target string) string) string {
+	var builder strings.Builder
+	last := 0
+	for i := 0; i < len(markdown); {
+		open := strings.IndexByte(markdown[i:], '[')
+		if open < 0 {
+			break
 		}
+		open += i
 
-		// Parse the link URL
-		parsedLink, err := url.Parse(linkURL)
-		if err != nil {
-			return match
+		// The text ends at the first unescaped ], and the target at the first ) after it
+		closeText := closingBracket(markdown, open+1)
+		if closeText < 0 {
+			break
+		}
+		// Brackets opened before closeText end at the same ], so the search resumes after it
+		if closeText == open+1 || closeText+1 >= len(markdown) || markdown[closeText+1] != '(' {
+			i = closeText + 1
+			continue
 		}
+		closeTarget := strings.IndexByte(markdown[closeText+2:], ')')
+		if closeTarget < 0 {
+			break
+		}
+		closeTarget += closeText + 2
+		if closeTarget == closeText+2 {
+			i = closeText + 1
+			continue
+		}
+
+		image := open > 0 && markdown[open-1] == '!'
+		end := closeTarget + 1
 
-		// Make relative URLs absolute
-		if !parsedLink.IsAbs() {
-			parsedLink = parsedBase.ResolveReference(parsedLink)
+		if builder.Len() == 0 {
+			builder.Grow(len(markdown))
 		}
+		builder.WriteString(markdown[last:open])
+		builder.WriteString(replace(markdown[open:end], image, markdown[open+1:closeText], markdown[closeText+2:closeTarget]))
+		last = end
+		i = end
+	}
+
+	if last == 0 {
+		return markdown
+	}
+	builder.WriteString(markdown[last:])
+	return builder.String()
+}
 
-		// Check if we have a local file for this URL
-		// Try with full URL including query parameters (normalized without trailing slash)
-		fullURL := parsedLink.Scheme + "://" + parsedLink.Host + strings.TrimSuffix(parsedLink.Path, "/")
-		if parsedLink.RawQuery != "" {
-			fullURL += "?" + parsedLink.RawQuery
+// closingBracket returns the index of the first ] from start that is not escaped with a backslash, or -1
+func closingBracket(markdown string, start int) int {
+	for i := start; i < len(markdown); i++ {
+		switch markdown[i] {
+		case '\\':
+			i++
+		case ']':
+			return i
 		}
+	}
+	return -1
+}
 
-		if localFile, exists := urlToFileMap[fullURL]; exists {
-			// Convert to local markdown file reference
-			if parsedLink.Fragment != "" {
-				return fmt.Sprintf("[%s](%s#%s)", linkText, localFile, parsedLink.Fragment)
-			}
-			return fmt.Sprintf("[%s](%s)", linkText, localFile)
+// ConvertLinksToWikilinks converts links to crawled pages into [[page|text]] wikilinks.
+// Images, external links, and link texts that cannot be represented in a wikilink are kept as Markdown links.
+func ConvertLinksToWikilinks(markdown string, baseURL string, urlToFileMap map[string]string) string {
+	parsedBase, err := url.Parse(baseURL)
+	if err != nil {
+		return markdown
+	}
+
+	return replaceMarkdownLinks(markdown, func(match string, image bool, linkText, target string) string {
+		if image {
+			return match
 		}
 
-		// Try without query parameters as fallback (also normalized)
-		cleanURL := parsedLink.Scheme + "://" + parsedLink.Host + strings.TrimSuffix(parsedLink.Path, "/")
-		if localFile, exists := urlToFileMap[cleanURL]; exists {
-			// Convert to local markdown file reference
-			if parsedLink.Fragment != "" {
-				return fmt.Sprintf("[%s](%s#%s)", linkText, localFile, parsedLink.Fragment)
-			}
+		localFile, _, ok := resolveLocalLink(target, parsedBase, urlToFileMap)
+		if !ok {
+			return match
+		}
+
+		if strings.ContainsAny(linkText, "|[]") {
 			return fmt.Sprintf("[%s](%s)", linkText, localFile)
 		}
 
-		// Keep external links as-is
-		return match
+		page := strings.TrimSuffix(localFile, filepath.Ext(localFile))
+		if linkText == page {
+			return "[[" + page + "]]"
+		}
+		return "[[" + page + "|" + linkText + "]]"
 	})
+}
 
-	return markdown
+// resolveLocalLink returns the local file and fragment for a link to a crawled page
+func resolveLocalLink(linkURL string, parsedBase *url.URL, urlToFileMap map[string]string) (string, string, bool) {
+	// Skip anchor links, external protocols, and fragments
+	if strings.HasPrefix(linkURL, "#") ||
+		strings.HasPrefix(linkURL, "mailto:") ||
+		strings.HasPrefix(linkURL, "javascript:") {
+		return "", "", false
+	}
+
+	// Parse the link URL
+	parsedLink, err := url.Parse(linkURL)
+	if err != nil {
+		return "", "", false
+	}
+
+	// Make relative URLs absolute
+	if !parsedLink.IsAbs() {
+		parsedLink = parsedBase.ResolveReference(parsedLink)
+	}
+
+	// Check if we have a local file for this URL
+	// Try with full URL including query parameters (normalized without trailing slash)
+	cleanURL := parsedLink.Scheme + "://" + parsedLink.Host + strings.TrimSuffix(parsedLink.Path, "/")
+	fullURL := cleanURL
+	if parsedLink.RawQuery != "" {
+		fullURL += "?" + parsedLink.RawQuery
+	}
+
+	if localFile, exists := urlToFileMap[fullURL]; exists {
+		return localFile, parsedLink.EscapedFragment(), true
+	}
+
+	// Try without query parameters as fallback (also normalized)
+	if localFile, exists := urlToFileMap[cleanURL]; exists {
+		return localFile, parsedLink.EscapedFragment(), true
+	}
+
+	return "", "", false
+}
+
+// RelativePath returns the slash-separated path of target as seen from the directory of fromFile.
+// Both paths are relative to the output root.
+func RelativePath(fromFile, target string) string {
+	fromDir := path.Dir(fromFile)
+	if fromDir == "." || fromDir == "/" {
+		return target
+	}
+
+	fromParts := strings.Split(fromDir, "/")
+	targetParts := strings.Split(target, "/")
+
+	common := 0
+	for common < len(fromParts) && common < len(targetParts)-1 && fromParts[common] == targetParts[common] {
+		common++
+	}
+
+	parts := make([]string, 0, len(fromParts)-common+len(targetParts)-common)
+	for range fromParts[common:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, targetParts[common:]...)
+
+	return strings.Join(parts, "/")
 }
 
 // GenerateFilename creates a safe filename from a URL
@@ -189,23 +414,33 @@ func GenerateFilename(pageURL string) string {
 	return filename
 }
 
+// SanitizeSegment makes a single path segment safe to use in a file name
+func SanitizeSegment(segment string) string {
+	return sanitizeFilename(segment)
+}
+
 // sanitizeFilename removes or replaces invalid filename characters
 func sanitizeFilename(filename string) string {
+	// Decode percent-encoded query values so that non-ASCII text stays readable
+	if strings.Contains(filename, "%") {
+		if decoded, err := url.PathUnescape(filename); err == nil && utf8.ValidString(decoded) {
+			filename = decoded
+		}
+	}
+
 	// Replace invalid characters with dash (including = and & from query params)
-	re := regexp.MustCompile(`[<>:"/\\|?*=&]`)
-	filename = re.ReplaceAllString(filename, "-")
+	filename = invalidFilenameChars.ReplaceAllString(filename, "-")
 
 	// Remove multiple consecutive dashes
-	re = regexp.MustCompile(`-+`)
-	filename = re.ReplaceAllString(filename, "-")
+	filename = dashRuns.ReplaceAllString(filename, "-")
 
-	// Trim dashes from start and end
-	filename = strings.Trim(filename, "-")
+	// Trim dashes from start and end, and the trailing dots and spaces Windows drops
+	filename = strings.TrimRight(strings.TrimLeft(filename, "-"), "-. ")
 
 	// If empty, return default
 	if filename == "" {
 		filename = "page"
 	}
 
-	return filename
+	return portableSegment(filename)
 }
@@ -0,0 +1,71 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// largePage returns the HTML of a page with the given number of sections, each with a heading, text, a list,
+// a code block, and links to other pages of the site
+func largePage(sections int) string {
+	var builder strings.Builder
+	builder.WriteString("<html><body><main>")
+	for i := 0; i < sections; i++ {
+		fmt.Fprintf(&builder, `<h2 id="section-%d">Section %d</h2>`, i, i)
+		fmt.Fprintf(&builder, `<p>Some <strong>text</strong> with a <a href="https://example.com/docs/page-%d">link</a> and <code>code</code>.</p>`, i)
+		fmt.Fprintf(&builder, `<ul><li><a href="/docs/page-%d#intro">First</a></li><li>Second</li></ul>`, i+1)
+		builder.WriteString("<pre><code>func main() {\n\tprintln(\"hello\")\n}</code></pre>")
+	}
+	builder.WriteString("</main></body></html>")
+	return builder.String()
+}
+
+// linkedMarkdown returns Markdown with the given number of links to crawled pages and external sites,
+// and the map of the crawled pages to their files
+func linkedMarkdown(links, pages int) (string, map[string]string) {
+	urlToFile := make(map[string]string, pages)
+	for i := 0; i < pages; i++ {
+		urlToFile[fmt.Sprintf("https://example.com/docs/page-%d", i)] = fmt.Sprintf("docs/page-%d.md", i)
+	}
+
+	var builder strings.Builder
+	for i := 0; i < links; i++ {
+		fmt.Fprintf(&builder, "Read [page %d](https://example.com/docs/page-%d#intro) or [the spec](https://other.example/spec-%d).\n\n", i, i%pages, i)
+	}
+	return builder.String(), urlToFile
+}
+
+func BenchmarkConvert(b *testing.B) {
+	for _, sections := range []int{10, 1000} {
+		b.Run(fmt.Sprintf("sections=%d", sections), func(b *testing.B) {
+			conv, err := NewConverter(Options{})
+			if err != nil {
+				b.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+			html := largePage(sections)
+
+			b.SetBytes(int64(len(html)))
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := conv.Convert(html); err != nil {
+					b.Fatalf("Convert() unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkConvertLinksToLocal(b *testing.B) {
+	for _, links := range []int{10, 1000} {
+		b.Run(fmt.Sprintf("links=%d", links), func(b *testing.B) {
+			markdown, urlToFile := linkedMarkdown(links, 10000)
+
+			b.SetBytes(int64(len(markdown)))
+			b.ReportAllocs()
+			for b.Loop() {
+				ConvertLinksToLocalFrom(markdown, "https://example.com/docs/index", "docs/index.md", urlToFile)
+			}
+		})
+	}
+}
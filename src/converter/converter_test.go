@@ -1,8 +1,11 @@
 package converter
 
 import (
+	"path"
+	"regexp"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestNewConverter(t *testing.T) {
@@ -158,6 +161,16 @@ func TestGenerateFilename(t *testing.T) {
 			url:      "https://example.com/page.html",
 			expected: "page.md",
 		},
+		{
+			name:     "windows device name",
+			url:      "https://example.com/con",
+			expected: "con_.md",
+		},
+		{
+			name:     "trailing dot",
+			url:      "https://example.com/docs/v1.",
+			expected: "docs-v1.md",
+		},
 		{
 			name:     "path with query",
 			url:      "https://example.com/search?q=test",
@@ -183,6 +196,16 @@ func TestGenerateFilename(t *testing.T) {
 			url:      "https://example.com",
 			expected: "index.md",
 		},
+		{
+			name:     "percent-encoded query",
+			url:      "https://example.com/search?q=caf%C3%A9",
+			expected: "search-q-café.md",
+		},
+		{
+			name:     "non-ASCII path",
+			url:      "https://example.com/%E6%97%A5%E6%9C%AC/%C3%BCber",
+			expected: "日本-über.md",
+		},
 		{
 			name:     "path with special chars",
 			url:      "https://example.com/hello:world",
@@ -231,6 +254,16 @@ func TestSanitizeFilename(t *testing.T) {
 			input:    "",
 			expected: "page",
 		},
+		{
+			name:     "trailing dots and spaces",
+			input:    "hello. . ",
+			expected: "hello",
+		},
+		{
+			name:     "windows device name",
+			input:    "NUL",
+			expected: "NUL_",
+		},
 		{
 			name:     "all invalid chars",
 			input:    "<>:\"/\\|?*",
@@ -285,3 +318,493 @@ func TestCleanMarkdown(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertLinksToWikilinks(t *testing.T) {
+	urlToFile := map[string]string{
+		"https://example.com/guide":      "guide.md",
+		"https://example.com/docs/intro": "docs-intro.md",
+	}
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "link with alias",
+			markdown: "[Read the intro](/docs/intro)",
+			want:     "[[docs-intro|Read the intro]]",
+		},
+		{
+			name:     "link text equal to page name",
+			markdown: "[guide](https://example.com/guide/)",
+			want:     "[[guide]]",
+		},
+		{
+			name:     "image kept",
+			markdown: "![diagram](/guide)",
+			want:     "![diagram](/guide)",
+		},
+		{
+			name:     "external link kept",
+			markdown: "[other](https://other.com/page)",
+			want:     "[other](https://other.com/page)",
+		},
+		{
+			name:     "pipe in text falls back to markdown link",
+			markdown: "[a | b](/guide)",
+			want:     "[a | b](guide.md)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertLinksToWikilinks(tt.markdown, "https://example.com/", urlToFile); got != tt.want {
+				t.Errorf("ConvertLinksToWikilinks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertLinksToLocalFrom(t *testing.T) {
+	urlToFile := map[string]string{
+		"https://example.com/en/guide": "en/guide.md",
+		"https://example.com/en/intro": "en/intro.md",
+		"https://example.com/de/guide": "de/guide.md",
+		"https://example.com/about":    "about.md",
+	}
+
+	markdown := "[intro](/en/intro#setup) [de](/de/guide) [about](/about) [steps](/about#first%20steps)"
+	want := "[intro](intro.md#setup) [de](../de/guide.md) [about](../about.md) [steps](../about.md#first%20steps)"
+
+	if got := ConvertLinksToLocalFrom(markdown, "https://example.com/en/guide", "en/guide.md", urlToFile); got != want {
+		t.Errorf("ConvertLinksToLocalFrom() = %q, want %q", got, want)
+	}
+}
+
+func TestReplaceMarkdownLinks(t *testing.T) {
+	// The links found must be those of the pattern the scanner replaces
+	pattern := regexp.MustCompile(`(?s)\[((?:\\.|[^\]\\])+)\]\(([^)]+)\)`)
+
+	inputs := []string{
+		"[a](b)",
+		"![alt](img.png) and [link](url)",
+		"text [a] (b) [](c) [d]() [e](f",
+		"[[nested](x)] [a]b](c) [a](b)c)",
+		"[multi\nline](target) [[[[x](y)",
+		"no links at all",
+		"[a](b)[c](d)!",
+		"]([a](b)",
+		`[Second \[draft\]](url) [a\\](b) [c\](d)`,
+		`[trailing\`,
+		"[hard\\\nbreak](target)",
+	}
+
+	for _, input := range inputs {
+		want := pattern.ReplaceAllStringFunc(input, func(match string) string {
+			parts := pattern.FindStringSubmatch(match)
+			return "<" + parts[1] + "|" + parts[2] + ">"
+		})
+		got := replaceMarkdownLinks(input, func(_ string, _ bool, text, target string) string {
+			return "<" + text + "|" + target + ">"
+		})
+		if got != want {
+			t.Errorf("replaceMarkdownLinks(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		fromFile string
+		target   string
+		want     string
+	}{
+		{fromFile: "index.md", target: "en/guide.md", want: "en/guide.md"},
+		{fromFile: "en/index.md", target: "en/guide.md", want: "guide.md"},
+		{fromFile: "en/index.md", target: "assets/a.png", want: "../assets/a.png"},
+		{fromFile: "en/docs/index.md", target: "en/guide.md", want: "../guide.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fromFile+"->"+tt.target, func(t *testing.T) {
+			if got := RelativePath(tt.fromFile, tt.target); got != tt.want {
+				t.Errorf("RelativePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertRemoveSelectors(t *testing.T) {
+	html := `<nav class="breadcrumb"><a href="/">Home</a> / Docs</nav>
+<h1>Install</h1>
+<p>Run the installer.</p>
+<aside>Related posts</aside>
+<div class="theme-edit-this-page"><a href="https://github.com/edit">Edit this page</a></div>
+<div class="note">Custom note</div>
+<footer><a href="/prev">Previous</a> <a href="/next">Next</a></footer>`
+
+	conv, err := NewConverter(Options{RemoveSelectors: append(append([]string{}, DefaultRemoveSelectors...), ".note")})
+	if err != nil {
+		t.Fatalf("NewConverter() failed: %v", err)
+	}
+
+	got, err := conv.Convert(html)
+	if err != nil {
+		t.Fatalf("Convert() unexpected error: %v", err)
+	}
+
+	want := "# Install\n\nRun the installer."
+	if got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+}
+
+func TestNewConverterInvalidRemoveSelector(t *testing.T) {
+	if _, err := NewConverter(Options{RemoveSelectors: []string{"div["}}); err == nil {
+		t.Errorf("NewConverter() expected error for an invalid selector")
+	}
+}
+
+func TestLimitFilename(t *testing.T) {
+	long := strings.Repeat("a", 300)
+
+	tests := []struct {
+		name      string
+		filename  string
+		maxLength int
+		wantLen   int
+		wantExt   string
+	}{
+		{name: "short name unchanged", filename: "docs/guide.md", maxLength: 64, wantLen: len("guide.md"), wantExt: ".md"},
+		{name: "long name truncated", filename: long + ".md", maxLength: 64, wantLen: 64, wantExt: ".md"},
+		{name: "long directory truncated", filename: "content/" + long + "/_index.md", maxLength: 64, wantLen: len("_index.md"), wantExt: ".md"},
+		{name: "multi-byte characters", filename: strings.Repeat("é", 100) + ".md", maxLength: 64, wantExt: ".md"},
+		{name: "no limit", filename: long + ".md", maxLength: 0, wantLen: 303, wantExt: ".md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LimitFilename(tt.filename, tt.maxLength)
+
+			for _, segment := range strings.Split(got, "/") {
+				if tt.maxLength > 0 && len(segment) > tt.maxLength {
+					t.Errorf("LimitFilename() segment %q has %d bytes, want at most %d", segment, len(segment), tt.maxLength)
+				}
+				if !utf8.ValidString(segment) {
+					t.Errorf("LimitFilename() segment %q is not valid UTF-8", segment)
+				}
+			}
+
+			base := path.Base(got)
+			if tt.wantLen > 0 && len(base) != tt.wantLen {
+				t.Errorf("LimitFilename() base %q has %d bytes, want %d", base, len(base), tt.wantLen)
+			}
+			if path.Ext(got) != tt.wantExt {
+				t.Errorf("LimitFilename() = %q, want extension %q", got, tt.wantExt)
+			}
+			if again := LimitFilename(tt.filename, tt.maxLength); again != got {
+				t.Errorf("LimitFilename() is not stable: %q != %q", again, got)
+			}
+		})
+	}
+
+	if LimitFilename(long+"1.md", 64) == LimitFilename(long+"2.md", 64) {
+		t.Error("LimitFilename() maps different long names to the same file")
+	}
+}
+
+func TestPortableFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "ordinary path unchanged", input: "docs/guide.md", expected: "docs/guide.md"},
+		{name: "device name with extension", input: "docs/con.md", expected: "docs/con_.md"},
+		{name: "device name in any case", input: "Aux/Com1.tar.gz", expected: "Aux_/Com1_.tar.gz"},
+		{name: "device name followed by spaces", input: "lpt9 .md", expected: "lpt9_ .md"},
+		{name: "superscript digit", input: "com¹.md", expected: "com¹_.md"},
+		{name: "longer names kept", input: "console/com10.md", expected: "console/com10.md"},
+		{name: "trailing dots and spaces", input: "docs. /guide .", expected: "docs/guide"},
+		{name: "only dots", input: "a/.../b.md", expected: "a/page/b.md"},
+		{name: "relative segments kept", input: "../a/./b.md", expected: "../a/./b.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PortableFilename(tt.input)
+			if got != tt.expected {
+				t.Errorf("PortableFilename(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+			if again := PortableFilename(got); again != got {
+				t.Errorf("PortableFilename() is not stable: %q != %q", again, got)
+			}
+		})
+	}
+}
+
+func TestDisambiguateFilename(t *testing.T) {
+	a := DisambiguateFilename("docs/a-b.md", "https://example.com/a/b")
+	b := DisambiguateFilename("docs/a-b.md", "https://example.com/a-b")
+
+	if a == b {
+		t.Errorf("DisambiguateFilename() = %q for both keys, want distinct names", a)
+	}
+
+	if !strings.HasPrefix(a, "docs/a-b-") || path.Ext(a) != ".md" {
+		t.Errorf("DisambiguateFilename() = %q, want docs/a-b-<hash>.md", a)
+	}
+
+	if again := DisambiguateFilename("docs/a-b.md", "https://example.com/a/b"); again != a {
+		t.Errorf("DisambiguateFilename() is not stable: %q != %q", again, a)
+	}
+}
+
+func TestTransliterateFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "ascii unchanged", input: "docs/guide.md", expected: "docs/guide.md"},
+		{name: "accents", input: "café-crème/über.md", expected: "cafe-creme/uber.md"},
+		{name: "special latin letters", input: "straße-smørrebrød.md", expected: "strasse-smorrebrod.md"},
+		{name: "cyrillic", input: "Привет-мир.md", expected: "Privet-mir.md"},
+		{name: "greek", input: "καλημέρα.md", expected: "kalimera.md"},
+		{name: "cjk kept", input: "日本語-ガイド.md", expected: "日本語-ガイド.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := TransliterateFilename(tt.input); result != tt.expected {
+				t.Errorf("TransliterateFilename() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertDefinitionLists(t *testing.T) {
+	html := `<dl><dt>timeout</dt><dd>Request timeout in <code>seconds</code>.</dd><dt>delay</dt><dd>Delay between requests.</dd><dd>Defaults to 1.</dd></dl>`
+
+	tests := []struct {
+		name     string
+		style    string
+		expected string
+	}{
+		{
+			name:     "bold terms by default",
+			expected: "**timeout**\n\nRequest timeout in `seconds`.\n\n**delay**\n\nDelay between requests.\n\nDefaults to 1.",
+		},
+		{
+			name:     "definition list syntax",
+			style:    DefinitionListSyntax,
+			expected: "timeout\n: Request timeout in `seconds`.\n\ndelay\n: Delay between requests.\n: Defaults to 1.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := NewConverter(Options{DefinitionLists: tt.style})
+			if err != nil {
+				t.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+
+			result, err := conv.Convert(html)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Convert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := NewConverter(Options{DefinitionLists: "table"}); err == nil {
+		t.Error("NewConverter() expected an error for an unknown definition list style")
+	}
+}
+
+func TestConvertFlattenTabs(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name: "aria tabs",
+			html: `<h2>Install</h2><div class="tabs"><div role="tablist"><button role="tab" aria-controls="npm">npm</button><button role="tab" aria-controls="yarn">Yarn</button></div>` +
+				`<div role="tabpanel" id="npm"><pre><code>npm install pkg</code></pre></div><div role="tabpanel" id="yarn" hidden><pre><code>yarn add pkg</code></pre></div></div>`,
+			expected: "## Install\n\n### npm\n\n```\nnpm install pkg\n```\n\n### Yarn\n\n```\nyarn add pkg\n```",
+		},
+		{
+			name: "material tabbed set",
+			html: `<h3>Run</h3><div class="tabbed-set"><input type="radio" id="t1"><input type="radio" id="t2"><div class="tabbed-labels"><label for="t1">Linux</label><label for="t2">Windows</label></div>` +
+				`<div class="tabbed-content"><div class="tabbed-block"><p>./run.sh</p></div><div class="tabbed-block"><p>run.bat</p></div></div></div>`,
+			expected: "### Run\n\n#### Linux\n\n./run.sh\n\n#### Windows\n\nrun.bat",
+		},
+		{
+			name:     "details accordion",
+			html:     `<h1>FAQ</h1><details><summary>Is it <em>free</em>?</summary><p>Yes.</p></details>`,
+			expected: "# FAQ\n\n## Is it free?\n\nYes.",
+		},
+		{
+			name:     "accordion without preceding heading",
+			html:     `<details><summary>More</summary><p>Hidden text.</p></details>`,
+			expected: "#### More\n\nHidden text.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := NewConverter(Options{FlattenTabs: true})
+			if err != nil {
+				t.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+
+			result, err := conv.Convert(tt.html)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Convert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConvertDiagrams(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "mermaid source in a div",
+			html:     "<div class=\"mermaid\">\n    graph TD\n      A --&gt; B\n</div>",
+			expected: "```mermaid\ngraph TD\n  A --> B\n```",
+		},
+		{
+			name:     "plantuml pre",
+			html:     "<pre class=\"plantuml\">@startuml\nAlice -> Bob\n@enduml</pre>",
+			expected: "```plantuml\n@startuml\nAlice -> Bob\n@enduml\n```",
+		},
+		{
+			name:     "rendered diagram with source attribute",
+			html:     `<figure data-diagram-source="sequenceDiagram&#10;A->>B: Hi"><svg><text>A</text></svg></figure>`,
+			expected: "```mermaid\nsequenceDiagram\nA->>B: Hi\n```",
+		},
+		{
+			name:     "rendered diagram without source",
+			html:     `<div class="mermaid"><svg><text>A</text></svg></div>`,
+			expected: "A",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := NewConverter(Options{})
+			if err != nil {
+				t.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+
+			result, err := conv.Convert(tt.html)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Convert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCleanMarkdownNormalize(t *testing.T) {
+	all := NormalizeOptions{NBSP: true, ZeroWidth: true, Punctuation: true, Typography: true}
+
+	tests := []struct {
+		name      string
+		normalize NormalizeOptions
+		input     string
+		expected  string
+	}{
+		{
+			name:     "disabled by default",
+			input:    "a\u00a0\u00a0b\u200b , “c”",
+			expected: "a\u00a0\u00a0b\u200b , “c”",
+		},
+		{
+			name:      "non-breaking spaces",
+			normalize: NormalizeOptions{NBSP: true},
+			input:     "10\u00a0km and a \u00a0 gap\n\n    indented",
+			expected:  "10 km and a gap\n\n    indented",
+		},
+		{
+			name:      "zero-width characters",
+			normalize: NormalizeOptions{ZeroWidth: true},
+			input:     "\ufeffzero\u200bwidth soft\u00adhyphen family \U0001F468\u200d\U0001F469",
+			expected:  "zerowidth softhyphen family \U0001F468\u200d\U0001F469",
+		},
+		{
+			name:      "spaces before punctuation",
+			normalize: NormalizeOptions{Punctuation: true},
+			input:     "See **docs** . Then run it , or not !\n(see [this](/x) )\n| a | b |\n| :-- | --: |",
+			expected:  "See **docs**. Then run it, or not!\n(see [this](/x))\n| a | b |\n| :-- | --: |",
+		},
+		{
+			name:      "typography",
+			normalize: NormalizeOptions{Typography: true},
+			input:     "“Quoted” it’s 1–2 — wait…",
+			expected:  "\"Quoted\" it's 1-2 -- wait...",
+		},
+		{
+			name:      "code is left unchanged",
+			normalize: all,
+			input:     "“a” `x , “y”`\n\n```\nfoo , “bar”\u00a0\n```\n\n~~~go\nx := “y”\n~~~",
+			expected:  "\"a\" `x , “y”`\n\n```\nfoo , “bar”\u00a0\n```\n\n~~~go\nx := “y”\n~~~",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := NewConverter(Options{Normalize: tt.normalize})
+			if err != nil {
+				t.Fatalf("NewConverter() failed: %v", err)
+			}
+
+			if result := conv.cleanMarkdown(tt.input); result != tt.expected {
+				t.Errorf("cleanMarkdown() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseNormalize(t *testing.T) {
+	tests := []struct {
+		names   []string
+		want    NormalizeOptions
+		wantErr bool
+	}{
+		{names: DefaultNormalize, want: NormalizeOptions{NBSP: true, ZeroWidth: true, Punctuation: true}},
+		{names: []string{"typography"}, want: NormalizeOptions{Typography: true}},
+		{names: []string{"none"}, want: NormalizeOptions{}},
+		{names: nil, want: NormalizeOptions{}},
+		{names: []string{"none", "nbsp"}, wantErr: true},
+		{names: []string{"emoji"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.names, ","), func(t *testing.T) {
+			got, err := ParseNormalize(tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNormalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseNormalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
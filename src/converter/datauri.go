@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"encoding/base64"
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// DefaultAssetsDir is the directory, relative to the output directory, where extracted assets are stored
+const DefaultAssetsDir = "assets"
+
+// Asset is a binary resource extracted from page content
+type Asset struct {
+	Path string // Slash-separated path relative to the output directory
+	Data []byte
+}
+
+var (
+	dataURIPattern    = regexp.MustCompile(`data:([\w.+-]+/[\w.+-]+)((?:;[\w.+-]+=[^;,"'()\s]*)*);base64,([A-Za-z0-9+/=]+)`)
+	simpleSubtypeRule = regexp.MustCompile(`^[a-z0-9]+$`)
+)
+
+// ExtractDataURIs replaces base64 data URIs larger than threshold bytes with references to asset files
+// stored under assetsDir. Smaller data URIs are kept inline.
+func ExtractDataURIs(html string, threshold int, assetsDir string) (string, []Asset) {
+	if !strings.Contains(html, "data:") {
+		return html, nil
+	}
+
+	if assetsDir == "" {
+		assetsDir = DefaultAssetsDir
+	}
+
+	var assets []Asset
+	seen := make(map[string]bool)
+
+	html = dataURIPattern.ReplaceAllStringFunc(html, func(match string) string {
+		parts := dataURIPattern.FindStringSubmatch(match)
+		if len(parts) != 4 {
+			return match
+		}
+
+		data, err := base64.StdEncoding.DecodeString(parts[3])
+		if err != nil || len(data) < threshold {
+			return match
+		}
+
+		assetPath := AssetPath(assetsDir, data, extensionForMimeType(parts[1]))
+
+		if !seen[assetPath] {
+			seen[assetPath] = true
+			assets = append(assets, Asset{Path: assetPath, Data: data})
+		}
+
+		return assetPath
+	})
+
+	return html, assets
+}
+
+// extensionForMimeType returns a file extension for a MIME type
+func extensionForMimeType(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "image/jpeg", "image/jpg":
+		return ".jpg"
+	case "image/svg+xml":
+		return ".svg"
+	}
+
+	if extensions, err := mime.ExtensionsByType(mimeType); err == nil && len(extensions) > 0 {
+		return extensions[0]
+	}
+
+	if _, subtype, ok := strings.Cut(mimeType, "/"); ok && simpleSubtypeRule.MatchString(subtype) {
+		return "." + subtype
+	}
+
+	return ".bin"
+}
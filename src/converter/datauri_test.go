@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestExtractDataURIs(t *testing.T) {
+	large := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 200)))
+	small := base64.StdEncoding.EncodeToString([]byte("tiny"))
+
+	html := `<img src="data:image/png;base64,` + large + `">` +
+		`<div style="background-image: url(data:image/png;base64,` + large + `)"></div>` +
+		`<img src="data:image/gif;base64,` + small + `">`
+
+	result, assets := ExtractDataURIs(html, 100, "")
+
+	if len(assets) != 1 {
+		t.Fatalf("ExtractDataURIs() expected 1 deduplicated asset, got %d", len(assets))
+	}
+
+	asset := assets[0]
+	if !strings.HasPrefix(asset.Path, "assets/") || !strings.HasSuffix(asset.Path, ".png") {
+		t.Errorf("ExtractDataURIs() unexpected asset path %q", asset.Path)
+	}
+
+	if string(asset.Data) != strings.Repeat("x", 200) {
+		t.Errorf("ExtractDataURIs() asset data was not decoded")
+	}
+
+	if strings.Count(result, asset.Path) != 2 {
+		t.Errorf("ExtractDataURIs() expected both references rewritten\nGot: %s", result)
+	}
+
+	if !strings.Contains(result, "data:image/gif;base64,"+small) {
+		t.Errorf("ExtractDataURIs() should keep small data URIs inline\nGot: %s", result)
+	}
+}
+
+func TestExtractDataURIsWithoutDataURIs(t *testing.T) {
+	html := `<p>No images here</p>`
+
+	result, assets := ExtractDataURIs(html, 0, "media")
+	if result != html || len(assets) != 0 {
+		t.Errorf("ExtractDataURIs() = %q, %d assets", result, len(assets))
+	}
+}
+
+func TestExtensionForMimeType(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		want     string
+	}{
+		{mimeType: "image/png", want: ".png"},
+		{mimeType: "image/jpeg", want: ".jpg"},
+		{mimeType: "image/svg+xml", want: ".svg"},
+		{mimeType: "image/webp", want: ".webp"},
+		{mimeType: "application/x-unknown+thing", want: ".bin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mimeType, func(t *testing.T) {
+			if got := extensionForMimeType(tt.mimeType); got != tt.want {
+				t.Errorf("extensionForMimeType(%q) = %q, want %q", tt.mimeType, got, tt.want)
+			}
+		})
+	}
+}
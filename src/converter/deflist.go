@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Definition list styles
+const (
+	// DefinitionListBold writes each term in bold on its own line, followed by its definitions as paragraphs
+	DefinitionListBold = "bold"
+	// DefinitionListSyntax writes PHP Markdown Extra definition lists ("Term" followed by ": definition")
+	DefinitionListSyntax = "definition"
+)
+
+// ValidateDefinitionListStyle checks that a definition list style is known
+func ValidateDefinitionListStyle(style string) error {
+	switch style {
+	case "", DefinitionListBold, DefinitionListSyntax:
+		return nil
+	default:
+		return fmt.Errorf("unknown definition list style %q (expected %s or %s)", style, DefinitionListBold, DefinitionListSyntax)
+	}
+}
+
+// definitionListRules converts <dl>, <dt>, and <dd> elements in the given style
+func definitionListRules(style string) []md.Rule {
+	return []md.Rule{
+		{
+			Filter: []string{"dl"},
+			Replacement: func(content string, _ *goquery.Selection, _ *md.Options) *string {
+				return md.String("\n\n" + strings.Trim(content, "\n") + "\n\n")
+			},
+		},
+		{
+			Filter: []string{"dt"},
+			Replacement: func(content string, _ *goquery.Selection, _ *md.Options) *string {
+				term := strings.Join(strings.Fields(content), " ")
+				if term == "" {
+					return md.String("")
+				}
+				if style == DefinitionListSyntax {
+					return md.String("\n\n" + term + "\n")
+				}
+				return md.String("\n\n**" + term + "**\n\n")
+			},
+		},
+		{
+			Filter: []string{"dd"},
+			Replacement: func(content string, _ *goquery.Selection, _ *md.Options) *string {
+				definition := strings.TrimSpace(content)
+				if definition == "" {
+					return md.String("")
+				}
+				if style == DefinitionListSyntax {
+					// Continuation lines are indented so that they stay part of the definition
+					return md.String(": " + strings.ReplaceAll(definition, "\n", "\n    ") + "\n")
+				}
+				return md.String(definition + "\n\n")
+			},
+		},
+	}
+}
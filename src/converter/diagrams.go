@@ -0,0 +1,85 @@
+package converter
+
+import (
+	"html"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Diagram languages written as the info string of fenced code blocks
+const (
+	diagramMermaid  = "mermaid"
+	diagramPlantUML = "plantuml"
+)
+
+// diagramSelector matches diagram sources and the elements rendered from them
+const diagramSelector = "[data-diagram-source], pre.mermaid, div.mermaid, pre.plantuml, div.plantuml"
+
+// preserveDiagrams replaces Mermaid and PlantUML diagrams with fenced code blocks of their source,
+// so that they stay editable. Diagrams already rendered to SVG without a source attribute are left as they are.
+func preserveDiagrams(root *goquery.Selection) {
+	root.Find(diagramSelector).Each(func(_ int, diagram *goquery.Selection) {
+		source, ok := diagram.Attr("data-diagram-source")
+		if !ok {
+			if diagram.Find("svg").Length() > 0 {
+				return
+			}
+			source = diagram.Text()
+		}
+
+		source = dedent(source)
+		if source == "" {
+			return
+		}
+
+		language := diagramLanguage(diagram, source)
+		diagram.ReplaceWithHtml(`<pre><code class="language-` + language + `">` + html.EscapeString(source) + `</code></pre>`)
+	})
+}
+
+// diagramLanguage returns the diagram language declared by the element, its class, or its source
+func diagramLanguage(diagram *goquery.Selection, source string) string {
+	for _, attr := range []string{"data-diagram-type", "data-diagram-language", "data-lang"} {
+		if language := strings.ToLower(strings.TrimSpace(diagram.AttrOr(attr, ""))); language != "" {
+			return language
+		}
+	}
+
+	if diagram.HasClass(diagramPlantUML) || strings.HasPrefix(source, "@start") {
+		return diagramPlantUML
+	}
+	return diagramMermaid
+}
+
+// dedent removes blank leading and trailing lines and the indentation shared by all lines
+func dedent(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		width := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || width < indent {
+			indent = width
+		}
+	}
+
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		} else {
+			lines[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,325 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Markdown dialects selecting the syntax extensions of the output
+const (
+	// DialectGFM writes GitHub Flavored Markdown: pipe tables, task lists, strikethrough, and footnotes
+	DialectGFM = "gfm"
+	// DialectCommonMark writes plain CommonMark: tables and strikethrough stay HTML and task list boxes become text
+	DialectCommonMark = "commonmark"
+	// DialectMkDocs writes the Python-Markdown of MkDocs: the GFM extensions with the four-space indentation
+	// of nested list blocks that Python-Markdown requires
+	DialectMkDocs = "mkdocs"
+)
+
+// mkdocsListIndent is the width Python-Markdown requires of the indentation of nested list blocks
+const mkdocsListIndent = 4
+
+// listPrefixAttr is the attribute in which html-to-markdown stores the marker of each list item before conversion,
+// from which it computes the indentation of nested blocks
+const listPrefixAttr = "data-converter-list-prefix"
+
+// Table syntaxes of the tables converted to Markdown
+const (
+	// TableSyntaxPipe writes GFM pipe tables, with one line per row
+	TableSyntaxPipe = "pipe"
+	// TableSyntaxGrid writes Pandoc grid tables, whose cells can hold several lines, lists, and code blocks
+	TableSyntaxGrid = "grid"
+)
+
+// Line break styles of <br> elements outside tables
+const (
+	// LineBreakParagraph starts a new paragraph at each line break
+	LineBreakParagraph = "paragraph"
+	// LineBreakSpaces ends the line with two spaces, the hard line break of CommonMark
+	LineBreakSpaces = "spaces"
+	// LineBreakBackslash ends the line with a backslash, the hard line break of CommonMark and Pandoc
+	// that is not lost when trailing spaces are trimmed
+	LineBreakBackslash = "backslash"
+)
+
+// minGridColumnWidth is the minimum width of the columns of grid tables
+const minGridColumnWidth = 3
+
+// spaceBreakMarker stands for the two trailing spaces of a line break until the converter has trimmed
+// the trailing spaces of all lines
+const spaceBreakMarker = "\uE000"
+
+// cellNewlines matches the line breaks of the content of a pipe table cell
+var cellNewlines = regexp.MustCompile(`\s*(\r?\n)+\s*`)
+
+// ValidateDialect checks that a Markdown dialect is known
+func ValidateDialect(dialect string) error {
+	switch dialect {
+	case "", DialectGFM, DialectCommonMark, DialectMkDocs:
+		return nil
+	default:
+		return fmt.Errorf("unknown dialect %q (expected %s, %s, or %s)", dialect, DialectGFM, DialectCommonMark, DialectMkDocs)
+	}
+}
+
+// useDialect enables the plugins and rules of a dialect
+func useDialect(converter *md.Converter, opts Options) {
+	switch opts.Dialect {
+	case DialectCommonMark:
+		converter.AddRules(commonMarkRules(opts.NoRawHTML)...)
+	case DialectMkDocs:
+		converter.Use(plugin.Table(), plugin.TaskListItems(), plugin.Strikethrough("~~"))
+		converter.AddRules(footnoteRules()...)
+		converter.Before(convertFootnotes, indentListsForMkDocs)
+	default:
+		converter.Use(plugin.GitHubFlavored())
+		converter.AddRules(footnoteRules()...)
+		converter.Before(convertFootnotes)
+	}
+}
+
+// commonMarkRules keep the elements of GFM extensions as HTML, or as lists and text when raw HTML is not allowed
+func commonMarkRules(noRawHTML bool) []md.Rule {
+	return []md.Rule{
+		{
+			Filter: []string{"table"},
+			Replacement: func(_ string, selection *goquery.Selection, _ *md.Options) *string {
+				if selection.ParentsFiltered("table").Length() > 0 {
+					return nil
+				}
+				if noRawHTML {
+					return md.String("\n\n" + tableList(tableGrid(selection)) + "\n\n")
+				}
+				return md.String("\n\n" + tableHTML(selection) + "\n\n")
+			},
+		},
+		{
+			Filter: []string{"del", "s", "strike"},
+			Replacement: func(content string, selection *goquery.Selection, _ *md.Options) *string {
+				if !noRawHTML {
+					content = "<del>" + strings.TrimSpace(content) + "</del>"
+				}
+				return md.String(md.AddSpaceIfNessesary(selection, content))
+			},
+		},
+		{
+			Filter: []string{"input"},
+			Replacement: func(_ string, selection *goquery.Selection, _ *md.Options) *string {
+				if !selection.Parent().Is("li") || selection.AttrOr("type", "") != "checkbox" {
+					return nil
+				}
+				if _, ok := selection.Attr("checked"); ok {
+					return md.String("\\[x\\] ")
+				}
+				return md.String("\\[ \\] ")
+			},
+		},
+	}
+}
+
+// indentListsForMkDocs pads the markers of list items to the indentation Python-Markdown requires,
+// so that paragraphs and lists nested in an item stay inside it
+func indentListsForMkDocs(root *goquery.Selection) {
+	root.Find("li").Each(func(_ int, item *goquery.Selection) {
+		prefix := item.AttrOr(listPrefixAttr, "")
+		if prefix == "" {
+			return
+		}
+		if width := utf8.RuneCountInString(prefix); width < mkdocsListIndent {
+			item.SetAttr(listPrefixAttr, prefix+strings.Repeat(" ", mkdocsListIndent-width))
+		}
+	})
+}
+
+// ValidateTableSyntax checks that a table syntax is known
+func ValidateTableSyntax(syntax string) error {
+	switch syntax {
+	case "", TableSyntaxPipe, TableSyntaxGrid:
+		return nil
+	default:
+		return fmt.Errorf("unknown table syntax %q (expected %s or %s)", syntax, TableSyntaxPipe, TableSyntaxGrid)
+	}
+}
+
+// ValidateLineBreakStyle checks that a line break style is known
+func ValidateLineBreakStyle(style string) error {
+	switch style {
+	case "", LineBreakParagraph, LineBreakSpaces, LineBreakBackslash:
+		return nil
+	default:
+		return fmt.Errorf("unknown line break style %q (expected %s, %s, or %s)", style, LineBreakParagraph, LineBreakSpaces, LineBreakBackslash)
+	}
+}
+
+// withoutRawHTML replaces the table strategy and heading anchor style writing HTML with their Markdown equivalents
+func withoutRawHTML(opts Options) Options {
+	if opts.Tables == TableHTML {
+		opts.Tables = TableGFM
+	}
+	if opts.HeadingAnchors == HeadingAnchorHTML {
+		opts.HeadingAnchors = HeadingAnchorAttribute
+	}
+	return opts
+}
+
+// lineBreakRules write <br> elements outside tables in the given style
+func lineBreakRules(style string) []md.Rule {
+	var lineBreak string
+	switch style {
+	case LineBreakSpaces:
+		lineBreak = spaceBreakMarker + "\n"
+	case LineBreakBackslash:
+		lineBreak = "\\\n"
+	default:
+		return nil
+	}
+
+	return []md.Rule{
+		{
+			Filter: []string{"br"},
+			Replacement: func(_ string, selection *goquery.Selection, _ *md.Options) *string {
+				if selection.ParentsFiltered("td, th").Length() > 0 {
+					return nil
+				}
+				return md.String(lineBreak)
+			},
+		},
+	}
+}
+
+// restoreSpaceBreaks turns the markers of space line breaks back into two trailing spaces
+func restoreSpaceBreaks(markdown string) string {
+	return strings.ReplaceAll(markdown, spaceBreakMarker, "  ")
+}
+
+// pipeCellRules join the lines of pipe table cells with spaces instead of <br> elements
+func pipeCellRules() []md.Rule {
+	return []md.Rule{
+		{
+			Filter: []string{"th", "td"},
+			Replacement: func(content string, selection *goquery.Selection, _ *md.Options) *string {
+				if selection.Find("table").Length() > 0 {
+					return nil
+				}
+				content = cellNewlines.ReplaceAllString(strings.TrimSpace(content), " ")
+
+				prefix := " "
+				if selection.Prev().Length() == 0 {
+					prefix = "| "
+				}
+				return md.String(prefix + content + " |")
+			},
+		},
+	}
+}
+
+// gridTableRules write the tables left to the table plugin as grid tables, converting the content of each
+// cell with cells. Tables nested in other tables are left to the table plugin.
+func gridTableRules(strategy string, cells *md.Converter) []md.Rule {
+	return []md.Rule{
+		{
+			Filter: []string{"table"},
+			Replacement: func(_ string, selection *goquery.Selection, _ *md.Options) *string {
+				if selection.Find("table").Length() > 0 || selection.ParentsFiltered("table").Length() > 0 {
+					return nil
+				}
+				if isComplexTable(selection) && strategy != "" && strategy != TableGFM {
+					return nil
+				}
+
+				grid := tableGridFunc(selection, func(cell *goquery.Selection) string {
+					return strings.TrimSpace(cells.Convert(cell))
+				})
+				header := tableRows(selection).First().ChildrenFiltered("th").Length() > 0 ||
+					tableRows(selection).First().ParentsFiltered("thead").Length() > 0
+				return md.String("\n\n" + gridTable(grid, header) + "\n\n")
+			},
+		},
+	}
+}
+
+// newCellConverter returns the converter of the content of grid table cells, where line breaks are backslashes
+func newCellConverter(opts Options) *md.Converter {
+	cells := md.NewConverter(opts.Domain, true, nil)
+	cells.Use(plugin.GitHubFlavored())
+	cells.AddRules(md.Rule{
+		Filter: []string{"br"},
+		Replacement: func(string, *goquery.Selection, *md.Options) *string {
+			return md.String("\\\n")
+		},
+	})
+	return cells
+}
+
+// gridTable writes the rows of a table grid as a Pandoc grid table, separating the first row with = when it is a header
+func gridTable(grid [][]string, header bool) string {
+	columns := 0
+	for _, row := range grid {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	widths := make([]int, columns)
+	for i := range widths {
+		widths[i] = minGridColumnWidth
+	}
+	cellLines := make([][][]string, len(grid))
+	for r, row := range grid {
+		cellLines[r] = make([][]string, columns)
+		for c := range columns {
+			text := ""
+			if c < len(row) {
+				text = row[c]
+			}
+			lines := strings.Split(text, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight(line, " ")
+				widths[c] = max(widths[c], utf8.RuneCountInString(lines[i]))
+			}
+			cellLines[r][c] = lines
+		}
+	}
+
+	separator := func(fill string) string {
+		var builder strings.Builder
+		builder.WriteString("+")
+		for _, width := range widths {
+			builder.WriteString(strings.Repeat(fill, width+2) + "+")
+		}
+		return builder.String()
+	}
+
+	var builder strings.Builder
+	builder.WriteString(separator("-") + "\n")
+	for r, row := range cellLines {
+		height := 1
+		for _, lines := range row {
+			height = max(height, len(lines))
+		}
+		for i := range height {
+			builder.WriteString("|")
+			for c, lines := range row {
+				line := ""
+				if i < len(lines) {
+					line = lines[i]
+				}
+				builder.WriteString(" " + line + strings.Repeat(" ", widths[c]-utf8.RuneCountInString(line)) + " |")
+			}
+			builder.WriteString("\n")
+		}
+		if r == 0 && header && len(cellLines) > 1 {
+			builder.WriteString(separator("=") + "\n")
+		} else {
+			builder.WriteString(separator("-") + "\n")
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
@@ -0,0 +1,152 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		html     string
+		expected string
+	}{
+		{
+			name:     "line breaks start paragraphs by default",
+			html:     `<p>Line one<br>Line two</p>`,
+			expected: "Line one\n\nLine two",
+		},
+		{
+			name:     "backslash line breaks",
+			opts:     Options{LineBreaks: LineBreakBackslash},
+			html:     `<p>Line one<br>Line two</p>`,
+			expected: "Line one\\\nLine two",
+		},
+		{
+			name:     "space line breaks",
+			opts:     Options{LineBreaks: LineBreakSpaces},
+			html:     `<p>Line one<br>Line two</p>`,
+			expected: "Line one  \nLine two",
+		},
+		{
+			name: "grid table with header and multi-line cells",
+			opts: Options{TableSyntax: TableSyntaxGrid},
+			html: `<table><thead><tr><th>Name</th><th>Notes</th></tr></thead>` +
+				`<tr><td><a href="https://example.com/go">Go</a></td><td>Fast<br>Typed</td></tr></table>`,
+			expected: "+------------------------------+-------+\n" +
+				"| Name                         | Notes |\n" +
+				"+==============================+=======+\n" +
+				"| [Go](https://example.com/go) | Fast\\ |\n" +
+				"|                              | Typed |\n" +
+				"+------------------------------+-------+",
+		},
+		{
+			name:     "grid table repeats merged cells with the gfm strategy",
+			opts:     Options{TableSyntax: TableSyntaxGrid, Tables: TableGFM},
+			html:     `<table><tr><td rowspan="2">A</td><td>B</td></tr><tr><td>C</td></tr></table>`,
+			expected: "+-----+-----+\n| A   | B   |\n+-----+-----+\n| A   | C   |\n+-----+-----+",
+		},
+		{
+			name:     "grid syntax leaves complex tables to the html strategy",
+			opts:     Options{TableSyntax: TableSyntaxGrid, Tables: TableHTML},
+			html:     `<table><tr><td colspan="2">A</td></tr><tr><td>B</td><td>C</td></tr></table>`,
+			expected: "<table><tbody><tr><td colspan=\"2\">A</td></tr><tr><td>B</td><td>C</td></tr></tbody></table>",
+		},
+		{
+			name:     "no raw html joins pipe table cell lines",
+			opts:     Options{NoRawHTML: true},
+			html:     `<table><tr><th>A</th></tr><tr><td>One<br>Two</td></tr></table>`,
+			expected: "| A |\n| --- |\n| One Two |",
+		},
+		{
+			name:     "no raw html writes heading anchors as attributes",
+			opts:     Options{NoRawHTML: true, HeadingAnchors: HeadingAnchorHTML},
+			html:     `<h2 id="setup">Install</h2>`,
+			expected: "## Install {#setup}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConverter(tt.opts)
+			if err != nil {
+				t.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+			got, err := c.Convert(tt.html)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Convert() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateDialect(t *testing.T) {
+	if err := ValidateTableSyntax("html"); err == nil {
+		t.Error("ValidateTableSyntax() expected an error for an unknown syntax")
+	}
+	if err := ValidateLineBreakStyle("br"); err == nil {
+		t.Error("ValidateLineBreakStyle() expected an error for an unknown style")
+	}
+	if _, err := NewConverter(Options{TableSyntax: "rst"}); err == nil {
+		t.Error("NewConverter() expected an error for an unknown table syntax")
+	}
+}
+
+func TestConvertDialects(t *testing.T) {
+	const page = `<p>Old <del>price</del> now</p>` +
+		`<ul><li><input type="checkbox" checked> Done<ul><li>Nested</li></ul></li></ul>` +
+		`<table><tr><th>A</th></tr><tr><td>1</td></tr></table>`
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{
+			name: "gfm",
+			expected: "Old ~~price~~ now\n\n- [x] Done\n  - Nested\n\n" +
+				"| A |\n| --- |\n| 1 |",
+		},
+		{
+			name: "commonmark",
+			opts: Options{Dialect: DialectCommonMark},
+			expected: "Old <del>price</del> now\n\n- \\[x\\] Done\n  - Nested\n\n" +
+				"<table><tbody><tr><th>A</th></tr><tr><td>1</td></tr></tbody></table>",
+		},
+		{
+			name: "commonmark without raw html",
+			opts: Options{Dialect: DialectCommonMark, NoRawHTML: true},
+			expected: "Old price now\n\n- \\[x\\] Done\n  - Nested\n\n" +
+				"- **1**",
+		},
+		{
+			name: "mkdocs",
+			opts: Options{Dialect: DialectMkDocs},
+			expected: "Old ~~price~~ now\n\n-   [x] Done\n    -   Nested\n\n" +
+				"| A |\n| --- |\n| 1 |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConverter(tt.opts)
+			if err != nil {
+				t.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+			got, err := c.Convert(page)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Convert() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := NewConverter(Options{Dialect: "markdown"}); err == nil {
+		t.Error("NewConverter() expected an error for an unknown dialect")
+	}
+}
@@ -0,0 +1,121 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxFilenameLength keeps file names below the 255 byte limit of common file systems,
+	// leaving room for suffixes added by other tools
+	DefaultMaxFilenameLength = 200
+	// filenameHashLength is the number of hex characters of the hash appended to truncated names
+	filenameHashLength = 8
+	// MinFilenameLength is the smallest limit that leaves room for the hash suffix and an extension
+	MinFilenameLength = 32
+)
+
+// LimitFilename shortens each segment of a slash-separated path to at most maxLength bytes.
+// Truncated segments keep their extension and end with a hash of the full segment,
+// so the same long name always maps to the same short one. A maxLength of 0 disables the limit.
+func LimitFilename(filename string, maxLength int) string {
+	if maxLength <= 0 {
+		return filename
+	}
+
+	segments := strings.Split(filename, "/")
+	for i, segment := range segments {
+		segments[i] = limitSegment(segment, maxLength)
+	}
+	return strings.Join(segments, "/")
+}
+
+// limitSegment shortens a single path segment
+func limitSegment(segment string, maxLength int) string {
+	if len(segment) <= maxLength {
+		return segment
+	}
+
+	ext := path.Ext(segment)
+	if len(ext) > maxLength/2 {
+		ext = ""
+	}
+
+	suffix := hashSuffix(segment)
+
+	stem := strings.TrimSuffix(segment, ext)
+	keep := maxLength - len(suffix) - len(ext)
+	if keep < 0 {
+		keep = 0
+	}
+
+	// Cut at a rune boundary so multi-byte characters are not split
+	for keep > 0 && !utf8.RuneStart(stem[keep]) {
+		keep--
+	}
+
+	return strings.TrimRight(stem[:keep], "-") + suffix + ext
+}
+
+// windowsReservedNames are the device names Windows does not allow as file names, whatever the extension
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM0": true, "COM1": true, "COM2": true, "COM3": true, "COM4": true,
+	"COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT0": true, "LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true,
+	"LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	"COM¹": true, "COM²": true, "COM³": true, "LPT¹": true, "LPT²": true, "LPT³": true,
+}
+
+// PortableFilename makes each segment of a slash-separated path usable on Windows too.
+// Trailing dots and spaces, which Windows drops, are removed, and device names such as CON or NUL,
+// which cannot be created even with an extension, get an underscore appended: con.md becomes con_.md.
+func PortableFilename(filename string) string {
+	segments := strings.Split(filename, "/")
+	for i, segment := range segments {
+		segments[i] = portableSegment(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// portableSegment makes a single path segment usable on Windows
+func portableSegment(segment string) string {
+	if segment == "" || segment == "." || segment == ".." {
+		return segment
+	}
+
+	segment = strings.TrimRight(segment, ". ")
+	if segment == "" {
+		return "page"
+	}
+
+	// Windows ignores the extension and the spaces before it when matching device names
+	stem, _, _ := strings.Cut(segment, ".")
+	stem = strings.TrimRight(stem, " ")
+	if windowsReservedNames[strings.ToUpper(stem)] {
+		return stem + "_" + segment[len(stem):]
+	}
+	return segment
+}
+
+// FoldFilename returns the key under which file names clash on case-insensitive file systems,
+// such as the defaults of Windows and macOS
+func FoldFilename(filename string) string {
+	return strings.ToLower(filename)
+}
+
+// DisambiguateFilename adds a hash of key, such as the page URL, before the extension of a file name.
+// It gives URLs that map to the same file name distinct, stable names.
+func DisambiguateFilename(filename, key string) string {
+	ext := path.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + hashSuffix(key) + ext
+}
+
+// hashSuffix returns a dash and the first hex characters of the SHA-256 hash of value
+func hashSuffix(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "-" + hex.EncodeToString(sum[:])[:filenameHashLength]
+}
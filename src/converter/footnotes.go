@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// footnoteListSelector matches the footnote lists written by Pandoc, GitHub, Python-Markdown, kramdown, and DPub ARIA markup
+const footnoteListSelector = `section.footnotes, div.footnotes, div.footnote, section[data-footnotes], [role="doc-endnotes"]`
+
+// Elements standing for footnote references and definitions between the before hook and the rules
+const (
+	footnoteRefElement = "crawldown-footnote-ref"
+	footnoteDefElement = "crawldown-footnote-def"
+)
+
+// convertFootnotes replaces the references to the items of footnote lists and the lists themselves with elements
+// written as [^n] references and definitions, numbered in the order of the lists. Links back from the definitions
+// to the references are dropped, and items without references are kept as definitions.
+func convertFootnotes(root *goquery.Selection) {
+	label := 0
+	root.Find(footnoteListSelector).Each(func(_ int, list *goquery.Selection) {
+		if list.ParentsFiltered(footnoteListSelector).Length() > 0 {
+			return
+		}
+		items := list.Find("li[id]")
+		if items.Length() == 0 {
+			return
+		}
+
+		var definitions strings.Builder
+		items.Each(func(_ int, item *goquery.Selection) {
+			label++
+			n := strconv.Itoa(label)
+
+			// Back links are found by their target, so they go before the references they point at
+			item.Find(`a[href^="#"]`).Each(func(_ int, link *goquery.Selection) {
+				if isFootnoteBackref(root, link) {
+					link.Remove()
+				}
+			})
+
+			target := "#" + item.AttrOr("id", "")
+			root.Find("a[href]").Each(func(_ int, ref *goquery.Selection) {
+				if ref.AttrOr("href", "") != target || ref.Closest(footnoteListSelector).Length() > 0 || !isFootnoteRef(ref) {
+					return
+				}
+				if sup := ref.Parent(); sup.Is("sup") && strings.TrimSpace(sup.Text()) == strings.TrimSpace(ref.Text()) {
+					ref = sup
+				}
+				ref.ReplaceWithHtml("<" + footnoteRefElement + ` data-label="` + n + `"></` + footnoteRefElement + ">")
+			})
+
+			content, err := item.Html()
+			if err != nil {
+				return
+			}
+			definitions.WriteString("<" + footnoteDefElement + ` data-label="` + n + `">` + content + "</" + footnoteDefElement + ">")
+		})
+		list.ReplaceWithHtml("<div>" + definitions.String() + "</div>")
+	})
+}
+
+// isFootnoteRef reports whether a link is marked up as a footnote reference rather than a plain in-page link
+func isFootnoteRef(link *goquery.Selection) bool {
+	return link.AttrOr("role", "") == "doc-noteref" || link.HasClass("footnote-ref") || link.Is("[data-footnote-ref]") ||
+		link.Parent().Is("sup") || link.Find("sup").Length() > 0
+}
+
+// isFootnoteBackref reports whether a link of a footnote definition points back at an element outside the footnote lists
+func isFootnoteBackref(root, link *goquery.Selection) bool {
+	if link.AttrOr("role", "") == "doc-backlink" || link.HasClass("footnote-backref") || link.HasClass("footnote-back") || link.HasClass("reversefootnote") {
+		return true
+	}
+
+	id := strings.TrimPrefix(link.AttrOr("href", ""), "#")
+	backref := false
+	root.Find("[id]").EachWithBreak(func(_ int, element *goquery.Selection) bool {
+		if element.AttrOr("id", "") != id {
+			return true
+		}
+		backref = element.Closest(footnoteListSelector).Length() == 0
+		return false
+	})
+	return backref
+}
+
+// footnoteRules write the elements left by convertFootnotes as footnote references and definitions,
+// indenting the following lines of definitions to keep them in the footnote
+func footnoteRules() []md.Rule {
+	return []md.Rule{
+		{
+			Filter: []string{footnoteRefElement},
+			Replacement: func(_ string, selection *goquery.Selection, _ *md.Options) *string {
+				return md.String("[^" + selection.AttrOr("data-label", "") + "]")
+			},
+		},
+		{
+			Filter: []string{footnoteDefElement},
+			Replacement: func(content string, selection *goquery.Selection, _ *md.Options) *string {
+				lines := strings.Split(strings.TrimSpace(content), "\n")
+				for i := 1; i < len(lines); i++ {
+					if lines[i] != "" {
+						lines[i] = "    " + lines[i]
+					}
+				}
+				return md.String("\n\n[^" + selection.AttrOr("data-label", "") + "]: " + strings.Join(lines, "\n") + "\n\n")
+			},
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestConvertFootnotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  string
+		html     string
+		expected string
+	}{
+		{
+			name:    "python-markdown",
+			dialect: DialectMkDocs,
+			html: `<p>Text<sup id="fnref:1"><a class="footnote-ref" href="#fn:1">1</a></sup> and <a href="#setup">setup</a>.</p>` +
+				`<div class="footnote"><hr><ol><li id="fn:1"><p>A <em>note</em>.&nbsp;<a class="footnote-backref" href="#fnref:1">&#8617;</a></p>` +
+				`<p>More.</p></li></ol></div>`,
+			expected: "Text[^1] and [setup](#setup).\n\n[^1]: A _note_.\n\n    More.",
+		},
+		{
+			name: "pandoc",
+			html: `<p>One<a href="#fn1" class="footnote-ref" id="fnref1" role="doc-noteref"><sup>1</sup></a>` +
+				` two<a href="#fn2" class="footnote-ref" id="fnref2" role="doc-noteref"><sup>2</sup></a></p>` +
+				`<section class="footnotes" role="doc-endnotes"><hr><ol>` +
+				`<li id="fn1"><p>First<a href="#fnref1" class="footnote-back" role="doc-backlink">↩︎</a></p></li>` +
+				`<li id="fn2"><p>Second</p></li></ol></section>`,
+			expected: "One[^1] two[^2]\n\n[^1]: First\n\n[^2]: Second",
+		},
+		{
+			name: "github back links found by target",
+			html: `<p>Claim<sup><a href="#user-content-fn-1" id="user-content-fnref-1" data-footnote-ref>1</a></sup></p>` +
+				`<section data-footnotes class="footnotes"><ol><li id="user-content-fn-1"><p>Source <a href="#user-content-fnref-1">↩</a></p></li></ol></section>`,
+			expected: "Claim[^1]\n\n[^1]: Source",
+		},
+		{
+			name:    "commonmark keeps footnotes as links",
+			dialect: DialectCommonMark,
+			html: `<p>Text<sup><a href="#fn1">1</a></sup></p>` +
+				`<section class="footnotes"><ol><li id="fn1"><p>Note</p></li></ol></section>`,
+			expected: "Text[1](#fn1)\n\n1. Note",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConverter(Options{Dialect: tt.dialect})
+			if err != nil {
+				t.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+			got, err := c.Convert(tt.html)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Convert() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Heading anchor styles, used to keep the id of headings as link targets
+const (
+	// HeadingAnchorNone drops heading ids, leaving anchors to the slugs generated by Markdown renderers
+	HeadingAnchorNone = "none"
+	// HeadingAnchorHTML writes an empty <a id="..."></a> element before the heading
+	HeadingAnchorHTML = "html"
+	// HeadingAnchorAttribute appends a {#id} attribute to the heading (Pandoc, kramdown, Hugo, and MkDocs syntax)
+	HeadingAnchorAttribute = "attribute"
+)
+
+// attributeID matches ids that can be written in the {#id} attribute syntax
+var attributeID = regexp.MustCompile(`^[A-Za-z][\w:.-]*$`)
+
+// ValidateHeadingAnchorStyle checks that a heading anchor style is known
+func ValidateHeadingAnchorStyle(style string) error {
+	switch style {
+	case "", HeadingAnchorNone, HeadingAnchorHTML, HeadingAnchorAttribute:
+		return nil
+	default:
+		return fmt.Errorf("unknown heading anchor style %q (expected %s, %s, or %s)", style, HeadingAnchorHTML, HeadingAnchorAttribute, HeadingAnchorNone)
+	}
+}
+
+// headingAnchorRules write the id of headings as explicit anchors in the given style.
+// Headings whose id is the slug a Markdown renderer would generate from their text are left to the default rule.
+func headingAnchorRules(style string) []md.Rule {
+	if style == "" || style == HeadingAnchorNone {
+		return nil
+	}
+
+	return []md.Rule{
+		{
+			Filter: []string{"h1", "h2", "h3", "h4", "h5", "h6"},
+			Replacement: func(content string, selection *goquery.Selection, _ *md.Options) *string {
+				id := headingID(selection)
+				if id == "" || id == HeadingSlug(selection.Text()) || selection.ParentsFiltered("a").Length() > 0 {
+					return nil
+				}
+
+				content = strings.NewReplacer("\n", " ", "\r", " ", "#", `\#`).Replace(content)
+				content = strings.TrimSpace(content)
+				if content == "" {
+					return nil
+				}
+
+				level, err := strconv.Atoi(goquery.NodeName(selection)[1:])
+				if err != nil {
+					return nil
+				}
+				heading := strings.Repeat("#", level) + " " + content
+
+				if style == HeadingAnchorAttribute && attributeID.MatchString(id) {
+					return md.String("\n\n" + heading + " {#" + id + "}\n\n")
+				}
+				return md.String("\n\n" + `<a id="` + html.EscapeString(id) + `"></a>` + "\n\n" + heading + "\n\n")
+			},
+		},
+	}
+}
+
+// headingID returns the id of a heading, or of an empty anchor element inside it
+// as in <h2><a name="setup"></a>Setup</h2>
+func headingID(heading *goquery.Selection) string {
+	if id := strings.TrimSpace(heading.AttrOr("id", "")); id != "" {
+		return id
+	}
+
+	id := ""
+	heading.Find("a[id], a[name]").EachWithBreak(func(_ int, anchor *goquery.Selection) bool {
+		if strings.TrimSpace(anchor.Text()) != "" {
+			return true
+		}
+		id = strings.TrimSpace(anchor.AttrOr("id", anchor.AttrOr("name", "")))
+		return id == ""
+	})
+	return id
+}
+
+// HeadingSlug returns the anchor GitHub generates for a heading text:
+// lowercase, without punctuation, and with spaces replaced by hyphens
+func HeadingSlug(text string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsNumber(r), r == '-', r == '_':
+			builder.WriteRune(r)
+		case r == ' ':
+			builder.WriteRune('-')
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,96 @@
+package converter
+
+import "testing"
+
+func TestConvertHeadingAnchors(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    string
+		html     string
+		expected string
+	}{
+		{
+			name:     "html anchor for custom id",
+			style:    HeadingAnchorHTML,
+			html:     `<h2 id="install-steps">Installation</h2><p>Run it.</p>`,
+			expected: "<a id=\"install-steps\"></a>\n\n## Installation\n\nRun it.",
+		},
+		{
+			name:     "attribute syntax",
+			style:    HeadingAnchorAttribute,
+			html:     `<h3 id="install-steps">Installation</h3>`,
+			expected: "### Installation {#install-steps}",
+		},
+		{
+			name:     "attribute falls back to html for unsupported ids",
+			style:    HeadingAnchorAttribute,
+			html:     `<h2 id="2 steps">Steps</h2>`,
+			expected: "<a id=\"2 steps\"></a>\n\n## Steps",
+		},
+		{
+			name:     "id matching the generated slug is left alone",
+			style:    HeadingAnchorHTML,
+			html:     `<h2 id="getting-started">Getting Started!</h2>`,
+			expected: "## Getting Started!",
+		},
+		{
+			name:     "empty named anchor inside heading",
+			style:    HeadingAnchorHTML,
+			html:     `<h2><a name="cfg"></a>Configuration</h2>`,
+			expected: "<a id=\"cfg\"></a>\n\n## Configuration",
+		},
+		{
+			name:     "none drops ids",
+			style:    HeadingAnchorNone,
+			html:     `<h2 id="install-steps">Installation</h2>`,
+			expected: "## Installation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := NewConverter(Options{HeadingAnchors: tt.style})
+			if err != nil {
+				t.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+
+			result, err := conv.Convert(tt.html)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Convert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHeadingSlug(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Getting Started", want: "getting-started"},
+		{text: "What's new in v2.0?", want: "whats-new-in-v20"},
+		{text: "  snake_case and-dash ", want: "snake_case-and-dash"},
+		{text: "Über uns", want: "über-uns"},
+	}
+
+	for _, tt := range tests {
+		if got := HeadingSlug(tt.text); got != tt.want {
+			t.Errorf("HeadingSlug(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestValidateHeadingAnchorStyle(t *testing.T) {
+	for _, style := range []string{"", HeadingAnchorNone, HeadingAnchorHTML, HeadingAnchorAttribute} {
+		if err := ValidateHeadingAnchorStyle(style); err != nil {
+			t.Errorf("ValidateHeadingAnchorStyle(%q) unexpected error: %v", style, err)
+		}
+	}
+	if err := ValidateHeadingAnchorStyle("comment"); err == nil {
+		t.Error("ValidateHeadingAnchorStyle(\"comment\") expected an error")
+	}
+}
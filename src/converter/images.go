@@ -0,0 +1,232 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// AssetPath returns the content-addressed path of an asset under assetsDir, named after the hash of its data,
+// so identical assets referenced from many pages are stored once
+func AssetPath(assetsDir string, data []byte, extension string) string {
+	if assetsDir == "" {
+		assetsDir = DefaultAssetsDir
+	}
+	sum := sha256.Sum256(data)
+	return path.Join(assetsDir, hex.EncodeToString(sum[:8])+extension)
+}
+
+// AssetExtension returns the file extension of a downloaded asset from its content type,
+// falling back to the extension of its URL path
+func AssetExtension(contentType, assetURL string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "application/octet-stream" {
+		if extension := extensionForMimeType(mediaType); extension != ".bin" {
+			return extension
+		}
+	}
+
+	if parsedURL, err := url.Parse(assetURL); err == nil {
+		if extension := strings.ToLower(path.Ext(parsedURL.Path)); simpleSubtypeRule.MatchString(strings.TrimPrefix(extension, ".")) {
+			return extension
+		}
+	}
+	return ".bin"
+}
+
+// LocalizeImages replaces the sources of the images of a page with the local paths returned by localize,
+// which receives absolute image URLs. Images localize does not handle keep their source.
+func LocalizeImages(content string, pageURL string, localize func(imageURL string) (string, bool)) string {
+	if !strings.Contains(content, "<img") {
+		return content
+	}
+
+	return localizeElements(content, pageURL, "img", imageSource, func(img *goquery.Selection, local string) {
+		img.SetAttr("src", local)
+		// The lazy-loading attributes would otherwise take precedence over the local copy
+		for _, attr := range append(lazySourceAttributes, lazySrcsetAttributes...) {
+			img.RemoveAttr(attr)
+		}
+	}, localize)
+}
+
+// DefaultFilesDir is the directory, relative to the output directory, where downloaded linked files are stored
+const DefaultFilesDir = "files"
+
+// LocalizeFiles replaces links to files with one of the given extensions, such as "zip" or "csv",
+// with the local paths returned by localize, which receives absolute file URLs
+func LocalizeFiles(content string, pageURL string, extensions []string, localize func(fileURL string) (string, bool)) string {
+	if len(extensions) == 0 || !strings.Contains(content, "<a") {
+		return content
+	}
+
+	wanted := make(map[string]bool, len(extensions))
+	for _, extension := range extensions {
+		wanted["."+strings.ToLower(strings.TrimPrefix(extension, "."))] = true
+	}
+
+	source := func(link *goquery.Selection) string {
+		href := strings.TrimSpace(link.AttrOr("href", ""))
+		parsed, err := url.Parse(href)
+		if err != nil || !wanted[strings.ToLower(path.Ext(parsed.Path))] {
+			return ""
+		}
+		return href
+	}
+
+	return localizeElements(content, pageURL, "a[href]", source, func(link *goquery.Selection, local string) {
+		link.SetAttr("href", local)
+	}, localize)
+}
+
+// localizeElements calls localize with the absolute URL returned by source for each element matching selector,
+// and calls set with the local paths it returns. The content is returned unchanged when no element was localized.
+func localizeElements(content, pageURL, selector string, source func(*goquery.Selection) string,
+	set func(*goquery.Selection, string), localize func(string) (string, bool)) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return content
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+
+	changed := false
+	doc.Find(selector).Each(func(_ int, element *goquery.Selection) {
+		src := source(element)
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return
+		}
+
+		ref, err := url.Parse(src)
+		if err != nil {
+			return
+		}
+		resourceURL := base.ResolveReference(ref)
+		if resourceURL.Scheme != "http" && resourceURL.Scheme != "https" {
+			return
+		}
+		resourceURL.Fragment = ""
+
+		if local, ok := localize(resourceURL.String()); ok {
+			set(element, local)
+			changed = true
+		}
+	})
+
+	if !changed {
+		return content
+	}
+
+	out, err := doc.Find("body").Html()
+	if err != nil {
+		return content
+	}
+	return out
+}
+
+// lazySourceAttributes hold the real image URL on lazy-loaded images, in order of preference
+var lazySourceAttributes = []string{"data-src", "data-lazy-src", "data-original", "data-lazy"}
+
+// lazySrcsetAttributes hold candidate image URLs with width or density descriptors
+var lazySrcsetAttributes = []string{"data-srcset", "data-lazy-srcset", "srcset"}
+
+// placeholderImage matches the file names of blank images put in src by lazy loaders
+var placeholderImage = regexp.MustCompile(`(?i)(blank|spacer|placeholder|pixel|transparent|lazy)[^/]*\.(gif|png|svg)$`)
+
+// resolveImageSources sets the src of lazy-loaded images to their real URL
+func resolveImageSources(root *goquery.Selection) {
+	root.Find("img").Each(func(_ int, img *goquery.Selection) {
+		if src := imageSource(img); src != "" && src != img.AttrOr("src", "") {
+			img.SetAttr("src", src)
+		}
+	})
+}
+
+// imageSource returns the real URL of an image: a lazy-loading attribute, the best srcset candidate
+// when src is missing or a placeholder, or src
+func imageSource(img *goquery.Selection) string {
+	for _, attr := range lazySourceAttributes {
+		if value := strings.TrimSpace(img.AttrOr(attr, "")); value != "" && !strings.HasPrefix(value, "data:") {
+			return value
+		}
+	}
+
+	src := strings.TrimSpace(img.AttrOr("src", ""))
+	if !isPlaceholderImage(img, src) {
+		return src
+	}
+
+	for _, attr := range lazySrcsetAttributes {
+		if candidate := bestSrcsetCandidate(img.AttrOr(attr, "")); candidate != "" {
+			return candidate
+		}
+	}
+	if img.Parent().Is("picture") {
+		var candidate string
+		img.Parent().ChildrenFiltered("source").EachWithBreak(func(_ int, source *goquery.Selection) bool {
+			candidate = bestSrcsetCandidate(source.AttrOr("srcset", source.AttrOr("data-srcset", "")))
+			return candidate == ""
+		})
+		if candidate != "" {
+			return candidate
+		}
+	}
+	return src
+}
+
+// isPlaceholderImage reports whether the src of an image is missing or a lazy-loading placeholder
+func isPlaceholderImage(img *goquery.Selection, src string) bool {
+	if src == "" || src == "about:blank" || strings.HasPrefix(src, "data:") {
+		return true
+	}
+	if img.AttrOr("width", "") == "1" && img.AttrOr("height", "") == "1" {
+		return true
+	}
+	if parsed, err := url.Parse(src); err == nil {
+		return placeholderImage.MatchString(parsed.Path)
+	}
+	return false
+}
+
+// bestSrcsetCandidate returns the URL of the widest, or else densest, candidate of a srcset attribute
+func bestSrcsetCandidate(srcset string) string {
+	best, bestWidth, bestDensity := "", 0.0, 0.0
+	for _, candidate := range strings.Split(srcset, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "data:") {
+			continue
+		}
+
+		width, density := 0.0, 1.0
+		if len(fields) > 1 {
+			descriptor := fields[1]
+			value, err := strconv.ParseFloat(descriptor[:len(descriptor)-1], 64)
+			if err != nil {
+				continue
+			}
+			switch descriptor[len(descriptor)-1] {
+			case 'w':
+				width = value
+			case 'x':
+				density = value
+			default:
+				continue
+			}
+		}
+
+		// Width descriptors are preferred, as they describe the actual image size
+		if width > bestWidth || (bestWidth == 0 && width == 0 && density > bestDensity) {
+			best, bestWidth, bestDensity = fields[0], width, density
+		}
+	}
+	return best
+}
@@ -0,0 +1,179 @@
+package converter
+
+import (
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestLocalizeImages(t *testing.T) {
+	content := `<p><img src="/img/logo.png" alt="Logo"></p>` +
+		`<p><img src="https://cdn.example.net/photo.jpg#top" alt="Photo"></p>` +
+		`<p><img src="data:image/gif;base64,R0lGOD" alt="Pixel"></p>` +
+		`<p><img src="/img/missing.png" alt="Missing"></p>`
+
+	var requested []string
+	result := LocalizeImages(content, "https://example.com/docs/page", func(imageURL string) (string, bool) {
+		requested = append(requested, imageURL)
+		if strings.HasSuffix(imageURL, "missing.png") {
+			return "", false
+		}
+		return "assets/" + strings.TrimPrefix(imageURL[strings.LastIndex(imageURL, "/"):], "/"), true
+	})
+
+	wantRequested := []string{"https://example.com/img/logo.png", "https://cdn.example.net/photo.jpg", "https://example.com/img/missing.png"}
+	if strings.Join(requested, " ") != strings.Join(wantRequested, " ") {
+		t.Errorf("LocalizeImages() requested %v, want %v", requested, wantRequested)
+	}
+
+	for _, want := range []string{`src="assets/logo.png"`, `src="assets/photo.jpg"`, `src="data:image/gif;base64,R0lGOD"`, `src="/img/missing.png"`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("LocalizeImages() = %q, want it to contain %q", result, want)
+		}
+	}
+}
+
+func TestAssetExtension(t *testing.T) {
+	tests := []struct {
+		contentType string
+		url         string
+		want        string
+	}{
+		{contentType: "image/png", url: "https://example.com/logo", want: ".png"},
+		{contentType: "image/jpeg; charset=binary", url: "https://example.com/a.jpeg", want: ".jpg"},
+		{contentType: "application/octet-stream", url: "https://example.com/a.webp?v=2", want: ".webp"},
+		{contentType: "", url: "https://example.com/image", want: ".bin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType+" "+tt.url, func(t *testing.T) {
+			if got := AssetExtension(tt.contentType, tt.url); got != tt.want {
+				t.Errorf("AssetExtension() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssetPath(t *testing.T) {
+	first := AssetPath("assets", []byte("logo"), ".png")
+	second := AssetPath("", []byte("logo"), ".png")
+	other := AssetPath("assets", []byte("photo"), ".png")
+
+	if first != second {
+		t.Errorf("AssetPath() = %q and %q, want the same path for the same data", first, second)
+	}
+	if first == other {
+		t.Errorf("AssetPath() = %q for different data", other)
+	}
+	if !strings.HasPrefix(first, "assets/") || len(first) != len("assets/")+16+len(".png") {
+		t.Errorf("AssetPath() = %q, want assets/<16 hex digits>.png", first)
+	}
+}
+
+func TestConvertLazyImages(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "data-src replaces placeholder",
+			html:     `<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" data-src="/img/chart.png" alt="Chart">`,
+			expected: "![Chart](/img/chart.png)",
+		},
+		{
+			name:     "data-lazy-src",
+			html:     `<img src="/img/blank.gif" data-lazy-src="/img/chart.png" alt="Chart">`,
+			expected: "![Chart](/img/chart.png)",
+		},
+		{
+			name:     "widest srcset candidate for placeholder",
+			html:     `<img src="/img/placeholder.png" srcset="/img/chart-480.png 480w, /img/chart-1200.png 1200w, /img/chart-800.png 800w" alt="Chart">`,
+			expected: "![Chart](/img/chart-1200.png)",
+		},
+		{
+			name:     "densest srcset candidate without src",
+			html:     `<img data-srcset="/img/chart.png, /img/chart@2x.png 2x" alt="Chart">`,
+			expected: "![Chart](/img/chart@2x.png)",
+		},
+		{
+			name:     "picture source for one pixel image",
+			html:     `<picture><source srcset="/img/chart.webp 1x"><img src="/img/p.gif" width="1" height="1" alt="Chart"></picture>`,
+			expected: "![Chart](/img/chart.webp)",
+		},
+		{
+			name:     "real src is kept",
+			html:     `<img src="/img/chart.png" srcset="/img/chart-2x.png 2x" alt="Chart">`,
+			expected: "![Chart](/img/chart.png)",
+		},
+	}
+
+	conv, err := NewConverter(Options{})
+	if err != nil {
+		t.Fatalf("NewConverter() unexpected error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.Convert(tt.html)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Convert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocalizeLazyImages(t *testing.T) {
+	content := `<p><img src="/img/spacer.gif" data-src="/img/chart.png" alt="Chart"></p>`
+
+	var requested string
+	result := LocalizeImages(content, "https://example.com/docs/", func(imageURL string) (string, bool) {
+		requested = imageURL
+		return "assets/chart.png", true
+	})
+
+	if requested != "https://example.com/img/chart.png" {
+		t.Errorf("LocalizeImages() requested %q, want the lazy-loaded image", requested)
+	}
+	if strings.Contains(result, "data-src") {
+		t.Errorf("LocalizeImages() = %q, want the lazy-loading attributes removed", result)
+	}
+
+	conv, err := NewConverter(Options{})
+	if err != nil {
+		t.Fatalf("NewConverter() unexpected error: %v", err)
+	}
+	markdown, err := conv.Convert(result)
+	if err != nil {
+		t.Fatalf("Convert() unexpected error: %v", err)
+	}
+	if want := "![Chart](assets/chart.png)"; markdown != want {
+		t.Errorf("Convert() = %q, want %q", markdown, want)
+	}
+}
+
+func TestLocalizeFiles(t *testing.T) {
+	content := `<p><a href="/downloads/Report.ZIP">Report</a> <a href="data.csv?v=2">Data</a> ` +
+		`<a href="/guide">Guide</a> <a href="/slides.pdf">Slides</a></p>`
+
+	var requested []string
+	result := LocalizeFiles(content, "https://example.com/docs/", []string{"zip", ".csv"}, func(fileURL string) (string, bool) {
+		requested = append(requested, fileURL)
+		return "files/" + path.Base(strings.SplitN(fileURL, "?", 2)[0]), true
+	})
+
+	wantRequested := []string{"https://example.com/downloads/Report.ZIP", "https://example.com/docs/data.csv?v=2"}
+	if strings.Join(requested, " ") != strings.Join(wantRequested, " ") {
+		t.Errorf("LocalizeFiles() requested %v, want %v", requested, wantRequested)
+	}
+
+	for _, want := range []string{`href="files/Report.ZIP"`, `href="files/data.csv"`, `href="/guide"`, `href="/slides.pdf"`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("LocalizeFiles() = %q, want it to contain %q", result, want)
+		}
+	}
+}
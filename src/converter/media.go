@@ -0,0 +1,102 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Media policies for <video> and <audio> elements
+const (
+	// MediaLink writes a link to the media URL
+	MediaLink = "link"
+	// MediaDownload writes a link to a local copy of the media, see LocalizeMedia; it converts like MediaLink
+	MediaDownload = "download"
+	// MediaDrop removes media elements, including their fallback content
+	MediaDrop = "drop"
+)
+
+// ValidateMediaPolicy checks that a media policy is known
+func ValidateMediaPolicy(policy string) error {
+	switch policy {
+	case "", MediaLink, MediaDownload, MediaDrop:
+		return nil
+	default:
+		return fmt.Errorf("unknown media policy %q (expected %s, %s, or %s)", policy, MediaLink, MediaDownload, MediaDrop)
+	}
+}
+
+// mediaRules converts <video> and <audio> elements with the given policy.
+// Without a policy, media elements are left to the default rules, which only keep their fallback text.
+func mediaRules(policy string) []md.Rule {
+	if policy == "" {
+		return nil
+	}
+
+	return []md.Rule{
+		{
+			Filter: []string{"video", "audio"},
+			Replacement: func(_ string, selection *goquery.Selection, _ *md.Options) *string {
+				if policy == MediaDrop {
+					return md.String("")
+				}
+
+				src := mediaSource(selection)
+				if src == "" {
+					return md.String("")
+				}
+				return md.String("\n\n[" + mediaLabel(selection, src) + "](" + src + ")\n\n")
+			},
+		},
+	}
+}
+
+// mediaSource returns the URL of a media element: its src, or the src of its first <source>
+func mediaSource(media *goquery.Selection) string {
+	if src := strings.TrimSpace(media.AttrOr("src", "")); src != "" {
+		return src
+	}
+
+	src := ""
+	media.ChildrenFiltered("source[src]").EachWithBreak(func(_ int, source *goquery.Selection) bool {
+		src = strings.TrimSpace(source.AttrOr("src", ""))
+		return src == ""
+	})
+	return src
+}
+
+// mediaLabel returns the link text of a media element, "Video" or "Audio" followed by its title or file name
+func mediaLabel(media *goquery.Selection, src string) string {
+	kind := "Video"
+	if goquery.NodeName(media) == "audio" {
+		kind = "Audio"
+	}
+
+	title := strings.TrimSpace(media.AttrOr("title", media.AttrOr("aria-label", "")))
+	if title == "" {
+		if parsed, err := url.Parse(src); err == nil && path.Base(parsed.Path) != "/" && path.Base(parsed.Path) != "." {
+			title = path.Base(parsed.Path)
+		}
+	}
+	if title == "" {
+		return kind
+	}
+	return kind + ": " + strings.NewReplacer("[", `\[`, "]", `\]`).Replace(strings.Join(strings.Fields(title), " "))
+}
+
+// LocalizeMedia replaces the sources of <video> and <audio> elements with the local paths returned by localize,
+// which receives absolute media URLs. Media localize does not handle keep their source.
+func LocalizeMedia(content string, pageURL string, localize func(mediaURL string) (string, bool)) string {
+	if !strings.Contains(content, "<video") && !strings.Contains(content, "<audio") {
+		return content
+	}
+
+	return localizeElements(content, pageURL, "video, audio", mediaSource, func(media *goquery.Selection, local string) {
+		media.SetAttr("src", local)
+		media.ChildrenFiltered("source").Remove()
+	}, localize)
+}
@@ -0,0 +1,86 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertMedia(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		html     string
+		expected string
+	}{
+		{
+			name:     "video src",
+			policy:   MediaLink,
+			html:     `<p>Intro</p><video src="/media/demo.mp4" controls>Your browser does not support video.</video>`,
+			expected: "Intro\n\n[Video: demo.mp4](/media/demo.mp4)",
+		},
+		{
+			name:     "first source with title",
+			policy:   MediaLink,
+			html:     `<video title="Product [tour]"><source src="/media/tour.webm" type="video/webm"><source src="/media/tour.mp4"></video>`,
+			expected: `[Video: Product \[tour\]](/media/tour.webm)`,
+		},
+		{
+			name:     "audio",
+			policy:   MediaDownload,
+			html:     `<audio controls><source src="https://cdn.example.com/episode-1.mp3"></audio>`,
+			expected: "[Audio: episode-1.mp3](https://cdn.example.com/episode-1.mp3)",
+		},
+		{
+			name:     "drop",
+			policy:   MediaDrop,
+			html:     `<p>Intro</p><video src="/media/demo.mp4">Fallback text</video>`,
+			expected: "Intro",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := NewConverter(Options{Media: tt.policy})
+			if err != nil {
+				t.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+
+			result, err := conv.Convert(tt.html)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Convert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocalizeMedia(t *testing.T) {
+	content := `<video controls><source src="/media/tour.webm"><source src="/media/tour.mp4"></video>`
+
+	var requested string
+	result := LocalizeMedia(content, "https://example.com/docs/", func(mediaURL string) (string, bool) {
+		requested = mediaURL
+		return "files/tour.webm", true
+	})
+
+	if requested != "https://example.com/media/tour.webm" {
+		t.Errorf("LocalizeMedia() requested %q, want the first source", requested)
+	}
+	if !strings.Contains(result, `src="files/tour.webm"`) || strings.Contains(result, "<source") {
+		t.Errorf("LocalizeMedia() = %q, want the local source only", result)
+	}
+}
+
+func TestValidateMediaPolicy(t *testing.T) {
+	for _, policy := range []string{"", MediaLink, MediaDownload, MediaDrop} {
+		if err := ValidateMediaPolicy(policy); err != nil {
+			t.Errorf("ValidateMediaPolicy(%q) unexpected error: %v", policy, err)
+		}
+	}
+	if err := ValidateMediaPolicy("embed"); err == nil {
+		t.Error("ValidateMediaPolicy(\"embed\") expected an error")
+	}
+}
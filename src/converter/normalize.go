@@ -0,0 +1,133 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Normalization names accepted by ParseNormalize
+const (
+	NormalizeNBSP        = "nbsp"
+	NormalizeZeroWidth   = "zero-width"
+	NormalizePunctuation = "punctuation"
+	NormalizeTypography  = "typography"
+	NormalizeNone        = "none"
+)
+
+// DefaultNormalize lists the normalizations applied by default by the CLI
+var DefaultNormalize = []string{NormalizeNBSP, NormalizeZeroWidth, NormalizePunctuation}
+
+// NormalizeOptions selects the whitespace and character normalizations applied to the Markdown.
+// Code spans and fenced code blocks are never changed.
+type NormalizeOptions struct {
+	NBSP        bool // Replace non-breaking and narrow spaces with regular spaces, collapsing the runs they are part of
+	ZeroWidth   bool // Remove zero-width spaces, word joiners, byte order marks, and soft hyphens
+	Punctuation bool // Remove spaces left before punctuation by the conversion of inline elements
+	Typography  bool // Replace curly quotes, dashes, and ellipses with their ASCII forms
+}
+
+var (
+	nbspRunPattern          = regexp.MustCompile(`[ \x{00A0}\x{2007}\x{202F}]*[\x{00A0}\x{2007}\x{202F}][ \x{00A0}\x{2007}\x{202F}]*`)
+	spaceBeforePunctPattern = regexp.MustCompile(`([^\s|]) +([,.;:!?)])(\s|$)`)
+	inlineCodePattern       = regexp.MustCompile("`+[^`\n]*`+")
+	fencePattern            = regexp.MustCompile("^ {0,3}(```|~~~)")
+
+	// ZWJ and ZWNJ are kept, as they join emoji sequences and shape scripts such as Persian
+	zeroWidthReplacer = strings.NewReplacer("\u200b", "", "\u2060", "", "\ufeff", "", "\u00ad", "")
+
+	typographyReplacer = strings.NewReplacer(
+		"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u2032", "'",
+		"\u201c", `"`, "\u201d", `"`, "\u201e", `"`, "\u2033", `"`,
+		"\u2013", "-", "\u2014", "--", "\u2026", "...",
+	)
+)
+
+// ParseNormalize builds normalization options from a list of names; "none" disables all normalizations
+func ParseNormalize(names []string) (NormalizeOptions, error) {
+	var opts NormalizeOptions
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case NormalizeNBSP:
+			opts.NBSP = true
+		case NormalizeZeroWidth:
+			opts.ZeroWidth = true
+		case NormalizePunctuation:
+			opts.Punctuation = true
+		case NormalizeTypography:
+			opts.Typography = true
+		case NormalizeNone:
+			if len(names) > 1 {
+				return NormalizeOptions{}, fmt.Errorf("%s cannot be combined with other normalizations", NormalizeNone)
+			}
+		default:
+			return NormalizeOptions{}, fmt.Errorf("unknown normalization %q (expected %s, %s, %s, %s, or %s)",
+				name, NormalizeNBSP, NormalizeZeroWidth, NormalizePunctuation, NormalizeTypography, NormalizeNone)
+		}
+	}
+	return opts, nil
+}
+
+// enabled reports whether any normalization is selected
+func (n NormalizeOptions) enabled() bool {
+	return n.NBSP || n.ZeroWidth || n.Punctuation || n.Typography
+}
+
+// normalize applies the selected normalizations outside of code spans and fenced code blocks
+func (n NormalizeOptions) normalize(markdown string) string {
+	if !n.enabled() {
+		return markdown
+	}
+
+	lines := strings.Split(markdown, "\n")
+	fence := ""
+	for i, line := range lines {
+		if match := fencePattern.FindStringSubmatch(line); match != nil {
+			switch {
+			case fence == "":
+				fence = match[1]
+				continue
+			case fence == match[1]:
+				fence = ""
+				continue
+			}
+		}
+		if fence != "" {
+			continue
+		}
+
+		lines[i] = n.normalizeLine(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// normalizeLine applies the selected normalizations to the text between the code spans of a line
+func (n NormalizeOptions) normalizeLine(line string) string {
+	var builder strings.Builder
+	last := 0
+	for _, span := range inlineCodePattern.FindAllStringIndex(line, -1) {
+		builder.WriteString(n.normalizeText(line[last:span[0]]))
+		builder.WriteString(line[span[0]:span[1]])
+		last = span[1]
+	}
+	builder.WriteString(n.normalizeText(line[last:]))
+	return builder.String()
+}
+
+// normalizeText applies the selected normalizations to plain text
+func (n NormalizeOptions) normalizeText(text string) string {
+	if n.ZeroWidth {
+		text = zeroWidthReplacer.Replace(text)
+	}
+	if n.NBSP {
+		text = nbspRunPattern.ReplaceAllString(text, " ")
+	}
+	if n.Typography {
+		text = typographyReplacer.Replace(text)
+	}
+	if n.Punctuation {
+		text = spaceBeforePunctPattern.ReplaceAllString(text, "$1$2$3")
+	}
+	return text
+}
@@ -0,0 +1,121 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/urlmatch"
+)
+
+// Stages a Replacement is applied at
+const (
+	// StageHTML applies a replacement to the extracted HTML, before conversion
+	StageHTML = "html"
+	// StageMarkdown applies a replacement to the converted Markdown
+	StageMarkdown = "markdown"
+	// StageBoth applies a replacement before and after conversion
+	StageBoth = "both"
+)
+
+// Replacement is a regular expression find and replace rule for removing site-specific boilerplate
+type Replacement struct {
+	Find    string `json:"find"`              // Regular expression (RE2 syntax)
+	Replace string `json:"replace,omitempty"` // Replacement, where $1 or ${name} refer to submatches; empty removes the match
+	Stage   string `json:"stage,omitempty"`   // "markdown" (default), "html", or "both"
+	Pattern string `json:"pattern,omitempty"` // Glob of the page URLs the rule applies to, all pages when empty
+}
+
+// Validate checks that the replacement is well formed
+func (r Replacement) Validate() error {
+	if r.Find == "" {
+		return fmt.Errorf("replacement must set find")
+	}
+
+	if _, err := regexp.Compile(r.Find); err != nil {
+		return fmt.Errorf("invalid replacement %q: %w", r.Find, err)
+	}
+
+	switch r.Stage {
+	case "", StageHTML, StageMarkdown, StageBoth:
+	default:
+		return fmt.Errorf("invalid replacement stage %q (expected %s, %s, or %s)", r.Stage, StageMarkdown, StageHTML, StageBoth)
+	}
+
+	if r.Pattern != "" {
+		if _, err := urlmatch.Compile(r.Pattern); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// appliesTo reports whether the replacement runs at a stage
+func (r Replacement) appliesTo(stage string) bool {
+	if r.Stage == "" {
+		return stage == StageMarkdown
+	}
+	return r.Stage == stage || r.Stage == StageBoth
+}
+
+type compiledReplacement struct {
+	re      *regexp.Regexp
+	replace string
+	pattern *urlmatch.Pattern
+}
+
+// Replacer applies replacements in order, separately to the HTML and the Markdown of pages
+type Replacer struct {
+	html     []compiledReplacement
+	markdown []compiledReplacement
+}
+
+// NewReplacer compiles replacements
+func NewReplacer(replacements []Replacement) (*Replacer, error) {
+	replacer := &Replacer{}
+
+	for i, replacement := range replacements {
+		if err := replacement.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid replacement %d: %w", i, err)
+		}
+
+		compiled := compiledReplacement{re: regexp.MustCompile(replacement.Find), replace: replacement.Replace}
+		if replacement.Pattern != "" {
+			compiled.pattern = urlmatch.MustCompile(replacement.Pattern)
+		}
+		if replacement.appliesTo(StageHTML) {
+			replacer.html = append(replacer.html, compiled)
+		}
+		if replacement.appliesTo(StageMarkdown) {
+			replacer.markdown = append(replacer.markdown, compiled)
+		}
+	}
+
+	return replacer, nil
+}
+
+// HTML applies the html replacements matching a page to its extracted HTML
+func (r *Replacer) HTML(pageURL, content string) string {
+	return applyReplacements(r.html, pageURL, content)
+}
+
+// Markdown applies the markdown replacements matching a page to its converted Markdown.
+// Blank lines left by removed text are collapsed as in converted Markdown.
+func (r *Replacer) Markdown(pageURL, markdown string) string {
+	replaced := applyReplacements(r.markdown, pageURL, markdown)
+	if replaced == markdown {
+		return markdown
+	}
+	return strings.TrimSpace(extraNewlines.ReplaceAllString(replaced, "\n\n"))
+}
+
+func applyReplacements(replacements []compiledReplacement, pageURL, content string) string {
+	for _, replacement := range replacements {
+		if replacement.pattern != nil && !replacement.pattern.Match(pageURL) {
+			continue
+		}
+		content = replacement.re.ReplaceAllString(content, replacement.replace)
+	}
+	return content
+}
@@ -0,0 +1,62 @@
+package converter
+
+import "testing"
+
+func TestReplacementValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		replacement Replacement
+		wantErr     bool
+	}{
+		{name: "markdown by default", replacement: Replacement{Find: "Was this page helpful\\?"}},
+		{name: "html with pattern", replacement: Replacement{Find: "<aside>.*?</aside>", Stage: StageHTML, Pattern: "/docs/*"}},
+		{name: "missing find", replacement: Replacement{Replace: "x"}, wantErr: true},
+		{name: "invalid expression", replacement: Replacement{Find: "(unclosed"}, wantErr: true},
+		{name: "unknown stage", replacement: Replacement{Find: "x", Stage: "text"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.replacement.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReplacer(t *testing.T) {
+	replacer, err := NewReplacer([]Replacement{
+		{Find: `(?m)^Was this page helpful\?.*$`},
+		{Find: `(?m)^© \d{4} .*$`, Pattern: "/docs/*"},
+		{Find: `(?s)<div class="feedback">.*?</div>`, Stage: StageHTML},
+		{Find: `Acme(Corp)`, Replace: "Acme $1", Stage: StageBoth},
+	})
+	if err != nil {
+		t.Fatalf("NewReplacer() unexpected error: %v", err)
+	}
+
+	html := `<p>AcmeCorp docs</p><div class="feedback">
+<p>Rate</p></div>`
+	if got, want := replacer.HTML("https://example.com/docs/a", html), "<p>Acme Corp docs</p>"; got != want {
+		t.Errorf("HTML() = %q, want %q", got, want)
+	}
+
+	markdown := "# Guide\n\nText by AcmeCorp.\n\nWas this page helpful? Yes No\n\n© 2024 Acme"
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "all rules", url: "https://example.com/docs/a", want: "# Guide\n\nText by Acme Corp."},
+		{name: "pattern not matching", url: "https://example.com/blog/a", want: "# Guide\n\nText by Acme Corp.\n\n© 2024 Acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replacer.Markdown(tt.url, markdown); got != tt.want {
+				t.Errorf("Markdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
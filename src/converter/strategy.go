@@ -0,0 +1,63 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+)
+
+// Filename strategy names
+const (
+	FilenameFromPath  = "path"
+	FilenameFromTitle = "title"
+	FilenameFromHash  = "hash"
+)
+
+// hashFilenameLength is the number of hex characters of hash based file names
+const hashFilenameLength = 16
+
+// FilenameStrategy names the output file of a crawled page.
+// The returned name is relative to the output root and ends with .md.
+type FilenameStrategy func(page crawler.Page) string
+
+// FilenameStrategies returns the names of the built-in strategies
+func FilenameStrategies() []string {
+	return []string{FilenameFromPath, FilenameFromTitle, FilenameFromHash}
+}
+
+// GetFilenameStrategy returns a built-in strategy by name
+func GetFilenameStrategy(name string) (FilenameStrategy, error) {
+	switch name {
+	case "", FilenameFromPath:
+		return PathFilename, nil
+	case FilenameFromTitle:
+		return TitleFilename, nil
+	case FilenameFromHash:
+		return HashFilename, nil
+	default:
+		return nil, fmt.Errorf("unknown filename strategy %q (expected %s)", name, strings.Join(FilenameStrategies(), ", "))
+	}
+}
+
+// PathFilename names the file after the URL path and query
+func PathFilename(page crawler.Page) string {
+	return GenerateFilename(page.URL)
+}
+
+// TitleFilename names the file after a slug of the page title, falling back to the URL path for untitled pages
+func TitleFilename(page crawler.Page) string {
+	title := strings.ToLower(strings.Join(strings.Fields(page.Title), "-"))
+	if title == "" {
+		return PathFilename(page)
+	}
+	return sanitizeFilename(title) + ".md"
+}
+
+// HashFilename names the file after a hash of the page URL, giving short names that do not change with the title
+func HashFilename(page crawler.Page) string {
+	sum := sha256.Sum256([]byte(page.URL))
+	return hex.EncodeToString(sum[:])[:hashFilenameLength] + ".md"
+}
@@ -0,0 +1,41 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+)
+
+func TestFilenameStrategies(t *testing.T) {
+	page := crawler.Page{URL: "https://example.com/docs/guide?id=1", Title: "Getting Started: The Guide"}
+
+	tests := []struct {
+		name     string
+		strategy string
+		page     crawler.Page
+		expected string
+	}{
+		{name: "path", strategy: FilenameFromPath, page: page, expected: "docs-guide-id-1.md"},
+		{name: "default", strategy: "", page: page, expected: "docs-guide-id-1.md"},
+		{name: "title", strategy: FilenameFromTitle, page: page, expected: "getting-started-the-guide.md"},
+		{name: "untitled", strategy: FilenameFromTitle, page: crawler.Page{URL: page.URL}, expected: "docs-guide-id-1.md"},
+		{name: "hash", strategy: FilenameFromHash, page: page, expected: "14ead4f9ee7003dc.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := GetFilenameStrategy(tt.strategy)
+			if err != nil {
+				t.Fatalf("GetFilenameStrategy() unexpected error: %v", err)
+			}
+
+			if result := strategy(tt.page); result != tt.expected {
+				t.Errorf("strategy() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := GetFilenameStrategy("uuid"); err == nil {
+		t.Error("GetFilenameStrategy() expected an error for an unknown strategy")
+	}
+}
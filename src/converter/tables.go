@@ -0,0 +1,278 @@
+package converter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Table strategies for complex tables, those with merged cells or nested tables.
+// Simple tables are always converted to GFM tables.
+const (
+	// TableGFM converts complex tables to GFM tables as well, losing merged cells
+	TableGFM = "gfm"
+	// TableHTML keeps complex tables as embedded HTML blocks
+	TableHTML = "html"
+	// TableCSV writes complex tables to CSV files referenced from the Markdown, see ExtractComplexTables
+	TableCSV = "csv"
+	// TableList flattens each row of a complex table into a list item
+	TableList = "list"
+)
+
+// blankLines matches runs of lines containing only whitespace
+var blankLines = regexp.MustCompile(`\n[ \t]*(\n[ \t]*)+`)
+
+// tableAttributes are the attributes kept on the elements of tables embedded as HTML
+var tableAttributes = map[string]bool{"colspan": true, "rowspan": true, "scope": true, "headers": true, "href": true, "src": true, "alt": true}
+
+// ValidateTableStrategy checks that a table strategy is known
+func ValidateTableStrategy(strategy string) error {
+	switch strategy {
+	case "", TableGFM, TableHTML, TableCSV, TableList:
+		return nil
+	default:
+		return fmt.Errorf("unknown table strategy %q (expected %s, %s, %s, or %s)", strategy, TableGFM, TableHTML, TableCSV, TableList)
+	}
+}
+
+// complexTableRules converts complex tables with the given strategy and leaves simple tables to the table plugin.
+// Tables left by ExtractComplexTables for the CSV strategy are embedded as HTML.
+func complexTableRules(strategy string) []md.Rule {
+	if strategy == "" || strategy == TableGFM {
+		return nil
+	}
+
+	return []md.Rule{
+		{
+			Filter: []string{"table"},
+			Replacement: func(_ string, selection *goquery.Selection, _ *md.Options) *string {
+				if !isComplexTable(selection) || selection.ParentsFiltered("table").Length() > 0 {
+					return nil
+				}
+				if strategy == TableList {
+					return md.String("\n\n" + tableList(tableGrid(selection)) + "\n\n")
+				}
+				return md.String("\n\n" + tableHTML(selection) + "\n\n")
+			},
+		},
+	}
+}
+
+// isComplexTable reports whether a table has merged cells or nested tables
+func isComplexTable(table *goquery.Selection) bool {
+	if table.Find("table").Length() > 0 {
+		return true
+	}
+
+	merged := false
+	table.Find("td, th").EachWithBreak(func(_ int, cell *goquery.Selection) bool {
+		merged = cellSpan(cell, "colspan") > 1 || cellSpan(cell, "rowspan") > 1
+		return !merged
+	})
+	return merged
+}
+
+// cellSpan returns the colspan or rowspan of a cell, 1 when it is missing or invalid
+func cellSpan(cell *goquery.Selection, attr string) int {
+	span, err := strconv.Atoi(strings.TrimSpace(cell.AttrOr(attr, "1")))
+	if err != nil || span < 1 {
+		return 1
+	}
+	return span
+}
+
+// tableRows returns the rows of a table, without the rows of nested tables
+func tableRows(table *goquery.Selection) *goquery.Selection {
+	node := table.Get(0)
+	return table.Find("tr").FilterFunction(func(_ int, row *goquery.Selection) bool {
+		return row.Closest("table").Get(0) == node
+	})
+}
+
+// tableGrid returns the text of the cells of a table, repeating merged cells in every position they cover
+func tableGrid(table *goquery.Selection) [][]string {
+	return tableGridFunc(table, func(cell *goquery.Selection) string {
+		return strings.Join(strings.Fields(cell.Text()), " ")
+	})
+}
+
+// tableGridFunc returns the content of the cells of a table given by cellContent, repeating merged cells
+// in every position they cover
+func tableGridFunc(table *goquery.Selection, cellContent func(cell *goquery.Selection) string) [][]string {
+	var grid [][]string
+	occupied := map[[2]int]bool{}
+
+	tableRows(table).Each(func(r int, row *goquery.Selection) {
+		for len(grid) <= r {
+			grid = append(grid, nil)
+		}
+
+		col := 0
+		row.ChildrenFiltered("td, th").Each(func(_ int, cell *goquery.Selection) {
+			for occupied[[2]int{r, col}] {
+				col++
+			}
+
+			text := cellContent(cell)
+			rowspan, colspan := cellSpan(cell, "rowspan"), cellSpan(cell, "colspan")
+			for dr := 0; dr < rowspan; dr++ {
+				for len(grid) <= r+dr {
+					grid = append(grid, nil)
+				}
+				for dc := 0; dc < colspan; dc++ {
+					for len(grid[r+dr]) <= col+dc {
+						grid[r+dr] = append(grid[r+dr], "")
+					}
+					grid[r+dr][col+dc] = text
+					occupied[[2]int{r + dr, col + dc}] = true
+				}
+			}
+			col += colspan
+		})
+	})
+
+	// Rows spanned past the last row are dropped, as browsers do
+	rows := tableRows(table).Length()
+	if len(grid) > rows {
+		grid = grid[:rows]
+	}
+	return grid
+}
+
+// tableList writes the rows of a table grid as list items. When the first row is a header,
+// each item is titled by its first cell and lists the other cells under their column name.
+func tableList(grid [][]string) string {
+	if len(grid) == 0 {
+		return ""
+	}
+
+	header, rows := grid[0], grid[1:]
+	if len(rows) == 0 {
+		header, rows = nil, grid
+	}
+
+	var builder strings.Builder
+	for _, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		if header == nil {
+			builder.WriteString("- " + strings.Join(nonEmpty(row), "; ") + "\n")
+			continue
+		}
+
+		builder.WriteString("- **" + row[0] + "**\n")
+		for i := 1; i < len(row); i++ {
+			if row[i] == "" {
+				continue
+			}
+			name := ""
+			if i < len(header) {
+				name = header[i]
+			}
+			if name == "" {
+				builder.WriteString("  - " + row[i] + "\n")
+				continue
+			}
+			builder.WriteString("  - " + name + ": " + row[i] + "\n")
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// nonEmpty returns the non-empty values
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// tableHTML returns the HTML of a table without presentational attributes
+func tableHTML(table *goquery.Selection) string {
+	clone := table.Clone()
+	clone.Find("*").AddSelection(clone).Each(func(_ int, element *goquery.Selection) {
+		node := element.Get(0)
+		attrs := node.Attr[:0]
+		for _, attr := range node.Attr {
+			if tableAttributes[attr.Key] {
+				attrs = append(attrs, attr)
+			}
+		}
+		node.Attr = attrs
+	})
+
+	out, err := goquery.OuterHtml(clone)
+	if err != nil {
+		return ""
+	}
+	// A blank line would end the HTML block in Markdown
+	return blankLines.ReplaceAllString(strings.TrimSpace(out), "\n")
+}
+
+// ExtractComplexTables replaces complex tables with links to CSV files stored under assetsDir,
+// named after the hash of their content. Simple tables are kept for conversion to GFM.
+func ExtractComplexTables(content string, assetsDir string) (string, []Asset) {
+	if !strings.Contains(content, "<table") {
+		return content, nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return content, nil
+	}
+
+	if assetsDir == "" {
+		assetsDir = DefaultAssetsDir
+	}
+
+	var assets []Asset
+	seen := make(map[string]bool)
+	doc.Find("table").Each(func(_ int, table *goquery.Selection) {
+		if !isComplexTable(table) || table.ParentsFiltered("table").Length() > 0 {
+			return
+		}
+
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		if err := writer.WriteAll(tableGrid(table)); err != nil {
+			return
+		}
+
+		sum := sha256.Sum256(buf.Bytes())
+		assetPath := path.Join(assetsDir, "table-"+hex.EncodeToString(sum[:8])+".csv")
+		if !seen[assetPath] {
+			seen[assetPath] = true
+			assets = append(assets, Asset{Path: assetPath, Data: buf.Bytes()})
+		}
+
+		label := strings.Join(strings.Fields(table.ChildrenFiltered("caption").Text()), " ")
+		if label == "" {
+			label = "Table"
+		}
+		table.ReplaceWithHtml(fmt.Sprintf(`<p><a href="%s">%s (CSV)</a></p>`, html.EscapeString(assetPath), html.EscapeString(label)))
+	})
+
+	if len(assets) == 0 {
+		return content, nil
+	}
+
+	out, err := doc.Find("body").Html()
+	if err != nil {
+		return content, nil
+	}
+	return out, assets
+}
@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+)
+
+const spannedTable = `<table class="specs" style="width:100%">
+<thead><tr><th>Model</th><th>Size</th><th>Price</th></tr></thead>
+<tbody>
+<tr><td rowspan="2">Basic</td><td>S</td><td>10</td></tr>
+
+<tr><td>M</td><td>12</td></tr>
+<tr><td>Pro</td><td colspan="2">On request</td></tr>
+</tbody>
+</table>`
+
+func TestConvertComplexTables(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		html     string
+		expected string
+	}{
+		{
+			name:     "simple table is always gfm",
+			strategy: TableHTML,
+			html:     `<table><tr><th>A</th><th>B</th></tr><tr><td>1</td><td>2</td></tr></table>`,
+			expected: "| A | B |\n| --- | --- |\n| 1 | 2 |",
+		},
+		{
+			name:     "html strategy keeps spans",
+			strategy: TableHTML,
+			html:     spannedTable,
+			expected: "<table>\n<thead><tr><th>Model</th><th>Size</th><th>Price</th></tr></thead>\n<tbody>\n" +
+				"<tr><td rowspan=\"2\">Basic</td><td>S</td><td>10</td></tr>\n" +
+				"<tr><td>M</td><td>12</td></tr>\n" +
+				"<tr><td>Pro</td><td colspan=\"2\">On request</td></tr>\n</tbody>\n</table>",
+		},
+		{
+			name:     "list strategy repeats merged cells",
+			strategy: TableList,
+			html:     spannedTable,
+			expected: "- **Basic**\n  - Size: S\n  - Price: 10\n" +
+				"- **Basic**\n  - Size: M\n  - Price: 12\n" +
+				"- **Pro**\n  - Size: On request\n  - Price: On request",
+		},
+		{
+			name:     "nested table is complex",
+			strategy: TableList,
+			html:     `<table><tr><th>Name</th><th>Details</th></tr><tr><td>Plan</td><td><table><tr><td>Inner</td></tr></table></td></tr></table>`,
+			expected: "- **Plan**\n  - Details: Inner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := NewConverter(Options{Tables: tt.strategy})
+			if err != nil {
+				t.Fatalf("NewConverter() unexpected error: %v", err)
+			}
+
+			result, err := conv.Convert(tt.html)
+			if err != nil {
+				t.Fatalf("Convert() unexpected error: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Convert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractComplexTables(t *testing.T) {
+	html := `<p>Intro</p><table><caption>Plans</caption><tr><th>Model</th><th>Price</th></tr><tr><td colspan="2">None</td></tr></table>` +
+		`<table><tr><td>simple</td></tr></table>`
+
+	result, assets := ExtractComplexTables(html, "assets")
+
+	if len(assets) != 1 {
+		t.Fatalf("ExtractComplexTables() assets = %d, want 1", len(assets))
+	}
+	if !strings.HasPrefix(assets[0].Path, "assets/table-") || !strings.HasSuffix(assets[0].Path, ".csv") {
+		t.Errorf("ExtractComplexTables() path = %q, want assets/table-<hash>.csv", assets[0].Path)
+	}
+	if got, want := string(assets[0].Data), "Model,Price\nNone,None\n"; got != want {
+		t.Errorf("ExtractComplexTables() CSV = %q, want %q", got, want)
+	}
+	if want := `<a href="` + assets[0].Path + `">Plans (CSV)</a>`; !strings.Contains(result, want) {
+		t.Errorf("ExtractComplexTables() = %q, want it to contain %q", result, want)
+	}
+	if !strings.Contains(result, "<td>simple</td>") {
+		t.Errorf("ExtractComplexTables() = %q, want the simple table kept", result)
+	}
+}
+
+func TestValidateTableStrategy(t *testing.T) {
+	for _, strategy := range []string{"", TableGFM, TableHTML, TableCSV, TableList} {
+		if err := ValidateTableStrategy(strategy); err != nil {
+			t.Errorf("ValidateTableStrategy(%q) unexpected error: %v", strategy, err)
+		}
+	}
+	if err := ValidateTableStrategy("json"); err == nil {
+		t.Error("ValidateTableStrategy(\"json\") expected an error")
+	}
+}
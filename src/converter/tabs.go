@@ -0,0 +1,132 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// tabPanelSelector matches the panels of tab widgets in ARIA markup and common docs frameworks
+	tabPanelSelector = `[role="tabpanel"], .tabbed-set .tabbed-block, .tabs .tab-pane`
+	// tabLabelSelector matches the tab labels within a tab widget, in panel order
+	tabLabelSelector = `[role="tab"], .tabbed-labels > label, .tabbed-set > label, .tabs .nav-link`
+	// tabChromeSelector matches tab bars and radio inputs left over once panels have headings
+	tabChromeSelector = `[role="tablist"], .tabbed-labels, .tabbed-set > input, .tabbed-set > label, .tabs > .nav`
+	// defaultSectionLevel is the heading level used when no preceding heading is found
+	defaultSectionLevel = 4
+)
+
+// flattenTabs turns tabbed examples and accordions into sequential sections with a heading per tab or summary,
+// so the content of hidden tabs and collapsed sections is kept in the Markdown
+func flattenTabs(root *goquery.Selection) {
+	// Headings are computed before any is inserted, so sibling tabs share the same level
+	panels := root.Find(tabPanelSelector)
+	headings := make([]string, panels.Length())
+	panels.Each(func(i int, panel *goquery.Selection) {
+		if label := tabLabel(root, panel); label != "" {
+			headings[i] = sectionHeading(panel, label)
+		}
+	})
+	panels.Each(func(i int, panel *goquery.Selection) {
+		panel.RemoveAttr("hidden")
+		if headings[i] != "" {
+			panel.BeforeHtml(headings[i])
+		}
+	})
+	root.Find(tabChromeSelector).Remove()
+
+	// Nested accordions are flattened from the inside out, so each summary is still in place when its details is handled
+	details := root.Find("details")
+	for i := details.Length() - 1; i >= 0; i-- {
+		element := details.Eq(i)
+		summary := element.ChildrenFiltered("summary").First()
+		label := strings.Join(strings.Fields(summary.Text()), " ")
+		summary.Remove()
+
+		content, err := element.Html()
+		if err != nil {
+			continue
+		}
+		if label != "" {
+			content = sectionHeading(element, label) + content
+		}
+		element.ReplaceWithHtml("<div>" + content + "</div>")
+	}
+}
+
+// tabLabel finds the label of a tab panel from ARIA references, the position of the panel among its siblings,
+// or its own label attributes
+func tabLabel(root, panel *goquery.Selection) string {
+	if id, ok := panel.Attr("aria-labelledby"); ok {
+		if label := textOfID(root, id); label != "" {
+			return label
+		}
+	}
+
+	if id, ok := panel.Attr("id"); ok && id != "" {
+		tab := root.Find("[aria-controls]").FilterFunction(func(_ int, tab *goquery.Selection) bool {
+			return tab.AttrOr("aria-controls", "") == id
+		})
+		if label := normalizeLabel(tab.First().Text()); label != "" {
+			return label
+		}
+	}
+
+	// Without references, the nth label of the closest widget belongs to the nth panel
+	for container := panel.Parent(); container.Length() > 0; container = container.Parent() {
+		labels := container.Find(tabLabelSelector)
+		if labels.Length() == 0 {
+			continue
+		}
+		panels := container.Find(tabPanelSelector)
+		if index := panels.IndexOfSelection(panel); index >= 0 && index < labels.Length() {
+			return normalizeLabel(labels.Eq(index).Text())
+		}
+		break
+	}
+
+	for _, attr := range []string{"data-label", "aria-label", "title"} {
+		if label := normalizeLabel(panel.AttrOr(attr, "")); label != "" {
+			return label
+		}
+	}
+
+	return ""
+}
+
+// textOfID returns the text of the element with the given id
+func textOfID(root *goquery.Selection, id string) string {
+	element := root.Find("[id]").FilterFunction(func(_ int, element *goquery.Selection) bool {
+		return element.AttrOr("id", "") == id
+	})
+	return normalizeLabel(element.First().Text())
+}
+
+// normalizeLabel collapses the whitespace of a label
+func normalizeLabel(label string) string {
+	return strings.Join(strings.Fields(label), " ")
+}
+
+// sectionHeading returns a heading one level below the closest heading before the element
+func sectionHeading(element *goquery.Selection, label string) string {
+	level := defaultSectionLevel
+	for node := element; node.Length() > 0; node = node.Parent() {
+		previous := node.PrevAllFiltered("h1, h2, h3, h4, h5, h6").First()
+		if previous.Length() > 0 {
+			if parsed, err := strconv.Atoi(strings.TrimPrefix(goquery.NodeName(previous), "h")); err == nil {
+				level = min(parsed+1, 6)
+			}
+			break
+		}
+	}
+
+	tag := "h" + strconv.Itoa(level)
+	return "<" + tag + ">" + escapeText(label) + "</" + tag + ">"
+}
+
+// escapeText escapes text for inclusion in HTML
+func escapeText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
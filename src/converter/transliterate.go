@@ -0,0 +1,82 @@
+package converter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations maps letters that do not decompose into an ASCII base letter
+var transliterations = map[rune]string{
+	// Latin
+	'ß': "ss", 'ẞ': "SS", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D", 'þ': "th", 'Þ': "Th", 'ı': "i",
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// TransliterateFilename replaces accented and other non-ASCII letters in a file name with ASCII equivalents,
+// e.g. "café" becomes "cafe" and "привет" becomes "privet". Scripts without a transliteration, such as CJK,
+// are kept as UTF-8.
+func TransliterateFilename(filename string) string {
+	if isASCII(filename) {
+		return filename
+	}
+
+	var builder strings.Builder
+	transliterated := false
+	for _, r := range norm.NFD.String(filename) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Accents of transliterated letters are dropped, other combining marks such as Japanese dakuten are kept
+			if !transliterated {
+				builder.WriteRune(r)
+			}
+		case r < utf8.RuneSelf:
+			builder.WriteRune(r)
+			transliterated = true
+		default:
+			ascii, ok := transliterateRune(r)
+			builder.WriteString(ascii)
+			transliterated = ok
+		}
+	}
+
+	return norm.NFC.String(builder.String())
+}
+
+// transliterateRune returns the ASCII form of a letter, keeping its case, and whether it has one
+func transliterateRune(r rune) (string, bool) {
+	if ascii, ok := transliterations[r]; ok {
+		return ascii, true
+	}
+
+	lower := unicode.ToLower(r)
+	if ascii, ok := transliterations[lower]; ok && lower != r {
+		if ascii == "" {
+			return "", true
+		}
+		return strings.ToUpper(ascii[:1]) + ascii[1:], true
+	}
+
+	return string(r), false
+}
+
+// isASCII reports whether a string only contains ASCII characters
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
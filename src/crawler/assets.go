@@ -0,0 +1,56 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// defaultMaxAssetSize limits asset downloads when MaxBodySize is not set, like the colly default for pages
+const defaultMaxAssetSize = 10 * 1024 * 1024
+
+// FetchAsset downloads a resource referenced by a page, such as an image, with the user agent,
+// timeout, and content decoding of the crawler. Assets larger than MaxBodySize are rejected.
+func (c *Crawler) FetchAsset(assetURL string) ([]byte, string, error) {
+	return c.FetchAssetLimited(assetURL, 0)
+}
+
+// FetchAssetLimited downloads a resource like FetchAsset, rejecting it when it is larger than maxSize bytes.
+// A maxSize of 0 applies MaxBodySize.
+func (c *Crawler) FetchAssetLimited(assetURL string, maxSize int64) ([]byte, string, error) {
+	request, err := http.NewRequest(http.MethodGet, assetURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create asset request: %w", err)
+	}
+	request.Header.Set("User-Agent", c.options.UserAgent)
+
+	response, err := c.embedClient.Do(request)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch asset: %w", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch asset: status %d", response.StatusCode)
+	}
+
+	limit := maxSize
+	if limit <= 0 {
+		limit = defaultMaxAssetSize
+		if c.options.MaxBodySize > 0 {
+			limit = int64(c.options.MaxBodySize)
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, limit+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read asset: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, "", fmt.Errorf("failed to fetch asset: larger than %d bytes", limit)
+	}
+
+	return data, response.Header.Get("Content-Type"), nil
+}
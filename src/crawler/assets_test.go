@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchAsset(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/logo.png", func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != "test-agent" {
+			t.Errorf("User-Agent = %q, want test-agent", r.UserAgent())
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("png-data"))
+	})
+	mux.HandleFunc("/large.png", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, 64))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{UserAgent: "test-agent", MaxBodySize: 32})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		wantData string
+		wantType string
+		wantErr  bool
+	}{
+		{name: "image", path: "/logo.png", wantData: "png-data", wantType: "image/png"},
+		{name: "larger than the limit", path: "/large.png", wantErr: true},
+		{name: "not found", path: "/missing.png", wantErr: true},
+	}
+
+	if _, _, err := c.FetchAssetLimited(srv.URL+"/large.png", 128); err != nil {
+		t.Errorf("FetchAssetLimited() unexpected error below a custom limit: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, contentType, err := c.FetchAsset(srv.URL + tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(data) != tt.wantData || contentType != tt.wantType {
+				t.Errorf("FetchAsset() = %q, %q, want %q, %q", data, contentType, tt.wantData, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package crawler
+
+import (
+	"strconv"
+
+	"github.com/gocolly/colly"
+)
+
+// isOversized reports whether a response exceeded the maximum body size.
+// colly stops reading at the limit, so a body of exactly the limit may have been cut off.
+func (c *Crawler) isOversized(r *colly.Response) bool {
+	if c.options.MaxBodySize <= 0 {
+		return false
+	}
+
+	if length, err := strconv.ParseInt(r.Headers.Get("Content-Length"), 10, 64); err == nil && length > int64(c.options.MaxBodySize) {
+		return true
+	}
+
+	return len(r.Body) >= c.options.MaxBodySize
+}
+
+// markOversized remembers a response to skip when its HTML is handled
+func (c *Crawler) markOversized(r *colly.Response) {
+	c.oversized.Store(r.Request, true)
+}
+
+// takeOversized reports whether the page of a request was marked as oversized
+func (c *Crawler) takeOversized(request *colly.Request) bool {
+	_, oversized := c.oversized.LoadAndDelete(request)
+	return oversized
+}
@@ -0,0 +1,58 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCrawlerMaxBodySize(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><a href="/small">Small</a> Large ` + strings.Repeat("x", 2048) + `</main></body></html>`))
+	})
+	mux.HandleFunc("/small", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>Small</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		truncate bool
+		wantURLs []string
+	}{
+		{name: "oversized page skipped", wantURLs: []string{srv.URL + "/small"}},
+		{name: "oversized page truncated", truncate: true, wantURLs: []string{srv.URL, srv.URL + "/small"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler(srv.URL, Options{MaxBodySize: 1024, TruncateOversized: tt.truncate})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			var urls []string
+			for _, page := range c.GetPages() {
+				urls = append(urls, page.URL)
+				if len(page.Content) > 1024 {
+					t.Errorf("Content of %s has %d bytes, want at most 1024", page.URL, len(page.Content))
+				}
+			}
+			sort.Strings(urls)
+
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("crawled %v, want %v", urls, tt.wantURLs)
+			}
+		})
+	}
+}
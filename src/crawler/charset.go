@@ -0,0 +1,81 @@
+package crawler
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saintfish/chardet"
+	"golang.org/x/net/html/charset"
+)
+
+// sniffLength is the number of bytes searched for a BOM or a meta charset declaration, as browsers do
+const sniffLength = 1024
+
+// metaCharsetPattern matches a charset declared by a meta element
+var metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset`)
+
+// detectCharset returns the charset of an HTML body from, in order, the Content-Type header,
+// a byte order mark, a meta charset declaration, and byte sniffing
+func detectCharset(body []byte, contentType string) string {
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		if _, name := charset.Lookup(params["charset"]); name != "" {
+			return name
+		}
+	}
+
+	head := body
+	if len(head) > sniffLength {
+		head = head[:sniffLength]
+	}
+
+	// Without a Content-Type charset, DetermineEncoding checks the BOM and meta declarations
+	// and falls back to a windows-1252 guess, which is replaced by byte sniffing below
+	if _, name, certain := charset.DetermineEncoding(head, ""); certain || metaCharsetPattern.Match(head) {
+		return name
+	}
+
+	if utf8.Valid(body) {
+		return "utf-8"
+	}
+
+	if result, err := chardet.NewHtmlDetector().DetectBest(body); err == nil {
+		if _, name := charset.Lookup(result.Charset); name != "" {
+			return name
+		}
+	}
+	return "windows-1252"
+}
+
+// toUTF8 transcodes an HTML body to UTF-8 from its detected charset.
+// The body is returned unchanged when it already is UTF-8 or cannot be decoded.
+func toUTF8(body []byte, contentType string) []byte {
+	name := detectCharset(body, contentType)
+	if name == "utf-8" {
+		return body
+	}
+
+	enc, _ := charset.Lookup(name)
+	if enc == nil {
+		return body
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body
+	}
+	return bytes.TrimPrefix(decoded, []byte("\uFEFF"))
+}
+
+// hasCharset reports whether a Content-Type header declares a charset
+func hasCharset(contentType string) bool {
+	_, params, err := mime.ParseMediaType(contentType)
+	return err == nil && params["charset"] != ""
+}
+
+// isHTML reports whether a Content-Type header is an HTML media type
+func isHTML(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "html")
+}
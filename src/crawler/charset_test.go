@@ -0,0 +1,99 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func encode(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+
+	data, err := enc.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("encode %q: %v", text, err)
+	}
+	return data
+}
+
+func TestToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		want        string
+	}{
+		{
+			name:        "utf-8 unchanged",
+			body:        []byte(`<html><body><p>Café</p></body></html>`),
+			contentType: "text/html",
+			want:        "Café",
+		},
+		{
+			name:        "content-type charset",
+			body:        encode(t, charmap.ISO8859_1, `<html><body><p>Café crème</p></body></html>`),
+			contentType: "text/html; charset=ISO-8859-1",
+			want:        "Café crème",
+		},
+		{
+			name:        "meta charset",
+			body:        encode(t, charmap.ISO8859_1, `<html><head><meta charset="iso-8859-1"></head><body><p>Größe</p></body></html>`),
+			contentType: "text/html",
+			want:        "Größe",
+		},
+		{
+			name:        "meta http-equiv shift_jis",
+			body:        encode(t, japanese.ShiftJIS, `<html><head><meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS"></head><body><p>日本語のページ</p></body></html>`),
+			contentType: "text/html",
+			want:        "日本語のページ",
+		},
+		{
+			name:        "meta charset gbk",
+			body:        encode(t, simplifiedchinese.GBK, `<html><head><meta charset="gbk"></head><body><p>中文网页内容</p></body></html>`),
+			contentType: "text/html",
+			want:        "中文网页内容",
+		},
+		{
+			name:        "sniffed latin-1",
+			body:        encode(t, charmap.Windows1252, `<html><body><p>Les élèves déjà arrivés à l'école</p></body></html>`),
+			contentType: "text/html",
+			want:        "Les élèves déjà arrivés à l'école",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(toUTF8(tt.body, tt.contentType)); !strings.Contains(got, tt.want) {
+				t.Errorf("toUTF8() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerTranscodesPages(t *testing.T) {
+	body := encode(t, charmap.ISO8859_1, `<html><head><meta charset="iso-8859-1"><title>Café</title></head><body><main><p>Déjà vu</p></main></body></html>`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{SinglePage: true})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	pages := c.GetPages()
+	if len(pages) != 1 || pages[0].Title != "Café" || !strings.Contains(pages[0].Content, "Déjà vu") {
+		t.Errorf("GetPages() = %+v, want the page transcoded to UTF-8", pages)
+	}
+}
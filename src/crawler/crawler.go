@@ -2,12 +2,18 @@ package crawler
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly"
+
+	"github.com/sandrolain/crawldown/src/metadata"
+	"github.com/sandrolain/crawldown/src/pagebuffer"
 )
 
 // Page represents a crawled web page
@@ -15,6 +21,21 @@ type Page struct {
 	URL     string
 	Title   string
 	Content string
+	Links   []string // Absolute HTTP(S) URLs linked from the page, without fragments
+	Lang    string   // Language declared by the page or its Content-Language header, empty if none
+	Status  int      // HTTP status code of the response
+
+	Canonical  string            // Absolute URL of <link rel="canonical">, empty if none
+	Alternates map[string]string // hreflang alternates of <link rel="alternate">, keyed by language
+
+	Next string // Absolute URL of the next page of a paginated series, empty if none
+	Prev string // Absolute URL of the previous page of a paginated series, empty if none
+
+	Metadata metadata.Metadata // Open Graph, Twitter card, and JSON-LD metadata
+
+	Aliases []string // Other URLs that lead to the same page, e.g. its www or apex and http or https variants
+
+	Depth int // Depth of the page in the crawl, 1 for the start page
 }
 
 // Options defines crawler configuration
@@ -24,23 +45,128 @@ type Options struct {
 	UserAgent           string
 	IgnoreRobotsTxt     bool
 	FollowExternalLinks bool
-	SinglePage          bool     // When true, only the provided start URL is fetched (no link following)
-	RequestTimeout      int      // Timeout in seconds for each request (default: 30)
-	RequestDelay        int      // Delay in seconds between requests (default: 0)
-	ExcludedPaths       []string // URL path prefixes to exclude from crawling
+	SinglePage          bool           // When true, only the provided start URL is fetched (no link following)
+	RequestTimeout      int            // Timeout in seconds for each request (default: 30)
+	RequestDelay        int            // Delay in seconds between requests (default: 0)
+	Jitter              time.Duration  // Maximum random time added to the delay of each request; 0 derives half of the delay, negative adds none
+	Parallelism         int            // Requests sent at the same time to each domain, 0 means 2
+	ExcludedPaths       []string       // URL path prefixes to exclude from crawling
+	CanonicalOnly       bool           // When true, pages whose canonical URL is another page of the same host are skipped and the canonical URL is crawled instead
+	FollowPagination    bool           // When true, next and previous pages of a paginated series are crawled regardless of MaxDepth
+	StripSelectors      []string       // CSS selectors of elements removed from the page before the main content is extracted
+	RequireSelectors    []string       // CSS selectors of which one must match an element of a page for it to be kept; other pages are only crawled for their links
+	DistinctSchemes     bool           // When true, http and https variants of a URL are crawled as separate pages instead of following the site scheme
+	MaxBodySize         int            // Maximum response body size in bytes, 0 keeps the colly default of 10MB
+	TruncateOversized   bool           // When true, pages larger than MaxBodySize are converted from the truncated body instead of being skipped
+	URLRewrites         []URLRewrite   // Rewrite rules applied in order to discovered URLs before they are visited
+	PathLimits          []PathLimit    // Page budgets for URL patterns; a URL counts against the first matching limit
+	IncludeSubdomains   bool           // When true, all subdomains of the start host's registrable domain are crawled and www and apex hosts are treated as one
+	EmbedPolicy         EmbedPolicy    // Handling of iframes and embedded objects without a matching rule; empty leaves them to the converter, which drops them
+	EmbedRules          []EmbedRule    // Embed policies for embed URL patterns; an embed uses the first matching rule
+	KeepStatuses        []int          // Error statuses whose pages are kept and their links followed, e.g. 404 for custom not found pages
+	FollowStatuses      []int          // Error statuses whose pages are not kept but their links are followed, e.g. 403
+	Retries             int            // Number of times requests failing with a timeout, network error, 429, or 5xx status are retried
+	MaxErrorRate        float64        // Share of failed requests (0-1) above which Start returns an ErrorRateError; 0 disables the check
+	Verbosity           Verbosity      // Amount of crawl log printed, VerbosityNormal by default
+	AcceptEncodings     []string       // Content encodings accepted and decoded, DefaultEncodings when empty
+	DisableKeepAlives   bool           // When true, each request opens a new connection
+	MaxIdleConnsPerHost int            // Idle connections kept open for reuse per host, 0 keeps the Go default of 2
+	DisableHTTP2        bool           // When true, requests are sent over HTTP/1.1 even to servers supporting HTTP/2
+	TLSMinVersion       string         // Minimum TLS version of connections, see ParseTLSVersion; empty means 1.2
+	CACertFile          string         // PEM file of certificate authorities trusted in addition to the system ones, e.g. a private CA
+	InsecureSkipVerify  bool           // When true, server certificates are not verified, for internal sites with self-signed certificates
+	DenyPrivateNetworks bool           // When true, connections to addresses for which IsPrivateAddress is true are refused with ErrAddressDenied, and proxies are not used
+	AllowedNetworks     []string       // IP addresses and CIDR prefixes always connected to, even when private or denied
+	DeniedNetworks      []string       // IP addresses and CIDR prefixes never connected to
+	IgnoreMetaRobots    bool           // When true, noindex and nofollow in robots meta tags and X-Robots-Tag headers are ignored
+	Scope               string         // Path prefix of the links followed on the start host, see ScopePrefix; empty derives it from the start URL
+	Traversal           string         // Order pages are visited in: TraversalParallel (default), TraversalBreadthFirst, or TraversalDepthFirst
+	Deterministic       bool           // When true, pages are fetched one at a time in a stable order, breadth-first unless Traversal is set
+	NavSelector         string         // CSS selector of the site navigation, read from the first page where it matches, see Navigation
+	Priorities          []PriorityRule // Weights of URL patterns; links are fetched one at a time, highest weight first
+	MaxPages            int            // Maximum number of requests sent, 0 means no limit
+	MaxDuration         time.Duration  // Time after which no more requests are sent, 0 means no limit
+	Frontier            Frontier       // Frontier shared with other crawlers splitting the crawl; links are then fetched one at a time in its order
+	SpillDir            string         // Directory of the database file crawled pages are kept in instead of memory, see Close; empty keeps them in memory
+
+	Domains         []DomainOptions   // Per-domain overrides, the most specific matching domain wins
+	ExternalDomains []string          // When following external links, only these domains (and subdomains) are crawled; empty allows all
+	DeniedDomains   []string          // Domains (and subdomains) never crawled
+	ExternalDepth   int               // Maximum depth into each external domain, counting the linked page as 1; 0 means no extra limit
+	HostAddresses   map[string]string // IP address connected to for each host instead of resolving it, see ParseHostAddress; URLs and output keep the host
 }
 
 // PageCallback is called when a page is successfully crawled
 type PageCallback func(page Page)
 
+// ErrorCallback is called when a request fails
+type ErrorCallback func(pageURL string, err error)
+
+// ContentAction tells the crawler what to do with the extracted content of a page
+type ContentAction int
+
+const (
+	// ContentConvert keeps the page as extracted
+	ContentConvert ContentAction = iota
+	// ContentSkip drops the page before it is stored or passed to the page callback
+	ContentSkip
+	// ContentTransform replaces the page content with the content returned by the hook
+	ContentTransform
+)
+
+// ContentHook inspects the extracted HTML of a page before conversion.
+// The returned content is only used when the action is ContentTransform.
+type ContentHook func(page Page) (ContentAction, string)
+
 // Crawler handles web crawling operations
 type Crawler struct {
-	collector    *colly.Collector
-	pages        []Page
-	pagesMutex   sync.Mutex
-	baseURL      *url.URL
-	options      Options
-	pageCallback PageCallback
+	collector     *colly.Collector
+	pages         pagebuffer.Buffer[Page] // Crawled pages keyed by their position in the crawl
+	pagesMutex    sync.Mutex
+	crawled       map[string]bool // Normalized URLs of the pages handled so far
+	baseURL       *url.URL
+	options       Options
+	pageCallback  PageCallback
+	errorHooks    []ErrorCallback
+	contentHooks  []ContentHook
+	requestHooks  []RequestHook
+	responseHooks []ResponseHook
+	skipHooks     []SkipHook
+	siteDomain    string        // Registrable domain of the start host
+	scope         string        // Path prefix of the links followed on the start host
+	frontier      Frontier      // Links waiting to be visited in order, nil for parallel crawls
+	pending       *pendingLinks // Links of the pages being handled, pushed to the frontier once a page is handled
+
+	domainDepths      map[string]int // Depth of each discovered URL within its domain
+	domainDepthsMutex sync.Mutex
+
+	redirects      map[string][]Redirect // Redirect chains keyed by the URL they lead to
+	redirectsMutex sync.Mutex
+	siteScheme     string // Scheme of links within the site, updated when the site redirects to another scheme
+	schemeMutex    sync.Mutex
+
+	pathBudget *pathBudget
+
+	priorities []compiledPriority
+	deadline   time.Time // End of MaxDuration, set when the crawl starts
+
+	rewrites       []compiledRewrite
+	rewritten      map[string]string // Original URLs changed by rewrite rules, mapped to the URL visited instead
+	rewrittenMutex sync.Mutex
+
+	oversized sync.Map // Requests whose responses exceeded MaxBodySize, skipped when their HTML is handled
+	robots    sync.Map // Robots directives of the pages being handled, by request
+
+	navigation []NavItem // Site navigation read with NavSelector
+	navMutex   sync.Mutex
+
+	errors      []CrawlError
+	retries     map[string]int // Number of retries of each URL
+	requests    int            // Number of distinct requests sent
+	errorsMutex sync.Mutex
+
+	embedRules  []compiledEmbedRule
+	embedClient *http.Client // Client used to fetch same-site embeds inlined into the page and page assets
 }
 
 // NewCrawler creates a new crawler instance
@@ -62,50 +188,113 @@ func NewCrawler(startURL string, opts Options) (*Crawler, error) {
 		opts.RequestTimeout = 30
 	}
 
+	// Subdomains are checked in the request callback, as colly only matches exact hosts
 	allowedDomains := opts.AllowedDomains
-	if len(allowedDomains) == 0 && !opts.FollowExternalLinks {
+	if len(allowedDomains) == 0 && !opts.FollowExternalLinks && !opts.IncludeSubdomains {
 		allowedDomains = []string{parsedURL.Host}
 	}
 
+	if err := ValidateTraversal(opts.Traversal); err != nil {
+		return nil, fmt.Errorf("invalid traversal: %w", err)
+	}
+	order := traversal(opts)
+
 	c := colly.NewCollector(
 		colly.MaxDepth(opts.MaxDepth),
 		colly.AllowedDomains(allowedDomains...),
 		colly.UserAgent(opts.UserAgent),
-		colly.Async(true), // Enable async to handle multiple requests
+		colly.Async(order == TraversalParallel), // Enable async to handle multiple requests, ordered crawls visit one page at a time
 	)
 
-	// Set timeout
-	c.SetRequestTimeout(time.Duration(opts.RequestTimeout) * time.Second)
+	rewrites, err := compileRewrites(opts.URLRewrites)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url rewrite: %w", err)
+	}
 
-	// Allow unlimited parallel requests
-	//nolint:errcheck // Intentionally using default parallelism
-	_ = c.Limit(&colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: 2,
-	})
+	budget, err := newPathBudget(opts.PathLimits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path limit: %w", err)
+	}
 
-	// Set delay between requests if specified
-	if opts.RequestDelay > 0 {
-		err := c.Limit(&colly.LimitRule{
-			DomainGlob:  "*",
-			Delay:       time.Duration(opts.RequestDelay) * time.Second,
-			RandomDelay: time.Duration(opts.RequestDelay/2) * time.Second,
-			Parallelism: 2,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to set rate limit: %w", err)
+	if opts.EmbedPolicy != "" {
+		if err := ValidateEmbedPolicy(opts.EmbedPolicy); err != nil {
+			return nil, fmt.Errorf("invalid embed policy: %w", err)
 		}
 	}
 
+	priorities, err := compilePriorities(opts.Priorities)
+	if err != nil {
+		return nil, fmt.Errorf("invalid priority: %w", err)
+	}
+
+	embedRules, err := compileEmbedRules(opts.EmbedRules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid embed rule: %w", err)
+	}
+
+	base, err := newBaseTransport(opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transport options: %w", err)
+	}
+
+	// Responses are decoded by the transport, so that MaxBodySize limits the decoded size
+	transport, err := newDecodingTransport(base, opts.AcceptEncodings)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accept encoding: %w", err)
+	}
+	c.WithTransport(transport)
+
+	if opts.MaxBodySize > 0 {
+		c.MaxBodySize = opts.MaxBodySize
+	}
+
+	// Set timeout
+	c.SetRequestTimeout(time.Duration(opts.RequestTimeout) * time.Second)
+
+	// Limit parallelism and set the delay between requests, per domain where configured
+	if err := c.Limits(limitRules(opts)); err != nil {
+		return nil, fmt.Errorf("failed to set rate limit: %w", err)
+	}
+
 	if opts.IgnoreRobotsTxt {
 		c.IgnoreRobotsTxt = true
 	}
 
+	// Error responses are only parsed when some of them are kept or followed
+	c.ParseHTTPErrorResponse = len(opts.KeepStatuses) > 0 || len(opts.FollowStatuses) > 0
+
+	pages, err := pagebuffer.Open[Page](opts.SpillDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open page buffer: %w", err)
+	}
+
 	crawler := &Crawler{
-		collector: c,
-		pages:     []Page{},
-		baseURL:   parsedURL,
-		options:   opts,
+		collector:    c,
+		pages:        pages,
+		crawled:      make(map[string]bool),
+		baseURL:      parsedURL,
+		options:      opts,
+		siteDomain:   SiteDomain(parsedURL.Host),
+		scope:        ScopePrefix(parsedURL, opts.Scope),
+		domainDepths: make(map[string]int),
+		redirects:    make(map[string][]Redirect),
+		siteScheme:   parsedURL.Scheme,
+		pathBudget:   budget,
+		rewrites:     rewrites,
+		priorities:   priorities,
+		rewritten:    make(map[string]string),
+		embedRules:   embedRules,
+		retries:      make(map[string]int),
+		embedClient:  &http.Client{Timeout: time.Duration(opts.RequestTimeout) * time.Second, Transport: transport},
+	}
+	c.RedirectHandler = crawler.handleRedirect
+
+	if order != TraversalParallel {
+		crawler.pending = &pendingLinks{}
+		crawler.frontier = opts.Frontier
+		if crawler.frontier == nil {
+			crawler.frontier = newMemoryFrontier(order)
+		}
 	}
 
 	return crawler, nil
@@ -116,38 +305,161 @@ func (c *Crawler) OnPage(callback PageCallback) {
 	c.pageCallback = callback
 }
 
+// OnError registers a callback called when a request fails.
+// Callbacks run in registration order.
+func (c *Crawler) OnError(callback ErrorCallback) {
+	c.errorHooks = append(c.errorHooks, callback)
+}
+
+// AddContentHook registers a hook evaluated on each page before it is stored.
+// Hooks run in registration order and the first skip stops evaluation.
+func (c *Crawler) AddContentHook(hook ContentHook) {
+	c.contentHooks = append(c.contentHooks, hook)
+}
+
 // Start begins the crawling process
 func (c *Crawler) Start() error {
 	c.setupCallbacks()
 
-	err := c.collector.Visit(c.baseURL.String())
-	if err != nil {
+	if c.options.MaxDuration > 0 {
+		c.deadline = time.Now().Add(c.options.MaxDuration)
+	}
+
+	// Crawls sharing a frontier queue the start URL, so that it is fetched once
+	if c.options.Frontier != nil {
+		if err := c.frontier.Push([]QueuedLink{{URL: c.baseURL.String(), Depth: 1}}); err != nil {
+			return fmt.Errorf("failed to start crawling: %w", err)
+		}
+	} else if err := c.collector.Visit(c.baseURL.String()); err != nil {
 		return fmt.Errorf("failed to start crawling: %w", err)
 	}
 
-	// Wait for all async requests to complete
+	// Visit the links queued by ordered crawls, then wait for all async requests to complete
+	if c.frontier != nil {
+		if err := c.drain(); err != nil {
+			return err
+		}
+	}
 	c.collector.Wait()
 
-	return nil
+	return c.checkErrorRate()
 }
 
 // setupCallbacks configures the collector callbacks
 func (c *Crawler) setupCallbacks() {
+	// Normalize XHTML and legacy markup before the HTML callbacks parse it
+	c.collector.OnResponse(func(r *colly.Response) {
+		if !c.reportStatus(r) {
+			return
+		}
+
+		// colly only transcodes bodies whose Content-Type declares a charset
+		if contentType := r.Headers.Get("Content-Type"); isHTML(contentType) && !hasCharset(contentType) {
+			r.Body = toUTF8(r.Body, contentType)
+		}
+
+		c.runResponseHooks(r)
+
+		c.adoptScheme(r.Request.URL, c.redirectChain(normalizeURL(r.Request.URL.String())))
+
+		if !isHTML(r.Headers.Get("Content-Type")) {
+			return
+		}
+
+		if !c.options.TruncateOversized && c.isOversized(r) {
+			c.logf(VerbosityNormal, "Skipping oversized page: %s\n", r.Request.URL.String())
+			c.markOversized(r)
+			c.skip(r.Request.URL.String(), SkipOversized)
+			return
+		}
+
+		r.Body = NormalizeMarkup(r.Body)
+	})
+
+	// The navigation is read before the main content is extracted from the page
+	if c.options.NavSelector != "" {
+		c.collector.OnHTML("html", c.readNavigation)
+	}
+
 	// On HTML element callback
 	c.collector.OnHTML("html", func(e *colly.HTMLElement) {
+		// Links of oversized pages are still followed, but the truncated page is not kept
+		if c.takeOversized(e.Request) {
+			return
+		}
+
+		if !c.keepsStatus(e.Response.StatusCode) {
+			c.skip(e.Request.URL.String(), SkipStatus)
+			return
+		}
+
 		// Normalize URL to handle query parameters consistently
 		normalizedURL := normalizeURL(e.Request.URL.String())
 
+		// A redirect can lead to a page that was already crawled from a direct link
+		if !c.markCrawled(normalizedURL) {
+			return
+		}
+
+		// Links of noindex pages are still followed unless they are nofollow too
+		robots := c.pageRobots(e)
+		if robots.noIndex {
+			c.skip(normalizedURL, SkipNoIndex)
+			return
+		}
+
+		canonical := extractCanonical(e)
+		if c.options.CanonicalOnly && !c.options.SinglePage && isOtherPage(canonical, normalizedURL) {
+			c.skip(normalizedURL, SkipCanonical)
+			c.visitSameDepth(e.Request, canonical)
+			return
+		}
+
+		next := extractPagination(e, nextPageSelectors, nextPageTexts)
+		prev := extractPagination(e, prevPageSelectors, prevPageTexts)
+		if c.options.FollowPagination && !c.options.SinglePage && !robots.noFollow {
+			for _, pageURL := range []string{next, prev} {
+				if pageURL != "" {
+					c.visitSameDepth(e.Request, pageURL)
+				}
+			}
+		}
+
+		// Listing and category pages without the required elements still lead to the pages that have them
+		if !hasRequiredElement(e.DOM, c.options.RequireSelectors) {
+			c.skip(normalizedURL, SkipSelector)
+			return
+		}
+
 		page := Page{
-			URL:     normalizedURL,
-			Title:   e.ChildText("title"),
-			Content: extractMainContent(e),
+			URL:        normalizedURL,
+			Title:      e.ChildText("title"),
+			Content:    c.resolveEmbeds(extractMainContent(e, c.stripSelectorsFor(e.Request.URL.Host)), e.Request.URL),
+			Links:      extractLinks(e),
+			Lang:       declaredLanguage(e),
+			Status:     e.Response.StatusCode,
+			Canonical:  canonical,
+			Alternates: extractAlternates(e),
+			Next:       next,
+			Prev:       prev,
+			Metadata:   metadata.Extract(e.DOM, e.Request.URL),
+			Aliases:    c.pageAliases(normalizedURL),
+			Depth:      e.Request.Depth,
 		}
 
-		// Thread-safe append for async crawling
+		page, keep := c.applyContentHooks(page)
+		if !keep {
+			c.skip(normalizedURL, SkipContent)
+			return
+		}
+
+		// Pages are keyed by a zero-padded sequence, so that the keys sort in crawl order
 		c.pagesMutex.Lock()
-		c.pages = append(c.pages, page)
+		err := c.pages.Put(fmt.Sprintf("%010d", c.pages.Len()), page)
 		c.pagesMutex.Unlock()
+		if err != nil {
+			c.logf(VerbosityQuiet, "Failed to keep %s: %v\n", normalizedURL, err)
+		}
 
 		// Call callback if set
 		if c.pageCallback != nil {
@@ -158,6 +470,10 @@ func (c *Crawler) setupCallbacks() {
 	// On link callback: only register if not in SinglePage mode
 	if !c.options.SinglePage {
 		c.collector.OnHTML("a[href]", func(e *colly.HTMLElement) {
+			if !c.followsStatus(e.Response.StatusCode) || c.pageRobots(e).noFollow {
+				return
+			}
+
 			link := e.Attr("href")
 
 			// Skip non-HTTP protocols and anchor links
@@ -177,35 +493,157 @@ func (c *Crawler) setupCallbacks() {
 				return
 			}
 
-			// Build absolute URL for checking
-			absoluteURL := e.Request.AbsoluteURL(link)
+			// Build absolute URL for checking, applying rewrite rules and folding www and apex hosts and http and https into one
+			absoluteURL := c.foldScheme(c.foldWWW(c.rewriteURL(e.Request.AbsoluteURL(link))))
 
 			// Skip excluded paths
 			if c.isExcludedPath(absoluteURL) {
+				c.logf(VerbosityVerbose, "Skipping (excluded path): %s\n", absoluteURL)
+				return
+			}
+
+			// Skip links outside the path prefix of the crawl
+			if !c.inScope(absoluteURL) {
+				c.logf(VerbosityVerbose, "Skipping (out of scope %s): %s\n", c.scope, absoluteURL)
 				return
 			}
 
-			// Visit is best effort, errors are logged via OnError callback
-			//nolint:errcheck // Intentionally ignoring error as it's handled by OnError callback
-			_ = e.Request.Visit(link)
+			// Skip links beyond the depth allowed on their domain
+			if !c.allowDomainDepth(e.Request.URL, absoluteURL) {
+				c.logf(VerbosityVerbose, "Skipping (domain depth): %s\n", absoluteURL)
+				return
+			}
+
+			// Ordered crawls queue the link, it is visited once the pages before it are handled
+			if c.frontier != nil {
+				c.queueLink(e.Request, absoluteURL)
+				return
+			}
+
+			// Visit is best effort, request errors are logged via OnError callback
+			if reason := linkSkipReason(e.Request.Visit(absoluteURL)); reason != "" {
+				c.logf(VerbosityVerbose, "Skipping (%s): %s\n", reason, absoluteURL)
+			}
 		})
 	}
 
-	// Error callback
-	c.collector.OnError(func(r *colly.Response, err error) {
-		// nolint:forbidigo // Logging output during crawling
-		fmt.Printf("Error crawling %s: %v\n", r.Request.URL, err)
+	c.collector.OnScraped(func(r *colly.Response) {
+		c.robots.Delete(r.Request)
+		if c.frontier != nil {
+			c.pushLinks(r.Request)
+		}
 	})
 
+	// Error callback
+	c.collector.OnError(c.reportError)
+
 	// Request callback
 	c.collector.OnRequest(func(r *colly.Request) {
-		// nolint:forbidigo // Logging output during crawling
-		fmt.Printf("Visiting: %s\n", r.URL.String())
+		if !c.isDomainAllowed(r.URL) || c.isExcludedOnDomain(r.URL) {
+			c.skip(r.URL.String(), SkipDomain)
+			r.Abort()
+			return
+		}
+
+		// Retried requests were already counted
+		if c.retryCount(r.URL.String()) > 0 {
+			return
+		}
+
+		if !c.runRequestHooks(r) {
+			c.skip(r.URL.String(), SkipRequest)
+			r.Abort()
+			return
+		}
+
+		// Counted last, so that only requests that are sent use the budget
+		if !c.pathBudget.take(r.URL.String()) {
+			c.logf(VerbosityNormal, "Skipping (path limit reached): %s\n", r.URL.String())
+			c.skip(r.URL.String(), SkipPathLimit)
+			r.Abort()
+			return
+		}
+
+		if !c.takeRequest() {
+			c.skip(r.URL.String(), SkipBudget)
+			r.Abort()
+			return
+		}
+
+		c.logf(VerbosityNormal, "Visiting: %s\n", r.URL.String())
 	})
 }
 
+// reportError retries a failed request when possible, otherwise records and logs it and passes it to the error callback
+func (c *Crawler) reportError(r *colly.Response, err error) {
+	// Answers to conditional requests sent by request hooks are not failures
+	if r.StatusCode == http.StatusNotModified {
+		c.skip(r.Request.URL.String(), SkipUnchanged)
+		return
+	}
+
+	errorType := classifyError(r.StatusCode, err)
+	if c.retry(r, errorType) {
+		return
+	}
+	c.recordError(r, errorType, err)
+
+	c.logf(VerbosityQuiet, "Error crawling %s: %v\n", r.Request.URL, err)
+
+	for _, callback := range c.errorHooks {
+		callback(r.Request.URL.String(), err)
+	}
+}
+
+// declaredLanguage returns the language declared by the html lang attribute, a content-language meta tag, or the response header
+func declaredLanguage(e *colly.HTMLElement) string {
+	candidates := []string{
+		e.Attr("lang"),
+		e.Attr("xml:lang"),
+		e.ChildAttr(`meta[http-equiv="content-language" i]`, "content"),
+	}
+	if e.Response != nil && e.Response.Headers != nil {
+		candidates = append(candidates, e.Response.Headers.Get("Content-Language"))
+	}
+
+	for _, candidate := range candidates {
+		if candidate = strings.TrimSpace(candidate); candidate != "" {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// applyContentHooks runs the registered content hooks and reports whether the page should be kept
+func (c *Crawler) applyContentHooks(page Page) (Page, bool) {
+	for _, hook := range c.contentHooks {
+		action, content := hook(page)
+		switch action {
+		case ContentSkip:
+			return page, false
+		case ContentTransform:
+			page.Content = content
+		}
+	}
+
+	return page, true
+}
+
 // extractMainContent attempts to extract the main content from the page
-func extractMainContent(e *colly.HTMLElement) string {
+func extractMainContent(e *colly.HTMLElement, stripSelectors []string) string {
+	// Strip overlays from a copy, so link discovery still sees the whole page
+	root := e.DOM
+	if len(stripSelectors) > 0 {
+		root = e.DOM.Clone()
+		root.Find(strings.Join(stripSelectors, ", ")).Remove()
+	}
+
+	return mainContent(root)
+}
+
+// mainContent returns the HTML of the first main content area found in the document
+func mainContent(root *goquery.Selection) string {
 	var content string
 
 	// Try to find main content areas in order of priority
@@ -221,7 +659,7 @@ func extractMainContent(e *colly.HTMLElement) string {
 	}
 
 	for _, selector := range selectors {
-		if html, err := e.DOM.Find(selector).First().Html(); err == nil && html != "" {
+		if html, err := root.Find(selector).First().Html(); err == nil && html != "" {
 			content = html
 			break
 		}
@@ -230,11 +668,171 @@ func extractMainContent(e *colly.HTMLElement) string {
 	return content
 }
 
-// GetPages returns all crawled pages
+// extractLinks returns the unique absolute HTTP(S) links of the page
+func extractLinks(e *colly.HTMLElement) []string {
+	links := []string{}
+	seen := make(map[string]bool)
+
+	e.ForEach("a[href]", func(_ int, link *colly.HTMLElement) {
+		absoluteURL := e.Request.AbsoluteURL(link.Attr("href"))
+		parsedURL, err := url.Parse(absoluteURL)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return
+		}
+
+		parsedURL.Fragment = ""
+		normalizedURL := normalizeURL(parsedURL.String())
+		if !seen[normalizedURL] {
+			seen[normalizedURL] = true
+			links = append(links, normalizedURL)
+		}
+	})
+
+	return links
+}
+
+// Follows reports whether a link of a page may still be crawled: the page is above the maximum depth, or the link
+// is its next or previous page with FollowPagination, and the link passes the rewrite rules, excluded paths, scope,
+// and domain rules applied to the links followed from pages. Pages already crawled, robots rules, domain depths,
+// and page budgets are not checked, so a link may be reported even though it is never visited.
+func (c *Crawler) Follows(page Page, link string) bool {
+	if c.options.SinglePage {
+		return false
+	}
+	pagination := c.options.FollowPagination && link != "" && (link == page.Next || link == page.Prev)
+	if !pagination && c.options.MaxDepth > 0 && page.Depth >= c.options.MaxDepth {
+		return false
+	}
+
+	absoluteURL := c.foldScheme(c.foldWWW(c.rewriteURL(link)))
+	if c.isExcludedPath(absoluteURL) || !c.inScope(absoluteURL) {
+		return false
+	}
+
+	parsedURL, err := url.Parse(absoluteURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return false
+	}
+	if len(c.collector.AllowedDomains) > 0 && !slices.Contains(c.collector.AllowedDomains, parsedURL.Host) {
+		return false
+	}
+	return c.isDomainAllowed(parsedURL) && !c.isExcludedOnDomain(parsedURL)
+}
+
+// visitSameDepth crawls a URL that stands in for or continues the current page, without consuming depth
+func (c *Crawler) visitSameDepth(request *colly.Request, targetURL string) {
+	if c.isExcludedPath(targetURL) {
+		return
+	}
+
+	sameDepthRequest, err := request.New(http.MethodGet, targetURL, nil)
+	if err != nil {
+		return
+	}
+	sameDepthRequest.Depth = request.Depth
+
+	// Visit is best effort, errors are logged via OnError callback
+	//nolint:errcheck // Intentionally ignoring error as it's handled by OnError callback
+	_ = sameDepthRequest.Do()
+}
+
+// extractCanonical returns the absolute canonical URL declared by the page, if any
+func extractCanonical(e *colly.HTMLElement) string {
+	href := strings.TrimSpace(e.ChildAttr(`link[rel~="canonical" i]`, "href"))
+	if href == "" {
+		return ""
+	}
+
+	absoluteURL := e.Request.AbsoluteURL(href)
+	parsedURL, err := url.Parse(absoluteURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return ""
+	}
+
+	parsedURL.Fragment = ""
+	return normalizeURL(parsedURL.String())
+}
+
+// extractAlternates returns the hreflang alternates declared by the page, keyed by language
+func extractAlternates(e *colly.HTMLElement) map[string]string {
+	var alternates map[string]string
+
+	e.ForEach(`link[rel~="alternate" i][hreflang]`, func(_ int, link *colly.HTMLElement) {
+		hreflang := strings.TrimSpace(link.Attr("hreflang"))
+		href := strings.TrimSpace(link.Attr("href"))
+		if hreflang == "" || href == "" {
+			return
+		}
+
+		if alternates == nil {
+			alternates = make(map[string]string)
+		}
+		alternates[hreflang] = e.Request.AbsoluteURL(href)
+	})
+
+	return alternates
+}
+
+// isOtherPage reports whether canonical points to a different page on the same host as pageURL
+func isOtherPage(canonical, pageURL string) bool {
+	if canonical == "" {
+		return false
+	}
+
+	canonicalURL, err := url.Parse(canonical)
+	if err != nil {
+		return false
+	}
+	parsedPage, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+
+	if !strings.EqualFold(canonicalURL.Host, parsedPage.Host) {
+		return false
+	}
+
+	return strings.TrimSuffix(canonicalURL.Path, "/") != strings.TrimSuffix(parsedPage.Path, "/") ||
+		canonicalURL.RawQuery != parsedPage.RawQuery
+}
+
+// markCrawled records a page URL and reports whether it was not crawled before
+func (c *Crawler) markCrawled(pageURL string) bool {
+	c.pagesMutex.Lock()
+	defer c.pagesMutex.Unlock()
+
+	if c.crawled[pageURL] {
+		return false
+	}
+	c.crawled[pageURL] = true
+	return true
+}
+
+// GetPages returns all crawled pages in crawl order.
+// Pages kept in SpillDir are all read back into memory; use OnPage to handle them one at a time.
 func (c *Crawler) GetPages() []Page {
 	c.pagesMutex.Lock()
 	defer c.pagesMutex.Unlock()
-	return c.pages
+
+	pages := make([]Page, 0, c.pages.Len())
+	for _, key := range c.pages.Keys() {
+		page, ok, err := c.pages.Get(key)
+		if err != nil {
+			c.logf(VerbosityQuiet, "Failed to read crawled page: %v\n", err)
+			continue
+		}
+		if ok {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
+
+// Close releases the pages kept by the crawler, removing the database file in SpillDir
+func (c *Crawler) Close() error {
+	c.pagesMutex.Lock()
+	defer c.pagesMutex.Unlock()
+	return c.pages.Close()
 }
 
 // normalizeURL normalizes URL by sorting query parameters alphabetically
@@ -253,7 +851,12 @@ func normalizeURL(rawURL string) string {
 
 // isExcludedPath checks if a URL path should be excluded
 func (c *Crawler) isExcludedPath(rawURL string) bool {
-	if len(c.options.ExcludedPaths) == 0 {
+	return MatchesExcludedPath(rawURL, c.options.ExcludedPaths)
+}
+
+// MatchesExcludedPath reports whether a URL starts with one of the excluded prefixes
+func MatchesExcludedPath(rawURL string, excludedPaths []string) bool {
+	if len(excludedPaths) == 0 {
 		return false
 	}
 
@@ -264,7 +867,7 @@ func (c *Crawler) isExcludedPath(rawURL string) bool {
 
 	fullPath := parsedURL.Scheme + "://" + parsedURL.Host + parsedURL.Path
 
-	for _, excluded := range c.options.ExcludedPaths {
+	for _, excluded := range excludedPaths {
 		if strings.HasPrefix(fullPath, excluded) || strings.HasPrefix(rawURL, excluded) {
 			return true
 		}
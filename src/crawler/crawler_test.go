@@ -3,6 +3,9 @@ package crawler
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
 	"testing"
 )
 
@@ -137,6 +140,9 @@ func TestCrawlerSinglePageMode(t *testing.T) {
 	if pages[0].Title != "Index" {
 		t.Fatalf("Unexpected page fetched: %s", pages[0].Title)
 	}
+	if len(pages[0].Links) != 1 || pages[0].Links[0] != srv.URL+"/next" {
+		t.Fatalf("Unexpected page links: %v", pages[0].Links)
+	}
 
 	// Non-single mode: should fetch both pages when following links
 	opts2 := Options{}
@@ -154,3 +160,176 @@ func TestCrawlerSinglePageMode(t *testing.T) {
 		t.Fatalf("Normal mode expected at least 2 pages, got %d", len(pages2))
 	}
 }
+
+func TestCrawlerContentHooks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Index</title></head><body><a href="/skip">Skip</a><main><p>Index content</p></main></body></html>`))
+	})
+	mux.HandleFunc("/skip", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Skip</title></head><body><main><p>Skip me</p></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	c.AddContentHook(func(page Page) (ContentAction, string) {
+		if page.Title == "Skip" {
+			return ContentSkip, ""
+		}
+		return ContentTransform, "<p>Transformed</p>"
+	})
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	pages := c.GetPages()
+	if len(pages) != 1 {
+		t.Fatalf("expected 1 page after skip hook, got %d", len(pages))
+	}
+	if pages[0].Content != "<p>Transformed</p>" {
+		t.Errorf("expected transformed content, got %q", pages[0].Content)
+	}
+}
+
+func TestCrawlerOnError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Index</title></head><body><main><a href="/missing">Missing</a></main></body></html>`))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	var failed []string
+	var failedMutex sync.Mutex
+	c.OnError(func(pageURL string, err error) {
+		failedMutex.Lock()
+		defer failedMutex.Unlock()
+		failed = append(failed, pageURL)
+	})
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	if len(failed) != 1 || failed[0] != srv.URL+"/missing" {
+		t.Errorf("OnError() reported %v, want [%s/missing]", failed, srv.URL)
+	}
+}
+
+func TestCrawlerDeclaredLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		body   string
+		want   string
+	}{
+		{name: "html lang attribute", body: `<html lang="de-DE"><body><main>Hallo</main></body></html>`, want: "de-DE"},
+		{name: "content-language meta", body: `<html><head><meta http-equiv="Content-Language" content="fr"></head><body><main>Bonjour</main></body></html>`, want: "fr"},
+		{name: "content-language header", header: "it", body: `<html><body><main>Ciao</main></body></html>`, want: "it"},
+		{name: "undeclared", body: `<html><body><main>Hello</main></body></html>`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.header != "" {
+					w.Header().Set("Content-Language", tt.header)
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c, err := NewCrawler(srv.URL, Options{SinglePage: true})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			pages := c.GetPages()
+			if len(pages) != 1 || pages[0].Lang != tt.want {
+				t.Errorf("Lang = %+v, want %q", pages, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerCanonicalOnly(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Index</title></head><body><main><a href="/article?utm_source=feed">Article</a></main></body></html>`))
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>Article</title>
+<link rel="canonical" href="/article">
+<link rel="alternate" hreflang="de" href="/de/article">
+<link rel="alternate" hreflang="x-default" href="https://example.com/article">
+</head><body><main>Article</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name          string
+		canonicalOnly bool
+		wantURLs      []string
+	}{
+		{name: "variants kept by default", wantURLs: []string{srv.URL, srv.URL + "/article?utm_source=feed"}},
+		{name: "canonical only", canonicalOnly: true, wantURLs: []string{srv.URL, srv.URL + "/article"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler(srv.URL, Options{CanonicalOnly: tt.canonicalOnly})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			var urls []string
+			var article Page
+			for _, page := range c.GetPages() {
+				urls = append(urls, page.URL)
+				if page.Title == "Article" {
+					article = page
+				}
+			}
+			sort.Strings(urls)
+
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("crawled %v, want %v", urls, tt.wantURLs)
+			}
+
+			if article.Canonical != srv.URL+"/article" {
+				t.Errorf("Canonical = %q, want %q", article.Canonical, srv.URL+"/article")
+			}
+
+			wantAlternates := map[string]string{"de": srv.URL + "/de/article", "x-default": "https://example.com/article"}
+			if !reflect.DeepEqual(article.Alternates, wantAlternates) {
+				t.Errorf("Alternates = %v, want %v", article.Alternates, wantAlternates)
+			}
+		})
+	}
+}
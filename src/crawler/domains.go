@@ -0,0 +1,220 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// defaultParallelism is the number of requests sent at the same time to each domain when Options.Parallelism is not set
+const defaultParallelism = 2
+
+// DomainOptions overrides crawl settings for the pages of a domain and its subdomains
+type DomainOptions struct {
+	Domain         string   `json:"domain"`                    // Host name, optionally with a port
+	MaxDepth       int      `json:"max_depth,omitempty"`       // Maximum pages deep into the domain, counting the first page reached on it as 1
+	RequestDelay   int      `json:"delay,omitempty"`           // Delay in seconds between requests to the domain
+	StripSelectors []string `json:"strip_selectors,omitempty"` // Extra CSS selectors of elements removed before content extraction
+	ExcludedPaths  []string `json:"exclude,omitempty"`         // URL path prefixes not crawled on the domain
+}
+
+// Validate checks that the domain options are well formed
+func (d DomainOptions) Validate() error {
+	if strings.TrimSpace(d.Domain) == "" || strings.Contains(d.Domain, "/") {
+		return fmt.Errorf("domain must be a host name, got %q", d.Domain)
+	}
+
+	if d.MaxDepth < 0 || d.RequestDelay < 0 {
+		return fmt.Errorf("max_depth and delay for %s cannot be negative", d.Domain)
+	}
+
+	for _, selector := range d.StripSelectors {
+		if err := ValidateSelector(selector); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MatchesDomain reports whether a URL host is the domain or one of its subdomains.
+// Domains with a port only match that port.
+func MatchesDomain(host, domain string) bool {
+	host = strings.ToLower(host)
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+
+	if !strings.Contains(domain, ":") {
+		host = strings.Split(host, ":")[0]
+	}
+
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// matchesAnyDomain reports whether a host matches one of the domains
+func matchesAnyDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if MatchesDomain(host, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitRules returns the rate limit rules, per-domain rules first as colly applies the first matching rule
+func limitRules(opts Options) []*colly.LimitRule {
+	var rules []*colly.LimitRule
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+
+	for _, domain := range opts.Domains {
+		if domain.RequestDelay <= 0 {
+			continue
+		}
+
+		delay := time.Duration(domain.RequestDelay) * time.Second
+		for _, glob := range []string{domain.Domain, "*." + domain.Domain} {
+			rules = append(rules, &colly.LimitRule{
+				DomainGlob:  glob,
+				Delay:       delay,
+				RandomDelay: randomDelay(opts.Jitter, domain.RequestDelay),
+				Parallelism: parallelism,
+			})
+		}
+	}
+
+	defaultRule := &colly.LimitRule{
+		DomainGlob:  "*",
+		Parallelism: parallelism,
+		RandomDelay: randomDelay(opts.Jitter, opts.RequestDelay),
+	}
+	if opts.RequestDelay > 0 {
+		defaultRule.Delay = time.Duration(opts.RequestDelay) * time.Second
+	}
+
+	return append(rules, defaultRule)
+}
+
+// randomDelay returns the maximum random time added to a delay in seconds: the jitter when set,
+// otherwise half of the delay
+func randomDelay(jitter time.Duration, delay int) time.Duration {
+	switch {
+	case jitter > 0:
+		return jitter
+	case jitter < 0:
+		return 0
+	}
+	return time.Duration(delay/2) * time.Second
+}
+
+// domainOptions returns the options of the most specific domain matching the host, or nil
+func (c *Crawler) domainOptions(host string) *DomainOptions {
+	var best *DomainOptions
+	for i := range c.options.Domains {
+		domain := &c.options.Domains[i]
+		if MatchesDomain(host, domain.Domain) && (best == nil || len(domain.Domain) > len(best.Domain)) {
+			best = domain
+		}
+	}
+	return best
+}
+
+// isExternal reports whether a host is outside the crawled site
+func (c *Crawler) isExternal(host string) bool {
+	return !c.isSameSite(host)
+}
+
+// isDomainAllowed applies the external domain allowlist and the denylist to a URL
+func (c *Crawler) isDomainAllowed(parsedURL *url.URL) bool {
+	if matchesAnyDomain(parsedURL.Host, c.options.DeniedDomains) {
+		return false
+	}
+
+	if !c.isExternal(parsedURL.Host) {
+		return true
+	}
+
+	// Without external links or allowed domains, subdomains limit the crawl here instead of in colly
+	if c.options.IncludeSubdomains && !c.options.FollowExternalLinks && len(c.options.AllowedDomains) == 0 {
+		return false
+	}
+
+	if len(c.options.ExternalDomains) > 0 {
+		return matchesAnyDomain(parsedURL.Host, c.options.ExternalDomains)
+	}
+
+	return true
+}
+
+// domainDepthLimit returns the maximum depth into the domain of a host, 0 when unlimited
+func (c *Crawler) domainDepthLimit(host string) int {
+	if domain := c.domainOptions(host); domain != nil && domain.MaxDepth > 0 {
+		return domain.MaxDepth
+	}
+	if c.isExternal(host) {
+		return c.options.ExternalDepth
+	}
+	return 0
+}
+
+// allowDomainDepth records the depth of a link target within its domain and reports whether it may be crawled.
+// The depth grows while links stay on the same host and restarts at 1 on another host.
+func (c *Crawler) allowDomainDepth(from *url.URL, target string) bool {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+
+	c.domainDepthsMutex.Lock()
+	defer c.domainDepthsMutex.Unlock()
+
+	depth := 1
+	if strings.EqualFold(from.Host, targetURL.Host) {
+		fromDepth, ok := c.domainDepths[normalizeURL(from.String())]
+		if !ok {
+			fromDepth = 1
+		}
+		depth = fromDepth + 1
+	}
+
+	if limit := c.domainDepthLimit(targetURL.Host); limit > 0 && depth > limit {
+		return false
+	}
+
+	key := normalizeURL(targetURL.String())
+	if existing, ok := c.domainDepths[key]; !ok || depth < existing {
+		c.domainDepths[key] = depth
+	}
+
+	return true
+}
+
+// isExcludedOnDomain reports whether a URL path is excluded by the options of its domain
+func (c *Crawler) isExcludedOnDomain(parsedURL *url.URL) bool {
+	domain := c.domainOptions(parsedURL.Host)
+	if domain == nil {
+		return false
+	}
+
+	for _, prefix := range domain.ExcludedPaths {
+		if strings.HasPrefix(parsedURL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripSelectorsFor returns the strip selectors for a host, including its domain specific ones
+func (c *Crawler) stripSelectorsFor(host string) []string {
+	domain := c.domainOptions(host)
+	if domain == nil || len(domain.StripSelectors) == 0 {
+		return c.options.StripSelectors
+	}
+
+	return append(append([]string{}, c.options.StripSelectors...), domain.StripSelectors...)
+}
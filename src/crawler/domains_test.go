@@ -0,0 +1,275 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchesDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		host   string
+		domain string
+		want   bool
+	}{
+		{name: "same host", host: "example.com", domain: "example.com", want: true},
+		{name: "subdomain", host: "docs.example.com", domain: "example.com", want: true},
+		{name: "port ignored without port in domain", host: "example.com:8080", domain: "example.com", want: true},
+		{name: "case insensitive", host: "Docs.Example.com", domain: "example.COM", want: true},
+		{name: "suffix without dot", host: "badexample.com", domain: "example.com", want: false},
+		{name: "other domain", host: "example.org", domain: "example.com", want: false},
+		{name: "port must match", host: "localhost:8080", domain: "localhost:9090", want: false},
+		{name: "port matches", host: "localhost:8080", domain: "localhost:8080", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesDomain(tt.host, tt.domain); got != tt.want {
+				t.Errorf("MatchesDomain(%q, %q) = %v, want %v", tt.host, tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		domain  DomainOptions
+		wantErr bool
+	}{
+		{name: "valid", domain: DomainOptions{Domain: "example.com", MaxDepth: 1, StripSelectors: []string{".ad"}}},
+		{name: "empty domain", domain: DomainOptions{}, wantErr: true},
+		{name: "url instead of host", domain: DomainOptions{Domain: "https://example.com/"}, wantErr: true},
+		{name: "negative depth", domain: DomainOptions{Domain: "example.com", MaxDepth: -1}, wantErr: true},
+		{name: "invalid selector", domain: DomainOptions{Domain: "example.com", StripSelectors: []string{"div["}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.domain.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLimitRules(t *testing.T) {
+	rules := limitRules(Options{
+		RequestDelay: 1,
+		Domains: []DomainOptions{
+			{Domain: "slow.example.com", RequestDelay: 5},
+			{Domain: "example.org", MaxDepth: 1},
+		},
+	})
+
+	var globs []string
+	for _, rule := range rules {
+		globs = append(globs, rule.DomainGlob)
+	}
+
+	wantGlobs := []string{"slow.example.com", "*.slow.example.com", "*"}
+	if !reflect.DeepEqual(globs, wantGlobs) {
+		t.Fatalf("DomainGlobs = %v, want %v", globs, wantGlobs)
+	}
+
+	if rules[0].Delay != 5*time.Second {
+		t.Errorf("domain Delay = %v, want %v", rules[0].Delay, 5*time.Second)
+	}
+
+	if last := rules[len(rules)-1]; last.Delay != time.Second || last.Parallelism != 2 {
+		t.Errorf("default rule = %+v, want Delay 1s and Parallelism 2", last)
+	}
+}
+
+func TestLimitRulesJitter(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            Options
+		wantDomain      time.Duration
+		wantDefault     time.Duration
+		wantParallelism int
+	}{
+		{
+			name:            "derives half of the delays",
+			opts:            Options{RequestDelay: 2},
+			wantDomain:      2 * time.Second,
+			wantDefault:     time.Second,
+			wantParallelism: 2,
+		},
+		{
+			name:            "uses the jitter for all rules",
+			opts:            Options{RequestDelay: 2, Jitter: 300 * time.Millisecond, Parallelism: 4},
+			wantDomain:      300 * time.Millisecond,
+			wantDefault:     300 * time.Millisecond,
+			wantParallelism: 4,
+		},
+		{
+			name:            "keeps the jitter without a delay",
+			opts:            Options{Jitter: time.Second},
+			wantDomain:      time.Second,
+			wantDefault:     time.Second,
+			wantParallelism: 2,
+		},
+		{
+			name:            "disables the jitter when negative",
+			opts:            Options{RequestDelay: 2, Jitter: -1},
+			wantParallelism: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Domains = []DomainOptions{{Domain: "slow.example.com", RequestDelay: 4}}
+			rules := limitRules(tt.opts)
+
+			if got := rules[0].RandomDelay; got != tt.wantDomain {
+				t.Errorf("domain RandomDelay = %v, want %v", got, tt.wantDomain)
+			}
+			last := rules[len(rules)-1]
+			if last.RandomDelay != tt.wantDefault {
+				t.Errorf("default RandomDelay = %v, want %v", last.RandomDelay, tt.wantDefault)
+			}
+			for _, rule := range rules {
+				if rule.Parallelism != tt.wantParallelism {
+					t.Errorf("%s Parallelism = %d, want %d", rule.DomainGlob, rule.Parallelism, tt.wantParallelism)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlerExternalDomains(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// The same server is reached as 127.0.0.1 for the start domain and as localhost for the external one
+	externalURL := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+	externalHost := strings.TrimPrefix(externalURL, "http://")
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Host, "localhost") {
+			_, _ = w.Write([]byte(`<html><body><main>External home</main></body></html>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><main>
+<a href="/local">Local</a>
+<a href="` + externalURL + `/ext/a">External</a>
+<a href="` + externalURL + `/private/x">Private</a>
+</main></body></html>`))
+	})
+	mux.HandleFunc("/local", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>Local</main></body></html>`))
+	})
+	mux.HandleFunc("/ext/", func(w http.ResponseWriter, r *http.Request) {
+		next := map[string]string{"/ext/a": "/ext/b", "/ext/b": "/ext/c"}[r.URL.Path]
+		_, _ = w.Write([]byte(`<html><body><main>External <a href="` + next + `">next</a></main></body></html>`))
+	})
+	mux.HandleFunc("/private/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>Private</main></body></html>`))
+	})
+
+	base := Options{MaxDepth: 10, FollowExternalLinks: true}
+
+	tests := []struct {
+		name     string
+		modify   func(*Options)
+		wantURLs []string
+	}{
+		{
+			name:   "external depth",
+			modify: func(o *Options) { o.ExternalDepth = 1 },
+			wantURLs: []string{
+				srv.URL, srv.URL + "/local", externalURL + "/ext/a", externalURL + "/private/x",
+			},
+		},
+		{
+			name: "domain options",
+			modify: func(o *Options) {
+				o.Domains = []DomainOptions{{Domain: externalHost, MaxDepth: 2, ExcludedPaths: []string{"/private/"}}}
+			},
+			wantURLs: []string{
+				srv.URL, srv.URL + "/local", externalURL + "/ext/a", externalURL + "/ext/b",
+			},
+		},
+		{
+			name:     "denied domain",
+			modify:   func(o *Options) { o.DeniedDomains = []string{"localhost"} },
+			wantURLs: []string{srv.URL, srv.URL + "/local"},
+		},
+		{
+			name:     "allowlist",
+			modify:   func(o *Options) { o.ExternalDomains = []string{"example.com"} },
+			wantURLs: []string{srv.URL, srv.URL + "/local"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.modify(&opts)
+
+			c, err := NewCrawler(srv.URL, opts)
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			var urls []string
+			for _, page := range c.GetPages() {
+				urls = append(urls, page.URL)
+			}
+			sort.Strings(urls)
+			sort.Strings(tt.wantURLs)
+
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("crawled %v, want %v", urls, tt.wantURLs)
+			}
+		})
+	}
+}
+
+func TestCrawlerDomainOptionsLookup(t *testing.T) {
+	c, err := NewCrawler("https://example.com", Options{
+		StripSelectors: []string{".cookie"},
+		Domains: []DomainOptions{
+			{Domain: "example.org", StripSelectors: []string{".ad"}, ExcludedPaths: []string{"/tag/"}},
+			{Domain: "docs.example.org", StripSelectors: []string{".banner"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	got := c.stripSelectorsFor("docs.example.org")
+	if want := []string{".cookie", ".banner"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stripSelectorsFor() = %v, want %v", got, want)
+	}
+
+	if got := c.stripSelectorsFor("example.com"); !reflect.DeepEqual(got, []string{".cookie"}) {
+		t.Errorf("stripSelectorsFor() = %v, want %v", got, []string{".cookie"})
+	}
+
+	for rawURL, want := range map[string]bool{
+		"https://www.example.org/tag/go":  true,
+		"https://docs.example.org/tag/go": false,
+		"https://example.com/tag/go":      false,
+	} {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatalf("url.Parse(%q) unexpected error: %v", rawURL, err)
+		}
+		if got := c.isExcludedOnDomain(parsed); got != want {
+			t.Errorf("isExcludedOnDomain(%s) = %v, want %v", rawURL, got, want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package crawler
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/sandrolain/crawldown/src/urlmatch"
+)
+
+// EmbedPolicy tells the crawler what to do with an iframe or embedded object
+type EmbedPolicy string
+
+const (
+	// EmbedLink replaces the embed with a link to its URL
+	EmbedLink EmbedPolicy = "link"
+	// EmbedInline replaces a same-site embed with the main content of the embedded page, other embeds become links
+	EmbedInline EmbedPolicy = "inline"
+	// EmbedDrop removes the embed
+	EmbedDrop EmbedPolicy = "drop"
+)
+
+// embedSelector matches the elements handled by the embed policy
+const embedSelector = "iframe[src], embed[src], object[data]"
+
+// ValidateEmbedPolicy checks that the policy is one of the supported values
+func ValidateEmbedPolicy(policy EmbedPolicy) error {
+	switch policy {
+	case EmbedLink, EmbedInline, EmbedDrop:
+		return nil
+	default:
+		return fmt.Errorf("unknown embed policy %q: expected %s, %s, or %s", policy, EmbedLink, EmbedInline, EmbedDrop)
+	}
+}
+
+// EmbedRule selects the policy for the embeds whose URL matches a pattern
+type EmbedRule struct {
+	Pattern string      // Glob matched against the embed URL path, or the full URL if it contains "://"
+	Policy  EmbedPolicy // Policy applied to matching embeds
+}
+
+// ParseEmbedRule parses a "pattern=policy" rule such as "https://www.youtube.com/*=link"
+func ParseEmbedRule(value string) (EmbedRule, error) {
+	separator := strings.LastIndex(value, "=")
+	if separator <= 0 {
+		return EmbedRule{}, fmt.Errorf("invalid embed rule %q: expected pattern=policy", value)
+	}
+
+	policy := EmbedPolicy(strings.TrimSpace(value[separator+1:]))
+	if err := ValidateEmbedPolicy(policy); err != nil {
+		return EmbedRule{}, fmt.Errorf("invalid embed rule %q: %w", value, err)
+	}
+
+	pattern := strings.TrimSpace(value[:separator])
+	if _, err := urlmatch.Compile(pattern); err != nil {
+		return EmbedRule{}, fmt.Errorf("invalid embed rule %q: %w", value, err)
+	}
+
+	return EmbedRule{Pattern: pattern, Policy: policy}, nil
+}
+
+// compiledEmbedRule is an embed rule with its pattern compiled
+type compiledEmbedRule struct {
+	pattern *urlmatch.Pattern
+	policy  EmbedPolicy
+}
+
+// compileEmbedRules compiles the embed rule patterns
+func compileEmbedRules(rules []EmbedRule) ([]compiledEmbedRule, error) {
+	compiled := make([]compiledEmbedRule, 0, len(rules))
+	for _, rule := range rules {
+		if err := ValidateEmbedPolicy(rule.Policy); err != nil {
+			return nil, err
+		}
+		pattern, err := urlmatch.Compile(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, compiledEmbedRule{pattern: pattern, policy: rule.Policy})
+	}
+	return compiled, nil
+}
+
+// embedPolicy returns the policy of the first rule matching the embed URL, or the default policy
+func (c *Crawler) embedPolicy(embedURL string) EmbedPolicy {
+	for _, rule := range c.embedRules {
+		if rule.pattern.Match(embedURL) {
+			return rule.policy
+		}
+	}
+	return c.options.EmbedPolicy
+}
+
+// resolveEmbeds applies the embed policy to the iframes and embedded objects of the page content.
+// Without a default policy or rules, the content is returned unchanged.
+func (c *Crawler) resolveEmbeds(content string, pageURL *url.URL) string {
+	if c.options.EmbedPolicy == "" && len(c.embedRules) == 0 {
+		return content
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+
+	embeds := doc.Find(embedSelector)
+	if embeds.Length() == 0 {
+		return content
+	}
+
+	embeds.Each(func(_ int, embed *goquery.Selection) {
+		source := embed.AttrOr("src", embed.AttrOr("data", ""))
+		reference, err := url.Parse(strings.TrimSpace(source))
+		if err != nil {
+			return
+		}
+		embedURL := pageURL.ResolveReference(reference)
+		if embedURL.Scheme != "http" && embedURL.Scheme != "https" {
+			return
+		}
+
+		switch c.embedPolicy(embedURL.String()) {
+		case EmbedDrop:
+			embed.Remove()
+		case EmbedInline:
+			if c.isSameSite(embedURL.Host) {
+				if inlined, err := c.fetchEmbed(embedURL.String()); err == nil && inlined != "" {
+					embed.ReplaceWithHtml("<div>" + inlined + "</div>")
+					return
+				}
+			}
+			embed.ReplaceWithHtml(embedLink(embed, embedURL))
+		case EmbedLink:
+			embed.ReplaceWithHtml(embedLink(embed, embedURL))
+		}
+	})
+
+	resolved, err := doc.Find("body").Html()
+	if err != nil {
+		return content
+	}
+	return resolved
+}
+
+// embedLink returns a paragraph linking to the page of an embed, labelled with its title or provider
+func embedLink(embed *goquery.Selection, embedURL *url.URL) string {
+	target, provider := embedPage(embedURL)
+
+	label := strings.Join(strings.Fields(embed.AttrOr("title", embed.AttrOr("aria-label", ""))), " ")
+	if label == "" {
+		label = provider
+	}
+	if label == "" {
+		label = target
+	}
+
+	return `<p><a href="` + html.EscapeString(target) + `">` + html.EscapeString(label) + `</a></p>`
+}
+
+// embedPage maps the embed URL of well-known providers to the page a reader can open, and names the provider
+func embedPage(embedURL *url.URL) (string, string) {
+	host := strings.TrimPrefix(strings.ToLower(embedURL.Hostname()), "www.")
+	segments := strings.Split(strings.Trim(embedURL.Path, "/"), "/")
+
+	switch {
+	case (host == "youtube.com" || host == "youtube-nocookie.com") && len(segments) == 2 && segments[0] == "embed":
+		return "https://www.youtube.com/watch?v=" + url.QueryEscape(segments[1]), "YouTube video"
+	case host == "player.vimeo.com" && len(segments) == 2 && segments[0] == "video":
+		return "https://vimeo.com/" + segments[1], "Vimeo video"
+	case host == "codepen.io" && len(segments) == 3 && segments[1] == "embed":
+		return "https://codepen.io/" + segments[0] + "/pen/" + segments[2], "CodePen"
+	case (host == "google.com" || strings.HasPrefix(host, "maps.google.")) && strings.HasPrefix(embedURL.Path, "/maps"):
+		return embedURL.String(), "Google Maps"
+	default:
+		return embedURL.String(), ""
+	}
+}
+
+// fetchEmbed downloads a same-site embedded page and returns its main content
+func (c *Crawler) fetchEmbed(embedURL string) (string, error) {
+	request, err := http.NewRequest(http.MethodGet, embedURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create embed request: %w", err)
+	}
+	request.Header.Set("User-Agent", c.options.UserAgent)
+
+	response, err := c.embedClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch embed: %w", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch embed: status %d", response.StatusCode)
+	}
+	contentType := response.Header.Get("Content-Type")
+	if !isHTML(contentType) {
+		return "", fmt.Errorf("failed to fetch embed: not an HTML page")
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read embed: %w", err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(toUTF8(body, contentType)))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse embed: %w", err)
+	}
+
+	return mainContent(doc.Selection), nil
+}
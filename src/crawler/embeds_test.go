@@ -0,0 +1,108 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseEmbedRule(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    EmbedRule
+		wantErr bool
+	}{
+		{value: "https://www.youtube.com/*=link", want: EmbedRule{Pattern: "https://www.youtube.com/*", Policy: EmbedLink}},
+		{value: "/widgets/* = inline", want: EmbedRule{Pattern: "/widgets/*", Policy: EmbedInline}},
+		{value: "https://ads.example.com/*=drop", want: EmbedRule{Pattern: "https://ads.example.com/*", Policy: EmbedDrop}},
+		{value: "/widgets/*", wantErr: true},
+		{value: "=link", wantErr: true},
+		{value: "/widgets/*=keep", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseEmbedRule(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEmbedRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseEmbedRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerEmbeds(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><h1>Guide</h1>` +
+			`<iframe src="https://www.youtube.com/embed/abc123"></iframe>` +
+			`<iframe src="https://codepen.io/team/embed/xyz" title="Button demo"></iframe>` +
+			`<iframe src="/widgets/table"></iframe>` +
+			`<iframe src="https://ads.example.net/banner"></iframe>` +
+			`</main></body></html>`))
+	})
+	mux.HandleFunc("/widgets/table", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><table><tr><td>Inlined cell</td></tr></table></main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{
+		SinglePage:  true,
+		EmbedPolicy: EmbedLink,
+		EmbedRules: []EmbedRule{
+			{Pattern: "/widgets/*", Policy: EmbedInline},
+			{Pattern: "https://ads.example.net/*", Policy: EmbedDrop},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	pages := c.GetPages()
+	if len(pages) != 1 {
+		t.Fatalf("GetPages() returned %d pages, want 1", len(pages))
+	}
+	content := pages[0].Content
+
+	for _, want := range []string{
+		`<a href="https://www.youtube.com/watch?v=abc123">YouTube video</a>`,
+		`<a href="https://codepen.io/team/pen/xyz">Button demo</a>`,
+		`<td>Inlined cell</td>`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Content = %q, want it to contain %q", content, want)
+		}
+	}
+	if strings.Contains(content, "<iframe") || strings.Contains(content, "ads.example.net") {
+		t.Errorf("Content = %q, want no iframes left", content)
+	}
+}
+
+func TestCrawlerEmbedsUnchangedWithoutPolicy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><iframe src="https://www.youtube.com/embed/abc123"></iframe></main></body></html>`))
+	}))
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{SinglePage: true})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	if pages := c.GetPages(); len(pages) != 1 || !strings.Contains(pages[0].Content, "<iframe") {
+		t.Errorf("GetPages() = %+v, want the iframe kept", pages)
+	}
+}
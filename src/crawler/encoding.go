@@ -0,0 +1,173 @@
+package crawler
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content encodings the crawler can decode
+const (
+	EncodingGzip     = "gzip"
+	EncodingDeflate  = "deflate"
+	EncodingBrotli   = "br"
+	EncodingZstd     = "zstd"
+	EncodingIdentity = "identity" // Asks for uncompressed responses
+)
+
+// DefaultEncodings are the content encodings accepted when Options.AcceptEncodings is empty
+var DefaultEncodings = []string{EncodingGzip, EncodingDeflate, EncodingBrotli, EncodingZstd}
+
+// ValidateEncoding checks that a content encoding can be decoded
+func ValidateEncoding(encoding string) error {
+	switch encoding {
+	case EncodingGzip, EncodingDeflate, EncodingBrotli, EncodingZstd, EncodingIdentity:
+		return nil
+	default:
+		return fmt.Errorf("unsupported content encoding %q: expected %s, %s, %s, %s, or %s",
+			encoding, EncodingGzip, EncodingDeflate, EncodingBrotli, EncodingZstd, EncodingIdentity)
+	}
+}
+
+// decodingTransport negotiates the accepted content encodings and decodes compressed responses,
+// so that the crawler always sees the decoded body
+type decodingTransport struct {
+	base   http.RoundTripper
+	accept string // Value of the Accept-Encoding header
+}
+
+// newDecodingTransport creates a transport accepting the given encodings, DefaultEncodings when empty
+func newDecodingTransport(base http.RoundTripper, encodings []string) (*decodingTransport, error) {
+	if len(encodings) == 0 {
+		encodings = DefaultEncodings
+	}
+	for _, encoding := range encodings {
+		if err := ValidateEncoding(encoding); err != nil {
+			return nil, err
+		}
+	}
+	return &decodingTransport{base: base, accept: strings.Join(encodings, ", ")}, nil
+}
+
+// RoundTrip sends the request with the Accept-Encoding header and decodes the response body
+func (t *decodingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", t.accept)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// decodeBody replaces the body of a response by its decoded content and removes the encoding headers.
+// Encodings listed in Content-Encoding are undone in reverse order of application.
+func decodeBody(resp *http.Response) error {
+	header := resp.Header.Get("Content-Encoding")
+	if header == "" {
+		return nil
+	}
+	if !hasBody(resp) {
+		// Nothing to decode, but colly would still open a gzip reader on the empty body
+		resp.Header.Del("Content-Encoding")
+		resp.Uncompressed = true
+		return nil
+	}
+
+	encodings := strings.Split(header, ",")
+	body := &decodedBody{Reader: resp.Body, closers: []io.Closer{resp.Body}}
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		decoded, err := decoder(encoding, body.Reader)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", encoding, err)
+		}
+		if closer, ok := decoded.(io.Closer); ok && decoded != body.Reader {
+			body.closers = append(body.closers, closer)
+		}
+		body.Reader = decoded
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// hasBody reports whether a response may carry a body. Responses without one can still declare
+// the Content-Encoding of the representation, which must not be decoded from an empty body.
+func hasBody(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode >= 100 && resp.StatusCode < 200,
+		resp.StatusCode == http.StatusNoContent,
+		resp.StatusCode == http.StatusNotModified:
+		return false
+	case resp.Request != nil && resp.Request.Method == http.MethodHead:
+		return false
+	}
+	return resp.ContentLength != 0
+}
+
+// decoder returns a reader decoding one content encoding
+func decoder(encoding string, r io.Reader) (io.Reader, error) {
+	switch encoding {
+	case EncodingIdentity, "":
+		return r, nil
+	case EncodingGzip, "x-gzip":
+		return gzip.NewReader(r)
+	case EncodingDeflate:
+		// Deflate is specified as zlib-wrapped, but some servers send raw deflate data
+		buffered := bufio.NewReader(r)
+		header, err := buffered.Peek(2)
+		if err == nil && isZlibHeader(header) {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	case EncodingBrotli:
+		return brotli.NewReader(r), nil
+	case EncodingZstd:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// isZlibHeader reports whether two bytes are a zlib header using the deflate method
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+// decodedBody reads decoded content and closes the decoders and the original body
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer // Original body first, then the decoders in the order they were created
+}
+
+func (b *decodedBody) Close() error {
+	for i := len(b.closers) - 1; i > 0; i-- {
+		_ = b.closers[i].Close()
+	}
+	return b.closers[0].Close()
+}
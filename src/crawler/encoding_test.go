@@ -0,0 +1,234 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+const encodedPage = `<html><head><title>Encoded</title></head><body><main><p>Compressed content</p></main></body></html>`
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		fw, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			t.Fatalf("flate.NewWriter() unexpected error: %v", err)
+		}
+		w = fw
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "zstd":
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatalf("zstd.NewWriter() unexpected error: %v", err)
+		}
+		w = zw
+	default:
+		t.Fatalf("unknown encoding %q", encoding)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("compress %s: %v", encoding, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("compress %s: %v", encoding, err)
+	}
+	return buf.Bytes()
+}
+
+func TestCrawlerDecodesResponses(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string // Encoding used to compress the body
+		header   string // Content-Encoding sent by the server
+	}{
+		{name: "gzip", encoding: "gzip", header: "gzip"},
+		{name: "zlib deflate", encoding: "deflate", header: "deflate"},
+		{name: "raw deflate", encoding: "raw-deflate", header: "deflate"},
+		{name: "brotli", encoding: "br", header: "br"},
+		{name: "zstd", encoding: "zstd", header: "zstd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accepted string
+			body := compress(t, tt.encoding, []byte(encodedPage))
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accepted = r.Header.Get("Accept-Encoding")
+				w.Header().Set("Content-Type", "text/html")
+				w.Header().Set("Content-Encoding", tt.header)
+				_, _ = w.Write(body)
+			}))
+			defer srv.Close()
+
+			c, err := NewCrawler(srv.URL, Options{SinglePage: true})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			if accepted != "gzip, deflate, br, zstd" {
+				t.Errorf("Accept-Encoding = %q, want %q", accepted, "gzip, deflate, br, zstd")
+			}
+
+			pages := c.GetPages()
+			if len(pages) != 1 || !strings.Contains(pages[0].Content, "Compressed content") || pages[0].Title != "Encoded" {
+				t.Errorf("GetPages() = %+v, want the decoded page", pages)
+			}
+		})
+	}
+}
+
+func TestDecodeBodyStacked(t *testing.T) {
+	body := compress(t, "br", compress(t, "gzip", []byte(encodedPage)))
+	resp := &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Encoding": {"gzip, br"}, "Content-Length": {"10"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+
+	if err := decodeBody(resp); err != nil {
+		t.Fatalf("decodeBody() unexpected error: %v", err)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading decoded body: %v", err)
+	}
+	if string(data) != encodedPage {
+		t.Errorf("decodeBody() body = %q, want %q", data, encodedPage)
+	}
+	if resp.Header.Get("Content-Encoding") != "" || resp.Header.Get("Content-Length") != "" || !resp.Uncompressed {
+		t.Errorf("decodeBody() headers = %v, want the encoding headers removed", resp.Header)
+	}
+}
+
+func TestDecodeBodyUnsupported(t *testing.T) {
+	resp := &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Encoding": {"compress"}},
+		Body:          io.NopCloser(strings.NewReader("data")),
+		ContentLength: -1,
+	}
+
+	if err := decodeBody(resp); err == nil {
+		t.Error("decodeBody() expected an error for an unsupported encoding")
+	}
+}
+
+func TestDecodeBodyWithoutBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		method string
+		length int64
+	}{
+		{name: "not modified", status: http.StatusNotModified, method: http.MethodGet, length: -1},
+		{name: "no content", status: http.StatusNoContent, method: http.MethodGet, length: -1},
+		{name: "informational", status: http.StatusEarlyHints, method: http.MethodGet, length: -1},
+		{name: "head", status: http.StatusOK, method: http.MethodHead, length: 1024},
+		{name: "empty", status: http.StatusOK, method: http.MethodGet, length: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode:    tt.status,
+				Header:        http.Header{"Content-Encoding": {"gzip"}},
+				Body:          http.NoBody,
+				ContentLength: tt.length,
+				Request:       httptest.NewRequest(tt.method, "https://example.com/", nil),
+			}
+
+			if err := decodeBody(resp); err != nil {
+				t.Fatalf("decodeBody() unexpected error: %v", err)
+			}
+			if resp.Header.Get("Content-Encoding") != "" || !resp.Uncompressed {
+				t.Errorf("decodeBody() headers = %v, want the response marked as decoded", resp.Header)
+			}
+		})
+	}
+}
+
+func TestCrawlerNotModifiedEncoded(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><a href="/cached">Cached</a></main></body></html>`))
+	})
+	mux.HandleFunc("/cached", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusNotModified)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	var skipped []string
+	c.OnSkip(func(pageURL string, reason SkipReason) {
+		if reason == SkipUnchanged {
+			skipped = append(skipped, pageURL)
+		}
+	})
+	var failed []string
+	c.OnError(func(pageURL string, err error) {
+		failed = append(failed, pageURL)
+	})
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	if want := []string{srv.URL + "/cached"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("unchanged pages = %v, want %v", skipped, want)
+	}
+	if len(failed) != 0 {
+		t.Errorf("failed requests = %v, want none", failed)
+	}
+}
+
+func TestValidateEncoding(t *testing.T) {
+	tests := []struct {
+		encoding string
+		wantErr  bool
+	}{
+		{encoding: "gzip"},
+		{encoding: "br"},
+		{encoding: "zstd"},
+		{encoding: "identity"},
+		{encoding: "lzma", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			if err := ValidateEncoding(tt.encoding); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEncoding() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// Error types of a CrawlError
+const (
+	ErrorTypeHTTP    = "http"    // The server answered with an error status
+	ErrorTypeTimeout = "timeout" // The request or the connection timed out
+	ErrorTypeNetwork = "network" // DNS resolution, connection, or TLS failure
+	ErrorTypeOther   = "other"
+)
+
+// CrawlError is a request that failed during a crawl
+type CrawlError struct {
+	URL     string    `json:"url"`
+	Status  int       `json:"status,omitempty"` // HTTP status, 0 when no response was received
+	Type    string    `json:"type"`
+	Message string    `json:"error"`
+	Retries int       `json:"retries,omitempty"` // Number of times the request was retried before giving up
+	At      time.Time `json:"at"`
+}
+
+// ErrorRateError is returned by Start when the share of failed requests exceeds Options.MaxErrorRate.
+// The pages crawled successfully are still available.
+type ErrorRateError struct {
+	Errors    int
+	Requests  int
+	Threshold float64
+}
+
+// Rate returns the share of failed requests
+func (e *ErrorRateError) Rate() float64 {
+	if e.Requests == 0 {
+		return 0
+	}
+	return float64(e.Errors) / float64(e.Requests)
+}
+
+func (e *ErrorRateError) Error() string {
+	return fmt.Sprintf("error rate %.1f%% (%d of %d requests) exceeds %.1f%%", e.Rate()*100, e.Errors, e.Requests, e.Threshold*100)
+}
+
+// classifyError returns the type of a request error
+func classifyError(status int, err error) string {
+	if status > 0 {
+		return ErrorTypeHTTP
+	}
+	// Denied addresses stay denied, so the request is not retried as a network error
+	if errors.Is(err, ErrAddressDenied) {
+		return ErrorTypeOther
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorTypeTimeout
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) || netErr != nil {
+		return ErrorTypeNetwork
+	}
+
+	return ErrorTypeOther
+}
+
+// isRetryable reports whether a failed request may succeed when sent again
+func isRetryable(status int, errorType string) bool {
+	switch errorType {
+	case ErrorTypeTimeout, ErrorTypeNetwork:
+		return true
+	case ErrorTypeHTTP:
+		return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// retryCount returns the number of times a URL was retried
+func (c *Crawler) retryCount(rawURL string) int {
+	c.errorsMutex.Lock()
+	defer c.errorsMutex.Unlock()
+	return c.retries[rawURL]
+}
+
+// retry sends a failed request again when retries are left, and reports whether it did
+func (c *Crawler) retry(r *colly.Response, errorType string) bool {
+	rawURL := r.Request.URL.String()
+	retries := c.retryCount(rawURL)
+	if retries >= c.options.Retries || !isRetryable(r.StatusCode, errorType) {
+		return false
+	}
+
+	c.errorsMutex.Lock()
+	c.retries[rawURL] = retries + 1
+	c.errorsMutex.Unlock()
+
+	if err := r.Request.Retry(); err != nil {
+		return false
+	}
+
+	c.logf(VerbosityNormal, "Retrying (%d/%d): %s\n", retries+1, c.options.Retries, rawURL)
+	return true
+}
+
+// recordError stores a failed request
+func (c *Crawler) recordError(r *colly.Response, errorType string, err error) {
+	rawURL := r.Request.URL.String()
+
+	c.errorsMutex.Lock()
+	defer c.errorsMutex.Unlock()
+
+	c.errors = append(c.errors, CrawlError{
+		URL:     rawURL,
+		Status:  r.StatusCode,
+		Type:    errorType,
+		Message: err.Error(),
+		Retries: c.retries[rawURL],
+		At:      time.Now().UTC(),
+	})
+}
+
+// Errors returns the requests that failed, in the order they failed
+func (c *Crawler) Errors() []CrawlError {
+	c.errorsMutex.Lock()
+	defer c.errorsMutex.Unlock()
+	return append([]CrawlError(nil), c.errors...)
+}
+
+// checkErrorRate returns an ErrorRateError when the share of failed requests exceeds Options.MaxErrorRate
+func (c *Crawler) checkErrorRate() error {
+	if c.options.MaxErrorRate <= 0 {
+		return nil
+	}
+
+	c.errorsMutex.Lock()
+	defer c.errorsMutex.Unlock()
+
+	rateErr := &ErrorRateError{Errors: len(c.errors), Requests: c.requests, Threshold: c.options.MaxErrorRate}
+	if rateErr.Rate() > rateErr.Threshold {
+		return rateErr
+	}
+	return nil
+}
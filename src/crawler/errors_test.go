@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   string
+	}{
+		{name: "http status", status: 404, err: errors.New("Not Found"), want: ErrorTypeHTTP},
+		{name: "deadline", err: fmt.Errorf("get: %w", context.DeadlineExceeded), want: ErrorTypeTimeout},
+		{name: "dns", err: &net.DNSError{Err: "no such host", Name: "example.invalid"}, want: ErrorTypeNetwork},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: ErrorTypeNetwork},
+		{name: "other", err: errors.New("boom"), want: ErrorTypeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.status, tt.err); got != tt.want {
+				t.Errorf("classifyError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerErrors(t *testing.T) {
+	var flakyRequests atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><a href="/flaky">Flaky</a> <a href="/broken">Broken</a> <a href="/missing">Missing</a></main></body></html>`))
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		if flakyRequests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><main>Recovered</main></body></html>`))
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{Retries: 2, MaxErrorRate: 0.25})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	err = c.Start()
+	var rateErr *ErrorRateError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("Start() error = %v, want an ErrorRateError", err)
+	}
+	if rateErr.Errors != 2 || rateErr.Requests != 4 {
+		t.Errorf("ErrorRateError = %+v, want 2 errors of 4 requests", rateErr)
+	}
+
+	if pages := len(c.GetPages()); pages != 2 {
+		t.Errorf("GetPages() returned %d pages, want 2", pages)
+	}
+
+	got := map[string]CrawlError{}
+	for _, crawlErr := range c.Errors() {
+		got[strings.TrimPrefix(crawlErr.URL, srv.URL)] = crawlErr
+	}
+
+	if broken := got["/broken"]; broken.Status != http.StatusInternalServerError || broken.Type != ErrorTypeHTTP || broken.Retries != 2 {
+		t.Errorf("Errors() /broken = %+v, want a 500 http error retried twice", broken)
+	}
+	if missing := got["/missing"]; missing.Status != http.StatusNotFound || missing.Retries != 0 {
+		t.Errorf("Errors() /missing = %+v, want a 404 without retries", missing)
+	}
+	if _, ok := got["/flaky"]; ok || len(got) != 2 {
+		t.Errorf("Errors() = %+v, want only /broken and /missing", got)
+	}
+}
@@ -0,0 +1,116 @@
+package crawler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gocolly/colly"
+)
+
+// SkipReason tells why a URL or page was not kept
+type SkipReason string
+
+// Skip reasons passed to skip hooks
+const (
+	SkipDomain    SkipReason = "domain"     // The domain is not allowed or the URL is excluded on it
+	SkipRequest   SkipReason = "request"    // A request hook aborted the request
+	SkipPathLimit SkipReason = "path-limit" // The page budget of the URL pattern is used up
+	SkipBudget    SkipReason = "budget"     // MaxPages requests were sent or MaxDuration has passed
+	SkipOversized SkipReason = "oversized"  // The response is larger than MaxBodySize
+	SkipStatus    SkipReason = "status"     // The error status is followed but not kept
+	SkipCanonical SkipReason = "canonical"  // The page declares another canonical URL, which is crawled instead
+	SkipContent   SkipReason = "content"    // A content hook skipped the page
+	SkipUnchanged SkipReason = "unchanged"  // The server answered 304 Not Modified to a conditional request
+	SkipNoIndex   SkipReason = "noindex"    // The page has a noindex robots meta tag or X-Robots-Tag header
+	SkipSelector  SkipReason = "selector"   // The page has no element matching RequireSelectors
+)
+
+// Request is a request about to be sent
+type Request struct {
+	URL     *url.URL
+	Depth   int
+	Headers *http.Header // Headers sent with the request, hooks may add or change them
+
+	aborted bool
+}
+
+// Abort cancels the request
+func (r *Request) Abort() {
+	r.aborted = true
+}
+
+// Response is a response received for a request, before its HTML is parsed
+type Response struct {
+	URL     *url.URL
+	Status  int
+	Headers *http.Header
+	Body    []byte // Body of the response, hooks may replace it
+}
+
+// RequestHook inspects or changes a request before it is sent
+type RequestHook func(req *Request)
+
+// ResponseHook inspects or changes a response before it is parsed
+type ResponseHook func(resp *Response)
+
+// SkipHook is called when a URL is not requested or a fetched page is not kept
+type SkipHook func(pageURL string, reason SkipReason)
+
+// OnRequest registers a hook called for each request that passes the crawl filters, before it is sent.
+// Hooks run in registration order and can change the headers or abort the request.
+func (c *Crawler) OnRequest(hook RequestHook) {
+	c.requestHooks = append(c.requestHooks, hook)
+}
+
+// OnResponse registers a hook called for each response that is handled, before its HTML is parsed.
+// Hooks run in registration order and can replace the body.
+func (c *Crawler) OnResponse(hook ResponseHook) {
+	c.responseHooks = append(c.responseHooks, hook)
+}
+
+// OnSkip registers a hook called when a URL is filtered out or a fetched page is dropped
+func (c *Crawler) OnSkip(hook SkipHook) {
+	c.skipHooks = append(c.skipHooks, hook)
+}
+
+// runRequestHooks passes the request to the request hooks and reports whether it should be sent
+func (c *Crawler) runRequestHooks(r *colly.Request) bool {
+	if len(c.requestHooks) == 0 {
+		return true
+	}
+
+	req := &Request{URL: r.URL, Depth: r.Depth, Headers: r.Headers}
+	for _, hook := range c.requestHooks {
+		hook(req)
+		if req.aborted {
+			return false
+		}
+	}
+	return true
+}
+
+// runResponseHooks passes the response to the response hooks
+func (c *Crawler) runResponseHooks(r *colly.Response) {
+	if len(c.responseHooks) == 0 {
+		return
+	}
+
+	resp := &Response{URL: r.Request.URL, Status: r.StatusCode, Headers: r.Headers, Body: r.Body}
+	for _, hook := range c.responseHooks {
+		hook(resp)
+	}
+	r.Body = resp.Body
+}
+
+// skip reports a skipped URL to the skip hooks and, in verbose mode, to the log
+func (c *Crawler) skip(pageURL string, reason SkipReason) {
+	switch reason {
+	case SkipPathLimit, SkipOversized, SkipContent:
+		// Logged where they are detected, with more detail
+	default:
+		c.logf(VerbosityVerbose, "Skipping (%s): %s\n", reason, pageURL)
+	}
+	for _, hook := range c.skipHooks {
+		hook(pageURL, reason)
+	}
+}
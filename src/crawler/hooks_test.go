@@ -0,0 +1,126 @@
+package crawler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCrawlerHooks(t *testing.T) {
+	var headersMutex sync.Mutex
+	headers := map[string]string{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		headersMutex.Lock()
+		headers[r.URL.Path] = r.Header.Get("X-Trace")
+		headersMutex.Unlock()
+
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`<html><body><main><a href="/guide">Guide</a> <a href="/private">Private</a> <a href="/draft">Draft</a> <a href="/cached">Cached</a></main></body></html>`))
+		case "/cached":
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			_, _ = w.Write([]byte(`<html><body><main>Page ` + r.URL.Path + `</main></body></html>`))
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	c.OnRequest(func(req *Request) {
+		req.Headers.Set("X-Trace", "crawl")
+		if req.URL.Path == "/private" {
+			req.Abort()
+		}
+	})
+
+	c.OnResponse(func(resp *Response) {
+		if resp.URL.Path == "/guide" && resp.Status == http.StatusOK {
+			resp.Body = bytes.ReplaceAll(resp.Body, []byte("Page"), []byte("Rewritten"))
+		}
+	})
+
+	c.AddContentHook(func(page Page) (ContentAction, string) {
+		if strings.HasSuffix(page.URL, "/draft") {
+			return ContentSkip, ""
+		}
+		return ContentConvert, ""
+	})
+
+	var skipsMutex sync.Mutex
+	skips := map[string]SkipReason{}
+	c.OnSkip(func(pageURL string, reason SkipReason) {
+		skipsMutex.Lock()
+		skips[strings.TrimPrefix(pageURL, srv.URL)] = reason
+		skipsMutex.Unlock()
+	})
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	if want := map[string]SkipReason{"/private": SkipRequest, "/draft": SkipContent, "/cached": SkipUnchanged}; !reflect.DeepEqual(skips, want) {
+		t.Errorf("skips = %v, want %v", skips, want)
+	}
+
+	if want := map[string]string{"/": "crawl", "/guide": "crawl", "/draft": "crawl", "/cached": "crawl"}; !reflect.DeepEqual(headers, want) {
+		t.Errorf("request headers = %v, want %v", headers, want)
+	}
+
+	if errs := c.Errors(); len(errs) != 0 {
+		t.Errorf("Errors() = %v, want a 304 response not to be an error", errs)
+	}
+
+	for _, page := range c.GetPages() {
+		if strings.HasSuffix(page.URL, "/guide") && !strings.Contains(page.Content, "Rewritten /guide") {
+			t.Errorf("guide content = %q, want the body replaced by the response hook", page.Content)
+		}
+	}
+}
+
+func TestCrawlerErrorHooks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><a href="/missing">Missing</a></main></body></html>`))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	var callsMutex sync.Mutex
+	var calls []string
+	for _, name := range []string{"first", "second"} {
+		c.OnError(func(pageURL string, err error) {
+			callsMutex.Lock()
+			defer callsMutex.Unlock()
+			calls = append(calls, name+" "+strings.TrimPrefix(pageURL, srv.URL))
+		})
+	}
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	if want := []string{"first /missing", "second /missing"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("error callbacks = %v, want %v", calls, want)
+	}
+}
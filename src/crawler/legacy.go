@@ -0,0 +1,65 @@
+package crawler
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	xmlDeclarationPattern = regexp.MustCompile(`^\s*<\?xml[^>]*\?>`)
+	cdataPattern          = regexp.MustCompile(`(?s)<!\[CDATA\[(.*?)\]\]>`)
+	// cdataOrRawTextPattern matches a CDATA section or a script or style element, whichever comes first
+	cdataOrRawTextPattern = regexp.MustCompile(`(?is)<!\[CDATA\[.*?\]\]>|<script\b[^>]*>.*?</script\s*>|<style\b[^>]*>.*?</style\s*>`)
+	selfClosingPattern    = regexp.MustCompile(`<([A-Za-z][\w:.-]*)((?:\s+[^\s"'<>/=]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'<>]+))?)*)\s*/>`)
+	namespacePattern      = regexp.MustCompile(`xmlns:([A-Za-z][\w.-]*)\s*=\s*["']http://www\.w3\.org/1999/xhtml["']`)
+)
+
+// voidElements are the HTML elements that never have content and may legitimately self-close
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+	"basefont": true, "bgsound": true, "frame": true, "keygen": true, "isindex": true,
+}
+
+// NormalizeMarkup rewrites XHTML constructs that an HTML5 parser misreads.
+// Self-closed non-void elements such as <script/> or <div/> would otherwise swallow the rest of the document,
+// CDATA sections would be dropped as comments, and prefixed XHTML tags would not be recognized.
+// Script and style elements hold raw text, so only the CDATA markers are removed from them.
+func NormalizeMarkup(body []byte) []byte {
+	body = xmlDeclarationPattern.ReplaceAll(body, nil)
+
+	body = cdataOrRawTextPattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		if !bytes.HasPrefix(match, []byte("<![CDATA[")) {
+			return cdataPattern.ReplaceAll(match, []byte("$1"))
+		}
+		content := cdataPattern.FindSubmatch(match)[1]
+		return []byte(html.EscapeString(string(content)))
+	})
+
+	for _, match := range namespacePattern.FindAllSubmatch(body, -1) {
+		prefix := string(match[1])
+		prefixPattern := regexp.MustCompile(`<(/?)` + regexp.QuoteMeta(prefix) + `:`)
+		body = prefixPattern.ReplaceAll(body, []byte("<$1"))
+	}
+
+	body = selfClosingPattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		parts := selfClosingPattern.FindSubmatch(match)
+		tag := string(parts[1])
+		if voidElements[strings.ToLower(tag)] {
+			return match
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString("<")
+		buf.WriteString(tag)
+		buf.Write(parts[2])
+		buf.WriteString("></")
+		buf.WriteString(tag)
+		buf.WriteString(">")
+		return buf.Bytes()
+	})
+
+	return body
+}
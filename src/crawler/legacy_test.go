@@ -0,0 +1,147 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestNormalizeMarkup(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "self-closed script",
+			in:   `<script src="a.js"/><p>Text</p>`,
+			want: `<script src="a.js"></script><p>Text</p>`,
+		},
+		{
+			name: "self-closed div with quoted slash",
+			in:   `<div title="a/>b"/>`,
+			want: `<div title="a/>b"></div>`,
+		},
+		{
+			name: "void elements untouched",
+			in:   `<br/><img src="a.png" />`,
+			want: `<br/><img src="a.png" />`,
+		},
+		{
+			name: "xml declaration removed",
+			in:   `<?xml version="1.0"?><html></html>`,
+			want: `<html></html>`,
+		},
+		{
+			name: "cdata escaped",
+			in:   `<p><![CDATA[a < b & c]]></p>`,
+			want: `<p>a &lt; b &amp; c</p>`,
+		},
+		{
+			name: "cdata markers removed from script and style",
+			in:   "<script>//<![CDATA[\nif (a < b && c) {}\n//]]></script><style><![CDATA[a > b {}]]></style><p><![CDATA[x < y]]></p>",
+			want: "<script>//\nif (a < b && c) {}\n//</script><style>a > b {}</style><p>x &lt; y</p>",
+		},
+		{
+			name: "xhtml prefix stripped",
+			in:   `<x:html xmlns:x="http://www.w3.org/1999/xhtml"><x:p>Hi</x:p></x:html>`,
+			want: `<html xmlns:x="http://www.w3.org/1999/xhtml"><p>Hi</p></html>`,
+		},
+		{
+			name: "other prefixes kept",
+			in:   `<o:p>Office</o:p>`,
+			want: `<o:p>Office</o:p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(NormalizeMarkup([]byte(tt.in))); got != tt.want {
+				t.Errorf("NormalizeMarkup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMarkupArchivedPages(t *testing.T) {
+	tests := []struct {
+		file      string
+		wantTitle string
+		wantText  []string
+	}{
+		{
+			file:      "xhtml_strict.html",
+			wantTitle: "Weblog Archive - March 2004",
+			wantText:  []string{"Upgrading to XHTML", "valid XHTML 1.0 Strict", "Code samples use <tags> & entities.", "on 2004-03-14"},
+		},
+		{
+			file:      "xhtml_prefixed.html",
+			wantTitle: "Prefixed XHTML",
+			wantText:  []string{"Namespaced Document", "XSLT pipeline", "Second item"},
+		},
+		{
+			file:      "legacy_tables.html",
+			wantTitle: "Welcome to Bob's Homepage",
+			wantText:  []string{"Welcome to my corner of the web!", "model trains", "visitor number 1337", "Netscape Navigator 4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(NormalizeMarkup(body))))
+			if err != nil {
+				t.Fatalf("failed to parse normalized markup: %v", err)
+			}
+
+			if got := doc.Find("title").Text(); got != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got, tt.wantTitle)
+			}
+
+			text := doc.Find("body").Text()
+			for _, want := range tt.wantText {
+				if !strings.Contains(text, want) {
+					t.Errorf("body text is missing %q: %q", want, text)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlerNormalizesXHTML(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "xhtml_strict.html"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xhtml+xml; charset=utf-8")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{SinglePage: true})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	pages := c.GetPages()
+	if len(pages) != 1 {
+		t.Fatalf("expected 1 page, got %d", len(pages))
+	}
+	if !strings.Contains(pages[0].Content, "Upgrading to XHTML") {
+		t.Errorf("page content is missing the article: %q", pages[0].Content)
+	}
+}
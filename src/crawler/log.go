@@ -0,0 +1,50 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gocolly/colly"
+)
+
+// Verbosity controls the crawl log printed to standard output
+type Verbosity int
+
+const (
+	// VerbosityQuiet only logs failed requests
+	VerbosityQuiet Verbosity = -1
+	// VerbosityNormal logs visited URLs, retries, and pages skipped by limits or rules
+	VerbosityNormal Verbosity = 0
+	// VerbosityVerbose also logs the decision taken for each discovered link
+	VerbosityVerbose Verbosity = 1
+)
+
+// logf prints a log line when the verbosity is at least the given level
+func (c *Crawler) logf(level Verbosity, format string, args ...any) {
+	if c.options.Verbosity < level {
+		return
+	}
+	// nolint:forbidigo // Logging output during crawling
+	fmt.Printf(format, args...)
+}
+
+// linkSkipReason describes why colly refused to visit a discovered link,
+// or returns an empty string when the link was queued or failed as a request
+func linkSkipReason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, colly.ErrRobotsTxtBlocked):
+		return "robots.txt"
+	case errors.Is(err, colly.ErrForbiddenDomain):
+		return "external domain"
+	case errors.Is(err, colly.ErrForbiddenURL), errors.Is(err, colly.ErrNoURLFiltersMatch):
+		return "excluded URL"
+	case errors.Is(err, colly.ErrMaxDepth):
+		return "max depth"
+	case errors.Is(err, colly.ErrAlreadyVisited):
+		return "already visited"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,33 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gocolly/colly"
+)
+
+func TestLinkSkipReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "queued", err: nil, want: ""},
+		{name: "robots.txt", err: colly.ErrRobotsTxtBlocked, want: "robots.txt"},
+		{name: "external domain", err: colly.ErrForbiddenDomain, want: "external domain"},
+		{name: "url filter", err: colly.ErrNoURLFiltersMatch, want: "excluded URL"},
+		{name: "max depth", err: colly.ErrMaxDepth, want: "max depth"},
+		{name: "already visited", err: fmt.Errorf("visit: %w", colly.ErrAlreadyVisited), want: "already visited"},
+		{name: "other error", err: errors.New("connection refused"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linkSkipReason(tt.err); got != tt.want {
+				t.Errorf("linkSkipReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package crawler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly"
+)
+
+// robotsDirectives are the indexing directives of a page from its robots meta tags and X-Robots-Tag headers
+type robotsDirectives struct {
+	noIndex  bool // The page must not be kept
+	noFollow bool // The links of the page must not be followed
+}
+
+// pageRobots returns the robots directives of the page of a request, parsed once per request
+func (c *Crawler) pageRobots(e *colly.HTMLElement) robotsDirectives {
+	if c.options.IgnoreMetaRobots {
+		return robotsDirectives{}
+	}
+	if directives, ok := c.robots.Load(e.Request); ok {
+		return directives.(robotsDirectives)
+	}
+
+	root := e.DOM
+	if parents := root.Parents(); parents.Length() > 0 {
+		root = parents.Last()
+	}
+	directives := parseRobots(root, e.Response.Headers, c.options.UserAgent)
+	c.robots.Store(e.Request, directives)
+	return directives
+}
+
+// parseRobots reads the directives of robots meta tags, named "robots" or after the user agent, and X-Robots-Tag headers.
+// Header values scoped to a user agent, such as "googlebot: noindex", are ignored.
+func parseRobots(root *goquery.Selection, headers *http.Header, userAgent string) robotsDirectives {
+	var values []string
+	root.Find("meta[name]").Each(func(_ int, meta *goquery.Selection) {
+		name := strings.TrimSpace(meta.AttrOr("name", ""))
+		if strings.EqualFold(name, "robots") || (userAgent != "" && strings.EqualFold(name, userAgent)) {
+			values = append(values, meta.AttrOr("content", ""))
+		}
+	})
+	if headers != nil {
+		for _, value := range headers.Values("X-Robots-Tag") {
+			if name, _, scoped := strings.Cut(value, ":"); scoped && !strings.ContainsAny(name, ", ") {
+				continue
+			}
+			values = append(values, value)
+		}
+	}
+
+	var directives robotsDirectives
+	for _, value := range values {
+		for _, directive := range strings.Split(value, ",") {
+			switch strings.ToLower(strings.TrimSpace(directive)) {
+			case "noindex":
+				directives.noIndex = true
+			case "nofollow":
+				directives.noFollow = true
+			case "none":
+				directives.noIndex = true
+				directives.noFollow = true
+			}
+		}
+	}
+	return directives
+}
@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name   string
+		meta   string
+		header string
+		want   robotsDirectives
+	}{
+		{name: "none", want: robotsDirectives{}},
+		{name: "noindex meta", meta: `<meta name="robots" content="noindex, follow">`, want: robotsDirectives{noIndex: true}},
+		{name: "nofollow meta", meta: `<meta name="ROBOTS" content="NOFOLLOW">`, want: robotsDirectives{noFollow: true}},
+		{name: "none meta", meta: `<meta name="robots" content="none">`, want: robotsDirectives{noIndex: true, noFollow: true}},
+		{name: "other agent meta", meta: `<meta name="googlebot" content="noindex">`, want: robotsDirectives{}},
+		{name: "user agent meta", meta: `<meta name="crawldown" content="nofollow">`, want: robotsDirectives{noFollow: true}},
+		{name: "header", header: "noindex", want: robotsDirectives{noIndex: true}},
+		{name: "header for another agent", header: "googlebot: noindex", want: robotsDirectives{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head>` + tt.meta + `</head><body></body></html>`))
+			if err != nil {
+				t.Fatalf("failed to parse HTML: %v", err)
+			}
+			headers := http.Header{}
+			if tt.header != "" {
+				headers.Set("X-Robots-Tag", tt.header)
+			}
+
+			if got := parseRobots(doc.Selection, &headers, "CrawlDown"); got != tt.want {
+				t.Errorf("parseRobots() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerMetaRobots(t *testing.T) {
+	mux := http.NewServeMux()
+	page := func(head, body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html><head>` + head + `</head><body><main>` + body + `</main></body></html>`))
+		}
+	}
+	mux.HandleFunc("/", page("", `<a href="/hidden">Hidden</a> <a href="/nofollow">No follow</a> <a href="/header">Header</a>`))
+	mux.HandleFunc("/hidden", page(`<meta name="robots" content="noindex">`, `<a href="/deep">Deep</a>`))
+	mux.HandleFunc("/nofollow", page(`<meta name="robots" content="nofollow">`, `<a href="/unreached">Unreached</a>`))
+	mux.HandleFunc("/header", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Robots-Tag", "noindex")
+		_, _ = w.Write([]byte(`<html><body><main>Header</main></body></html>`))
+	})
+	mux.HandleFunc("/deep", page("", "Deep"))
+	mux.HandleFunc("/unreached", page("", "Unreached"))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		ignore   bool
+		wantURLs []string
+	}{
+		{name: "respected", wantURLs: []string{srv.URL, srv.URL + "/deep", srv.URL + "/nofollow"}},
+		{name: "ignored", ignore: true, wantURLs: []string{srv.URL, srv.URL + "/deep", srv.URL + "/header", srv.URL + "/hidden", srv.URL + "/nofollow", srv.URL + "/unreached"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler(srv.URL, Options{MaxDepth: 3, IgnoreMetaRobots: tt.ignore})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			var skipped []string
+			c.OnSkip(func(pageURL string, reason SkipReason) {
+				if reason == SkipNoIndex {
+					skipped = append(skipped, pageURL)
+				}
+			})
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			var urls []string
+			for _, page := range c.GetPages() {
+				urls = append(urls, page.URL)
+			}
+			sort.Strings(urls)
+
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("crawled %v, want %v", urls, tt.wantURLs)
+			}
+			if !tt.ignore && len(skipped) != 2 {
+				t.Errorf("skipped %v as noindex, want /hidden and /header", skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly"
+)
+
+// NavItem is a link of the site navigation, in the order it appears on the page
+type NavItem struct {
+	URL      string // Normalized absolute URL of the linked page
+	Title    string // Link text
+	Parent   string // URL of the enclosing navigation item, empty for top-level items
+	Position int    // 1-based position in the navigation
+}
+
+// readNavigation records the site navigation from the first page where NavSelector matches
+func (c *Crawler) readNavigation(e *colly.HTMLElement) {
+	c.navMutex.Lock()
+	defer c.navMutex.Unlock()
+	if c.navigation != nil {
+		return
+	}
+
+	nav := e.DOM.Find(c.options.NavSelector)
+	if nav.Length() == 0 {
+		return
+	}
+
+	items := parseNavigation(nav, func(href string) string {
+		return c.foldScheme(c.foldWWW(c.rewriteURL(e.Request.AbsoluteURL(href))))
+	})
+	if len(items) == 0 {
+		return
+	}
+
+	c.navigation = items
+	c.logf(VerbosityVerbose, "Navigation: %d items from %s\n", len(items), e.Request.URL.String())
+}
+
+// parseNavigation lists the links of navigation elements in document order.
+// Nested lists give the hierarchy: the parent of a link is the first link of the enclosing list item.
+func parseNavigation(nav *goquery.Selection, resolve func(href string) string) []NavItem {
+	var items []NavItem
+	seen := make(map[string]bool)
+
+	nav.Find("a[href]").Each(func(_ int, anchor *goquery.Selection) {
+		linkURL := navURL(resolve, anchor.AttrOr("href", ""))
+		if linkURL == "" || seen[linkURL] {
+			return
+		}
+		seen[linkURL] = true
+
+		items = append(items, NavItem{
+			URL:      linkURL,
+			Title:    strings.Join(strings.Fields(anchor.Text()), " "),
+			Parent:   navParent(anchor, resolve),
+			Position: len(items) + 1,
+		})
+	})
+
+	return items
+}
+
+// navParent returns the URL of the first link of the list item enclosing the list item of an anchor
+func navParent(anchor *goquery.Selection, resolve func(href string) string) string {
+	item := anchor.Closest("li")
+	if item.Length() == 0 {
+		return ""
+	}
+
+	parentItem := item.Parent().Closest("li")
+	if parentItem.Length() == 0 {
+		return ""
+	}
+
+	// The parent link is outside the nested lists of its item
+	parentLink := parentItem.Find("a[href]").FilterFunction(func(_ int, link *goquery.Selection) bool {
+		return link.Closest("li").IsSelection(parentItem)
+	}).First()
+	if parentLink.Length() == 0 {
+		return ""
+	}
+	return navURL(resolve, parentLink.AttrOr("href", ""))
+}
+
+// navURL resolves a navigation link, returning an empty string for links that do not lead to a page
+func navURL(resolve func(href string) string, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") || strings.HasPrefix(href, "mailto:") {
+		return ""
+	}
+
+	parsedURL, err := url.Parse(resolve(href))
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return ""
+	}
+	parsedURL.Fragment = ""
+	parsedURL.RawFragment = ""
+	return normalizeURL(parsedURL.String())
+}
+
+// Navigation returns the site navigation read with NavSelector, nil when it was not found
+func (c *Crawler) Navigation() []NavItem {
+	c.navMutex.Lock()
+	defer c.navMutex.Unlock()
+	return append([]NavItem(nil), c.navigation...)
+}
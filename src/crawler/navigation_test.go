@@ -0,0 +1,82 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseNavigation(t *testing.T) {
+	html := `<nav class="sidebar"><ul>
+		<li><a href="/">Home</a></li>
+		<li><a href="/guide/">Guide</a>
+			<ul>
+				<li><a href="/guide/install#top">Install</a></li>
+				<li><a href="install">Install again</a></li>
+				<li><span>Advanced</span>
+					<ul><li><a href="/guide/tuning">Tuning</a></li></ul>
+				</li>
+			</ul>
+		</li>
+		<li><a href="#main">Skip</a> <a href="mailto:docs@example.com">Mail</a></li>
+		<li><a href="/api?b=2&a=1">API   reference</a></li>
+	</ul></nav>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("NewDocumentFromReader() unexpected error: %v", err)
+	}
+
+	resolve := func(href string) string {
+		if strings.HasPrefix(href, "/") {
+			return "https://example.com" + href
+		}
+		return "https://example.com/guide/" + href
+	}
+
+	want := []NavItem{
+		{URL: "https://example.com/", Title: "Home", Position: 1},
+		{URL: "https://example.com/guide/", Title: "Guide", Position: 2},
+		{URL: "https://example.com/guide/install", Title: "Install", Parent: "https://example.com/guide/", Position: 3},
+		{URL: "https://example.com/guide/tuning", Title: "Tuning", Position: 4},
+		{URL: "https://example.com/api?a=1&b=2", Title: "API reference", Position: 5},
+	}
+
+	if got := parseNavigation(doc.Find("nav.sidebar"), resolve); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNavigation() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCrawlerNavigation(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>Home <a href="/docs/">Docs</a></main></body></html>`))
+	})
+	mux.HandleFunc("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><aside id="toc"><ul><li><a href="/docs/b">B</a></li><li><a href="/docs/a">A</a></li></ul></aside><main>Docs</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL+"/", Options{MaxDepth: 2, NavSelector: "#toc", Deterministic: true})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	want := []NavItem{
+		{URL: srv.URL + "/docs/b", Title: "B", Position: 1},
+		{URL: srv.URL + "/docs/a", Title: "A", Position: 2},
+	}
+	if got := c.Navigation(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Navigation() = %+v, want %+v", got, want)
+	}
+}
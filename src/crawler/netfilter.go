@@ -0,0 +1,99 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"syscall"
+)
+
+// ErrAddressDenied is the error of connections to an IP address the crawler may not connect to
+var ErrAddressDenied = errors.New("address denied")
+
+// sharedAddressSpace is the carrier-grade NAT range, not covered by netip.Addr.IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// ParseNetwork parses a CIDR prefix, or a single IP address as the prefix of that address only
+func ParseNetwork(network string) (netip.Prefix, error) {
+	if addr, err := netip.ParseAddr(network); err == nil {
+		return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(network)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid network %q: expected an IP address or a CIDR prefix", network)
+	}
+	return prefix.Masked(), nil
+}
+
+// IsPrivateAddress reports whether an address is not reachable on the public internet: loopback, private,
+// link-local (such as the 169.254.169.254 metadata endpoint of cloud providers), carrier-grade NAT,
+// unspecified, or multicast
+func IsPrivateAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr)
+}
+
+// AddressFilter decides which IP addresses the crawler connects to, from the address options of Options.
+// The crawler checks it when connecting, after DNS resolution, so that redirects and DNS rebinding cannot
+// reach denied addresses.
+type AddressFilter struct {
+	denyPrivate bool
+	allowed     []netip.Prefix // Networks allowed even when private or denied
+	denied      []netip.Prefix
+}
+
+// NewAddressFilter parses the address options, returning nil when every address is allowed
+func NewAddressFilter(opts Options) (*AddressFilter, error) {
+	if !opts.DenyPrivateNetworks && len(opts.DeniedNetworks) == 0 {
+		return nil, nil
+	}
+
+	filter := &AddressFilter{denyPrivate: opts.DenyPrivateNetworks}
+	for _, network := range opts.AllowedNetworks {
+		prefix, err := ParseNetwork(network)
+		if err != nil {
+			return nil, err
+		}
+		filter.allowed = append(filter.allowed, prefix)
+	}
+	for _, network := range opts.DeniedNetworks {
+		prefix, err := ParseNetwork(network)
+		if err != nil {
+			return nil, err
+		}
+		filter.denied = append(filter.denied, prefix)
+	}
+	return filter, nil
+}
+
+// Allows reports whether the crawler may connect to an address
+func (f *AddressFilter) Allows(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range f.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if f.denyPrivate && IsPrivateAddress(addr) {
+		return false
+	}
+	for _, prefix := range f.denied {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// control is the net.Dialer Control function refusing connections to denied addresses
+func (f *AddressFilter) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAddressDenied, address)
+	}
+	if !f.Allows(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrAddressDenied, addrPort.Addr().Unmap())
+	}
+	return nil
+}
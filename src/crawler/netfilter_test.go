@@ -0,0 +1,123 @@
+package crawler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPrivateAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "127.0.0.1", want: true},
+		{addr: "10.1.2.3", want: true},
+		{addr: "172.16.0.1", want: true},
+		{addr: "192.168.1.1", want: true},
+		{addr: "169.254.169.254", want: true},
+		{addr: "100.64.0.1", want: true},
+		{addr: "0.0.0.0", want: true},
+		{addr: "::1", want: true},
+		{addr: "fd00:ec2::254", want: true},
+		{addr: "fe80::1", want: true},
+		{addr: "::ffff:10.0.0.1", want: true},
+		{addr: "93.184.215.14", want: false},
+		{addr: "2606:2800:21f:cb07:6820:80da:af6b:8b2c", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := IsPrivateAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("IsPrivateAddress(%s) = %t, want %t", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNetwork(t *testing.T) {
+	tests := []struct {
+		network string
+		want    string
+		wantErr bool
+	}{
+		{network: "10.0.0.0/8", want: "10.0.0.0/8"},
+		{network: "10.1.2.3/8", want: "10.0.0.0/8"},
+		{network: "192.168.1.10", want: "192.168.1.10/32"},
+		{network: "fd00::/8", want: "fd00::/8"},
+		{network: "internal.example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			got, err := ParseNetwork(tt.network)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNetwork() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("ParseNetwork() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerAddressFilter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Local</title></head><body><main><p>Local content</p></main></body></html>`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		opts      Options
+		wantPages int
+	}{
+		{name: "private networks denied", opts: Options{DenyPrivateNetworks: true}, wantPages: 0},
+		{name: "network denied", opts: Options{DeniedNetworks: []string{"127.0.0.0/8"}}, wantPages: 0},
+		{name: "address allowed", opts: Options{DenyPrivateNetworks: true, AllowedNetworks: []string{"127.0.0.1"}}, wantPages: 1},
+		{name: "other network denied", opts: Options{DeniedNetworks: []string{"10.0.0.0/8"}}, wantPages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.SinglePage = true
+			tt.opts.IgnoreRobotsTxt = true
+			tt.opts.Retries = 2
+			c, err := NewCrawler(srv.URL, tt.opts)
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+			_ = c.Start()
+
+			if pages := c.GetPages(); len(pages) != tt.wantPages {
+				t.Fatalf("GetPages() = %d pages, want %d", len(pages), tt.wantPages)
+			}
+			if tt.wantPages == 0 {
+				crawlErrors := c.Errors()
+				if len(crawlErrors) != 1 || crawlErrors[0].Type != ErrorTypeOther || crawlErrors[0].Retries != 0 {
+					t.Errorf("Errors() = %+v, want one denied request without retries", crawlErrors)
+				}
+			}
+		})
+	}
+}
+
+func TestAddressFilterControl(t *testing.T) {
+	filter, err := NewAddressFilter(Options{DenyPrivateNetworks: true})
+	if err != nil {
+		t.Fatalf("NewAddressFilter() unexpected error: %v", err)
+	}
+	if err := filter.control("tcp4", "169.254.169.254:80", nil); !errors.Is(err, ErrAddressDenied) {
+		t.Errorf("control() = %v, want ErrAddressDenied", err)
+	}
+	if err := filter.control("tcp4", "93.184.215.14:443", nil); err != nil {
+		t.Errorf("control() unexpected error: %v", err)
+	}
+
+	if _, err := NewAddressFilter(Options{AllowedNetworks: []string{"not a network"}, DenyPrivateNetworks: true}); err == nil {
+		t.Error("NewAddressFilter() expected an error for an invalid network")
+	}
+}
@@ -0,0 +1,89 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gocolly/colly"
+)
+
+// paginationContainers are the elements whose links are matched by text when no rel or class marks the next page
+const paginationContainers = `.pagination, .pager, .paging, nav[aria-label*="pagination" i], nav[aria-label*="pages" i]`
+
+// Selectors marking pagination links, most specific first
+var (
+	nextPageSelectors = []string{
+		`link[rel~="next" i]`,
+		`a[rel~="next" i]`,
+		`a[aria-label="next" i]`,
+		`a[aria-label="next page" i]`,
+		`a.next`,
+		`a.next-page`,
+		`a.pagination-next`,
+		`.next > a`,
+	}
+	prevPageSelectors = []string{
+		`link[rel~="prev" i]`,
+		`link[rel~="previous" i]`,
+		`a[rel~="prev" i]`,
+		`a[rel~="previous" i]`,
+		`a[aria-label="previous" i]`,
+		`a[aria-label="previous page" i]`,
+		`a.prev`,
+		`a.previous`,
+		`a.prev-page`,
+		`a.pagination-previous`,
+		`.prev > a`,
+		`.previous > a`,
+	}
+)
+
+// Link texts of pagination links inside pagination containers, lowercased
+var (
+	nextPageTexts = map[string]bool{"next": true, "next page": true, "next »": true, "next ›": true, "next >": true, "»": true, "›": true, ">": true}
+	prevPageTexts = map[string]bool{"prev": true, "previous": true, "previous page": true, "« previous": true, "‹ previous": true, "< previous": true, "« prev": true, "«": true, "‹": true, "<": true}
+)
+
+// extractPagination returns the absolute URL of the first pagination link matching the selectors or link texts
+func extractPagination(e *colly.HTMLElement, selectors []string, texts map[string]bool) string {
+	for _, selector := range selectors {
+		if pageURL := paginationURL(e, e.ChildAttr(selector, "href")); pageURL != "" {
+			return pageURL
+		}
+	}
+
+	var pageURL string
+	e.ForEach(paginationContainers, func(_ int, container *colly.HTMLElement) {
+		if pageURL != "" {
+			return
+		}
+		container.ForEach("a[href]", func(_ int, link *colly.HTMLElement) {
+			if pageURL == "" && texts[strings.ToLower(strings.Join(strings.Fields(link.Text), " "))] {
+				pageURL = paginationURL(e, link.Attr("href"))
+			}
+		})
+	})
+
+	return pageURL
+}
+
+// paginationURL resolves a pagination href to a normalized absolute URL of another HTTP(S) page
+func paginationURL(e *colly.HTMLElement, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return ""
+	}
+
+	parsedURL, err := url.Parse(e.Request.AbsoluteURL(href))
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return ""
+	}
+
+	parsedURL.Fragment = ""
+	pageURL := normalizeURL(parsedURL.String())
+	if pageURL == normalizeURL(e.Request.URL.String()) {
+		return ""
+	}
+
+	return pageURL
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCrawlerFollowPagination(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><a href="/list">List</a></main></body></html>`))
+	})
+	mux.HandleFunc("/list", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			_, _ = w.Write([]byte(`<html><head><link rel="next" href="/list?page=2"></head><body><main>Part 1</main></body></html>`))
+		case "2":
+			_, _ = w.Write([]byte(`<html><body><main>Part 2</main><nav class="pagination"><a href="/list">Previous</a><a href="/list?page=3">Next &raquo;</a></nav></body></html>`))
+		default:
+			_, _ = w.Write([]byte(`<html><body><main>Part 3</main><a class="prev" href="/list?page=2">Back</a></body></html>`))
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name             string
+		followPagination bool
+		wantURLs         []string
+	}{
+		{name: "depth stops pagination", wantURLs: []string{srv.URL, srv.URL + "/list"}},
+		{
+			name:             "pagination followed beyond depth",
+			followPagination: true,
+			wantURLs:         []string{srv.URL, srv.URL + "/list", srv.URL + "/list?page=2", srv.URL + "/list?page=3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler(srv.URL, Options{MaxDepth: 2, FollowPagination: tt.followPagination})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			pages := make(map[string]Page)
+			var urls []string
+			for _, page := range c.GetPages() {
+				pages[page.URL] = page
+				urls = append(urls, page.URL)
+			}
+			sort.Strings(urls)
+
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Fatalf("crawled %v, want %v", urls, tt.wantURLs)
+			}
+
+			if got := pages[srv.URL+"/list"].Next; got != srv.URL+"/list?page=2" {
+				t.Errorf("Next of part 1 = %q", got)
+			}
+
+			if !tt.followPagination {
+				return
+			}
+
+			second := pages[srv.URL+"/list?page=2"]
+			if second.Next != srv.URL+"/list?page=3" || second.Prev != srv.URL+"/list" {
+				t.Errorf("part 2 Next = %q, Prev = %q", second.Next, second.Prev)
+			}
+			if got := pages[srv.URL+"/list?page=3"].Prev; got != srv.URL+"/list?page=2" {
+				t.Errorf("Prev of part 3 = %q", got)
+			}
+		})
+	}
+}
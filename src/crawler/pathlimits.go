@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sandrolain/crawldown/src/urlmatch"
+)
+
+// PathLimit caps the number of pages crawled under a URL pattern
+type PathLimit struct {
+	Pattern string // Glob matched against the URL path, or the full URL if it contains "://"
+	Max     int    // Maximum number of pages requested for the pattern
+}
+
+// ParsePathLimit parses a "pattern=max" limit such as "/blog/*=50"
+func ParsePathLimit(value string) (PathLimit, error) {
+	separator := strings.LastIndex(value, "=")
+	if separator <= 0 {
+		return PathLimit{}, fmt.Errorf("invalid path limit %q: expected pattern=max", value)
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(value[separator+1:]))
+	if err != nil || limit < 0 {
+		return PathLimit{}, fmt.Errorf("invalid path limit %q: max must be a non-negative number", value)
+	}
+
+	pattern := strings.TrimSpace(value[:separator])
+	if _, err := urlmatch.Compile(pattern); err != nil {
+		return PathLimit{}, fmt.Errorf("invalid path limit %q: %w", value, err)
+	}
+
+	return PathLimit{Pattern: pattern, Max: limit}, nil
+}
+
+// pathBudget counts the pages requested for each path limit
+type pathBudget struct {
+	patterns []*urlmatch.Pattern
+	limits   []int
+	counts   []int
+	mutex    sync.Mutex
+}
+
+// newPathBudget compiles the path limits
+func newPathBudget(limits []PathLimit) (*pathBudget, error) {
+	budget := &pathBudget{
+		limits: make([]int, len(limits)),
+		counts: make([]int, len(limits)),
+	}
+
+	for i, limit := range limits {
+		pattern, err := urlmatch.Compile(limit.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		budget.patterns = append(budget.patterns, pattern)
+		budget.limits[i] = limit.Max
+	}
+
+	return budget, nil
+}
+
+// take counts a request against the first matching limit and reports whether it is within budget
+func (b *pathBudget) take(rawURL string) bool {
+	for i, pattern := range b.patterns {
+		if !pattern.Match(rawURL) {
+			continue
+		}
+
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		if b.counts[i] >= b.limits[i] {
+			return false
+		}
+		b.counts[i]++
+		return true
+	}
+
+	return true
+}
@@ -0,0 +1,81 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePathLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    PathLimit
+		wantErr bool
+	}{
+		{value: "/blog/*=50", want: PathLimit{Pattern: "/blog/*", Max: 50}},
+		{value: "/calendar/* = 0", want: PathLimit{Pattern: "/calendar/*", Max: 0}},
+		{value: "https://example.com/search?q=*=5", want: PathLimit{Pattern: "https://example.com/search?q=*", Max: 5}},
+		{value: "/blog/*", wantErr: true},
+		{value: "=5", wantErr: true},
+		{value: "/blog/*=many", wantErr: true},
+		{value: "/blog/*=-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePathLimit(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePathLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParsePathLimit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerPathLimits(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		var links strings.Builder
+		for i := 1; i <= 5; i++ {
+			fmt.Fprintf(&links, `<a href="/tag/%d">Tag %d</a> `, i, i)
+		}
+		_, _ = w.Write([]byte(`<html><body><main>` + links.String() + `<a href="/about">About</a></main></body></html>`))
+	})
+	mux.HandleFunc("/tag/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>Tag</main></body></html>`))
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>About</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{PathLimits: []PathLimit{{Pattern: "/tag/*", Max: 2}}})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	counts := map[string]int{}
+	for _, page := range c.GetPages() {
+		switch {
+		case strings.Contains(page.URL, "/tag/"):
+			counts["tag"]++
+		case strings.HasSuffix(page.URL, "/about"):
+			counts["about"]++
+		}
+	}
+
+	if want := map[string]int{"tag": 2, "about": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("crawled pages = %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,90 @@
+package crawler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/urlmatch"
+)
+
+// PriorityRule gives the links matching a URL pattern a weight; links with a higher weight are fetched first
+type PriorityRule struct {
+	Pattern string // Glob matched against the URL path, or the full URL if it contains "://"
+	Weight  int    // Weight of matching links, links matching no rule weigh 0
+}
+
+// ParsePriorityRule parses a "pattern=weight" rule such as "/docs/*=10" or "/blog/*=-5"
+func ParsePriorityRule(value string) (PriorityRule, error) {
+	separator := strings.LastIndex(value, "=")
+	if separator <= 0 {
+		return PriorityRule{}, fmt.Errorf("invalid priority %q: expected pattern=weight", value)
+	}
+
+	weight, err := strconv.Atoi(strings.TrimSpace(value[separator+1:]))
+	if err != nil {
+		return PriorityRule{}, fmt.Errorf("invalid priority %q: weight must be a number", value)
+	}
+
+	pattern := strings.TrimSpace(value[:separator])
+	if _, err := urlmatch.Compile(pattern); err != nil {
+		return PriorityRule{}, fmt.Errorf("invalid priority %q: %w", value, err)
+	}
+
+	return PriorityRule{Pattern: pattern, Weight: weight}, nil
+}
+
+type compiledPriority struct {
+	pattern *urlmatch.Pattern
+	weight  int
+}
+
+func compilePriorities(rules []PriorityRule) ([]compiledPriority, error) {
+	compiled := make([]compiledPriority, 0, len(rules))
+	for _, rule := range rules {
+		pattern, err := urlmatch.Compile(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, compiledPriority{pattern: pattern, weight: rule.Weight})
+	}
+	return compiled, nil
+}
+
+// priority returns the weight of the first priority rule matching a URL
+func (c *Crawler) priority(rawURL string) int {
+	for _, rule := range c.priorities {
+		if rule.pattern.Match(rawURL) {
+			return rule.weight
+		}
+	}
+	return 0
+}
+
+// takeRequest counts a request against MaxPages and reports whether it is within the page and time budget
+func (c *Crawler) takeRequest() bool {
+	c.errorsMutex.Lock()
+	defer c.errorsMutex.Unlock()
+
+	if c.budgetExhausted() {
+		return false
+	}
+	c.requests++
+	return true
+}
+
+// budgetExhausted reports whether MaxPages requests were sent or MaxDuration has passed; errorsMutex must be held
+func (c *Crawler) budgetExhausted() bool {
+	if c.options.MaxPages > 0 && c.requests >= c.options.MaxPages {
+		return true
+	}
+	return !c.deadline.IsZero() && time.Now().After(c.deadline)
+}
+
+// outOfBudget reports whether no more requests will be sent
+func (c *Crawler) outOfBudget() bool {
+	c.errorsMutex.Lock()
+	defer c.errorsMutex.Unlock()
+	return c.budgetExhausted()
+}
@@ -0,0 +1,96 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePriorityRule(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    PriorityRule
+		wantErr bool
+	}{
+		{value: "/docs/*=10", want: PriorityRule{Pattern: "/docs/*", Weight: 10}},
+		{value: "/blog/* = -5", want: PriorityRule{Pattern: "/blog/*", Weight: -5}},
+		{value: "https://example.com/search?q=*=1", want: PriorityRule{Pattern: "https://example.com/search?q=*", Weight: 1}},
+		{value: "/docs/*", wantErr: true},
+		{value: "=5", wantErr: true},
+		{value: "/docs/*=high", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePriorityRule(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePriorityRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParsePriorityRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerPriorities(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body := "Page"
+		if r.URL.Path == "/" {
+			body = `<a href="/blog/1">1</a> <a href="/about">About</a> <a href="/docs/a">A</a> <a href="/blog/2">2</a> <a href="/docs/b">B</a>`
+		}
+		_, _ = w.Write([]byte(`<html><body><main>` + body + `</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	priorities := []PriorityRule{{Pattern: "/docs/*", Weight: 10}, {Pattern: "/blog/*", Weight: -1}}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		wantPaths []string
+	}{
+		{
+			name:      "highest weight first",
+			opts:      Options{MaxDepth: 2, Priorities: priorities},
+			wantPaths: []string{"/", "/docs/a", "/docs/b", "/about", "/blog/1", "/blog/2"},
+		},
+		{
+			name:      "max pages",
+			opts:      Options{MaxDepth: 2, Priorities: priorities, MaxPages: 3},
+			wantPaths: []string{"/", "/docs/a", "/docs/b"},
+		},
+		{
+			name:      "max duration",
+			opts:      Options{MaxDepth: 2, Priorities: priorities, MaxDuration: time.Nanosecond},
+			wantPaths: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler(srv.URL+"/", tt.opts)
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			var paths []string
+			for _, page := range c.GetPages() {
+				paths = append(paths, page.URL[len(srv.URL):])
+			}
+
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("crawled %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
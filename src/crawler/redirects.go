@@ -0,0 +1,166 @@
+package crawler
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxRedirects matches the default limit of the Go HTTP client
+const maxRedirects = 10
+
+// Redirect is a hop of a redirect chain
+type Redirect struct {
+	URL    string // URL that was requested
+	Status int    // HTTP status of its redirect response, e.g. 301
+}
+
+// handleRedirect records each hop of a redirect chain and follows it like the default colly handler
+func (c *Crawler) handleRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return http.ErrUseLastResponse
+	}
+
+	// The chain so far is stored under the URL that just redirected, which is not a page
+	previous := normalizeURL(via[len(via)-1].URL.String())
+	c.redirectsMutex.Lock()
+	chain := append([]Redirect{}, c.redirects[previous]...)
+	delete(c.redirects, previous)
+	c.redirectsMutex.Unlock()
+
+	status := http.StatusFound
+	if req.Response != nil {
+		status = req.Response.StatusCode
+	}
+	chain = append(chain, Redirect{URL: previous, Status: status})
+
+	// Chains of several URLs redirected to the same target are merged
+	target := normalizeURL(req.URL.String())
+	c.redirectsMutex.Lock()
+	for _, hop := range chain {
+		if !containsRedirect(c.redirects[target], hop.URL) {
+			c.redirects[target] = append(c.redirects[target], hop)
+		}
+	}
+	c.redirectsMutex.Unlock()
+
+	// Copy the headers from the last request, dropping credentials when the host changes
+	lastRequest := via[len(via)-1]
+	for name, values := range lastRequest.Header {
+		for _, value := range values {
+			req.Header.Set(name, value)
+		}
+	}
+	if req.URL.Host != lastRequest.URL.Host {
+		req.Header.Del("Authorization")
+	}
+
+	return nil
+}
+
+// containsRedirect reports whether a chain contains a hop for the URL
+func containsRedirect(chain []Redirect, rawURL string) bool {
+	for _, hop := range chain {
+		if hop.URL == rawURL {
+			return true
+		}
+	}
+	return false
+}
+
+// redirectChain returns the redirects that led to a page, in request order
+func (c *Crawler) redirectChain(pageURL string) []Redirect {
+	c.redirectsMutex.Lock()
+	defer c.redirectsMutex.Unlock()
+	return c.redirects[pageURL]
+}
+
+// adoptScheme follows the scheme of the site when a redirect switches it on the same host, e.g. from http to https
+func (c *Crawler) adoptScheme(finalURL *url.URL, chain []Redirect) {
+	if c.options.DistinctSchemes || len(chain) == 0 || !c.isSameSite(finalURL.Host) {
+		return
+	}
+
+	first, err := url.Parse(chain[0].URL)
+	if err != nil || !strings.EqualFold(first.Hostname(), finalURL.Hostname()) || first.Scheme == finalURL.Scheme {
+		return
+	}
+
+	c.schemeMutex.Lock()
+	c.siteScheme = finalURL.Scheme
+	c.schemeMutex.Unlock()
+}
+
+// currentScheme returns the scheme used for links within the site
+func (c *Crawler) currentScheme() string {
+	c.schemeMutex.Lock()
+	defer c.schemeMutex.Unlock()
+	return c.siteScheme
+}
+
+// foldScheme rewrites http and https links within the site to the site scheme
+func (c *Crawler) foldScheme(rawURL string) string {
+	if c.options.DistinctSchemes {
+		return rawURL
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || !c.isSameSite(parsedURL.Host) {
+		return rawURL
+	}
+
+	scheme := c.currentScheme()
+	if parsedURL.Scheme == scheme {
+		return rawURL
+	}
+	parsedURL.Scheme = scheme
+
+	return parsedURL.String()
+}
+
+// schemeAliases returns the other-scheme variants of URLs, which links may still use
+func (c *Crawler) schemeAliases(urls []string) []string {
+	if c.options.DistinctSchemes {
+		return nil
+	}
+
+	var aliases []string
+	for _, rawURL := range urls {
+		parsedURL, err := url.Parse(rawURL)
+		if err != nil {
+			continue
+		}
+
+		switch parsedURL.Scheme {
+		case "http":
+			parsedURL.Scheme = "https"
+		case "https":
+			parsedURL.Scheme = "http"
+		default:
+			continue
+		}
+		aliases = append(aliases, parsedURL.String())
+	}
+
+	return aliases
+}
+
+// pageAliases returns the other URLs of a page: its www or apex and other-scheme variants
+func (c *Crawler) pageAliases(pageURL string) []string {
+	aliases := c.hostAliases(pageURL)
+	return append(aliases, c.schemeAliases(append([]string{pageURL}, aliases...))...)
+}
+
+// Redirects returns the redirect chains followed during the crawl, keyed by the normalized URL they led to.
+// A page reached both directly and through a redirect is only crawled once, so chains are collected here
+// rather than on the page.
+func (c *Crawler) Redirects() map[string][]Redirect {
+	c.redirectsMutex.Lock()
+	defer c.redirectsMutex.Unlock()
+
+	redirects := make(map[string][]Redirect, len(c.redirects))
+	for target, chain := range c.redirects {
+		redirects[target] = chain
+	}
+	return redirects
+}
@@ -0,0 +1,128 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestCrawlerRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><a href="/old">Old</a> <a href="/new">New</a></main></body></html>`))
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><head><title>New</title></head><body><main>New</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	var newPage Page
+	newPages := 0
+	for _, page := range c.GetPages() {
+		if page.Title == "New" {
+			newPage = page
+			newPages++
+		}
+	}
+
+	if newPages != 1 {
+		t.Errorf("crawled the redirect target %d times, want 1", newPages)
+	}
+
+	if newPage.URL != srv.URL+"/new" {
+		t.Fatalf("URL = %q, want %q", newPage.URL, srv.URL+"/new")
+	}
+
+	want := map[string][]Redirect{
+		srv.URL + "/new": {{URL: srv.URL + "/old", Status: http.StatusMovedPermanently}, {URL: srv.URL + "/moved", Status: http.StatusFound}},
+	}
+	if got := c.Redirects(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Redirects() = %v, want %v", got, want)
+	}
+}
+
+func TestCrawlerFoldScheme(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		redirect []Redirect
+		url      string
+		want     string
+	}{
+		{name: "follows start scheme", url: "https://example.com/a", want: "http://example.com/a"},
+		{name: "other host kept", url: "https://example.org/a", want: "https://example.org/a"},
+		{name: "distinct schemes", opts: Options{DistinctSchemes: true}, url: "https://example.com/a", want: "https://example.com/a"},
+		{
+			name:     "adopts redirect scheme",
+			redirect: []Redirect{{URL: "http://example.com/", Status: http.StatusMovedPermanently}},
+			url:      "http://example.com/a",
+			want:     "https://example.com/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler("http://example.com/", tt.opts)
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if tt.redirect != nil {
+				finalURL, _ := url.Parse("https://example.com/")
+				c.adoptScheme(finalURL, tt.redirect)
+			}
+
+			if got := c.foldScheme(tt.url); got != tt.want {
+				t.Errorf("foldScheme(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerPageAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{name: "scheme variant", want: []string{"http://www.example.com/docs"}},
+		{
+			name: "host and scheme variants",
+			opts: Options{IncludeSubdomains: true},
+			want: []string{"https://example.com/docs", "http://www.example.com/docs", "http://example.com/docs"},
+		},
+		{name: "distinct schemes", opts: Options{DistinctSchemes: true}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler("https://www.example.com/", tt.opts)
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if got := c.pageAliases("https://www.example.com/docs"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pageAliases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ParseHostAddress parses a host:ip override of the address of a host, like the --resolve option of curl
+// without the port. IPv6 addresses may be enclosed in brackets.
+func ParseHostAddress(value string) (string, string, error) {
+	host, ip, ok := strings.Cut(value, ":")
+	if !ok || host == "" || ip == "" {
+		return "", "", fmt.Errorf("invalid host address %q: expected HOST:IP", value)
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(ip, "["), "]"))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid host address %q: %q is not an IP address", value, ip)
+	}
+	return strings.ToLower(host), addr.String(), nil
+}
+
+// dialFunc is the signature of the DialContext function of http.Transport
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// resolvingDial connects to the overriding address of hosts found in addresses instead of resolving them,
+// keeping the port. URLs, Host headers, and TLS server names keep the host.
+func resolvingDial(dial dialFunc, addresses map[string]string) (dialFunc, error) {
+	overrides := make(map[string]string, len(addresses))
+	for host, ip := range addresses {
+		addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(ip, "["), "]"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q of host %s", ip, host)
+		}
+		overrides[strings.ToLower(host)] = addr.String()
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err == nil {
+			if ip, ok := overrides[strings.ToLower(host)]; ok {
+				address = net.JoinHostPort(ip, port)
+			}
+		}
+		return dial(ctx, network, address)
+	}, nil
+}
@@ -0,0 +1,72 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestParseHostAddress(t *testing.T) {
+	tests := []struct {
+		value    string
+		wantHost string
+		wantIP   string
+		wantErr  bool
+	}{
+		{value: "docs.example.com:10.0.0.5", wantHost: "docs.example.com", wantIP: "10.0.0.5"},
+		{value: "Docs.Example.com:[2001:db8::1]", wantHost: "docs.example.com", wantIP: "2001:db8::1"},
+		{value: "docs.example.com:2001:db8::1", wantHost: "docs.example.com", wantIP: "2001:db8::1"},
+		{value: "docs.example.com", wantErr: true},
+		{value: ":10.0.0.5", wantErr: true},
+		{value: "docs.example.com:staging", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			host, ip, err := ParseHostAddress(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHostAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if host != tt.wantHost || ip != tt.wantIP {
+				t.Errorf("ParseHostAddress() = %q, %q, want %q, %q", host, ip, tt.wantHost, tt.wantIP)
+			}
+		})
+	}
+}
+
+func TestCrawlerHostAddresses(t *testing.T) {
+	var requestHost string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestHost = r.Host
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Staging</title></head><body><main><p>Staging content</p></main></body></html>`))
+	}))
+	defer srv.Close()
+
+	serverURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parsing server URL: %v", err)
+	}
+	startURL := "http://docs.example.invalid:" + serverURL.Port() + "/"
+
+	c, err := NewCrawler(startURL, Options{
+		SinglePage:      true,
+		IgnoreRobotsTxt: true,
+		HostAddresses:   map[string]string{"Docs.Example.invalid": serverURL.Hostname()},
+	})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	pages := c.GetPages()
+	if len(pages) != 1 || pages[0].URL != startURL || pages[0].Title != "Staging" {
+		t.Fatalf("GetPages() = %+v, want the page under the public host", pages)
+	}
+	if requestHost != "docs.example.invalid:"+serverURL.Port() {
+		t.Errorf("Host header = %q, want the public host", requestHost)
+	}
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// URLRewrite replaces the matches of a regular expression in URLs before they are visited
+type URLRewrite struct {
+	Find    string `json:"find"`    // Regular expression matched against the absolute URL
+	Replace string `json:"replace"` // Replacement, where $1 or ${name} refer to submatches
+}
+
+// Validate checks that the rewrite rule is well formed
+func (r URLRewrite) Validate() error {
+	if r.Find == "" {
+		return fmt.Errorf("url rewrite must set find")
+	}
+
+	if _, err := regexp.Compile(r.Find); err != nil {
+		return fmt.Errorf("invalid url rewrite %q: %w", r.Find, err)
+	}
+
+	return nil
+}
+
+// compiledRewrite is a URLRewrite with its compiled expression
+type compiledRewrite struct {
+	re      *regexp.Regexp
+	replace string
+}
+
+// compileRewrites compiles the rewrite rules in order
+func compileRewrites(rewrites []URLRewrite) ([]compiledRewrite, error) {
+	compiled := make([]compiledRewrite, 0, len(rewrites))
+	for _, rewrite := range rewrites {
+		if err := rewrite.Validate(); err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, compiledRewrite{re: regexp.MustCompile(rewrite.Find), replace: rewrite.Replace})
+	}
+	return compiled, nil
+}
+
+// rewriteURL applies all rewrite rules in order and records the original URL when it changes
+func (c *Crawler) rewriteURL(rawURL string) string {
+	rewritten := rawURL
+	for _, rewrite := range c.rewrites {
+		rewritten = rewrite.re.ReplaceAllString(rewritten, rewrite.replace)
+	}
+
+	if rewritten != rawURL {
+		c.rewrittenMutex.Lock()
+		c.rewritten[normalizeURL(rawURL)] = normalizeURL(rewritten)
+		c.rewrittenMutex.Unlock()
+	}
+
+	return rewritten
+}
+
+// RewrittenURLs returns the URLs changed by rewrite rules, mapped to the normalized URL that was visited instead
+func (c *Crawler) RewrittenURLs() map[string]string {
+	c.rewrittenMutex.Lock()
+	defer c.rewrittenMutex.Unlock()
+
+	rewritten := make(map[string]string, len(c.rewritten))
+	for original, target := range c.rewritten {
+		rewritten[original] = target
+	}
+	return rewritten
+}
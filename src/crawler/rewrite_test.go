@@ -0,0 +1,67 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestURLRewriteValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rewrite URLRewrite
+		wantErr bool
+	}{
+		{name: "valid", rewrite: URLRewrite{Find: `/index\.html$`, Replace: "/"}},
+		{name: "empty replacement", rewrite: URLRewrite{Find: `\?utm_[^&]*$`}},
+		{name: "missing find", rewrite: URLRewrite{Replace: "/"}, wantErr: true},
+		{name: "invalid regex", rewrite: URLRewrite{Find: "(unclosed"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rewrite.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCrawlerURLRewrites(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><a href="/docs/index.html">Docs</a> <a href="/docs/">Docs again</a></main></body></html>`))
+	})
+	mux.HandleFunc("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>Docs</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{URLRewrites: []URLRewrite{{Find: `/index\.html$`, Replace: "/"}}})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	var urls []string
+	for _, page := range c.GetPages() {
+		urls = append(urls, page.URL)
+	}
+	sort.Strings(urls)
+
+	if want := []string{srv.URL, srv.URL + "/docs/"}; !reflect.DeepEqual(urls, want) {
+		t.Errorf("crawled %v, want %v", urls, want)
+	}
+
+	want := map[string]string{srv.URL + "/docs/index.html": srv.URL + "/docs/"}
+	if got := c.RewrittenURLs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RewrittenURLs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// ContentRule decides what happens to a page depending on the presence of an element
+// in its extracted content. Exactly one of Selector or XPath must be set.
+type ContentRule struct {
+	Selector string `json:"selector,omitempty"` // CSS selector
+	XPath    string `json:"xpath,omitempty"`    // XPath expression
+	Match    string `json:"match,omitempty"`    // "present" (default) or "absent"
+	Action   string `json:"action"`             // "skip", "convert", or "remove"
+}
+
+// Rule actions
+const (
+	RuleActionSkip    = "skip"
+	RuleActionConvert = "convert"
+	RuleActionRemove  = "remove"
+)
+
+// Validate checks that the rule is well formed
+func (r ContentRule) Validate() error {
+	if (r.Selector == "") == (r.XPath == "") {
+		return fmt.Errorf("content rule must set exactly one of selector or xpath")
+	}
+
+	if r.XPath != "" {
+		if _, err := htmlquery.QueryAll(&html.Node{Type: html.DocumentNode}, r.XPath); err != nil {
+			return fmt.Errorf("invalid xpath %q: %w", r.XPath, err)
+		}
+	}
+
+	switch r.Match {
+	case "", "present", "absent":
+	default:
+		return fmt.Errorf("invalid content rule match %q", r.Match)
+	}
+
+	switch r.Action {
+	case RuleActionSkip, RuleActionConvert:
+	case RuleActionRemove:
+		if r.Match == "absent" {
+			return fmt.Errorf("content rule action %q cannot be combined with match \"absent\"", r.Action)
+		}
+	default:
+		return fmt.Errorf("invalid content rule action %q", r.Action)
+	}
+
+	return nil
+}
+
+// NewContentRuleHook builds a ContentHook from declarative rules.
+// Rules are evaluated in order: "remove" rules strip matching elements and evaluation continues,
+// while the first matching "skip" or "convert" rule ends evaluation.
+func NewContentRuleHook(rules []ContentRule) (ContentHook, error) {
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+
+	return func(page Page) (ContentAction, string) {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(page.Content))
+		if err != nil {
+			return ContentConvert, ""
+		}
+
+		transformed := false
+
+		for _, rule := range rules {
+			matches := rule.find(doc)
+			present := matches.Length() > 0
+
+			if rule.Action == RuleActionRemove {
+				if present {
+					matches.Remove()
+					transformed = true
+				}
+				continue
+			}
+
+			if present == (rule.Match == "absent") {
+				continue
+			}
+
+			if rule.Action == RuleActionSkip {
+				return ContentSkip, ""
+			}
+			break
+		}
+
+		if !transformed {
+			return ContentConvert, ""
+		}
+
+		content, err := doc.Find("body").Html()
+		if err != nil {
+			return ContentConvert, ""
+		}
+
+		return ContentTransform, content
+	}, nil
+}
+
+// find returns the elements matched by the rule
+func (r ContentRule) find(doc *goquery.Document) *goquery.Selection {
+	if r.Selector != "" {
+		return doc.Find(r.Selector)
+	}
+
+	nodes, err := htmlquery.QueryAll(doc.Get(0), r.XPath)
+	if err != nil {
+		return doc.FindNodes()
+	}
+
+	return doc.FindNodes(nodes...)
+}
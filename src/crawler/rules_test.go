@@ -0,0 +1,93 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContentRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    ContentRule
+		wantErr bool
+	}{
+		{name: "css skip", rule: ContentRule{Selector: ".login", Action: RuleActionSkip}},
+		{name: "xpath convert", rule: ContentRule{XPath: "//article", Match: "absent", Action: RuleActionConvert}},
+		{name: "missing target", rule: ContentRule{Action: RuleActionSkip}, wantErr: true},
+		{name: "both targets", rule: ContentRule{Selector: "a", XPath: "//a", Action: RuleActionSkip}, wantErr: true},
+		{name: "invalid xpath", rule: ContentRule{XPath: "//[", Action: RuleActionSkip}, wantErr: true},
+		{name: "invalid action", rule: ContentRule{Selector: "a", Action: "delete"}, wantErr: true},
+		{name: "invalid match", rule: ContentRule{Selector: "a", Match: "maybe", Action: RuleActionSkip}, wantErr: true},
+		{name: "remove absent", rule: ContentRule{Selector: "a", Match: "absent", Action: RuleActionRemove}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr && err == nil {
+				t.Errorf("Validate() expected error but got none")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewContentRuleHook(t *testing.T) {
+	hook, err := NewContentRuleHook([]ContentRule{
+		{Selector: ".share", Action: RuleActionRemove},
+		{XPath: "//form[@id='login']", Action: RuleActionSkip},
+		{Selector: "article", Match: "absent", Action: RuleActionSkip},
+	})
+	if err != nil {
+		t.Fatalf("NewContentRuleHook() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		content    string
+		wantAction ContentAction
+		wantHTML   string
+	}{
+		{
+			name:       "plain article",
+			content:    `<article><p>Text</p></article>`,
+			wantAction: ContentConvert,
+		},
+		{
+			name:       "login wall",
+			content:    `<article><form id="login"></form></article>`,
+			wantAction: ContentSkip,
+		},
+		{
+			name:       "listing page",
+			content:    `<ul><li>Item</li></ul>`,
+			wantAction: ContentSkip,
+		},
+		{
+			name:       "share widget removed",
+			content:    `<article><p>Text</p><div class="share">Share</div></article>`,
+			wantAction: ContentTransform,
+			wantHTML:   `<article><p>Text</p></article>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, content := hook(Page{Content: tt.content})
+			if action != tt.wantAction {
+				t.Fatalf("hook() action = %v, want %v", action, tt.wantAction)
+			}
+			if tt.wantHTML != "" && strings.TrimSpace(content) != tt.wantHTML {
+				t.Errorf("hook() content = %q, want %q", content, tt.wantHTML)
+			}
+		})
+	}
+}
+
+func TestNewContentRuleHookInvalidRule(t *testing.T) {
+	if _, err := NewContentRuleHook([]ContentRule{{Action: RuleActionSkip}}); err == nil {
+		t.Errorf("NewContentRuleHook() expected error for invalid rule")
+	}
+}
@@ -0,0 +1,49 @@
+package crawler
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ScopePrefix returns the path prefix that links on the start host must match to be followed.
+// An empty scope is derived from the directory of the start URL, so crawling /docs/ stays under /docs/;
+// the scope "/" follows the whole host.
+func ScopePrefix(startURL *url.URL, scope string) string {
+	if scope != "" {
+		if !strings.HasPrefix(scope, "/") {
+			scope = "/" + scope
+		}
+		return scope
+	}
+
+	startPath := startURL.Path
+	if startPath == "" || strings.HasSuffix(startPath, "/") {
+		return "/" + strings.TrimPrefix(startPath, "/")
+	}
+
+	dir := path.Dir(startPath)
+	if dir == "/" || dir == "." {
+		return "/"
+	}
+	return dir + "/"
+}
+
+// inScope reports whether a URL is under the scope prefix. URLs of other hosts are left to the domain options.
+func (c *Crawler) inScope(rawURL string) bool {
+	if c.scope == "/" {
+		return true
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return true
+	}
+
+	if strings.TrimPrefix(strings.ToLower(parsedURL.Hostname()), wwwPrefix) != strings.TrimPrefix(strings.ToLower(c.baseURL.Hostname()), wwwPrefix) {
+		return true
+	}
+
+	// The prefix directory itself is in scope without its trailing slash
+	return strings.HasPrefix(parsedURL.Path, c.scope) || parsedURL.Path+"/" == c.scope
+}
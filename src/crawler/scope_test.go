@@ -0,0 +1,129 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestScopePrefix(t *testing.T) {
+	tests := []struct {
+		startURL string
+		scope    string
+		want     string
+	}{
+		{startURL: "https://example.com", want: "/"},
+		{startURL: "https://example.com/", want: "/"},
+		{startURL: "https://example.com/docs/", want: "/docs/"},
+		{startURL: "https://example.com/docs/intro", want: "/docs/"},
+		{startURL: "https://example.com/docs", want: "/"},
+		{startURL: "https://example.com/docs/v2/api.html", want: "/docs/v2/"},
+		{startURL: "https://example.com/docs/intro", scope: "/", want: "/"},
+		{startURL: "https://example.com/docs/intro", scope: "docs/v2", want: "/docs/v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.startURL+" "+tt.scope, func(t *testing.T) {
+			startURL, err := url.Parse(tt.startURL)
+			if err != nil {
+				t.Fatalf("url.Parse() unexpected error: %v", err)
+			}
+			if got := ScopePrefix(startURL, tt.scope); got != tt.want {
+				t.Errorf("ScopePrefix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerScope(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main><a href="/docs/guide">Guide</a> <a href="/blog/news">News</a> <a href="/pricing">Pricing</a></main></body></html>`))
+	})
+	mux.HandleFunc("/docs/guide", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>Guide</main></body></html>`))
+	})
+	mux.HandleFunc("/blog/news", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>News</main></body></html>`))
+	})
+	mux.HandleFunc("/pricing", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><main>Pricing</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		scope    string
+		wantURLs []string
+	}{
+		{name: "derived", wantURLs: []string{srv.URL + "/docs/", srv.URL + "/docs/guide"}},
+		{name: "whole host", scope: "/", wantURLs: []string{srv.URL + "/blog/news", srv.URL + "/docs/", srv.URL + "/docs/guide", srv.URL + "/pricing"}},
+		{name: "explicit", scope: "/blog/", wantURLs: []string{srv.URL + "/blog/news", srv.URL + "/docs/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler(srv.URL+"/docs/", Options{MaxDepth: 2, Scope: tt.scope})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			var urls []string
+			for _, page := range c.GetPages() {
+				urls = append(urls, page.URL)
+			}
+			sort.Strings(urls)
+
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("crawled %v, want %v", urls, tt.wantURLs)
+			}
+		})
+	}
+}
+
+func TestCrawlerFollows(t *testing.T) {
+	c, err := NewCrawler("https://example.com/docs/", Options{
+		MaxDepth:         3,
+		ExcludedPaths:    []string{"https://example.com/docs/private"},
+		DeniedDomains:    []string{"ads.example.com"},
+		FollowPagination: true,
+	})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	page := Page{URL: "https://example.com/docs/guide", Depth: 1}
+	deepest := Page{URL: "https://example.com/docs/deep", Depth: 3, Next: "https://example.com/docs/deep?page=2"}
+
+	tests := []struct {
+		name string
+		page Page
+		link string
+		want bool
+	}{
+		{name: "page in scope", page: page, link: "https://example.com/docs/install", want: true},
+		{name: "excluded path", page: page, link: "https://example.com/docs/private/keys", want: false},
+		{name: "out of scope", page: page, link: "https://example.com/blog/news", want: false},
+		{name: "external host", page: page, link: "https://other.example.org/docs/", want: false},
+		{name: "denied domain", page: page, link: "https://ads.example.com/docs/", want: false},
+		{name: "page at the maximum depth", page: deepest, link: "https://example.com/docs/install", want: false},
+		{name: "next page at the maximum depth", page: deepest, link: deepest.Next, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Follows(tt.page, tt.link); got != tt.want {
+				t.Errorf("Follows(%s) = %v, want %v", tt.link, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package crawler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// SkipRules drop fetched pages that are not worth converting, such as soft 404s and login walls
+type SkipRules struct {
+	Titles   []string `json:"titles,omitempty"`    // Regular expressions matched case-insensitively against the page title
+	Content  []string `json:"content,omitempty"`   // Regular expressions matched case-insensitively against the text of the extracted content
+	MinWords int      `json:"min_words,omitempty"` // Minimum number of words in the extracted content, 0 disables the check
+}
+
+// Validate checks that the patterns compile and the word threshold is not negative
+func (r SkipRules) Validate() error {
+	_, _, err := r.compile()
+	if err != nil {
+		return err
+	}
+	if r.MinWords < 0 {
+		return fmt.Errorf("min_words must not be negative")
+	}
+	return nil
+}
+
+// IsZero reports whether no rule is set
+func (r SkipRules) IsZero() bool {
+	return len(r.Titles) == 0 && len(r.Content) == 0 && r.MinWords == 0
+}
+
+// compile compiles the title and content patterns
+func (r SkipRules) compile() ([]*regexp.Regexp, []*regexp.Regexp, error) {
+	titles, err := compilePatterns(r.Titles)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid title pattern: %w", err)
+	}
+	content, err := compilePatterns(r.Content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid content pattern: %w", err)
+	}
+	return titles, content, nil
+}
+
+// compilePatterns compiles case-insensitive regular expressions
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// NewSkipRuleHook builds a ContentHook skipping the pages matched by the rules,
+// reporting each skipped page and its reason to logf when it is not nil
+func NewSkipRuleHook(rules SkipRules, logf func(format string, args ...any)) (ContentHook, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	titles, content, err := rules.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(page Page) (ContentAction, string) {
+		if reason := skipReason(page, titles, content, rules.MinWords); reason != "" {
+			if logf != nil {
+				logf("Skipping (%s): %s\n", reason, page.URL)
+			}
+			return ContentSkip, ""
+		}
+		return ContentConvert, ""
+	}, nil
+}
+
+// skipReason returns why a page is skipped, or an empty string when it is kept
+func skipReason(page Page, titles, content []*regexp.Regexp, minWords int) string {
+	for _, re := range titles {
+		if re.MatchString(page.Title) {
+			return fmt.Sprintf("title matches %q", strings.TrimPrefix(re.String(), "(?i)"))
+		}
+	}
+
+	if len(content) == 0 && minWords == 0 {
+		return ""
+	}
+
+	text := page.Content
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(page.Content)); err == nil {
+		text = doc.Text()
+	}
+
+	for _, re := range content {
+		if re.MatchString(text) {
+			return fmt.Sprintf("content matches %q", strings.TrimPrefix(re.String(), "(?i)"))
+		}
+	}
+
+	if words := len(strings.Fields(text)); words < minWords {
+		return fmt.Sprintf("%d words, fewer than %d", words, minWords)
+	}
+
+	return ""
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"testing"
+)
+
+func TestSkipRulesValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   SkipRules
+		wantErr bool
+	}{
+		{name: "empty", rules: SkipRules{}},
+		{name: "valid", rules: SkipRules{Titles: []string{`\b404\b`, "page not found"}, Content: []string{"sign in to continue"}, MinWords: 50}},
+		{name: "invalid title", rules: SkipRules{Titles: []string{"("}}, wantErr: true},
+		{name: "invalid content", rules: SkipRules{Content: []string{"[a-"}}, wantErr: true},
+		{name: "negative words", rules: SkipRules{MinWords: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSkipRuleHook(t *testing.T) {
+	hook, err := NewSkipRuleHook(SkipRules{
+		Titles:   []string{`\b404\b`, "page not found", "^log ?in"},
+		Content:  []string{`sign in to (continue|read)`},
+		MinWords: 5,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewSkipRuleHook() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		page Page
+		want ContentAction
+	}{
+		{
+			name: "regular page",
+			page: Page{Title: "Install guide", Content: "<p>Run the installer and follow the steps.</p>"},
+			want: ContentConvert,
+		},
+		{
+			name: "soft 404 title",
+			page: Page{Title: "404 - Page Not Found", Content: "<p>Run the installer and follow the steps.</p>"},
+			want: ContentSkip,
+		},
+		{
+			name: "login title",
+			page: Page{Title: "Login | Example", Content: "<p>Run the installer and follow the steps.</p>"},
+			want: ContentSkip,
+		},
+		{
+			name: "login wall content",
+			page: Page{Title: "Article", Content: "<p>This article is for members.</p><p>Sign in to <b>continue</b> reading.</p>"},
+			want: ContentSkip,
+		},
+		{
+			name: "too few words",
+			page: Page{Title: "Tag", Content: "<ul><li>One</li><li>Two</li></ul>"},
+			want: ContentSkip,
+		},
+		{
+			name: "markup does not count as words",
+			page: Page{Title: "Short", Content: `<div class="a b c d e f"><p>Hello there</p></div>`},
+			want: ContentSkip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := hook(tt.page); got != tt.want {
+				t.Errorf("hook() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package crawler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gocolly/colly"
+)
+
+// isSuccessStatus reports whether colly handles a status as a successful response
+func isSuccessStatus(status int) bool {
+	return status < 203
+}
+
+// keepsStatus reports whether pages with the status are kept
+func (c *Crawler) keepsStatus(status int) bool {
+	return isSuccessStatus(status) || slices.Contains(c.options.KeepStatuses, status)
+}
+
+// followsStatus reports whether the links of pages with the status are followed; links of kept pages always are
+func (c *Crawler) followsStatus(status int) bool {
+	return c.keepsStatus(status) || slices.Contains(c.options.FollowStatuses, status)
+}
+
+// reportStatus reports error responses that are neither kept nor followed, as colly does when they are not parsed.
+// It returns false when the response must not be handled further.
+func (c *Crawler) reportStatus(r *colly.Response) bool {
+	if c.followsStatus(r.StatusCode) {
+		return true
+	}
+	c.reportError(r, errors.New(http.StatusText(r.StatusCode)))
+	return false
+}
@@ -0,0 +1,93 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCrawlerStatusPolicy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<html><head><title>Not found</title></head><body><main>Missing. <a href="/from-404">Home</a></main></body></html>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><main><a href="/missing">Missing</a> <a href="/private">Private</a></main></body></html>`))
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`<html><body><main>Forbidden. <a href="/from-403">Public</a></main></body></html>`))
+	})
+	for _, path := range []string{"/from-404", "/from-403"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html><body><main>Reached</main></body></html>`))
+		})
+	}
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		keep       []int
+		follow     []int
+		wantPages  map[string]int
+		wantErrors []string
+	}{
+		{
+			name:       "error pages reported by default",
+			wantPages:  map[string]int{"/": 200},
+			wantErrors: []string{"/missing", "/private"},
+		},
+		{
+			name:      "kept and followed statuses",
+			keep:      []int{http.StatusNotFound},
+			follow:    []int{http.StatusForbidden},
+			wantPages: map[string]int{"/": 200, "/missing": 404, "/from-404": 200, "/from-403": 200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler(srv.URL, Options{MaxDepth: 3, KeepStatuses: tt.keep, FollowStatuses: tt.follow})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			var errorURLs []string
+			var errorsMutex sync.Mutex
+			c.OnError(func(pageURL string, err error) {
+				errorsMutex.Lock()
+				errorURLs = append(errorURLs, strings.TrimPrefix(pageURL, srv.URL))
+				errorsMutex.Unlock()
+			})
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			pages := map[string]int{}
+			for _, page := range c.GetPages() {
+				path := strings.TrimPrefix(page.URL, srv.URL)
+				if path == "" {
+					path = "/"
+				}
+				pages[path] = page.Status
+			}
+			if !reflect.DeepEqual(pages, tt.wantPages) {
+				t.Errorf("pages = %v, want %v", pages, tt.wantPages)
+			}
+
+			sort.Strings(errorURLs)
+			if !reflect.DeepEqual(errorURLs, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", errorURLs, tt.wantErrors)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// DefaultStripSelectors match overlay markup removed before the main content is extracted:
+// cookie consent banners, newsletter modals, and social share widgets
+var DefaultStripSelectors = []string{
+	// Cookie consent banners
+	"#onetrust-consent-sdk",
+	"#onetrust-banner-sdk",
+	"#CybotCookiebotDialog",
+	"#usercentrics-root",
+	"#didomi-host",
+	".qc-cmp2-container",
+	"#cookie-banner",
+	"#cookie-notice",
+	"#cookie-consent",
+	".cookie-banner",
+	".cookie-notice",
+	".cookie-consent",
+	".cc-window",
+	".cc-banner",
+	".gdpr-banner",
+	`[aria-label="cookieconsent" i]`,
+	// Newsletter and subscription modals
+	`[aria-modal="true"]`,
+	".modal-backdrop",
+	".newsletter-modal",
+	".newsletter-popup",
+	"#newsletter-popup",
+	".subscribe-modal",
+	".mc-modal",
+	// Share widgets
+	".share-buttons",
+	".social-share",
+	".share-links",
+	".sharedaddy",
+	".addthis_toolbox",
+	".a2a_kit",
+	".sharethis-inline-share-buttons",
+}
+
+// hasRequiredElement reports whether the page has an element matching one of the selectors, or no selectors are required
+func hasRequiredElement(root *goquery.Selection, selectors []string) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+	return root.Find(strings.Join(selectors, ", ")).Length() > 0
+}
+
+// ValidateSelector checks that a CSS selector can be parsed
+func ValidateSelector(selector string) error {
+	if _, err := cascadia.ParseGroup(selector); err != nil {
+		return fmt.Errorf("invalid selector %q: %w", selector, err)
+	}
+	return nil
+}
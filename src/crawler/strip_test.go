@@ -0,0 +1,147 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCrawlerStripSelectors(t *testing.T) {
+	body := `<html><body>
+<div id="onetrust-consent-sdk"><div class="content">We use cookies <a href="/privacy">Privacy</a></div></div>
+<div class="newsletter-modal" aria-modal="true">Subscribe now</div>
+<p>Article text</p>
+<div class="share-buttons">Share on X</div>
+<div class="promo">Promo</div>
+</body></html>`
+
+	tests := []struct {
+		name      string
+		selectors []string
+		want      []string
+		notWant   []string
+	}{
+		{
+			name:    "banner picked as main content without selectors",
+			want:    []string{"We use cookies"},
+			notWant: []string{"Article text"},
+		},
+		{
+			name:      "default and custom selectors",
+			selectors: append(append([]string{}, DefaultStripSelectors...), ".promo"),
+			want:      []string{"Article text"},
+			notWant:   []string{"We use cookies", "Subscribe now", "Share on X", "Promo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(body))
+			})
+			mux.HandleFunc("/privacy", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`<html><body><main>Privacy</main></body></html>`))
+			})
+
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			c, err := NewCrawler(srv.URL, Options{StripSelectors: tt.selectors})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			var home Page
+			for _, page := range c.GetPages() {
+				if page.URL == srv.URL {
+					home = page
+				}
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(home.Content, want) {
+					t.Errorf("Content = %q, want it to contain %q", home.Content, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(home.Content, notWant) {
+					t.Errorf("Content = %q, want %q stripped", home.Content, notWant)
+				}
+			}
+
+			if len(c.GetPages()) != 2 {
+				t.Errorf("expected links inside stripped elements to be followed, got %d pages", len(c.GetPages()))
+			}
+		})
+	}
+}
+
+func TestCrawlerRequireSelectors(t *testing.T) {
+	mux := http.NewServeMux()
+	page := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html><body>` + body + `</body></html>`))
+		}
+	}
+	mux.HandleFunc("/", page(`<main><a href="/category">Category</a></main>`))
+	mux.HandleFunc("/category", page(`<main><a href="/post">Post</a> <a href="/guide">Guide</a></main>`))
+	mux.HandleFunc("/post", page(`<article class="doc-content"><p>Post text</p></article>`))
+	mux.HandleFunc("/guide", page(`<div class="guide"><p>Guide text</p></div>`))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := NewCrawler(srv.URL, Options{MaxDepth: 3, RequireSelectors: []string{"article.doc-content", ".guide"}})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	var skipsMutex sync.Mutex
+	var skipped []string
+	c.OnSkip(func(pageURL string, reason SkipReason) {
+		if reason == SkipSelector {
+			skipsMutex.Lock()
+			skipped = append(skipped, strings.TrimPrefix(pageURL, srv.URL))
+			skipsMutex.Unlock()
+		}
+	})
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	var kept []string
+	for _, page := range c.GetPages() {
+		kept = append(kept, strings.TrimPrefix(page.URL, srv.URL))
+	}
+	sort.Strings(kept)
+	sort.Strings(skipped)
+
+	if want := []string{"/guide", "/post"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept pages = %v, want %v", kept, want)
+	}
+	if want := []string{"", "/category"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped pages = %v, want %v", skipped, want)
+	}
+}
+
+func TestValidateSelector(t *testing.T) {
+	for _, selector := range DefaultStripSelectors {
+		if err := ValidateSelector(selector); err != nil {
+			t.Errorf("ValidateSelector(%q) unexpected error: %v", selector, err)
+		}
+	}
+
+	if err := ValidateSelector("div[unclosed"); err == nil {
+		t.Errorf("ValidateSelector() expected error for an invalid selector")
+	}
+}
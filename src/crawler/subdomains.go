@@ -0,0 +1,96 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+const wwwPrefix = "www."
+
+// SiteDomain returns the registrable domain of a host, e.g. example.com for docs.example.com.
+// Hosts without a public suffix, such as localhost or IP addresses, are returned unchanged.
+func SiteDomain(host string) string {
+	hostname := strings.ToLower(host)
+	if parsed, err := url.Parse("//" + host); err == nil && parsed.Hostname() != "" {
+		hostname = strings.ToLower(parsed.Hostname())
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(hostname)
+	if err != nil {
+		return hostname
+	}
+	return domain
+}
+
+// isSameSite reports whether a host belongs to the crawled site, including its subdomains when enabled
+func (c *Crawler) isSameSite(host string) bool {
+	if strings.EqualFold(host, c.baseURL.Host) {
+		return true
+	}
+	return c.options.IncludeSubdomains && MatchesDomain(host, c.siteDomain)
+}
+
+// preferredHost returns the host used for the www and apex variants of the site domain:
+// the start host when it is one of them, the apex otherwise
+func (c *Crawler) preferredHost() string {
+	hostname := strings.ToLower(c.baseURL.Hostname())
+	if hostname == wwwPrefix+c.siteDomain {
+		return hostname
+	}
+	return c.siteDomain
+}
+
+// foldWWW rewrites the www and apex variants of the site domain to the preferred host
+func (c *Crawler) foldWWW(rawURL string) string {
+	if !c.options.IncludeSubdomains {
+		return rawURL
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	hostname := strings.ToLower(parsedURL.Hostname())
+	if hostname != c.siteDomain && hostname != wwwPrefix+c.siteDomain {
+		return rawURL
+	}
+
+	preferred := c.preferredHost()
+	if hostname == preferred {
+		return rawURL
+	}
+
+	if port := parsedURL.Port(); port != "" {
+		preferred += ":" + port
+	}
+	parsedURL.Host = preferred
+
+	return parsedURL.String()
+}
+
+// hostAliases returns the www or apex variant of a page URL on the preferred host, which links may still use
+func (c *Crawler) hostAliases(pageURL string) []string {
+	if !c.options.IncludeSubdomains {
+		return nil
+	}
+
+	parsedURL, err := url.Parse(pageURL)
+	if err != nil || !strings.EqualFold(parsedURL.Hostname(), c.preferredHost()) {
+		return nil
+	}
+
+	other := wwwPrefix + c.siteDomain
+	if strings.HasPrefix(c.preferredHost(), wwwPrefix) {
+		other = c.siteDomain
+	}
+
+	if port := parsedURL.Port(); port != "" {
+		other += ":" + port
+	}
+	parsedURL.Host = other
+
+	return []string{parsedURL.String()}
+}
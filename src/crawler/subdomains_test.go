@@ -0,0 +1,125 @@
+package crawler
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSiteDomain(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "docs.example.com", want: "example.com"},
+		{host: "www.example.co.uk", want: "example.co.uk"},
+		{host: "Example.com:8080", want: "example.com"},
+		{host: "localhost:8080", want: "localhost"},
+		{host: "127.0.0.1", want: "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := SiteDomain(tt.host); got != tt.want {
+				t.Errorf("SiteDomain(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawlerIncludeSubdomains(t *testing.T) {
+	tests := []struct {
+		name        string
+		startURL    string
+		opts        Options
+		url         string
+		wantAllowed bool
+		wantFolded  string
+	}{
+		{
+			name:        "subdomain allowed",
+			startURL:    "https://docs.example.com/",
+			opts:        Options{IncludeSubdomains: true},
+			url:         "https://api.example.com/v1",
+			wantAllowed: true,
+			wantFolded:  "https://api.example.com/v1",
+		},
+		{
+			name:        "other domain rejected",
+			startURL:    "https://docs.example.com/",
+			opts:        Options{IncludeSubdomains: true},
+			url:         "https://example.org/",
+			wantAllowed: false,
+			wantFolded:  "https://example.org/",
+		},
+		{
+			name:        "www folded to apex",
+			startURL:    "https://docs.example.com/",
+			opts:        Options{IncludeSubdomains: true},
+			url:         "https://www.example.com/about",
+			wantAllowed: true,
+			wantFolded:  "https://example.com/about",
+		},
+		{
+			name:        "apex folded to www start host",
+			startURL:    "https://www.example.com/",
+			opts:        Options{IncludeSubdomains: true},
+			url:         "https://example.com/about",
+			wantAllowed: true,
+			wantFolded:  "https://www.example.com/about",
+		},
+		{
+			name:        "other domain allowed with external links",
+			startURL:    "https://docs.example.com/",
+			opts:        Options{IncludeSubdomains: true, FollowExternalLinks: true},
+			url:         "https://example.org/",
+			wantAllowed: true,
+			wantFolded:  "https://example.org/",
+		},
+		{
+			name:        "no folding when disabled",
+			startURL:    "https://www.example.com/",
+			url:         "https://example.com/about",
+			wantAllowed: true,
+			wantFolded:  "https://example.com/about",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler(tt.startURL, tt.opts)
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			parsedURL, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatalf("url.Parse() unexpected error: %v", err)
+			}
+
+			if got := c.isDomainAllowed(parsedURL); got != tt.wantAllowed {
+				t.Errorf("isDomainAllowed(%s) = %v, want %v", tt.url, got, tt.wantAllowed)
+			}
+
+			if got := c.foldWWW(tt.url); got != tt.wantFolded {
+				t.Errorf("foldWWW(%s) = %q, want %q", tt.url, got, tt.wantFolded)
+			}
+		})
+	}
+}
+
+func TestCrawlerHostAliases(t *testing.T) {
+	c, err := NewCrawler("https://www.example.com/", Options{IncludeSubdomains: true})
+	if err != nil {
+		t.Fatalf("NewCrawler() unexpected error: %v", err)
+	}
+
+	want := []string{"https://example.com/docs?a=1"}
+	if got := c.hostAliases("https://www.example.com/docs?a=1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("hostAliases() = %v, want %v", got, want)
+	}
+
+	if got := c.hostAliases("https://api.example.com/docs"); got != nil {
+		t.Errorf("hostAliases() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,124 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// TLS versions accepted as the minimum version of connections
+const (
+	TLSVersion10 = "1.0"
+	TLSVersion11 = "1.1"
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+// ParseTLSVersion returns the crypto/tls constant of a TLS version
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case TLSVersion10:
+		return tls.VersionTLS10, nil
+	case TLSVersion11:
+		return tls.VersionTLS11, nil
+	case TLSVersion12:
+		return tls.VersionTLS12, nil
+	case TLSVersion13:
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version %q (expected %s, %s, %s, or %s)", version, TLSVersion10, TLSVersion11, TLSVersion12, TLSVersion13)
+	}
+}
+
+// tunesTransport reports whether any connection option differs from the defaults of http.DefaultTransport
+func tunesTransport(opts Options) bool {
+	return opts.DisableKeepAlives || opts.MaxIdleConnsPerHost > 0 || opts.DisableHTTP2 ||
+		opts.TLSMinVersion != "" || opts.CACertFile != "" || opts.InsecureSkipVerify ||
+		opts.DenyPrivateNetworks || len(opts.DeniedNetworks) > 0 || len(opts.HostAddresses) > 0
+}
+
+// newBaseTransport returns the transport sending the requests of the crawler: http.DefaultTransport,
+// or a copy of it tuned with the connection and address options
+func newBaseTransport(opts Options) (http.RoundTripper, error) {
+	if !tunesTransport(opts) {
+		return http.DefaultTransport, nil
+	}
+
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("default transport is not an *http.Transport")
+	}
+	transport := base.Clone()
+
+	filter, err := NewAddressFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	if filter != nil || len(opts.HostAddresses) > 0 {
+		// The dialer of http.DefaultTransport
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		if filter != nil {
+			dialer.Control = filter.control
+		}
+		dial, err := resolvingDial(dialer.DialContext, opts.HostAddresses)
+		if err != nil {
+			return nil, err
+		}
+		transport.DialContext = dial
+		// Through a proxy, the filter would only see the address of the proxy, which would resolve the hosts itself
+		transport.Proxy = nil
+	}
+
+	transport.DisableKeepAlives = opts.DisableKeepAlives
+	if opts.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+		transport.MaxIdleConns = max(transport.MaxIdleConns, opts.MaxIdleConnsPerHost)
+	}
+	if opts.DisableHTTP2 {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		transport.Protocols = protocols
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.TLSMinVersion != "" {
+		version, err := ParseTLSVersion(opts.TLSMinVersion)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.MinVersion = version
+	}
+	if opts.CACertFile != "" {
+		pool, err := certPool(opts.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	//nolint:gosec // Certificate verification is only skipped when asked for, to crawl internal sites.
+	tlsConfig.InsecureSkipVerify = opts.InsecureSkipVerify
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// certPool returns the system certificate authorities with those of a PEM file added
+func certPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file) //nolint:gosec // The CA bundle is chosen by the user.
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", file)
+	}
+	return pool, nil
+}
@@ -0,0 +1,93 @@
+package crawler
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestCrawlerTransportOptions(t *testing.T) {
+	var protoMutex sync.Mutex
+	proto := 0
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protoMutex.Lock()
+		proto = r.ProtoMajor
+		protoMutex.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Internal</title></head><body><main><p>Internal content</p></main></body></html>`))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600); err != nil {
+		t.Fatalf("writing CA file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		wantPages int
+		wantProto int
+	}{
+		{name: "untrusted certificate", opts: Options{}, wantPages: 0},
+		{name: "custom CA bundle", opts: Options{CACertFile: caFile}, wantPages: 1, wantProto: 2},
+		{name: "HTTP/2 disabled", opts: Options{CACertFile: caFile, DisableHTTP2: true}, wantPages: 1, wantProto: 1},
+		{name: "insecure skip verify", opts: Options{InsecureSkipVerify: true, DisableKeepAlives: true, MaxIdleConnsPerHost: 4}, wantPages: 1, wantProto: 2},
+		{name: "TLS 1.3", opts: Options{InsecureSkipVerify: true, TLSMinVersion: TLSVersion13}, wantPages: 1, wantProto: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protoMutex.Lock()
+			proto = 0
+			protoMutex.Unlock()
+
+			tt.opts.SinglePage = true
+			tt.opts.IgnoreRobotsTxt = true
+			c, err := NewCrawler(srv.URL, tt.opts)
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+			_ = c.Start()
+
+			if pages := c.GetPages(); len(pages) != tt.wantPages {
+				t.Fatalf("GetPages() = %d pages, want %d", len(pages), tt.wantPages)
+			}
+			protoMutex.Lock()
+			defer protoMutex.Unlock()
+			if tt.wantPages > 0 && proto != tt.wantProto {
+				t.Errorf("request protocol = HTTP/%d, want HTTP/%d", proto, tt.wantProto)
+			}
+		})
+	}
+}
+
+func TestNewCrawlerInvalidTransportOptions(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("writing CA file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "unknown TLS version", opts: Options{TLSMinVersion: "1.4"}},
+		{name: "missing CA file", opts: Options{CACertFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{name: "CA file without certificates", opts: Options{CACertFile: notPEM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCrawler("https://example.com", tt.opts); err == nil {
+				t.Error("NewCrawler() expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package crawler
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gocolly/colly"
+)
+
+// Traversal orders of the crawl
+const (
+	// TraversalParallel fetches discovered links concurrently as they are found; the order of pages varies between runs
+	TraversalParallel = "parallel"
+	// TraversalBreadthFirst fetches one page at a time, all pages of a depth before the next depth
+	TraversalBreadthFirst = "breadth-first"
+	// TraversalDepthFirst fetches one page at a time, following the first link of each page before its next links
+	TraversalDepthFirst = "depth-first"
+)
+
+// ValidateTraversal checks that a traversal order is known
+func ValidateTraversal(traversal string) error {
+	switch traversal {
+	case "", TraversalParallel, TraversalBreadthFirst, TraversalDepthFirst:
+		return nil
+	default:
+		return fmt.Errorf("unknown traversal %q (expected %s, %s, or %s)", traversal, TraversalParallel, TraversalBreadthFirst, TraversalDepthFirst)
+	}
+}
+
+// traversal returns the effective traversal order: deterministic crawls, crawls with priority rules,
+// and crawls with a shared frontier default to breadth-first
+func traversal(opts Options) string {
+	if opts.Traversal == "" || opts.Traversal == TraversalParallel {
+		if opts.Deterministic || len(opts.Priorities) > 0 || opts.Frontier != nil {
+			return TraversalBreadthFirst
+		}
+		return TraversalParallel
+	}
+	return opts.Traversal
+}
+
+// QueuedLink is a discovered link waiting in the frontier of an ordered crawl
+type QueuedLink struct {
+	URL      string `json:"url"`
+	Depth    int    `json:"depth"` // Depth the link is visited at, 1 for the start URL
+	Priority int    `json:"-"`     // Weight of the first matching priority rule
+}
+
+// Frontier holds the links waiting to be visited by ordered crawls, which visit them one at a time in the order
+// Pop returns them. A frontier shared between processes lets several crawlers split a crawl: each link pushed by
+// any of them is popped by one.
+type Frontier interface {
+	// Push queues the links found on a page, in page order
+	Push(links []QueuedLink) error
+	// Pop returns the next link to visit, or false once no link is left
+	Pop() (QueuedLink, bool, error)
+	// Done reports that a popped link was handled and the links of its page were pushed
+	Done(link QueuedLink) error
+}
+
+// memoryFrontier is the frontier of a crawl run by a single process.
+// Links are popped highest priority first, then in traversal order, so pages are fetched and numbered
+// in the same order on every run of a static site.
+type memoryFrontier struct {
+	mutex     sync.Mutex
+	depthLast bool // Pops the links of the last page first, for depth-first traversal
+	links     frontierHeap
+	sequence  int // Number of links pushed
+	pushes    int // Number of pages whose links were pushed
+}
+
+func newMemoryFrontier(order string) *memoryFrontier {
+	return &memoryFrontier{depthLast: order == TraversalDepthFirst}
+}
+
+// Push queues the links of a page
+func (f *memoryFrontier) Push(links []QueuedLink) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.pushes++
+	for i, link := range links {
+		f.sequence++
+		sequence := f.sequence
+		if f.depthLast {
+			// The links of the last page come first, and the first link of a page before its next links
+			sequence = -f.pushes<<24 + i
+		}
+		heap.Push(&f.links, frontierLink{QueuedLink: link, sequence: sequence})
+	}
+	return nil
+}
+
+// Pop returns the next link to visit
+func (f *memoryFrontier) Pop() (QueuedLink, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.links.Len() == 0 {
+		return QueuedLink{}, false, nil
+	}
+	link, _ := heap.Pop(&f.links).(frontierLink)
+	return link.QueuedLink, true, nil
+}
+
+// Done does nothing, the links of a page are pushed before its link is handled
+func (f *memoryFrontier) Done(QueuedLink) error {
+	return nil
+}
+
+// frontierLink is a queued link with the order it was queued in, which breaks ties between links of the same priority
+type frontierLink struct {
+	QueuedLink
+	sequence int
+}
+
+// frontierHeap orders links by descending priority, then by ascending sequence
+type frontierHeap []frontierLink
+
+func (h frontierHeap) Len() int { return len(h) }
+
+func (h frontierHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority > h[j].Priority
+	}
+	return h[i].sequence < h[j].sequence
+}
+
+func (h frontierHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *frontierHeap) Push(x any) {
+	if link, ok := x.(frontierLink); ok {
+		*h = append(*h, link)
+	}
+}
+
+func (h *frontierHeap) Pop() any {
+	old := *h
+	link := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return link
+}
+
+// pendingLinks collects the links of the pages being handled, pushed to the frontier once a page is handled
+type pendingLinks struct {
+	mutex sync.Mutex
+	links map[*colly.Request][]QueuedLink
+}
+
+// add records a link found on the page of a request
+func (p *pendingLinks) add(from *colly.Request, link QueuedLink) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.links == nil {
+		p.links = make(map[*colly.Request][]QueuedLink)
+	}
+	p.links[from] = append(p.links[from], link)
+}
+
+// take removes and returns the links found on the page of a request
+func (p *pendingLinks) take(from *colly.Request) []QueuedLink {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	links := p.links[from]
+	delete(p.links, from)
+	return links
+}
+
+// queueLink records a link found on a page, to be pushed to the frontier once the page is handled
+func (c *Crawler) queueLink(from *colly.Request, linkURL string) {
+	c.pending.add(from, QueuedLink{URL: linkURL, Depth: from.Depth + 1, Priority: c.priority(linkURL)})
+}
+
+// pushLinks pushes the links found on the page of a request to the frontier
+func (c *Crawler) pushLinks(from *colly.Request) {
+	links := c.pending.take(from)
+	if len(links) == 0 {
+		return
+	}
+	if err := c.frontier.Push(links); err != nil {
+		c.logf(VerbosityQuiet, "Error queueing links of %s: %v\n", from.URL.String(), err)
+	}
+}
+
+// drain visits the queued links one at a time until none are left or the crawl budget is used up
+func (c *Crawler) drain() error {
+	for !c.outOfBudget() {
+		link, ok, err := c.frontier.Pop()
+		if err != nil {
+			return fmt.Errorf("failed to read the crawl frontier: %w", err)
+		}
+		if !ok {
+			return nil
+		}
+
+		if reason := linkSkipReason(c.visitQueued(link)); reason != "" {
+			c.logf(VerbosityVerbose, "Skipping (%s): %s\n", reason, link.URL)
+		}
+
+		if err := c.frontier.Done(link); err != nil {
+			return fmt.Errorf("failed to update the crawl frontier: %w", err)
+		}
+	}
+	return nil
+}
+
+// visitQueued fetches a queued link at its depth
+func (c *Crawler) visitQueued(link QueuedLink) error {
+	// Requests are created through colly, which ties them to the collector
+	data, err := json.Marshal(struct{ URL, Method string }{URL: link.URL, Method: http.MethodGet})
+	if err != nil {
+		return err
+	}
+	request, err := c.collector.UnmarshalRequest(data)
+	if err != nil {
+		return err
+	}
+	request.Depth = link.Depth
+	return request.Do()
+}
@@ -0,0 +1,75 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCrawlerTraversalOrder(t *testing.T) {
+	site := map[string]string{
+		"/":    `<a href="/a">A</a> <a href="/b">B</a>`,
+		"/a":   `<a href="/a/1">A1</a> <a href="/a/2">A2</a>`,
+		"/b":   `<a href="/b/1">B1</a>`,
+		"/a/1": `A1`,
+		"/a/2": `A2`,
+		"/b/1": `B1`,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := site[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`<html><body><main>` + body + `</main></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name          string
+		traversal     string
+		deterministic bool
+		wantPaths     []string
+	}{
+		{name: "deterministic", deterministic: true, wantPaths: []string{"/", "/a", "/b", "/a/1", "/a/2", "/b/1"}},
+		{name: "breadth-first", traversal: TraversalBreadthFirst, wantPaths: []string{"/", "/a", "/b", "/a/1", "/a/2", "/b/1"}},
+		{name: "depth-first", traversal: TraversalDepthFirst, wantPaths: []string{"/", "/a", "/a/1", "/a/2", "/b", "/b/1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCrawler(srv.URL+"/", Options{MaxDepth: 3, Traversal: tt.traversal, Deterministic: tt.deterministic})
+			if err != nil {
+				t.Fatalf("NewCrawler() unexpected error: %v", err)
+			}
+
+			if err := c.Start(); err != nil {
+				t.Fatalf("Start() unexpected error: %v", err)
+			}
+
+			var paths []string
+			for _, page := range c.GetPages() {
+				paths = append(paths, page.URL[len(srv.URL):])
+			}
+
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("crawled %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestValidateTraversal(t *testing.T) {
+	for _, traversal := range []string{"", TraversalParallel, TraversalBreadthFirst, TraversalDepthFirst} {
+		if err := ValidateTraversal(traversal); err != nil {
+			t.Errorf("ValidateTraversal(%q) unexpected error: %v", traversal, err)
+		}
+	}
+	if err := ValidateTraversal("random"); err == nil {
+		t.Error("ValidateTraversal(\"random\") expected an error")
+	}
+}
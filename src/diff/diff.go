@@ -0,0 +1,111 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Report lists the Markdown files that differ between two output directories
+type Report struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Empty reports whether both directories contain the same Markdown files
+func (r Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// CompareDirs compares the Markdown files of two output directories.
+// Paths in the report are slash-separated and relative to the directories.
+func CompareDirs(oldDir, newDir string) (Report, error) {
+	oldFiles, err := listMarkdownFiles(oldDir)
+	if err != nil {
+		return Report{}, err
+	}
+
+	newFiles, err := listMarkdownFiles(newDir)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{}
+
+	for name := range newFiles {
+		if !oldFiles[name] {
+			report.Added = append(report.Added, name)
+			continue
+		}
+
+		same, err := sameContent(filepath.Join(oldDir, filepath.FromSlash(name)), filepath.Join(newDir, filepath.FromSlash(name)))
+		if err != nil {
+			return Report{}, err
+		}
+		if !same {
+			report.Changed = append(report.Changed, name)
+		}
+	}
+
+	for name := range oldFiles {
+		if !newFiles[name] {
+			report.Removed = append(report.Removed, name)
+		}
+	}
+
+	sort.Strings(report.Added)
+	sort.Strings(report.Removed)
+	sort.Strings(report.Changed)
+
+	return report, nil
+}
+
+// listMarkdownFiles returns the set of Markdown files below dir
+func listMarkdownFiles(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s: %w", dir, err)
+	}
+
+	return files, nil
+}
+
+// sameContent reports whether two files have identical content
+func sameContent(pathA, pathB string) (bool, error) {
+	//nolint:gosec // Paths come from walking user-provided directories.
+	a, err := os.ReadFile(pathA)
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	//nolint:gosec // Paths come from walking user-provided directories.
+	b, err := os.ReadFile(pathB)
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return bytes.Equal(a, b), nil
+}
@@ -0,0 +1,55 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("MkdirAll() failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+}
+
+func TestCompareDirs(t *testing.T) {
+	oldDir := t.TempDir()
+	newDir := t.TempDir()
+
+	writeFile(t, oldDir, "same.md", "same")
+	writeFile(t, newDir, "same.md", "same")
+	writeFile(t, oldDir, "changed.md", "old")
+	writeFile(t, newDir, "changed.md", "new")
+	writeFile(t, oldDir, "removed.md", "gone")
+	writeFile(t, newDir, "docs/added.md", "fresh")
+	writeFile(t, newDir, "manifest.json", "{}")
+
+	report, err := CompareDirs(oldDir, newDir)
+	if err != nil {
+		t.Fatalf("CompareDirs() unexpected error: %v", err)
+	}
+
+	if len(report.Added) != 1 || report.Added[0] != "docs/added.md" {
+		t.Errorf("CompareDirs() Added = %v", report.Added)
+	}
+
+	if len(report.Removed) != 1 || report.Removed[0] != "removed.md" {
+		t.Errorf("CompareDirs() Removed = %v", report.Removed)
+	}
+
+	if len(report.Changed) != 1 || report.Changed[0] != "changed.md" {
+		t.Errorf("CompareDirs() Changed = %v", report.Changed)
+	}
+}
+
+func TestCompareDirsMissingDirectory(t *testing.T) {
+	if _, err := CompareDirs(filepath.Join(t.TempDir(), "missing"), t.TempDir()); err == nil {
+		t.Errorf("CompareDirs() expected error for missing directory")
+	}
+}
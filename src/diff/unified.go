@@ -0,0 +1,252 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type operation int
+
+const (
+	opEqual operation = iota
+	opDelete
+	opInsert
+)
+
+type edit struct {
+	op   operation
+	line string
+}
+
+// Unified returns a unified diff between two texts, or an empty string if they are equal
+func Unified(fromName, toName, from, to string, context int) string {
+	edits := diffLines(splitLines(from), splitLines(to))
+
+	hasChanges := false
+	for _, e := range edits {
+		if e.op != opEqual {
+			hasChanges = true
+			break
+		}
+	}
+	if !hasChanges {
+		return ""
+	}
+
+	// Line positions in both texts before each edit
+	fromPos := make([]int, len(edits)+1)
+	toPos := make([]int, len(edits)+1)
+	for i, e := range edits {
+		fromPos[i+1] = fromPos[i]
+		toPos[i+1] = toPos[i]
+		if e.op != opInsert {
+			fromPos[i+1]++
+		}
+		if e.op != opDelete {
+			toPos[i+1]++
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("--- " + fromName + "\n")
+	builder.WriteString("+++ " + toName + "\n")
+
+	i := 0
+	for i < len(edits) {
+		for i < len(edits) && edits[i].op == opEqual {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		last := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].op != opEqual {
+				last = j
+			} else if j-last > 2*context {
+				break
+			}
+		}
+
+		start := max(i-context, 0)
+		end := min(last+context+1, len(edits))
+
+		builder.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(fromPos[start], fromPos[end]-fromPos[start]),
+			hunkRange(toPos[start], toPos[end]-toPos[start])))
+
+		for _, e := range edits[start:end] {
+			switch e.op {
+			case opEqual:
+				builder.WriteString(" " + e.line + "\n")
+			case opDelete:
+				builder.WriteString("-" + e.line + "\n")
+			case opInsert:
+				builder.WriteString("+" + e.line + "\n")
+			}
+		}
+
+		i = end
+	}
+
+	return builder.String()
+}
+
+// hunkRange formats the start,count part of a hunk header
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits text into lines without trailing newline characters
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes a shortest edit script between two line slices using the linear space
+// variant of the Myers algorithm, which splits the texts at the middle snake of an optimal path
+// instead of keeping the furthest reaching paths of every step
+func diffLines(a, b []string) []edit {
+	size := 2*((len(a)+len(b)+1)/2) + 2
+	d := &differ{forward: make([]int, size), backward: make([]int, size)}
+	d.compare(a, b)
+	return d.edits
+}
+
+// differ accumulates the edits of diffLines, reusing the path buffers across the recursion
+type differ struct {
+	edits    []edit
+	forward  []int
+	backward []int
+}
+
+// compare appends the edits turning a into b
+func (d *differ) compare(a, b []string) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for _, line := range a[:prefix] {
+		d.edits = append(d.edits, edit{op: opEqual, line: line})
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch x, y, ok := d.split(a, b); {
+	case ok:
+		d.compare(a[:x], b[:y])
+		d.compare(a[x:], b[y:])
+	default:
+		d.replace(a, b)
+	}
+
+	for _, line := range common {
+		d.edits = append(d.edits, edit{op: opEqual, line: line})
+	}
+}
+
+// replace appends the edits deleting all of a and inserting all of b
+func (d *differ) replace(a, b []string) {
+	for _, line := range a {
+		d.edits = append(d.edits, edit{op: opDelete, line: line})
+	}
+	for _, line := range b {
+		d.edits = append(d.edits, edit{op: opInsert, line: line})
+	}
+}
+
+// split finds the middle snake of a shortest edit script by searching forward from the start and
+// backward from the end at the same time, returning the point where the two searches overlap.
+// It reports false when a or b is empty or the texts share no line, so that they are replaced whole.
+func (d *differ) split(a, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	length := 2*maxD + 2
+	forward, backward := d.forward[:length], d.backward[:length]
+	for i := range length {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// With an odd delta the searches overlap in a forward step, otherwise in a backward step
+	front := delta%2 != 0
+	// Diagonals trimmed from the search once their paths leave the edit graph
+	forwardStart, forwardEnd, backwardStart, backwardEnd := 0, 0, 0, 0
+
+	for step := 0; step < maxD; step++ {
+		for k := -step + forwardStart; k <= step-forwardEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			switch {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case front:
+				i := offset + delta - k
+				if i >= 0 && i < length && backward[i] != -1 && x >= n-backward[i] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -step + backwardStart; k <= step-backwardEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			switch {
+			case x > n:
+				backwardEnd += 2
+			case y > m:
+				backwardStart += 2
+			case !front:
+				i := offset + delta - k
+				if i >= 0 && i < length && forward[i] != -1 && forward[i] >= n-x {
+					forwardX := forward[i]
+					return forwardX, forwardX - (delta - k), true
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
@@ -0,0 +1,159 @@
+package diff
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "identical texts",
+			from: "a\nb\n",
+			to:   "a\nb\n",
+			want: "",
+		},
+		{
+			name: "changed line",
+			from: "a\nb\nc\n",
+			to:   "a\nx\nc\n",
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "added to empty",
+			from: "",
+			to:   "a\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			name: "separate hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			to:   "x\n2\n3\n4\n5\n6\n7\n8\n9\ny\n",
+			want: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -9,2 +9,2 @@\n 9\n-10\n+y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.from, tt.to, 1); got != tt.want {
+				t.Errorf("Unified() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesReconstructsBothTexts(t *testing.T) {
+	a := []string{"a", "b", "c", "a", "b", "b", "a"}
+	b := []string{"c", "b", "a", "b", "a", "c"}
+
+	var gotA, gotB []string
+	for _, e := range diffLines(a, b) {
+		if e.op != opInsert {
+			gotA = append(gotA, e.line)
+		}
+		if e.op != opDelete {
+			gotB = append(gotB, e.line)
+		}
+	}
+
+	if len(gotA) != len(a) || len(gotB) != len(b) {
+		t.Fatalf("diffLines() produced %v / %v", gotA, gotB)
+	}
+
+	for i := range a {
+		if gotA[i] != a[i] {
+			t.Errorf("diffLines() old text mismatch at %d", i)
+		}
+	}
+
+	for i := range b {
+		if gotB[i] != b[i] {
+			t.Errorf("diffLines() new text mismatch at %d", i)
+		}
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of two line slices
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				curr[j+1] = prev[j] + 1
+			} else {
+				curr[j+1] = max(curr[j], prev[j+1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// checkEdits fails the test unless the edits turn a into b with the fewest insertions and deletions
+func checkEdits(t *testing.T, a, b []string, edits []edit) {
+	t.Helper()
+
+	var gotA, gotB []string
+	changes := 0
+	for _, e := range edits {
+		if e.op != opInsert {
+			gotA = append(gotA, e.line)
+		}
+		if e.op != opDelete {
+			gotB = append(gotB, e.line)
+		}
+		if e.op != opEqual {
+			changes++
+		}
+	}
+
+	if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+		t.Fatalf("diffLines(%v, %v) does not reconstruct the texts: %v / %v", a, b, gotA, gotB)
+	}
+	if want := len(a) + len(b) - 2*lcsLength(a, b); changes != want {
+		t.Fatalf("diffLines(%v, %v) made %d changes, want %d", a, b, changes, want)
+	}
+}
+
+func TestDiffLinesShortest(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	lines := func() []string {
+		text := make([]string, random.IntN(12))
+		for i := range text {
+			text[i] = string(rune('a' + random.IntN(3)))
+		}
+		return text
+	}
+
+	for range 2000 {
+		a, b := lines(), lines()
+		checkEdits(t, a, b, diffLines(a, b))
+	}
+}
+
+func TestDiffLinesLargeTexts(t *testing.T) {
+	// Two unrelated texts of this size need an edit script as long as both, which used to keep
+	// a copy of the furthest reaching paths for every step
+	const size = 5000
+
+	a := make([]string, size)
+	b := make([]string, size)
+	for i := range size {
+		a[i] = "old " + strconv.Itoa(i)
+		b[i] = "new " + strconv.Itoa(i)
+	}
+	b[size/2] = a[size/3]
+
+	edits := diffLines(a, b)
+	if len(edits) != 2*size-1 {
+		t.Errorf("diffLines() returned %d edits, want %d", len(edits), 2*size-1)
+	}
+}
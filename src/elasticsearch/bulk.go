@@ -0,0 +1,145 @@
+// Package elasticsearch exports converted pages to Elasticsearch or OpenSearch, either as a bulk API
+// NDJSON file or by pushing them to a cluster through the bulk API
+package elasticsearch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/export"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+const (
+	// DefaultIndex is the default name of the index pages are written to
+	DefaultIndex = "crawldown"
+	// BulkFilename is the default name of the bulk NDJSON file
+	BulkFilename = "elasticsearch-bulk.ndjson"
+)
+
+// Source is the indexed document of a page
+type Source struct {
+	URL     string     `json:"url"`
+	Title   string     `json:"title"`
+	Body    string     `json:"body"`
+	Summary string     `json:"summary,omitempty"`
+	File    string     `json:"file"`
+	Date    *time.Time `json:"date,omitempty"` // Time the page was fetched
+}
+
+// Mapping returns the index settings mapping the fields of Source
+func Mapping() map[string]any {
+	return map[string]any{
+		"mappings": map[string]any{
+			"properties": map[string]any{
+				"url":     map[string]any{"type": "keyword"},
+				"title":   map[string]any{"type": "text"},
+				"body":    map[string]any{"type": "text"},
+				"summary": map[string]any{"type": "text"},
+				"file":    map[string]any{"type": "keyword"},
+				"date":    map[string]any{"type": "date"},
+			},
+		},
+	}
+}
+
+// ValidateIndex checks that name is a valid index name
+func ValidateIndex(name string) error {
+	switch {
+	case name == "", name == ".", name == "..":
+		return fmt.Errorf("invalid index name %q", name)
+	case len(name) > 255:
+		return fmt.Errorf("invalid index name %q: longer than 255 bytes", name)
+	case strings.ToLower(name) != name:
+		return fmt.Errorf("invalid index name %q: must be lowercase", name)
+	case strings.ContainsAny(name[:1], "-_+"):
+		return fmt.Errorf("invalid index name %q: must not start with -, _, or +", name)
+	case strings.ContainsAny(name, "\\/*?\"<>| ,#:"):
+		return fmt.Errorf("invalid index name %q: must not contain spaces or any of \\/*?\"<>|,#:", name)
+	}
+	return nil
+}
+
+// DocumentID returns the ID of the indexed document of a page, so that runs overwrite their documents
+func DocumentID(pageURL string) string {
+	sum := sha256.Sum256([]byte(pageURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSource returns the indexed document of a page, with the plain text of its content as the body
+func NewSource(doc export.Document) Source {
+	source := Source{
+		URL:     doc.URL,
+		Title:   doc.Title,
+		Body:    export.PlainText(export.Content(doc)),
+		Summary: doc.Summary,
+		File:    doc.File,
+	}
+	if !doc.FetchedAt.IsZero() {
+		date := doc.FetchedAt.UTC()
+		source.Date = &date
+	}
+	return source
+}
+
+// Bulk returns the bulk API NDJSON indexing the documents into index, ordered by URL
+func Bulk(docs []export.Document, index string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	for _, doc := range sortedByURL(docs) {
+		action := map[string]any{"index": map[string]any{"_index": index, "_id": DocumentID(doc.URL)}}
+		if err := encoder.Encode(action); err != nil {
+			return nil, fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+		if err := encoder.Encode(NewSource(doc)); err != nil {
+			return nil, fmt.Errorf("failed to encode bulk document: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// sortedByURL returns a copy of the documents ordered by URL
+func sortedByURL(docs []export.Document) []export.Document {
+	sorted := append([]export.Document(nil), docs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].URL < sorted[j].URL
+	})
+	return sorted
+}
+
+// FileExporter writes a bulk API NDJSON file, loadable with
+// curl -H "Content-Type: application/x-ndjson" --data-binary @elasticsearch-bulk.ndjson http://localhost:9200/_bulk
+type FileExporter struct {
+	Filename string
+	Index    string // DefaultIndex when empty
+}
+
+// Name identifies the exporter
+func (e FileExporter) Name() string {
+	return "elasticsearch bulk file"
+}
+
+// Export writes the bulk file through the writer
+func (e FileExporter) Export(docs []export.Document, writer output.Writer) error {
+	filename := e.Filename
+	if filename == "" {
+		filename = BulkFilename
+	}
+	index := e.Index
+	if index == "" {
+		index = DefaultIndex
+	}
+
+	data, err := Bulk(docs, index)
+	if err != nil {
+		return err
+	}
+	return writer.WriteFile(filename, data)
+}
@@ -0,0 +1,61 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/export"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+func TestBulk(t *testing.T) {
+	fetched := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	docs := []export.Document{
+		{URL: "https://example.com/b", Title: "B", File: "b.md", Markdown: "# B\n\nURL: https://example.com/b\n\n---\n\nSee <**this**>."},
+		{URL: "https://example.com/a", Title: "A", File: "a.md", Markdown: "Hello.", Summary: "Hello.", FetchedAt: fetched},
+	}
+
+	data, err := Bulk(docs, "docs")
+	if err != nil {
+		t.Fatalf("Bulk() unexpected error: %v", err)
+	}
+
+	want := `{"index":{"_id":"` + DocumentID("https://example.com/a") + `","_index":"docs"}}` + "\n" +
+		`{"url":"https://example.com/a","title":"A","body":"Hello.","summary":"Hello.","file":"a.md","date":"2026-03-01T11:00:00Z"}` + "\n" +
+		`{"index":{"_id":"` + DocumentID("https://example.com/b") + `","_index":"docs"}}` + "\n" +
+		`{"url":"https://example.com/b","title":"B","body":"See < this >.","file":"b.md"}` + "\n"
+	if string(data) != want {
+		t.Errorf("Bulk() = %s, want %s", data, want)
+	}
+}
+
+func TestValidateIndex(t *testing.T) {
+	for _, name := range []string{"docs", "crawl-2026.03", "docs_v2"} {
+		if err := ValidateIndex(name); err != nil {
+			t.Errorf("ValidateIndex(%q) unexpected error: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "..", "Docs", "_docs", "-docs", "my docs", "docs*", "a/b", strings.Repeat("a", 256)} {
+		if err := ValidateIndex(name); err == nil {
+			t.Errorf("ValidateIndex(%q) expected an error", name)
+		}
+	}
+}
+
+func TestFileExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	docs := []export.Document{{URL: "https://example.com/", Title: "Home", Markdown: "Welcome."}}
+
+	if err := (FileExporter{}).Export(docs, writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	data, err := writer.ReadFile(BulkFilename)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(data), `{"index":{"_id":"`) || !strings.Contains(string(data), `"_index":"crawldown"`) {
+		t.Errorf("bulk file = %s, want actions on the default index", data)
+	}
+}
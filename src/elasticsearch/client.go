@@ -0,0 +1,178 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/export"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// bulkBatch is the number of documents sent per bulk request
+const bulkBatch = 500
+
+// maxErrorBody is the number of bytes of an error response included in errors
+const maxErrorBody = 1024
+
+// Exporter pushes the documents to an Elasticsearch or OpenSearch cluster through the bulk API,
+// creating the index with the Source mapping when it does not exist
+type Exporter struct {
+	URL      string // Cluster URL, with user:password for basic authentication
+	Index    string // DefaultIndex when empty
+	APIKey   string // Sent as an ApiKey authorization when set
+	Username string
+	Password string
+
+	client *http.Client
+}
+
+// NewExporter creates an exporter for the cluster at endpoint, taking basic authentication credentials from
+// its user information and reading the API key from ELASTICSEARCH_API_KEY
+func NewExporter(endpoint, index string) *Exporter {
+	if index == "" {
+		index = DefaultIndex
+	}
+
+	e := &Exporter{URL: strings.TrimSuffix(endpoint, "/"), Index: index, APIKey: os.Getenv("ELASTICSEARCH_API_KEY"), client: &http.Client{Timeout: time.Minute}}
+	if parsed, err := url.Parse(endpoint); err == nil && parsed.User != nil {
+		e.Username = parsed.User.Username()
+		e.Password, _ = parsed.User.Password()
+		parsed.User = nil
+		e.URL = strings.TrimSuffix(parsed.String(), "/")
+	}
+	return e
+}
+
+// Name identifies the exporter
+func (e *Exporter) Name() string {
+	return "elasticsearch"
+}
+
+// Export indexes the documents in batches, ordered by URL. The writer is not used.
+func (e *Exporter) Export(docs []export.Document, _ output.Writer) error {
+	if err := e.ensureIndex(); err != nil {
+		return err
+	}
+
+	sorted := sortedByURL(docs)
+	for start := 0; start < len(sorted); start += bulkBatch {
+		body, err := Bulk(sorted[start:min(start+bulkBatch, len(sorted))], e.Index)
+		if err != nil {
+			return err
+		}
+		if err := e.bulk(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureIndex creates the index with the Source mapping unless it exists
+func (e *Exporter) ensureIndex() error {
+	resp, err := e.do(http.MethodHead, "/"+url.PathEscape(e.Index), "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to get index: %w", err)
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return nil
+	case resp.StatusCode != http.StatusNotFound:
+		return fmt.Errorf("failed to get index: server returned %s", resp.Status)
+	}
+
+	mapping, err := json.Marshal(Mapping())
+	if err != nil {
+		return fmt.Errorf("failed to encode index mapping: %w", err)
+	}
+	resp, err = e.do(http.MethodPut, "/"+url.PathEscape(e.Index), "application/json", mapping)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkStatus(resp); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	return nil
+}
+
+// bulkResponse is the part of a bulk API response reporting failed items
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string `json:"_id"`
+		Status int    `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// bulk sends a bulk request and reports the items it failed to index
+func (e *Exporter) bulk(body []byte) error {
+	resp, err := e.do(http.MethodPost, "/_bulk", "application/x-ndjson", body)
+	if err != nil {
+		return fmt.Errorf("failed to send bulk request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if err := checkStatus(resp); err != nil {
+		return fmt.Errorf("failed to send bulk request: %w", err)
+	}
+
+	var reply bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !reply.Errors {
+		return nil
+	}
+
+	failed := 0
+	first := ""
+	for _, item := range reply.Items {
+		for _, result := range item {
+			if result.Error == nil {
+				continue
+			}
+			failed++
+			if first == "" {
+				first = fmt.Sprintf("%s: %s", result.Error.Type, result.Error.Reason)
+			}
+		}
+	}
+	return fmt.Errorf("failed to index %d documents, first error: %s", failed, first)
+}
+
+func (e *Exporter) do(method, endpoint, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, e.URL+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case e.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.APIKey)
+	case e.Username != "":
+		req.SetBasicAuth(e.Username, e.Password)
+	}
+	return e.client.Do(req)
+}
+
+// checkStatus returns the error message of a response with a non-2xx status
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+}
@@ -0,0 +1,142 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/export"
+)
+
+// fakeCluster serves the index and bulk APIs of an Elasticsearch cluster from memory
+type fakeCluster struct {
+	mutex     sync.Mutex
+	indices   map[string]map[string]any // Index -> mapping
+	documents map[string]string         // Document ID -> URL
+	calls     []string
+	auth      []string
+	failURL   string // URL whose documents are rejected
+}
+
+func (c *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, r.Method+" "+r.URL.Path)
+	c.auth = append(c.auth, r.Header.Get("Authorization"))
+
+	switch {
+	case r.URL.Path == "/_bulk" && r.Method == http.MethodPost:
+		if r.Header.Get("Content-Type") != "application/x-ndjson" {
+			http.Error(w, "bad content type", http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		var items []any
+		hasErrors := false
+		for i := 0; i+1 < len(lines); i += 2 {
+			var action struct {
+				Index struct {
+					ID string `json:"_id"`
+				} `json:"index"`
+			}
+			var source Source
+			if json.Unmarshal([]byte(lines[i]), &action) != nil || json.Unmarshal([]byte(lines[i+1]), &source) != nil {
+				http.Error(w, "bad bulk line", http.StatusBadRequest)
+				return
+			}
+			if source.URL == c.failURL {
+				hasErrors = true
+				items = append(items, map[string]any{"index": map[string]any{"_id": action.Index.ID, "status": 400,
+					"error": map[string]any{"type": "mapper_parsing_exception", "reason": "failed to parse field [date]"}}})
+				continue
+			}
+			c.documents[action.Index.ID] = source.URL
+			items = append(items, map[string]any{"index": map[string]any{"_id": action.Index.ID, "status": 201}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"errors": hasErrors, "items": items})
+	case r.Method == http.MethodHead:
+		if _, ok := c.indices[strings.TrimPrefix(r.URL.Path, "/")]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodPut:
+		var mapping map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&mapping)
+		c.indices[strings.TrimPrefix(r.URL.Path, "/")] = mapping
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeCluster() *fakeCluster {
+	return &fakeCluster{indices: map[string]map[string]any{}, documents: map[string]string{}}
+}
+
+func TestExporter(t *testing.T) {
+	cluster := newFakeCluster()
+	srv := httptest.NewServer(cluster)
+	defer srv.Close()
+
+	exporter := NewExporter(strings.Replace(srv.URL, "http://", "http://elastic:secret@", 1), "docs")
+
+	docs := make([]export.Document, bulkBatch+1)
+	for i := range docs {
+		docs[i] = export.Document{URL: fmt.Sprintf("https://example.com/page-%03d", i), Title: "Page"}
+	}
+	if err := exporter.Export(docs, nil); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	// A second run finds the index and overwrites the documents
+	if err := exporter.Export(docs[:1], nil); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	want := []string{"HEAD /docs", "PUT /docs", "POST /_bulk", "POST /_bulk", "HEAD /docs", "POST /_bulk"}
+	if !reflect.DeepEqual(cluster.calls, want) {
+		t.Errorf("calls = %v, want %v", cluster.calls, want)
+	}
+	if len(cluster.documents) != len(docs) {
+		t.Errorf("indexed %d documents, want %d", len(cluster.documents), len(docs))
+	}
+	if got := cluster.documents[DocumentID(docs[3].URL)]; got != docs[3].URL {
+		t.Errorf("document %s = %q, want %q", DocumentID(docs[3].URL), got, docs[3].URL)
+	}
+	properties := cluster.indices["docs"]["mappings"].(map[string]any)["properties"].(map[string]any)
+	if properties["date"].(map[string]any)["type"] != "date" {
+		t.Errorf("index mapping = %v, want a date field", properties)
+	}
+	for _, auth := range cluster.auth {
+		if !strings.HasPrefix(auth, "Basic ") {
+			t.Errorf("Authorization = %q, want basic authentication from the URL", auth)
+		}
+	}
+}
+
+func TestExporterFailedItems(t *testing.T) {
+	cluster := newFakeCluster()
+	cluster.failURL = "https://example.com/bad"
+	srv := httptest.NewServer(cluster)
+	defer srv.Close()
+
+	t.Setenv("ELASTICSEARCH_API_KEY", "key")
+	exporter := NewExporter(srv.URL, "")
+
+	docs := []export.Document{{URL: "https://example.com/"}, {URL: "https://example.com/bad"}}
+	err := exporter.Export(docs, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to index 1 documents") || !strings.Contains(err.Error(), "mapper_parsing_exception") {
+		t.Errorf("Export() error = %v, want the failed item", err)
+	}
+	if _, ok := cluster.indices[DefaultIndex]; !ok {
+		t.Errorf("indices = %v, want the default index", cluster.indices)
+	}
+	if cluster.auth[0] != "ApiKey key" {
+		t.Errorf("Authorization = %q, want the API key", cluster.auth[0])
+	}
+}
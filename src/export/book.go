@@ -0,0 +1,209 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// mdBook and GitBook file names
+const (
+	// BookSummaryFilename is the default name of the table of contents of mdBook and GitBook
+	BookSummaryFilename = "SUMMARY.md"
+	// BookConfigFilename is the configuration of an mdBook book, at the root of the book next to its source folder
+	BookConfigFilename = "book.toml"
+)
+
+// bookIndent is the indentation of nested chapters
+const bookIndent = "    "
+
+// BookSummaryExporter writes the SUMMARY.md table of contents read by mdBook and GitBook, so that the output
+// can be built as a book with the output root as the book source. The start page is the introduction, the pages
+// of the site navigation follow in its order and nesting, then the other pages nested by URL path.
+type BookSummaryExporter struct {
+	Filename string
+	// Site is the start URL of the crawl, whose page is the introduction of the book
+	Site string
+}
+
+// MdBookExporter writes the book.toml and the SUMMARY.md of an mdBook book whose source folder Dir holds the pages,
+// so that the output builds with mdbook build. The book is titled and described after the start page.
+type MdBookExporter struct {
+	Dir string
+	// Site is the start URL of the crawl, whose page is the introduction of the book
+	Site string
+}
+
+// Name identifies the exporter
+func (e MdBookExporter) Name() string {
+	return "mdbook"
+}
+
+// Export writes the configuration of the book at the output root and its summary in the source folder
+func (e MdBookExporter) Export(docs []Document, writer output.Writer) error {
+	summaryFile := path.Join(e.Dir, BookSummaryFilename)
+
+	// The links of the summary are relative to the source folder, which holds the pages
+	sources := make([]Document, 0, len(docs))
+	for _, doc := range docs {
+		if strings.EqualFold(doc.File, summaryFile) {
+			return fmt.Errorf("summary file %s is the file of page %s", summaryFile, doc.URL)
+		}
+		if file, ok := strings.CutPrefix(doc.File, e.Dir+"/"); ok {
+			doc.File = file
+			sources = append(sources, doc)
+		}
+	}
+
+	if err := writer.WriteFile(BookConfigFilename, []byte(BuildBookConfig(docs, e.Site, e.Dir))); err != nil {
+		return err
+	}
+	return writer.WriteFile(summaryFile, []byte(BuildBookSummary(sources, e.Site)))
+}
+
+// BuildBookConfig returns the book.toml of a book with the source folder dir, titled and described after
+// the document of the site URL
+func BuildBookConfig(docs []Document, site, dir string) string {
+	title, description := siteHeading(docs, site)
+
+	var builder strings.Builder
+	builder.WriteString("[book]\n")
+	builder.WriteString("title = " + tomlString(title) + "\n")
+	if description != "" {
+		builder.WriteString("description = " + tomlString(description) + "\n")
+	}
+	builder.WriteString("src = " + tomlString(dir) + "\n")
+	return builder.String()
+}
+
+// tomlString quotes a TOML basic string, which accepts the escapes of JSON strings
+func tomlString(s string) string {
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return `""`
+	}
+	return string(encoded)
+}
+
+// bookChapter is a chapter of the summary: a page, or a draft chapter for a path segment without a page
+type bookChapter struct {
+	doc      *Document
+	label    string
+	children []*bookChapter
+}
+
+// Name identifies the exporter
+func (e BookSummaryExporter) Name() string {
+	return "summary.md"
+}
+
+// Export builds the summary and writes it through the writer
+func (e BookSummaryExporter) Export(docs []Document, writer output.Writer) error {
+	filename := e.Filename
+	if filename == "" {
+		filename = BookSummaryFilename
+	}
+
+	for _, doc := range docs {
+		if strings.EqualFold(doc.File, filename) {
+			return fmt.Errorf("summary file %s is the file of page %s", filename, doc.URL)
+		}
+	}
+
+	return writer.WriteFile(filename, []byte(BuildBookSummary(docs, e.Site)))
+}
+
+// BuildBookSummary returns the SUMMARY.md of the documents, with the document of the site URL as the introduction
+func BuildBookSummary(docs []Document, site string) string {
+	var intro *Document
+	var navigation, others []Document
+	for i := range docs {
+		switch {
+		case intro == nil && strings.TrimSuffix(docs[i].URL, "/") == strings.TrimSuffix(site, "/"):
+			intro = &docs[i]
+		case docs[i].NavPosition > 0:
+			navigation = append(navigation, docs[i])
+		default:
+			others = append(others, docs[i])
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# Summary\n\n")
+	if intro != nil {
+		builder.WriteString(bookLink(intro) + "\n\n")
+	}
+
+	chapters := navigationChapters(navigation)
+	if len(others) > 0 {
+		root := buildDocTree(others)
+		if root.doc != nil {
+			chapters = append(chapters, &bookChapter{doc: root.doc})
+		}
+		for _, child := range sortedChildren(root) {
+			chapters = append(chapters, pathChapter(child))
+		}
+	}
+	writeBookChapters(&builder, chapters, 0)
+
+	return builder.String()
+}
+
+// navigationChapters nests the documents of the site navigation under the document of their navigation parent,
+// in navigation order. Documents whose parent was not saved are top-level chapters.
+func navigationChapters(docs []Document) []*bookChapter {
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].NavPosition < docs[j].NavPosition
+	})
+
+	byURL := make(map[string]*bookChapter, len(docs))
+	for i := range docs {
+		byURL[strings.TrimSuffix(docs[i].URL, "/")] = &bookChapter{doc: &docs[i]}
+	}
+
+	var chapters []*bookChapter
+	for i := range docs {
+		chapter := byURL[strings.TrimSuffix(docs[i].URL, "/")]
+		if parent, ok := byURL[strings.TrimSuffix(docs[i].NavParent, "/")]; ok && parent != chapter && docs[i].NavParent != "" {
+			parent.children = append(parent.children, chapter)
+			continue
+		}
+		chapters = append(chapters, chapter)
+	}
+	return chapters
+}
+
+// pathChapter turns a node of the URL path tree into a chapter, with a draft chapter for segments without a page
+func pathChapter(node *docNode) *bookChapter {
+	chapter := &bookChapter{doc: node.doc, label: sectionLabel(node.segment)}
+	for _, child := range sortedChildren(node) {
+		chapter.children = append(chapter.children, pathChapter(child))
+	}
+	return chapter
+}
+
+func writeBookChapters(builder *strings.Builder, chapters []*bookChapter, depth int) {
+	for _, chapter := range chapters {
+		builder.WriteString(strings.Repeat(bookIndent, depth) + "- ")
+		if chapter.doc != nil {
+			builder.WriteString(bookLink(chapter.doc))
+		} else {
+			builder.WriteString("[" + linkTextEscaper.Replace(chapter.label) + "]()")
+		}
+		builder.WriteString("\n")
+		writeBookChapters(builder, chapter.children, depth+1)
+	}
+}
+
+// bookLink returns the link to the file of a document, titled after the document
+func bookLink(doc *Document) string {
+	title := doc.Title
+	if title == "" {
+		title = doc.URL
+	}
+	return "[" + linkTextEscaper.Replace(title) + "](" + escapeWikiPath(doc.File) + ")"
+}
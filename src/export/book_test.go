@@ -0,0 +1,85 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+func TestBuildBookSummary(t *testing.T) {
+	docs := []Document{
+		{URL: "https://example.com/", Title: "Home", File: "index.md", NavPosition: 1},
+		{URL: "https://example.com/blog/first", Title: "First post", File: "blog/first.md"},
+		{URL: "https://example.com/guide/install", Title: "Install [beta]", File: "guide/install.md", NavPosition: 3, NavParent: "https://example.com/guide/"},
+		{URL: "https://example.com/guide", Title: "Guide", File: "guide/index.md", NavPosition: 2},
+		{URL: "https://example.com/reference", Title: "Reference", File: "reference.md", NavPosition: 4, NavParent: "https://example.com/unsaved"},
+		{URL: "https://example.com/about us", Title: "About", File: "about us.md"},
+	}
+
+	want := "# Summary\n\n[Home](index.md)\n\n" +
+		"- [Guide](guide/index.md)\n" +
+		"    - [Install \\[beta\\]](guide/install.md)\n" +
+		"- [Reference](reference.md)\n" +
+		"- [About](about%20us.md)\n" +
+		"- [Blog]()\n" +
+		"    - [First post](blog/first.md)\n"
+	if got := BuildBookSummary(docs, "https://example.com"); got != want {
+		t.Errorf("BuildBookSummary() = %q, want %q", got, want)
+	}
+}
+
+func TestBookSummaryExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	docs := []Document{
+		{URL: "https://example.com/", Title: "Home", File: "index.md"},
+		{URL: "https://example.com/docs/start", Title: "Start", File: "docs/start.md"},
+	}
+
+	if err := (BookSummaryExporter{Site: "https://example.com/"}).Export(docs, writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	data, err := writer.ReadFile(BookSummaryFilename)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if want := "# Summary\n\n[Home](index.md)\n\n- [Docs]()\n    - [Start](docs/start.md)\n"; string(data) != want {
+		t.Errorf("SUMMARY.md = %q, want %q", data, want)
+	}
+
+	if err := (BookSummaryExporter{Filename: "docs/start.md"}).Export(docs, writer); err == nil {
+		t.Error("Export() expected an error when the summary file is the file of a page")
+	}
+}
+
+func TestMdBookExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	docs := []Document{
+		{URL: "https://example.com/", Title: `The "Example" Book`, File: "src/index.md", Summary: "All about the example."},
+		{URL: "https://example.com/docs/start", Title: "Start", File: "src/docs-start.md", NavPosition: 1},
+	}
+
+	if err := (MdBookExporter{Dir: "src", Site: "https://example.com/"}).Export(docs, writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	config, err := writer.ReadFile(BookConfigFilename)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if want := "[book]\ntitle = \"The \\\"Example\\\" Book\"\ndescription = \"All about the example.\"\nsrc = \"src\"\n"; string(config) != want {
+		t.Errorf("book.toml = %q, want %q", config, want)
+	}
+
+	summary, err := writer.ReadFile("src/" + BookSummaryFilename)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if want := "# Summary\n\n[The \"Example\" Book](index.md)\n\n- [Start](docs-start.md)\n"; string(summary) != want {
+		t.Errorf("SUMMARY.md = %q, want %q", summary, want)
+	}
+
+	docs = append(docs, Document{URL: "https://example.com/summary", File: "src/summary.md"})
+	if err := (MdBookExporter{Dir: "src"}).Export(docs, writer); err == nil {
+		t.Error("Export() expected an error when the summary file is the file of a page")
+	}
+}
@@ -0,0 +1,229 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// ConfluenceDir is the default folder of the Confluence export
+const ConfluenceDir = "confluence"
+
+// confluenceRenderer keeps the raw HTML of pages, such as complex tables, which is made well-formed afterwards
+var confluenceRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+)
+
+// ConfluenceExporter writes each page as a Confluence storage format XHTML file, ready for the body of the
+// Confluence REST API with the "storage" representation. Code blocks become code macros, links between pages
+// become page links by title, and local images become attachments listed in the mapping file.
+type ConfluenceExporter struct {
+	Dir string
+}
+
+// Name identifies the exporter
+func (e ConfluenceExporter) Name() string {
+	return "confluence"
+}
+
+// Export writes the pages under the pages folder and the mapping file through the writer
+func (e ConfluenceExporter) Export(docs []Document, writer output.Writer) error {
+	dir := e.Dir
+	if dir == "" {
+		dir = ConfluenceDir
+	}
+
+	pages := buildWikiTree(docs, ".xhtml")
+	byFile := wikiPagesByFile(pages, make(map[string]*wikiPage))
+
+	var write func(pages []*wikiPage) error
+	write = func(pages []*wikiPage) error {
+		for _, page := range pages {
+			content, err := confluencePage(page, byFile)
+			if err != nil {
+				return err
+			}
+			if err := writer.WriteFile(path.Join(dir, page.File), []byte(content)); err != nil {
+				return err
+			}
+			if err := write(page.children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := write(pages); err != nil {
+		return err
+	}
+
+	return writeWikiMapping(pages, dir, writer)
+}
+
+// confluencePage renders the storage format of a page and records the attachments it references
+func confluencePage(page *wikiPage, byFile map[string]*wikiPage) (string, error) {
+	if page.doc == nil {
+		return "", nil
+	}
+
+	var rendered bytes.Buffer
+	if err := confluenceRenderer.Convert([]byte(Content(*page.doc)), &rendered); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", page.doc.URL, err)
+	}
+
+	// Parsing and rendering back balances the raw HTML of the page and closes void elements
+	nodes, err := html.ParseFragment(&rendered, &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", page.doc.URL, err)
+	}
+
+	var out bytes.Buffer
+	for _, node := range nodes {
+		node = toStorageFormat(node, page, byFile)
+		if node == nil {
+			continue
+		}
+		if err := html.Render(&out, node); err != nil {
+			return "", fmt.Errorf("failed to render %s: %w", page.doc.URL, err)
+		}
+	}
+	return strings.TrimSpace(out.String()) + "\n", nil
+}
+
+// toStorageFormat replaces the elements of a node that have a Confluence macro or link equivalent,
+// returning the node to render in its place or nil to drop it
+func toStorageFormat(node *html.Node, page *wikiPage, byFile map[string]*wikiPage) *html.Node {
+	if node.Type == html.ElementNode {
+		switch node.DataAtom {
+		case atom.Script, atom.Style:
+			return nil
+		case atom.Pre:
+			return codeMacro(node)
+		case atom.Img:
+			if image := confluenceImage(node, page); image != nil {
+				return image
+			}
+		case atom.A:
+			if link := confluenceLink(node, page, byFile); link != nil {
+				node = link
+			}
+		}
+	}
+
+	for child := node.FirstChild; child != nil; {
+		next := child.NextSibling
+		if replacement := toStorageFormat(child, page, byFile); replacement != child {
+			if replacement != nil {
+				node.InsertBefore(replacement, child)
+			}
+			node.RemoveChild(child)
+		}
+		child = next
+	}
+	return node
+}
+
+// codeMacro turns a pre block into a code macro with the language of its code element
+func codeMacro(pre *html.Node) *html.Node {
+	macro := storageElement("ac:structured-macro", "ac:name", "code")
+	for _, class := range strings.Fields(attribute(pre.FirstChild, "class")) {
+		if language, ok := strings.CutPrefix(class, "language-"); ok {
+			parameter := storageElement("ac:parameter", "ac:name", "language")
+			parameter.AppendChild(&html.Node{Type: html.TextNode, Data: language})
+			macro.AppendChild(parameter)
+		}
+	}
+
+	body := storageElement("ac:plain-text-body")
+	body.AppendChild(&html.Node{Type: html.TextNode, Data: strings.TrimSuffix(textContent(pre), "\n")})
+	macro.AppendChild(body)
+	return macro
+}
+
+// confluenceImage turns an image of a file of the output into an attachment image, or returns nil for other images
+func confluenceImage(img *html.Node, page *wikiPage) *html.Node {
+	resolved, _, ok := resolveWikiTarget(page.doc, attribute(img, "src"))
+	if !ok {
+		return nil
+	}
+
+	if !slices.Contains(page.Attachments, resolved) {
+		page.Attachments = append(page.Attachments, resolved)
+	}
+	image := storageElement("ac:image")
+	if alt := attribute(img, "alt"); alt != "" {
+		image.Attr = append(image.Attr, html.Attribute{Key: "ac:alt", Val: alt})
+	}
+	image.AppendChild(storageElement("ri:attachment", "ri:filename", path.Base(resolved)))
+	return image
+}
+
+// confluenceLink turns a link to an exported page into a page link by title, or returns nil for other links
+func confluenceLink(a *html.Node, page *wikiPage, byFile map[string]*wikiPage) *html.Node {
+	resolved, fragment, ok := resolveWikiTarget(page.doc, attribute(a, "href"))
+	if !ok {
+		return nil
+	}
+	linked, ok := byFile[resolved]
+	if !ok {
+		return nil
+	}
+
+	link := storageElement("ac:link")
+	if fragment != "" {
+		link.Attr = append(link.Attr, html.Attribute{Key: "ac:anchor", Val: fragment})
+	}
+	link.AppendChild(storageElement("ri:page", "ri:content-title", linked.Title))
+
+	body := storageElement("ac:link-body")
+	for child := a.FirstChild; child != nil; {
+		next := child.NextSibling
+		a.RemoveChild(child)
+		body.AppendChild(child)
+		child = next
+	}
+	link.AppendChild(body)
+	return link
+}
+
+// storageElement creates an element of the Confluence storage format with attribute key and value pairs
+func storageElement(name string, attributes ...string) *html.Node {
+	node := &html.Node{Type: html.ElementNode, Data: name}
+	for i := 0; i+1 < len(attributes); i += 2 {
+		node.Attr = append(node.Attr, html.Attribute{Key: attributes[i], Val: attributes[i+1]})
+	}
+	return node
+}
+
+func attribute(node *html.Node, key string) string {
+	if node == nil {
+		return ""
+	}
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var text strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		text.WriteString(textContent(child))
+	}
+	return text.String()
+}
@@ -0,0 +1,369 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// Docusaurus output names
+const (
+	DocusaurusDir       = "docusaurus"
+	DocusaurusSidebar   = "sidebar.json"
+	docusaurusDocsDir   = "docs"
+	docusaurusIndexName = "index"
+	docusaurusSidebarID = "docs"
+)
+
+var docusaurusLinkPattern = regexp.MustCompile(`\]\(([^)#\s]+\.md)(#[^)\s]*)?\)`)
+
+// DocusaurusExporter writes a Docusaurus docs folder with front matter and a sidebar derived from the crawl tree
+type DocusaurusExporter struct {
+	Dir string
+}
+
+// SidebarItem is a doc id or a category of the Docusaurus sidebar
+type SidebarItem struct {
+	Type  string        `json:"type"`
+	ID    string        `json:"id,omitempty"`
+	Label string        `json:"label,omitempty"`
+	Link  *SidebarLink  `json:"link,omitempty"`
+	Items []SidebarItem `json:"items,omitempty"`
+}
+
+// SidebarLink links a category to its index doc
+type SidebarLink struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// docNode is a URL path segment in the crawl tree
+type docNode struct {
+	segment  string
+	doc      *Document
+	order    int
+	children map[string]*docNode
+}
+
+// Name identifies the exporter
+func (e DocusaurusExporter) Name() string {
+	return "docusaurus"
+}
+
+// Export writes the docs and the sidebar through the writer
+func (e DocusaurusExporter) Export(docs []Document, writer output.Writer) error {
+	dir := e.Dir
+	if dir == "" {
+		dir = DocusaurusDir
+	}
+
+	root := buildDocTree(docs)
+
+	ids := make(map[string]string)
+	assignDocIDs(root, nil, ids)
+
+	sidebar := map[string][]SidebarItem{docusaurusSidebarID: sidebarItems(root, ids)}
+	data, err := json.MarshalIndent(sidebar, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sidebar: %w", err)
+	}
+	if err := writer.WriteFile(path.Join(dir, DocusaurusSidebar), data); err != nil {
+		return err
+	}
+
+	fileToID := make(map[string]string, len(ids))
+	for _, doc := range docs {
+		fileToID[doc.File] = ids[doc.URL]
+	}
+
+	return writeDocusaurusDocs(root, dir, ids, fileToID, writer)
+}
+
+// writeDocusaurusDocs writes the docs below a node, numbering siblings in navigation and crawl order
+func writeDocusaurusDocs(node *docNode, dir string, ids, fileToID map[string]string, writer output.Writer) error {
+	if node.segment == "" && node.doc != nil {
+		content := docusaurusPage(*node.doc, docusaurusIndexName, 1, fileToID)
+		if err := writer.WriteFile(path.Join(dir, docusaurusDocsDir, docusaurusIndexName+".md"), []byte(content)); err != nil {
+			return err
+		}
+	}
+
+	for position, child := range sortedChildren(node) {
+		if child.doc != nil {
+			id := ids[child.doc.URL]
+			content := docusaurusPage(*child.doc, id, position+1, fileToID)
+			if err := writer.WriteFile(path.Join(dir, docusaurusDocsDir, id+".md"), []byte(content)); err != nil {
+				return err
+			}
+		}
+
+		if err := writeDocusaurusDocs(child, dir, ids, fileToID, writer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// docusaurusPage renders a doc with front matter, MDX escaping, and links to other docs
+func docusaurusPage(doc Document, id string, position int, fileToID map[string]string) string {
+	body := stripDefaultHeader(doc)
+
+	body = docusaurusLinkPattern.ReplaceAllStringFunc(body, func(match string) string {
+		parts := docusaurusLinkPattern.FindStringSubmatch(match)
+		targetID, ok := fileToID[parts[1]]
+		if !ok {
+			return match
+		}
+		return "](" + relativeDocPath(id, targetID) + parts[2] + ")"
+	})
+
+	var builder strings.Builder
+	builder.WriteString("---\n")
+	builder.WriteString("title: " + strconv.Quote(doc.Title) + "\n")
+	builder.WriteString("sidebar_position: " + strconv.Itoa(position) + "\n")
+	builder.WriteString("---\n\n")
+	builder.WriteString(EscapeMDX(body))
+	builder.WriteString("\n")
+
+	return builder.String()
+}
+
+// stripDefaultHeader removes the "# Title / URL:" header prepended to standard pages
+func stripDefaultHeader(doc Document) string {
+	header := fmt.Sprintf("# %s\n\nURL: %s\n\n---\n\n", doc.Title, doc.URL)
+	return strings.TrimSpace(strings.TrimPrefix(doc.Markdown, header))
+}
+
+// buildDocTree arranges documents by URL path, ordered by the site navigation and then by crawl discovery
+func buildDocTree(docs []Document) *docNode {
+	sorted := make([]Document, len(docs))
+	copy(sorted, docs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].URL < sorted[j].URL
+	})
+
+	order := navigationOrder(sorted, discoveryOrder(sorted))
+	root := &docNode{children: make(map[string]*docNode), order: -1}
+
+	for i := range sorted {
+		doc := &sorted[i]
+		node := root
+		for _, segment := range urlSegments(doc.URL) {
+			child, exists := node.children[segment]
+			if !exists {
+				child = &docNode{segment: segment, children: make(map[string]*docNode), order: len(sorted)}
+				node.children[segment] = child
+			}
+			node = child
+		}
+		node.doc = doc
+		node.order = order[doc.URL]
+	}
+
+	propagateOrder(root)
+
+	return root
+}
+
+// discoveryOrder numbers documents breadth-first along their links, starting from the shortest URL
+func discoveryOrder(docs []Document) map[string]int {
+	byURL := make(map[string]*Document, len(docs))
+	for i := range docs {
+		byURL[normalizeDocURL(docs[i].URL)] = &docs[i]
+	}
+
+	order := make(map[string]int, len(docs))
+	if len(docs) == 0 {
+		return order
+	}
+
+	start := docs[0]
+	for _, doc := range docs {
+		if len(doc.URL) < len(start.URL) {
+			start = doc
+		}
+	}
+
+	queue := []string{start.URL}
+	order[start.URL] = 0
+	for len(queue) > 0 {
+		current := byURL[normalizeDocURL(queue[0])]
+		queue = queue[1:]
+
+		for _, link := range current.Links {
+			target, exists := byURL[normalizeDocURL(link)]
+			if !exists {
+				continue
+			}
+			if _, seen := order[target.URL]; seen {
+				continue
+			}
+			order[target.URL] = len(order)
+			queue = append(queue, target.URL)
+		}
+	}
+
+	for _, doc := range docs {
+		if _, seen := order[doc.URL]; !seen {
+			order[doc.URL] = len(order)
+		}
+	}
+
+	return order
+}
+
+// navigationOrder places documents linked from the site navigation first, in navigation order,
+// followed by the other documents in discovery order
+func navigationOrder(docs []Document, discovery map[string]int) map[string]int {
+	navItems := 0
+	for _, doc := range docs {
+		navItems = max(navItems, doc.NavPosition)
+	}
+	if navItems == 0 {
+		return discovery
+	}
+
+	order := make(map[string]int, len(docs))
+	for _, doc := range docs {
+		if doc.NavPosition > 0 {
+			order[doc.URL] = doc.NavPosition - 1
+		} else {
+			order[doc.URL] = navItems + discovery[doc.URL]
+		}
+	}
+	return order
+}
+
+// propagateOrder gives each node the earliest discovery order of its subtree
+func propagateOrder(node *docNode) int {
+	for _, child := range node.children {
+		if childOrder := propagateOrder(child); childOrder < node.order {
+			node.order = childOrder
+		}
+	}
+	return node.order
+}
+
+// assignDocIDs gives each document a Docusaurus doc id mirroring its URL path
+func assignDocIDs(node *docNode, segments []string, ids map[string]string) {
+	if node.doc != nil {
+		switch {
+		case len(segments) == 0:
+			ids[node.doc.URL] = docusaurusIndexName
+		case len(node.children) > 0:
+			ids[node.doc.URL] = path.Join(path.Join(segments...), docusaurusIndexName)
+		default:
+			ids[node.doc.URL] = path.Join(segments...)
+		}
+	}
+
+	for _, child := range node.children {
+		assignDocIDs(child, append(append([]string{}, segments...), child.segment), ids)
+	}
+}
+
+// sidebarItems builds the sidebar entries for the children of a node
+func sidebarItems(node *docNode, ids map[string]string) []SidebarItem {
+	var items []SidebarItem
+
+	if node.segment == "" && node.doc != nil {
+		items = append(items, SidebarItem{Type: "doc", ID: ids[node.doc.URL]})
+	}
+
+	for _, child := range sortedChildren(node) {
+		if len(child.children) == 0 {
+			items = append(items, SidebarItem{Type: "doc", ID: ids[child.doc.URL]})
+			continue
+		}
+
+		category := SidebarItem{
+			Type:  "category",
+			Label: sectionLabel(child.segment),
+			Items: sidebarItems(child, ids),
+		}
+		if child.doc != nil {
+			category.Label = child.doc.Title
+			category.Link = &SidebarLink{Type: "doc", ID: ids[child.doc.URL]}
+		}
+		items = append(items, category)
+	}
+
+	return items
+}
+
+func sortedChildren(node *docNode) []*docNode {
+	children := make([]*docNode, 0, len(node.children))
+	for _, child := range node.children {
+		children = append(children, child)
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].order != children[j].order {
+			return children[i].order < children[j].order
+		}
+		return children[i].segment < children[j].segment
+	})
+
+	return children
+}
+
+// urlSegments returns the sanitized path segments of a URL, including the query in the last segment
+func urlSegments(rawURL string) []string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(parsedURL.Path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		segments = append(segments, converter.SanitizeSegment(strings.TrimSuffix(segment, path.Ext(segment))))
+	}
+
+	if parsedURL.RawQuery != "" {
+		if len(segments) == 0 {
+			segments = append(segments, docusaurusIndexName)
+		}
+		last := len(segments) - 1
+		segments[last] = converter.SanitizeSegment(segments[last] + "-" + parsedURL.RawQuery)
+	}
+
+	return segments
+}
+
+func normalizeDocURL(rawURL string) string {
+	return strings.TrimSuffix(rawURL, "/")
+}
+
+// relativeDocPath returns the relative path of the target doc file as seen from the source doc
+func relativeDocPath(fromID, toID string) string {
+	rel := converter.RelativePath(fromID, toID) + ".md"
+	if !strings.HasPrefix(rel, "..") {
+		rel = "./" + rel
+	}
+	return rel
+}
+
+// sectionLabel turns a path segment into a readable label
+func sectionLabel(segment string) string {
+	words := strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' })
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = strings.ToUpper(string(runes[0])) + string(runes[1:])
+	}
+	if len(words) == 0 {
+		return segment
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,189 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+func TestEscapeMDX(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "escapes braces and angle brackets",
+			markdown: "Use {value} when a < b",
+			want:     `Use \{value\} when a &lt; b`,
+		},
+		{
+			name:     "keeps inline code",
+			markdown: "Call `f({x})` or ``<a>``",
+			want:     "Call `f({x})` or ``<a>``",
+		},
+		{
+			name:     "keeps fenced code",
+			markdown: "```go\nm := map[string]int{}\n```\n{after}",
+			want:     "```go\nm := map[string]int{}\n```\n\\{after\\}",
+		},
+		{
+			name:     "keeps heading ids",
+			markdown: "## Set {x} {#set-x}\nText {#not-a-heading}",
+			want:     "## Set \\{x\\} {#set-x}\nText \\{#not-a-heading\\}",
+		},
+		{
+			name:     "escapes after an unclosed backtick",
+			markdown: "a ` {b}",
+			want:     `a ` + "`" + ` \{b\}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeMDX(tt.markdown); got != tt.want {
+				t.Errorf("EscapeMDX() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeDocPath(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want string
+	}{
+		{from: "index", to: "guide/install", want: "./guide/install.md"},
+		{from: "guide/install", to: "guide/index", want: "./index.md"},
+		{from: "guide/install", to: "api", want: "../api.md"},
+		{from: "a/b/c", to: "a/d", want: "../d.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			if got := relativeDocPath(tt.from, tt.to); got != tt.want {
+				t.Errorf("relativeDocPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocusaurusExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	docs := []Document{
+		{
+			URL:      "https://example.com/",
+			Title:    "Home",
+			File:     "index.md",
+			Markdown: "# Home\n\nURL: https://example.com/\n\n---\n\nSee [install](guide-install.md) and [API](api.md).",
+			Links:    []string{"https://example.com/guide/", "https://example.com/api"},
+		},
+		{URL: "https://example.com/api", Title: "API", File: "api.md", Markdown: "Returns {json}"},
+		{
+			URL:      "https://example.com/guide/",
+			Title:    "Guide",
+			File:     "guide.md",
+			Markdown: "Guide",
+			Links:    []string{"https://example.com/guide/usage", "https://example.com/guide/install"},
+		},
+		{URL: "https://example.com/guide/install", Title: "Install", File: "guide-install.md", Markdown: "Back to [API](api.md)"},
+		{URL: "https://example.com/guide/usage", Title: "Usage", File: "guide-usage.md", Markdown: "Usage"},
+	}
+
+	if err := (DocusaurusExporter{}).Export(docs, writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	data, err := writer.ReadFile("docusaurus/sidebar.json")
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+
+	var sidebar map[string][]SidebarItem
+	if err := json.Unmarshal(data, &sidebar); err != nil {
+		t.Fatalf("sidebar is not valid JSON: %v", err)
+	}
+
+	want := []SidebarItem{
+		{Type: "doc", ID: "index"},
+		{
+			Type:  "category",
+			Label: "Guide",
+			Link:  &SidebarLink{Type: "doc", ID: "guide/index"},
+			Items: []SidebarItem{{Type: "doc", ID: "guide/usage"}, {Type: "doc", ID: "guide/install"}},
+		},
+		{Type: "doc", ID: "api"},
+	}
+	if !reflect.DeepEqual(sidebar["docs"], want) {
+		t.Errorf("sidebar = %s", data)
+	}
+
+	home, err := writer.ReadFile("docusaurus/docs/index.md")
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	wantHome := "---\ntitle: \"Home\"\nsidebar_position: 1\n---\n\nSee [install](./guide/install.md) and [API](./api.md).\n"
+	if string(home) != wantHome {
+		t.Errorf("index.md = %q, want %q", home, wantHome)
+	}
+
+	install, err := writer.ReadFile("docusaurus/docs/guide/install.md")
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if !strings.Contains(string(install), "sidebar_position: 2\n") || !strings.Contains(string(install), "[API](../api.md)") {
+		t.Errorf("guide/install.md = %q", install)
+	}
+
+	api, err := writer.ReadFile("docusaurus/docs/api.md")
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if !strings.Contains(string(api), `Returns \{json\}`) {
+		t.Errorf("api.md is not MDX escaped: %q", api)
+	}
+}
+
+func TestDocusaurusExporterNavigationOrder(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	docs := []Document{
+		{URL: "https://example.com/", Title: "Home", File: "index.md", Links: []string{"https://example.com/guide/", "https://example.com/api"}, NavPosition: 1},
+		{URL: "https://example.com/guide/", Title: "Guide", File: "guide.md", Links: []string{"https://example.com/guide/usage", "https://example.com/guide/install"}, NavPosition: 4},
+		{URL: "https://example.com/guide/install", Title: "Install", File: "guide-install.md", NavPosition: 3},
+		{URL: "https://example.com/guide/usage", Title: "Usage", File: "guide-usage.md"},
+		{URL: "https://example.com/api", Title: "API", File: "api.md", NavPosition: 2},
+	}
+
+	if err := (DocusaurusExporter{}).Export(docs, writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	data, err := writer.ReadFile("docusaurus/sidebar.json")
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+
+	var sidebar map[string][]SidebarItem
+	if err := json.Unmarshal(data, &sidebar); err != nil {
+		t.Fatalf("sidebar is not valid JSON: %v", err)
+	}
+
+	// Pages outside the navigation follow the navigated ones
+	want := []SidebarItem{
+		{Type: "doc", ID: "index"},
+		{Type: "doc", ID: "api"},
+		{
+			Type:  "category",
+			Label: "Guide",
+			Link:  &SidebarLink{Type: "doc", ID: "guide/index"},
+			Items: []SidebarItem{{Type: "doc", ID: "guide/install"}, {Type: "doc", ID: "guide/usage"}},
+		},
+	}
+	if !reflect.DeepEqual(sidebar["docs"], want) {
+		t.Errorf("sidebar = %s", data)
+	}
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// Document is a converted page handed to exporters
+type Document struct {
+	URL       string
+	Title     string
+	File      string // Slash-separated path of the page relative to the output root
+	Markdown  string
+	Summary   string   // Extractive summary of the page, empty unless summaries are enabled
+	Links     []string // Absolute URLs linked from the page
+	FetchedAt time.Time
+
+	NavPosition int    // 1-based position of the page in the site navigation, 0 when it is not linked from it
+	NavParent   string // URL of the navigation item the page is nested under, empty for top-level items
+}
+
+// Exporter produces additional output from the converted pages once a crawl completes
+type Exporter interface {
+	// Name identifies the exporter in logs
+	Name() string
+	// Export writes the exporter output through the writer
+	Export(docs []Document, writer output.Writer) error
+}
+
+var (
+	fencePattern    = regexp.MustCompile("(?m)^\\s*(```|~~~).*$")
+	imagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	headingPattern  = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	quotePattern    = regexp.MustCompile(`(?m)^\s*>\s?`)
+	listPattern     = regexp.MustCompile(`(?m)^\s*([-*+]|\d+\.)\s+`)
+	tableRowPattern = regexp.MustCompile(`(?m)^\s*\|?(\s*:?-{3,}:?\s*\|)+\s*:?-*:?\s*$`)
+	emphasisPattern = regexp.MustCompile("[*_`~|]+")
+	spacePattern    = regexp.MustCompile(`[ \t]+`)
+	blankPattern    = regexp.MustCompile(`\n{2,}`)
+)
+
+// PlainText strips Markdown syntax and returns the readable text of a page
+func PlainText(markdown string) string {
+	text := fencePattern.ReplaceAllString(markdown, "")
+	text = imagePattern.ReplaceAllString(text, "$1")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = headingPattern.ReplaceAllString(text, "")
+	text = quotePattern.ReplaceAllString(text, "")
+	text = listPattern.ReplaceAllString(text, "")
+	text = tableRowPattern.ReplaceAllString(text, "")
+	text = emphasisPattern.ReplaceAllString(text, " ")
+	text = spacePattern.ReplaceAllString(text, " ")
+	text = blankPattern.ReplaceAllString(text, "\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Content returns the Markdown of a document without its leading front matter and the "# Title / URL:" header
+// prepended to standard pages
+func Content(doc Document) string {
+	if rest, ok := strings.CutPrefix(doc.Markdown, "---\n"); ok {
+		if end := strings.Index(rest, "\n---\n"); end >= 0 {
+			doc.Markdown = strings.TrimLeft(rest[end+len("\n---\n"):], "\n")
+		}
+	}
+	return stripDefaultHeader(doc)
+}
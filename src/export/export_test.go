@@ -0,0 +1,38 @@
+package export
+
+import (
+	"testing"
+)
+
+func TestPlainText(t *testing.T) {
+	markdown := "# Title\n\nSome **bold** and *italic* with a [link](https://example.com).\n\n" +
+		"![logo](logo.png)\n\n- item one\n- item two\n\n```go\nfmt.Println(\"hi\")\n```\n\n" +
+		"| A | B |\n|---|---|\n| 1 | 2 |\n\n> quoted"
+
+	want := "Title\nSome bold and italic with a link.\nlogo\nitem one\nitem two\nfmt.Println(\"hi\")\nA B\n1 2\nquoted"
+
+	if got := PlainText(markdown); got != want {
+		t.Errorf("PlainText() = %q, want %q", got, want)
+	}
+}
+
+func TestContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{name: "default header", markdown: "# Home\n\nURL: https://example.com/\n\n---\n\nWelcome.\n", want: "Welcome."},
+		{name: "front matter and header", markdown: "---\nsummary: \"Hi.\"\n---\n\n# Home\n\nURL: https://example.com/\n\n---\n\nWelcome.\n", want: "Welcome."},
+		{name: "custom template", markdown: "Welcome.\n\n---\n\nFooter.", want: "Welcome.\n\n---\n\nFooter."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Document{URL: "https://example.com/", Title: "Home", Markdown: tt.markdown}
+			if got := Content(doc); got != tt.want {
+				t.Errorf("Content() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
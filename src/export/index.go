@@ -0,0 +1,88 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// IndexFilename is the default name of the index of the output root
+const IndexFilename = "README.md"
+
+// IndexExporter writes a Markdown index at the root of the output listing the pages nested by URL path,
+// with links to their files and their summaries, so that the output can be browsed on a Git host or in an editor
+type IndexExporter struct {
+	Filename string
+	// Site is the start URL of the crawl, whose page gives the title and the description of the index
+	Site string
+}
+
+// Name identifies the exporter
+func (e IndexExporter) Name() string {
+	return "index"
+}
+
+// Export builds the index and writes it through the writer
+func (e IndexExporter) Export(docs []Document, writer output.Writer) error {
+	filename := e.Filename
+	if filename == "" {
+		filename = IndexFilename
+	}
+
+	for _, doc := range docs {
+		if strings.EqualFold(doc.File, filename) {
+			return fmt.Errorf("index file %s is the file of page %s", filename, doc.URL)
+		}
+	}
+
+	return writer.WriteFile(filename, []byte(BuildIndex(docs, e.Site)))
+}
+
+// BuildIndex returns a Markdown index of the documents, nested by URL path in navigation and crawl order.
+// Path segments without a page of their own are listed as section labels.
+func BuildIndex(docs []Document, site string) string {
+	title, description := siteHeading(docs, site)
+
+	var builder strings.Builder
+	builder.WriteString("# " + title + "\n\n")
+	if description != "" {
+		builder.WriteString("> " + description + "\n\n")
+	}
+
+	root := buildDocTree(docs)
+	if root.doc != nil {
+		writeIndexItem(&builder, root, 0)
+	}
+	for _, child := range sortedChildren(root) {
+		writeIndexTree(&builder, child, 0)
+	}
+
+	return builder.String()
+}
+
+func writeIndexTree(builder *strings.Builder, node *docNode, depth int) {
+	writeIndexItem(builder, node, depth)
+	for _, child := range sortedChildren(node) {
+		writeIndexTree(builder, child, depth+1)
+	}
+}
+
+// writeIndexItem writes the list item of a node: a link to the file of its page with the page summary, or its section label
+func writeIndexItem(builder *strings.Builder, node *docNode, depth int) {
+	builder.WriteString(strings.Repeat("  ", depth) + "- ")
+	if node.doc == nil {
+		builder.WriteString(sectionLabel(node.segment) + "\n")
+		return
+	}
+
+	text := node.doc.Title
+	if text == "" {
+		text = node.doc.URL
+	}
+	builder.WriteString("[" + linkTextEscaper.Replace(text) + "](" + escapeWikiPath(node.doc.File) + ")")
+	if node.doc.Summary != "" {
+		builder.WriteString(": " + node.doc.Summary)
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+func TestBuildIndex(t *testing.T) {
+	docs := []Document{
+		{URL: "https://example.com/", Title: "Example Docs", File: "index.md", Summary: "Documentation of the example tool."},
+		{URL: "https://example.com/guides/getting-started/install", Title: "Install [beta]", File: "guides/getting-started/install.md", Summary: "How to install."},
+		{URL: "https://example.com/guides/", Title: "Guides", File: "guides/index.md"},
+		{URL: "https://example.com/api reference", File: "api reference.md"},
+	}
+
+	want := "# Example Docs\n\n> Documentation of the example tool.\n\n" +
+		"- [Example Docs](index.md): Documentation of the example tool.\n" +
+		"- [https://example.com/api reference](api%20reference.md)\n" +
+		"- [Guides](guides/index.md)\n" +
+		"  - Getting Started\n" +
+		"    - [Install \\[beta\\]](guides/getting-started/install.md): How to install.\n"
+	if got := BuildIndex(docs, "https://example.com"); got != want {
+		t.Errorf("BuildIndex() = %q, want %q", got, want)
+	}
+}
+
+func TestIndexExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	docs := []Document{{URL: "https://example.com/", Title: "Home", File: "index.md"}}
+
+	if err := (IndexExporter{Site: "https://example.com/"}).Export(docs, writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	data, err := writer.ReadFile(IndexFilename)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	if want := "# Home\n\n- [Home](index.md)\n"; string(data) != want {
+		t.Errorf("README.md = %q, want %q", data, want)
+	}
+
+	if err := (IndexExporter{Filename: "index.md", Site: "https://example.com/"}).Export(docs, writer); err == nil {
+		t.Error("Export() expected an error when the index file is the file of a page")
+	}
+}
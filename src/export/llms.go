@@ -0,0 +1,85 @@
+package export
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// LLMsTxtFilename is the default name of the llms.txt index
+const LLMsTxtFilename = "llms.txt"
+
+var linkTextEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+
+// LLMsTxtExporter writes an llms.txt index (https://llmstxt.org) listing the converted pages with their summaries
+type LLMsTxtExporter struct {
+	Filename string
+	// Site is the start URL of the crawl, whose page gives the title and the description of the index
+	Site string
+}
+
+// Name identifies the exporter
+func (e LLMsTxtExporter) Name() string {
+	return "llms.txt"
+}
+
+// Export builds the index and writes it through the writer
+func (e LLMsTxtExporter) Export(docs []Document, writer output.Writer) error {
+	filename := e.Filename
+	if filename == "" {
+		filename = LLMsTxtFilename
+	}
+
+	return writer.WriteFile(filename, []byte(BuildLLMsTxt(docs, e.Site)))
+}
+
+// BuildLLMsTxt returns an llms.txt index of the documents, ordered by URL. The title and the description
+// are those of the document of the site URL, with the host of the site as the title when it was not converted.
+func BuildLLMsTxt(docs []Document, site string) string {
+	sorted := append([]Document(nil), docs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].URL < sorted[j].URL
+	})
+
+	title, description := siteHeading(sorted, site)
+
+	var builder strings.Builder
+	builder.WriteString("# " + title + "\n\n")
+	if description != "" {
+		builder.WriteString("> " + description + "\n\n")
+	}
+	builder.WriteString("## Pages\n\n")
+	for _, doc := range sorted {
+		text := doc.Title
+		if text == "" {
+			text = doc.URL
+		}
+		builder.WriteString("- [" + linkTextEscaper.Replace(text) + "](" + doc.URL + ")")
+		if doc.Summary != "" {
+			builder.WriteString(": " + doc.Summary)
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// siteHeading returns the title and the description of an index of the documents: those of the document of the site URL,
+// with the host of the site as the title when it was not converted
+func siteHeading(docs []Document, site string) (string, string) {
+	title := "Pages"
+	if parsedURL, err := url.Parse(site); err == nil && parsedURL.Host != "" {
+		title = parsedURL.Host
+	}
+	for _, doc := range docs {
+		if strings.TrimSuffix(doc.URL, "/") == strings.TrimSuffix(site, "/") {
+			if doc.Title != "" {
+				title = doc.Title
+			}
+			return title, doc.Summary
+		}
+	}
+	return title, ""
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+func TestBuildLLMsTxt(t *testing.T) {
+	docs := []Document{
+		{URL: "https://example.com/install", Title: "Install [beta]", Summary: "How to install the tool."},
+		{URL: "https://example.com/", Title: "Example Docs", Summary: "Documentation of the example tool."},
+		{URL: "https://example.com/faq"},
+	}
+
+	want := "# Example Docs\n\n> Documentation of the example tool.\n\n## Pages\n\n" +
+		"- [Example Docs](https://example.com/): Documentation of the example tool.\n" +
+		"- [https://example.com/faq](https://example.com/faq)\n" +
+		"- [Install \\[beta\\]](https://example.com/install): How to install the tool.\n"
+	if got := BuildLLMsTxt(docs, "https://example.com"); got != want {
+		t.Errorf("BuildLLMsTxt() = %q, want %q", got, want)
+	}
+
+	want = "# example.com\n\n## Pages\n\n- [https://example.com/faq](https://example.com/faq)\n"
+	if got := BuildLLMsTxt(docs[2:], "https://example.com/"); got != want {
+		t.Errorf("BuildLLMsTxt() without start page = %q, want %q", got, want)
+	}
+}
+
+func TestLLMsTxtExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	docs := []Document{{URL: "https://example.com/", Title: "Home", Summary: "Welcome."}}
+
+	if err := (LLMsTxtExporter{Site: "https://example.com/"}).Export(docs, writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	data, err := writer.ReadFile(LLMsTxtFilename)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+
+	if want := "# Home\n\n> Welcome.\n\n## Pages\n\n- [Home](https://example.com/): Welcome.\n"; string(data) != want {
+		t.Errorf("llms.txt = %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"regexp"
+	"strings"
+)
+
+// headingID matches the trailing {#id} attribute of an ATX heading, which Docusaurus reads as the heading id
+var headingID = regexp.MustCompile(`^ {0,3}#{1,6}\s.*?(\s\{#[A-Za-z][\w:.-]*\})\s*$`)
+
+// EscapeMDX escapes the characters that MDX would parse as JSX or expressions.
+// Braces are backslash-escaped and "<" becomes "&lt;" outside fenced code blocks and inline code spans.
+// The {#id} attribute ending a heading is kept, since Docusaurus supports it as explicit heading id.
+func EscapeMDX(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	fence := ""
+
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		if match := headingID.FindStringSubmatchIndex(line); match != nil {
+			lines[i] = escapeMDXLine(line[:match[2]]) + line[match[2]:]
+			continue
+		}
+
+		lines[i] = escapeMDXLine(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// escapeMDXLine escapes a single line, leaving inline code spans untouched
+func escapeMDXLine(line string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(line); {
+		if line[i] == '`' {
+			run := backtickRun(line[i:])
+			if end := strings.Index(line[i+run:], line[i:i+run]); end >= 0 {
+				spanEnd := i + run + end + run
+				builder.WriteString(line[i:spanEnd])
+				i = spanEnd
+				continue
+			}
+			builder.WriteString(line[i : i+run])
+			i += run
+			continue
+		}
+
+		switch line[i] {
+		case '{':
+			builder.WriteString(`\{`)
+		case '}':
+			builder.WriteString(`\}`)
+		case '<':
+			builder.WriteString("&lt;")
+		default:
+			builder.WriteByte(line[i])
+		}
+		i++
+	}
+
+	return builder.String()
+}
+
+// backtickRun returns the number of leading backticks
+func backtickRun(s string) int {
+	n := 0
+	for n < len(s) && s[n] == '`' {
+		n++
+	}
+	return n
+}
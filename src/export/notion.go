@@ -0,0 +1,87 @@
+package export
+
+import (
+	"path"
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// NotionDir is the default folder of the Notion export
+const NotionDir = "notion"
+
+// NotionExporter writes the pages in the layout of Notion Markdown exports, which Notion imports back as nested
+// pages: each page is a Markdown file named after its title and starting with it as a heading, its child pages
+// are in the folder of the same name, and links between pages are relative file links
+type NotionExporter struct {
+	Dir string
+}
+
+// Name identifies the exporter
+func (e NotionExporter) Name() string {
+	return "notion"
+}
+
+// Export writes the pages under the pages folder and the mapping file through the writer
+func (e NotionExporter) Export(docs []Document, writer output.Writer) error {
+	dir := e.Dir
+	if dir == "" {
+		dir = NotionDir
+	}
+
+	pages := buildWikiTree(docs, ".md")
+	byFile := wikiPagesByFile(pages, make(map[string]*wikiPage))
+
+	var write func(pages []*wikiPage) error
+	write = func(pages []*wikiPage) error {
+		for _, page := range pages {
+			if err := writer.WriteFile(path.Join(dir, page.File), []byte(notionPage(page, dir, byFile))); err != nil {
+				return err
+			}
+			if err := write(page.children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := write(pages); err != nil {
+		return err
+	}
+
+	return writeWikiMapping(pages, dir, writer)
+}
+
+// notionPage renders a page with its title heading and its links pointing at the exported pages,
+// or back at the files of the output they reference
+func notionPage(page *wikiPage, dir string, byFile map[string]*wikiPage) string {
+	content := "# " + page.Title + "\n"
+	if page.doc == nil {
+		return content
+	}
+
+	body := markdownTargetPattern.ReplaceAllStringFunc(Content(*page.doc), func(match string) string {
+		parts := markdownTargetPattern.FindStringSubmatch(match)
+		resolved, fragment, ok := resolveWikiTarget(page.doc, parts[2])
+		if !ok {
+			return match
+		}
+
+		var target string
+		if linked, ok := byFile[resolved]; ok {
+			target = converter.RelativePath(page.File, linked.File)
+		} else {
+			target = relativeToExport(dir, page.File, resolved)
+		}
+		target = escapeWikiPath(target)
+		if fragment != "" {
+			target += "#" + fragment
+		}
+		return parts[1] + target
+	})
+
+	if body = strings.TrimSpace(body); body != "" {
+		content += "\n" + body + "\n"
+	}
+	return content
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// SearchIndexFilename is the default name of the search index file
+const SearchIndexFilename = "search-index.json"
+
+// minTermLength is the minimum number of characters of an indexed term
+const minTermLength = 2
+
+// SearchIndex is a client-side full-text search index.
+// Documents can be loaded directly into lunr or MiniSearch (fields "title" and "text"),
+// while Terms is a prebuilt inverted index for lightweight clients.
+type SearchIndex struct {
+	Version   int                `json:"version"`
+	Documents []SearchDocument   `json:"documents"`
+	Terms     map[string][][]int `json:"terms"` // term -> [[document id, term frequency], ...]
+}
+
+// SearchDocument is an indexed page
+type SearchDocument struct {
+	ID    int    `json:"id"`
+	URL   string `json:"url"`
+	File  string `json:"file"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// SearchIndexExporter writes a JSON search index over the converted pages
+type SearchIndexExporter struct {
+	Filename string
+}
+
+// Name identifies the exporter
+func (e SearchIndexExporter) Name() string {
+	return "search index"
+}
+
+// Export builds the index and writes it through the writer
+func (e SearchIndexExporter) Export(docs []Document, writer output.Writer) error {
+	filename := e.Filename
+	if filename == "" {
+		filename = SearchIndexFilename
+	}
+
+	data, err := json.Marshal(BuildSearchIndex(docs))
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+
+	return writer.WriteFile(filename, data)
+}
+
+// BuildSearchIndex indexes the title and text of each document, ordered by URL
+func BuildSearchIndex(docs []Document) SearchIndex {
+	sorted := append([]Document(nil), docs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].URL < sorted[j].URL
+	})
+
+	index := SearchIndex{
+		Version:   1,
+		Documents: make([]SearchDocument, 0, len(sorted)),
+		Terms:     make(map[string][][]int),
+	}
+
+	for id, doc := range sorted {
+		text := PlainText(doc.Markdown)
+
+		index.Documents = append(index.Documents, SearchDocument{
+			ID:    id,
+			URL:   doc.URL,
+			File:  doc.File,
+			Title: doc.Title,
+			Text:  text,
+		})
+
+		frequencies := make(map[string]int)
+		for _, term := range Tokenize(doc.Title + " " + text) {
+			frequencies[term]++
+		}
+
+		for term, frequency := range frequencies {
+			index.Terms[term] = append(index.Terms[term], []int{id, frequency})
+		}
+	}
+
+	return index
+}
+
+// Tokenize splits text into lowercase search terms
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) >= minTermLength {
+			terms = append(terms, word)
+		}
+	}
+
+	return terms
+}
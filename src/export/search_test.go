@@ -0,0 +1,62 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+func TestBuildSearchIndex(t *testing.T) {
+	docs := []Document{
+		{URL: "https://example.com/b", Title: "Install", File: "b.md", Markdown: "Install the **tool** with go."},
+		{URL: "https://example.com/a", Title: "Home", File: "a.md", Markdown: "Welcome to the tool tool."},
+	}
+
+	index := BuildSearchIndex(docs)
+
+	if len(index.Documents) != 2 || index.Documents[0].URL != "https://example.com/a" {
+		t.Fatalf("BuildSearchIndex() documents = %+v", index.Documents)
+	}
+
+	if got := index.Terms["tool"]; !reflect.DeepEqual(got, [][]int{{0, 2}, {1, 1}}) {
+		t.Errorf("BuildSearchIndex() terms[tool] = %v", got)
+	}
+
+	if got := index.Terms["install"]; !reflect.DeepEqual(got, [][]int{{1, 2}}) {
+		t.Errorf("BuildSearchIndex() terms[install] = %v", got)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Go is a Tool, café & 42!")
+	want := []string{"go", "is", "tool", "café", "42"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestSearchIndexExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	docs := []Document{{URL: "https://example.com/", Title: "Home", File: "index.md", Markdown: "Hello"}}
+
+	if err := (SearchIndexExporter{}).Export(docs, writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	data, err := writer.ReadFile(SearchIndexFilename)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+
+	var index SearchIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("search index is not valid JSON: %v", err)
+	}
+
+	if index.Version != 1 || len(index.Documents) != 1 || index.Documents[0].Text != "Hello" {
+		t.Errorf("unexpected search index: %+v", index)
+	}
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/sandrolain/crawldown/src/mdlint"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// ValidationReportFilename is the default name of the Markdown validation report
+const ValidationReportFilename = "validation-report.json"
+
+// ValidationReport lists the structural Markdown issues found in the converted pages
+type ValidationReport struct {
+	Files  int              `json:"files"`  // Number of files checked
+	Issues int              `json:"issues"` // Total number of issues
+	Pages  []PageValidation `json:"pages"`  // Files with at least one issue, ordered by file
+}
+
+// PageValidation holds the issues of a single file
+type PageValidation struct {
+	URL    string         `json:"url"`
+	File   string         `json:"file"`
+	Issues []mdlint.Issue `json:"issues"`
+}
+
+// ValidationExporter checks the Markdown of each page and writes the issues found to a JSON report
+type ValidationExporter struct {
+	Filename string
+}
+
+// Name identifies the exporter
+func (e ValidationExporter) Name() string {
+	return "validation report"
+}
+
+// Export validates the documents and writes the report through the writer
+func (e ValidationExporter) Export(docs []Document, writer output.Writer) error {
+	filename := e.Filename
+	if filename == "" {
+		filename = ValidationReportFilename
+	}
+
+	data, err := json.MarshalIndent(Validate(docs), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode validation report: %w", err)
+	}
+
+	return writer.WriteFile(filename, data)
+}
+
+// Validate checks the Markdown of each document
+func Validate(docs []Document) ValidationReport {
+	report := ValidationReport{Files: len(docs), Pages: []PageValidation{}}
+
+	for _, doc := range docs {
+		issues := mdlint.Check(doc.Markdown)
+		if len(issues) == 0 {
+			continue
+		}
+		report.Issues += len(issues)
+		report.Pages = append(report.Pages, PageValidation{URL: doc.URL, File: doc.File, Issues: issues})
+	}
+
+	sort.Slice(report.Pages, func(i, j int) bool {
+		return report.Pages[i].File < report.Pages[j].File
+	})
+
+	return report
+}
@@ -0,0 +1,42 @@
+package export
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/mdlint"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+func TestValidationExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	docs := []Document{
+		{URL: "https://example.com/b", File: "b.md", Markdown: "```\nunclosed"},
+		{URL: "https://example.com/", File: "index.md", Markdown: "# Home"},
+		{URL: "https://example.com/a", File: "a.md", Markdown: "See [docs][missing].\n\n| A |\n| 1 |"},
+	}
+
+	if err := (ValidationExporter{}).Export(docs, writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	data, err := writer.ReadFile(ValidationReportFilename)
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+
+	var report ValidationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("validation report is not valid JSON: %v", err)
+	}
+
+	if report.Files != 3 || report.Issues != 3 || len(report.Pages) != 2 {
+		t.Fatalf("unexpected validation report: %+v", report)
+	}
+	if page := report.Pages[0]; page.File != "a.md" || page.Issues[0].Kind != mdlint.KindBrokenReference || page.Issues[1].Kind != mdlint.KindMalformedTable {
+		t.Errorf("unexpected issues for a.md: %+v", page)
+	}
+	if page := report.Pages[1]; page.File != "b.md" || page.Issues[0].Kind != mdlint.KindUnclosedFence {
+		t.Errorf("unexpected issues for b.md: %+v", page)
+	}
+}
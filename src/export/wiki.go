@@ -0,0 +1,187 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// Wiki export names
+const (
+	WikiMapping    = "pages.json"
+	wikiPagesDir   = "pages"
+	maxWikiNameLen = 100
+)
+
+// markdownTargetPattern matches the target of Markdown links and images
+var markdownTargetPattern = regexp.MustCompile(`(\]\()([^)\s]+)`)
+
+// WikiPage is an entry of the mapping file of a wiki export, listed parents first and siblings in order
+type WikiPage struct {
+	Title       string   `json:"title"`
+	File        string   `json:"file"`                  // Path of the page relative to the export folder
+	URL         string   `json:"url,omitempty"`         // Empty for section pages grouping pages without a page of their own
+	Parent      string   `json:"parent,omitempty"`      // File of the parent page
+	Position    int      `json:"position"`              // 1-based position of the page among its siblings
+	Attachments []string `json:"attachments,omitempty"` // Files to attach to the page, relative to the output root
+}
+
+// wikiPage is a page of a wiki export with the document it is made from, nil for section pages
+type wikiPage struct {
+	WikiPage
+	doc      *Document
+	children []*wikiPage
+}
+
+// buildWikiTree arranges the documents in uniquely titled pages nested by URL path, in navigation and crawl order,
+// and names their files after their titles with ext, each page in the folder named after its parent
+func buildWikiTree(docs []Document, ext string) []*wikiPage {
+	root := buildDocTree(docs)
+	titles := make(map[string]bool)
+
+	var pages []*wikiPage
+	if root.doc != nil {
+		pages = append(pages, newWikiPage(root, titles))
+	}
+	for _, child := range sortedChildren(root) {
+		pages = append(pages, wikiSubtree(child, titles))
+	}
+
+	assignWikiFiles(pages, nil, wikiPagesDir, ext, make(map[string]bool))
+	return pages
+}
+
+func wikiSubtree(node *docNode, titles map[string]bool) *wikiPage {
+	page := newWikiPage(node, titles)
+	for _, child := range sortedChildren(node) {
+		page.children = append(page.children, wikiSubtree(child, titles))
+	}
+	return page
+}
+
+// newWikiPage titles the page of a node after its document, or after its path segment for sections,
+// numbering titles already taken since wikis such as Confluence require unique titles
+func newWikiPage(node *docNode, titles map[string]bool) *wikiPage {
+	page := &wikiPage{doc: node.doc}
+
+	title := sectionLabel(node.segment)
+	if node.doc != nil {
+		page.URL = node.doc.URL
+		title = strings.TrimSpace(node.doc.Title)
+		if title == "" {
+			title = node.doc.URL
+		}
+	}
+
+	page.Title = uniqueName(title, titles)
+	return page
+}
+
+// assignWikiFiles names the files of the pages in dir and of their children in the folder named after them
+func assignWikiFiles(pages []*wikiPage, parent *wikiPage, dir, ext string, used map[string]bool) {
+	for position, page := range pages {
+		name := uniqueName(path.Join(dir, wikiFilename(page.Title)), used)
+		page.File = name + ext
+		page.Position = position + 1
+		if parent != nil {
+			page.Parent = parent.File
+		}
+		assignWikiFiles(page.children, page, name, ext, used)
+	}
+}
+
+// uniqueName returns name, or name followed by the first free number, and marks it as used ignoring case
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for n := 2; used[strings.ToLower(unique)]; n++ {
+		unique = name + " " + strconv.Itoa(n)
+	}
+	used[strings.ToLower(unique)] = true
+	return unique
+}
+
+// wikiFilename turns a title into a file name, replacing the characters invalid on common file systems
+func wikiFilename(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return ' '
+		}
+		return r
+	}, title)
+	name = strings.Join(strings.Fields(name), " ")
+
+	if runes := []rune(name); len(runes) > maxWikiNameLen {
+		name = strings.TrimSpace(string(runes[:maxWikiNameLen]))
+	}
+	name = strings.TrimRight(name, ". ")
+	if name == "" {
+		name = "Untitled"
+	}
+	return name
+}
+
+// wikiPagesByFile indexes the pages made from documents by the file of their document
+func wikiPagesByFile(pages []*wikiPage, byFile map[string]*wikiPage) map[string]*wikiPage {
+	for _, page := range pages {
+		if page.doc != nil {
+			byFile[page.doc.File] = page
+		}
+		wikiPagesByFile(page.children, byFile)
+	}
+	return byFile
+}
+
+// resolveWikiTarget resolves a relative link target of a document to a path relative to the output root
+// and its fragment. Absolute URLs, root-relative paths, and fragments of the page itself are not resolved.
+func resolveWikiTarget(doc *Document, target string) (string, string, bool) {
+	if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") {
+		return "", "", false
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" || parsed.Path == "" {
+		return "", "", false
+	}
+
+	resolved := path.Join(path.Dir(doc.File), parsed.Path)
+	if strings.HasPrefix(resolved, "../") {
+		return "", "", false
+	}
+	return resolved, parsed.Fragment, true
+}
+
+// escapeWikiPath percent-encodes a relative path for use as a link target
+func escapeWikiPath(relative string) string {
+	return (&url.URL{Path: relative}).EscapedPath()
+}
+
+// writeWikiMapping writes the mapping file of the pages, parents first
+func writeWikiMapping(pages []*wikiPage, dir string, writer output.Writer) error {
+	var entries []WikiPage
+	var flatten func(pages []*wikiPage)
+	flatten = func(pages []*wikiPage) {
+		for _, page := range pages {
+			entries = append(entries, page.WikiPage)
+			flatten(page.children)
+		}
+	}
+	flatten(pages)
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode page mapping: %w", err)
+	}
+	return writer.WriteFile(path.Join(dir, WikiMapping), data)
+}
+
+// relativeToExport returns the path of a file of the output root as seen from a page file of an export folder
+func relativeToExport(dir, pageFile, target string) string {
+	return converter.RelativePath(path.Join(dir, pageFile), target)
+}
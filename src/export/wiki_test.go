@@ -0,0 +1,105 @@
+package export
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+func wikiDocs() []Document {
+	return []Document{
+		{
+			URL: "https://example.com/", Title: "Home", File: "index.md",
+			Markdown: "# Home\n\nURL: https://example.com/\n\n---\n\nSee [install](guide/install.md#steps) and [FAQ](faq.md).\n\n![Logo](assets/logo.png)",
+			Links:    []string{"https://example.com/guide/install", "https://example.com/faq"},
+		},
+		{
+			URL: "https://example.com/guide/install", Title: "Install: Linux", File: "guide/install.md",
+			Markdown: "Back [home](../index.md).\n\n```sh\nmake <target>\n```\n\n<table><tr><td>A<br>B</td></tr></table>",
+		},
+		{URL: "https://example.com/faq", Title: "Home", File: "faq.md", Markdown: "Questions."},
+	}
+}
+
+func readMapping(t *testing.T, writer output.Writer, dir string) []WikiPage {
+	t.Helper()
+	data, err := writer.ReadFile(path.Join(dir, WikiMapping))
+	if err != nil {
+		t.Fatalf("ReadFile() unexpected error: %v", err)
+	}
+	var pages []WikiPage
+	if err := json.Unmarshal(data, &pages); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	return pages
+}
+
+func readExportFile(t *testing.T, writer output.Writer, name string) string {
+	t.Helper()
+	data, err := writer.ReadFile(name)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) unexpected error: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestNotionExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	if err := (NotionExporter{}).Export(wikiDocs(), writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	want := []WikiPage{
+		{Title: "Home", File: "pages/Home.md", URL: "https://example.com/", Position: 1},
+		{Title: "Guide", File: "pages/Guide.md", Position: 2},
+		{Title: "Install: Linux", File: "pages/Guide/Install Linux.md", URL: "https://example.com/guide/install", Parent: "pages/Guide.md", Position: 1},
+		{Title: "Home 2", File: "pages/Home 2.md", URL: "https://example.com/faq", Position: 3},
+	}
+	if got := readMapping(t, writer, NotionDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("mapping = %+v, want %+v", got, want)
+	}
+
+	home := readExportFile(t, writer, "notion/pages/Home.md")
+	wantHome := "# Home\n\nSee [install](Guide/Install%20Linux.md#steps) and [FAQ](Home%202.md).\n\n![Logo](../../assets/logo.png)\n"
+	if home != wantHome {
+		t.Errorf("home page = %q, want %q", home, wantHome)
+	}
+	if install := readExportFile(t, writer, "notion/pages/Guide/Install Linux.md"); !strings.HasPrefix(install, "# Install: Linux\n\nBack [home](../Home.md).") {
+		t.Errorf("install page = %q, want a link back to the home page", install)
+	}
+	if section := readExportFile(t, writer, "notion/pages/Guide.md"); section != "# Guide\n" {
+		t.Errorf("section page = %q, want its title only", section)
+	}
+}
+
+func TestConfluenceExporter(t *testing.T) {
+	writer := output.NewDirWriter(t.TempDir(), output.Permissions{})
+	if err := (ConfluenceExporter{}).Export(wikiDocs(), writer); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	pages := readMapping(t, writer, ConfluenceDir)
+	if len(pages) != 4 || pages[0].File != "pages/Home.xhtml" || !reflect.DeepEqual(pages[0].Attachments, []string{"assets/logo.png"}) {
+		t.Errorf("mapping = %+v, want the home page with its logo attachment first", pages)
+	}
+
+	home := readExportFile(t, writer, "confluence/pages/Home.xhtml")
+	wantHome := `<p>See <ac:link ac:anchor="steps"><ri:page ri:content-title="Install: Linux"></ri:page><ac:link-body>install</ac:link-body></ac:link>` +
+		` and <ac:link><ri:page ri:content-title="Home 2"></ri:page><ac:link-body>FAQ</ac:link-body></ac:link>.</p>` + "\n" +
+		`<p><ac:image ac:alt="Logo"><ri:attachment ri:filename="logo.png"></ri:attachment></ac:image></p>` + "\n"
+	if home != wantHome {
+		t.Errorf("home page = %q, want %q", home, wantHome)
+	}
+
+	install := readExportFile(t, writer, "confluence/pages/Guide/Install Linux.xhtml")
+	wantInstall := `<p>Back <ac:link><ri:page ri:content-title="Home"></ri:page><ac:link-body>home</ac:link-body></ac:link>.</p>` + "\n" +
+		`<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">sh</ac:parameter><ac:plain-text-body>make &lt;target&gt;</ac:plain-text-body></ac:structured-macro>` + "\n" +
+		`<table><tbody><tr><td>A<br/>B</td></tr></tbody></table>` + "\n"
+	if install != wantInstall {
+		t.Errorf("install page = %q, want %q", install, wantInstall)
+	}
+}
@@ -0,0 +1,314 @@
+package flavor
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+// Flavor names
+const (
+	Standard = "standard"
+	Obsidian = "obsidian"
+	Hugo     = "hugo"
+	Pandoc   = "pandoc"
+)
+
+// Flavor controls the page layout, link syntax, and asset location of the Markdown output
+type Flavor interface {
+	// Name returns the flavor name used on the command line
+	Name() string
+	// AssetsDir returns the directory where extracted assets are written
+	AssetsDir() string
+	// Filename returns the output path of a page
+	Filename(pageURL string) string
+	// Page returns the file content for a page without a matching template
+	Page(data render.Data) string
+	// RewriteLinks rewrites links to crawled pages into local references
+	RewriteLinks(markdown, pageURL string, urlToFile map[string]string) string
+}
+
+// SectionIndexer is implemented by flavors that need index files for directories without a crawled page
+type SectionIndexer interface {
+	// SectionIndexes returns the content of the index files missing for the given page files
+	SectionIndexes(files []string) map[string]string
+}
+
+// LanguageSplitter is implemented by flavors that place language directories somewhere other than the output root
+type LanguageSplitter interface {
+	// LanguageFilename returns the output path of a page in the directory of its language
+	LanguageFilename(filename, language string) string
+}
+
+// LanguageFilename returns the output path of a page when the output is split by language
+func LanguageFilename(f Flavor, filename, language string) string {
+	if splitter, ok := f.(LanguageSplitter); ok {
+		return splitter.LanguageFilename(filename, language)
+	}
+	return path.Join(language, filename)
+}
+
+// Names returns the supported flavor names
+func Names() []string {
+	return []string{Standard, Obsidian, Hugo, Pandoc}
+}
+
+// Get returns the flavor with the given name, defaulting to the standard flavor
+func Get(name string) (Flavor, error) {
+	switch name {
+	case "", Standard:
+		return standardFlavor{}, nil
+	case Obsidian:
+		return obsidianFlavor{}, nil
+	case Hugo:
+		return hugoFlavor{}, nil
+	case Pandoc:
+		return pandocFlavor{}, nil
+	default:
+		return nil, fmt.Errorf("unknown flavor %q: expected one of %s", name, strings.Join(Names(), ", "))
+	}
+}
+
+// standardFlavor writes a title header and relative Markdown links
+type standardFlavor struct {
+	metadata bool // When true, the Open Graph, Twitter card, and JSON-LD metadata of pages is written to front matter
+}
+
+// StandardWithMetadata returns the standard flavor writing the metadata of pages into their front matter
+func StandardWithMetadata() Flavor { return standardFlavor{metadata: true} }
+
+func (standardFlavor) Name() string { return Standard }
+
+func (standardFlavor) AssetsDir() string { return converter.DefaultAssetsDir }
+
+func (standardFlavor) Filename(pageURL string) string { return converter.GenerateFilename(pageURL) }
+
+// Page writes the title header, preceded by front matter with the tags, the summary, and, when enabled,
+// the metadata of the page when it has any
+func (f standardFlavor) Page(data render.Data) string {
+	header := render.DefaultHeader(data.Title, data.URL)
+	metadata := f.metadata && !data.Metadata.IsZero()
+	if len(data.Tags) == 0 && data.Summary == "" && !metadata {
+		return header + data.Markdown
+	}
+
+	var builder strings.Builder
+	builder.WriteString("---\n")
+	writeTags(&builder, data.Tags)
+	writeSummary(&builder, data.Summary)
+	if metadata {
+		writeStandardMetadata(&builder, data)
+	}
+	builder.WriteString("---\n\n")
+	return builder.String() + header + data.Markdown
+}
+
+// standardAssetRefPattern matches references to extracted assets and downloaded files, which are relative to the output root
+var standardAssetRefPattern = regexp.MustCompile(`\]\(((?:` + converter.DefaultAssetsDir + `|` + converter.DefaultFilesDir + `)/[^)\s]+)`)
+
+// RewriteLinks writes links relative to the page file, so pages in language directories reach root-level assets
+func (standardFlavor) RewriteLinks(markdown, pageURL string, urlToFile map[string]string) string {
+	pageFile := urlToFile[strings.TrimSuffix(pageURL, "/")]
+	markdown = converter.ConvertLinksToLocalFrom(markdown, pageURL, pageFile, urlToFile)
+
+	if !strings.Contains(pageFile, "/") {
+		return markdown
+	}
+
+	return standardAssetRefPattern.ReplaceAllStringFunc(markdown, func(match string) string {
+		return "](" + converter.RelativePath(pageFile, strings.TrimPrefix(match, "]("))
+	})
+}
+
+// obsidianFlavor writes front matter with aliases and tags, wikilinks, and an attachments folder
+type obsidianFlavor struct{}
+
+// obsidianAttachmentsDir is the folder used for assets in Obsidian vaults
+const obsidianAttachmentsDir = "attachments"
+
+var tagInvalidChars = regexp.MustCompile(`[^a-z0-9_/-]+`)
+
+func (obsidianFlavor) Name() string { return Obsidian }
+
+func (obsidianFlavor) AssetsDir() string { return obsidianAttachmentsDir }
+
+func (obsidianFlavor) Filename(pageURL string) string { return converter.GenerateFilename(pageURL) }
+
+func (obsidianFlavor) Page(data render.Data) string {
+	var builder strings.Builder
+
+	builder.WriteString("---\n")
+	builder.WriteString("title: " + strconv.Quote(data.Title) + "\n")
+	if data.Title != "" {
+		builder.WriteString("aliases:\n")
+		builder.WriteString("  - " + strconv.Quote(data.Title) + "\n")
+	}
+
+	tags := pageTags(data)
+	if len(tags) > 0 {
+		builder.WriteString("tags:\n")
+		for _, tag := range tags {
+			builder.WriteString("  - " + tag + "\n")
+		}
+	}
+
+	writeSummary(&builder, data.Summary)
+	builder.WriteString("source: " + strconv.Quote(data.URL) + "\n")
+	if !data.FetchedAt.IsZero() {
+		builder.WriteString("created: " + data.FetchedAt.UTC().Format(time.RFC3339) + "\n")
+	}
+	writeObsidianMetadata(&builder, data)
+	writeCanonical(&builder, data)
+	builder.WriteString("---\n\n")
+	builder.WriteString(data.Markdown)
+
+	return builder.String()
+}
+
+func (obsidianFlavor) RewriteLinks(markdown, pageURL string, urlToFile map[string]string) string {
+	return converter.ConvertLinksToWikilinks(markdown, pageURL, urlToFile)
+}
+
+// writeObsidianMetadata writes page metadata as Obsidian properties
+func writeObsidianMetadata(builder *strings.Builder, data render.Data) {
+	meta := data.Metadata
+	if meta.Description != "" {
+		builder.WriteString("description: " + strconv.Quote(meta.Description) + "\n")
+	}
+	if meta.Author != "" {
+		builder.WriteString("author: " + strconv.Quote(meta.Author) + "\n")
+	}
+	if meta.Image != "" {
+		builder.WriteString("image: " + strconv.Quote(meta.Image) + "\n")
+	}
+	if meta.PublishedTime != "" {
+		builder.WriteString("published: " + strconv.Quote(meta.PublishedTime) + "\n")
+	}
+	if meta.ModifiedTime != "" {
+		builder.WriteString("modified: " + strconv.Quote(meta.ModifiedTime) + "\n")
+	}
+}
+
+// writeStandardMetadata writes page metadata under the field names of the manifest, with the Open Graph and
+// Twitter card properties as maps and the JSON-LD blocks as a list of JSON objects, which are valid YAML
+func writeStandardMetadata(builder *strings.Builder, data render.Data) {
+	meta := data.Metadata
+	for _, field := range []struct{ key, value string }{
+		{"description", meta.Description},
+		{"image", meta.Image},
+		{"type", meta.Type},
+		{"site_name", meta.SiteName},
+		{"author", meta.Author},
+		{"published_time", meta.PublishedTime},
+		{"modified_time", meta.ModifiedTime},
+	} {
+		if field.value != "" {
+			builder.WriteString(field.key + ": " + strconv.Quote(field.value) + "\n")
+		}
+	}
+
+	writeStringMap(builder, "open_graph", meta.OpenGraph)
+	writeStringMap(builder, "twitter", meta.Twitter)
+
+	if len(meta.JSONLD) > 0 {
+		builder.WriteString("json_ld:\n")
+		for _, block := range meta.JSONLD {
+			builder.WriteString("  - " + string(block) + "\n")
+		}
+	}
+}
+
+// writeStringMap writes a map as a front matter field with quoted keys in sorted order
+func writeStringMap(builder *strings.Builder, key string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for name := range values {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	builder.WriteString(key + ":\n")
+	for _, name := range keys {
+		builder.WriteString("  " + strconv.Quote(name) + ": " + strconv.Quote(values[name]) + "\n")
+	}
+}
+
+// writeCanonical writes the canonical URL and the hreflang alternates of a page as front matter fields
+func writeCanonical(builder *strings.Builder, data render.Data) {
+	if data.Canonical != "" {
+		builder.WriteString("canonical: " + strconv.Quote(data.Canonical) + "\n")
+	}
+
+	if len(data.Alternates) == 0 {
+		return
+	}
+
+	languages := make([]string, 0, len(data.Alternates))
+	for language := range data.Alternates {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	builder.WriteString("alternates:\n")
+	for _, language := range languages {
+		builder.WriteString("  " + strconv.Quote(language) + ": " + strconv.Quote(data.Alternates[language]) + "\n")
+	}
+}
+
+// writeTags writes the tags assigned by tag rules as a front matter list
+func writeTags(builder *strings.Builder, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	builder.WriteString("tags:\n")
+	for _, tag := range tags {
+		builder.WriteString("  - " + strconv.Quote(tag) + "\n")
+	}
+}
+
+// writeSummary writes the extractive summary of a page as a front matter field
+func writeSummary(builder *strings.Builder, summary string) {
+	if summary != "" {
+		builder.WriteString("summary: " + strconv.Quote(summary) + "\n")
+	}
+}
+
+// pageTags derives tags from the site host and the URL section, followed by the tags assigned by tag rules
+func pageTags(data render.Data) []string {
+	var tags []string
+
+	if parsedURL, err := url.Parse(data.URL); err == nil && parsedURL.Hostname() != "" {
+		tags = appendTag(tags, strings.ReplaceAll(parsedURL.Hostname(), ".", "-"))
+	}
+	tags = appendTag(tags, data.Section)
+	for _, tag := range data.Tags {
+		tags = appendTag(tags, tag)
+	}
+
+	return tags
+}
+
+func appendTag(tags []string, value string) []string {
+	tag := strings.Trim(tagInvalidChars.ReplaceAllString(strings.ToLower(value), "-"), "-/")
+	if tag == "" {
+		return tags
+	}
+	for _, existing := range tags {
+		if existing == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
@@ -0,0 +1,191 @@
+package flavor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/metadata"
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "", wantName: Standard},
+		{name: Standard, wantName: Standard},
+		{name: Obsidian, wantName: Obsidian},
+		{name: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Get(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Name() != tt.wantName {
+				t.Errorf("Get() name = %q, want %q", got.Name(), tt.wantName)
+			}
+		})
+	}
+}
+
+func TestStandardPage(t *testing.T) {
+	f, _ := Get(Standard)
+	data := render.NewData("https://example.com/docs/intro", "Intro", "docs-intro.md", "Body", time.Time{})
+
+	want := "# Intro\n\nURL: https://example.com/docs/intro\n\n---\n\nBody"
+	if got := f.Page(data); got != want {
+		t.Errorf("Page() = %q, want %q", got, want)
+	}
+
+	data.Tags = []string{"docs", "getting started"}
+	want = "---\ntags:\n  - \"docs\"\n  - \"getting started\"\n---\n\n# Intro\n\nURL: https://example.com/docs/intro\n\n---\n\nBody"
+	if got := f.Page(data); got != want {
+		t.Errorf("Page() with tags = %q, want %q", got, want)
+	}
+
+	data.Tags = nil
+	data.Summary = "Getting started with the tool."
+	want = "---\nsummary: \"Getting started with the tool.\"\n---\n\n# Intro\n\nURL: https://example.com/docs/intro\n\n---\n\nBody"
+	if got := f.Page(data); got != want {
+		t.Errorf("Page() with summary = %q, want %q", got, want)
+	}
+}
+
+func TestStandardPageMetadata(t *testing.T) {
+	data := render.NewData("https://example.com/docs/intro", "Intro", "docs-intro.md", "Body", time.Time{})
+	data.Metadata = metadata.Metadata{
+		Description:   "Getting started",
+		PublishedTime: "2024-03-01T08:00:00Z",
+		OpenGraph:     map[string]string{"title": "Intro", "image:width": "1200"},
+		Twitter:       map[string]string{"card": "summary"},
+		JSONLD:        []json.RawMessage{json.RawMessage(`{"@type":"Article"}`)},
+	}
+
+	f, _ := Get(Standard)
+	if got := f.Page(data); strings.HasPrefix(got, "---") {
+		t.Errorf("Page() without metadata front matter = %q, want no front matter", got)
+	}
+
+	want := "---\n" +
+		"description: \"Getting started\"\n" +
+		"published_time: \"2024-03-01T08:00:00Z\"\n" +
+		"open_graph:\n  \"image:width\": \"1200\"\n  \"title\": \"Intro\"\n" +
+		"twitter:\n  \"card\": \"summary\"\n" +
+		"json_ld:\n  - {\"@type\":\"Article\"}\n" +
+		"---\n\n# Intro\n\nURL: https://example.com/docs/intro\n\n---\n\nBody"
+	if got := StandardWithMetadata().Page(data); got != want {
+		t.Errorf("Page() with metadata = %q, want %q", got, want)
+	}
+
+	data.Metadata = metadata.Metadata{}
+	if got := StandardWithMetadata().Page(data); strings.HasPrefix(got, "---") {
+		t.Errorf("Page() of a page without metadata = %q, want no front matter", got)
+	}
+}
+
+func TestStandardRewriteLinksInLanguageDirectory(t *testing.T) {
+	f, _ := Get(Standard)
+	urlToFile := map[string]string{
+		"https://example.com/en/guide": "en/guide.md",
+		"https://example.com/en/intro": "en/intro.md",
+	}
+
+	markdown := "[intro](/en/intro) ![logo](assets/logo.png) [data](files/data.csv)"
+	want := "[intro](intro.md) ![logo](../assets/logo.png) [data](../files/data.csv)"
+	if got := f.RewriteLinks(markdown, "https://example.com/en/guide", urlToFile); got != want {
+		t.Errorf("RewriteLinks() = %q, want %q", got, want)
+	}
+}
+
+func TestLanguageFilename(t *testing.T) {
+	tests := []struct {
+		flavor   string
+		filename string
+		want     string
+	}{
+		{flavor: Standard, filename: "guide.md", want: "en/guide.md"},
+		{flavor: Obsidian, filename: "docs-intro.md", want: "en/docs-intro.md"},
+		{flavor: Hugo, filename: "content/docs/intro.md", want: "content/en/docs/intro.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flavor, func(t *testing.T) {
+			f, _ := Get(tt.flavor)
+			if got := LanguageFilename(f, tt.filename, "en"); got != tt.want {
+				t.Errorf("LanguageFilename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObsidianPage(t *testing.T) {
+	f, _ := Get(Obsidian)
+	fetchedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	data := render.NewData("https://docs.example.com/Guides/setup", `Setup "Guide"`, "Guides-setup.md", "Body", fetchedAt)
+
+	want := "---\n" +
+		"title: \"Setup \\\"Guide\\\"\"\n" +
+		"aliases:\n" +
+		"  - \"Setup \\\"Guide\\\"\"\n" +
+		"tags:\n" +
+		"  - docs-example-com\n" +
+		"  - guides\n" +
+		"source: \"https://docs.example.com/Guides/setup\"\n" +
+		"created: 2024-05-01T10:00:00Z\n" +
+		"---\n\nBody"
+
+	if got := f.Page(data); got != want {
+		t.Errorf("Page() = %q, want %q", got, want)
+	}
+	if f.AssetsDir() != "attachments" {
+		t.Errorf("AssetsDir() = %q, want attachments", f.AssetsDir())
+	}
+}
+
+func TestObsidianPageMetadata(t *testing.T) {
+	f, _ := Get(Obsidian)
+	data := render.NewData("https://example.com/post", "Post", "post.md", "Body", time.Time{})
+	data.Metadata = metadata.Metadata{
+		Description:   "Summary",
+		Author:        "Jane Doe",
+		Image:         "https://example.com/cover.png",
+		PublishedTime: "2024-03-01T08:00:00Z",
+	}
+
+	want := "description: \"Summary\"\nauthor: \"Jane Doe\"\nimage: \"https://example.com/cover.png\"\npublished: \"2024-03-01T08:00:00Z\"\n---\n\nBody"
+	if got := f.Page(data); !strings.HasSuffix(got, want) {
+		t.Errorf("Page() = %q, want suffix %q", got, want)
+	}
+}
+
+func TestObsidianPageTags(t *testing.T) {
+	f, _ := Get(Obsidian)
+	data := render.NewData("https://example.com/blog/post", "Post", "blog-post.md", "Body", time.Time{})
+	data.Tags = []string{"Blog", "Release Notes"}
+
+	want := "tags:\n  - example-com\n  - blog\n  - release-notes\n"
+	if got := f.Page(data); !strings.Contains(got, want) {
+		t.Errorf("Page() = %q, want it to contain %q", got, want)
+	}
+}
+
+func TestObsidianRewriteLinks(t *testing.T) {
+	f, _ := Get(Obsidian)
+	urlToFile := map[string]string{
+		"https://example.com/guide": "guide.md",
+	}
+
+	markdown := "See the [guide](/guide), [guide](https://example.com/guide#install), ![logo](/guide) and [elsewhere](https://other.com)."
+	want := "See the [[guide]], [[guide]], ![logo](/guide) and [elsewhere](https://other.com)."
+
+	if got := f.RewriteLinks(markdown, "https://example.com/", urlToFile); got != want {
+		t.Errorf("RewriteLinks() = %q, want %q", got, want)
+	}
+}
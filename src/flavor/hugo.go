@@ -0,0 +1,191 @@
+package flavor
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+const (
+	// hugoContentDir is the directory holding Hugo pages
+	hugoContentDir = "content"
+	// hugoSectionIndex is the file name of Hugo section pages
+	hugoSectionIndex = "_index.md"
+	// hugoStaticDir is served by Hugo at the site root
+	hugoStaticDir = "static"
+)
+
+var (
+	hugoStaticRefPattern = regexp.MustCompile(`\]\(` + hugoStaticDir + `/`)
+	markdownLinkPattern  = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+)
+
+// hugoFlavor writes a content/ tree with _index.md section pages and Hugo front matter
+type hugoFlavor struct{}
+
+func (hugoFlavor) Name() string { return Hugo }
+
+func (hugoFlavor) AssetsDir() string { return hugoStaticDir + "/" + converter.DefaultAssetsDir }
+
+// Filename maps the URL path to content/<path>.md; URLs ending with a slash become section _index.md files
+func (hugoFlavor) Filename(pageURL string) string {
+	parsedURL, err := url.Parse(pageURL)
+	if err != nil {
+		return path.Join(hugoContentDir, hugoSectionIndex)
+	}
+
+	isSection := parsedURL.Path == "" || strings.HasSuffix(parsedURL.Path, "/")
+
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(parsedURL.Path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		segments = append(segments, converter.SanitizeSegment(segment))
+	}
+
+	if len(segments) > 0 && !isSection {
+		last := segments[len(segments)-1]
+		last = strings.TrimSuffix(last, path.Ext(last))
+		if parsedURL.RawQuery != "" {
+			last = converter.SanitizeSegment(last + "-" + parsedURL.RawQuery)
+		}
+		segments[len(segments)-1] = last + ".md"
+		return path.Join(append([]string{hugoContentDir}, segments...)...)
+	}
+
+	if parsedURL.RawQuery != "" {
+		segments = append(segments, converter.SanitizeSegment(parsedURL.RawQuery))
+	}
+
+	return path.Join(append(append([]string{hugoContentDir}, segments...), hugoSectionIndex)...)
+}
+
+// LanguageFilename places language directories inside content/, as used by Hugo multilingual sites
+func (hugoFlavor) LanguageFilename(filename, language string) string {
+	return path.Join(hugoContentDir, language, strings.TrimPrefix(filename, hugoContentDir+"/"))
+}
+
+func (hugoFlavor) Page(data render.Data) string {
+	var builder strings.Builder
+
+	builder.WriteString("---\n")
+	builder.WriteString("title: " + strconv.Quote(data.Title) + "\n")
+	if !data.FetchedAt.IsZero() {
+		builder.WriteString("date: " + data.FetchedAt.UTC().Format(time.RFC3339) + "\n")
+	}
+	if path.Base(data.File) != hugoSectionIndex {
+		builder.WriteString("slug: " + strconv.Quote(strings.TrimSuffix(path.Base(data.File), ".md")) + "\n")
+	}
+	builder.WriteString("draft: false\n")
+	writeTags(&builder, data.Tags)
+	writeSummary(&builder, data.Summary)
+	writeHugoMetadata(&builder, data)
+	writeCanonical(&builder, data)
+	builder.WriteString("---\n\n")
+	builder.WriteString(data.Markdown)
+
+	return builder.String()
+}
+
+// writeHugoMetadata maps page metadata to the front matter fields known to Hugo
+func writeHugoMetadata(builder *strings.Builder, data render.Data) {
+	meta := data.Metadata
+	if meta.Description != "" {
+		builder.WriteString("description: " + strconv.Quote(meta.Description) + "\n")
+	}
+	if meta.Author != "" {
+		builder.WriteString("author: " + strconv.Quote(meta.Author) + "\n")
+	}
+	if meta.Image != "" {
+		builder.WriteString("images:\n  - " + strconv.Quote(meta.Image) + "\n")
+	}
+	if meta.PublishedTime != "" {
+		builder.WriteString("publishDate: " + strconv.Quote(meta.PublishedTime) + "\n")
+	}
+	if meta.ModifiedTime != "" {
+		builder.WriteString("lastmod: " + strconv.Quote(meta.ModifiedTime) + "\n")
+	}
+}
+
+// RewriteLinks points links to crawled pages at their Hugo permalinks and assets at the static root
+func (hugoFlavor) RewriteLinks(markdown, pageURL string, urlToFile map[string]string) string {
+	markdown = converter.ConvertLinksToLocal(markdown, pageURL, urlToFile)
+
+	markdown = markdownLinkPattern.ReplaceAllStringFunc(markdown, func(match string) string {
+		parts := markdownLinkPattern.FindStringSubmatch(match)
+		target, fragment, _ := strings.Cut(parts[2], "#")
+		if !strings.HasPrefix(target, hugoContentDir+"/") || !strings.HasSuffix(target, ".md") {
+			return match
+		}
+
+		link := hugoPermalink(target)
+		if fragment != "" {
+			link += "#" + fragment
+		}
+		return "[" + parts[1] + "](" + link + ")"
+	})
+
+	return hugoStaticRefPattern.ReplaceAllString(markdown, "](/")
+}
+
+// SectionIndexes creates _index.md files for directories that contain pages but no section page
+func (hugoFlavor) SectionIndexes(files []string) map[string]string {
+	existing := make(map[string]bool, len(files))
+	for _, file := range files {
+		existing[file] = true
+	}
+
+	indexes := make(map[string]string)
+	for _, file := range files {
+		if !strings.HasPrefix(file, hugoContentDir+"/") {
+			continue
+		}
+
+		for dir := path.Dir(file); dir != hugoContentDir && dir != "."; dir = path.Dir(dir) {
+			index := path.Join(dir, hugoSectionIndex)
+			if existing[index] {
+				continue
+			}
+			if _, exists := indexes[index]; exists {
+				continue
+			}
+			indexes[index] = "---\ntitle: " + strconv.Quote(sectionTitle(path.Base(dir))) + "\ndraft: false\n---\n"
+		}
+	}
+
+	return indexes
+}
+
+// hugoPermalink returns the default Hugo URL of a content file
+func hugoPermalink(file string) string {
+	rel := strings.TrimPrefix(file, hugoContentDir+"/")
+	if path.Base(rel) == hugoSectionIndex {
+		dir := path.Dir(rel)
+		if dir == "." {
+			return "/"
+		}
+		return "/" + dir + "/"
+	}
+
+	return "/" + strings.TrimSuffix(rel, ".md") + "/"
+}
+
+// sectionTitle turns a directory name into a readable title
+func sectionTitle(dir string) string {
+	words := strings.FieldsFunc(dir, func(r rune) bool { return r == '-' || r == '_' })
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = strings.ToUpper(string(runes[0])) + string(runes[1:])
+	}
+	if len(words) == 0 {
+		return dir
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,113 @@
+package flavor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+func TestHugoFilename(t *testing.T) {
+	f, _ := Get(Hugo)
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://example.com", want: "content/_index.md"},
+		{url: "https://example.com/", want: "content/_index.md"},
+		{url: "https://example.com/docs/", want: "content/docs/_index.md"},
+		{url: "https://example.com/docs/guide/install", want: "content/docs/guide/install.md"},
+		{url: "https://example.com/about.html", want: "content/about.md"},
+		{url: "https://example.com/search?q=go", want: "content/search-q-go.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := f.Filename(tt.url); got != tt.want {
+				t.Errorf("Filename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHugoPage(t *testing.T) {
+	f, _ := Get(Hugo)
+	fetchedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	page := f.Page(render.NewData("https://example.com/docs/install", "Install", "content/docs/install.md", "Body", fetchedAt))
+	want := "---\ntitle: \"Install\"\ndate: 2024-05-01T10:00:00Z\nslug: \"install\"\ndraft: false\n---\n\nBody"
+	if page != want {
+		t.Errorf("Page() = %q, want %q", page, want)
+	}
+
+	section := f.Page(render.NewData("https://example.com/docs/", "Docs", "content/docs/_index.md", "Body", fetchedAt))
+	want = "---\ntitle: \"Docs\"\ndate: 2024-05-01T10:00:00Z\ndraft: false\n---\n\nBody"
+	if section != want {
+		t.Errorf("Page() for section = %q, want %q", section, want)
+	}
+
+	tagged := render.NewData("https://example.com/blog/post", "Post", "content/blog/post.md", "Body", time.Time{})
+	tagged.Tags = []string{"blog"}
+	want = "---\ntitle: \"Post\"\nslug: \"post\"\ndraft: false\ntags:\n  - \"blog\"\n---\n\nBody"
+	if got := f.Page(tagged); got != want {
+		t.Errorf("Page() with tags = %q, want %q", got, want)
+	}
+
+	tagged.Summary = "Release notes for the new version."
+	want = "---\ntitle: \"Post\"\nslug: \"post\"\ndraft: false\ntags:\n  - \"blog\"\nsummary: \"Release notes for the new version.\"\n---\n\nBody"
+	if got := f.Page(tagged); got != want {
+		t.Errorf("Page() with summary = %q, want %q", got, want)
+	}
+
+	data := render.NewData("https://example.com/docs/install", "Install", "content/docs/install.md", "Body", time.Time{})
+	data.Canonical = "https://example.com/docs/install"
+	data.Alternates = map[string]string{"it": "https://example.com/it/docs/install", "de": "https://example.com/de/docs/install"}
+	want = "---\ntitle: \"Install\"\nslug: \"install\"\ndraft: false\n" +
+		"canonical: \"https://example.com/docs/install\"\n" +
+		"alternates:\n  \"de\": \"https://example.com/de/docs/install\"\n  \"it\": \"https://example.com/it/docs/install\"\n" +
+		"---\n\nBody"
+	if got := f.Page(data); got != want {
+		t.Errorf("Page() with alternates = %q, want %q", got, want)
+	}
+}
+
+func TestHugoRewriteLinks(t *testing.T) {
+	f, _ := Get(Hugo)
+	urlToFile := map[string]string{
+		"https://example.com":              "content/_index.md",
+		"https://example.com/docs":         "content/docs/_index.md",
+		"https://example.com/docs/install": "content/docs/install.md",
+	}
+
+	markdown := "[Home](/) [Docs](/docs/) [Install](/docs/install#linux) ![Logo](static/assets/logo.png) [Ext](https://other.com)"
+	want := "[Home](/) [Docs](/docs/) [Install](/docs/install/#linux) ![Logo](/assets/logo.png) [Ext](https://other.com)"
+
+	if got := f.RewriteLinks(markdown, "https://example.com/docs/", urlToFile); got != want {
+		t.Errorf("RewriteLinks() = %q, want %q", got, want)
+	}
+}
+
+func TestHugoSectionIndexes(t *testing.T) {
+	f, _ := Get(Hugo)
+	indexer, ok := f.(SectionIndexer)
+	if !ok {
+		t.Fatal("hugo flavor does not implement SectionIndexer")
+	}
+
+	got := indexer.SectionIndexes([]string{
+		"content/_index.md",
+		"content/docs/_index.md",
+		"content/docs/getting-started/install.md",
+		"content/blog/post.md",
+	})
+
+	want := map[string]string{
+		"content/docs/getting-started/_index.md": "---\ntitle: \"Getting Started\"\ndraft: false\n---\n",
+		"content/blog/_index.md":                 "---\ntitle: \"Blog\"\ndraft: false\n---\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SectionIndexes() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package flavor
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+// MdBookSourceDir is the source folder of an mdBook book, holding the pages, their assets, and SUMMARY.md
+const MdBookSourceDir = "src"
+
+// mdbookAssetRefPattern matches references to extracted assets and downloaded files, which are relative to the output root
+var mdbookAssetRefPattern = regexp.MustCompile(`\]\((` + MdBookSourceDir + `/(?:` + converter.DefaultAssetsDir + `|` + converter.DefaultFilesDir + `)/[^)\s]+)`)
+
+// mdbookFlavor is the layout of the mdbook output format: the pages of the standard flavor and their assets under
+// the book source folder, without front matter, which mdBook would render as text
+type mdbookFlavor struct{}
+
+// MdBook returns the layout of the mdbook output format, which is selected by the format rather than by name
+func MdBook() Flavor { return mdbookFlavor{} }
+
+func (mdbookFlavor) Name() string { return "mdbook" }
+
+func (mdbookFlavor) AssetsDir() string { return path.Join(MdBookSourceDir, converter.DefaultAssetsDir) }
+
+func (mdbookFlavor) Filename(pageURL string) string {
+	return path.Join(MdBookSourceDir, converter.GenerateFilename(pageURL))
+}
+
+// LanguageFilename places language directories inside the book source folder
+func (mdbookFlavor) LanguageFilename(filename, language string) string {
+	return path.Join(MdBookSourceDir, language, strings.TrimPrefix(filename, MdBookSourceDir+"/"))
+}
+
+func (mdbookFlavor) Page(data render.Data) string {
+	return render.DefaultHeader(data.Title, data.URL) + data.Markdown
+}
+
+// RewriteLinks writes links to pages and assets relative to the page file
+func (mdbookFlavor) RewriteLinks(markdown, pageURL string, urlToFile map[string]string) string {
+	pageFile := urlToFile[strings.TrimSuffix(pageURL, "/")]
+	markdown = converter.ConvertLinksToLocalFrom(markdown, pageURL, pageFile, urlToFile)
+
+	return mdbookAssetRefPattern.ReplaceAllStringFunc(markdown, func(match string) string {
+		return "](" + converter.RelativePath(pageFile, strings.TrimPrefix(match, "]("))
+	})
+}
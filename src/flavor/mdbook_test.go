@@ -0,0 +1,40 @@
+package flavor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+func TestMdBookPage(t *testing.T) {
+	f := MdBook()
+	if got := f.Filename("https://example.com/docs/intro"); got != "src/docs-intro.md" {
+		t.Errorf("Filename() = %q, want %q", got, "src/docs-intro.md")
+	}
+	if got := LanguageFilename(f, "src/docs-intro.md", "en"); got != "src/en/docs-intro.md" {
+		t.Errorf("LanguageFilename() = %q, want %q", got, "src/en/docs-intro.md")
+	}
+
+	data := render.NewData("https://example.com/docs/intro", "Intro", "src/docs-intro.md", "Body", time.Time{})
+	data.Tags = []string{"docs"}
+	data.Summary = "Getting started with the tool."
+	want := "# Intro\n\nURL: https://example.com/docs/intro\n\n---\n\nBody"
+	if got := f.Page(data); got != want {
+		t.Errorf("Page() = %q, want %q", got, want)
+	}
+}
+
+func TestMdBookRewriteLinks(t *testing.T) {
+	f := MdBook()
+	urlToFile := map[string]string{
+		"https://example.com/en/guide": "src/en/guide.md",
+		"https://example.com/faq":      "src/faq.md",
+	}
+
+	markdown := "[FAQ](https://example.com/faq) ![logo](src/assets/logo.png) [data](src/files/data.csv)"
+	want := "[FAQ](../faq.md) ![logo](../assets/logo.png) [data](../files/data.csv)"
+	if got := f.RewriteLinks(markdown, "https://example.com/en/guide", urlToFile); got != want {
+		t.Errorf("RewriteLinks() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package flavor
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/converter"
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+// pandocFlavor writes a Pandoc YAML metadata block instead of the title header, so that pandoc sets the
+// title, author, date, language, and abstract of documents converted to formats such as docx or LaTeX
+type pandocFlavor struct{}
+
+func (pandocFlavor) Name() string { return Pandoc }
+
+func (pandocFlavor) AssetsDir() string { return converter.DefaultAssetsDir }
+
+func (pandocFlavor) Filename(pageURL string) string { return converter.GenerateFilename(pageURL) }
+
+func (pandocFlavor) Page(data render.Data) string {
+	var builder strings.Builder
+
+	builder.WriteString("---\n")
+	builder.WriteString("title: " + strconv.Quote(data.Title) + "\n")
+	if data.Metadata.Author != "" {
+		builder.WriteString("author: " + strconv.Quote(data.Metadata.Author) + "\n")
+	}
+	if date := pandocDate(data); date != "" {
+		builder.WriteString("date: " + strconv.Quote(date) + "\n")
+	}
+	if data.Lang != "" {
+		builder.WriteString("lang: " + strconv.Quote(data.Lang) + "\n")
+	}
+
+	abstract := data.Summary
+	if abstract == "" {
+		abstract = data.Metadata.Description
+	}
+	if abstract != "" {
+		builder.WriteString("abstract: " + strconv.Quote(abstract) + "\n")
+	}
+	if len(data.Tags) > 0 {
+		builder.WriteString("keywords:\n")
+		for _, tag := range data.Tags {
+			builder.WriteString("  - " + strconv.Quote(tag) + "\n")
+		}
+	}
+	builder.WriteString("url: " + strconv.Quote(data.URL) + "\n")
+	builder.WriteString("---\n\n")
+	builder.WriteString(data.Markdown)
+
+	return builder.String()
+}
+
+// pandocDate returns the publication date of a page, or the date it was fetched, as YYYY-MM-DD
+func pandocDate(data render.Data) string {
+	if published := data.Metadata.PublishedTime; published != "" {
+		for _, layout := range []string{time.RFC3339, time.DateOnly} {
+			if date, err := time.Parse(layout, published); err == nil {
+				return date.Format(time.DateOnly)
+			}
+		}
+		return published
+	}
+	if !data.FetchedAt.IsZero() {
+		return data.FetchedAt.UTC().Format(time.DateOnly)
+	}
+	return ""
+}
+
+// RewriteLinks writes relative Markdown links like the standard flavor
+func (pandocFlavor) RewriteLinks(markdown, pageURL string, urlToFile map[string]string) string {
+	return standardFlavor{}.RewriteLinks(markdown, pageURL, urlToFile)
+}
@@ -0,0 +1,49 @@
+package flavor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/render"
+)
+
+func TestPandocPage(t *testing.T) {
+	f, _ := Get(Pandoc)
+	fetchedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	page := f.Page(render.NewData("https://example.com/docs/install", "Install", "docs/install.md", "Body", fetchedAt))
+	want := "---\ntitle: \"Install\"\ndate: \"2024-05-01\"\nurl: \"https://example.com/docs/install\"\n---\n\nBody"
+	if page != want {
+		t.Errorf("Page() = %q, want %q", page, want)
+	}
+
+	data := render.NewData("https://example.com/blog/post", "Post", "blog/post.md", "Body", fetchedAt)
+	data.Lang = "en"
+	data.Tags = []string{"blog"}
+	data.Metadata.Author = "Jane Doe"
+	data.Metadata.Description = "A post."
+	data.Metadata.PublishedTime = "2023-12-24T08:00:00+01:00"
+	want = "---\ntitle: \"Post\"\nauthor: \"Jane Doe\"\ndate: \"2023-12-24\"\nlang: \"en\"\nabstract: \"A post.\"\n" +
+		"keywords:\n  - \"blog\"\nurl: \"https://example.com/blog/post\"\n---\n\nBody"
+	if got := f.Page(data); got != want {
+		t.Errorf("Page() with metadata = %q, want %q", got, want)
+	}
+
+	data.Summary = "The summary."
+	want = strings.Replace(want, `abstract: "A post."`, `abstract: "The summary."`, 1)
+	if got := f.Page(data); got != want {
+		t.Errorf("Page() with summary = %q, want %q", got, want)
+	}
+}
+
+func TestPandocRewriteLinks(t *testing.T) {
+	f, _ := Get(Pandoc)
+	urlToFile := map[string]string{"https://example.com/docs/install": "docs/install.md", "https://example.com/docs/faq": "docs/faq.md"}
+
+	got := f.RewriteLinks("See [FAQ](https://example.com/docs/faq) and ![logo](assets/logo.png).", "https://example.com/docs/install", urlToFile)
+	want := "See [FAQ](faq.md) and ![logo](../assets/logo.png)."
+	if got != want {
+		t.Errorf("RewriteLinks() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+// Package frontier provides crawl frontiers shared by several crawldown processes
+package frontier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+)
+
+// Defaults of RedisOptions
+const (
+	DefaultPollInterval = 250 * time.Millisecond
+	DefaultIdleTimeout  = 2 * time.Minute
+)
+
+// pushScript queues links not seen before, scored by descending priority and then by queue order
+var pushScript = redis.NewScript(`
+for i = 1, #ARGV, 2 do
+	local link = cjson.decode(ARGV[i])
+	if redis.call('SADD', KEYS[2], link.url) == 1 then
+		local sequence = redis.call('INCR', KEYS[3])
+		redis.call('ZADD', KEYS[1], -tonumber(ARGV[i + 1]) * 4294967296 + sequence, ARGV[i])
+	end
+end
+return 0
+`)
+
+// popScript pops the next link and counts it as active, or returns the number of active links when none is queued
+var popScript = redis.NewScript(`
+local popped = redis.call('ZPOPMIN', KEYS[1])
+if #popped > 0 then
+	redis.call('INCR', KEYS[2])
+	return {popped[1], ''}
+end
+return {'', redis.call('GET', KEYS[2]) or '0'}
+`)
+
+// RedisOptions configures a Redis frontier
+type RedisOptions struct {
+	Key          string        // Prefix of the Redis keys of the crawl, shared by its workers
+	PollInterval time.Duration // Wait between polls while other workers are still handling pages, DefaultPollInterval when 0
+	IdleTimeout  time.Duration // Time without queued links after which a worker stops although other workers look busy, DefaultIdleTimeout when 0
+}
+
+// Redis is a breadth-first frontier stored in Redis and shared by the workers of a crawl.
+// Every URL is queued once across workers, and workers stop when no link is queued and no worker is handling one.
+type Redis struct {
+	client *redis.Client
+	opts   RedisOptions
+	keys   []string // Queue, seen URLs, queue sequence, and active links
+}
+
+// NewRedis connects to the Redis server of a redis:// URL
+func NewRedis(redisURL string, opts RedisOptions) (*Redis, error) {
+	clientOptions, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if opts.Key == "" {
+		return nil, errors.New("redis key must not be empty")
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+
+	client := redis.NewClient(clientOptions)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &Redis{
+		client: client,
+		opts:   opts,
+		keys:   []string{opts.Key + ":queue", opts.Key + ":seen", opts.Key + ":sequence", opts.Key + ":active"},
+	}, nil
+}
+
+// Push queues the links that no worker has queued yet
+func (r *Redis) Push(links []crawler.QueuedLink) error {
+	args := make([]any, 0, 2*len(links))
+	for _, link := range links {
+		data, err := json.Marshal(link)
+		if err != nil {
+			return fmt.Errorf("failed to encode link: %w", err)
+		}
+		args = append(args, string(data), link.Priority)
+	}
+
+	if err := pushScript.Run(context.Background(), r.client, r.keys[:3], args...).Err(); err != nil {
+		return fmt.Errorf("failed to queue links: %w", err)
+	}
+	return nil
+}
+
+// Pop returns the next queued link, waiting while other workers handle pages that may queue more links
+func (r *Redis) Pop() (crawler.QueuedLink, bool, error) {
+	idleSince := time.Now()
+	for {
+		result, err := popScript.Run(context.Background(), r.client, []string{r.keys[0], r.keys[3]}).StringSlice()
+		if err != nil {
+			return crawler.QueuedLink{}, false, fmt.Errorf("failed to pop link: %w", err)
+		}
+
+		if result[0] != "" {
+			var link crawler.QueuedLink
+			if err := json.Unmarshal([]byte(result[0]), &link); err != nil {
+				return crawler.QueuedLink{}, false, fmt.Errorf("failed to decode link: %w", err)
+			}
+			return link, true, nil
+		}
+
+		active, err := strconv.Atoi(result[1])
+		if err != nil || active <= 0 || time.Since(idleSince) >= r.opts.IdleTimeout {
+			return crawler.QueuedLink{}, false, nil
+		}
+		time.Sleep(r.opts.PollInterval)
+	}
+}
+
+// Done stops counting a popped link as active
+func (r *Redis) Done(crawler.QueuedLink) error {
+	if err := r.client.Decr(context.Background(), r.keys[3]).Err(); err != nil {
+		return fmt.Errorf("failed to update active links: %w", err)
+	}
+	return nil
+}
+
+// Reset deletes the keys of the crawl, so that its URLs can be crawled again
+func (r *Redis) Reset() error {
+	if err := r.client.Del(context.Background(), r.keys...).Err(); err != nil {
+		return fmt.Errorf("failed to reset frontier: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection to Redis
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
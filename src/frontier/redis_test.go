@@ -0,0 +1,148 @@
+package frontier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/sandrolain/crawldown/src/crawler"
+)
+
+func newTestRedis(t *testing.T, server *miniredis.Miniredis) *Redis {
+	t.Helper()
+	r, err := NewRedis("redis://"+server.Addr(), RedisOptions{Key: "crawl", PollInterval: time.Millisecond, IdleTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewRedis() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func TestRedisOrder(t *testing.T) {
+	r := newTestRedis(t, miniredis.RunT(t))
+
+	links := []crawler.QueuedLink{
+		{URL: "https://example.com/a", Depth: 2},
+		{URL: "https://example.com/docs", Depth: 2, Priority: 5},
+		{URL: "https://example.com/b", Depth: 2},
+		{URL: "https://example.com/a", Depth: 3},
+	}
+	if err := r.Push(links); err != nil {
+		t.Fatalf("Push() unexpected error: %v", err)
+	}
+
+	var got []crawler.QueuedLink
+	for {
+		link, ok, err := r.Pop()
+		if err != nil {
+			t.Fatalf("Pop() unexpected error: %v", err)
+		}
+		if !ok {
+			break
+		}
+		got = append(got, link)
+		if err := r.Done(link); err != nil {
+			t.Fatalf("Done() unexpected error: %v", err)
+		}
+	}
+
+	want := []crawler.QueuedLink{
+		{URL: "https://example.com/docs", Depth: 2},
+		{URL: "https://example.com/a", Depth: 2},
+		{URL: "https://example.com/b", Depth: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("popped %+v, want %+v", got, want)
+	}
+}
+
+func TestRedisWaitsForActiveWorkers(t *testing.T) {
+	server := miniredis.RunT(t)
+	first := newTestRedis(t, server)
+	second := newTestRedis(t, server)
+
+	if err := first.Push([]crawler.QueuedLink{{URL: "https://example.com/", Depth: 1}}); err != nil {
+		t.Fatalf("Push() unexpected error: %v", err)
+	}
+	start, _, err := first.Pop()
+	if err != nil {
+		t.Fatalf("Pop() unexpected error: %v", err)
+	}
+
+	// The first worker queues a link while the second one waits for it
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = first.Push([]crawler.QueuedLink{{URL: "https://example.com/next", Depth: 2}})
+		_ = first.Done(start)
+	}()
+
+	link, ok, err := second.Pop()
+	if err != nil || !ok || link.URL != "https://example.com/next" {
+		t.Errorf("Pop() = %+v, %v, %v, want the link queued by the other worker", link, ok, err)
+	}
+}
+
+func TestRedisSharedCrawl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body := ""
+		if r.URL.Path == "/" {
+			for i := 1; i <= 6; i++ {
+				body += `<a href="/page` + strconv.Itoa(i) + `">Page</a> `
+			}
+		}
+		_, _ = w.Write([]byte(`<html><body><main>Content ` + body + `</main></body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	server := miniredis.RunT(t)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var urls []string
+	for worker := 0; worker < 2; worker++ {
+		shared := newTestRedis(t, server)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := crawler.NewCrawler(srv.URL+"/", crawler.Options{MaxDepth: 2, Frontier: shared, Verbosity: crawler.VerbosityQuiet})
+			if err != nil {
+				t.Errorf("NewCrawler() unexpected error: %v", err)
+				return
+			}
+			if err := c.Start(); err != nil {
+				t.Errorf("Start() unexpected error: %v", err)
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			for _, page := range c.GetPages() {
+				urls = append(urls, page.URL)
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Strings(urls)
+	want := []string{srv.URL + "/"}
+	for i := 1; i <= 6; i++ {
+		want = append(want, srv.URL+"/page"+strconv.Itoa(i))
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("workers crawled %v, want each page once: %v", urls, want)
+	}
+}
+
+func TestNewRedisRejectsInvalidURL(t *testing.T) {
+	if _, err := NewRedis("http://localhost", RedisOptions{Key: "crawl"}); err == nil {
+		t.Error("NewRedis() expected an error for a non-redis URL")
+	}
+}
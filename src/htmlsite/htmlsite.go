@@ -0,0 +1,73 @@
+package htmlsite
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// Extension is the file extension used for rendered pages
+const Extension = ".html"
+
+var (
+	// Heading attributes keep the {#id} anchors the converter writes when it writes no raw HTML,
+	// and other headings get ids from their text like on GitHub
+	markdownRenderer = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAttribute(), parser.WithAutoHeadingID()),
+	)
+	pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 48rem; margin: 2rem auto; padding: 0 1rem; font-family: system-ui, sans-serif; line-height: 1.6; color: #222; }
+pre { overflow-x: auto; padding: 1rem; background: #f5f5f5; }
+code { font-family: ui-monospace, monospace; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.25rem 0.5rem; }
+img { max-width: 100%; }
+</style>
+</head>
+<body>
+{{.Body}}
+</body>
+</html>
+`))
+)
+
+// Render converts a page's Markdown into a standalone HTML document.
+// Raw HTML embedded in the Markdown is omitted by the renderer, so pages must be converted without raw HTML.
+func Render(title, markdown string) (string, error) {
+	var body bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(markdown), &body); err != nil {
+		return "", fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	var out bytes.Buffer
+	err := pageTemplate.Execute(&out, struct {
+		Title string
+		Body  template.HTML
+	}{
+		Title: title,
+		//nolint:gosec // The body is produced by the Markdown renderer, which does not pass raw HTML through.
+		Body: template.HTML(body.String()),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render page template: %w", err)
+	}
+
+	return out.String(), nil
+}
+
+// Filename converts a Markdown filename into the corresponding HTML filename
+func Filename(markdownFilename string) string {
+	return strings.TrimSuffix(markdownFilename, ".md") + Extension
+}
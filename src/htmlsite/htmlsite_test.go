@@ -0,0 +1,59 @@
+package htmlsite
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	markdown := "# Guide\n\nSee [install](install.html#setup).\n\n| A | B |\n|---|---|\n| 1 | 2 |\n\n<script>alert(1)</script>\n"
+
+	result, err := Render("Guide <Docs>", markdown)
+	if err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"<title>Guide &lt;Docs&gt;</title>",
+		`<h1 id="guide">Guide</h1>`,
+		`<a href="install.html#setup">install</a>`,
+		"<table>",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Render() result does not contain %q\nGot: %s", want, result)
+		}
+	}
+
+	if strings.Contains(result, "<script>") {
+		t.Errorf("Render() should not pass raw HTML through\nGot: %s", result)
+	}
+}
+
+func TestRenderHeadingAttributes(t *testing.T) {
+	result, err := Render("Guide", "## Setup {#install-setup}\n")
+	if err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	if want := `<h2 id="install-setup">Setup</h2>`; !strings.Contains(result, want) {
+		t.Errorf("Render() result does not contain %q\nGot: %s", want, result)
+	}
+}
+
+func TestFilename(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "index.md", want: "index.html"},
+		{input: "docs-guide.md", want: "docs-guide.html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Filename(tt.input); got != tt.want {
+				t.Errorf("Filename(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
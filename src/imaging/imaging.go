@@ -0,0 +1,240 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Registers the WebP decoder
+)
+
+// Output formats of converted images
+const (
+	FormatPNG  = "png"
+	FormatJPEG = "jpeg"
+)
+
+// ErrUnsupportedFormat is returned when an image should be converted but its format cannot be decoded
+var ErrUnsupportedFormat = errors.New("image format cannot be decoded")
+
+// jpegQuality is the quality of re-encoded JPEG images
+const jpegQuality = 90
+
+// Options configures the processing of downloaded images
+type Options struct {
+	Format        string // Format of converted WebP and AVIF images: FormatPNG or FormatJPEG, empty to keep them
+	MaxDimension  int    // Maximum width and height in pixels, larger images are scaled down; 0 keeps the size
+	StripMetadata bool   // When true, EXIF, XMP, IPTC, and text metadata are removed from JPEG and PNG images
+}
+
+// IsZero reports whether the options leave images unchanged
+func (o Options) IsZero() bool {
+	return o.Format == "" && o.MaxDimension <= 0 && !o.StripMetadata
+}
+
+// ValidateFormat checks that an output format is known
+func ValidateFormat(format string) error {
+	switch format {
+	case "", FormatPNG, FormatJPEG:
+		return nil
+	default:
+		return fmt.Errorf("unknown image format %q (expected %s or %s)", format, FormatPNG, FormatJPEG)
+	}
+}
+
+// Process converts, scales down, and strips the metadata of an image as configured, returning the new data
+// and content type. Images in formats that cannot be decoded, such as SVG, and animated GIFs are returned
+// unchanged. AVIF images cannot be decoded either, so converting them fails with ErrUnsupportedFormat.
+func Process(data []byte, contentType string, opts Options) ([]byte, string, error) {
+	if opts.IsZero() {
+		return data, contentType, nil
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if opts.Format != "" && isAVIF(data) {
+			return nil, "", fmt.Errorf("failed to convert AVIF image to %s: %w", opts.Format, ErrUnsupportedFormat)
+		}
+		return data, contentType, nil
+	}
+
+	convert := opts.Format != "" && format == "webp"
+	resize := opts.MaxDimension > 0 && (config.Width > opts.MaxDimension || config.Height > opts.MaxDimension) && !isAnimated(data, format)
+
+	if !convert && !resize {
+		if opts.StripMetadata {
+			return stripMetadata(data, format), contentType, nil
+		}
+		return data, contentType, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %s image: %w", format, err)
+	}
+
+	if resize {
+		img = scaleDown(img, opts.MaxDimension)
+	}
+
+	target := format
+	if convert || format == "webp" {
+		// WebP images cannot be encoded, scaled ones are written as PNG unless a format was chosen
+		target = opts.Format
+		if target == "" {
+			target = FormatPNG
+		}
+	}
+
+	var buf bytes.Buffer
+	switch target {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+		contentType = "image/jpeg"
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+		contentType = "image/gif"
+	default:
+		err = png.Encode(&buf, img)
+		contentType = "image/png"
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode %s image: %w", target, err)
+	}
+
+	// Encoded images carry no metadata
+	return buf.Bytes(), contentType, nil
+}
+
+// isAVIF reports whether data is an AVIF image, an ISO media file whose ftyp box lists an AVIF brand
+func isAVIF(data []byte) bool {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	size := min(int(binary.BigEndian.Uint32(data[:4])), len(data))
+	// Major brand, minor version, then compatible brands
+	for i := 8; i+4 <= size; i += 4 {
+		if i == 12 {
+			continue
+		}
+		if brand := string(data[i : i+4]); brand == "avif" || brand == "avis" {
+			return true
+		}
+	}
+	return false
+}
+
+// isAnimated reports whether a GIF has more than one frame
+func isAnimated(data []byte, format string) bool {
+	if format != "gif" {
+		return false
+	}
+	animation, err := gif.DecodeAll(bytes.NewReader(data))
+	return err == nil && len(animation.Image) > 1
+}
+
+// scaleDown resizes an image to fit in a square of maxDimension pixels, keeping its aspect ratio
+func scaleDown(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width >= height {
+		height = max(1, height*maxDimension/width)
+		width = maxDimension
+	} else {
+		width = max(1, width*maxDimension/height)
+		height = maxDimension
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+	return scaled
+}
+
+// stripMetadata removes metadata from JPEG and PNG images without re-encoding them
+func stripMetadata(data []byte, format string) []byte {
+	switch format {
+	case "jpeg":
+		return stripJPEG(data)
+	case "png":
+		return stripPNG(data)
+	default:
+		return data
+	}
+}
+
+// jpegMetadataMarkers are the JPEG segments holding EXIF and XMP (APP1), IPTC (APP13), and comments
+var jpegMetadataMarkers = map[byte]bool{0xE1: true, 0xED: true, 0xFE: true}
+
+// stripJPEG removes the metadata segments before the image data of a JPEG file
+func stripJPEG(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return data
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// Fill byte before a marker
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			// Start of scan: the rest is image data
+			return append(out, data[i:]...)
+		}
+
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:i+4]))
+		if end > len(data) {
+			return data
+		}
+		if !jpegMetadataMarkers[marker] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return data
+}
+
+// pngMetadataChunks are the PNG chunks holding EXIF, text, and modification time metadata
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// stripPNG removes the metadata chunks of a PNG file
+func stripPNG(data []byte) []byte {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	i := len(pngSignature)
+	for i+8 <= len(data) {
+		// Length, type, data, and CRC
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:i+4]))
+		if end > len(data) || end < i {
+			return data
+		}
+		if !pngMetadataChunks[string(data[i+4:i+8])] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	if i != len(data) {
+		return data
+	}
+	return out
+}
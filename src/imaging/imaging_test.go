@@ -0,0 +1,170 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+)
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode() unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessConvertsWebP(t *testing.T) {
+	data, err := os.ReadFile("testdata/gopher.webp")
+	if err != nil {
+		t.Fatalf("failed to read test image: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantType   string
+		wantFormat string
+		wantWidth  int
+	}{
+		{name: "png", opts: Options{Format: FormatPNG}, wantType: "image/png", wantFormat: "png", wantWidth: 75},
+		{name: "jpeg", opts: Options{Format: FormatJPEG}, wantType: "image/jpeg", wantFormat: "jpeg", wantWidth: 75},
+		{name: "scaled without format", opts: Options{MaxDimension: 50}, wantType: "image/png", wantFormat: "png", wantWidth: 37},
+		{name: "kept", opts: Options{StripMetadata: true}, wantType: "image/webp", wantFormat: "webp", wantWidth: 75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, contentType, err := Process(data, "image/webp", tt.opts)
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if contentType != tt.wantType {
+				t.Errorf("Process() content type = %q, want %q", contentType, tt.wantType)
+			}
+
+			config, format, err := image.DecodeConfig(bytes.NewReader(out))
+			if err != nil {
+				t.Fatalf("Process() output cannot be decoded: %v", err)
+			}
+			if format != tt.wantFormat || config.Width != tt.wantWidth {
+				t.Errorf("Process() = %s %dpx wide, want %s %dpx wide", format, config.Width, tt.wantFormat, tt.wantWidth)
+			}
+		})
+	}
+}
+
+func TestProcessScalesDown(t *testing.T) {
+	out, contentType, err := Process(encodeJPEG(t, testImage(200, 100)), "image/jpeg", Options{MaxDimension: 80})
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("Process() output cannot be decoded: %v", err)
+	}
+	if contentType != "image/jpeg" || format != "jpeg" || config.Width != 80 || config.Height != 40 {
+		t.Errorf("Process() = %s (%s) %dx%d, want image/jpeg 80x40", contentType, format, config.Width, config.Height)
+	}
+}
+
+func TestProcessStripsMetadata(t *testing.T) {
+	exif := append([]byte{0xFF, 0xE1, 0x00, 0x0F}, []byte("Exif\x00\x00GPSDATA")...)
+	original := encodeJPEG(t, testImage(8, 8))
+	withExif := append(append(append([]byte{}, original[:2]...), exif...), original[2:]...)
+
+	text := append([]byte{0x00, 0x00, 0x00, 0x06}, []byte("tEXtAuthor\x00\x00\x00\x00")...)
+	pngData := encodePNG(t, testImage(8, 8))
+	// The text chunk is inserted after the signature and the IHDR chunk
+	withText := append(append(append([]byte{}, pngData[:33]...), text...), pngData[33:]...)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		want     []byte
+		metadata string
+	}{
+		{name: "jpeg", data: withExif, want: original, metadata: "GPSDATA"},
+		{name: "png", data: withText, want: pngData, metadata: "Author"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, err := Process(tt.data, "", Options{StripMetadata: true})
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+			if bytes.Contains(out, []byte(tt.metadata)) {
+				t.Errorf("Process() kept the %q metadata", tt.metadata)
+			}
+			if !bytes.Equal(out, tt.want) {
+				t.Errorf("Process() = %d bytes, want the %d bytes of the image without metadata", len(out), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestProcessKeepsUnknownFormats(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`)
+	out, contentType, err := Process(svg, "image/svg+xml", Options{Format: FormatPNG, MaxDimension: 10, StripMetadata: true})
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if !bytes.Equal(out, svg) || contentType != "image/svg+xml" {
+		t.Errorf("Process() = %q, %q, want the SVG unchanged", out, contentType)
+	}
+}
+
+func TestProcessAVIF(t *testing.T) {
+	// The ftyp box of an AVIF file, which is all that is read before the image is rejected
+	avif := []byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00avifmif1miaf")
+
+	if _, _, err := Process(avif, "image/avif", Options{Format: FormatPNG}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Process() converting AVIF error = %v, want ErrUnsupportedFormat", err)
+	}
+
+	out, contentType, err := Process(avif, "image/avif", Options{MaxDimension: 10, StripMetadata: true})
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if !bytes.Equal(out, avif) || contentType != "image/avif" {
+		t.Errorf("Process() = %q, %q, want the AVIF unchanged when no conversion is asked", out, contentType)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range []string{"", FormatPNG, FormatJPEG} {
+		if err := ValidateFormat(format); err != nil {
+			t.Errorf("ValidateFormat(%q) unexpected error: %v", format, err)
+		}
+	}
+	if err := ValidateFormat("avif"); err == nil {
+		t.Error("ValidateFormat(\"avif\") expected an error")
+	}
+}
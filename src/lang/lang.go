@@ -0,0 +1,172 @@
+package lang
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Undetermined is the BCP 47 code for pages whose language is unknown
+const Undetermined = "und"
+
+// minStopwordHits is the number of stopword matches needed before the detected language is trusted
+const minStopwordHits = 3
+
+// stopwords lists frequent words that distinguish Latin-script languages
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "as", "are", "this", "was", "on", "be", "by", "not", "you", "or"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "den", "von", "zu", "ein", "eine", "auf", "für", "sich", "dem", "des", "auch", "es", "sie"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "pour", "dans", "que", "qui", "pas", "sur", "du", "au", "avec", "ce", "sont", "il", "par"},
+	"es": {"el", "la", "los", "las", "y", "que", "de", "en", "es", "por", "con", "para", "una", "del", "se", "no", "al", "como", "más", "su"},
+	"it": {"il", "la", "di", "che", "e", "per", "un", "una", "del", "della", "non", "sono", "con", "è", "gli", "le", "nel", "si", "da", "anche"},
+	"pt": {"o", "a", "os", "as", "de", "que", "não", "em", "um", "uma", "para", "com", "é", "do", "da", "se", "por", "mais", "dos", "são"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "zijn", "met", "voor", "er", "die", "ook", "aan", "als", "maar", "bij"},
+}
+
+// stopwordIndex maps each stopword to the languages using it
+var stopwordIndex = buildStopwordIndex()
+
+func buildStopwordIndex() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}
+
+// scripts maps non-Latin scripts to the language detected for them, in order of precedence
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// Primary returns the lowercase primary subtag of a language tag such as "en" for "en-US", or "" if the tag is not valid
+func Primary(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	if len(tag) < 2 || len(tag) > 3 {
+		return ""
+	}
+	for _, r := range tag {
+		if r < 'a' || r > 'z' {
+			return ""
+		}
+	}
+
+	return tag
+}
+
+// Resolve returns the primary language of a page, preferring the declared tag and falling back to detection on its text
+func Resolve(declared, text string) string {
+	for _, tag := range strings.Split(declared, ",") {
+		if primary := Primary(tag); primary != "" && primary != Undetermined {
+			return primary
+		}
+	}
+
+	return Detect(text)
+}
+
+// Detect guesses the language of a text from its script and common words, returning "" when unsure
+func Detect(text string) string {
+	if language := detectScript(text); language != "" {
+		return language
+	}
+
+	hits := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, language := range stopwordIndex[word] {
+			hits[language]++
+		}
+	}
+
+	languages := make([]string, 0, len(hits))
+	for language := range hits {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if hits[languages[i]] != hits[languages[j]] {
+			return hits[languages[i]] > hits[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+
+	if len(languages) == 0 || hits[languages[0]] < minStopwordHits {
+		return ""
+	}
+	if len(languages) > 1 && hits[languages[0]] == hits[languages[1]] {
+		return ""
+	}
+
+	return languages[0]
+}
+
+// detectScript returns the language of the dominant non-Latin script, if letters of that script are the majority
+func detectScript(text string) string {
+	letters := 0
+	counts := make([]int, len(scripts))
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for i, script := range scripts {
+			if unicode.Is(script.table, r) {
+				counts[i]++
+				break
+			}
+		}
+	}
+
+	nonLatin := 0
+	for _, count := range counts {
+		nonLatin += count
+	}
+	if letters == 0 || nonLatin*2 <= letters {
+		return ""
+	}
+
+	// Japanese mixes kana with Han characters, so any kana wins over Han
+	for i, script := range scripts {
+		if counts[i] > 0 && script.language == "ja" {
+			return "ja"
+		}
+	}
+
+	best := 0
+	for i := range counts {
+		if counts[i] > counts[best] {
+			best = i
+		}
+	}
+
+	return scripts[best].language
+}
+
+// Matches reports whether the language is one of the wanted language tags, comparing primary subtags
+func Matches(language string, wanted []string) bool {
+	language = Primary(language)
+	for _, tag := range wanted {
+		if Primary(tag) == language {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package lang
+
+import "testing"
+
+func TestPrimary(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "en", want: "en"},
+		{tag: "en-US", want: "en"},
+		{tag: " pt_BR ", want: "pt"},
+		{tag: "zh-Hant-TW", want: "zh"},
+		{tag: "", want: ""},
+		{tag: "english", want: ""},
+		{tag: "e1", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := Primary(tt.tag); got != tt.want {
+				t.Errorf("Primary(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "english", text: "This is the guide to the installation of the tool and it is easy to follow.", want: "en"},
+		{name: "german", text: "Das ist die Anleitung für die Installation, und sie ist nicht schwer mit dem Werkzeug.", want: "de"},
+		{name: "french", text: "Les étapes pour installer le logiciel sont dans le guide et il est facile de les suivre avec la documentation.", want: "fr"},
+		{name: "spanish", text: "El proceso para instalar los programas es sencillo y los pasos están en la guía con las instrucciones del sistema.", want: "es"},
+		{name: "italian", text: "Il processo per installare il programma è semplice e non richiede che una guida della documentazione.", want: "it"},
+		{name: "japanese", text: "これはインストールの手順です。", want: "ja"},
+		{name: "chinese", text: "这是安装指南，请按照步骤操作。", want: "zh"},
+		{name: "russian", text: "Это руководство по установке программы.", want: "ru"},
+		{name: "too short", text: "Home", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.text); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	text := "This is the guide to the installation of the tool and it is easy to follow."
+
+	tests := []struct {
+		name     string
+		declared string
+		want     string
+	}{
+		{name: "declared tag wins", declared: "de-DE", want: "de"},
+		{name: "first valid tag of a list", declared: "und, fr-CA", want: "fr"},
+		{name: "falls back to detection", declared: "", want: "en"},
+		{name: "invalid tag falls back to detection", declared: "english", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resolve(tt.declared, text); got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.declared, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	if !Matches("en", []string{"de", "en-GB"}) {
+		t.Errorf("Matches(en, [de en-GB]) = false, want true")
+	}
+	if Matches("fr", []string{"de", "en"}) {
+		t.Errorf("Matches(fr, [de en]) = true, want false")
+	}
+}
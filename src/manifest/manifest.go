@@ -0,0 +1,198 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/metadata"
+)
+
+// Filename is the name of the manifest file written into the output directory
+const Filename = "manifest.json"
+
+// Entry describes a single saved page
+type Entry struct {
+	URL       string             `json:"url"`
+	File      string             `json:"file"`
+	Hash      string             `json:"hash"`
+	FetchedAt time.Time          `json:"fetched_at"`
+	Status    int                `json:"status,omitempty"` // HTTP status of the page, recorded when it is not 200
+	Metadata  *metadata.Metadata `json:"metadata,omitempty"`
+	Redirects []Redirect         `json:"redirects,omitempty"` // Redirects that led to the page, in request order
+	Tags      []string           `json:"tags,omitempty"`      // Tags assigned by tag rules
+	Summary   string             `json:"summary,omitempty"`   // Extractive summary of the page
+
+	// NavPosition is the 1-based position of the page in the site navigation, 0 when it is not linked from it,
+	// and NavParent the URL of the navigation item the page is nested under
+	NavPosition int    `json:"nav_position,omitempty"`
+	NavParent   string `json:"nav_parent,omitempty"`
+
+	// OriginalFile is the file name generated for the URL when other URLs mapped to the same name
+	// and it was disambiguated
+	OriginalFile string `json:"original_file,omitempty"`
+}
+
+// Redirect is a hop of the redirect chain of a page
+type Redirect struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// Manifest records the pages produced by a crawl run
+type Manifest struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Pages       []Entry   `json:"pages"`
+
+	// index maps page URLs to their position in Pages, built on Decode and kept up to date by Add
+	index map[string]int
+}
+
+// Changes lists the differences between two manifests
+type Changes struct {
+	Added    []Entry
+	Removed  []Entry
+	Modified []Entry
+}
+
+// New creates an empty manifest
+func New() *Manifest {
+	return &Manifest{
+		GeneratedAt: time.Now().UTC(),
+		Pages:       []Entry{},
+		index:       map[string]int{},
+	}
+}
+
+// Load reads a manifest from disk, returning an empty manifest if the file does not exist
+func Load(path string) (*Manifest, error) {
+	//nolint:gosec // The manifest path is derived from the user-selected output directory.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return New(), nil
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return Decode(data)
+}
+
+// Decode parses a manifest from its JSON representation
+func Decode(data []byte) (*Manifest, error) {
+	m := New()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	m.reindex()
+	return m, nil
+}
+
+// Save writes the manifest to disk
+func (m *Manifest) Save(path string) error {
+	data, err := m.Encode()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Encode returns the JSON representation of the manifest with pages sorted by URL
+func (m *Manifest) Encode() ([]byte, error) {
+	sort.Slice(m.Pages, func(i, j int) bool {
+		return m.Pages[i].URL < m.Pages[j].URL
+	})
+	m.reindex()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	return data, nil
+}
+
+// Add appends an entry to the manifest
+func (m *Manifest) Add(entry Entry) {
+	if m.index == nil {
+		m.reindex()
+	}
+	if _, exists := m.index[entry.URL]; !exists {
+		m.index[entry.URL] = len(m.Pages)
+	}
+	m.Pages = append(m.Pages, entry)
+}
+
+// Lookup returns the entry recorded for a URL
+func (m *Manifest) Lookup(pageURL string) (Entry, bool) {
+	if m.index == nil {
+		m.reindex()
+	}
+	i, ok := m.index[pageURL]
+	if !ok {
+		return Entry{}, false
+	}
+	return m.Pages[i], true
+}
+
+// reindex rebuilds the URL index, keeping the first entry of a URL recorded more than once
+func (m *Manifest) reindex() {
+	m.index = make(map[string]int, len(m.Pages))
+	for i, entry := range m.Pages {
+		if _, exists := m.index[entry.URL]; !exists {
+			m.index[entry.URL] = i
+		}
+	}
+}
+
+// Diff compares a previous manifest with the current one
+func Diff(previous, current *Manifest) Changes {
+	changes := Changes{}
+
+	previousByURL := make(map[string]Entry, len(previous.Pages))
+	for _, entry := range previous.Pages {
+		previousByURL[entry.URL] = entry
+	}
+
+	currentByURL := make(map[string]Entry, len(current.Pages))
+	for _, entry := range current.Pages {
+		currentByURL[entry.URL] = entry
+
+		old, exists := previousByURL[entry.URL]
+		switch {
+		case !exists:
+			changes.Added = append(changes.Added, entry)
+		case old.Hash != entry.Hash:
+			changes.Modified = append(changes.Modified, entry)
+		}
+	}
+
+	for _, entry := range previous.Pages {
+		if _, exists := currentByURL[entry.URL]; !exists {
+			changes.Removed = append(changes.Removed, entry)
+		}
+	}
+
+	return changes
+}
+
+// Empty reports whether no changes were detected
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// HashContent returns the hex encoded SHA-256 hash of the content
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
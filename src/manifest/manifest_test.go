@@ -0,0 +1,133 @@
+package manifest
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	m, err := Load(filepath.Join(t.TempDir(), Filename))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if len(m.Pages) != 0 {
+		t.Errorf("Load() expected empty manifest, got %d pages", len(m.Pages))
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), Filename)
+
+	m := New()
+	m.Add(Entry{URL: "https://example.com/b", File: "b.md", Hash: "2"})
+	m.Add(Entry{URL: "https://example.com/a", File: "a.md", Hash: "1"})
+
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if len(loaded.Pages) != 2 {
+		t.Fatalf("Load() expected 2 pages, got %d", len(loaded.Pages))
+	}
+
+	if loaded.Pages[0].URL != "https://example.com/a" {
+		t.Errorf("Save() expected pages sorted by URL, got %s first", loaded.Pages[0].URL)
+	}
+
+	entry, ok := loaded.Lookup("https://example.com/b")
+	if !ok || entry.File != "b.md" {
+		t.Errorf("Lookup() = %+v, %v", entry, ok)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	m := New()
+	m.Add(Entry{URL: "https://example.com/b", File: "b.md"})
+	m.Add(Entry{URL: "https://example.com/a", File: "a.md"})
+	m.Add(Entry{URL: "https://example.com/b", File: "b-1.md"})
+
+	if entry, ok := m.Lookup("https://example.com/b"); !ok || entry.File != "b.md" {
+		t.Errorf("Lookup() of a URL added twice = %+v, %v, want the first entry", entry, ok)
+	}
+
+	data, err := m.Encode()
+	if err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+
+	// Encode sorts the pages, which must not leave the index pointing at other entries
+	if entry, ok := m.Lookup("https://example.com/a"); !ok || entry.File != "a.md" {
+		t.Errorf("Lookup() after Encode() = %+v, %v", entry, ok)
+	}
+
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+
+	if entry, ok := decoded.Lookup("https://example.com/a"); !ok || entry.File != "a.md" {
+		t.Errorf("Lookup() after Decode() = %+v, %v", entry, ok)
+	}
+
+	if _, ok := decoded.Lookup("https://example.com/missing"); ok {
+		t.Error("Lookup() found a URL that was never added")
+	}
+
+	var literal Manifest
+	literal.Pages = []Entry{{URL: "https://example.com/c", File: "c.md"}}
+	if entry, ok := literal.Lookup("https://example.com/c"); !ok || entry.File != "c.md" {
+		t.Errorf("Lookup() on a manifest built without New() = %+v, %v", entry, ok)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	previous := New()
+	previous.Add(Entry{URL: "https://example.com/kept", Hash: "same"})
+	previous.Add(Entry{URL: "https://example.com/changed", Hash: "old"})
+	previous.Add(Entry{URL: "https://example.com/removed", Hash: "gone"})
+
+	current := New()
+	current.Add(Entry{URL: "https://example.com/kept", Hash: "same"})
+	current.Add(Entry{URL: "https://example.com/changed", Hash: "new"})
+	current.Add(Entry{URL: "https://example.com/added", Hash: "fresh"})
+
+	changes := Diff(previous, current)
+
+	if len(changes.Added) != 1 || changes.Added[0].URL != "https://example.com/added" {
+		t.Errorf("Diff() Added = %+v", changes.Added)
+	}
+
+	if len(changes.Removed) != 1 || changes.Removed[0].URL != "https://example.com/removed" {
+		t.Errorf("Diff() Removed = %+v", changes.Removed)
+	}
+
+	if len(changes.Modified) != 1 || changes.Modified[0].URL != "https://example.com/changed" {
+		t.Errorf("Diff() Modified = %+v", changes.Modified)
+	}
+
+	if Diff(current, current).Empty() != true {
+		t.Errorf("Diff() of identical manifests should be empty")
+	}
+}
+
+func TestHashContent(t *testing.T) {
+	if HashContent([]byte("a")) == HashContent([]byte("b")) {
+		t.Errorf("HashContent() returned the same hash for different content")
+	}
+
+	if HashContent([]byte("a")) != HashContent([]byte("a")) {
+		t.Errorf("HashContent() is not stable")
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode([]byte("{")); err == nil {
+		t.Errorf("Decode() expected error for invalid JSON")
+	}
+}
@@ -0,0 +1,188 @@
+package mdlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// Issue kinds
+const (
+	KindUnclosedFence   = "unclosed-fence"
+	KindBrokenReference = "broken-reference"
+	KindMalformedTable  = "malformed-table"
+)
+
+// Issue is a structural problem found in a Markdown document
+type Issue struct {
+	Line    int    `json:"line"` // 1-based line where the problem starts
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+var (
+	markdownParser = goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser()
+
+	openingFencePattern = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})(.*)$")
+	inlineCodePattern   = regexp.MustCompile("`+[^`]*`+")
+	referencePattern    = regexp.MustCompile(`!?\[([^\[\]]+)\]\[([^\[\]]*)\]`)
+	delimiterRowPattern = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+)
+
+// Check parses the Markdown and reports unclosed code fences, reference links without a definition,
+// and tables whose delimiter row is missing or whose rows do not match the header
+func Check(markdown string) []Issue {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	code, issues := codeLines(lines)
+
+	issues = append(issues, checkReferences(markdown, lines, code)...)
+	issues = append(issues, checkTables(lines, code)...)
+
+	return issues
+}
+
+// codeLines marks the lines inside fenced code blocks and reports fences left open at the end of the document
+func codeLines(lines []string) ([]bool, []Issue) {
+	code := make([]bool, len(lines))
+	fence := ""
+	opened := 0
+
+	for i, line := range lines {
+		if fence != "" {
+			code[i] = true
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+
+		match := openingFencePattern.FindStringSubmatch(line)
+		// Backtick fences cannot have backticks in their info string
+		if match == nil || (match[1][0] == '`' && strings.Contains(match[2], "`")) {
+			continue
+		}
+		code[i] = true
+		fence = match[1]
+		opened = i
+	}
+
+	if fence == "" {
+		return code, nil
+	}
+	return code, []Issue{{
+		Line:    opened + 1,
+		Kind:    KindUnclosedFence,
+		Message: fmt.Sprintf("code fence %q is never closed", fence),
+	}}
+}
+
+// checkReferences reports full and collapsed reference links whose label has no definition
+func checkReferences(markdown string, lines []string, code []bool) []Issue {
+	context := parser.NewContext()
+	markdownParser.Parse(text.NewReader([]byte(markdown)), parser.WithContext(context))
+
+	var issues []Issue
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+
+		line = inlineCodePattern.ReplaceAllString(line, "")
+		for _, match := range referencePattern.FindAllStringSubmatch(line, -1) {
+			label := match[2]
+			if label == "" {
+				label = match[1]
+			}
+			if _, ok := context.Reference(normalizeLabel(label)); !ok {
+				issues = append(issues, Issue{
+					Line:    i + 1,
+					Kind:    KindBrokenReference,
+					Message: fmt.Sprintf("reference %q is not defined", label),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// normalizeLabel matches reference labels case-insensitively with collapsed whitespace, as CommonMark does
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// checkTables reports runs of pipe rows without a delimiter row and rows with a different cell count than the header
+func checkTables(lines []string, code []bool) []Issue {
+	var issues []Issue
+
+	for i := 0; i < len(lines); {
+		if code[i] || !isTableRow(lines[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(lines) && !code[i] && isTableRow(lines[i]) {
+			i++
+		}
+		rows := lines[start:i]
+
+		if len(rows) < 2 || !delimiterRowPattern.MatchString(strings.TrimSpace(rows[1])) {
+			issues = append(issues, Issue{
+				Line:    start + 1,
+				Kind:    KindMalformedTable,
+				Message: "table rows without a delimiter row after the header",
+			})
+			continue
+		}
+
+		header := len(tableCells(rows[0]))
+		for offset, row := range rows[1:] {
+			if cells := len(tableCells(row)); cells != header {
+				issues = append(issues, Issue{
+					Line:    start + offset + 2,
+					Kind:    KindMalformedTable,
+					Message: fmt.Sprintf("table row has %d cells, the header has %d", cells, header),
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// isTableRow reports whether a line looks like a pipe table row
+func isTableRow(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "|") && len(tableCells(trimmed)) > 0
+}
+
+// tableCells splits a table row on pipes that are not escaped or inside code spans
+func tableCells(row string) []string {
+	row = strings.TrimSpace(inlineCodePattern.ReplaceAllString(row, "code"))
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = strings.TrimSuffix(row, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteString(`\|`)
+			i++
+		case row[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, cell.String())
+}
@@ -0,0 +1,50 @@
+package mdlint
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     []Issue
+	}{
+		{
+			name:     "valid document",
+			markdown: "# Title\n\nSee [docs][1] and [Guide][].\n\n[1]: https://example.com/docs\n[guide]: /guide\n\n| A | B |\n| --- | --- |\n| 1 | `a|b` |\n\n```go\n[x][missing]\n| not | a table\n```",
+		},
+		{
+			name:     "unclosed fence",
+			markdown: "Intro\n\n````python\nprint(1)\n```\n",
+			want:     []Issue{{Line: 3, Kind: KindUnclosedFence, Message: "code fence \"````\" is never closed"}},
+		},
+		{
+			name:     "broken reference",
+			markdown: "See [the docs][docs] and ![logo][].\n\nUse `[a][b]` literally.",
+			want: []Issue{
+				{Line: 1, Kind: KindBrokenReference, Message: `reference "docs" is not defined`},
+				{Line: 1, Kind: KindBrokenReference, Message: `reference "logo" is not defined`},
+			},
+		},
+		{
+			name:     "table without delimiter row",
+			markdown: "| A | B |\n| 1 | 2 |",
+			want:     []Issue{{Line: 1, Kind: KindMalformedTable, Message: "table rows without a delimiter row after the header"}},
+		},
+		{
+			name:     "table row with extra cells",
+			markdown: "Text\n\n| A | B |\n|---|---|\n| 1 | 2 | 3 |\n| 4 \\| 5 | 6 |",
+			want:     []Issue{{Line: 5, Kind: KindMalformedTable, Message: "table row has 3 cells, the header has 2"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Check(tt.markdown); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
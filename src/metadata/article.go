@@ -0,0 +1,215 @@
+package metadata
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// articleTypes are the schema.org types whose author and dates describe the page
+var articleTypes = map[string]bool{
+	"Article":          true,
+	"NewsArticle":      true,
+	"BlogPosting":      true,
+	"TechArticle":      true,
+	"ScholarlyArticle": true,
+	"Report":           true,
+	"LiveBlogPosting":  true,
+}
+
+// Heuristic sources, most reliable first
+var (
+	authorMetaNames    = []string{"author", "article:author", "dc.creator", "dcterms.creator", "sailthru.author", "parsely-author"}
+	publishedMetaNames = []string{"date", "pubdate", "publish-date", "publish_date", "publication_date", "dc.date", "dc.date.issued", "dcterms.created", "dcterms.issued", "sailthru.date", "parsely-pub-date"}
+	modifiedMetaNames  = []string{"last-modified", "dcterms.modified", "dc.date.modified"}
+
+	authorSelectors = []string{
+		`[itemprop="author"] [itemprop="name"]`,
+		`[itemprop="author"]`,
+		`[rel="author"]`,
+		`.byline .author`,
+		`.author-name`,
+		`.post-author`,
+		`.byline`,
+		`.author`,
+	}
+	publishedSelectors = []string{`[itemprop="datePublished"]`, `time[pubdate]`, `article time[datetime]`}
+	modifiedSelectors  = []string{`[itemprop="dateModified"]`}
+)
+
+// maxAuthorLength discards byline matches that are really author bio boxes
+const maxAuthorLength = 100
+
+// dateLayouts are the date formats normalized to RFC 3339
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02 15:04:05",
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+}
+
+// extractArticle fills the author and the published and modified dates from meta tags, JSON-LD, and bylines
+func extractArticle(doc *goquery.Selection, m *Metadata, metaValues map[string]string) {
+	var ldAuthor, ldPublished, ldModified string
+	for _, block := range m.JSONLD {
+		author, published, modified := articleFromJSONLD(block)
+		ldAuthor = firstNonEmpty(ldAuthor, author)
+		ldPublished = firstNonEmpty(ldPublished, published)
+		ldModified = firstNonEmpty(ldModified, modified)
+	}
+
+	m.Author = firstNonEmpty(firstMeta(metaValues, authorMetaNames[:1]), ldAuthor, firstMeta(metaValues, authorMetaNames[1:]), firstText(doc, authorSelectors, maxAuthorLength))
+	if strings.HasPrefix(m.Author, "http://") || strings.HasPrefix(m.Author, "https://") {
+		// article:author often holds a profile URL rather than a name
+		m.Author = firstNonEmpty(ldAuthor, firstText(doc, authorSelectors, maxAuthorLength), m.Author)
+	}
+	m.Author = cleanAuthor(m.Author)
+
+	m.PublishedTime = NormalizeDate(firstNonEmpty(m.PublishedTime, ldPublished, firstMeta(metaValues, publishedMetaNames), firstDate(doc, publishedSelectors)))
+	m.ModifiedTime = NormalizeDate(firstNonEmpty(m.ModifiedTime, ldModified, firstMeta(metaValues, modifiedMetaNames), firstDate(doc, modifiedSelectors)))
+}
+
+// articleFromJSONLD returns the author and dates of the first article object in a JSON-LD block
+func articleFromJSONLD(block json.RawMessage) (string, string, string) {
+	var value any
+	if err := json.Unmarshal(block, &value); err != nil {
+		return "", "", ""
+	}
+
+	for _, object := range jsonLDObjects(value) {
+		if !isArticle(object["@type"]) {
+			continue
+		}
+		published, _ := object["datePublished"].(string)
+		modified, _ := object["dateModified"].(string)
+		return jsonLDName(object["author"]), published, modified
+	}
+
+	return "", "", ""
+}
+
+// jsonLDObjects flattens top-level arrays and @graph containers into a list of objects
+func jsonLDObjects(value any) []map[string]any {
+	var objects []map[string]any
+
+	switch typed := value.(type) {
+	case []any:
+		for _, item := range typed {
+			objects = append(objects, jsonLDObjects(item)...)
+		}
+	case map[string]any:
+		objects = append(objects, typed)
+		if graph, ok := typed["@graph"]; ok {
+			objects = append(objects, jsonLDObjects(graph)...)
+		}
+	}
+
+	return objects
+}
+
+func isArticle(value any) bool {
+	switch typed := value.(type) {
+	case string:
+		return articleTypes[typed]
+	case []any:
+		for _, item := range typed {
+			if name, ok := item.(string); ok && articleTypes[name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonLDName returns the names of a JSON-LD person or organization, or a list of them
+func jsonLDName(value any) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case map[string]any:
+		name, _ := typed["name"].(string)
+		return name
+	case []any:
+		var names []string
+		for _, item := range typed {
+			if name := jsonLDName(item); name != "" {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+func firstMeta(values map[string]string, names []string) string {
+	for _, name := range names {
+		if value := values[name]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// firstText returns the text of the first element matching one of the selectors, ignoring texts longer than maxLength
+func firstText(doc *goquery.Selection, selectors []string, maxLength int) string {
+	for _, selector := range selectors {
+		selection := doc.Find(selector).First()
+		text := strings.Join(strings.Fields(firstNonEmpty(selection.AttrOr("content", ""), selection.Text())), " ")
+		if text != "" && len(text) <= maxLength {
+			return text
+		}
+	}
+	return ""
+}
+
+// firstDate returns the datetime or content attribute, or the text, of the first element matching one of the selectors
+func firstDate(doc *goquery.Selection, selectors []string) string {
+	for _, selector := range selectors {
+		selection := doc.Find(selector).First()
+		value := firstNonEmpty(selection.AttrOr("datetime", ""), selection.AttrOr("content", ""), strings.TrimSpace(selection.Text()))
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// cleanAuthor removes the "By" prefix of bylines
+func cleanAuthor(author string) string {
+	author = strings.TrimSpace(author)
+	if len(author) > 3 && strings.EqualFold(author[:3], "by ") {
+		author = strings.TrimSpace(author[3:])
+	}
+	return author
+}
+
+// NormalizeDate rewrites common date formats as RFC 3339, or as YYYY-MM-DD for dates without a time.
+// Values that cannot be parsed are returned trimmed.
+func NormalizeDate(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date.Format("2006-01-02")
+	}
+
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			if layout == "January 2, 2006" || layout == "Jan 2, 2006" || layout == "2 January 2006" {
+				return date.Format("2006-01-02")
+			}
+			return date.Format(time.RFC3339)
+		}
+	}
+
+	return value
+}
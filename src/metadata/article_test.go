@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestExtractArticle(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/news/story")
+
+	tests := []struct {
+		name          string
+		html          string
+		wantAuthor    string
+		wantPublished string
+		wantModified  string
+	}{
+		{
+			name: "meta tags",
+			html: `<html><head>
+<meta name="author" content="Jane Doe">
+<meta property="article:published_time" content="2024-03-01T08:00:00+01:00">
+<meta name="last-modified" content="2024-03-02 10:00:00">
+</head><body></body></html>`,
+			wantAuthor:    "Jane Doe",
+			wantPublished: "2024-03-01T08:00:00+01:00",
+			wantModified:  "2024-03-02T10:00:00Z",
+		},
+		{
+			name: "json-ld article in a graph",
+			html: `<html><head><script type="application/ld+json">{"@context":"https://schema.org","@graph":[
+{"@type":"WebSite","name":"News"},
+{"@type":["NewsArticle"],"author":[{"@type":"Person","name":"Ann"},{"@type":"Person","name":"Bob"}],"datePublished":"2024-01-05","dateModified":"2024-01-06T12:00:00Z"}
+]}</script></head><body></body></html>`,
+			wantAuthor:    "Ann, Bob",
+			wantPublished: "2024-01-05",
+			wantModified:  "2024-01-06T12:00:00Z",
+		},
+		{
+			name: "article author url falls back to json-ld name",
+			html: `<html><head>
+<meta property="article:author" content="https://example.com/authors/jane">
+<script type="application/ld+json">{"@type":"BlogPosting","author":"Jane"}</script>
+</head><body></body></html>`,
+			wantAuthor: "Jane",
+		},
+		{
+			name: "byline and time elements",
+			html: `<html><body><article>
+<p class="byline">By <span class="author">Sam Smith</span></p>
+<time datetime="2023-12-24T18:30:00Z">Christmas Eve</time>
+<span itemprop="dateModified" content="December 27, 2023"></span>
+</article></body></html>`,
+			wantAuthor:    "Sam Smith",
+			wantPublished: "2023-12-24T18:30:00Z",
+			wantModified:  "2023-12-27",
+		},
+		{
+			name:       "byline prefix removed",
+			html:       `<html><body><div class="byline">by Alex</div></body></html>`,
+			wantAuthor: "Alex",
+		},
+		{
+			name:       "author bio box ignored",
+			html:       `<html><body><div class="author">Alex is a writer who has covered technology, science, and culture for more than twenty years at several outlets.</div></body></html>`,
+			wantAuthor: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Extract(parse(t, tt.html), pageURL)
+
+			if got.Author != tt.wantAuthor {
+				t.Errorf("Author = %q, want %q", got.Author, tt.wantAuthor)
+			}
+			if got.PublishedTime != tt.wantPublished {
+				t.Errorf("PublishedTime = %q, want %q", got.PublishedTime, tt.wantPublished)
+			}
+			if got.ModifiedTime != tt.wantModified {
+				t.Errorf("ModifiedTime = %q, want %q", got.ModifiedTime, tt.wantModified)
+			}
+		})
+	}
+}
+
+func TestNormalizeDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "2024-03-01", want: "2024-03-01"},
+		{value: " 2024-03-01T08:00:00.123Z ", want: "2024-03-01T08:00:00Z"},
+		{value: "Fri, 01 Mar 2024 08:00:00 GMT", want: "2024-03-01T08:00:00Z"},
+		{value: "March 1, 2024", want: "2024-03-01"},
+		{value: "yesterday", want: "yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := NormalizeDate(tt.value); got != tt.want {
+				t.Errorf("NormalizeDate(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
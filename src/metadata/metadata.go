@@ -0,0 +1,115 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Metadata is the structured information a page declares about itself
+type Metadata struct {
+	Title         string            `json:"title,omitempty"`
+	Description   string            `json:"description,omitempty"`
+	Image         string            `json:"image,omitempty"`
+	Type          string            `json:"type,omitempty"`
+	SiteName      string            `json:"site_name,omitempty"`
+	Author        string            `json:"author,omitempty"`
+	PublishedTime string            `json:"published_time,omitempty"`
+	ModifiedTime  string            `json:"modified_time,omitempty"`
+	OpenGraph     map[string]string `json:"open_graph,omitempty"` // og:* properties without the prefix
+	Twitter       map[string]string `json:"twitter,omitempty"`    // twitter:* card fields without the prefix
+	JSONLD        []json.RawMessage `json:"json_ld,omitempty"`    // Compacted application/ld+json blocks
+}
+
+// IsZero reports whether no metadata was found
+func (m Metadata) IsZero() bool {
+	return m.Title == "" && m.Description == "" && m.Image == "" && m.Type == "" && m.SiteName == "" && m.Author == "" &&
+		m.PublishedTime == "" && m.ModifiedTime == "" && len(m.OpenGraph) == 0 && len(m.Twitter) == 0 && len(m.JSONLD) == 0
+}
+
+// Extract collects Open Graph, Twitter card, article, and JSON-LD metadata from a document.
+// Author and dates fall back to other meta tags, JSON-LD articles, and bylines. Image URLs are resolved against pageURL.
+func Extract(doc *goquery.Selection, pageURL *url.URL) Metadata {
+	var m Metadata
+	metaValues := make(map[string]string)
+
+	doc.Find("meta").Each(func(_ int, meta *goquery.Selection) {
+		key := strings.ToLower(strings.TrimSpace(meta.AttrOr("property", "")))
+		if key == "" {
+			key = strings.ToLower(strings.TrimSpace(meta.AttrOr("name", "")))
+		}
+		content := strings.TrimSpace(meta.AttrOr("content", ""))
+		if key == "" || content == "" {
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(key, "og:"):
+			m.OpenGraph = setFirst(m.OpenGraph, strings.TrimPrefix(key, "og:"), content)
+		case strings.HasPrefix(key, "twitter:"):
+			m.Twitter = setFirst(m.Twitter, strings.TrimPrefix(key, "twitter:"), content)
+		case key == "article:published_time" && m.PublishedTime == "":
+			m.PublishedTime = content
+		case key == "article:modified_time" && m.ModifiedTime == "":
+			m.ModifiedTime = content
+		default:
+			metaValues = setFirst(metaValues, key, content)
+		}
+	})
+
+	m.Title = firstNonEmpty(m.OpenGraph["title"], m.Twitter["title"])
+	m.Description = firstNonEmpty(m.OpenGraph["description"], m.Twitter["description"], metaValues["description"])
+	m.Image = resolve(pageURL, firstNonEmpty(m.OpenGraph["image"], m.OpenGraph["image:url"], m.Twitter["image"], m.Twitter["image:src"]))
+	m.Type = m.OpenGraph["type"]
+	m.SiteName = m.OpenGraph["site_name"]
+	m.ModifiedTime = firstNonEmpty(m.ModifiedTime, m.OpenGraph["updated_time"])
+
+	doc.Find(`script[type="application/ld+json" i]`).Each(func(_ int, script *goquery.Selection) {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, []byte(strings.TrimSpace(script.Text()))); err != nil {
+			return
+		}
+		m.JSONLD = append(m.JSONLD, json.RawMessage(compacted.Bytes()))
+	})
+
+	extractArticle(doc, &m, metaValues)
+
+	return m
+}
+
+// setFirst stores the first value seen for a key, as pages list the preferred value first
+func setFirst(values map[string]string, key, value string) map[string]string {
+	if values == nil {
+		values = make(map[string]string)
+	}
+	if _, exists := values[key]; !exists {
+		values[key] = value
+	}
+	return values
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// resolve makes a possibly relative URL absolute
+func resolve(base *url.URL, ref string) string {
+	if ref == "" || base == nil {
+		return ref
+	}
+
+	parsedRef, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return base.ResolveReference(parsedRef).String()
+}
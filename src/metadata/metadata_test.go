@@ -0,0 +1,101 @@
+package metadata
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func parse(t *testing.T, html string) *goquery.Selection {
+	t.Helper()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("parsing HTML: %v", err)
+	}
+	return doc.Selection
+}
+
+func TestExtract(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/blog/post")
+
+	doc := parse(t, `<html><head>
+<title>Post | Blog</title>
+<meta name="description" content="Plain description">
+<meta property="og:title" content="Post">
+<meta property="og:description" content="OG description">
+<meta property="og:image" content="/img/cover.png">
+<meta property="og:image" content="/img/second.png">
+<meta property="og:type" content="article">
+<meta property="og:site_name" content="Blog">
+<meta property="article:published_time" content="2024-03-01T08:00:00Z">
+<meta property="article:modified_time" content="2024-03-02T09:30:00Z">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:creator" content="@writer">
+<script type="application/ld+json">
+{
+  "@context": "https://schema.org",
+  "@type": "BlogPosting",
+  "headline": "Post"
+}
+</script>
+<script type="application/ld+json">{ not json</script>
+</head><body></body></html>`)
+
+	got := Extract(doc, pageURL)
+
+	want := Metadata{
+		Title:         "Post",
+		Description:   "OG description",
+		Image:         "https://example.com/img/cover.png",
+		Type:          "article",
+		SiteName:      "Blog",
+		PublishedTime: "2024-03-01T08:00:00Z",
+		ModifiedTime:  "2024-03-02T09:30:00Z",
+		OpenGraph: map[string]string{
+			"title":       "Post",
+			"description": "OG description",
+			"image":       "/img/cover.png",
+			"type":        "article",
+			"site_name":   "Blog",
+		},
+		Twitter: map[string]string{"card": "summary_large_image", "creator": "@writer"},
+	}
+
+	if len(got.JSONLD) != 1 || string(got.JSONLD[0]) != `{"@context":"https://schema.org","@type":"BlogPosting","headline":"Post"}` {
+		t.Errorf("Extract() JSONLD = %s", got.JSONLD)
+	}
+
+	got.JSONLD = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExtractFallbacks(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/")
+
+	doc := parse(t, `<html><head>
+<meta name="description" content="Plain description">
+<meta name="twitter:title" content="Twitter title">
+<meta name="twitter:image" content="https://cdn.example.com/a.png">
+</head></html>`)
+
+	got := Extract(doc, pageURL)
+
+	if got.Title != "Twitter title" || got.Description != "Plain description" || got.Image != "https://cdn.example.com/a.png" {
+		t.Errorf("Extract() = %+v", got)
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	if !(Metadata{}).IsZero() {
+		t.Errorf("IsZero() = false for empty metadata")
+	}
+	if (Metadata{Description: "d"}).IsZero() {
+		t.Errorf("IsZero() = true for metadata with a description")
+	}
+}
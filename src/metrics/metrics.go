@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// runDurationBuckets are the upper bounds in seconds of the run duration histogram
+var runDurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600}
+
+// Metrics collects the counters of long-running crawls.
+// All methods are safe for concurrent use and do nothing on a nil receiver.
+type Metrics struct {
+	mutex           sync.Mutex
+	pagesFetched    int64
+	pagesSaved      int64
+	pageFailures    int64
+	requestErrors   int64
+	bytesDownloaded int64
+	queueDepth      int64
+	runningCrawls   int64
+	runsSucceeded   int64
+	runsFailed      int64
+	lastRun         time.Time
+	durationCounts  []int64 // Cumulative counts per bucket of runDurationBuckets
+	durationSum     float64
+}
+
+// New creates an empty metrics collector
+func New() *Metrics {
+	return &Metrics{durationCounts: make([]int64, len(runDurationBuckets))}
+}
+
+// PageFetched records a page fetched by the crawler
+func (m *Metrics) PageFetched() {
+	m.update(func() { m.pagesFetched++ })
+}
+
+// PageSaved records a page written or stored
+func (m *Metrics) PageSaved() {
+	m.update(func() { m.pagesSaved++ })
+}
+
+// PageFailed records a page that could not be converted, rendered, or saved
+func (m *Metrics) PageFailed() {
+	m.update(func() { m.pageFailures++ })
+}
+
+// RequestFailed records a failed request
+func (m *Metrics) RequestFailed() {
+	m.update(func() { m.requestErrors++ })
+}
+
+// Downloaded records bytes of response bodies read
+func (m *Metrics) Downloaded(n int) {
+	m.update(func() { m.bytesDownloaded += int64(n) })
+}
+
+// QueueAdd changes the number of crawled pages waiting to be saved by delta
+func (m *Metrics) QueueAdd(delta int) {
+	m.update(func() { m.queueDepth += int64(delta) })
+}
+
+// RunStarted records the start of a crawl run
+func (m *Metrics) RunStarted() {
+	m.update(func() { m.runningCrawls++ })
+}
+
+// RunFinished records the end of a crawl run started with RunStarted
+func (m *Metrics) RunFinished(duration time.Duration, success bool) {
+	m.update(func() {
+		m.runningCrawls--
+		if success {
+			m.runsSucceeded++
+		} else {
+			m.runsFailed++
+		}
+		m.lastRun = time.Now()
+
+		seconds := duration.Seconds()
+		m.durationSum += seconds
+		for i, bound := range runDurationBuckets {
+			if seconds <= bound {
+				m.durationCounts[i]++
+			}
+		}
+	})
+}
+
+func (m *Metrics) update(fn func()) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	fn()
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	p := &printer{w: w}
+	p.metric("crawldown_pages_fetched_total", "counter", "Pages fetched by the crawler.", m.pagesFetched)
+	p.metric("crawldown_pages_saved_total", "counter", "Pages written or stored.", m.pagesSaved)
+	p.metric("crawldown_page_failures_total", "counter", "Pages that failed to convert, render, or save.", m.pageFailures)
+	p.metric("crawldown_request_errors_total", "counter", "Failed requests.", m.requestErrors)
+	p.metric("crawldown_downloaded_bytes_total", "counter", "Bytes of response bodies read.", m.bytesDownloaded)
+	p.metric("crawldown_queue_depth", "gauge", "Crawled pages waiting to be saved.", m.queueDepth)
+	p.metric("crawldown_running_crawls", "gauge", "Crawl runs in progress.", m.runningCrawls)
+
+	p.header("crawldown_runs_total", "counter", "Finished crawl runs by result.")
+	p.line(`crawldown_runs_total{result="success"}`, m.runsSucceeded)
+	p.line(`crawldown_runs_total{result="failure"}`, m.runsFailed)
+
+	var lastRun int64
+	if !m.lastRun.IsZero() {
+		lastRun = m.lastRun.Unix()
+	}
+	p.metric("crawldown_last_run_timestamp_seconds", "gauge", "Unix time of the end of the last crawl run.", lastRun)
+
+	p.header("crawldown_run_duration_seconds", "histogram", "Duration of crawl runs.")
+	for i, bound := range runDurationBuckets {
+		p.line(fmt.Sprintf(`crawldown_run_duration_seconds_bucket{le="%s"}`, formatFloat(bound)), m.durationCounts[i])
+	}
+	runs := m.runsSucceeded + m.runsFailed
+	p.line(`crawldown_run_duration_seconds_bucket{le="+Inf"}`, runs)
+	p.printf("crawldown_run_duration_seconds_sum %s\n", formatFloat(m.durationSum))
+	p.line("crawldown_run_duration_seconds_count", runs)
+
+	return p.n, p.err
+}
+
+// Handler serves the metrics in the Prometheus text exposition format
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if _, err := m.WriteTo(w); err != nil {
+			return
+		}
+	})
+}
+
+// printer writes lines and keeps the first error
+type printer struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (p *printer) printf(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(p.w, format, args...)
+	p.n += int64(n)
+	p.err = err
+}
+
+func (p *printer) header(name, kind, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (p *printer) line(series string, value int64) {
+	p.printf("%s %d\n", series, value)
+}
+
+func (p *printer) metric(name, kind, help string, value int64) {
+	p.header(name, kind, help)
+	p.line(name, value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTo(t *testing.T) {
+	m := New()
+	m.RunStarted()
+	m.PageFetched()
+	m.PageFetched()
+	m.QueueAdd(2)
+	m.QueueAdd(-1)
+	m.PageSaved()
+	m.RequestFailed()
+	m.Downloaded(512)
+	m.RunFinished(20*time.Second, true)
+	m.RunStarted()
+
+	var out strings.Builder
+	if _, err := m.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "fetched", want: "crawldown_pages_fetched_total 2\n"},
+		{name: "saved", want: "crawldown_pages_saved_total 1\n"},
+		{name: "errors", want: "crawldown_request_errors_total 1\n"},
+		{name: "bytes", want: "crawldown_downloaded_bytes_total 512\n"},
+		{name: "queue", want: "crawldown_queue_depth 1\n"},
+		{name: "running", want: "crawldown_running_crawls 1\n"},
+		{name: "runs", want: `crawldown_runs_total{result="success"} 1` + "\n"},
+		{name: "type", want: "# TYPE crawldown_run_duration_seconds histogram\n"},
+		{name: "bucket below", want: `crawldown_run_duration_seconds_bucket{le="15"} 0` + "\n"},
+		{name: "bucket above", want: `crawldown_run_duration_seconds_bucket{le="30"} 1` + "\n"},
+		{name: "bucket inf", want: `crawldown_run_duration_seconds_bucket{le="+Inf"} 1` + "\n"},
+		{name: "sum", want: "crawldown_run_duration_seconds_sum 20\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("WriteTo() = %q, want it to contain %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.PageFetched()
+	m.QueueAdd(1)
+	m.RunFinished(time.Second, false)
+}
+
+func TestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, ContentType)
+	}
+	if !strings.Contains(rec.Body.String(), "crawldown_pages_fetched_total 0\n") {
+		t.Errorf("body = %q, want the fetched pages counter", rec.Body.String())
+	}
+}
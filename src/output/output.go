@@ -0,0 +1,291 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrNotExist is returned by ReadFile when the requested file does not exist
+var ErrNotExist = fs.ErrNotExist
+
+// ErrOutsideOutput is returned by Remove for names that are not paths inside the output directory
+var ErrOutsideOutput = errors.New("path is outside the output directory")
+
+// Writer stores the files produced by a crawl.
+// Names are slash-separated paths relative to the output root.
+type Writer interface {
+	// WriteFile creates or replaces a file
+	WriteFile(name string, data []byte) error
+	// ReadFile returns the content of a file, or an error wrapping ErrNotExist
+	ReadFile(name string) ([]byte, error)
+	// Exists reports whether a file exists
+	Exists(name string) (bool, error)
+	// Remove deletes a file; removing a missing file is not an error
+	Remove(name string) error
+	// Location returns a human readable location of a file for logging
+	Location(name string) string
+}
+
+// Permissions are the modes of the files and directories created in a local directory.
+// Zero modes default to private ones, 0600 and 0750, or to 0666 and 0777 with Umask.
+type Permissions struct {
+	File fs.FileMode
+	Dir  fs.FileMode
+	// Umask applies the umask of the process to the modes, like most tools do, instead of setting them exactly
+	Umask bool
+}
+
+// withDefaults fills in the zero modes
+func (p Permissions) withDefaults() Permissions {
+	if p.File == 0 {
+		p.File = 0o600
+		if p.Umask {
+			p.File = 0o666
+		}
+	}
+	if p.Dir == 0 {
+		p.Dir = 0o750
+		if p.Umask {
+			p.Dir = 0o777
+		}
+	}
+	return p
+}
+
+// ParseMode parses an octal file mode such as 644 or 0o2775, including the setuid, setgid, and sticky bits
+func ParseMode(value string) (fs.FileMode, error) {
+	bits, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(value, "0o"), "0O"), 8, 32)
+	if err != nil || bits > 0o7777 {
+		return 0, fmt.Errorf("invalid mode %q (expected octal permissions such as 0644)", value)
+	}
+
+	mode := fs.FileMode(bits) & fs.ModePerm
+	if bits&0o4000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if bits&0o2000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if bits&0o1000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode, nil
+}
+
+// Open returns the writer for an output target: an s3://bucket/prefix URL or a local directory
+// whose files and directories are created with perm
+func Open(target string, perm Permissions) (Writer, error) {
+	if strings.HasPrefix(target, "s3://") {
+		return NewS3WriterFromEnv(target)
+	}
+
+	if target == "" {
+		return nil, fmt.Errorf("empty output target")
+	}
+
+	return NewDirWriter(target, perm), nil
+}
+
+// IsRemote reports whether an output target is not a local directory
+func IsRemote(target string) bool {
+	return strings.HasPrefix(target, "s3://")
+}
+
+// DirWriter writes files below a local directory
+type DirWriter struct {
+	dir  string
+	perm Permissions
+}
+
+// NewDirWriter creates a writer for a local directory creating files and directories with perm
+func NewDirWriter(dir string, perm Permissions) *DirWriter {
+	return &DirWriter{dir: dir, perm: perm.withDefaults()}
+}
+
+// WriteFile atomically creates or replaces a file, creating parent directories as needed
+func (w *DirWriter) WriteFile(name string, data []byte) error {
+	return writeFileAtomic(w.path(name), data, w.perm)
+}
+
+// ReadFile returns the content of a file
+func (w *DirWriter) ReadFile(name string) ([]byte, error) {
+	//nolint:gosec // The path is below the user-selected output directory.
+	data, err := os.ReadFile(w.path(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// Exists reports whether a file exists
+func (w *DirWriter) Exists(name string) (bool, error) {
+	_, err := os.Stat(w.path(name))
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check %s: %w", name, err)
+}
+
+// Remove deletes a file and the directories it leaves empty below the output directory.
+// Names leading outside the output directory, directly or through a symlinked directory, are refused with ErrOutsideOutput.
+func (w *DirWriter) Remove(name string) error {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return fmt.Errorf("failed to remove %s: %w", name, ErrOutsideOutput)
+	}
+
+	path := w.path(name)
+	inside, err := w.contains(filepath.Dir(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	if !inside {
+		return fmt.Errorf("failed to remove %s: %w", name, ErrOutsideOutput)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		rel, err := filepath.Rel(w.dir, dir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			break
+		}
+		// Removing a directory that is not empty fails, which ends the cleanup
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// Location returns the local path of a file
+func (w *DirWriter) Location(name string) string {
+	return w.path(name)
+}
+
+// path converts a slash-separated name into a path below the directory
+// contains reports whether dir, with its symlinks resolved, is the output directory or inside it
+func (w *DirWriter) contains(dir string) (bool, error) {
+	root, err := filepath.EvalSymlinks(w.dir)
+	if err != nil {
+		return false, err
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false, err
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil {
+		return false, nil
+	}
+	return filepath.IsLocal(rel) || rel == ".", nil
+}
+
+func (w *DirWriter) path(name string) string {
+	return filepath.Join(w.dir, filepath.FromSlash(name))
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it over path, creating parent
+// directories as needed. An interrupted write leaves either the previous file or the new one, never a partial
+// file, and a symlink or hard link at path is replaced rather than written through.
+func WriteFileAtomic(path string, data []byte) error {
+	return writeFileAtomic(path, data, Permissions{}.withDefaults())
+}
+
+// writeFileAtomic is WriteFileAtomic creating the file and its parent directories with perm
+func writeFileAtomic(path string, data []byte, perm Permissions) error {
+	if err := mkdirAll(filepath.Dir(path), perm); err != nil {
+		return err
+	}
+
+	file, err := createTemp(path, perm)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	tempPath := file.Name()
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// MkdirAll creates a directory and its missing parents with the directory mode of perm
+func MkdirAll(dir string, perm Permissions) error {
+	return mkdirAll(dir, perm.withDefaults())
+}
+
+// mkdirAll creates the missing directories of dir one at a time, so that each gets the mode exactly
+func mkdirAll(dir string, perm Permissions) error {
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return nil
+	}
+
+	if parent := filepath.Dir(dir); parent != dir {
+		if err := mkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Mkdir(dir, perm.Dir); err != nil {
+		// Another writer may have created it in the meantime
+		if errors.Is(err, fs.ErrExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if !perm.Umask {
+		if err := os.Chmod(dir, perm.Dir); err != nil {
+			return fmt.Errorf("failed to set directory mode: %w", err)
+		}
+	}
+	return nil
+}
+
+// createTemp creates a new temporary file next to path with the file mode of perm.
+// Unlike os.CreateTemp, which always uses 0600, the mode is passed on creation so that the umask applies to it.
+func createTemp(path string, perm Permissions) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	for range 10000 {
+		//nolint:gosec // The path is next to the target file, and the random suffix only avoids name clashes.
+		file, err := os.OpenFile(prefix+strconv.FormatUint(rand.Uint64(), 36), os.O_RDWR|os.O_CREATE|os.O_EXCL, perm.File)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !perm.Umask {
+			if err := file.Chmod(perm.File); err != nil {
+				_ = file.Close()
+				_ = os.Remove(file.Name())
+				return nil, err
+			}
+		}
+		return file, nil
+	}
+	return nil, fmt.Errorf("failed to create a temporary file next to %s", path)
+}
@@ -0,0 +1,253 @@
+package output
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	tests := []struct {
+		name    string
+		target  string
+		wantS3  bool
+		wantErr bool
+	}{
+		{name: "local directory", target: t.TempDir()},
+		{name: "s3 target", target: "s3://bucket/prefix", wantS3: true},
+		{name: "empty target", target: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, err := Open(tt.target, Permissions{})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Open(%q) expected error but got none", tt.target)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Open(%q) unexpected error: %v", tt.target, err)
+			}
+
+			if _, isS3 := writer.(*S3Writer); isS3 != tt.wantS3 {
+				t.Errorf("Open(%q) returned %T", tt.target, writer)
+			}
+
+			if IsRemote(tt.target) != tt.wantS3 {
+				t.Errorf("IsRemote(%q) = %v", tt.target, !tt.wantS3)
+			}
+		})
+	}
+}
+
+func TestDirWriter(t *testing.T) {
+	dir := t.TempDir()
+	writer := NewDirWriter(dir, Permissions{})
+
+	if exists, err := writer.Exists("docs/page.md"); err != nil || exists {
+		t.Fatalf("Exists() = %v, %v before writing", exists, err)
+	}
+
+	if _, err := writer.ReadFile("docs/page.md"); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("ReadFile() error = %v, want ErrNotExist", err)
+	}
+
+	if err := writer.WriteFile("docs/page.md", []byte("# Page")); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+
+	data, err := writer.ReadFile("docs/page.md")
+	if err != nil || string(data) != "# Page" {
+		t.Fatalf("ReadFile() = %q, %v", data, err)
+	}
+
+	if exists, err := writer.Exists("docs/page.md"); err != nil || !exists {
+		t.Fatalf("Exists() = %v, %v after writing", exists, err)
+	}
+
+	if got := writer.Location("docs/page.md"); got != filepath.Join(dir, "docs", "page.md") {
+		t.Errorf("Location() = %q", got)
+	}
+
+	if err := writer.Remove("docs/page.md"); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat() error = %v, want the emptied directory removed", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Stat() error = %v, want the output directory kept", err)
+	}
+	if err := writer.Remove("docs/page.md"); err != nil {
+		t.Errorf("Remove() of a missing file unexpected error: %v", err)
+	}
+}
+
+func TestDirWriterRemoveOutside(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "out")
+	outside := filepath.Join(root, "other")
+	for _, file := range []string{filepath.Join(root, "secret.md"), filepath.Join(outside, "page.md")} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte("secret"), 0o600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatalf("failed to create output: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "linked")); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	writer := NewDirWriter(dir, Permissions{})
+	for _, name := range []string{"../secret.md", "/secret.md", "linked/page.md"} {
+		if err := writer.Remove(name); !errors.Is(err, ErrOutsideOutput) {
+			t.Errorf("Remove(%q) error = %v, want ErrOutsideOutput", name, err)
+		}
+	}
+
+	for _, file := range []string{filepath.Join(root, "secret.md"), filepath.Join(outside, "page.md")} {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("Stat(%s) error = %v, want the file outside the output kept", file, err)
+		}
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.md")
+	if err := os.WriteFile(target, []byte("target"), 0o600); err != nil {
+		t.Fatalf("failed to write target: %v", err)
+	}
+	link := filepath.Join(dir, "docs", "page.md")
+	if err := os.MkdirAll(filepath.Dir(link), 0o750); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	if err := WriteFileAtomic(link, []byte("# Page")); err != nil {
+		t.Fatalf("WriteFileAtomic() unexpected error: %v", err)
+	}
+
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	if data, err := os.ReadFile(target); err != nil || string(data) != "target" {
+		t.Errorf("symlink target = %q, %v, want it untouched", data, err)
+	}
+	info, err := os.Lstat(link)
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Fatalf("Lstat() = %v, %v, want the symlink replaced by a file", info, err)
+	}
+	//nolint:gosec // The path is created under t.TempDir and controlled by the test.
+	if data, err := os.ReadFile(link); err != nil || string(data) != "# Page" {
+		t.Errorf("ReadFile() = %q, %v, want the new content", data, err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(link))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("directory holds %d entries, %v, want no temporary file left", len(entries), err)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    fs.FileMode
+		wantErr bool
+	}{
+		{value: "644", want: 0o644},
+		{value: "0640", want: 0o640},
+		{value: "0o755", want: 0o755},
+		{value: "2775", want: 0o775 | fs.ModeSetgid},
+		{value: "1777", want: 0o777 | fs.ModeSticky},
+		{value: "0999", wantErr: true},
+		{value: "17777", wantErr: true},
+		{value: "rw-r--r--", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseMode(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseMode(%q) expected error but got none", tt.value)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseMode(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirWriterPermissions(t *testing.T) {
+	dir := t.TempDir()
+
+	// Files and directories created with 0666 and 0777 get the modes the umask leaves, whatever the umask is
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0o666); err != nil {
+		t.Fatalf("failed to write reference file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "dir"), 0o777); err != nil {
+		t.Fatalf("failed to create reference directory: %v", err)
+	}
+	masked := make(map[string]fs.FileMode)
+	for _, name := range []string{"file", "dir"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to stat reference %s: %v", name, err)
+		}
+		masked[name] = info.Mode().Perm()
+	}
+
+	tests := []struct {
+		name     string
+		perm     Permissions
+		wantFile fs.FileMode
+		wantDir  fs.FileMode
+	}{
+		{name: "defaults", wantFile: 0o600, wantDir: 0o750},
+		{name: "exact modes", perm: Permissions{File: 0o664, Dir: 0o775}, wantFile: 0o664, wantDir: 0o775},
+		{name: "umask", perm: Permissions{Umask: true}, wantFile: masked["file"], wantDir: masked["dir"]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := filepath.Join(dir, tt.name)
+			writer := NewDirWriter(root, tt.perm)
+			if err := writer.WriteFile("docs/page.md", []byte("page")); err != nil {
+				t.Fatalf("WriteFile() unexpected error: %v", err)
+			}
+
+			file, err := os.Stat(filepath.Join(root, "docs", "page.md"))
+			if err != nil {
+				t.Fatalf("failed to stat page: %v", err)
+			}
+			if file.Mode().Perm() != tt.wantFile {
+				t.Errorf("file mode = %v, want %v", file.Mode().Perm(), tt.wantFile)
+			}
+
+			for _, path := range []string{root, filepath.Join(root, "docs")} {
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatalf("failed to stat directory: %v", err)
+				}
+				if info.Mode().Perm() != tt.wantDir {
+					t.Errorf("mode of %s = %v, want %v", path, info.Mode().Perm(), tt.wantDir)
+				}
+			}
+		})
+	}
+}
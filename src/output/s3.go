@@ -0,0 +1,321 @@
+package output
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// S3Config holds the settings of an S3-compatible object storage target
+type S3Config struct {
+	Bucket          string
+	Prefix          string
+	Region          string
+	Endpoint        string // Custom endpoint for S3-compatible services, e.g. http://localhost:9000
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	PathStyle       bool // Address buckets as endpoint/bucket instead of bucket.endpoint
+}
+
+// S3Writer writes files as objects into an S3-compatible bucket
+type S3Writer struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Writer creates a writer for an S3-compatible bucket
+func NewS3Writer(config S3Config) (*S3Writer, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("missing S3 bucket")
+	}
+
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("missing S3 credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	config.Prefix = strings.Trim(config.Prefix, "/")
+
+	return &S3Writer{
+		config: config,
+		client: &http.Client{Timeout: 60 * time.Second},
+		now:    time.Now,
+	}, nil
+}
+
+// NewS3WriterFromEnv creates a writer for an s3://bucket/prefix target using the standard AWS environment variables
+func NewS3WriterFromEnv(target string) (*S3Writer, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Scheme != "s3" {
+		return nil, fmt.Errorf("invalid S3 target %q: expected s3://bucket/prefix", target)
+	}
+
+	endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL")
+
+	return NewS3Writer(S3Config{
+		Bucket:          parsed.Host,
+		Prefix:          parsed.Path,
+		Region:          firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		Endpoint:        endpoint,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		PathStyle:       endpoint != "" || os.Getenv("AWS_S3_FORCE_PATH_STYLE") == "true",
+	})
+}
+
+// WriteFile uploads an object
+func (w *S3Writer) WriteFile(name string, data []byte) error {
+	headers := http.Header{}
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+
+	resp, err := w.do(http.MethodPut, name, data, headers)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		return w.responseError(resp, name)
+	}
+
+	return nil
+}
+
+// ReadFile downloads an object
+func (w *S3Writer) ReadFile(name string) ([]byte, error) {
+	resp, err := w.do(http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("object %s: %w", w.Location(name), ErrNotExist)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return nil, w.responseError(resp, name)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", w.Location(name), err)
+	}
+
+	return data, nil
+}
+
+// Exists reports whether an object exists
+func (w *S3Writer) Exists(name string) (bool, error) {
+	resp, err := w.do(http.MethodHead, name, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode/100 == 2:
+		return true, nil
+	default:
+		return false, w.responseError(resp, name)
+	}
+}
+
+// Remove deletes an object
+func (w *S3Writer) Remove(name string) error {
+	resp, err := w.do(http.MethodDelete, name, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return w.responseError(resp, name)
+	}
+
+	return nil
+}
+
+// Location returns the s3:// URL of an object
+func (w *S3Writer) Location(name string) string {
+	return "s3://" + w.config.Bucket + "/" + w.key(name)
+}
+
+// key returns the object key of a file
+func (w *S3Writer) key(name string) string {
+	if w.config.Prefix == "" {
+		return name
+	}
+	return w.config.Prefix + "/" + name
+}
+
+// objectURL returns the request URL of an object
+func (w *S3Writer) objectURL(name string) (*url.URL, error) {
+	endpoint, err := url.Parse(w.config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+
+	if w.config.PathStyle {
+		endpoint.Path = "/" + w.config.Bucket + "/" + w.key(name)
+	} else {
+		endpoint.Host = w.config.Bucket + "." + endpoint.Host
+		endpoint.Path = "/" + w.key(name)
+	}
+
+	// Use the stricter S3 encoding so the request path matches the signed canonical path
+	endpoint.RawPath = escapePath(endpoint.Path)
+
+	return endpoint, nil
+}
+
+// escapePath percent-encodes every byte of a path except unreserved characters and slashes
+func escapePath(p string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			builder.WriteByte(c)
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("%%%02X", c))
+	}
+
+	return builder.String()
+}
+
+// do sends a signed request for an object
+func (w *S3Writer) do(method, name string, body []byte, headers http.Header) (*http.Response, error) {
+	objectURL, err := w.objectURL(name)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+
+	w.sign(req, body)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s %s failed: %w", method, w.Location(name), err)
+	}
+
+	return resp, nil
+}
+
+// responseError builds an error from an unexpected S3 response
+func (w *S3Writer) responseError(resp *http.Response, name string) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 request for %s failed with status %d: %s", w.Location(name), resp.StatusCode, strings.TrimSpace(string(message)))
+}
+
+// sign adds AWS Signature Version 4 headers to the request
+func (w *S3Writer) sign(req *http.Request, body []byte) {
+	now := w.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := hashHex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if w.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", w.config.SessionToken)
+	}
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signedHeaders = append([]string{"content-type"}, signedHeaders...)
+	}
+	if w.config.SessionToken != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, header := range signedHeaders {
+		value := req.Header.Get(header)
+		if header == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(header + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + w.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+	signature := hex.EncodeToString(hmacSHA256(signingKey(w.config.SecretAccessKey, date, w.config.Region, "s3"), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		w.config.AccessKeyID, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// signingKey derives the Signature Version 4 signing key
+func signingKey(secret, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// hmacSHA256 computes an HMAC-SHA256 digest
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// hashHex returns the hex encoded SHA-256 hash of data
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// firstEnv returns the first non-empty environment variable among names
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
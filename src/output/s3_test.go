@@ -0,0 +1,144 @@
+package output
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newFakeS3 starts a minimal in-memory S3-compatible server
+func newFakeS3(t *testing.T) (*httptest.Server, map[string]string) {
+	t.Helper()
+
+	objects := make(map[string]string)
+	var mutex sync.Mutex
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.EscapedPath()] = string(body)
+		case http.MethodDelete:
+			delete(objects, r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodGet, http.MethodHead:
+			body, ok := objects[r.URL.EscapedPath()]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Method == http.MethodGet {
+				_, _ = w.Write([]byte(body))
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, objects
+}
+
+func TestS3Writer(t *testing.T) {
+	srv, objects := newFakeS3(t)
+
+	writer, err := NewS3Writer(S3Config{
+		Bucket:          "bucket",
+		Prefix:          "/crawls/site/",
+		Endpoint:        srv.URL,
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Writer() unexpected error: %v", err)
+	}
+
+	if exists, err := writer.Exists("index.md"); err != nil || exists {
+		t.Fatalf("Exists() = %v, %v before writing", exists, err)
+	}
+
+	if _, err := writer.ReadFile("index.md"); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("ReadFile() error = %v, want ErrNotExist", err)
+	}
+
+	if err := writer.WriteFile("docs/a+b.md", []byte("# Page")); err != nil {
+		t.Fatalf("WriteFile() unexpected error: %v", err)
+	}
+
+	if objects["/bucket/crawls/site/docs/a%2Bb.md"] != "# Page" {
+		t.Fatalf("object not stored under the expected key: %v", objects)
+	}
+
+	data, err := writer.ReadFile("docs/a+b.md")
+	if err != nil || string(data) != "# Page" {
+		t.Fatalf("ReadFile() = %q, %v", data, err)
+	}
+
+	if exists, err := writer.Exists("docs/a+b.md"); err != nil || !exists {
+		t.Fatalf("Exists() = %v, %v after writing", exists, err)
+	}
+
+	if got := writer.Location("index.md"); got != "s3://bucket/crawls/site/index.md" {
+		t.Errorf("Location() = %q", got)
+	}
+
+	if err := writer.Remove("docs/a+b.md"); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if exists, err := writer.Exists("docs/a+b.md"); err != nil || exists {
+		t.Fatalf("Exists() = %v, %v after removing", exists, err)
+	}
+}
+
+func TestNewS3WriterRequiresCredentials(t *testing.T) {
+	if _, err := NewS3Writer(S3Config{Bucket: "bucket"}); err == nil {
+		t.Errorf("NewS3Writer() expected error without credentials")
+	}
+
+	if _, err := NewS3Writer(S3Config{AccessKeyID: "key", SecretAccessKey: "secret"}); err == nil {
+		t.Errorf("NewS3Writer() expected error without bucket")
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20150830", "us-east-1", "iam")
+
+	want := "c4afb1cc5771d871763a393e44b703571b55cc28424d1a5e86da6ed3c154a4b9"
+	if got := hex.EncodeToString(key); got != want {
+		t.Errorf("signingKey() = %s, want %s", got, want)
+	}
+}
+
+func TestVirtualHostedURL(t *testing.T) {
+	writer, err := NewS3Writer(S3Config{
+		Bucket:          "bucket",
+		Region:          "eu-west-1",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Writer() unexpected error: %v", err)
+	}
+
+	objectURL, err := writer.objectURL("index.md")
+	if err != nil {
+		t.Fatalf("objectURL() unexpected error: %v", err)
+	}
+
+	if got := objectURL.String(); got != "https://bucket.s3.eu-west-1.amazonaws.com/index.md" {
+		t.Errorf("objectURL() = %s", got)
+	}
+}
@@ -0,0 +1,223 @@
+// Package pagebuffer holds the pages of a crawl until they are saved, in memory or spilled to a database file
+// so that crawls of hundreds of thousands of pages do not exhaust memory
+package pagebuffer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buffer stores values by key
+type Buffer[T any] interface {
+	// Put stores the value of a key, replacing any previous value
+	Put(key string, value T) error
+	// Get returns the value of a key and whether it is stored
+	Get(key string) (T, bool, error)
+	// Delete removes a key
+	Delete(key string) error
+	// Keys returns the stored keys in ascending order
+	Keys() []string
+	// Len returns the number of stored keys
+	Len() int
+	// Close releases the buffer and its files
+	Close() error
+}
+
+// Memory is a Buffer kept in memory, the default for small crawls
+type Memory[T any] struct {
+	mutex  sync.Mutex
+	values map[string]T
+}
+
+// NewMemory creates an empty in-memory buffer
+func NewMemory[T any]() *Memory[T] {
+	return &Memory[T]{values: make(map[string]T)}
+}
+
+// Put stores the value of a key
+func (m *Memory[T]) Put(key string, value T) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.values[key] = value
+	return nil
+}
+
+// Get returns the value of a key
+func (m *Memory[T]) Get(key string) (T, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	value, ok := m.values[key]
+	return value, ok, nil
+}
+
+// Delete removes a key
+func (m *Memory[T]) Delete(key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.values, key)
+	return nil
+}
+
+// Keys returns the stored keys in ascending order
+func (m *Memory[T]) Keys() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	keys := make([]string, 0, len(m.values))
+	for key := range m.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Len returns the number of stored keys
+func (m *Memory[T]) Len() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.values)
+}
+
+// Close does nothing
+func (m *Memory[T]) Close() error {
+	return nil
+}
+
+// bucket holds the values of a disk buffer
+var bucket = []byte("values")
+
+// Disk is a Buffer spilled to a temporary bbolt database, values are stored as JSON.
+// Only the keys are kept in memory.
+type Disk[T any] struct {
+	db   *bolt.DB
+	path string
+
+	mutex sync.Mutex
+	keys  map[string]struct{}
+}
+
+// OpenDisk creates a buffer in a temporary database file under dir, which is created if needed.
+// The file is removed when the buffer is closed.
+func OpenDisk[T any](dir string) (*Disk[T], error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create buffer directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, "crawldown-buffer-*.db")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create buffer file: %w", err)
+	}
+	path := file.Name()
+	_ = file.Close()
+
+	// Writes are not synced, the buffer does not outlive the process
+	db, err := bolt.Open(path, 0o600, &bolt.Options{NoSync: true, NoFreelistSync: true})
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("failed to open buffer file: %w", err)
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	}); err != nil {
+		_ = db.Close()
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("failed to initialize buffer file: %w", err)
+	}
+
+	return &Disk[T]{db: db, path: path, keys: make(map[string]struct{})}, nil
+}
+
+// Put stores the value of a key
+func (d *Disk[T]) Put(key string, value T) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	if err := d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), data)
+	}); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+
+	d.mutex.Lock()
+	d.keys[key] = struct{}{}
+	d.mutex.Unlock()
+	return nil
+}
+
+// Get returns the value of a key
+func (d *Disk[T]) Get(key string) (T, bool, error) {
+	var value T
+	var data []byte
+	if err := d.db.View(func(tx *bolt.Tx) error {
+		// The data is only valid during the transaction
+		if stored := tx.Bucket(bucket).Get([]byte(key)); stored != nil {
+			data = append([]byte(nil), stored...)
+		}
+		return nil
+	}); err != nil {
+		return value, false, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if data == nil {
+		return value, false, nil
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, false, fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Delete removes a key
+func (d *Disk[T]) Delete(key string) error {
+	if err := d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete([]byte(key))
+	}); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+
+	d.mutex.Lock()
+	delete(d.keys, key)
+	d.mutex.Unlock()
+	return nil
+}
+
+// Keys returns the stored keys in ascending order
+func (d *Disk[T]) Keys() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	keys := make([]string, 0, len(d.keys))
+	for key := range d.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Len returns the number of stored keys
+func (d *Disk[T]) Len() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return len(d.keys)
+}
+
+// Close closes and removes the database file
+func (d *Disk[T]) Close() error {
+	return errors.Join(d.db.Close(), os.Remove(d.path))
+}
+
+// Open returns a disk buffer under dir, or an in-memory buffer when dir is empty
+func Open[T any](dir string) (Buffer[T], error) {
+	if dir == "" {
+		return NewMemory[T](), nil
+	}
+	return OpenDisk[T](dir)
+}
@@ -0,0 +1,72 @@
+package pagebuffer
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+type record struct {
+	Title string
+	Links []string
+}
+
+func TestBuffer(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		dir  string
+	}{
+		{name: "memory"},
+		{name: "disk", dir: dir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer, err := Open[record](tt.dir)
+			if err != nil {
+				t.Fatalf("Open() unexpected error: %v", err)
+			}
+
+			for _, key := range []string{"b", "a", "c"} {
+				if err := buffer.Put(key, record{Title: key, Links: []string{key + "/1"}}); err != nil {
+					t.Fatalf("Put(%q) unexpected error: %v", key, err)
+				}
+			}
+			if err := buffer.Put("a", record{Title: "A"}); err != nil {
+				t.Fatalf("Put(\"a\") unexpected error: %v", err)
+			}
+			if err := buffer.Delete("c"); err != nil {
+				t.Fatalf("Delete(\"c\") unexpected error: %v", err)
+			}
+
+			if keys := buffer.Keys(); !reflect.DeepEqual(keys, []string{"a", "b"}) || buffer.Len() != 2 {
+				t.Errorf("Keys() = %v, Len() = %d, want [a b] and 2", keys, buffer.Len())
+			}
+
+			got, ok, err := buffer.Get("b")
+			if err != nil || !ok || !reflect.DeepEqual(got, record{Title: "b", Links: []string{"b/1"}}) {
+				t.Errorf("Get(\"b\") = %+v, %v, %v, want the stored record", got, ok, err)
+			}
+			if got, ok, err := buffer.Get("a"); err != nil || !ok || got.Title != "A" {
+				t.Errorf("Get(\"a\") = %+v, %v, %v, want the replaced record", got, ok, err)
+			}
+			if _, ok, err := buffer.Get("c"); err != nil || ok {
+				t.Errorf("Get(\"c\") = %v, %v, want a deleted key to be missing", ok, err)
+			}
+
+			if err := buffer.Close(); err != nil {
+				t.Fatalf("Close() unexpected error: %v", err)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read buffer directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("buffer directory holds %d files after Close(), want none", len(entries))
+	}
+}
@@ -0,0 +1,105 @@
+package postprocess
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxErrorBody is the number of bytes of an error response included in errors
+const maxErrorBody = 1024
+
+// fencedReply matches replies that wrap the whole page in a code fence
+var fencedReply = regexp.MustCompile("^```(?:markdown|md)?\\n([\\s\\S]*)\\n```$")
+
+// APIProcessor sends each page to the chat completions endpoint of an OpenAI-compatible API,
+// with the prompt as the system message and the Markdown as the user message
+type APIProcessor struct {
+	BaseURL string // API root such as https://api.openai.com/v1 or http://localhost:11434/v1
+	Model   string
+	Prompt  string
+	APIKey  string // Sent as a bearer token when set
+
+	client *http.Client
+}
+
+// NewAPIProcessor creates a processor calling the API at baseURL, reading the API key from OPENAI_API_KEY
+func NewAPIProcessor(baseURL, model, prompt string, timeout time.Duration) *APIProcessor {
+	return &APIProcessor{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Model:   model,
+		Prompt:  prompt,
+		APIKey:  os.Getenv("OPENAI_API_KEY"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Process asks the model to rewrite a page
+func (p *APIProcessor) Process(page Page) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: p.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: p.Prompt},
+			{Role: "user", Content: page.Markdown},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode chat request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create chat request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call chat API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return "", fmt.Errorf("chat API returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var reply chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return "", fmt.Errorf("failed to decode chat response: %w", err)
+	}
+	if len(reply.Choices) == 0 {
+		return "", fmt.Errorf("chat API returned no choices")
+	}
+
+	content := strings.TrimSpace(reply.Choices[0].Message.Content)
+	if match := fencedReply.FindStringSubmatch(content); match != nil {
+		content = match[1]
+	}
+	return checkOutput(content)
+}
@@ -0,0 +1,47 @@
+package postprocess
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/shell"
+)
+
+// CommandProcessor runs a shell command for each page, with the Markdown on standard input
+// and the URL and title in the CRAWLDOWN_URL and CRAWLDOWN_TITLE environment variables.
+// The standard output of the command replaces the Markdown; a non-zero exit status fails the page.
+type CommandProcessor struct {
+	Command string
+	Timeout time.Duration // Time limit of each run, none when zero
+}
+
+// Process runs the command for a page
+func (p CommandProcessor) Process(page Page) (string, error) {
+	ctx := context.Background()
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	cmd := shell.Command(ctx, p.Command)
+	cmd.Env = append(os.Environ(), "CRAWLDOWN_URL="+page.URL, "CRAWLDOWN_TITLE="+page.Title)
+	cmd.Stdin = strings.NewReader(page.Markdown)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("failed to run post-processing command: %w: %s", err, message)
+		}
+		return "", fmt.Errorf("failed to run post-processing command: %w", err)
+	}
+
+	return checkOutput(stdout.String())
+}
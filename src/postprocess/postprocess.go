@@ -0,0 +1,80 @@
+// Package postprocess passes the Markdown of converted pages through an external command or an
+// OpenAI-compatible chat completions API, e.g. to summarize, translate, or clean it, before pages are written
+package postprocess
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Page is a converted page handed to a processor
+type Page struct {
+	URL      string
+	Title    string
+	Markdown string
+}
+
+// Processor rewrites the Markdown of a page
+type Processor interface {
+	// Process returns the new Markdown of the page
+	Process(page Page) (string, error)
+}
+
+// Limit wraps a processor so that at most workers pages are processed at once and at most perSecond
+// are started each second. perSecond 0 does not limit the rate.
+func Limit(processor Processor, workers int, perSecond float64) Processor {
+	limited := &limitedProcessor{
+		processor: processor,
+		slots:     make(chan struct{}, max(workers, 1)),
+	}
+	if perSecond > 0 {
+		limited.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+	return limited
+}
+
+// limitedProcessor bounds the concurrency and the rate of the calls to a processor
+type limitedProcessor struct {
+	processor Processor
+	slots     chan struct{}
+	interval  time.Duration
+
+	mutex sync.Mutex
+	next  time.Time // Earliest start of the next call
+}
+
+// Process waits for a free slot and for the rate limit, then processes the page
+func (l *limitedProcessor) Process(page Page) (string, error) {
+	l.slots <- struct{}{}
+	defer func() { <-l.slots }()
+
+	l.wait()
+	return l.processor.Process(page)
+}
+
+// wait sleeps until the next call may start and reserves the following start time
+func (l *limitedProcessor) wait() {
+	if l.interval == 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	start := time.Now()
+	if l.next.After(start) {
+		start = l.next
+	}
+	l.next = start.Add(l.interval)
+	l.mutex.Unlock()
+
+	time.Sleep(time.Until(start))
+}
+
+// checkOutput rejects empty results, so that a failing processor does not blank pages
+func checkOutput(markdown string) (string, error) {
+	if strings.TrimSpace(markdown) == "" {
+		return "", fmt.Errorf("processor returned no Markdown")
+	}
+	return markdown, nil
+}
@@ -0,0 +1,123 @@
+package postprocess
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCommandProcessor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	processor := CommandProcessor{Command: `tr a-z A-Z; printf '\n%s' "$CRAWLDOWN_URL"`}
+	got, err := processor.Process(Page{URL: "https://example.com/", Markdown: "hello"})
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if want := "HELLO\nhttps://example.com/"; got != want {
+		t.Errorf("Process() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		wantErr string
+	}{
+		{name: "failing command", command: "echo broken >&2; exit 3", wantErr: "broken"},
+		{name: "empty output", command: "cat > /dev/null", wantErr: "no Markdown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CommandProcessor{Command: tt.command}.Process(Page{Markdown: "hello"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Process() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIProcessor(t *testing.T) {
+	var request chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"error": "unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "` + "```markdown\\n# Cleaned\\n```" + `"}}]}`))
+	}))
+	defer srv.Close()
+
+	processor := NewAPIProcessor(srv.URL+"/v1/", "test-model", "Clean up the page.", time.Minute)
+	processor.APIKey = "secret"
+
+	got, err := processor.Process(Page{URL: srv.URL, Markdown: "# Page"})
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got != "# Cleaned" {
+		t.Errorf("Process() = %q, want the reply without its code fence", got)
+	}
+	if request.Model != "test-model" || len(request.Messages) != 2 || request.Messages[0].Content != "Clean up the page." || request.Messages[1].Content != "# Page" {
+		t.Errorf("request = %+v, want the prompt and the page as messages", request)
+	}
+
+	processor.APIKey = ""
+	if _, err := processor.Process(Page{Markdown: "# Page"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Process() error = %v, want the status of the response", err)
+	}
+}
+
+type countingProcessor struct {
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (p *countingProcessor) Process(page Page) (string, error) {
+	running := p.running.Add(1)
+	defer p.running.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if running <= peak || p.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return page.Markdown, nil
+}
+
+func TestLimit(t *testing.T) {
+	counting := &countingProcessor{}
+	processor := Limit(counting, 2, 100)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Go(func() {
+			if _, err := processor.Process(Page{Markdown: "page"}); err != nil {
+				t.Errorf("Process() unexpected error: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if peak := counting.peak.Load(); peak > 2 {
+		t.Errorf("%d pages processed at once, want at most 2", peak)
+	}
+	// Six calls at 100 per second start at least 50ms apart from the first to the last
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("6 calls took %v, want the rate limit to space them out", elapsed)
+	}
+}
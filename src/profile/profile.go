@@ -0,0 +1,153 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileExtension is the extension of profile files
+const fileExtension = ".json"
+
+// ErrNotFound is returned when a profile does not exist
+var ErrNotFound = errors.New("profile not found")
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Profile is a saved set of crawl flags and an optional start URL
+type Profile struct {
+	Name    string              `json:"name"`
+	URL     string              `json:"url,omitempty"`
+	Flags   map[string][]string `json:"flags"`
+	SavedAt time.Time           `json:"saved_at"`
+}
+
+// Store reads and writes profiles as JSON files in a directory
+type Store struct {
+	Dir string
+}
+
+// DefaultDir returns the profile directory inside the user configuration directory
+func DefaultDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "crawldown", "profiles"), nil
+}
+
+// NewStore creates a store for the given directory
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// ValidateName checks that a profile name is safe to use as a file name
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// Save writes a profile, replacing any existing profile with the same name
+func (s *Store) Save(p Profile) error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	if err := os.WriteFile(s.path(p.Name), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the profile with the given name
+func (s *Store) Load(name string) (Profile, error) {
+	if err := ValidateName(name); err != nil {
+		return Profile{}, err
+	}
+
+	//nolint:gosec // Profile names are validated and resolved inside the profile directory.
+	data, err := os.ReadFile(s.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Profile{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return Profile{}, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Profile{}, fmt.Errorf("failed to parse profile %s: %w", name, err)
+	}
+	p.Name = name
+
+	return p, nil
+}
+
+// List returns all saved profiles sorted by name
+func (s *Store) List() ([]Profile, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var profiles []Profile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExtension) {
+			continue
+		}
+
+		p, err := s.Load(strings.TrimSuffix(entry.Name(), fileExtension))
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	return profiles, nil
+}
+
+// Delete removes the profile with the given name
+func (s *Store) Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.path(name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) path(name string) string {
+	return filepath.Join(s.Dir, name+fileExtension)
+}
@@ -0,0 +1,81 @@
+package profile
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	saved := Profile{
+		Name:  "docs",
+		URL:   "https://example.com/docs",
+		Flags: map[string][]string{"depth": {"3"}, "exclude": {"/admin", "/private"}},
+	}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save(Profile{Name: "blog", URL: "https://example.com/blog"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := store.Load("docs")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.URL != saved.URL || len(loaded.Flags["exclude"]) != 2 {
+		t.Errorf("Load() = %+v, want %+v", loaded, saved)
+	}
+
+	profiles, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(profiles) != 2 || profiles[0].Name != "blog" || profiles[1].Name != "docs" {
+		t.Errorf("List() = %+v, want blog and docs", profiles)
+	}
+
+	if err := store.Delete("docs"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Load("docs"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() after Delete() error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("docs"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete() of missing profile error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestStoreListMissingDir(t *testing.T) {
+	profiles, err := NewStore(t.TempDir() + "/missing").List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(profiles) != 0 {
+		t.Errorf("List() = %+v, want no profiles", profiles)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "docs"},
+		{name: "my-site_v2.prod"},
+		{name: "", wantErr: true},
+		{name: "../escape", wantErr: true},
+		{name: "a/b", wantErr: true},
+		{name: ".hidden", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Filename is the name of the progress file written into the output directory
+const Filename = "progress.json"
+
+// Crawl phases
+const (
+	PhaseCrawling = "crawling"
+	PhaseSaving   = "saving"
+	PhaseDone     = "done"
+)
+
+// recentLimit is the number of recent URLs and errors kept in snapshots
+const recentLimit = 10
+
+// ErrorEntry describes a failed URL
+type ErrorEntry struct {
+	URL   string    `json:"url"`
+	Error string    `json:"error"`
+	At    time.Time `json:"at"`
+}
+
+// Snapshot is the state of a crawl at a point in time
+type Snapshot struct {
+	Phase          string       `json:"phase"`
+	StartedAt      time.Time    `json:"started_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	PagesCrawled   int          `json:"pages_crawled"`
+	PagesSaved     int          `json:"pages_saved"`
+	PagesTotal     int          `json:"pages_total,omitempty"`
+	Errors         int          `json:"errors"`
+	PagesPerSecond float64      `json:"pages_per_second"`
+	ETASeconds     *float64     `json:"eta_seconds,omitempty"`
+	LastURLs       []string     `json:"last_urls"`
+	LastErrors     []ErrorEntry `json:"last_errors"`
+}
+
+// Tracker collects crawl progress from concurrent callbacks
+type Tracker struct {
+	mutex        sync.Mutex
+	now          func() time.Time
+	phase        string
+	startedAt    time.Time
+	phaseStarted time.Time
+	crawled      int
+	saved        int
+	total        int
+	errors       int
+	lastURLs     []string
+	lastErrors   []ErrorEntry
+}
+
+// NewTracker creates a tracker in the crawling phase
+func NewTracker() *Tracker {
+	return newTrackerWithClock(time.Now)
+}
+
+func newTrackerWithClock(now func() time.Time) *Tracker {
+	startedAt := now().UTC()
+	return &Tracker{
+		now:          now,
+		phase:        PhaseCrawling,
+		startedAt:    startedAt,
+		phaseStarted: startedAt,
+	}
+}
+
+// Crawled records a fetched page
+func (t *Tracker) Crawled(pageURL string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.crawled++
+	t.lastURLs = appendRecent(t.lastURLs, pageURL)
+}
+
+// Saved records a page written to the output
+func (t *Tracker) Saved(pageURL string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.saved++
+	t.lastURLs = appendRecent(t.lastURLs, pageURL)
+}
+
+// Failed records a failed URL
+func (t *Tracker) Failed(pageURL string, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.errors++
+	t.lastErrors = append(t.lastErrors, ErrorEntry{URL: pageURL, Error: err.Error(), At: t.now().UTC()})
+	if len(t.lastErrors) > recentLimit {
+		t.lastErrors = t.lastErrors[len(t.lastErrors)-recentLimit:]
+	}
+}
+
+// StartSaving switches to the saving phase with the number of pages to save
+func (t *Tracker) StartSaving(total int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.phase = PhaseSaving
+	t.phaseStarted = t.now().UTC()
+	t.total = total
+}
+
+// Finish switches to the done phase
+func (t *Tracker) Finish() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.phase = PhaseDone
+}
+
+// Snapshot returns the current progress.
+// The rate covers the current phase; the ETA is only known while saving, when the total is known.
+func (t *Tracker) Snapshot() Snapshot {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now().UTC()
+	snapshot := Snapshot{
+		Phase:        t.phase,
+		StartedAt:    t.startedAt,
+		UpdatedAt:    now,
+		PagesCrawled: t.crawled,
+		PagesSaved:   t.saved,
+		PagesTotal:   t.total,
+		Errors:       t.errors,
+		LastURLs:     append([]string{}, t.lastURLs...),
+		LastErrors:   append([]ErrorEntry{}, t.lastErrors...),
+	}
+
+	done := t.crawled
+	if t.phase != PhaseCrawling {
+		done = t.saved
+	}
+
+	elapsed := now.Sub(t.phaseStarted).Seconds()
+	if elapsed > 0 {
+		snapshot.PagesPerSecond = float64(done) / elapsed
+	}
+
+	if t.phase == PhaseSaving && snapshot.PagesPerSecond > 0 {
+		eta := float64(t.total-t.saved) / snapshot.PagesPerSecond
+		snapshot.ETASeconds = &eta
+	}
+
+	return snapshot
+}
+
+// Encode returns the JSON representation of the current progress
+func (t *Tracker) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(t.Snapshot(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode progress: %w", err)
+	}
+	return data, nil
+}
+
+// Run calls write with the encoded progress every interval until the context is canceled
+func (t *Tracker) Run(ctx context.Context, interval time.Duration, write func(data []byte)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			data, err := t.Encode()
+			if err != nil {
+				continue
+			}
+			write(data)
+		}
+	}
+}
+
+func appendRecent(items []string, item string) []string {
+	items = append(items, item)
+	if len(items) > recentLimit {
+		items = items[len(items)-recentLimit:]
+	}
+	return items
+}
@@ -0,0 +1,89 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.current
+}
+
+func TestTrackerSnapshot(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tracker := newTrackerWithClock(clock.now)
+
+	for i := 0; i < 12; i++ {
+		tracker.Crawled(fmt.Sprintf("https://example.com/%d", i))
+	}
+	tracker.Failed("https://example.com/broken", errors.New("not found"))
+	clock.current = clock.current.Add(4 * time.Second)
+
+	snapshot := tracker.Snapshot()
+	if snapshot.Phase != PhaseCrawling || snapshot.PagesCrawled != 12 || snapshot.Errors != 1 {
+		t.Errorf("Snapshot() = %+v, want crawling phase with 12 pages and 1 error", snapshot)
+	}
+	if snapshot.PagesPerSecond != 3 {
+		t.Errorf("PagesPerSecond = %v, want 3", snapshot.PagesPerSecond)
+	}
+	if snapshot.ETASeconds != nil {
+		t.Errorf("ETASeconds = %v, want nil while crawling", *snapshot.ETASeconds)
+	}
+	if len(snapshot.LastURLs) != recentLimit || snapshot.LastURLs[recentLimit-1] != "https://example.com/11" {
+		t.Errorf("LastURLs = %v, want the last %d URLs", snapshot.LastURLs, recentLimit)
+	}
+
+	tracker.StartSaving(10)
+	tracker.Saved("https://example.com/0")
+	tracker.Saved("https://example.com/1")
+	clock.current = clock.current.Add(time.Second)
+
+	snapshot = tracker.Snapshot()
+	if snapshot.Phase != PhaseSaving || snapshot.PagesTotal != 10 || snapshot.PagesSaved != 2 {
+		t.Errorf("Snapshot() = %+v, want saving phase with 2 of 10 pages", snapshot)
+	}
+	if snapshot.ETASeconds == nil || *snapshot.ETASeconds != 4 {
+		t.Errorf("ETASeconds = %v, want 4", snapshot.ETASeconds)
+	}
+
+	tracker.Finish()
+	if got := tracker.Snapshot().Phase; got != PhaseDone {
+		t.Errorf("Phase = %q, want %q", got, PhaseDone)
+	}
+}
+
+func TestTrackerRun(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Crawled("https://example.com/")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var once sync.Once
+	written := make(chan []byte, 1)
+	go tracker.Run(ctx, 10*time.Millisecond, func(data []byte) {
+		once.Do(func() { written <- data })
+	})
+
+	select {
+	case data := <-written:
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			t.Fatalf("failed to decode progress: %v", err)
+		}
+		if snapshot.PagesCrawled != 1 {
+			t.Errorf("PagesCrawled = %d, want 1", snapshot.PagesCrawled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not write progress")
+	}
+}
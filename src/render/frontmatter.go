@@ -0,0 +1,234 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types a front matter value is converted to
+const (
+	FieldString = "string"
+	FieldInt    = "int"
+	FieldFloat  = "float"
+	FieldBool   = "bool"
+	FieldDate   = "date"
+	FieldList   = "list"
+)
+
+// metadataFields are the sources of front matter fields read from the page metadata, by JSON name
+var metadataFields = map[string]func(data Data) string{
+	"title":          func(data Data) string { return data.Metadata.Title },
+	"description":    func(data Data) string { return data.Metadata.Description },
+	"image":          func(data Data) string { return data.Metadata.Image },
+	"type":           func(data Data) string { return data.Metadata.Type },
+	"site_name":      func(data Data) string { return data.Metadata.SiteName },
+	"author":         func(data Data) string { return data.Metadata.Author },
+	"published_time": func(data Data) string { return data.Metadata.PublishedTime },
+	"modified_time":  func(data Data) string { return data.Metadata.ModifiedTime },
+	"page.title":     func(data Data) string { return data.Title },
+	"page.url":       func(data Data) string { return data.URL },
+	"page.path":      func(data Data) string { return data.Path },
+	"page.section":   func(data Data) string { return data.Section },
+	"page.lang":      func(data Data) string { return data.Lang },
+	"page.canonical": func(data Data) string { return data.Canonical },
+	"page.summary":   func(data Data) string { return data.Summary },
+	"page.fetched_at": func(data Data) string {
+		if data.FetchedAt.IsZero() {
+			return ""
+		}
+		return data.FetchedAt.UTC().Format(time.RFC3339)
+	},
+}
+
+// dateLayouts are the formats dates found in metadata are parsed with
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+}
+
+// FrontMatterField maps a piece of page metadata to a front matter key
+type FrontMatterField struct {
+	// From is a metadata field (title, description, image, type, site_name, author, published_time, modified_time),
+	// an og:* or twitter:* property, or a page field (page.title, page.url, page.path, page.section, page.lang,
+	// page.canonical, page.summary, page.fetched_at)
+	From string `json:"from"`
+	// To is the front matter key
+	To string `json:"to"`
+	// Type converts the value: string (default), int, float, bool, date, or list (comma-separated values)
+	Type string `json:"type,omitempty"`
+	// Format is the Go time layout of date values, RFC 3339 by default
+	Format string `json:"format,omitempty"`
+}
+
+// Validate checks that the field mapping is well formed
+func (f FrontMatterField) Validate() error {
+	if _, ok := metadataFields[f.From]; !ok && !strings.HasPrefix(f.From, "og:") && !strings.HasPrefix(f.From, "twitter:") {
+		return fmt.Errorf("unknown metadata field %q", f.From)
+	}
+
+	if f.To == "" || strings.ContainsAny(f.To, ":#\n") || strings.TrimSpace(f.To) != f.To {
+		return fmt.Errorf("invalid front matter key %q", f.To)
+	}
+
+	switch f.Type {
+	case "", FieldString, FieldInt, FieldFloat, FieldBool, FieldDate, FieldList:
+	default:
+		return fmt.Errorf("unknown type %q (expected %s, %s, %s, %s, %s, or %s)", f.Type, FieldString, FieldInt, FieldFloat, FieldBool, FieldDate, FieldList)
+	}
+
+	if f.Format != "" && f.Type != FieldDate {
+		return fmt.Errorf("format requires the %s type", FieldDate)
+	}
+
+	return nil
+}
+
+// source returns the metadata value the field reads
+func (f FrontMatterField) source(data Data) string {
+	if get, ok := metadataFields[f.From]; ok {
+		return strings.TrimSpace(get(data))
+	}
+	if property, ok := strings.CutPrefix(f.From, "og:"); ok {
+		return strings.TrimSpace(data.Metadata.OpenGraph[property])
+	}
+	return strings.TrimSpace(data.Metadata.Twitter[strings.TrimPrefix(f.From, "twitter:")])
+}
+
+// yaml returns the value of the field for a page as YAML, reporting false when the page has no value
+// or it cannot be converted to the type of the field
+func (f FrontMatterField) yaml(data Data) (string, bool) {
+	value := f.source(data)
+	if value == "" {
+		return "", false
+	}
+
+	switch f.Type {
+	case FieldInt:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", false
+		}
+		return " " + strconv.FormatInt(number, 10), true
+	case FieldFloat:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", false
+		}
+		return " " + strconv.FormatFloat(number, 'f', -1, 64), true
+	case FieldBool:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", false
+		}
+		return " " + strconv.FormatBool(flag), true
+	case FieldDate:
+		date, ok := parseDate(value)
+		if !ok {
+			return "", false
+		}
+		// RFC 3339 dates are YAML timestamps, other layouts are written as strings
+		if f.Format == "" {
+			return " " + date.Format(time.RFC3339), true
+		}
+		return " " + strconv.Quote(date.Format(f.Format)), true
+	case FieldList:
+		var builder strings.Builder
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				builder.WriteString("\n  - " + strconv.Quote(item))
+			}
+		}
+		return builder.String(), builder.Len() > 0
+	default:
+		return " " + strconv.Quote(value), true
+	}
+}
+
+// parseDate parses a date in one of the layouts commonly found in metadata
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ApplyFrontMatter sets the mapped fields in the front matter of a rendered page, replacing the fields
+// of the flavor or template with the same key, and adds a front matter block to pages without one.
+// Fields without a value for the page are left out.
+func ApplyFrontMatter(content string, fields []FrontMatterField, data Data) string {
+	keys := make(map[string]bool, len(fields))
+	var mapped strings.Builder
+	for _, field := range fields {
+		if keys[field.To] {
+			continue
+		}
+		value, ok := field.yaml(data)
+		if !ok {
+			continue
+		}
+		keys[field.To] = true
+		mapped.WriteString(field.To + ":" + value + "\n")
+	}
+	if mapped.Len() == 0 {
+		return content
+	}
+
+	header, body, found := splitFrontMatter(content)
+	if !found {
+		return "---\n" + mapped.String() + "---\n\n" + content
+	}
+
+	var builder strings.Builder
+	builder.WriteString("---\n")
+	replaced := false
+	for _, line := range strings.SplitAfter(header, "\n") {
+		if line == "" {
+			continue
+		}
+		// Indented lines and list items belong to the key above them
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "-") {
+			if !replaced {
+				builder.WriteString(line)
+			}
+			continue
+		}
+		key, _, _ := strings.Cut(line, ":")
+		replaced = keys[strings.Trim(strings.TrimSpace(key), `"'`)]
+		if !replaced {
+			builder.WriteString(line)
+		}
+	}
+	builder.WriteString(mapped.String())
+	builder.WriteString("---\n")
+	builder.WriteString(body)
+	return builder.String()
+}
+
+// splitFrontMatter returns the lines of a leading YAML front matter block and the content after it
+func splitFrontMatter(content string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(content, "---\n")
+	if !ok {
+		return "", content, false
+	}
+	if body, ok := strings.CutPrefix(rest, "---\n"); ok {
+		return "", body, true
+	}
+
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		return "", content, false
+	}
+	return rest[:end+1], rest[end+len("\n---\n"):], true
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/metadata"
+)
+
+func TestFrontMatterFieldValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   FrontMatterField
+		wantErr bool
+	}{
+		{name: "metadata field", field: FrontMatterField{From: "description", To: "summary"}},
+		{name: "open graph property", field: FrontMatterField{From: "og:locale", To: "locale"}},
+		{name: "page field", field: FrontMatterField{From: "page.url", To: "source"}},
+		{name: "date with format", field: FrontMatterField{From: "published_time", To: "date", Type: FieldDate, Format: "2006-01-02"}},
+		{name: "unknown source", field: FrontMatterField{From: "keywords", To: "tags"}, wantErr: true},
+		{name: "empty key", field: FrontMatterField{From: "description"}, wantErr: true},
+		{name: "key with colon", field: FrontMatterField{From: "description", To: "a: b"}, wantErr: true},
+		{name: "unknown type", field: FrontMatterField{From: "description", To: "summary", Type: "text"}, wantErr: true},
+		{name: "format without date", field: FrontMatterField{From: "description", To: "summary", Format: "2006"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyFrontMatter(t *testing.T) {
+	data := NewData("https://example.com/blog/post", "Post", "blog-post.md", "Body", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	data.Metadata = metadata.Metadata{
+		Description:   `A "quoted" summary`,
+		PublishedTime: "2024-04-30T08:15:00+02:00",
+		OpenGraph:     map[string]string{"description": "From Open Graph", "article:tag": "go, crawling,", "rating": "4.5"},
+		Twitter:       map[string]string{"label1": "Reading time"},
+	}
+
+	fields := []FrontMatterField{
+		{From: "og:description", To: "summary"},
+		{From: "published_time", To: "date", Type: FieldDate},
+		{From: "published_time", To: "day", Type: FieldDate, Format: "2006-01-02"},
+		{From: "og:article:tag", To: "tags", Type: FieldList},
+		{From: "og:rating", To: "rating", Type: FieldFloat},
+		{From: "og:rating", To: "stars", Type: FieldInt},
+		{From: "author", To: "author"},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "page without front matter",
+			content: "# Post\n\nBody",
+			want: "---\nsummary: \"From Open Graph\"\ndate: 2024-04-30T08:15:00+02:00\nday: \"2024-04-30\"\n" +
+				"tags:\n  - \"go\"\n  - \"crawling\"\nrating: 4.5\n---\n\n# Post\n\nBody",
+		},
+		{
+			name:    "flavor front matter with replaced fields",
+			content: "---\ntitle: \"Post\"\ndate: 2024-05-01T10:00:00Z\ntags:\n  - example-com\nsummary: \"old\"\n---\n\nBody",
+			want: "---\ntitle: \"Post\"\nsummary: \"From Open Graph\"\ndate: 2024-04-30T08:15:00+02:00\nday: \"2024-04-30\"\n" +
+				"tags:\n  - \"go\"\n  - \"crawling\"\nrating: 4.5\n---\n\nBody",
+		},
+		{
+			name:    "empty front matter",
+			content: "---\n---\nBody",
+			want: "---\nsummary: \"From Open Graph\"\ndate: 2024-04-30T08:15:00+02:00\nday: \"2024-04-30\"\n" +
+				"tags:\n  - \"go\"\n  - \"crawling\"\nrating: 4.5\n---\nBody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyFrontMatter(tt.content, fields, data); got != tt.want {
+				t.Errorf("ApplyFrontMatter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := ApplyFrontMatter("Body", []FrontMatterField{{From: "author", To: "author"}}, data); got != "Body" {
+		t.Errorf("ApplyFrontMatter() = %q, want the page unchanged when no field has a value", got)
+	}
+}
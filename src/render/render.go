@@ -0,0 +1,194 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sandrolain/crawldown/src/metadata"
+	"github.com/sandrolain/crawldown/src/urlmatch"
+)
+
+// Data is the information available to page templates
+type Data struct {
+	URL       string
+	Title     string
+	Path      string
+	Section   string
+	File      string
+	Lang      string // Primary language subtag, empty if unknown
+	Markdown  string
+	FetchedAt time.Time
+	Status    int      // HTTP status code of the response
+	Tags      []string // Tags assigned by the tag rules of the configuration
+	Summary   string   // Extractive summary of the page, empty unless summaries are enabled
+
+	Crawl Crawl // The crawl that saved the page
+
+	Canonical  string            // Canonical URL declared by the page, empty if none
+	Alternates map[string]string // hreflang alternates keyed by language
+	Metadata   metadata.Metadata // Open Graph, Twitter card, and JSON-LD metadata
+}
+
+// Crawl describes the run that saved a page
+type Crawl struct {
+	StartURL  string
+	StartedAt time.Time
+	Version   string // crawldown version
+}
+
+// Rule selects a template for pages whose URL matches a pattern
+type Rule struct {
+	Pattern      string `json:"pattern"`
+	Template     string `json:"template,omitempty"`
+	TemplateFile string `json:"template_file,omitempty"`
+}
+
+// Validate checks that the rule is well formed
+func (r Rule) Validate() error {
+	if _, err := urlmatch.Compile(r.Pattern); err != nil {
+		return err
+	}
+
+	if (r.Template == "") == (r.TemplateFile == "") {
+		return fmt.Errorf("exactly one of template or template_file must be set")
+	}
+
+	return nil
+}
+
+type compiledRule struct {
+	pattern  *urlmatch.Pattern
+	template *template.Template
+}
+
+// Renderer renders pages with the first template whose pattern matches the page URL
+type Renderer struct {
+	rules       []compiledRule
+	frontMatter []FrontMatterField
+}
+
+// NewRenderer compiles the template rules, resolving template files relative to baseDir
+func NewRenderer(rules []Rule, baseDir string) (*Renderer, error) {
+	renderer := &Renderer{}
+
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid template rule %d: %w", i, err)
+		}
+
+		source := rule.Template
+		if rule.TemplateFile != "" {
+			path := rule.TemplateFile
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(baseDir, path)
+			}
+
+			//nolint:gosec // Template files are referenced by the user-provided configuration.
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template file: %w", err)
+			}
+			source = string(data)
+		}
+
+		tmpl, err := template.New(rule.Pattern).Funcs(funcs).Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template for %q: %w", rule.Pattern, err)
+		}
+
+		renderer.rules = append(renderer.rules, compiledRule{
+			pattern:  urlmatch.MustCompile(rule.Pattern),
+			template: tmpl,
+		})
+	}
+
+	return renderer, nil
+}
+
+// Render executes the matching template, reporting false when no rule matches
+func (r *Renderer) Render(data Data) (string, bool, error) {
+	for _, rule := range r.rules {
+		if !rule.pattern.Match(data.URL) {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := rule.template.Execute(&buf, data); err != nil {
+			return "", true, fmt.Errorf("failed to render template for %q: %w", rule.pattern, err)
+		}
+
+		return buf.String(), true, nil
+	}
+
+	return "", false, nil
+}
+
+// MapFrontMatter sets the metadata fields written to the front matter of every page, see ApplyFrontMatter
+func (r *Renderer) MapFrontMatter(fields []FrontMatterField) error {
+	for i, field := range fields {
+		if err := field.Validate(); err != nil {
+			return fmt.Errorf("invalid front matter field %d: %w", i, err)
+		}
+	}
+	r.frontMatter = fields
+	return nil
+}
+
+// ApplyFrontMatter sets the mapped front matter fields in a rendered page
+func (r *Renderer) ApplyFrontMatter(content string, data Data) string {
+	if len(r.frontMatter) == 0 {
+		return content
+	}
+	return ApplyFrontMatter(content, r.frontMatter, data)
+}
+
+// NewData builds the template data for a converted page
+func NewData(pageURL, title, file, markdown string, fetchedAt time.Time) Data {
+	data := Data{
+		URL:       pageURL,
+		Title:     title,
+		File:      file,
+		Markdown:  markdown,
+		FetchedAt: fetchedAt,
+		Path:      "/",
+	}
+
+	if parsedURL, err := url.Parse(pageURL); err == nil && parsedURL.Path != "" {
+		data.Path = parsedURL.Path
+	}
+
+	segments := strings.Split(strings.Trim(data.Path, "/"), "/")
+	if len(segments) > 1 {
+		data.Section = segments[0]
+	}
+
+	return data
+}
+
+// DefaultHeader returns the header prepended to pages without a matching template
+func DefaultHeader(title, pageURL string) string {
+	return fmt.Sprintf("# %s\n\nURL: %s\n\n---\n\n", title, pageURL)
+}
+
+var funcs = template.FuncMap{
+	"date": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	"quote": func(s string) string {
+		encoded, err := json.Marshal(s)
+		if err != nil {
+			return `""`
+		}
+		return string(encoded)
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+}
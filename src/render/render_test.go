@@ -0,0 +1,119 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRendererRender(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docs.tmpl"), []byte("section: {{.Section}}\n\n{{.Markdown}}"), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	renderer, err := NewRenderer([]Rule{
+		{Pattern: "/blog/*", Template: "---\ntitle: {{quote .Title}}\ndate: {{date \"2006-01-02\" .FetchedAt}}\n---\n\n{{.Markdown}}"},
+		{Pattern: "/docs/*", TemplateFile: "docs.tmpl"},
+	}, dir)
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+
+	fetchedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		url         string
+		title       string
+		wantMatched bool
+		want        string
+	}{
+		{
+			name:        "blog post",
+			url:         "https://example.com/blog/hello",
+			title:       `Say "hi"`,
+			wantMatched: true,
+			want:        "---\ntitle: \"Say \\\"hi\\\"\"\ndate: 2024-05-01\n---\n\nBody",
+		},
+		{
+			name:        "docs page from file",
+			url:         "https://example.com/docs/guide/install",
+			title:       "Install",
+			wantMatched: true,
+			want:        "section: docs\n\nBody",
+		},
+		{
+			name:        "no match",
+			url:         "https://example.com/about",
+			title:       "About",
+			wantMatched: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := NewData(tt.url, tt.title, "page.md", "Body", fetchedAt)
+			got, matched, err := renderer.Render(data)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if matched != tt.wantMatched {
+				t.Errorf("Render() matched = %v, want %v", matched, tt.wantMatched)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{name: "inline template", rule: Rule{Pattern: "/*", Template: "x"}},
+		{name: "template file", rule: Rule{Pattern: "/*", TemplateFile: "x.tmpl"}},
+		{name: "missing pattern", rule: Rule{Template: "x"}, wantErr: true},
+		{name: "missing template", rule: Rule{Pattern: "/*"}, wantErr: true},
+		{name: "both templates", rule: Rule{Pattern: "/*", Template: "x", TemplateFile: "x.tmpl"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRendererInvalidTemplate(t *testing.T) {
+	_, err := NewRenderer([]Rule{{Pattern: "/*", Template: "{{.Missing"}}, "")
+	if err == nil {
+		t.Errorf("NewRenderer() expected error for invalid template")
+	}
+}
+
+func TestNewDataSection(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://example.com/docs/intro", want: "docs"},
+		{url: "https://example.com/about", want: ""},
+		{url: "https://example.com", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := NewData(tt.url, "", "", "", time.Time{}).Section; got != tt.want {
+				t.Errorf("Section = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
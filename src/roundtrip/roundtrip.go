@@ -0,0 +1,134 @@
+package roundtrip
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"golang.org/x/net/html"
+)
+
+// maxMissingSamples limits the number of lost words reported per page
+const maxMissingSamples = 20
+
+// Result describes how much of the source text survived a Markdown round trip
+type Result struct {
+	SourceWords   int
+	RenderedWords int
+	MissingWords  int
+	Score         float64  // Fraction of source words found after the round trip, between 0 and 1
+	Missing       []string // Most frequently lost words
+}
+
+var markdownRenderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+)
+
+// Compare renders markdown back to HTML and compares its text with the source HTML
+func Compare(sourceHTML, markdown string) (Result, error) {
+	sourceText, err := textContent(sourceHTML)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse source HTML: %w", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(markdown), &rendered); err != nil {
+		return Result{}, fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	renderedText, err := textContent(rendered.String())
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse rendered HTML: %w", err)
+	}
+
+	return compareWords(countWords(sourceText), countWords(renderedText)), nil
+}
+
+// compareWords computes the round trip result from word frequencies
+func compareWords(source, rendered map[string]int) Result {
+	result := Result{Score: 1}
+	lost := make(map[string]int)
+
+	for word, count := range source {
+		result.SourceWords += count
+		if missing := count - rendered[word]; missing > 0 {
+			lost[word] = missing
+			result.MissingWords += missing
+		}
+	}
+
+	for _, count := range rendered {
+		result.RenderedWords += count
+	}
+
+	if result.SourceWords > 0 {
+		result.Score = 1 - float64(result.MissingWords)/float64(result.SourceWords)
+	}
+
+	for word := range lost {
+		result.Missing = append(result.Missing, word)
+	}
+	sort.Slice(result.Missing, func(i, j int) bool {
+		a, b := result.Missing[i], result.Missing[j]
+		if lost[a] != lost[b] {
+			return lost[a] > lost[b]
+		}
+		return a < b
+	})
+	if len(result.Missing) > maxMissingSamples {
+		result.Missing = result.Missing[:maxMissingSamples]
+	}
+
+	return result
+}
+
+// textContent returns the visible text of an HTML document or fragment
+func textContent(source string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			switch node.Data {
+			case "script", "style", "noscript", "template":
+				return
+			}
+		}
+
+		if node.Type == html.TextNode {
+			builder.WriteString(node.Data)
+			builder.WriteString(" ")
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	return builder.String(), nil
+}
+
+// countWords returns the frequency of each lowercase word in text
+func countWords(text string) map[string]int {
+	counts := make(map[string]int)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		counts[word]++
+	}
+
+	return counts
+}
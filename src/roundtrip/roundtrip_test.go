@@ -0,0 +1,64 @@
+package roundtrip
+
+import (
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		markdown    string
+		wantScore   float64
+		wantMissing int
+	}{
+		{
+			name:      "lossless conversion",
+			source:    `<h1>Title</h1><p>Hello <strong>world</strong></p><script>ignored()</script>`,
+			markdown:  "# Title\n\nHello **world**\n",
+			wantScore: 1,
+		},
+		{
+			name:        "lost paragraph",
+			source:      `<p>one two</p><p>three four</p>`,
+			markdown:    "one two\n",
+			wantScore:   0.5,
+			wantMissing: 2,
+		},
+		{
+			name:      "empty source",
+			source:    ``,
+			markdown:  "anything",
+			wantScore: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Compare(tt.source, tt.markdown)
+			if err != nil {
+				t.Fatalf("Compare() unexpected error: %v", err)
+			}
+
+			if result.Score != tt.wantScore {
+				t.Errorf("Compare() Score = %v, want %v", result.Score, tt.wantScore)
+			}
+
+			if result.MissingWords != tt.wantMissing {
+				t.Errorf("Compare() MissingWords = %d, want %d", result.MissingWords, tt.wantMissing)
+			}
+
+			if len(result.Missing) != tt.wantMissing {
+				t.Errorf("Compare() Missing = %v", result.Missing)
+			}
+		})
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	counts := countWords("Hello, hello WORLD! café 42")
+
+	if counts["hello"] != 2 || counts["world"] != 1 || counts["café"] != 1 || counts["42"] != 1 {
+		t.Errorf("countWords() = %v", counts)
+	}
+}
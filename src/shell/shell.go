@@ -0,0 +1,36 @@
+// Package shell runs user-provided command lines with the shell of the platform
+package shell
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Command returns a command running line with sh -c, or cmd /C on Windows
+func Command(ctx context.Context, line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		//nolint:gosec // The command line is given by the user.
+		return exec.CommandContext(ctx, "cmd", "/C", line)
+	}
+	//nolint:gosec // The command line is given by the user.
+	return exec.CommandContext(ctx, "sh", "-c", line)
+}
+
+// Quote quotes a value as a single argument of the command lines run by Command
+func Quote(value string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// Expand replaces the {name} placeholders of a command line with the quoted values of vars
+func Expand(line string, vars map[string]string) string {
+	pairs := make([]string, 0, 2*len(vars))
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", Quote(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(line)
+}
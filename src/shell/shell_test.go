@@ -0,0 +1,26 @@
+package shell
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	line := Expand("printf '%s|%s' {file} {url}", map[string]string{
+		"file": "out/it's here.md",
+		"url":  "https://example.com/?a=1&b=$HOME",
+	})
+
+	got, err := Command(context.Background(), line).Output()
+	if err != nil {
+		t.Fatalf("running %q: %v", line, err)
+	}
+	if want := "out/it's here.md|https://example.com/?a=1&b=$HOME"; string(got) != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
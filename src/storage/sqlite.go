@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	// Register the pure Go SQLite driver
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS pages (
+	url        TEXT PRIMARY KEY,
+	title      TEXT NOT NULL,
+	file       TEXT NOT NULL,
+	markdown   TEXT NOT NULL,
+	hash       TEXT NOT NULL,
+	metadata   TEXT NOT NULL DEFAULT '{}',
+	fetched_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS links (
+	source TEXT NOT NULL,
+	target TEXT NOT NULL,
+	PRIMARY KEY (source, target)
+);
+CREATE INDEX IF NOT EXISTS links_target ON links (target);
+`
+
+// SQLiteStore persists pages and the link graph in a SQLite database
+type SQLiteStore struct {
+	db    *sql.DB
+	mutex sync.Mutex
+}
+
+// OpenSQLite opens or creates a SQLite database at path
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	// SQLite allows a single writer at a time
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create sqlite schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// SavePage inserts or replaces a page
+func (s *SQLiteStore) SavePage(page Page) error {
+	metadata := page.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	encodedMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.db.Exec(`
+		INSERT INTO pages (url, title, file, markdown, hash, metadata, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET
+			title = excluded.title,
+			file = excluded.file,
+			markdown = excluded.markdown,
+			hash = excluded.hash,
+			metadata = excluded.metadata,
+			fetched_at = excluded.fetched_at`,
+		page.URL, page.Title, page.File, page.Markdown, page.Hash, string(encodedMetadata),
+		page.FetchedAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save page %s: %w", page.URL, err)
+	}
+
+	return nil
+}
+
+// SaveLinks replaces the outgoing links recorded for a page
+func (s *SQLiteStore) SaveLinks(source string, targets []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM links WHERE source = ?`, source); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to clear links of %s: %w", source, err)
+	}
+
+	for _, target := range targets {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO links (source, target) VALUES (?, ?)`, source, target); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to save link %s -> %s: %w", source, target, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit links of %s: %w", source, err)
+	}
+
+	return nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	store, err := OpenSQLite(filepath.Join(t.TempDir(), "crawl.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite() unexpected error: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	page := Page{
+		URL:       "https://example.com/",
+		Title:     "Home",
+		File:      "index.md",
+		Markdown:  "# Home",
+		Hash:      "abc",
+		Metadata:  map[string]string{"lang": "en"},
+		FetchedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	if err := store.SavePage(page); err != nil {
+		t.Fatalf("SavePage() unexpected error: %v", err)
+	}
+
+	page.Title = "Updated"
+	if err := store.SavePage(page); err != nil {
+		t.Fatalf("SavePage() upsert unexpected error: %v", err)
+	}
+
+	var count int
+	var title, metadata string
+	if err := store.db.QueryRow(`SELECT COUNT(*), MAX(title), MAX(metadata) FROM pages`).Scan(&count, &title, &metadata); err != nil {
+		t.Fatalf("querying pages: %v", err)
+	}
+
+	if count != 1 || title != "Updated" || metadata != `{"lang":"en"}` {
+		t.Errorf("pages table = %d rows, title %q, metadata %q", count, title, metadata)
+	}
+
+	if err := store.SaveLinks(page.URL, []string{"https://example.com/a", "https://example.com/b", "https://example.com/a"}); err != nil {
+		t.Fatalf("SaveLinks() unexpected error: %v", err)
+	}
+
+	if err := store.SaveLinks(page.URL, []string{"https://example.com/c"}); err != nil {
+		t.Fatalf("SaveLinks() replace unexpected error: %v", err)
+	}
+
+	var target string
+	if err := store.db.QueryRow(`SELECT COUNT(*), MAX(target) FROM links WHERE source = ?`, page.URL).Scan(&count, &target); err != nil {
+		t.Fatalf("querying links: %v", err)
+	}
+
+	if count != 1 || target != "https://example.com/c" {
+		t.Errorf("links table = %d rows, target %q", count, target)
+	}
+}
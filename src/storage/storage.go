@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Page is a crawled page persisted by a Store
+type Page struct {
+	URL       string
+	Title     string
+	File      string
+	Markdown  string
+	Hash      string
+	Metadata  map[string]string
+	FetchedAt time.Time
+}
+
+// Store persists crawl results
+type Store interface {
+	// SavePage inserts or replaces a page
+	SavePage(page Page) error
+	// SaveLinks replaces the outgoing links recorded for a page
+	SaveLinks(source string, targets []string) error
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// Open creates a store from a "backend:location" specification, e.g. "sqlite:crawl.db"
+func Open(spec string) (Store, error) {
+	backend, location, ok := strings.Cut(spec, ":")
+	if !ok || location == "" {
+		return nil, fmt.Errorf("invalid store %q: expected backend:location", spec)
+	}
+
+	switch backend {
+	case "sqlite":
+		return OpenSQLite(location)
+	default:
+		return nil, fmt.Errorf("unsupported store backend %q", backend)
+	}
+}
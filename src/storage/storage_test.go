@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "sqlite", spec: "sqlite:" + filepath.Join(dir, "crawl.db")},
+		{name: "missing location", spec: "sqlite:", wantErr: true},
+		{name: "missing backend separator", spec: "crawl.db", wantErr: true},
+		{name: "unsupported backend", spec: "mysql:localhost", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := Open(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Open(%q) expected error but got none", tt.spec)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Open(%q) unexpected error: %v", tt.spec, err)
+			}
+
+			if err := store.Close(); err != nil {
+				t.Errorf("Close() unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package summary builds short extractive summaries of pages from their Markdown
+package summary
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sandrolain/crawldown/src/export"
+)
+
+const (
+	// MaxSentences is the largest number of sentences a summary may have
+	MaxSentences = 3
+	// minParagraphLength is the number of characters below which paragraphs are not meaningful,
+	// such as captions, bylines, or link rows
+	minParagraphLength = 40
+)
+
+// Summarize returns the first sentences of the first meaningful paragraph of a page, or of description,
+// usually the meta description, when the page has none. Headings, lists, tables, quotes, code, and short
+// paragraphs are skipped.
+func Summarize(markdown, description string, sentences int) string {
+	if sentences < 1 {
+		return ""
+	}
+
+	for _, paragraph := range paragraphs(markdown) {
+		text := strings.Join(strings.Fields(export.PlainText(paragraph)), " ")
+		if utf8.RuneCountInString(text) >= minParagraphLength {
+			return firstSentences(text, sentences)
+		}
+	}
+
+	return firstSentences(strings.Join(strings.Fields(description), " "), sentences)
+}
+
+// paragraphs returns the blocks of Markdown separated by blank lines that are prose paragraphs
+func paragraphs(markdown string) []string {
+	var result []string
+	var current []string
+	fence := ""
+
+	flush := func() {
+		if len(current) > 0 && prose(current[0]) {
+			result = append(result, strings.Join(current, "\n"))
+		}
+		current = current[:0]
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence = trimmed[:3]
+			continue
+		case trimmed == "":
+			flush()
+			continue
+		}
+		current = append(current, trimmed)
+	}
+	flush()
+
+	return result
+}
+
+// prose reports whether a block starting with line is a paragraph rather than another Markdown element
+func prose(line string) bool {
+	for _, prefix := range []string{"#", "|", ">", "<", "!", "- ", "* ", "+ ", "---", "***", "___"} {
+		if strings.HasPrefix(line, prefix) {
+			return false
+		}
+	}
+
+	// Ordered list items
+	digits := strings.TrimLeftFunc(line, unicode.IsDigit)
+	return len(digits) == len(line) || !strings.HasPrefix(digits, ". ") && !strings.HasPrefix(digits, ") ")
+}
+
+// firstSentences returns up to count sentences from the start of text. A sentence ends at a period,
+// question mark, or exclamation mark followed by a space and an uppercase letter, so abbreviations
+// such as "e.g. the" do not end one.
+func firstSentences(text string, count int) string {
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '.' && runes[i] != '?' && runes[i] != '!' {
+			continue
+		}
+		if i+2 >= len(runes) || runes[i+1] != ' ' || !unicode.IsUpper(runes[i+2]) {
+			continue
+		}
+
+		count--
+		if count == 0 {
+			return string(runes[:i+1])
+		}
+	}
+
+	return text
+}
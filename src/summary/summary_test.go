@@ -0,0 +1,65 @@
+package summary
+
+import "testing"
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name        string
+		markdown    string
+		description string
+		sentences   int
+		want        string
+	}{
+		{
+			name:      "first paragraph",
+			markdown:  "# Install\n\nThe installer sets up the **command line tool** and its [configuration](/config). It takes a minute. Then run it.",
+			sentences: 2,
+			want:      "The installer sets up the command line tool and its configuration. It takes a minute.",
+		},
+		{
+			name:      "skips short paragraphs and other elements",
+			markdown:  "By Jane\n\n![Diagram](/img.png)\n\n- A list item that is long enough to be a paragraph\n\n```\nA code block that is long enough to be a paragraph.\n```\n\n| A | table |\n\n1. An ordered item that is long enough to be a paragraph\n\nWrapped text that\nis meaningful enough. Second sentence.",
+			sentences: 1,
+			want:      "Wrapped text that is meaningful enough.",
+		},
+		{
+			name:      "abbreviations do not end sentences",
+			markdown:  "Options are read from files, e.g. config.json, and the environment. Flags come last.",
+			sentences: 1,
+			want:      "Options are read from files, e.g. config.json, and the environment.",
+		},
+		{
+			name:      "fewer sentences than requested",
+			markdown:  "A single sentence that is long enough to summarize the page",
+			sentences: 3,
+			want:      "A single sentence that is long enough to summarize the page",
+		},
+		{
+			name:        "description fallback",
+			markdown:    "# Reference\n\n- item one\n- item two",
+			description: "  Reference of the API.\nEvery endpoint is listed. Examples follow.  ",
+			sentences:   2,
+			want:        "Reference of the API. Every endpoint is listed.",
+		},
+		{
+			name:      "nothing to summarize",
+			markdown:  "# Empty",
+			sentences: 1,
+			want:      "",
+		},
+		{
+			name:      "disabled",
+			markdown:  "A paragraph that is long enough to summarize the page.",
+			sentences: 0,
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.markdown, tt.description, tt.sentences); got != tt.want {
+				t.Errorf("Summarize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Package tagging assigns tags to pages by URL pattern and by the elements found in their content
+package tagging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+
+	"github.com/sandrolain/crawldown/src/urlmatch"
+)
+
+// Rule adds tags to the pages matching its pattern and containing an element matching its selector.
+// At least one of Pattern or Selector must be set; a rule with both requires both to match.
+type Rule struct {
+	Pattern  string   `json:"pattern,omitempty"`  // Glob matched against the URL path, or the full URL if it contains ://
+	Selector string   `json:"selector,omitempty"` // CSS selector looked up in the extracted content of the page
+	Tags     []string `json:"tags"`
+}
+
+// Validate checks that the rule is well formed
+func (r Rule) Validate() error {
+	if r.Pattern == "" && r.Selector == "" {
+		return fmt.Errorf("tag rule must set a pattern, a selector, or both")
+	}
+
+	if r.Pattern != "" {
+		if _, err := urlmatch.Compile(r.Pattern); err != nil {
+			return err
+		}
+	}
+
+	if r.Selector != "" {
+		if _, err := cascadia.Compile(r.Selector); err != nil {
+			return fmt.Errorf("invalid selector %q: %w", r.Selector, err)
+		}
+	}
+
+	if len(r.Tags) == 0 {
+		return fmt.Errorf("tag rule must set at least one tag")
+	}
+	for _, tag := range r.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("tag rule has an empty tag")
+		}
+	}
+
+	return nil
+}
+
+type compiledRule struct {
+	pattern  *urlmatch.Pattern
+	selector cascadia.Selector
+	tags     []string
+}
+
+// Tagger evaluates tag rules against pages
+type Tagger struct {
+	rules     []compiledRule
+	selectors bool // Whether a rule needs the content of the page to be parsed
+}
+
+// NewTagger compiles tag rules
+func NewTagger(rules []Rule) (*Tagger, error) {
+	tagger := &Tagger{}
+
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid tag rule %d: %w", i, err)
+		}
+
+		compiled := compiledRule{tags: rule.Tags}
+		if rule.Pattern != "" {
+			compiled.pattern = urlmatch.MustCompile(rule.Pattern)
+		}
+		if rule.Selector != "" {
+			compiled.selector = cascadia.MustCompile(rule.Selector)
+			tagger.selectors = true
+		}
+		tagger.rules = append(tagger.rules, compiled)
+	}
+
+	return tagger, nil
+}
+
+// Tags returns the tags of all the rules matching a page, in rule order and without duplicates.
+// content is the extracted HTML of the page.
+func (t *Tagger) Tags(pageURL, content string) []string {
+	var doc *goquery.Document
+	if t.selectors {
+		parsed, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+		if err == nil {
+			doc = parsed
+		}
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, rule := range t.rules {
+		if rule.pattern != nil && !rule.pattern.Match(pageURL) {
+			continue
+		}
+		if rule.selector != nil && (doc == nil || doc.FindMatcher(rule.selector).Length() == 0) {
+			continue
+		}
+
+		for _, tag := range rule.tags {
+			tag = strings.TrimSpace(tag)
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,63 @@
+package tagging
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{name: "pattern", rule: Rule{Pattern: "/blog/*", Tags: []string{"blog"}}},
+		{name: "selector", rule: Rule{Selector: "pre code", Tags: []string{"code"}}},
+		{name: "pattern and selector", rule: Rule{Pattern: "/docs/*", Selector: ".api", Tags: []string{"api"}}},
+		{name: "no condition", rule: Rule{Tags: []string{"all"}}, wantErr: true},
+		{name: "no tags", rule: Rule{Pattern: "/blog/*"}, wantErr: true},
+		{name: "empty tag", rule: Rule{Pattern: "/blog/*", Tags: []string{" "}}, wantErr: true},
+		{name: "invalid selector", rule: Rule{Selector: "div[", Tags: []string{"broken"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaggerTags(t *testing.T) {
+	tagger, err := NewTagger([]Rule{
+		{Pattern: "/blog/*", Tags: []string{"blog"}},
+		{Selector: "pre code", Tags: []string{"code", "blog"}},
+		{Pattern: "/docs/*", Selector: ".api", Tags: []string{"api"}},
+		{Pattern: "https://example.com/docs/*", Tags: []string{"docs"}},
+	})
+	if err != nil {
+		t.Fatalf("NewTagger() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		content string
+		want    []string
+	}{
+		{name: "pattern", url: "https://example.com/blog/post", content: "<p>Text</p>", want: []string{"blog"}},
+		{name: "pattern and selector without duplicates", url: "https://example.com/blog/post", content: "<pre><code>x</code></pre>", want: []string{"blog", "code"}},
+		{name: "both conditions", url: "https://example.com/docs/ref", content: `<div class="api">Ref</div>`, want: []string{"api", "docs"}},
+		{name: "selector without pattern match", url: "https://example.com/about", content: `<div class="api">Ref</div>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tagger.Tags(tt.url, tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package urlmatch
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Pattern matches URLs against a glob.
+// Patterns containing "://" are matched against the full URL, otherwise against the URL path.
+// "*" matches any sequence of characters, including "/", and "?" matches a single character.
+type Pattern struct {
+	raw     string
+	fullURL bool
+	re      *regexp.Regexp
+}
+
+// Compile parses a glob pattern
+func Compile(pattern string) (*Pattern, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty URL pattern")
+	}
+
+	var builder strings.Builder
+	builder.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			builder.WriteString(".*")
+		case '?':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	builder.WriteString("$")
+
+	re, err := regexp.Compile(builder.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL pattern %q: %w", pattern, err)
+	}
+
+	return &Pattern{
+		raw:     pattern,
+		fullURL: strings.Contains(pattern, "://"),
+		re:      re,
+	}, nil
+}
+
+// MustCompile is like Compile but panics on invalid patterns
+func MustCompile(pattern string) *Pattern {
+	p, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match reports whether the URL matches the pattern
+func (p *Pattern) Match(rawURL string) bool {
+	if p.fullURL {
+		return p.re.MatchString(rawURL)
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	path := parsedURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	return p.re.MatchString(path)
+}
+
+// String returns the original pattern
+func (p *Pattern) String() string {
+	return p.raw
+}
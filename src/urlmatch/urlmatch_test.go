@@ -0,0 +1,38 @@
+package urlmatch
+
+import (
+	"testing"
+)
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{pattern: "/blog/*", url: "https://example.com/blog/2024/post", want: true},
+		{pattern: "/blog/*", url: "https://example.com/blog", want: false},
+		{pattern: "/blog*", url: "https://example.com/blog", want: true},
+		{pattern: "/docs/?", url: "https://example.com/docs/a", want: true},
+		{pattern: "/docs/?", url: "https://example.com/docs/ab", want: false},
+		{pattern: "/", url: "https://example.com", want: true},
+		{pattern: "/a.html", url: "https://example.com/a-html", want: false},
+		{pattern: "https://*.example.com/*", url: "https://docs.example.com/page", want: true},
+		{pattern: "https://*.example.com/*", url: "https://example.org/page", want: false},
+		{pattern: "/page", url: "https://example.com/page?x=1", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.url, func(t *testing.T) {
+			if got := MustCompile(tt.pattern).Match(tt.url); got != tt.want {
+				t.Errorf("Match(%q) with %q = %v, want %v", tt.url, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileEmpty(t *testing.T) {
+	if _, err := Compile(""); err == nil {
+		t.Errorf("Compile() expected error for empty pattern")
+	}
+}
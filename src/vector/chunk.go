@@ -0,0 +1,235 @@
+package vector
+
+import (
+	"crypto/sha1" //nolint:gosec // SHA-1 derives name-based UUIDs (RFC 9562 version 5), not signatures.
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sandrolain/crawldown/src/export"
+)
+
+const (
+	// DefaultChunkSize is the default maximum number of characters of a chunk
+	DefaultChunkSize = 2000
+	// DefaultChunkOverlap is the default number of characters repeated from the end of the previous chunk of a section
+	DefaultChunkOverlap = 200
+)
+
+var headingLinePattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// chunkNamespace is the UUID namespace of chunk IDs
+var chunkNamespace = [16]byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+// Chunk is a part of a page embedded and stored as one vector
+type Chunk struct {
+	ID      string // UUID derived from the page URL and the chunk index, so that runs replace their chunks
+	URL     string
+	Title   string
+	File    string
+	Heading string // Headings the chunk is nested under, joined with " > "
+	Index   int    // Position of the chunk in the page
+	Text    string
+}
+
+// EmbeddingText returns the text embedded for the chunk: its page title and headings, then its text
+func (c Chunk) EmbeddingText() string {
+	context := c.Title
+	if c.Heading != "" {
+		if context != "" {
+			context += " > "
+		}
+		context += c.Heading
+	}
+	if context == "" {
+		return c.Text
+	}
+	return context + "\n\n" + c.Text
+}
+
+// section is the content under a heading
+type section struct {
+	heading    string
+	paragraphs []string
+}
+
+// Split cuts the content of a page, without its front matter and header, into chunks of at most size characters along headings and paragraphs,
+// repeating the last overlap characters of a chunk at the start of the next one of the same section.
+// Paragraphs longer than size are cut at whitespace.
+func Split(doc export.Document, size, overlap int) []Chunk {
+	var chunks []Chunk
+	for _, sec := range sections(export.Content(doc)) {
+		for _, text := range pack(sec.paragraphs, size, overlap) {
+			chunks = append(chunks, Chunk{
+				ID:      chunkID(doc.URL, len(chunks)),
+				URL:     doc.URL,
+				Title:   doc.Title,
+				File:    doc.File,
+				Heading: sec.heading,
+				Index:   len(chunks),
+				Text:    text,
+			})
+		}
+	}
+	return chunks
+}
+
+// sections splits Markdown at its headings into the paragraphs of each section, keeping fenced code blocks whole
+func sections(markdown string) []section {
+	var result []section
+	var headings [6]string
+	current := section{}
+	var paragraph []string
+	fence := ""
+
+	flushParagraph := func() {
+		if text := strings.TrimSpace(strings.Join(paragraph, "\n")); text != "" {
+			current.paragraphs = append(current.paragraphs, text)
+		}
+		paragraph = paragraph[:0]
+	}
+	flushSection := func() {
+		flushParagraph()
+		if len(current.paragraphs) > 0 {
+			result = append(result, current)
+		}
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			paragraph = append(paragraph, line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+
+		if match := headingLinePattern.FindStringSubmatch(line); match != nil {
+			flushSection()
+			level := len(match[1])
+			headings[level-1] = match[2]
+			for i := level; i < len(headings); i++ {
+				headings[i] = ""
+			}
+			current = section{heading: joinHeadings(headings[:])}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"), strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case trimmed == "":
+			flushParagraph()
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	flushSection()
+
+	return result
+}
+
+func joinHeadings(headings []string) string {
+	var parts []string
+	for _, heading := range headings {
+		if heading != "" {
+			parts = append(parts, heading)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+// pack groups paragraphs into chunks of at most size characters
+func pack(paragraphs []string, size, overlap int) []string {
+	var chunks []string
+	var current []string
+	length := 0
+	fresh := false // Whether current holds more than the overlap of the previous chunk
+
+	// Paragraphs longer than a chunk are cut leaving room for the overlap
+	pieceSize := size - overlap - 2
+	if pieceSize < size/2 {
+		pieceSize = size
+	}
+
+	for _, paragraph := range paragraphs {
+		pieces := []string{paragraph}
+		if utf8.RuneCountInString(paragraph) > size {
+			pieces = splitLong(paragraph, pieceSize)
+		}
+		for _, piece := range pieces {
+			pieceLength := utf8.RuneCountInString(piece)
+			if fresh && length+2+pieceLength > size {
+				chunk := strings.Join(current, "\n\n")
+				chunks = append(chunks, chunk)
+				current, length, fresh = nil, 0, false
+				if carried := tail(chunk, overlap); carried != "" && utf8.RuneCountInString(carried)+2+pieceLength <= size {
+					current = []string{carried}
+					length = utf8.RuneCountInString(carried)
+				}
+			}
+			if len(current) > 0 {
+				length += 2
+			}
+			current = append(current, piece)
+			length += pieceLength
+			fresh = true
+		}
+	}
+	if fresh {
+		chunks = append(chunks, strings.Join(current, "\n\n"))
+	}
+
+	return chunks
+}
+
+// splitLong cuts text into pieces of at most size characters, at the last whitespace before the limit when possible
+func splitLong(text string, size int) []string {
+	var pieces []string
+	runes := []rune(text)
+	for len(runes) > size {
+		cut := size
+		for i := size; i > size/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+		pieces = append(pieces, strings.TrimSpace(string(runes[:cut])))
+		runes = []rune(strings.TrimSpace(string(runes[cut:])))
+	}
+	if len(runes) > 0 {
+		pieces = append(pieces, string(runes))
+	}
+	return pieces
+}
+
+// tail returns the last overlap characters of text, starting at a word boundary
+func tail(text string, overlap int) string {
+	runes := []rune(text)
+	if overlap <= 0 {
+		return ""
+	}
+	if len(runes) <= overlap {
+		return text
+	}
+
+	start := len(runes) - overlap
+	for start < len(runes) && !unicode.IsSpace(runes[start-1]) {
+		start++
+	}
+	return strings.TrimSpace(string(runes[start:]))
+}
+
+// chunkID returns a version 5 UUID of the chunk index of a page URL
+func chunkID(pageURL string, index int) string {
+	sum := sha1.Sum(append(chunkNamespace[:], pageURL+"#"+strconv.Itoa(index)...)) //nolint:gosec // See the import.
+
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
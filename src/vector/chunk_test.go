@@ -0,0 +1,82 @@
+package vector
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/export"
+)
+
+func TestSplit(t *testing.T) {
+	doc := export.Document{
+		URL:   "https://example.com/guide",
+		Title: "Guide",
+		File:  "guide.md",
+		Markdown: "Intro text.\n\n# Install\n\nFirst step of the install.\n\nSecond step of the install.\n\n" +
+			"## Linux\n\n```sh\n# not a heading\n\nmake install\n```\n\n# Usage\n\nRun it.",
+	}
+
+	chunks := Split(doc, 40, 0)
+
+	type part struct{ heading, text string }
+	var got []part
+	for i, chunk := range chunks {
+		if chunk.Index != i || chunk.URL != doc.URL || chunk.Title != "Guide" || chunk.File != "guide.md" {
+			t.Errorf("chunk %d = %+v, want the page fields and its index", i, chunk)
+		}
+		got = append(got, part{chunk.Heading, chunk.Text})
+	}
+	want := []part{
+		{"", "Intro text."},
+		{"Install", "First step of the install."},
+		{"Install", "Second step of the install."},
+		{"Install > Linux", "```sh\n# not a heading\n\nmake install\n```"},
+		{"Usage", "Run it."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Split() = %q, want %q", got, want)
+	}
+
+	if text := chunks[3].EmbeddingText(); !strings.HasPrefix(text, "Guide > Install > Linux\n\n```sh") {
+		t.Errorf("EmbeddingText() = %q, want the title and headings first", text)
+	}
+}
+
+func TestSplitLongParagraphsWithOverlap(t *testing.T) {
+	words := strings.Repeat("alpha beta gamma delta ", 20)
+	chunks := Split(export.Document{URL: "https://example.com/", Markdown: words}, 100, 30)
+
+	if len(chunks) < 5 {
+		t.Fatalf("Split() returned %d chunks, want the paragraph cut in pieces", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if n := len([]rune(chunk.Text)); n > 100 {
+			t.Errorf("chunk %d has %d characters, want at most 100", i, n)
+		}
+		if strings.HasPrefix(chunk.Text, " ") || strings.HasSuffix(chunk.Text, " ") {
+			t.Errorf("chunk %d = %q, want it trimmed", i, chunk.Text)
+		}
+		if i > 0 {
+			// The start of each chunk repeats the end of the previous one
+			previous := chunks[i-1].Text
+			overlap, _, _ := strings.Cut(chunk.Text, "\n\n")
+			if !strings.HasSuffix(previous, overlap) {
+				t.Errorf("chunk %d starts with %q, want the end of the previous chunk %q", i, overlap, previous)
+			}
+		}
+	}
+}
+
+func TestChunkID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	id := chunkID("https://example.com/", 0)
+	if !uuid.MatchString(id) {
+		t.Errorf("chunkID() = %q, want a version 5 UUID", id)
+	}
+	if chunkID("https://example.com/", 0) != id || chunkID("https://example.com/", 1) == id {
+		t.Error("chunkID() must be stable for a chunk and differ between chunks")
+	}
+}
@@ -0,0 +1,127 @@
+package vector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// embeddingBatch is the number of texts embedded per request
+const embeddingBatch = 64
+
+// Embedder computes embeddings with the embeddings endpoint of an OpenAI-compatible API
+type Embedder struct {
+	BaseURL string // API root such as https://api.openai.com/v1 or http://localhost:11434/v1
+	Model   string
+	APIKey  string // Sent as a bearer token when set
+
+	client *http.Client
+}
+
+// NewEmbedder creates an embedder calling the API at baseURL, reading the API key from OPENAI_API_KEY
+func NewEmbedder(baseURL, model string) *Embedder {
+	return &Embedder{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Model:   model,
+		APIKey:  os.Getenv("OPENAI_API_KEY"),
+		client:  &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed returns the embeddings of texts, in order
+func (e *Embedder) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatch {
+		batch := texts[start:min(start+embeddingBatch, len(texts))]
+
+		var reply embeddingResponse
+		headers := http.Header{}
+		if e.APIKey != "" {
+			headers.Set("Authorization", "Bearer "+e.APIKey)
+		}
+		if err := doJSON(e.client, http.MethodPost, e.BaseURL+"/embeddings", headers, embeddingRequest{Model: e.Model, Input: batch}, &reply); err != nil {
+			return nil, fmt.Errorf("failed to compute embeddings: %w", err)
+		}
+		if len(reply.Data) != len(batch) {
+			return nil, fmt.Errorf("embeddings API returned %d embeddings for %d texts", len(reply.Data), len(batch))
+		}
+
+		sort.Slice(reply.Data, func(i, j int) bool { return reply.Data[i].Index < reply.Data[j].Index })
+		for _, item := range reply.Data {
+			vectors = append(vectors, item.Embedding)
+		}
+	}
+	return vectors, nil
+}
+
+// maxErrorBody is the number of bytes of an error response included in errors
+const maxErrorBody = 1024
+
+// statusError is an unexpected HTTP status
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server returned %d %s: %s", e.status, http.StatusText(e.status), e.message)
+}
+
+// doJSON sends body as JSON, when not nil, and decodes the response into reply, when not nil
+func doJSON(client *http.Client, method, target string, headers http.Header, body, reply any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, target, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &statusError{status: resp.StatusCode, message: strings.TrimSpace(string(message))}
+	}
+
+	if reply == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(reply); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
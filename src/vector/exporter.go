@@ -0,0 +1,56 @@
+// Package vector chunks converted pages, embeds the chunks with an OpenAI-compatible embeddings API,
+// and stores them in a Qdrant, Chroma, or pgvector (through PostgREST) vector store for retrieval
+package vector
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sandrolain/crawldown/src/export"
+	"github.com/sandrolain/crawldown/src/output"
+)
+
+// Exporter replaces the chunks of each converted page in a vector store
+type Exporter struct {
+	Store    Store
+	Embedder *Embedder
+	Size     int // Maximum number of characters of a chunk, DefaultChunkSize when zero
+	Overlap  int // Characters repeated from the previous chunk of a section
+}
+
+// Name identifies the exporter
+func (e Exporter) Name() string {
+	return "vector store"
+}
+
+// Export chunks, embeds, and stores the documents, ordered by URL. The writer is not used.
+func (e Exporter) Export(docs []export.Document, _ output.Writer) error {
+	size := e.Size
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+
+	sorted := append([]export.Document(nil), docs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].URL < sorted[j].URL
+	})
+
+	for _, doc := range sorted {
+		chunks := Split(doc, size, e.Overlap)
+
+		texts := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			texts[i] = chunk.EmbeddingText()
+		}
+		vectors, err := e.Embedder.Embed(texts)
+		if err != nil {
+			return fmt.Errorf("failed to embed %s: %w", doc.URL, err)
+		}
+
+		if err := e.Store.Replace(doc.URL, chunks, vectors); err != nil {
+			return fmt.Errorf("failed to store %s: %w", doc.URL, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package vector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sandrolain/crawldown/src/export"
+)
+
+// memoryStore keeps the chunks stored by page URL
+type memoryStore struct {
+	pages   map[string][]Chunk
+	vectors map[string][][]float32
+}
+
+func (s *memoryStore) Replace(pageURL string, chunks []Chunk, vectors [][]float32) error {
+	s.pages[pageURL] = chunks
+	s.vectors[pageURL] = vectors
+	return nil
+}
+
+func TestExporter(t *testing.T) {
+	var inputs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request embeddingRequest
+		if r.URL.Path != "/v1/embeddings" || json.NewDecoder(r.Body).Decode(&request) != nil || request.Model != "embed-small" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		inputs = append(inputs, request.Input...)
+
+		// Embeddings are returned out of order, with the length of the input as the vector
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		var data []item
+		for i := len(request.Input) - 1; i >= 0; i-- {
+			data = append(data, item{Index: i, Embedding: []float32{float32(len(request.Input[i]))}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer srv.Close()
+
+	store := &memoryStore{pages: map[string][]Chunk{}, vectors: map[string][][]float32{}}
+	exporter := Exporter{Store: store, Embedder: NewEmbedder(srv.URL+"/v1", "embed-small")}
+
+	docs := []export.Document{
+		{URL: "https://example.com/b", Title: "B", Markdown: "# Usage\n\nRun it."},
+		{URL: "https://example.com/a", Title: "A", Markdown: "Hello.\n\n# Install\n\nInstall it."},
+		{URL: "https://example.com/empty", Title: "Empty"},
+	}
+	if err := exporter.Export(docs, nil); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	want := []string{"A\n\nHello.", "A > Install\n\nInstall it.", "B > Usage\n\nRun it."}
+	if len(inputs) != len(want) {
+		t.Fatalf("embedded %q, want %q", inputs, want)
+	}
+	for i := range want {
+		if inputs[i] != want[i] {
+			t.Errorf("embedded text %d = %q, want %q", i, inputs[i], want[i])
+		}
+	}
+
+	chunks := store.pages["https://example.com/a"]
+	if len(chunks) != 2 || store.vectors["https://example.com/a"][1][0] != float32(len(want[1])) {
+		t.Errorf("stored %+v with %v, want both chunks with their own embedding", chunks, store.vectors["https://example.com/a"])
+	}
+	if stored, ok := store.pages["https://example.com/empty"]; !ok || len(stored) != 0 {
+		t.Errorf("empty page stored %v, want its chunks replaced with none", stored)
+	}
+}
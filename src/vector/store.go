@@ -0,0 +1,231 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Store backends
+const (
+	BackendQdrant   = "qdrant"
+	BackendChroma   = "chroma"
+	BackendPgvector = "pgvector"
+)
+
+// Store keeps the chunks of pages with their embeddings
+type Store interface {
+	// Replace deletes the chunks stored for a page and stores its new chunks, in page order
+	Replace(pageURL string, chunks []Chunk, vectors [][]float32) error
+}
+
+// Open connects to a vector store given as backend:URL, where the last segment of the URL path is the
+// Qdrant or Chroma collection, or the pgvector table exposed by PostgREST. The API key of the store
+// is read from VECTOR_STORE_API_KEY.
+func Open(spec string) (Store, error) {
+	backend, location, ok := strings.Cut(spec, ":")
+	if !ok || location == "" {
+		return nil, fmt.Errorf("invalid vector store %q: expected backend:URL", spec)
+	}
+
+	parsed, err := url.Parse(location)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid vector store URL %q: expected http(s)://host/collection", location)
+	}
+	name := path.Base(parsed.Path)
+	if name == "/" || name == "." {
+		return nil, fmt.Errorf("invalid vector store URL %q: missing collection or table name", location)
+	}
+	parsed.Path = path.Dir(parsed.Path)
+	base := strings.TrimSuffix(parsed.String(), "/")
+
+	client := &http.Client{Timeout: time.Minute}
+	apiKey := os.Getenv("VECTOR_STORE_API_KEY")
+
+	switch backend {
+	case BackendQdrant:
+		return &qdrantStore{base: base, collection: name, apiKey: apiKey, client: client}, nil
+	case BackendChroma:
+		return &chromaStore{base: base, collection: name, apiKey: apiKey, client: client}, nil
+	case BackendPgvector:
+		return &postgrestStore{base: base, table: name, apiKey: apiKey, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported vector store backend %q: expected %s, %s, or %s", backend, BackendQdrant, BackendChroma, BackendPgvector)
+	}
+}
+
+// payload returns the fields stored with a chunk
+func payload(chunk Chunk) map[string]any {
+	return map[string]any{
+		"url":     chunk.URL,
+		"title":   chunk.Title,
+		"file":    chunk.File,
+		"heading": chunk.Heading,
+		"chunk":   chunk.Index,
+		"text":    chunk.Text,
+	}
+}
+
+// qdrantStore stores chunks as the points of a Qdrant collection, created on first use
+type qdrantStore struct {
+	base       string
+	collection string
+	apiKey     string
+	client     *http.Client
+	ready      bool
+}
+
+func (s *qdrantStore) do(method, endpoint string, body, reply any) error {
+	headers := http.Header{}
+	if s.apiKey != "" {
+		headers.Set("api-key", s.apiKey)
+	}
+	return doJSON(s.client, method, s.base+"/collections/"+url.PathEscape(s.collection)+endpoint, headers, body, reply)
+}
+
+// ensureCollection creates the collection with the size of the vectors unless it exists
+func (s *qdrantStore) ensureCollection(size int) error {
+	if s.ready {
+		return nil
+	}
+
+	err := s.do(http.MethodGet, "", nil, nil)
+	var status *statusError
+	if errors.As(err, &status) && status.status == http.StatusNotFound {
+		err = s.do(http.MethodPut, "", map[string]any{"vectors": map[string]any{"size": size, "distance": "Cosine"}}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create qdrant collection: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to get qdrant collection: %w", err)
+	}
+
+	s.ready = true
+	return nil
+}
+
+func (s *qdrantStore) Replace(pageURL string, chunks []Chunk, vectors [][]float32) error {
+	if len(vectors) > 0 {
+		if err := s.ensureCollection(len(vectors[0])); err != nil {
+			return err
+		}
+	}
+
+	filter := map[string]any{"filter": map[string]any{"must": []any{map[string]any{"key": "url", "match": map[string]any{"value": pageURL}}}}}
+	if err := s.do(http.MethodPost, "/points/delete?wait=true", filter, nil); err != nil {
+		// Nothing is stored in a collection that does not exist yet
+		var status *statusError
+		if !s.ready && errors.As(err, &status) && status.status == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to delete qdrant points: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	points := make([]map[string]any, len(chunks))
+	for i, chunk := range chunks {
+		points[i] = map[string]any{"id": chunk.ID, "vector": vectors[i], "payload": payload(chunk)}
+	}
+	if err := s.do(http.MethodPut, "/points?wait=true", map[string]any{"points": points}, nil); err != nil {
+		return fmt.Errorf("failed to upsert qdrant points: %w", err)
+	}
+	return nil
+}
+
+// chromaStore stores chunks as the records of a Chroma collection in the default tenant and database,
+// created on first use
+type chromaStore struct {
+	base       string
+	collection string
+	apiKey     string
+	client     *http.Client
+	id         string // Collection ID, resolved on first use
+}
+
+func (s *chromaStore) do(endpoint string, body, reply any) error {
+	headers := http.Header{}
+	if s.apiKey != "" {
+		headers.Set("x-chroma-token", s.apiKey)
+	}
+	return doJSON(s.client, http.MethodPost, s.base+"/api/v2/tenants/default_tenant/databases/default_database/collections"+endpoint, headers, body, reply)
+}
+
+func (s *chromaStore) Replace(pageURL string, chunks []Chunk, vectors [][]float32) error {
+	if s.id == "" {
+		var collection struct {
+			ID string `json:"id"`
+		}
+		if err := s.do("", map[string]any{"name": s.collection, "get_or_create": true}, &collection); err != nil {
+			return fmt.Errorf("failed to get chroma collection: %w", err)
+		}
+		s.id = collection.ID
+	}
+
+	endpoint := "/" + url.PathEscape(s.id)
+	if err := s.do(endpoint+"/delete", map[string]any{"where": map[string]any{"url": pageURL}}, nil); err != nil {
+		return fmt.Errorf("failed to delete chroma records: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(chunks))
+	documents := make([]string, len(chunks))
+	metadatas := make([]map[string]any, len(chunks))
+	for i, chunk := range chunks {
+		ids[i] = chunk.ID
+		documents[i] = chunk.Text
+		metadatas[i] = payload(chunk)
+		delete(metadatas[i], "text")
+	}
+	body := map[string]any{"ids": ids, "embeddings": vectors, "documents": documents, "metadatas": metadatas}
+	if err := s.do(endpoint+"/upsert", body, nil); err != nil {
+		return fmt.Errorf("failed to upsert chroma records: %w", err)
+	}
+	return nil
+}
+
+// postgrestStore stores chunks as the rows of a pgvector table exposed by PostgREST
+type postgrestStore struct {
+	base   string
+	table  string
+	apiKey string
+	client *http.Client
+}
+
+func (s *postgrestStore) do(method, query string, body any, headers http.Header) error {
+	if s.apiKey != "" {
+		headers.Set("Authorization", "Bearer "+s.apiKey)
+	}
+	return doJSON(s.client, method, s.base+"/"+url.PathEscape(s.table)+query, headers, body, nil)
+}
+
+func (s *postgrestStore) Replace(pageURL string, chunks []Chunk, vectors [][]float32) error {
+	if err := s.do(http.MethodDelete, "?url=eq."+url.QueryEscape(pageURL), nil, http.Header{}); err != nil {
+		return fmt.Errorf("failed to delete pgvector rows: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	rows := make([]map[string]any, len(chunks))
+	for i, chunk := range chunks {
+		row := payload(chunk)
+		row["id"] = chunk.ID
+		row["embedding"] = vectors[i]
+		rows[i] = row
+	}
+	headers := http.Header{}
+	headers.Set("Prefer", "resolution=merge-duplicates,return=minimal")
+	if err := s.do(http.MethodPost, "", rows, headers); err != nil {
+		return fmt.Errorf("failed to insert pgvector rows: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package vector
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordedRequest is a request received by a fake vector store
+type recordedRequest struct {
+	Method string
+	Path   string // Path and query
+	Body   map[string]any
+}
+
+// fakeStore records requests and answers them with the responses registered by method and path
+type fakeStore struct {
+	mutex     sync.Mutex
+	requests  []recordedRequest
+	responses map[string]string // "METHOD path" -> JSON body, a missing key answers 200 with {}
+	notFound  map[string]bool   // "METHOD path" answered 404
+}
+
+func (f *fakeStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	request := recordedRequest{Method: r.Method, Path: r.URL.RequestURI()}
+	data, _ := io.ReadAll(r.Body)
+	if len(data) > 0 {
+		var body any
+		if err := json.Unmarshal(data, &body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if object, ok := body.(map[string]any); ok {
+			request.Body = object
+		} else {
+			request.Body = map[string]any{"rows": body}
+		}
+	}
+	f.requests = append(f.requests, request)
+
+	key := r.Method + " " + r.URL.Path
+	if f.notFound[key] {
+		http.Error(w, `{"status": "not found"}`, http.StatusNotFound)
+		return
+	}
+	if response, ok := f.responses[key]; ok {
+		_, _ = w.Write([]byte(response))
+		return
+	}
+	_, _ = w.Write([]byte(`{}`))
+}
+
+func (f *fakeStore) calls() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var calls []string
+	for _, request := range f.requests {
+		calls = append(calls, request.Method+" "+request.Path)
+	}
+	return calls
+}
+
+func testChunks() ([]Chunk, [][]float32) {
+	chunks := []Chunk{
+		{ID: chunkID("https://example.com/a", 0), URL: "https://example.com/a", Title: "A", File: "a.md", Index: 0, Text: "First"},
+		{ID: chunkID("https://example.com/a", 1), URL: "https://example.com/a", Title: "A", File: "a.md", Heading: "Usage", Index: 1, Text: "Second"},
+	}
+	return chunks, [][]float32{{0.1, 0.2}, {0.3, 0.4}}
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{spec: "qdrant:http://localhost:6333/docs"},
+		{spec: "chroma:https://chroma.example.com/docs"},
+		{spec: "pgvector:http://localhost:3000/chunks"},
+		{spec: "qdrant", wantErr: "expected backend:URL"},
+		{spec: "qdrant:localhost:6333/docs", wantErr: "invalid vector store URL"},
+		{spec: "qdrant:http://localhost:6333", wantErr: "missing collection"},
+		{spec: "milvus:http://localhost:19530/docs", wantErr: "unsupported vector store backend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := Open(tt.spec)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Open() unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Open() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQdrantStore(t *testing.T) {
+	fake := &fakeStore{notFound: map[string]bool{"GET /collections/docs": true}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store, err := Open("qdrant:" + srv.URL + "/docs")
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	chunks, vectors := testChunks()
+	if err := store.Replace("https://example.com/a", chunks, vectors); err != nil {
+		t.Fatalf("Replace() unexpected error: %v", err)
+	}
+	if err := store.Replace("https://example.com/b", nil, nil); err != nil {
+		t.Fatalf("Replace() without chunks unexpected error: %v", err)
+	}
+
+	want := []string{
+		"GET /collections/docs",
+		"PUT /collections/docs",
+		"POST /collections/docs/points/delete?wait=true",
+		"PUT /collections/docs/points?wait=true",
+		"POST /collections/docs/points/delete?wait=true",
+	}
+	if got := fake.calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+
+	if size := fake.requests[1].Body["vectors"].(map[string]any)["size"]; size != float64(2) {
+		t.Errorf("collection vector size = %v, want 2", size)
+	}
+	points := fake.requests[3].Body["points"].([]any)
+	point := points[1].(map[string]any)
+	if len(points) != 2 || point["id"] != chunks[1].ID || point["payload"].(map[string]any)["heading"] != "Usage" {
+		t.Errorf("points = %v, want the chunks with their payload", points)
+	}
+}
+
+func TestChromaStore(t *testing.T) {
+	collections := "/api/v2/tenants/default_tenant/databases/default_database/collections"
+	fake := &fakeStore{responses: map[string]string{"POST " + collections: `{"id": "c1", "name": "docs"}`}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store, err := Open("chroma:" + srv.URL + "/docs")
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	chunks, vectors := testChunks()
+	if err := store.Replace("https://example.com/a", chunks, vectors); err != nil {
+		t.Fatalf("Replace() unexpected error: %v", err)
+	}
+
+	want := []string{"POST " + collections, "POST " + collections + "/c1/delete", "POST " + collections + "/c1/upsert"}
+	if got := fake.calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	if where := fake.requests[1].Body["where"].(map[string]any); where["url"] != "https://example.com/a" {
+		t.Errorf("delete filter = %v, want the page URL", where)
+	}
+	upsert := fake.requests[2].Body
+	if ids := upsert["ids"].([]any); len(ids) != 2 || ids[0] != chunks[0].ID {
+		t.Errorf("ids = %v", ids)
+	}
+	if documents := upsert["documents"].([]any); documents[1] != "Second" {
+		t.Errorf("documents = %v", documents)
+	}
+}
+
+func TestPostgrestStore(t *testing.T) {
+	fake := &fakeStore{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store, err := Open("pgvector:" + srv.URL + "/api/chunks")
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	chunks, vectors := testChunks()
+	if err := store.Replace("https://example.com/a", chunks, vectors); err != nil {
+		t.Fatalf("Replace() unexpected error: %v", err)
+	}
+
+	want := []string{"DELETE /api/chunks?url=eq.https%3A%2F%2Fexample.com%2Fa", "POST /api/chunks"}
+	if got := fake.calls(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	rows := fake.requests[1].Body["rows"].([]any)
+	row := rows[0].(map[string]any)
+	if len(rows) != 2 || row["id"] != chunks[0].ID || row["text"] != "First" || len(row["embedding"].([]any)) != 2 {
+		t.Errorf("rows = %v, want the chunks with their embeddings", rows)
+	}
+}
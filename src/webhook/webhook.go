@@ -0,0 +1,197 @@
+// Package webhook posts JSON notifications of crawl events to a URL, such as a Slack incoming webhook
+// or a CI pipeline trigger
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Event types
+const (
+	CrawlStarted  = "crawl.started"
+	CrawlFinished = "crawl.finished"
+	CrawlFailed   = "crawl.failed"
+	PageSaved     = "page.saved"
+	PageFailed    = "page.failed"
+	RequestFailed = "request.failed"
+)
+
+const (
+	// queueSize is the number of events waiting for delivery before Send blocks
+	queueSize = 256
+	// attempts is the number of times an event is posted before it is dropped
+	attempts = 3
+)
+
+// Types returns the supported event types
+func Types() []string {
+	return []string{CrawlStarted, CrawlFinished, CrawlFailed, PageSaved, PageFailed, RequestFailed}
+}
+
+// ValidateTypes checks that types are supported event types
+func ValidateTypes(types []string) error {
+	for _, eventType := range types {
+		if !slices.Contains(Types(), eventType) {
+			return fmt.Errorf("unknown event type %q: expected one of %s", eventType, strings.Join(Types(), ", "))
+		}
+	}
+	return nil
+}
+
+// Event is the JSON body posted for each event
+type Event struct {
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Text      string    `json:"text"`                // Human-readable description, shown by Slack and similar chat webhooks
+	URL       string    `json:"url,omitempty"`       // Start URL of crawl events, page or request URL of the others
+	File      string    `json:"file,omitempty"`      // Location of saved pages
+	Unchanged bool      `json:"unchanged,omitempty"` // Whether a saved page was already up to date
+	Stage     string    `json:"stage,omitempty"`     // Pipeline stage where a page failed
+	Error     string    `json:"error,omitempty"`
+	Summary   any       `json:"summary,omitempty"` // Summary of the run of crawl.finished events
+}
+
+// Notifier posts events in order from a background goroutine, so that a slow endpoint does not hold up
+// the crawl until its queue is full. Failed posts are retried, then reported through logf and dropped.
+// All methods do nothing on a nil receiver.
+type Notifier struct {
+	url        string
+	types      map[string]bool // Types sent, all when empty
+	client     *http.Client
+	logf       func(format string, args ...any)
+	retryDelay time.Duration // Delay before the first retry, doubled for each following one
+
+	events chan Event
+	done   chan struct{}
+}
+
+// New starts a notifier posting the given event types, all when types is empty, to url
+func New(url string, types []string, logf func(format string, args ...any)) *Notifier {
+	n := &Notifier{
+		url:        url,
+		types:      make(map[string]bool, len(types)),
+		client:     &http.Client{Timeout: 30 * time.Second},
+		logf:       logf,
+		retryDelay: time.Second,
+		events:     make(chan Event, queueSize),
+		done:       make(chan struct{}),
+	}
+	for _, eventType := range types {
+		n.types[eventType] = true
+	}
+
+	go n.run()
+	return n
+}
+
+// Send queues an event, setting its time and, when empty, its text
+func (n *Notifier) Send(event Event) {
+	if n == nil || len(n.types) > 0 && !n.types[event.Type] {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if event.Text == "" {
+		event.Text = describe(event)
+	}
+	n.events <- event
+}
+
+// Close waits for the queued events to be delivered. Send must not be called after Close.
+func (n *Notifier) Close() {
+	if n == nil {
+		return
+	}
+	close(n.events)
+	<-n.done
+}
+
+func (n *Notifier) run() {
+	defer close(n.done)
+	for event := range n.events {
+		if err := n.post(event); err != nil {
+			n.logf("Error sending %s webhook: %v\n", event.Type, err)
+		}
+	}
+}
+
+// post delivers an event, retrying network errors, rate limiting, and server errors
+func (n *Notifier) post(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	delay := n.retryDelay
+	for attempt := 1; ; attempt++ {
+		err = n.postOnce(body)
+		if err == nil || attempt == attempts {
+			return err
+		}
+		var retryable retryableError
+		if !errors.As(err, &retryable) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// retryableError is a failed post that may succeed later
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string { return e.err.Error() }
+
+func (n *Notifier) postOnce(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return retryableError{fmt.Errorf("failed to post event: %w", err)}
+	}
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return retryableError{fmt.Errorf("webhook returned %s", resp.Status)}
+	default:
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+}
+
+// describe returns the default text of an event
+func describe(event Event) string {
+	switch event.Type {
+	case CrawlStarted:
+		return "Crawl of " + event.URL + " started"
+	case CrawlFinished:
+		return "Crawl of " + event.URL + " finished"
+	case CrawlFailed:
+		return "Crawl of " + event.URL + " failed: " + event.Error
+	case PageSaved:
+		return "Saved " + event.URL + " to " + event.File
+	case PageFailed:
+		return "Failed to " + event.Stage + " " + event.URL + ": " + event.Error
+	case RequestFailed:
+		return "Request to " + event.URL + " failed: " + event.Error
+	default:
+		return event.Type
+	}
+}
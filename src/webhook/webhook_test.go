@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNotifier(t *testing.T) {
+	var mutex sync.Mutex
+	var received []Event
+	failures := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		// The first post fails and is retried
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var event Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, event)
+	}))
+	defer srv.Close()
+
+	var logged []string
+	notifier := New(srv.URL, []string{CrawlStarted, PageFailed}, func(format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	})
+	notifier.retryDelay = 0
+
+	notifier.Send(Event{Type: CrawlStarted, URL: "https://example.com"})
+	notifier.Send(Event{Type: PageSaved, URL: "https://example.com/", File: "index.md"})
+	notifier.Send(Event{Type: PageFailed, URL: "https://example.com/broken", Stage: "convert", Error: "bad HTML"})
+	notifier.Close()
+
+	if len(logged) > 0 {
+		t.Errorf("logged errors: %v", logged)
+	}
+	if len(received) != 2 {
+		t.Fatalf("received %d events, want the 2 selected ones: %+v", len(received), received)
+	}
+	if received[0].Type != CrawlStarted || received[0].Text != "Crawl of https://example.com started" || received[0].Time.IsZero() {
+		t.Errorf("first event = %+v", received[0])
+	}
+	if received[1].Text != "Failed to convert https://example.com/broken: bad HTML" {
+		t.Errorf("second event text = %q", received[1].Text)
+	}
+}
+
+func TestNotifierReportsRejectedEvents(t *testing.T) {
+	posts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	var logged []string
+	notifier := New(srv.URL, nil, func(format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	})
+	notifier.Send(Event{Type: CrawlFinished, URL: "https://example.com"})
+	notifier.Close()
+
+	// Client errors are not retried
+	if posts != 1 || len(logged) != 1 || !strings.Contains(logged[0], "404") {
+		t.Errorf("posts = %d, logged = %v, want one post and its error logged", posts, logged)
+	}
+}
+
+func TestNilNotifier(t *testing.T) {
+	var notifier *Notifier
+	notifier.Send(Event{Type: CrawlStarted})
+	notifier.Close()
+}
+
+func TestValidateTypes(t *testing.T) {
+	if err := ValidateTypes([]string{CrawlFinished, PageFailed}); err != nil {
+		t.Errorf("ValidateTypes() unexpected error: %v", err)
+	}
+	if err := ValidateTypes([]string{"crawl.done"}); err == nil {
+		t.Error("ValidateTypes() expected error for an unknown type")
+	}
+}